@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+// newAuthCmd creates the auth command group.
+func newAuthCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage stored credentials",
+		Long: `Manage how API keys and OAuth tokens are stored.
+
+Examples:
+  cdd auth migrate-keyring  Move plaintext credentials into the OS keyring`,
+	}
+
+	cmd.AddCommand(newAuthMigrateKeyringCmd())
+
+	return cmd
+}
+
+// newAuthMigrateKeyringCmd moves plaintext credentials into the OS keyring.
+func newAuthMigrateKeyringCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate-keyring",
+		Short: "Move plaintext credentials into the OS keyring",
+		Long: `Move the API keys and OAuth tokens stored in plaintext in cdd.json into the
+OS keyring (macOS Keychain, libsecret, Windows Credential Manager), leaving
+only keyring references in the config file. Environment variable references
+such as $OPENAI_API_KEY are left untouched.`,
+		Args: cobra.NoArgs,
+		RunE: runAuthMigrateKeyring,
+	}
+}
+
+// runAuthMigrateKeyring executes the auth migrate-keyring command.
+func runAuthMigrateKeyring(_ *cobra.Command, _ []string) error {
+	moved, err := config.MigrateSecretsToKeyring()
+	if err != nil {
+		return fmt.Errorf("migrating credentials: %w", err)
+	}
+
+	if moved == 0 {
+		fmt.Println("No plaintext credentials to migrate.")
+		return nil
+	}
+	fmt.Printf("Moved %d credential(s) to the OS keyring.\n", moved)
+	return nil
+}
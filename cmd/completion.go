@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/db"
+	"github.com/guilhermegouw/cdd/internal/session"
+)
+
+// firstArg completes the first argument with complete and nothing after it.
+func firstArg(complete cobra.CompletionFunc) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return complete(cmd, args, toComplete)
+	}
+}
+
+// completionConfig loads the configuration of the profile chosen on the
+// command line. Completion skips the persistent pre-run that normally does.
+func completionConfig(cmd *cobra.Command) (*config.Config, bool) {
+	if err := applyGlobalFlags(cmd, nil); err != nil {
+		return nil, false
+	}
+	cfg, err := config.Load()
+	return cfg, err == nil
+}
+
+// completeProviderIDs completes the IDs of every known provider, or only the
+// custom ones.
+func completeProviderIDs(customOnly bool) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, _ []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		cfg, ok := completionConfig(cmd)
+		if !ok {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		loader := config.NewProviderLoader(cfg.DataDir())
+
+		var completions []cobra.Completion
+		if customOnly {
+			custom, _ := loader.GetCustomProviderManager().Load() //nolint:errcheck // No completions on error
+			for i := range custom {
+				completions = append(completions, cobra.CompletionWithDesc(custom[i].ID, custom[i].Name))
+			}
+			return completions, cobra.ShellCompDirectiveNoFileComp
+		}
+		providers, _ := loader.LoadAllProviders(cfg) //nolint:errcheck // No completions on error
+		for i := range providers {
+			completions = append(completions, cobra.CompletionWithDesc(string(providers[i].ID), providers[i].Name))
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeProvidersAndConnections completes provider and connection IDs, for
+// commands that accept either.
+func completeProvidersAndConnections(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	completions, directive := completeProviderIDs(false)(cmd, args, toComplete)
+	if cfg, ok := completionConfig(cmd); ok {
+		for _, conn := range config.NewConnectionManager(cfg).List() {
+			completions = append(completions, cobra.CompletionWithDesc(conn.ID, conn.Name))
+		}
+	}
+	return completions, directive
+}
+
+// completeConnections completes connection names.
+func completeConnections(cmd *cobra.Command, _ []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	cfg, ok := completionConfig(cmd)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var completions []cobra.Completion
+	for _, conn := range config.NewConnectionManager(cfg).List() {
+		completions = append(completions, cobra.CompletionWithDesc(conn.Name, conn.ProviderID))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTemplates completes the names of the provider templates.
+func completeTemplates(*cobra.Command, []string, string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	templates := config.ProviderTemplates()
+	var completions []cobra.Completion
+	for _, name := range config.ListTemplateNames() {
+		completions = append(completions, cobra.CompletionWithDesc(name, templates[name].Description))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeModelRefs completes the tier and then the <connection>/<model>
+// arguments of cdd models set.
+func completeModelRefs(cmd *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return []cobra.Completion{string(config.SelectedModelTypeLarge), string(config.SelectedModelTypeSmall)}, cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		cfg, ok := completionConfig(cmd)
+		if !ok {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var completions []cobra.Completion
+		connections := config.NewConnectionManager(cfg).List()
+		for i := range connections {
+			for _, model := range cfg.ConnectionModels(&connections[i]) {
+				completions = append(completions, cobra.CompletionWithDesc(connections[i].Name+"/"+model.ID, model.Name))
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeModes completes the names of the agent modes.
+func completeModes(cmd *cobra.Command, _ []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	cfg, ok := completionConfig(cmd)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	modes := cfg.AgentModes()
+	completions := make([]cobra.Completion, 0, len(modes))
+	for _, name := range slices.Sorted(maps.Keys(modes)) {
+		completions = append(completions, cobra.CompletionWithDesc(name, modes[name].Description))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSessionIDs completes the IDs of this project's sessions, described
+// by their titles.
+func completeSessionIDs(cmd *cobra.Command, _ []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	cfg, ok := completionConfig(cmd)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	database, err := db.Open(databasePath(cfg))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	defer database.Close() //nolint:errcheck // Read-only use
+
+	sessions, err := session.NewSQLiteStore(database.Conn()).ListWithPreview(context.Background(), currentProject())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	completions := make([]cobra.Completion, 0, len(sessions))
+	for _, s := range sessions {
+		desc := s.Title
+		if desc == "" || desc == "New Session" {
+			desc = s.FirstMessage
+		}
+		completions = append(completions, cobra.CompletionWithDesc(s.ID, strings.Join(strings.Fields(desc), " ")))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSessionExport completes the session ID of cdd sessions export and
+// then the output file.
+func completeSessionExport(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) == 1 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return firstArg(completeSessionIDs)(cmd, args, toComplete)
+}
@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+// newConfigCmd creates the config command group.
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect and check cdd.json",
+		Long: `Inspect and check the configuration files.
+
+Examples:
+  cdd config validate                        Check the global and project config files
+  cdd config validate cdd.json               Check a specific file
+  cdd config get models.large.model          Print a setting
+  cdd config set options.debug true          Change a setting
+  cdd config set --file cdd.json lsp.gopls '{"command": "gopls", "filetypes": ["go"]}'
+  cdd config snapshot save openai            Keep a copy of the current setup
+  cdd config snapshot restore openai         Put it back`,
+	}
+
+	cmd.AddCommand(newConfigValidateCmd())
+	cmd.AddCommand(newConfigGetCmd())
+	cmd.AddCommand(newConfigSetCmd())
+	cmd.AddCommand(newConfigSnapshotCmd())
+
+	return cmd
+}
+
+// newConfigValidateCmd checks config files against the configuration schema.
+func newConfigValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate [file]",
+		Short: "Check config files for mistakes",
+		Long: `Check config files for unknown keys, values of the wrong type and missing
+required keys, and print every problem found. Without a file, the global config
+and the project config for the current directory are checked.`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE:         runConfigValidate,
+	}
+}
+
+// runConfigValidate executes the config validate command.
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	var files []string
+	if len(args) == 1 {
+		files = args
+	} else {
+		if _, err := os.Stat(config.GlobalConfigPath()); err == nil {
+			files = append(files, config.GlobalConfigPath())
+		}
+		if project := config.ProjectConfigPath(); project != "" {
+			files = append(files, project)
+		}
+		if len(files) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No config files found.")
+			return nil
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	count := 0
+	for _, file := range files {
+		problems, err := config.ValidateFile(file)
+		if err != nil {
+			return fmt.Errorf("reading config: %w", err)
+		}
+		if len(problems) == 0 {
+			fmt.Fprintf(out, "%s: OK\n", file)
+			continue
+		}
+		fmt.Fprintf(out, "%s:\n", file)
+		for _, p := range problems {
+			fmt.Fprintf(out, "  %s\n", p)
+		}
+		count += len(problems)
+	}
+
+	if count > 0 {
+		return fmt.Errorf("found %d problem(s)", count)
+	}
+
+	// The files are well formed; check that they also load, which catches
+	// problems such as unknown providers or unresolvable models.
+	var err error
+	if len(args) == 0 {
+		_, err = config.Load()
+	} else {
+		_, err = config.LoadFromFile(args[0])
+	}
+	return err
+}
+
+// newConfigGetCmd prints a setting from a config file.
+func newConfigGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print a setting from cdd.json",
+		Long: `Print the value at a dotted key path, such as models.large.model. List items
+are addressed by index (connections.0.id). Strings are printed as they are and
+other values as JSON. Reads the global config unless --file is given.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         runConfigGet,
+	}
+	cmd.Flags().String("file", "", "Config file to read (default: the global config)")
+	return cmd
+}
+
+// runConfigGet executes the config get command.
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	value, err := config.GetValue(configFileFlag(cmd), args[0])
+	if err != nil {
+		return err
+	}
+
+	if s, ok := value.(string); ok {
+		fmt.Fprintln(cmd.OutOrStdout(), s)
+		return nil
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling value: %w", err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	return nil
+}
+
+// newConfigSetCmd changes a setting in a config file.
+func newConfigSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Change a setting in cdd.json",
+		Long: `Set the value at a dotted key path, creating missing objects on the way. The
+value is read as JSON when it parses (true, 3, ["go"], {"a": 1}) and as a
+string otherwise; quote it as JSON ('"3"') to force a string. The change is
+checked before the file is written, and the file is replaced atomically.
+Writes the global config unless --file is given.`,
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE:         runConfigSet,
+	}
+	cmd.Flags().String("file", "", "Config file to change (default: the global config)")
+	return cmd
+}
+
+// runConfigSet executes the config set command.
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	path := configFileFlag(cmd)
+	if err := config.SetValue(path, args[0], args[1]); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Set %s in %s\n", args[0], path)
+	return nil
+}
+
+// configFileFlag returns the --file flag, defaulting to the global config.
+func configFileFlag(cmd *cobra.Command) string {
+	if path, _ := cmd.Flags().GetString("file"); path != "" { //nolint:errcheck // Flag is always defined
+		return path
+	}
+	return config.GlobalConfigPath()
+}
+
+// newConfigSnapshotCmd creates the config snapshot command group.
+func newConfigSnapshotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Save and restore named copies of the configuration",
+		Long: `Save the global cdd.json and custom-providers.json under a name and restore
+them later, so a provider setup can be tried out and undone. Snapshots are kept
+in the data directory of the active profile. API keys in the system keyring
+are referenced by the copies, not copied.
+
+Restoring saves the configuration it replaces as "` + config.PreRestoreSnapshot + `" first; restore
+that snapshot to undo the restore.`,
+	}
+
+	cmd.AddCommand(newConfigSnapshotSaveCmd())
+	cmd.AddCommand(newConfigSnapshotRestoreCmd())
+	cmd.AddCommand(newConfigSnapshotListCmd())
+	cmd.AddCommand(newConfigSnapshotDeleteCmd())
+
+	return cmd
+}
+
+// newConfigSnapshotSaveCmd saves a snapshot.
+func newConfigSnapshotSaveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "save <name>",
+		Short:        "Save the current configuration as a snapshot",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			force, _ := cmd.Flags().GetBool("force") //nolint:errcheck // Flag is defined.
+			snap, err := configSnapshots().Save(args[0], force)
+			if err != nil {
+				if errors.Is(err, config.ErrSnapshotExists) {
+					return fmt.Errorf("%w; use --force to replace it", err)
+				}
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Saved snapshot %s (%s)\n", snap.Name, snapshotFiles(snap))
+			return nil
+		},
+	}
+	cmd.Flags().Bool("force", false, "Replace an existing snapshot of the same name")
+	return cmd
+}
+
+// newConfigSnapshotRestoreCmd restores a snapshot.
+func newConfigSnapshotRestoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "restore <name>",
+		Short:        "Replace the configuration with a snapshot",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snap, err := configSnapshots().Restore(args[0])
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Restored snapshot %s (%s)\n", snap.Name, snapshotFiles(snap))
+			if snap.Name != config.PreRestoreSnapshot {
+				fmt.Fprintf(out, "The previous configuration was saved as %s\n", config.PreRestoreSnapshot)
+			}
+			return nil
+		},
+	}
+}
+
+// newConfigSnapshotListCmd lists the snapshots.
+func newConfigSnapshotListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "list",
+		Short:        "List snapshots, newest first",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			snaps, err := configSnapshots().List()
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if len(snaps) == 0 {
+				fmt.Fprintln(out, "No snapshots.")
+				return nil
+			}
+			for _, snap := range snaps {
+				fmt.Fprintf(out, "%-20s %s  %s\n", snap.Name, snap.CreatedAt.Local().Format(time.DateTime), snapshotFiles(snap))
+			}
+			return nil
+		},
+	}
+}
+
+// newConfigSnapshotDeleteCmd deletes a snapshot.
+func newConfigSnapshotDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "delete <name>",
+		Short:        "Delete a snapshot",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := configSnapshots().Delete(args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted snapshot %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+// configSnapshots returns the snapshots of the active profile. The config is
+// not loaded, since a broken setup is what a snapshot is restored to fix; only
+// the data_dir option, where custom providers are kept, is read.
+func configSnapshots() *config.Snapshots {
+	dataDir, _ := config.GetValue(config.GlobalConfigPath(), "options.data_dir") //nolint:errcheck // Unset means the default
+	dir, _ := dataDir.(string)
+	return config.NewSnapshots(dir)
+}
+
+// snapshotFiles lists the files a snapshot holds.
+func snapshotFiles(snap config.Snapshot) string {
+	if len(snap.Files) == 0 {
+		return "no config files"
+	}
+	return strings.Join(snap.Files, ", ")
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+// newConnectionsCmd creates the connections command group.
+func newConnectionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "connections",
+		Short: "List, duplicate and delete connections",
+		Long: `Manage the named API connections to providers, as the connections modal
+(/models) does in the TUI. Connections are named by name or ID.
+
+Examples:
+  cdd connections list                                      List connections and what uses them
+  cdd connections duplicate Gateway --name "Gateway (web)"   Copy a connection, asking for a new API key
+  cdd connections delete --unused                           Delete the connections no model uses`,
+	}
+
+	cmd.AddCommand(newConnectionsListCmd())
+	cmd.AddCommand(newConnectionsDuplicateCmd())
+	cmd.AddCommand(newConnectionsDeleteCmd())
+
+	return cmd
+}
+
+func newConnectionsListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List connections",
+		Args:  cobra.NoArgs,
+		RunE:  runConnectionsList,
+	}
+}
+
+func runConnectionsList(cmd *cobra.Command, _ []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	manager := config.NewConnectionManager(cfg)
+	connections := manager.List()
+	if len(connections) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No connections configured. Run 'cdd' to set one up.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tID\tPROVIDER\tAUTH\tUSED BY")
+	for i := range connections {
+		conn := &connections[i]
+		auth := "none"
+		switch {
+		case conn.IsOAuth():
+			auth = "oauth"
+		case conn.APIKey != "":
+			auth = "api key"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", conn.Name, conn.ID, conn.ProviderID, auth,
+			strings.Join(connectionTiers(manager, conn.ID), ", "))
+	}
+	return w.Flush()
+}
+
+func newConnectionsDuplicateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "duplicate <connection> --name <name>",
+		Short: "Copy a connection under a new name",
+		Long: `Copy a connection, with its endpoint, headers, query parameters and HTTP
+settings, under a new name, e.g. to use a per-project API key at the same
+endpoint. Without --api-key the new key is asked for when run in a terminal;
+an empty answer, or no terminal, keeps the original's key. OAuth sign-ins are
+not copied, so a copy of an OAuth connection needs --api-key.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: firstArg(completeConnections),
+		SilenceUsage:      true,
+		RunE:              runConnectionsDuplicate,
+	}
+	cmd.Flags().String("name", "", "Name of the copy (required)")
+	cmd.Flags().String("api-key", "", "API key of the copy, or a $ENV_VAR reference")
+	_ = cmd.MarkFlagRequired("name") //nolint:errcheck // Flag is defined.
+	return cmd
+}
+
+func runConnectionsDuplicate(cmd *cobra.Command, args []string) error {
+	name, _ := cmd.Flags().GetString("name")      //nolint:errcheck // Flag is defined.
+	apiKey, _ := cmd.Flags().GetString("api-key") //nolint:errcheck // Flag is defined.
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	manager := config.NewConnectionManager(cfg)
+	src, err := findConnection(manager, args[0])
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Flags().Changed("api-key") && term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "API key for %s (empty keeps the key of %s): ", name, src.Name)
+		key, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return fmt.Errorf("reading API key: %w", err)
+		}
+		apiKey = strings.TrimSpace(string(key))
+	}
+
+	dup, err := manager.Duplicate(src.ID, name, apiKey)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Added connection %s (%s), a copy of %s\n", dup.Name, dup.ID, src.Name)
+	return nil
+}
+
+func newConnectionsDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete [connection...]",
+		Short: "Delete connections",
+		Long: `Delete the named connections, or with --unused every connection that
+neither the large nor the small model uses. Connections a model uses are not
+deleted; choose another model with 'cdd models set' first.`,
+		ValidArgsFunction: completeConnections,
+		SilenceUsage:      true,
+		RunE:              runConnectionsDelete,
+	}
+	cmd.Flags().Bool("unused", false, "Delete every connection no model uses")
+	cmd.Flags().Bool("dry-run", false, "List the connections that would be deleted")
+	return cmd
+}
+
+func runConnectionsDelete(cmd *cobra.Command, args []string) error {
+	unused, _ := cmd.Flags().GetBool("unused")  //nolint:errcheck // Flag is defined.
+	dryRun, _ := cmd.Flags().GetBool("dry-run") //nolint:errcheck // Flag is defined.
+	if unused == (len(args) > 0) {
+		return errors.New("name the connections to delete, or pass --unused")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	manager := config.NewConnectionManager(cfg)
+
+	var targets []config.Connection
+	if unused {
+		targets = manager.Unused()
+	}
+	for _, ref := range args {
+		conn, err := findConnection(manager, ref)
+		if err != nil {
+			return err
+		}
+		if tiers := connectionTiers(manager, conn.ID); len(tiers) > 0 {
+			return fmt.Errorf("connection %s is used by the %s model; choose another with 'cdd models set' first",
+				conn.Name, strings.Join(tiers, " and "))
+		}
+		targets = append(targets, *conn)
+	}
+
+	out := cmd.OutOrStdout()
+	if len(targets) == 0 {
+		fmt.Fprintln(out, "No unused connections.")
+		return nil
+	}
+	ids := make([]string, 0, len(targets))
+	for i := range targets {
+		ids = append(ids, targets[i].ID)
+	}
+	if !dryRun {
+		if err := manager.DeleteMany(ids); err != nil {
+			return err
+		}
+	}
+
+	verb := "Deleted"
+	if dryRun {
+		verb = "Would delete"
+	}
+	for i := range targets {
+		fmt.Fprintf(out, "%s %s (%s)\n", verb, targets[i].Name, targets[i].ProviderID)
+	}
+	return nil
+}
+
+// findConnection returns the connection with the given ID or name.
+func findConnection(manager *config.ConnectionManager, ref string) (*config.Connection, error) {
+	if conn := manager.Get(ref); conn != nil {
+		return conn, nil
+	}
+	if conn := manager.GetByName(ref); conn != nil {
+		return conn, nil
+	}
+	return nil, fmt.Errorf("connection %q not found; see 'cdd connections list'", ref)
+}
+
+// connectionTiers lists the model tiers that use a connection.
+func connectionTiers(manager *config.ConnectionManager, connectionID string) []string {
+	var tiers []string
+	for _, tier := range []config.SelectedModelType{config.SelectedModelTypeLarge, config.SelectedModelTypeSmall} {
+		if conn := manager.GetActiveConnection(tier); conn != nil && conn.ID == connectionID {
+			tiers = append(tiers, string(tier))
+		}
+	}
+	return tiers
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/db"
+	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/index"
+)
+
+func newIndexCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "index",
+		Short: "Build or update the codebase index",
+		Long: `Embed the files of the current project for the search_codebase tool.
+Only files changed since the last run are embedded again, and the agent
+updates the index itself before each search, so running this is only needed
+to build a large index up front.
+
+The index is off until enabled in cdd.json:
+
+  "options": {
+    "index": {
+      "enabled": true,
+      "provider": "openai",
+      "model": "text-embedding-3-small",
+      "api_key": "$OPENAI_API_KEY"
+    }
+  }
+
+The "local" provider, the default, needs no service but only matches code
+that shares words with the query. "openai" works with any OpenAI-compatible
+embeddings API; set base_url for Ollama or a gateway.`,
+		Args: cobra.NoArgs,
+		RunE: runIndex,
+	}
+}
+
+func runIndex(cmd *cobra.Command, _ []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if cfg.CodebaseIndex() == nil {
+		return errors.New("the codebase index is off; set options.index.enabled in cdd.json")
+	}
+	database, err := db.Open(databasePath(cfg))
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer database.Close() //nolint:errcheck // Read-only after the update commits.
+
+	idx := openIndex(cfg, database.Conn())
+	if idx == nil {
+		return errors.New("the codebase index could not be opened; run with --debug for details")
+	}
+
+	started := time.Now()
+	stats, err := idx.Update(cmd.Context(), func(done, total int) {
+		fmt.Fprintf(os.Stderr, "\rEmbedding files: %d/%d", done, total)
+		if done == total {
+			fmt.Fprintln(os.Stderr)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("updating index: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Indexed %d files: %d embedded (%d chunks), %d removed in %s\n",
+		stats.Files, stats.Indexed, stats.Chunks, stats.Removed, time.Since(started).Round(time.Millisecond))
+	return nil
+}
+
+// openIndex returns the codebase index of the current project, or nil when
+// it is not enabled or its embedder is misconfigured.
+func openIndex(cfg *config.Config, conn *sql.DB) *index.Index {
+	opts := cfg.CodebaseIndex()
+	if opts == nil {
+		return nil
+	}
+	embedder, err := index.NewEmbedder(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Codebase index disabled: %v\n", err)
+		debug.Log("Codebase index disabled: %v", err)
+		return nil
+	}
+	return index.New(conn, currentProject(), embedder)
+}
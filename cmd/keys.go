@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
+)
+
+func newKeysCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "keys",
+		Short: "List TUI key bindings",
+		Long: `List the key bindings of the TUI, including overrides from
+options.keybindings in cdd.json. Override an action by listing its keys:
+
+  "options": {
+    "keybindings": {
+      "newline": ["ctrl+j", "shift+enter"],
+      "search": []
+    }
+  }
+
+An empty list unbinds the action.`,
+		Args: cobra.NoArgs,
+		RunE: runKeys,
+	}
+}
+
+func runKeys(cmd *cobra.Command, _ []string) error {
+	var overrides map[string][]string
+	if cfg, err := config.Load(); err == nil {
+		overrides = cfg.Keybindings()
+	}
+
+	km, err := keymap.New(overrides)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	for i, group := range km.Groups() {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s:\n", group.Name)
+		for _, e := range group.Entries {
+			keys := strings.Join(e.Keys, ", ")
+			if keys == "" {
+				keys = "(unbound)"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", e.Action, keys, e.Help)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+func newManCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "man [directory]",
+		Short: "Generate man pages",
+		Long: `Write a man page for cdd and each of its commands to a directory (the
+current one by default), for example:
+
+  cdd man /usr/local/share/man/man1`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: firstArg(cobra.FixedCompletions(nil, cobra.ShellCompDirectiveFilterDirs)),
+		RunE:              runMan,
+	}
+}
+
+func runMan(cmd *cobra.Command, args []string) error {
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+
+	root := cmd.Root()
+	root.DisableAutoGenTag = true // Keeps the pages reproducible
+	escapeUsage(root)
+	header := &doc.GenManHeader{Title: "CDD", Section: "1", Source: "cdd " + Version}
+	if err := doc.GenManTree(root, header, dir); err != nil {
+		return fmt.Errorf("generating man pages: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Wrote man pages to %s\n", dir)
+	return nil
+}
+
+// argumentName matches argument names such as <session-id>, which the man
+// page generator reads as Markdown and would drop as HTML tags.
+var argumentName = regexp.MustCompile(`<([A-Za-z][\w|-]*)>`)
+
+// escapeUsage escapes the usage lines and descriptions of cmd and its
+// subcommands.
+func escapeUsage(cmd *cobra.Command) {
+	cmd.Use = argumentName.ReplaceAllString(cmd.Use, `\<$1\>`)
+	cmd.Long = argumentName.ReplaceAllString(cmd.Long, `\<$1\>`)
+	for _, sub := range cmd.Commands() {
+		escapeUsage(sub)
+	}
+}
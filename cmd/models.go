@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+// newModelsCmd creates the models command group.
+func newModelsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "models",
+		Short: "List models and choose the active ones",
+		Long: `List the models of every connection and choose the large and small models,
+as the models modal does in the TUI. Models are named <connection>/<model>,
+with the connection's name or ID.
+
+Examples:
+  cdd models list                                  List the models of every connection
+  cdd models set large "Work Claude/claude-sonnet-4-5"  Use a model for the main agent
+  cdd models set small openrouter/openai/gpt-4o-mini   Use a model for summaries and sub-agents`,
+		Args: cobra.NoArgs,
+		RunE: runModelsList,
+	}
+
+	cmd.AddCommand(newModelsListCmd())
+	cmd.AddCommand(newModelsSetCmd())
+
+	return cmd
+}
+
+func newModelsListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the models of every connection",
+		Long: `List the models of every connection with their context window and cost
+per million input and output tokens. The active models are marked with their
+tier.`,
+		Args: cobra.NoArgs,
+		RunE: runModelsList,
+	}
+}
+
+func runModelsList(cmd *cobra.Command, _ []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	connections := config.NewConnectionManager(cfg).List()
+	if len(connections) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No connections configured. Run 'cdd' to set one up.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tNAME\tCONTEXT\tINPUT\tOUTPUT\tACTIVE")
+	for i := range connections {
+		conn := &connections[i]
+		for _, model := range cfg.ConnectionModels(conn) {
+			fmt.Fprintf(w, "%s/%s\t%s\t%s\t%s\t%s\t%s\n",
+				conn.Name, model.ID, model.Name,
+				formatContext(model.ContextWindow),
+				formatPrice(model.CostPer1MIn), formatPrice(model.CostPer1MOut),
+				activeTiers(cfg, conn.ID, model.ID))
+		}
+	}
+	return w.Flush()
+}
+
+func newModelsSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <large|small> <connection>/<model>",
+		Short: "Choose the large or small model",
+		Long: `Choose the model of a tier and save it to the global config. The large model
+runs the agent; the small one summarizes history and runs sub-agents.`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeModelRefs,
+		RunE:              runModelsSet,
+	}
+}
+
+func runModelsSet(cmd *cobra.Command, args []string) error {
+	tier := config.SelectedModelType(args[0])
+	if tier != config.SelectedModelTypeLarge && tier != config.SelectedModelTypeSmall {
+		return fmt.Errorf("unknown tier %q: use large or small", args[0])
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	manager := config.NewConnectionManager(cfg)
+	conn, modelID, err := parseModelRef(manager, args[1])
+	if err != nil {
+		return err
+	}
+
+	name := modelID
+	if known := cfg.ConnectionModels(conn); len(known) > 0 {
+		found := false
+		for _, model := range known {
+			if model.ID == modelID {
+				found = true
+				if model.Name != "" {
+					name = model.Name
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("connection %q has no model %q; see 'cdd models list'", conn.Name, modelID)
+		}
+	}
+
+	if err := manager.SetActiveModel(tier, conn.ID, modelID); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "The %s model is now %s on %s\n", tier, name, conn.Name)
+	return nil
+}
+
+// parseModelRef splits <connection>/<model> into a connection, named by name
+// or ID, and a model ID. Model IDs may contain slashes themselves, so the
+// longest connection that prefixes ref wins.
+func parseModelRef(manager *config.ConnectionManager, ref string) (*config.Connection, string, error) {
+	var match *config.Connection
+	var modelID string
+	connections := manager.List()
+	for i := range connections {
+		for _, prefix := range []string{connections[i].Name, connections[i].ID} {
+			rest, ok := strings.CutPrefix(ref, prefix+"/")
+			if ok && rest != "" && (match == nil || len(rest) < len(modelID)) {
+				match, modelID = &connections[i], rest
+			}
+		}
+	}
+	if match == nil {
+		if !strings.Contains(ref, "/") {
+			return nil, "", errors.New("name the model as <connection>/<model>")
+		}
+		return nil, "", fmt.Errorf("no connection matches %q; see 'cdd models list'", ref)
+	}
+	return match, modelID, nil
+}
+
+// activeTiers lists the tiers that use a connection's model.
+func activeTiers(cfg *config.Config, connectionID, modelID string) string {
+	var tiers []string
+	for _, tier := range []config.SelectedModelType{config.SelectedModelTypeLarge, config.SelectedModelTypeSmall} {
+		if selected, ok := cfg.Models[tier]; ok && selected.ConnectionID == connectionID && selected.Model == modelID {
+			tiers = append(tiers, string(tier))
+		}
+	}
+	return strings.Join(tiers, ", ")
+}
+
+// formatContext shows a context window in thousands of tokens.
+func formatContext(tokens int64) string {
+	switch {
+	case tokens <= 0:
+		return "-"
+	case tokens >= 1_000_000:
+		return fmt.Sprintf("%gM", float64(tokens)/1_000_000)
+	default:
+		return fmt.Sprintf("%dK", tokens/1000) //nolint:mnd // Thousands
+	}
+}
+
+// formatPrice shows a cost per million tokens.
+func formatPrice(perMillion float64) string {
+	if perMillion == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("$%.2f", perMillion)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+// newProfilesCmd creates the profiles command group.
+func newProfilesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profiles",
+		Short: "Manage configuration profiles",
+		Long: `Manage configuration profiles. Each profile has its own cdd.json, credentials,
+sessions and data directory, so personal and work setups stay apart.
+
+The profile is chosen by --profile, then CDD_PROFILE, then the last
+'cdd profiles switch', and is "default" otherwise.
+
+Examples:
+  cdd profiles create work   Create an empty profile
+  cdd --profile work         Run the TUI with it (the setup wizard starts first)
+  cdd profiles switch work   Use it from now on
+  cdd profiles list          List profiles, marking the active one`,
+		// Profiles are managed here, so the selected one need not exist yet.
+		PersistentPreRunE: func(*cobra.Command, []string) error { return nil },
+	}
+
+	cmd.AddCommand(newProfilesListCmd())
+	cmd.AddCommand(newProfilesCreateCmd())
+	cmd.AddCommand(newProfilesSwitchCmd())
+
+	return cmd
+}
+
+// newProfilesListCmd lists the profiles.
+func newProfilesListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List profiles",
+		Args:  cobra.NoArgs,
+		RunE:  runProfilesList,
+	}
+}
+
+// runProfilesList executes the profiles list command.
+func runProfilesList(cmd *cobra.Command, _ []string) error {
+	names, err := config.ListProfiles()
+	if err != nil {
+		return err
+	}
+
+	flag, err := cmd.Flags().GetString("profile")
+	if err != nil {
+		return fmt.Errorf("getting profile flag: %w", err)
+	}
+	active := config.ResolveProfile(flag)
+
+	for _, name := range names {
+		marker := "  "
+		if name == active {
+			marker = "* "
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), marker+name)
+	}
+	return nil
+}
+
+// newProfilesCreateCmd creates a profile.
+func newProfilesCreateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a profile",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runProfilesCreate,
+	}
+}
+
+// runProfilesCreate executes the profiles create command.
+func runProfilesCreate(cmd *cobra.Command, args []string) error {
+	if err := config.CreateProfile(args[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Created profile %q. Run 'cdd --profile %s' to set it up.\n", args[0], args[0])
+	return nil
+}
+
+// newProfilesSwitchCmd changes the default profile.
+func newProfilesSwitchCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "switch <name>",
+		Short: "Use a profile by default",
+		Long:  `Use a profile whenever neither --profile nor CDD_PROFILE is given.`,
+		Args:  cobra.ExactArgs(1),
+		RunE:  runProfilesSwitch,
+	}
+}
+
+// runProfilesSwitch executes the profiles switch command.
+func runProfilesSwitch(cmd *cobra.Command, args []string) error {
+	if err := config.SwitchProfile(args[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Switched to profile %q.\n", args[0])
+	return nil
+}
@@ -2,18 +2,24 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/provider"
 )
 
 // newProvidersCmd creates the providers command group.
@@ -29,13 +35,16 @@ enterprise LLMs that are not available through the default catwalk service.
 Examples:
   cdd providers list              List all providers (catwalk + custom)
   cdd providers show <provider-id> Show provider details
-  cdd providers add               Add a custom provider interactively
+  cdd providers add --id gw --endpoint <url> --model <id>  Add a custom provider from flags
   cdd providers add-template ollama  Add from a pre-built template
   cdd providers add-file providers.json  Import from file
   cdd providers add-url <url>      Import from URL
+  cdd providers add-litellm http://localhost:4000  Import the models of a LiteLLM proxy
+  cdd providers edit my-provider --endpoint http://gpu-box:8000/v1  Change a custom provider
   cdd providers remove my-provider  Remove a custom provider
   cdd providers export providers.json  Export custom providers to file
   cdd providers validate           Validate custom provider configurations
+  cdd providers test ollama        Check that a provider endpoint works
   cdd providers templates           List available templates`,
 	}
 
@@ -45,9 +54,12 @@ Examples:
 	cmd.AddCommand(newProvidersAddTemplateCmd())
 	cmd.AddCommand(newProvidersAddFileCmd())
 	cmd.AddCommand(newProvidersAddURLCmd())
+	cmd.AddCommand(newProvidersAddLiteLLMCmd())
+	cmd.AddCommand(newProvidersEditCmd())
 	cmd.AddCommand(newProvidersRemoveCmd())
 	cmd.AddCommand(newProvidersExportCmd())
 	cmd.AddCommand(newProvidersValidateCmd())
+	cmd.AddCommand(newProvidersTestCmd())
 	cmd.AddCommand(newProvidersTemplatesCmd())
 
 	return cmd
@@ -155,11 +167,12 @@ func runProvidersList(cmd *cobra.Command, args []string) error {
 // newProvidersShowCmd shows details of a specific provider.
 func newProvidersShowCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "show <provider-id>",
-		Short: "Show provider details",
-		Long:  `Show detailed information about a specific provider including its models.`,
-		Args:  cobra.ExactArgs(1),
-		RunE:  runProvidersShow,
+		Use:               "show <provider-id>",
+		Short:             "Show provider details",
+		Long:              `Show detailed information about a specific provider including its models.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: firstArg(completeProviderIDs(false)),
+		RunE:              runProvidersShow,
 	}
 
 	return cmd
@@ -239,37 +252,176 @@ func runProvidersShow(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// newProvidersAddCmd adds a new custom provider interactively.
+// newProvidersAddCmd adds a new custom provider from flags.
 func newProvidersAddCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "add",
 		Short: "Add a custom provider",
-		Long:  `Add a new custom provider through an interactive wizard.`,
-		RunE:  runProvidersAdd,
+		Long: `Add a custom provider defined entirely by flags, so provisioning scripts
+can configure CDD without templates or the TUI. Without flags, lists the
+other ways to add a provider.
+
+Models are given as id[:name[:context_window[:max_tokens]]]; omitted fields
+fall back to the model ID and default limits. Model IDs containing a colon
+need all five fields, e.g. qwen2.5:7b:::. The first model is the default
+large and small model unless --large-model or --small-model says otherwise.
+
+Examples:
+  cdd providers add --id gateway --name "Gateway" --endpoint https://llm.example.com/v1 \
+    --header "X-Team=platform" --model gpt-4o:GPT-4o:128000:16384 --model gpt-4o-mini
+  cdd providers add --id claude-proxy --type anthropic --endpoint https://proxy.example.com \
+    --model claude-sonnet-4:Sonnet:200000:64000`,
+		RunE: runProvidersAdd,
 	}
 
+	cmd.Flags().String("id", "", "Provider ID")
+	cmd.Flags().String("name", "", "Provider name (defaults to the ID)")
+	cmd.Flags().String("type", string(catwalk.TypeOpenAICompat), "Provider type")
+	cmd.Flags().String("endpoint", "", "API endpoint URL")
+	cmd.Flags().StringSlice("header", []string{}, "Default header (format: key=value)")
+	cmd.Flags().StringArray("model", []string{}, "Model (format: id[:name[:context_window[:max_tokens]]])")
+	cmd.Flags().String("large-model", "", "Default large model ID (defaults to the first model)")
+	cmd.Flags().String("small-model", "", "Default small model ID (defaults to the first model)")
+
 	return cmd
 }
 
 // runProvidersAdd executes the providers add command.
 func runProvidersAdd(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	if flags.NFlag() == 0 {
+		printAddMethods()
+		return nil
+	}
+	if !flags.Changed("id") {
+		return fmt.Errorf("--id is required")
+	}
+
+	cfg, err := config.LoadWithoutModels()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	customProvider, err := customProviderFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	result := config.ValidateCustomProvider(&customProvider, getExistingProviderIDs(cfg))
+	if !result.IsValid {
+		fmt.Printf("Validation failed:\n")
+		for _, e := range result.Errors {
+			fmt.Printf("  - %s\n", e)
+		}
+		return fmt.Errorf("provider validation failed")
+	}
+	for _, w := range result.WarningStrings() {
+		fmt.Printf("Warning: %s\n", w)
+	}
+
+	loader := config.NewProviderLoader(cfg.DataDir())
+	manager := loader.GetCustomProviderManager()
+	if err := manager.Add(customProvider); err != nil {
+		return fmt.Errorf("adding provider: %w", err)
+	}
+
+	fmt.Printf("Added provider: %s (%s) with %d model(s)\n", customProvider.Name, customProvider.ID, len(customProvider.Models))
+	fmt.Printf("\nNext steps:\n")
+	fmt.Printf("  1. Set your API key in cdd.json under providers.%s.api_key\n", customProvider.ID)
+	fmt.Printf("  2. Check the connection: cdd providers test %s\n", customProvider.ID)
+
+	return nil
+}
+
+// customProviderFromFlags builds a provider from the add command's flags.
+func customProviderFromFlags(cmd *cobra.Command) (config.CustomProvider, error) {
+	flags := cmd.Flags()
+	id, _ := flags.GetString("id")                  //nolint:errcheck // Flag is defined.
+	name, _ := flags.GetString("name")              //nolint:errcheck // Flag is defined.
+	providerType, _ := flags.GetString("type")      //nolint:errcheck // Flag is defined.
+	endpoint, _ := flags.GetString("endpoint")      //nolint:errcheck // Flag is defined.
+	headers, _ := flags.GetStringSlice("header")    //nolint:errcheck // Flag is defined.
+	modelSpecs, _ := flags.GetStringArray("model")  //nolint:errcheck // Flag is defined.
+	largeModel, _ := flags.GetString("large-model") //nolint:errcheck // Flag is defined.
+	smallModel, _ := flags.GetString("small-model") //nolint:errcheck // Flag is defined.
+
+	if name == "" {
+		name = id
+	}
+	customProvider := config.CustomProvider{
+		ID:          id,
+		Name:        name,
+		Type:        catwalk.Type(providerType),
+		APIEndpoint: endpoint,
+	}
+
+	if len(headers) > 0 {
+		customProvider.DefaultHeaders = make(map[string]string, len(headers))
+		for _, h := range headers {
+			key, value, ok := strings.Cut(h, "=")
+			if !ok || strings.TrimSpace(key) == "" {
+				return config.CustomProvider{}, fmt.Errorf("invalid header %q, want key=value", h)
+			}
+			customProvider.DefaultHeaders[key] = value
+		}
+	}
+
+	for _, spec := range modelSpecs {
+		model, err := config.ParseModelSpec(spec)
+		if err != nil {
+			return config.CustomProvider{}, err //nolint:wrapcheck // Already describes the failure
+		}
+		if model.ContextWindow == 0 {
+			model.ContextWindow = provider.DiscoveredContextWindow
+		}
+		if model.DefaultMaxTokens == 0 {
+			model.DefaultMaxTokens = provider.DiscoveredMaxTokens
+		}
+		customProvider.Models = append(customProvider.Models, model)
+	}
+
+	for _, tier := range []struct {
+		flag  string
+		value string
+		id    *string
+	}{
+		{"large-model", largeModel, &customProvider.DefaultLargeModelID},
+		{"small-model", smallModel, &customProvider.DefaultSmallModelID},
+	} {
+		switch {
+		case tier.value != "":
+			if !hasModel(customProvider.Models, tier.value) {
+				return config.CustomProvider{}, fmt.Errorf("--%s %q is not one of the --model IDs", tier.flag, tier.value)
+			}
+			*tier.id = tier.value
+		case len(customProvider.Models) > 0:
+			*tier.id = customProvider.Models[0].ID
+		}
+	}
+
+	return customProvider, nil
+}
+
+// printAddMethods lists the ways to add a custom provider.
+func printAddMethods() {
 	fmt.Println("To add a custom provider, use one of the following methods:")
 	fmt.Println()
-	fmt.Println("1. Use a pre-built template:")
+	fmt.Println("1. Pass its definition as flags (see cdd providers add --help):")
+	fmt.Println("   cdd providers add --id gateway --endpoint https://llm.example.com/v1 --model gpt-4o")
+	fmt.Println()
+	fmt.Println("2. Use a pre-built template:")
 	fmt.Println("   cdd providers templates")
 	fmt.Println("   cdd providers add-template ollama")
 	fmt.Println()
-	fmt.Println("2. Import from a JSON file:")
+	fmt.Println("3. Import from a JSON file:")
 	fmt.Println("   cdd providers add-file ./my-providers.json")
 	fmt.Println()
-	fmt.Println("3. Import from a URL:")
+	fmt.Println("4. Import from a URL:")
 	fmt.Println("   cdd providers add-url https://example.com/providers.json")
 	fmt.Println()
-	fmt.Println("4. Use the interactive setup wizard:")
+	fmt.Println("5. Use the interactive setup wizard:")
 	fmt.Println("   cdd")
 	fmt.Println("   (select '➕ Add Custom Provider')")
-
-	return nil
 }
 
 // newProvidersAddTemplateCmd adds a provider from a template.
@@ -280,13 +432,15 @@ func newProvidersAddTemplateCmd() *cobra.Command {
 		Long: `Add a custom provider from a pre-built template.
 
 Available templates: ollama, lmstudio, openrouter, together, deepseek, groq, anthropic-compatible, azure-openai, vertexai`,
-		Args: cobra.ExactArgs(1),
-		RunE: runProvidersAddTemplate,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: firstArg(completeTemplates),
+		RunE:              runProvidersAddTemplate,
 	}
 
 	cmd.Flags().String("id", "", "Custom provider ID (defaults to template ID)")
 	cmd.Flags().String("name", "", "Custom provider name (defaults to template name)")
 	cmd.Flags().StringSlice("var", []string{}, "Template variables (format: key=value)")
+	cmd.Flags().Bool("discover", false, "Fetch the model list from the endpoint's /models API")
 
 	return cmd
 }
@@ -295,7 +449,7 @@ Available templates: ollama, lmstudio, openrouter, together, deepseek, groq, ant
 func runProvidersAddTemplate(cmd *cobra.Command, args []string) error {
 	templateName := args[0]
 
-	cfg, err := config.Load()
+	cfg, err := config.LoadWithoutModels()
 	if err != nil {
 		return fmt.Errorf("loading config: %w", err)
 	}
@@ -310,6 +464,7 @@ func runProvidersAddTemplate(cmd *cobra.Command, args []string) error {
 	customID, _ := cmd.Flags().GetString("id")        //nolint:errcheck // Flag is defined.
 	customName, _ := cmd.Flags().GetString("name")    //nolint:errcheck // Flag is defined.
 	varValues, _ := cmd.Flags().GetStringSlice("var") //nolint:errcheck // Flag is defined.
+	discover, _ := cmd.Flags().GetBool("discover")    //nolint:errcheck // Flag is defined.
 
 	// Parse variable values.
 	vars := make(map[string]string)
@@ -330,6 +485,12 @@ func runProvidersAddTemplate(cmd *cobra.Command, args []string) error {
 	// Create provider from template.
 	customProvider := template.ToCustomProvider(vars, customID, customName)
 
+	if discover {
+		if err := discoverTemplateModels(cmd.Context(), &customProvider); err != nil {
+			return err
+		}
+	}
+
 	// Validate the provider.
 	existingProviders := getExistingProviderIDs(cfg)
 	result := config.ValidateCustomProvider(&customProvider, existingProviders)
@@ -352,6 +513,19 @@ func runProvidersAddTemplate(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Added provider: %s (%s)\n", customProvider.Name, customProvider.ID)
 	fmt.Printf("\nNext steps:\n")
+	if customProvider.Type == catwalk.TypeVertexAI {
+		fmt.Printf("  1. Authenticate with Google Cloud:\n")
+		fmt.Printf("     gcloud auth application-default login\n")
+		fmt.Printf("  2. Set the project and location in cdd.json under providers.%s.provider_options:\n", customProvider.ID)
+		fmt.Printf("     {\"project\": %q, \"location\": %q}\n", vars["project"], vars["location"])
+		return nil
+	}
+	if customProvider.Type == catwalk.TypeAzure {
+		fmt.Printf("  1. Set your API key in cdd.json under providers.%s.api_key\n", customProvider.ID)
+		fmt.Printf("  2. Set the API version and deployment names under providers.%s.provider_options:\n", customProvider.ID)
+		fmt.Printf("     {\"api_version\": %q, \"deployments\": {\"gpt-4o\": \"<deployment-name>\"}}\n", vars["api_version"])
+		return nil
+	}
 	fmt.Printf("  1. Set your API key in cdd.json:\n")
 	fmt.Printf("     cdd providers show %s\n", customProvider.ID)
 	fmt.Printf("  2. Or set environment variable and run cdd\n")
@@ -359,16 +533,67 @@ func runProvidersAddTemplate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// discoverTemplateModels replaces a provider's models with those served by its endpoint.
+func discoverTemplateModels(ctx context.Context, customProvider *config.CustomProvider) error {
+	if !provider.SupportsDiscovery(customProvider.Type) {
+		return fmt.Errorf("--discover is only supported for OpenAI-compatible providers, not %q", customProvider.Type)
+	}
+
+	endpoint := customProvider.APIEndpoint
+	if endpoint == "" {
+		endpoint = customProvider.BaseURL
+	}
+
+	fmt.Printf("Discovering models from %s...\n", endpoint)
+	discovered, err := provider.DiscoverModels(ctx, endpoint, "", customProvider.DefaultHeaders)
+	if err != nil {
+		return fmt.Errorf("discovering models: %w", err)
+	}
+
+	customProvider.Models = provider.MergeModels(discovered, customProvider.Models)
+	fmt.Printf("Found %d model(s)\n\n", len(customProvider.Models))
+
+	// Keep the template defaults only if the endpoint serves them.
+	if !hasModel(customProvider.Models, customProvider.DefaultLargeModelID) {
+		customProvider.DefaultLargeModelID = customProvider.Models[0].ID
+	}
+	if !hasModel(customProvider.Models, customProvider.DefaultSmallModelID) {
+		customProvider.DefaultSmallModelID = customProvider.Models[0].ID
+	}
+	return nil
+}
+
+// hasModel reports whether models contains a model with the given ID.
+func hasModel(models []catwalk.Model, id string) bool {
+	for i := range models {
+		if models[i].ID == id {
+			return true
+		}
+	}
+	return false
+}
+
 // newProvidersAddFileCmd adds providers from a JSON file.
 func newProvidersAddFileCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "add-file <file-path>",
 		Short: "Add providers from a JSON file",
-		Long:  `Import custom providers from a JSON file. The file should match the custom-providers.json format.`,
-		Args:  cobra.ExactArgs(1),
-		RunE:  runProvidersAddFile,
+		Long: `Import custom providers from a JSON file. The file should match the custom-providers.json format.
+
+A provider whose ID is already taken is skipped, replaced with
+--on-conflict overwrite, or added under a free ID such as my-proxy-2 with
+--on-conflict rename. Built-in providers are never replaced. Each provider
+is listed with what happened to it and, when replaced, what changed.
+
+Examples:
+  cdd providers add-file team.json
+  cdd providers add-file team.json --on-conflict overwrite`,
+		Args: cobra.ExactArgs(1),
+		RunE: runProvidersAddFile,
 	}
 
+	addConflictFlag(cmd)
+
 	return cmd
 }
 
@@ -376,31 +601,24 @@ func newProvidersAddFileCmd() *cobra.Command {
 func runProvidersAddFile(cmd *cobra.Command, args []string) error {
 	filePath := args[0]
 
-	cfg, err := config.Load()
+	onConflict, err := conflictFlag(cmd)
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return err
 	}
 
-	// Read the file.
-	data, err := os.ReadFile(filePath) //nolint:gosec // G304: User-provided file path is expected for CLI import command
+	cfg, err := config.LoadWithoutModels()
 	if err != nil {
-		return fmt.Errorf("reading file: %w", err)
-	}
-
-	// Parse the JSON.
-	var file config.CustomProvidersFile
-	if err := json.Unmarshal(data, &file); err != nil {
-		return fmt.Errorf("parsing JSON: %w", err)
+		return fmt.Errorf("loading config: %w", err)
 	}
 
-	if len(file.Providers) == 0 {
-		return fmt.Errorf("no providers found in file")
+	file, err := config.ReadCustomProviders(cmd.Context(), filePath)
+	if err != nil {
+		return err //nolint:wrapcheck // Already describes the failure
 	}
 
-	addedCount := importProvidersFromFile(file, cfg)
-	fmt.Printf("\nAdded %d provider(s) from %s\n", addedCount, filePath)
-
-	return nil
+	fmt.Printf("Importing providers from %s:\n", filePath)
+	_, err = importProvidersFromFile(*file, cfg, onConflict)
+	return err
 }
 
 // newProvidersAddURLCmd adds providers from a URL.
@@ -408,11 +626,14 @@ func newProvidersAddURLCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "add-url <url>",
 		Short: "Add providers from a URL",
-		Long:  `Import custom providers from a URL that serves a providers.json file.`,
-		Args:  cobra.ExactArgs(1),
-		RunE:  runProvidersAddURL,
+		Long: `Import custom providers from a URL that serves a providers.json file.
+IDs already taken are settled with --on-conflict, as for add-file.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runProvidersAddURL,
 	}
 
+	addConflictFlag(cmd)
+
 	return cmd
 }
 
@@ -420,46 +641,269 @@ func newProvidersAddURLCmd() *cobra.Command {
 func runProvidersAddURL(cmd *cobra.Command, args []string) error {
 	url := args[0]
 
-	cfg, err := config.Load()
+	onConflict, err := conflictFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadWithoutModels()
 	if err != nil {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
 	fmt.Printf("Fetching providers from %s...\n", url)
 
-	// Fetch the URL.
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, http.NoBody) //nolint:gosec // URL is user-provided, expected behavior.
+	file, err := config.ReadCustomProviders(cmd.Context(), url)
+	if err != nil {
+		return err //nolint:wrapcheck // Already describes the failure
+	}
+
+	_, err = importProvidersFromFile(*file, cfg, onConflict)
+	return err
+}
+
+// addConflictFlag adds --on-conflict to an import command.
+func addConflictFlag(cmd *cobra.Command) {
+	cmd.Flags().String("on-conflict", config.ConflictSkip,
+		"What to do with a provider whose ID is taken: "+strings.Join(config.ConflictStrategies, ", "))
+}
+
+// conflictFlag reads and checks --on-conflict.
+func conflictFlag(cmd *cobra.Command) (string, error) {
+	onConflict, _ := cmd.Flags().GetString("on-conflict") //nolint:errcheck // Flag is defined.
+	if !slices.Contains(config.ConflictStrategies, onConflict) {
+		return "", fmt.Errorf("invalid --on-conflict %q: use %s", onConflict, strings.Join(config.ConflictStrategies, ", "))
+	}
+	return onConflict, nil
+}
+
+// newProvidersAddLiteLLMCmd adds a provider for a LiteLLM proxy.
+func newProvidersAddLiteLLMCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-litellm <config.yaml|url>",
+		Short: "Add a provider for a LiteLLM proxy",
+		Long: `Add an OpenAI-compatible provider for a LiteLLM proxy, with a model for
+each model_name it serves.
+
+The models are read from the proxy's config.yaml, or fetched from the
+/model/info endpoint of the proxy at the given URL. Context windows and
+costs are taken from model_info where set.
+
+Examples:
+  cdd providers add-litellm http://localhost:4000 --api-key sk-1234
+  cdd providers add-litellm litellm/config.yaml --base-url https://llm.example.com`,
+		Args: cobra.ExactArgs(1),
+		RunE: runProvidersAddLiteLLM,
+	}
+
+	cmd.Flags().String("id", "litellm", "Provider ID")
+	cmd.Flags().String("name", "LiteLLM", "Provider name")
+	cmd.Flags().String("base-url", "http://localhost:4000", "URL of the proxy, when importing a config file")
+	cmd.Flags().String("api-key", "", "Key for the /model/info endpoint (not saved)")
+
+	return cmd
+}
+
+// runProvidersAddLiteLLM executes the add-litellm command.
+func runProvidersAddLiteLLM(cmd *cobra.Command, args []string) error {
+	source := args[0]
+
+	cfg, err := config.LoadWithoutModels()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	id, _ := cmd.Flags().GetString("id")            //nolint:errcheck // Flag is defined.
+	name, _ := cmd.Flags().GetString("name")        //nolint:errcheck // Flag is defined.
+	baseURL, _ := cmd.Flags().GetString("base-url") //nolint:errcheck // Flag is defined.
+	apiKey, _ := cmd.Flags().GetString("api-key")   //nolint:errcheck // Flag is defined.
+
+	var models []catwalk.Model
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		baseURL = strings.TrimSuffix(strings.TrimSuffix(source, "/"), "/model/info")
+		models, err = fetchLiteLLMModels(cmd.Context(), baseURL, apiKey)
+	} else {
+		var data []byte
+		data, err = os.ReadFile(source) //nolint:gosec // G304: User-provided file path is expected for CLI import command
+		if err != nil {
+			return fmt.Errorf("reading file: %w", err)
+		}
+		models, err = config.ParseLiteLLMConfig(data)
+	}
+	if err != nil {
+		return err
+	}
+
+	for i := range models {
+		if models[i].ContextWindow == 0 {
+			models[i].ContextWindow = provider.DiscoveredContextWindow
+		}
+		if models[i].DefaultMaxTokens == 0 {
+			models[i].DefaultMaxTokens = provider.DiscoveredMaxTokens
+		}
+	}
+
+	customProvider := config.LiteLLMProvider(id, name, baseURL, models)
+	file := config.CustomProvidersFile{Providers: []config.CustomProvider{customProvider}}
+	added, err := importProvidersFromFile(file, cfg, config.ConflictSkip)
 	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		return err
+	}
+	if added == 0 {
+		return fmt.Errorf("provider %s was not added", id)
+	}
+
+	fmt.Printf("\n%d model(s) at %s\n", len(models), customProvider.APIEndpoint)
+	fmt.Printf("\nNext steps:\n")
+	fmt.Printf("  1. Set the proxy key in cdd.json under providers.%s.api_key\n", id)
+	fmt.Printf("  2. Choose the models to use:\n")
+	fmt.Printf("     cdd models set large %s/%s\n", id, models[0].ID)
+
+	return nil
+}
+
+// fetchLiteLLMModels fetches the models served by the LiteLLM proxy at baseURL.
+func fetchLiteLLMModels(ctx context.Context, baseURL, apiKey string) ([]catwalk.Model, error) {
+	url := baseURL + "/model/info"
+	fmt.Printf("Fetching models from %s...\n", url)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody) //nolint:gosec // URL is user-provided, expected behavior.
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
 	}
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("fetching URL: %w", err)
+		return nil, fmt.Errorf("fetching URL: %w", err)
 	}
 	defer resp.Body.Close() //nolint:errcheck // Error on close is not actionable.
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
 	}
 
-	// Read and parse the response.
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("reading response: %w", err)
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	return config.ParseLiteLLMModelInfo(data)
+}
+
+// newProvidersEditCmd changes a custom provider.
+func newProvidersEditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit <provider-id>",
+		Short: "Edit a custom provider",
+		Long: `Change the name, endpoint, headers or default models of a custom provider.
+Only the given flags change; an empty header value removes the header.
+
+The provider can also be edited in the TUI: open the models modal and press
+p on a connection to the provider.
+
+Examples:
+  cdd providers edit ollama --endpoint http://gpu-box:11434/v1
+  cdd providers edit gateway --header "X-Team=platform" --header "X-Old="
+  cdd providers edit ollama --large-model qwen2.5:32b --small-model qwen2.5:7b`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: firstArg(completeProviderIDs(true)),
+		RunE:              runProvidersEdit,
+	}
+
+	cmd.Flags().String("name", "", "Provider name")
+	cmd.Flags().String("endpoint", "", "API endpoint URL")
+	cmd.Flags().StringSlice("header", []string{}, "Default header to set (format: key=value, empty value removes it)")
+	cmd.Flags().String("large-model", "", "Default large model ID")
+	cmd.Flags().String("small-model", "", "Default small model ID")
+
+	return cmd
+}
+
+// runProvidersEdit executes the providers edit command.
+func runProvidersEdit(cmd *cobra.Command, args []string) error {
+	providerID := args[0]
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	loader := config.NewProviderLoader(cfg.DataDir())
+	manager := loader.GetCustomProviderManager()
+
+	existing, err := manager.Get(providerID)
+	if err != nil {
+		return fmt.Errorf("custom provider %q not found", providerID)
+	}
+	customProvider := *existing
+
+	flags := cmd.Flags()
+	if !flags.Changed("name") && !flags.Changed("endpoint") && !flags.Changed("header") &&
+		!flags.Changed("large-model") && !flags.Changed("small-model") {
+		return fmt.Errorf("nothing to change: pass --name, --endpoint, --header, --large-model or --small-model")
 	}
 
-	var file config.CustomProvidersFile
-	if err := json.Unmarshal(data, &file); err != nil {
-		return fmt.Errorf("parsing JSON: %w", err)
+	if flags.Changed("name") {
+		customProvider.Name, _ = flags.GetString("name") //nolint:errcheck // Flag is defined.
+	}
+	if flags.Changed("endpoint") {
+		customProvider.APIEndpoint, _ = flags.GetString("endpoint") //nolint:errcheck // Flag is defined.
+		customProvider.BaseURL = ""
 	}
 
-	if len(file.Providers) == 0 {
-		return fmt.Errorf("no providers found at URL")
+	headers, _ := flags.GetStringSlice("header") //nolint:errcheck // Flag is defined.
+	if len(headers) > 0 {
+		updated := make(map[string]string, len(customProvider.DefaultHeaders)+len(headers))
+		for k, v := range customProvider.DefaultHeaders {
+			updated[k] = v
+		}
+		for _, h := range headers {
+			key, value, ok := strings.Cut(h, "=")
+			if !ok || strings.TrimSpace(key) == "" {
+				return fmt.Errorf("invalid header %q, want key=value", h)
+			}
+			if value == "" {
+				delete(updated, key)
+			} else {
+				updated[key] = value
+			}
+		}
+		customProvider.DefaultHeaders = updated
+	}
+
+	for _, tier := range []struct {
+		flag string
+		id   *string
+	}{
+		{"large-model", &customProvider.DefaultLargeModelID},
+		{"small-model", &customProvider.DefaultSmallModelID},
+	} {
+		if !flags.Changed(tier.flag) {
+			continue
+		}
+		modelID, _ := flags.GetString(tier.flag) //nolint:errcheck // Flag is defined.
+		if !hasModel(customProvider.Models, modelID) {
+			return fmt.Errorf("provider %s has no model %q", providerID, modelID)
+		}
+		*tier.id = modelID
+	}
+
+	// The provider keeps its ID, so only check the definition itself.
+	result := config.ValidateCustomProvider(&customProvider, nil)
+	if !result.IsValid {
+		fmt.Printf("Validation failed:\n")
+		for _, e := range result.Errors {
+			fmt.Printf("  - %s\n", e)
+		}
+		return fmt.Errorf("provider validation failed")
 	}
 
-	addedCount := importProvidersFromFile(file, cfg)
-	fmt.Printf("\nAdded %d provider(s) from URL\n", addedCount)
+	if err := manager.Update(providerID, customProvider); err != nil {
+		return fmt.Errorf("updating provider: %w", err)
+	}
 
+	fmt.Printf("Updated provider: %s (%s)\n", customProvider.Name, customProvider.ID)
 	return nil
 }
 
@@ -468,11 +912,19 @@ func newProvidersRemoveCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "remove <provider-id>",
 		Short: "Remove a custom provider",
-		Long:  `Remove a custom provider by its ID.`,
-		Args:  cobra.ExactArgs(1),
-		RunE:  runProvidersRemove,
+		Long: `Remove a custom provider by its ID.
+
+Connections to the provider, the large or small model they serve and its
+settings in cdd.json would be left pointing at nothing, so removal asks
+whether to reassign them to another provider or remove them too. Pass
+--cascade to remove them without asking, e.g. from scripts.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: firstArg(completeProviderIDs(true)),
+		RunE:              runProvidersRemove,
 	}
 
+	cmd.Flags().Bool("cascade", false, "Also remove the connections, model selections and settings that use the provider")
+
 	return cmd
 }
 
@@ -493,35 +945,163 @@ func runProvidersRemove(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("custom provider %q not found", providerID)
 	}
 
+	// Deal with what refers to the provider before it goes.
+	if usage := cfg.ProviderUsage(providerID); !usage.Empty() {
+		cmd.SilenceUsage = true
+		if err := resolveProviderUsage(cmd, cfg, loader, providerID, usage); err != nil {
+			return err
+		}
+	}
+
 	// Remove the provider.
 	if err := manager.Remove(providerID); err != nil {
 		return fmt.Errorf("removing provider: %w", err)
 	}
 
 	fmt.Printf("Removed custom provider: %s\n", providerID)
-	fmt.Println()
-	fmt.Println("Note: Provider configuration may still exist in cdd.json.")
-	fmt.Println("      Run 'cdd providers list' to see remaining providers.")
+	return nil
+}
+
+// resolveProviderUsage removes the connections, model selections and
+// settings that refer to a provider being removed, or reassigns them to
+// another provider, as --cascade or the user's answer says, and saves the
+// config.
+func resolveProviderUsage(cmd *cobra.Command, cfg *config.Config, loader *config.ProviderLoader, providerID string, usage config.ProviderUsage) error {
+	cascade, _ := cmd.Flags().GetBool("cascade") //nolint:errcheck // Flag is defined.
+	if !cascade && !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("provider %s is still used by %s; pass --cascade to remove them as well",
+			providerID, strings.Join(describeProviderUsage(cfg, providerID, usage), ", "))
+	}
+
+	reassignTo := ""
+	if !cascade {
+		fmt.Printf("Provider %s is used by:\n", providerID)
+		for _, line := range describeProviderUsage(cfg, providerID, usage) {
+			fmt.Printf("  %s\n", line)
+		}
+		in := bufio.NewReader(os.Stdin)
+		answer, err := promptLine(in, "[r]eassign them to another provider, [p]urge them, or [c]ancel? ")
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(answer) {
+		case "r", "reassign":
+			reassignTo, err = promptLine(in, "Provider to use instead: ")
+			if err != nil {
+				return err
+			}
+		case "p", "purge":
+		default:
+			return errors.New("removal cancelled")
+		}
+	}
+
+	if reassignTo == "" {
+		removed := describeProviderUsage(cfg, providerID, usage)
+		cfg.PurgeProvider(providerID)
+		if err := config.Save(cfg); err != nil {
+			return fmt.Errorf("saving config: %w", err)
+		}
+		for _, line := range removed {
+			fmt.Printf("Removed %s\n", line)
+		}
+		if len(usage.Tiers) > 0 {
+			fmt.Println("Choose new models with 'cdd models set'.")
+		}
+		return nil
+	}
 
+	target, err := findProvider(cfg, loader, reassignTo)
+	if err != nil {
+		return err
+	}
+	if string(target.ID) == providerID {
+		return errors.New("choose a provider other than the one being removed")
+	}
+	cfg.ReassignProvider(providerID, reassignTo)
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+	for i := range usage.Connections {
+		fmt.Printf("Connection %q now uses %s\n", usage.Connections[i].Name, reassignTo)
+	}
+	for _, tier := range usage.Tiers {
+		model := cfg.Models[tier].Model
+		if !slices.ContainsFunc(target.Models, func(m catwalk.Model) bool { return m.ID == model }) {
+			fmt.Printf("%s does not offer the %s model %s; choose another with 'cdd models set %s'\n", reassignTo, tier, model, tier)
+		}
+	}
 	return nil
 }
 
+// describeProviderUsage lists what refers to a provider, one item per line.
+func describeProviderUsage(cfg *config.Config, providerID string, usage config.ProviderUsage) []string {
+	var lines []string
+	for i := range usage.Connections {
+		lines = append(lines, fmt.Sprintf("connection %q", usage.Connections[i].Name))
+	}
+	for _, tier := range usage.Tiers {
+		lines = append(lines, fmt.Sprintf("the %s model (%s)", tier, cfg.Models[tier].Model))
+	}
+	if usage.Settings {
+		lines = append(lines, "providers."+providerID+" in cdd.json")
+	}
+	return lines
+}
+
+// findProvider returns the known or custom provider with the given ID.
+func findProvider(cfg *config.Config, loader *config.ProviderLoader, providerID string) (catwalk.Provider, error) {
+	providers, err := loader.LoadAllProviders(cfg)
+	if err != nil {
+		return catwalk.Provider{}, fmt.Errorf("loading providers: %w", err)
+	}
+	for i := range providers {
+		if string(providers[i].ID) == providerID {
+			return providers[i], nil
+		}
+	}
+	return catwalk.Provider{}, fmt.Errorf("provider %q not found; see 'cdd providers list'", providerID)
+}
+
+// promptLine prints a question on stderr and reads the answer.
+func promptLine(in *bufio.Reader, question string) (string, error) {
+	fmt.Fprint(os.Stderr, question)
+	answer, err := in.ReadString('\n')
+	if err != nil && answer == "" {
+		return "", fmt.Errorf("reading answer: %w", err)
+	}
+	return strings.TrimSpace(answer), nil
+}
+
 // newProvidersExportCmd exports custom providers to a file.
 func newProvidersExportCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "export [output-file]",
 		Short: "Export custom providers to a file",
-		Long:  `Export custom providers to a JSON file that can be shared or imported elsewhere.`,
-		Args:  cobra.ExactArgs(1),
-		RunE:  runProvidersExport,
+		Long: `Export custom providers to a JSON file that can be shared or imported elsewhere.
+
+Credentials in provider headers are replaced with environment variable
+placeholders (e.g. "Bearer $MY_PROXY_API_KEY"). Use --include-secrets to
+export them as they are.
+
+Examples:
+  cdd providers export providers.json
+  cdd providers export team.json --only my-proxy,ollama-gpu`,
+		Args: cobra.ExactArgs(1),
+		RunE: runProvidersExport,
 	}
 
+	cmd.Flags().Bool("include-secrets", false, "Export header credentials instead of placeholders")
+	cmd.Flags().StringSlice("only", nil, "Export only these provider IDs (comma-separated)")
+
 	return cmd
 }
 
 // runProvidersExport executes the providers export command.
 func runProvidersExport(cmd *cobra.Command, args []string) error {
 	outputPath := args[0]
+	includeSecrets, _ := cmd.Flags().GetBool("include-secrets") //nolint:errcheck // Flag is defined.
+	only, _ := cmd.Flags().GetStringSlice("only")               //nolint:errcheck // Flag is defined.
 
 	cfg, err := config.Load()
 	if err != nil {
@@ -536,30 +1116,70 @@ func runProvidersExport(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("loading custom providers: %w", err)
 	}
 
+	if len(only) > 0 {
+		customProviders, err = selectCustomProviders(customProviders, only)
+		if err != nil {
+			return err
+		}
+	}
+
 	if len(customProviders) == 0 {
 		fmt.Println("No custom providers to export.")
 		return nil
 	}
 
-	// Get the custom providers file path.
-	customProvidersPath := manager.GetFilePath()
+	var placeholders []string
+	perm := os.FileMode(0o644)
+	if includeSecrets {
+		perm = 0o600
+	} else {
+		customProviders, placeholders = config.RedactCustomProviders(customProviders)
+	}
 
-	// Read the current custom providers file.
-	data, err := os.ReadFile(customProvidersPath) //nolint:gosec // G304: Internal path from manager, not user input
+	data, err := json.MarshalIndent(config.CustomProvidersFile{
+		Version:   "1.0",
+		Providers: customProviders,
+	}, "", "  ")
 	if err != nil {
-		return fmt.Errorf("reading custom providers file: %w", err)
+		return fmt.Errorf("marshaling custom providers: %w", err)
 	}
 
-	// Write to the output path.
-	if err := os.WriteFile(outputPath, data, 0o644); err != nil { //nolint:gosec // G306: Export file should be readable by others
+	if err := os.WriteFile(outputPath, data, perm); err != nil {
 		return fmt.Errorf("writing output file: %w", err)
 	}
 
 	fmt.Printf("Exported %d custom provider(s) to: %s\n", len(customProviders), outputPath)
+	if len(placeholders) > 0 {
+		fmt.Printf("Redacted %d credential(s). Set these variables where the file is imported:\n", len(placeholders))
+		for _, p := range placeholders {
+			fmt.Printf("  %s\n", strings.TrimPrefix(p, "$"))
+		}
+	}
 
 	return nil
 }
 
+// selectCustomProviders returns the providers with the given IDs, in the
+// order they are saved, or an error naming the IDs that are not saved.
+func selectCustomProviders(providers []config.CustomProvider, ids []string) ([]config.CustomProvider, error) {
+	selected := make([]config.CustomProvider, 0, len(ids))
+	for i := range providers {
+		if slices.Contains(ids, providers[i].ID) {
+			selected = append(selected, providers[i])
+		}
+	}
+	var unknown []string
+	for _, id := range ids {
+		if !slices.ContainsFunc(selected, func(p config.CustomProvider) bool { return p.ID == id }) {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("no custom provider %s", strings.Join(unknown, ", "))
+	}
+	return selected, nil
+}
+
 // newProvidersValidateCmd validates custom provider configurations.
 func newProvidersValidateCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -638,6 +1258,79 @@ func runProvidersValidate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// newProvidersTestCmd checks connectivity to a provider or connection.
+func newProvidersTestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test <provider-id|connection-id>",
+		Short: "Check that a provider endpoint works",
+		Long: `Check a configured provider or connection by listing its models
+(OpenAI-compatible endpoints such as Ollama and LM Studio) or sending a
+minimal completion. Reports latency, whether the credentials were accepted,
+and whether the model is available.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: firstArg(completeProvidersAndConnections),
+		RunE:              runProvidersTest,
+	}
+
+	cmd.Flags().String("model", "", "Model to check (defaults to the provider's configured model)")
+
+	return cmd
+}
+
+// runProvidersTest executes the providers test command.
+func runProvidersTest(cmd *cobra.Command, args []string) error {
+	model, _ := cmd.Flags().GetString("model") //nolint:errcheck // Flag is defined.
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	fmt.Printf("Testing %s...\n\n", args[0])
+
+	result, err := provider.NewBuilder(cfg).Check(cmd.Context(), args[0], model)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Provider: %s\n", result.ProviderID)
+	if result.ConnectionID != "" {
+		fmt.Printf("Connection: %s\n", result.ConnectionID)
+	}
+	if result.Endpoint != "" {
+		fmt.Printf("Endpoint: %s\n", result.Endpoint)
+	}
+	fmt.Printf("Method: %s\n", result.Method)
+	fmt.Printf("Latency: %s\n", result.Latency.Round(time.Millisecond))
+	fmt.Printf("%s Authentication\n", checkMark(result.AuthValid))
+	fmt.Printf("%s Model %s\n", checkMark(result.ModelAvailable), result.Model)
+	if !result.ModelAvailable && len(result.Models) > 0 {
+		fmt.Printf("\nModels served by this endpoint (%d):\n", len(result.Models))
+		for _, m := range result.Models {
+			fmt.Printf("  %s\n", m)
+		}
+	}
+
+	if !result.OK() {
+		fmt.Println()
+		if result.Err != nil {
+			return fmt.Errorf("health check failed: %w", result.Err)
+		}
+		return fmt.Errorf("health check failed")
+	}
+
+	fmt.Println("\nProvider is healthy.")
+	return nil
+}
+
+// checkMark renders a pass/fail marker.
+func checkMark(ok bool) string {
+	if ok {
+		return "✓"
+	}
+	return "✗"
+}
+
 // newProvidersTemplatesCmd lists available provider templates.
 func newProvidersTemplatesCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -693,41 +1386,59 @@ func getExistingProviderIDs(cfg *config.Config) []string {
 	return ids
 }
 
-// importProvidersFromFile imports providers from a CustomProvidersFile.
-// It validates each provider, skips duplicates, and adds valid providers.
-// Returns the count of providers added.
-func importProvidersFromFile(file config.CustomProvidersFile, cfg *config.Config) int {
-	existingProviders := getExistingProviderIDs(cfg)
+// importProvidersFromFile merges the providers of a CustomProvidersFile into
+// the custom providers, settling IDs already taken with onConflict, and
+// prints what changed. Returns the count of providers added or updated.
+func importProvidersFromFile(file config.CustomProvidersFile, cfg *config.Config, onConflict string) (int, error) {
 	loader := config.NewProviderLoader(cfg.DataDir())
 	manager := loader.GetCustomProviderManager()
 
-	addedCount := 0
-	for i := range file.Providers {
-		// Validate.
-		result := config.ValidateCustomProvider(&file.Providers[i], existingProviders)
-		if !result.IsValid {
-			fmt.Printf("Skipping %s: validation failed\n", file.Providers[i].ID)
-			for _, e := range result.Errors {
-				fmt.Printf("  - %s\n", e)
-			}
-			continue
-		}
+	current, err := manager.Load()
+	if err != nil {
+		return 0, fmt.Errorf("loading custom providers: %w", err)
+	}
 
-		// Add if not duplicate.
-		if manager.Exists(file.Providers[i].ID) {
-			fmt.Printf("Skipping %s: already exists\n", file.Providers[i].ID)
-			continue
+	merged, changes := config.MergeCustomProviders(current, file.Providers, getExistingProviderIDs(cfg), onConflict)
+
+	counts := make(map[string]int)
+	for _, c := range changes {
+		counts[c.Action]++
+	}
+	changed := counts[config.ProviderAdded] + counts[config.ProviderUpdated] + counts[config.ProviderRenamed]
+	if changed > 0 {
+		if err := manager.Save(merged); err != nil {
+			return 0, fmt.Errorf("saving custom providers: %w", err)
 		}
+	}
 
-		if err := manager.Add(file.Providers[i]); err != nil {
-			fmt.Printf("Error adding %s: %v\n", file.Providers[i].ID, err)
-			continue
+	for _, c := range changes {
+		switch c.Action {
+		case config.ProviderAdded:
+			fmt.Printf("  + %s (%s)\n", c.ID, c.Name)
+		case config.ProviderUpdated:
+			fmt.Printf("  ~ %s (%s)\n", c.ID, c.Name)
+		case config.ProviderRenamed:
+			fmt.Printf("  + %s (%s), renamed from %s\n", c.NewID, c.Name, c.ID)
+		case config.ProviderUnchanged:
+			fmt.Printf("  = %s (%s), unchanged\n", c.ID, c.Name)
+		case config.ProviderSkipped:
+			fmt.Printf("  - %s, skipped\n", c.ID)
 		}
+		for _, d := range c.Details {
+			fmt.Printf("      %s\n", d)
+		}
+	}
 
-		existingProviders = append(existingProviders, file.Providers[i].ID)
-		addedCount++
-		fmt.Printf("Added: %s (%s)\n", file.Providers[i].Name, file.Providers[i].ID)
+	summary := make([]string, 0, len(config.ProviderActions))
+	for _, action := range config.ProviderActions {
+		if counts[action] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[action], action))
+		}
+	}
+	if len(summary) == 0 {
+		summary = append(summary, "no providers")
 	}
+	fmt.Printf("\n%s\n", strings.Join(summary, ", "))
 
-	return addedCount
+	return changed, nil
 }
@@ -3,24 +3,42 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"charm.land/fantasy"
-	"github.com/adrg/xdg"
 	"github.com/spf13/cobra"
 
 	"github.com/guilhermegouw/cdd/internal/agent"
 	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/contextfiles"
 	"github.com/guilhermegouw/cdd/internal/db"
 	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/history"
+	"github.com/guilhermegouw/cdd/internal/hooks"
+	"github.com/guilhermegouw/cdd/internal/index"
+	"github.com/guilhermegouw/cdd/internal/journal"
+	"github.com/guilhermegouw/cdd/internal/lsp"
+	"github.com/guilhermegouw/cdd/internal/memory"
 	"github.com/guilhermegouw/cdd/internal/message"
+	"github.com/guilhermegouw/cdd/internal/metrics"
 	"github.com/guilhermegouw/cdd/internal/provider"
 	"github.com/guilhermegouw/cdd/internal/pubsub"
 	"github.com/guilhermegouw/cdd/internal/session"
+	"github.com/guilhermegouw/cdd/internal/telemetry"
+	"github.com/guilhermegouw/cdd/internal/tickets"
 	"github.com/guilhermegouw/cdd/internal/tools"
+	"github.com/guilhermegouw/cdd/internal/transcript"
 	"github.com/guilhermegouw/cdd/internal/tui"
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
+	"github.com/guilhermegouw/cdd/internal/tui/styles"
+	"github.com/guilhermegouw/cdd/internal/usage"
 )
 
 func newRootCmd() *cobra.Command {
@@ -34,17 +52,60 @@ It supports multiple phases of development:
   - Socrates: Clarify requirements through dialogue
   - Planner: Design implementation strategy
   - Executor: Write and modify code`,
-		RunE: runTUI,
+		PersistentPreRunE: applyGlobalFlags,
+		RunE:              runTUI,
 	}
 
+	cmd.PersistentFlags().String("profile", "", "Configuration profile to use (default: $CDD_PROFILE or the switched-to profile)")
+	cmd.PersistentFlags().Bool("offline", false, "Use the cached provider list instead of fetching it (also $CDD_OFFLINE=1)")
 	cmd.Flags().Bool("debug", false, "Enable debug logging to ~/.cdd/debug.log")
+	cmd.Flags().Bool("accessible", false, "Screen reader output: no spinners, box drawing or color-only cues")
 	cmd.AddCommand(newVersionCmd())
 	cmd.AddCommand(newStatusCmd())
 	cmd.AddCommand(newProvidersCmd())
+	cmd.AddCommand(newConnectionsCmd())
+	cmd.AddCommand(newRunCmd())
+	cmd.AddCommand(newSessionsCmd())
+	cmd.AddCommand(newUsageCmd())
+	cmd.AddCommand(newAuthCmd())
+	cmd.AddCommand(newKeysCmd())
+	cmd.AddCommand(newConfigCmd())
+	cmd.AddCommand(newProfilesCmd())
+	cmd.AddCommand(newIndexCmd())
+	cmd.AddCommand(newTrustCmd())
+	cmd.AddCommand(newModelsCmd())
+	cmd.AddCommand(newManCmd())
 
 	return cmd
 }
 
+// applyGlobalFlags applies the persistent flags before any command reads
+// configuration.
+func applyGlobalFlags(cmd *cobra.Command, args []string) error {
+	offline, err := cmd.Flags().GetBool("offline")
+	if err != nil {
+		return fmt.Errorf("getting offline flag: %w", err)
+	}
+	if offline {
+		config.SetOffline(true)
+	}
+	return selectProfile(cmd, args)
+}
+
+// selectProfile activates the profile chosen by --profile, CDD_PROFILE or
+// 'cdd profiles switch' before any command reads configuration.
+func selectProfile(cmd *cobra.Command, _ []string) error {
+	flag, err := cmd.Flags().GetString("profile")
+	if err != nil {
+		return fmt.Errorf("getting profile flag: %w", err)
+	}
+	if err := config.SetProfile(config.ResolveProfile(flag)); err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	return nil
+}
+
 func runTUI(cmd *cobra.Command, _ []string) error {
 	// Enable debug logging if requested.
 	debugMode, err := cmd.Flags().GetBool("debug")
@@ -52,7 +113,7 @@ func runTUI(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("getting debug flag: %w", err)
 	}
 	if debugMode {
-		logPath := filepath.Join(xdg.DataHome, "cdd", "debug.log")
+		logPath := filepath.Join(config.DefaultDataDir(), "debug.log")
 		if debugErr := debug.Enable(logPath); debugErr != nil {
 			fmt.Fprintf(os.Stderr, "Warning: Failed to enable debug logging: %v\n", debugErr)
 		} else {
@@ -61,10 +122,22 @@ func runTUI(cmd *cobra.Command, _ []string) error {
 		}
 	}
 
+	// Ask before loading a new directory's project config, hooks and commands.
+	if err := confirmTrust(); err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+
 	// Load configuration.
 	isFirstRun := config.IsFirstRun()
 	cfg, err := config.Load()
 	if err != nil {
+		// A config file with mistakes would otherwise be ignored without a word.
+		var schemaErr *config.SchemaError
+		if errors.As(err, &schemaErr) {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%w\nRun 'cdd config validate' to check your configuration", err)
+		}
 		cfg = config.NewConfig()
 	}
 
@@ -77,20 +150,84 @@ func runTUI(cmd *cobra.Command, _ []string) error {
 		}
 	}
 
+	// Apply key binding overrides before any TUI component reads them.
+	km, err := keymap.New(cfg.Keybindings())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	keymap.SetCurrent(km)
+
+	accessible, err := cmd.Flags().GetBool("accessible")
+	if err != nil {
+		return fmt.Errorf("getting accessible flag: %w", err)
+	}
+	styles.SetAccessible(accessible || cfg.Accessible())
+	if _, unknown := cfg.StatusBar(); len(unknown) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: unknown status bar segment(s) %s; valid segments: %s\n",
+			strings.Join(unknown, ", "), strings.Join(config.StatusSegments, ", "))
+	}
+	if _, ok := cfg.Markdown(); !ok {
+		fmt.Fprintf(os.Stderr, "Warning: unknown markdown style %q; valid styles: %s\n",
+			cfg.Options.Markdown.Style, strings.Join(config.MarkdownStyles, ", "))
+	}
+	if _, ok := cfg.Colors(); !ok {
+		fmt.Fprintf(os.Stderr, "Warning: unknown colors setting %q; valid settings: %s\n",
+			cfg.Options.Colors, strings.Join(config.ColorSettings, ", "))
+	}
+
+	backupDatabase(cfg)
+
+	// Export traces and serve metrics when configured.
+	defer startTelemetry(cfg)()
+	agentMetrics := startMetrics(cfg)
+
 	// Create the pub/sub hub for event distribution.
 	hub := pubsub.NewHub()
 	defer hub.Shutdown()
 
+	// Refresh OAuth tokens before they expire; the chat swaps in a model
+	// built with the new token.
+	refreshCtx, stopRefresher := context.WithCancel(context.Background())
+	defer stopRefresher()
+	go provider.NewRefresher(hub.Auth, config.Load).Run(refreshCtx)
+
+	// Pick up config and custom provider changes made outside the TUI.
+	go provider.NewWatcher(hub.Config, config.Load,
+		config.GlobalConfigPath(),
+		config.ProjectConfigPath(),
+		config.NewCustomProviderManager(cfg.DataDir()).GetFilePath(),
+	).Run(refreshCtx)
+
+	// Language servers start on first use and stop when the TUI exits.
+	lspManager := newLSPManager(cfg)
+	if lspManager != nil {
+		defer lspManager.Shutdown(context.Background())
+	}
+
+	// Sessions open here are locked against other cdd processes until exit;
+	// every agent built below shares the locks.
+	locks := session.NewLocker(locksDir(cfg), "cdd")
+	defer locks.UnlockAll()
+
 	// Create agent if not first run.
 	var ag *agent.DefaultAgent
 	var modelName string
 	var sessionSvc *session.Service
 	if !isFirstRun {
-		ag, modelName, sessionSvc, err = createAgent(cfg, hub)
+		ag, modelName, sessionSvc, err = createAgent(cfg, hub, lspManager, agentMetrics, locks)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: Failed to create agent: %v\n", err)
 		}
 	}
+	// current is the agent whose messages must be written before exit,
+	// including the replies still streaming when the TUI quits, is
+	// interrupted or panics.
+	current := ag
+	defer func() {
+		if current != nil {
+			stopAgent(current)
+		}
+	}()
 
 	// Define agent factory for TUI to reload agent on config changes.
 	agentFactory := func() (*agent.DefaultAgent, *session.Service, error) {
@@ -98,7 +235,14 @@ func runTUI(cmd *cobra.Command, _ []string) error {
 		if loadErr != nil {
 			return nil, nil, fmt.Errorf("loading config: %w", loadErr)
 		}
-		newAgent, _, newSessionSvc, createErr := createAgent(newCfg, hub)
+		// The new agent's writer takes over the message journal.
+		if current != nil {
+			_ = current.Close() //nolint:errcheck // Later writes fall back to synchronous
+		}
+		newAgent, _, newSessionSvc, createErr := createAgent(newCfg, hub, lspManager, agentMetrics, locks)
+		if newAgent != nil {
+			current = newAgent
+		}
 		return newAgent, newSessionSvc, createErr
 	}
 
@@ -112,16 +256,169 @@ func runTUI(cmd *cobra.Command, _ []string) error {
 		return createModel(newCfg)
 	}
 
-	return tui.Run(cfg, providers, isFirstRun, ag, agentFactory, modelFactory, hub, modelName, sessionSvc)
+	return tui.Run(cfg, providers, isFirstRun, ag, agentFactory, modelFactory, hub, modelName, sessionSvc, openPromptHistory(cfg))
+}
+
+// lockSession locks a session for a command, explaining how to take it over
+// when another cdd is using it.
+func lockSession(locks *session.Locker, sessionID string, force bool) error {
+	err := locks.Lock(sessionID, force)
+	var locked *session.LockedError
+	if errors.As(err, &locked) {
+		return fmt.Errorf("%w; use --force to take it over", err)
+	}
+	if err != nil {
+		return fmt.Errorf("locking session: %w", err)
+	}
+	return nil
+}
+
+// shutdownTimeout bounds how long exiting waits for the replies in progress
+// to be saved.
+const shutdownTimeout = 5 * time.Second
+
+// stopAgent interrupts the agent's runs in progress, so each saves the reply
+// so far for the next start to offer to resume, then waits for the session
+// store to finish writing.
+func stopAgent(ag *agent.DefaultAgent) {
+	if n := ag.Busy(); n > 0 {
+		fmt.Fprintf(os.Stderr, "Saving %d reply(s) in progress...\n", n)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := ag.Interrupt(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	_ = ag.Close() //nolint:errcheck // Best effort on exit
+}
+
+// openTickets returns the client for the issue trackers configured under
+// integrations, or nil when there are none.
+func openTickets(cfg *config.Config) *tickets.Client {
+	client, err := tickets.New(cfg.Integrations)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: fetch_ticket disabled: %v\n", err)
+		debug.Log("fetch_ticket disabled: %v", err)
+		return nil
+	}
+	return client
 }
 
-func createAgent(cfg *config.Config, hub *pubsub.Hub) (*agent.DefaultAgent, string, *session.Service, error) {
+// openPromptHistory returns the store for chat input history, or nil when
+// the database is unavailable and history is kept in memory only.
+func openPromptHistory(cfg *config.Config) history.Store {
+	database, err := db.Open(databasePath(cfg))
+	if err != nil {
+		debug.Log("Prompt history unavailable: %v", err)
+		return nil
+	}
+	return history.NewSQLiteStore(database.Conn())
+}
+
+// backupDatabase copies the session database into the backups directory when
+// the last copy is older than the configured interval.
+func backupDatabase(cfg *config.Config) {
+	keep := cfg.BackupKeep()
+	if keep == 0 {
+		return
+	}
+	database, err := db.Open(databasePath(cfg))
+	if err != nil {
+		debug.Log("Database backup skipped: %v", err)
+		return
+	}
+	defer database.Close() //nolint:errcheck // Nothing was written through this connection.
+
+	path, err := database.Backup(context.Background(), backupDir(cfg), keep, cfg.BackupInterval())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to back up database: %v\n", err)
+		return
+	}
+	if path != "" {
+		debug.Log("Backed up database to %s", path)
+	}
+}
+
+// telemetryFlushTimeout bounds how long exiting waits to send pending spans.
+const telemetryFlushTimeout = 5 * time.Second
+
+// startTelemetry starts trace export as configured and returns a function
+// that flushes it. A misconfigured exporter is reported and tracing stays off.
+func startTelemetry(cfg *config.Config) func() {
+	shutdown, err := telemetry.Setup(context.Background(), cfg.Telemetry(), Version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to start telemetry: %v\n", err)
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), telemetryFlushTimeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			debug.Log("Flushing telemetry: %v", err)
+		}
+	}
+}
+
+// startMetrics serves the metrics endpoint when options.metrics.listen is set.
+// It returns nil, which records nothing, when the endpoint is off or cannot
+// be started.
+func startMetrics(cfg *config.Config) *metrics.Metrics {
+	listen := cfg.MetricsListen()
+	if listen == "" {
+		return nil
+	}
+	m := metrics.New()
+	addr, err := m.Serve(context.Background(), listen)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to start metrics endpoint: %v\n", err)
+		return nil
+	}
+	debug.Log("Serving metrics on http://%s/metrics", addr)
+	return m
+}
+
+// newLSPManager creates a manager for the language servers configured in cfg,
+// or returns nil when there are none.
+func newLSPManager(cfg *config.Config) *lsp.Manager {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	manager := lsp.NewManager(cwd, cfg.LSP)
+	if !manager.HasServers() {
+		return nil
+	}
+	return manager
+}
+
+// systemPrompt returns the contents of options.system_prompt_file, or the
+// built-in prompt when it is not set.
+func systemPrompt(cfg *config.Config) (string, error) {
+	path := cfg.SystemPromptFile()
+	if path == "" {
+		return agent.DefaultSystemPrompt, nil
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: Path comes from the user's config.
+	if err != nil {
+		return "", fmt.Errorf("reading system prompt file: %w", err)
+	}
+	prompt := strings.TrimSpace(string(data))
+	if prompt == "" {
+		return "", fmt.Errorf("system prompt file %s is empty", path)
+	}
+	return prompt, nil
+}
+
+func createAgent(cfg *config.Config, hub *pubsub.Hub, lspManager *lsp.Manager, agentMetrics *metrics.Metrics, locks *session.Locker) (*agent.DefaultAgent, string, *session.Service, error) {
 	ctx := context.Background()
 
 	// Initialize database for persistent sessions first (independent of model building).
 	var sessions agent.Sessions
 	var sessionSvc *session.Service
-	dbPath := filepath.Join(cfg.DataDir(), "cdd.db")
+	todoStore := tools.NewTodoStore()
+	var memories memory.Store
+	var codebase *index.Index
+	var tracker *usage.Tracker
+	dbPath := databasePath(cfg)
 	database, dbErr := db.Open(dbPath)
 	if dbErr != nil {
 		// Fall back to in-memory sessions if database unavailable.
@@ -131,17 +428,28 @@ func createAgent(cfg *config.Config, hub *pubsub.Hub) (*agent.DefaultAgent, stri
 		// Create persistent session store.
 		sessionStore := session.NewSQLiteStore(database.Conn())
 		sessionSvc = session.NewService(sessionStore, hub.Session)
+		sessionSvc.SetProject(currentProject())
 
 		messageStore := message.NewSQLiteStore(database.Conn())
 		messageSvc := message.NewService(messageStore, hub.Session)
 
-		sessions = agent.NewPersistentSessionStore(sessionSvc, messageSvc)
+		store := agent.NewPersistentSessionStore(sessionSvc, messageSvc)
+		// Messages are written in the background; the journals beside the
+		// database keep any that were queued when a cdd last exited.
+		if err := store.StartWriter(messageJournalDir(cfg)); err != nil {
+			debug.Log("Writing messages synchronously: %v", err)
+		}
+		sessions = store
+		todoStore = tools.NewPersistentTodoStore(database.Conn())
+		memories = memory.NewSQLiteStore(database.Conn())
+		codebase = openIndex(cfg, database.Conn())
+		tracker = usage.NewTracker(database.Conn(), modelPrices(cfg))
 		debug.Log("Using persistent sessions: %s", dbPath)
 	}
 
 	// Build models from configuration.
 	builder := provider.NewBuilder(cfg)
-	largeModel, _, err := builder.BuildModels(ctx)
+	largeModel, smallModel, err := builder.BuildModels(ctx)
 	if err != nil {
 		return nil, "", nil, fmt.Errorf("building models: %w", err)
 	}
@@ -152,21 +460,79 @@ func createAgent(cfg *config.Config, hub *pubsub.Hub) (*agent.DefaultAgent, stri
 		return nil, "", nil, fmt.Errorf("getting working directory: %w", err)
 	}
 
-	// Create todo store and tools registry.
-	todoStore := tools.NewTodoStore()
-	registry := tools.NewDefaultRegistry(tools.RegistryConfig{
-		WorkingDir: cwd,
-		Hub:        hub,
-		TodoStore:  todoStore,
+	// Create tools registry.
+	registryCfg := tools.RegistryConfig{
+		WorkingDir:  cwd,
+		Hub:         hub,
+		TodoStore:   todoStore,
+		BashTimeout: cfg.BashTimeout(),
+		Memory:      memories,
+		Index:       codebase,
+		Tickets:     openTickets(cfg),
+		Project:     currentProject(),
+	}
+	if lspManager != nil {
+		registryCfg.Diagnostics = lspManager
+	}
+	if sandbox := cfg.Sandbox; sandbox != nil && sandbox.Enabled {
+		registryCfg.Sandbox = tools.NewSandbox(cwd, sandbox.AllowedPaths, sandbox.AllowedCommands)
+	}
+	registry := tools.NewDefaultRegistry(registryCfg)
+
+	// Sub-agents spawned by the task tool explore with the small model and read-only tools.
+	registry.Register(agent.NewTaskTool(agent.TaskConfig{
+		Model: smallModel.Model,
+		Tools: registry.Filter([]string{tools.ReadToolName, tools.GlobToolName, tools.GrepToolName}),
+	}), tools.ToolMetadata{
+		Name:        agent.TaskToolName,
+		Category:    "task",
+		Description: "Delegate research to a sub-agent with its own context",
+		Safe:        true,
 	})
 
+	prompt, err := systemPrompt(cfg)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	modes, err := agentModes(cfg, registry)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
 	// Create agent configuration.
 	agentCfg := agent.Config{
 		Model:        largeModel.Model,
 		Tools:        registry.All(),
-		SystemPrompt: agent.DefaultSystemPrompt,
+		SystemPrompt: prompt,
+		ContextFiles: contextfiles.Load(cwd, cfg.Options.ContextPaths),
 		Hub:          hub,
 		Sessions:     sessions,
+
+		ModelResolver: modelResolver(cfg),
+		SummaryModel:  smallModel.Model,
+		ContextWindow: largeModel.CatwalkCfg.ContextWindow,
+
+		Retry:         agent.RetryPolicy{MaxAttempts: cfg.MaxAttempts()},
+		MaxIterations: cfg.MaxIterations(),
+		MaxToolOutput: cfg.MaxToolOutput(),
+		ToolOutputDir: toolOutputDir(cfg),
+
+		Journal: journal.New(journalDir(cfg)),
+		Runs:    agent.NewRunLog(runsDir(cfg)),
+		Locks:   locks,
+		Metrics: agentMetrics,
+		Hooks:   hooks.New(cwd, cfg.Hooks),
+		Todos:   todoStore,
+
+		Usage:  tracker,
+		Budget: usage.Budget(cfg.Budget()),
+
+		Modes: modes,
+		Mode:  cfg.DefaultMode(),
+	}
+
+	if cfg.Transcript() {
+		agentCfg.Transcript = transcript.New(transcriptDir(cfg))
 	}
 
 	// Get model name for display
@@ -178,6 +544,64 @@ func createAgent(cfg *config.Config, hub *pubsub.Hub) (*agent.DefaultAgent, stri
 	return agent.New(agentCfg), modelName, sessionSvc, nil
 }
 
+// agentModes resolves the configured agent modes against the registered
+// tools, sorted by name.
+func agentModes(cfg *config.Config, registry *tools.Registry) ([]agent.Mode, error) {
+	configured := cfg.AgentModes()
+	if _, ok := configured[cfg.DefaultMode()]; !ok {
+		return nil, fmt.Errorf("default mode %q is not defined", cfg.DefaultMode())
+	}
+
+	modes := make([]agent.Mode, 0, len(configured))
+	for _, name := range slices.Sorted(maps.Keys(configured)) {
+		mc := configured[name]
+		var unsafe []string // Tools a read-only mode adds to the safe ones
+		for _, tool := range mc.Tools {
+			meta, ok := registry.Metadata(tool)
+			if !ok {
+				return nil, fmt.Errorf("mode %q: unknown tool %q", name, tool)
+			}
+			if !meta.Safe {
+				unsafe = append(unsafe, tool)
+			}
+		}
+
+		var modeTools []fantasy.AgentTool
+		switch {
+		case mc.ReadOnly:
+			modeTools = append(registry.SafeTools(), registry.Filter(unsafe)...)
+		case len(mc.Tools) > 0:
+			modeTools = registry.Filter(mc.Tools)
+		default:
+			modeTools = registry.All()
+		}
+		modes = append(modes, agent.Mode{
+			Name:        name,
+			Description: mc.Description,
+			Prompt:      mc.Prompt,
+			Tools:       modeTools,
+		})
+	}
+	return modes, nil
+}
+
+// modelPrices returns the price of every known and configured model by ID,
+// for the usage tracker.
+func modelPrices(cfg *config.Config) map[string]usage.Price {
+	prices := make(map[string]usage.Price)
+	for _, p := range cfg.KnownProviders() {
+		for _, m := range p.Models {
+			prices[m.ID] = usage.PriceOf(m)
+		}
+	}
+	for _, p := range cfg.Providers {
+		for _, m := range p.Models {
+			prices[m.ID] = usage.PriceOf(m)
+		}
+	}
+	return prices
+}
+
 // createModel builds just the model from config with fresh tokens.
 // Used for swapping models after token refresh without creating a new agent.
 func createModel(cfg *config.Config) (fantasy.LanguageModel, error) {
@@ -193,6 +617,27 @@ func createModel(cfg *config.Config) (fantasy.LanguageModel, error) {
 	return largeModel.Model, nil
 }
 
+// modelResolver builds the model a prompt names in place of the large model,
+// as --model would, leaving cfg as it is. A tier name ("small") stands for
+// the model selected for it.
+func modelResolver(cfg *config.Config) func(context.Context, string) (fantasy.LanguageModel, error) {
+	return func(ctx context.Context, spec string) (fantasy.LanguageModel, error) {
+		override := *cfg
+		override.Models = maps.Clone(cfg.Models)
+		spec = cfg.ResolveModelAlias(spec)
+		if selected, ok := cfg.Models[config.SelectedModelType(spec)]; ok {
+			override.Models[config.SelectedModelTypeLarge] = selected
+		} else if err := provider.OverrideModel(&override, config.SelectedModelTypeLarge, spec); err != nil {
+			return nil, err //nolint:wrapcheck // Says which model was not found
+		}
+		large, _, err := provider.NewBuilder(&override).BuildModels(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("building model: %w", err)
+		}
+		return large.Model, nil
+	}
+}
+
 // Execute runs the root command.
 func Execute() error {
 	return newRootCmd().Execute()
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/headless"
+	"github.com/guilhermegouw/cdd/internal/provider"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+	"github.com/guilhermegouw/cdd/internal/session"
+)
+
+func newRunCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run <prompt>",
+		Short: "Run a prompt without the TUI",
+		Long: `Run a single prompt through the agent without launching the TUI.
+
+The assistant's response is streamed to stdout and tool activity to stderr.
+The command exits non-zero if the agent fails or is cancelled.
+
+Examples:
+  cdd run "explain main.go"
+  cdd run --json --max-turns 10 "fix the failing tests"
+  cdd run --session <id> "now add docs"
+  cdd run --session <id> --force "take over a session open elsewhere"
+  cdd run --mode review "check the staged changes"
+  cdd run --background "upgrade the dependencies and fix the build"
+  echo "summarize this repo" | cdd run -`,
+		Args: cobra.ExactArgs(1),
+		RunE: runHeadless,
+	}
+
+	cmd.Flags().String("session", "", "Continue an existing session by ID")
+	cmd.Flags().Bool("force", false, "Take over the session even if another cdd is using it")
+	cmd.Flags().String("model", "", "Model to use, as provider/model, model ID or alias")
+	cmd.Flags().String("mode", "", "Agent mode to run in, e.g. plan, code or review")
+	cmd.Flags().Bool("json", false, "Emit newline-delimited JSON events")
+	cmd.Flags().Int("max-turns", 0, "Maximum agent steps before stopping (0 for unlimited)")
+	cmd.Flags().Bool("background", false, "Detach and run in the background, writing the output to a log file")
+	cmd.RegisterFlagCompletionFunc("session", completeSessionIDs) //nolint:errcheck // Flag is defined above
+	cmd.RegisterFlagCompletionFunc("mode", completeModes)         //nolint:errcheck // Flag is defined above
+
+	return cmd
+}
+
+func runHeadless(cmd *cobra.Command, args []string) error {
+	sessionID, _ := cmd.Flags().GetString("session")   //nolint:errcheck // Flag is defined.
+	modelSpec, _ := cmd.Flags().GetString("model")     //nolint:errcheck // Flag is defined.
+	mode, _ := cmd.Flags().GetString("mode")           //nolint:errcheck // Flag is defined.
+	jsonOut, _ := cmd.Flags().GetBool("json")          //nolint:errcheck // Flag is defined.
+	maxTurns, _ := cmd.Flags().GetInt("max-turns")     //nolint:errcheck // Flag is defined.
+	background, _ := cmd.Flags().GetBool("background") //nolint:errcheck // Flag is defined.
+	force, _ := cmd.Flags().GetBool("force")           //nolint:errcheck // Flag is defined.
+
+	// Agent failures are not usage errors.
+	cmd.SilenceUsage = true
+
+	if maxTurns < 0 {
+		return fmt.Errorf("--max-turns must not be negative")
+	}
+
+	prompt, err := readPrompt(args[0])
+	if err != nil {
+		return err
+	}
+
+	if config.IsFirstRun() {
+		return fmt.Errorf("cdd is not configured yet; run 'cdd' to complete setup")
+	}
+
+	// The project's cdd.json, hooks and commands load only in a trusted
+	// directory; without a terminal to ask on, an untrusted one is refused.
+	if err := confirmTrust(); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if warning := cfg.ProvidersWarning(); warning != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	// Refuse a session in use before starting anything; the lock is held
+	// until the run ends.
+	locks := session.NewLocker(locksDir(cfg), "cdd run")
+	if sessionID != "" {
+		if err := lockSession(locks, sessionID, force); err != nil {
+			return err
+		}
+	}
+	defer locks.UnlockAll()
+
+	if background {
+		locks.UnlockAll() // The job takes the lock itself
+		return startBackgroundRun(cmd, cfg, prompt)
+	}
+	if os.Getenv(backgroundJobEnv) != "" {
+		// The terminal that started the job may close before it finishes.
+		signal.Ignore(syscall.SIGHUP)
+	}
+
+	if modelSpec != "" {
+		if err := provider.OverrideModel(cfg, config.SelectedModelTypeLarge, modelSpec); err != nil {
+			return fmt.Errorf("selecting model: %w", err)
+		}
+	}
+
+	if mode != "" {
+		cfg.Options.DefaultMode = mode
+	}
+
+	defer startTelemetry(cfg)()
+
+	hub := pubsub.NewHub()
+	defer hub.Shutdown()
+
+	lspManager := newLSPManager(cfg)
+	if lspManager != nil {
+		defer lspManager.Shutdown(context.Background())
+	}
+
+	ag, _, _, err := createAgent(cfg, hub, lspManager, startMetrics(cfg), locks)
+	if err != nil {
+		return fmt.Errorf("creating agent: %w", err)
+	}
+	defer ag.Close() //nolint:errcheck // Best effort on exit
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runner := headless.NewRunner(ag, hub, os.Stdout, os.Stderr)
+	return runner.Run(ctx, prompt, headless.Options{
+		SessionID:      sessionID,
+		MaxTurns:       maxTurns,
+		ThinkingBudget: cfg.ThinkingBudget(config.SelectedModelTypeLarge),
+		JSON:           jsonOut,
+	})
+}
+
+// backgroundJobEnv is set in the environment of a run started with
+// --background.
+const backgroundJobEnv = "CDD_BACKGROUND_JOB"
+
+// startBackgroundRun runs the prompt in a detached cdd process with the same
+// flags, and returns once it has started. The process's output goes to a log
+// file under the data directory.
+func startBackgroundRun(cmd *cobra.Command, cfg *config.Config, prompt string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding the cdd executable: %w", err)
+	}
+
+	dir := jobsDir(cfg)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("creating jobs directory: %w", err)
+	}
+	logPath := filepath.Join(dir, time.Now().Format("20060102-150405.000")+".log")
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600) //nolint:gosec // Path is built above
+	if err != nil {
+		return fmt.Errorf("creating job log: %w", err)
+	}
+	defer logFile.Close() //nolint:errcheck // The child has its own descriptor
+
+	args := []string{"run"}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if f.Name != "background" {
+			args = append(args, "--"+f.Name+"="+f.Value.String())
+		}
+	})
+	// The prompt was already read, so the job doesn't need stdin.
+	args = append(args, "--", prompt)
+
+	child := exec.Command(exe, args...) //nolint:gosec // Re-running cdd itself
+	child.Env = append(os.Environ(), backgroundJobEnv+"=1")
+	child.Stdout = logFile
+	child.Stderr = logFile
+	if err := child.Start(); err != nil {
+		return fmt.Errorf("starting background job: %w", err)
+	}
+	pid := child.Process.Pid
+	child.Process.Release() //nolint:errcheck,gosec // The job outlives this process
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Started background job (pid %d)\nOutput: %s\n", pid, logPath)
+	return nil
+}
+
+// readPrompt returns the prompt argument, reading stdin when it is "-".
+func readPrompt(arg string) (string, error) {
+	if arg != "-" {
+		return arg, nil
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("reading prompt from stdin: %w", err)
+	}
+	prompt := strings.TrimSpace(string(data))
+	if prompt == "" {
+		return "", fmt.Errorf("prompt from stdin is empty")
+	}
+	return prompt, nil
+}
@@ -0,0 +1,563 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/db"
+	"github.com/guilhermegouw/cdd/internal/journal"
+	"github.com/guilhermegouw/cdd/internal/message"
+	"github.com/guilhermegouw/cdd/internal/session"
+	"github.com/guilhermegouw/cdd/internal/usage"
+)
+
+// newSessionsCmd creates the sessions command group.
+func newSessionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "Manage saved chat sessions",
+		Long: `Manage chat sessions stored in the local database. Without a subcommand,
+list the sessions of the current project (the enclosing git repository, or the
+working directory outside one).
+
+Examples:
+  cdd sessions                                  List this project's sessions
+  cdd sessions --all                            List the sessions of every project
+  cdd sessions view <session-id>                Read a session in the terminal
+  cdd sessions stats <session-id>               Count a session's messages, tools and cost
+  cdd sessions export <session-id> session.json  Export a session to a file
+  cdd sessions import session.json              Import a session from a file
+  cdd sessions revert <session-id>              Undo the session's file changes
+  cdd sessions prune --older-than 90d           Delete sessions idle for 90 days`,
+		Args: cobra.NoArgs,
+		RunE: runSessionsList,
+	}
+
+	cmd.Flags().Bool("all", false, "List sessions of all projects")
+
+	cmd.AddCommand(newSessionsViewCmd())
+	cmd.AddCommand(newSessionsStatsCmd())
+	cmd.AddCommand(newSessionsExportCmd())
+	cmd.AddCommand(newSessionsImportCmd())
+	cmd.AddCommand(newSessionsRevertCmd())
+	cmd.AddCommand(newSessionsPruneCmd())
+
+	return cmd
+}
+
+// runSessionsList lists sessions, newest first.
+func runSessionsList(cmd *cobra.Command, _ []string) error {
+	all, _ := cmd.Flags().GetBool("all") //nolint:errcheck // Flag is defined.
+
+	database, err := openSessionsDB()
+	if err != nil {
+		return err
+	}
+	defer database.Close() //nolint:errcheck // Read-only use, close error is not actionable.
+
+	project := ""
+	if !all {
+		project = currentProject()
+	}
+	sessions, err := session.NewSQLiteStore(database.Conn()).ListWithPreview(context.Background(), project)
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		if all {
+			fmt.Println("No sessions yet.")
+		} else {
+			fmt.Println("No sessions in this project. Use --all to list those of every project.")
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	header := "ID\tUPDATED\tMESSAGES\tTITLE"
+	if all {
+		header += "\tPROJECT"
+	}
+	fmt.Fprintln(w, header)
+	for _, s := range sessions {
+		row := fmt.Sprintf("%s\t%s\t%d\t%s", s.ID, s.UpdatedAt.Format("2006-01-02 15:04"), s.MessageCount, s.Title)
+		if all {
+			row += "\t" + s.Project
+		}
+		fmt.Fprintln(w, row)
+	}
+	return w.Flush()
+}
+
+// newSessionsViewCmd renders a session in the terminal.
+func newSessionsViewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "view <session-id>",
+		Short: "Read a session in the terminal",
+		Long: `Render a saved session, with its prompts, replies, tool calls and tool
+output, as formatted Markdown. No agent or model is started.
+
+The output goes through $PAGER (less by default) when stdout is a terminal.
+
+Examples:
+  cdd sessions view <session-id>
+  cdd sessions view <session-id> --thinking   Include the model's thinking
+  cdd sessions view <session-id> --raw > session.md`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: firstArg(completeSessionIDs),
+		RunE:              runSessionsView,
+	}
+
+	cmd.Flags().Bool("no-pager", false, "Write to stdout instead of the pager")
+	cmd.Flags().Bool("raw", false, "Print the Markdown source without formatting it")
+	cmd.Flags().Bool("thinking", false, "Include the model's thinking")
+
+	return cmd
+}
+
+// runSessionsView executes the sessions view command.
+func runSessionsView(cmd *cobra.Command, args []string) error {
+	noPager, _ := cmd.Flags().GetBool("no-pager")  //nolint:errcheck // Flag is defined.
+	raw, _ := cmd.Flags().GetBool("raw")           //nolint:errcheck // Flag is defined.
+	thinking, _ := cmd.Flags().GetBool("thinking") //nolint:errcheck // Flag is defined.
+
+	database, err := openSessionsDB()
+	if err != nil {
+		return err
+	}
+	defer database.Close() //nolint:errcheck // Read-only use, close error is not actionable.
+
+	export, err := session.ExportSession(context.Background(), database.Conn(), args[0])
+	if err != nil {
+		return fmt.Errorf("reading session: %w", err)
+	}
+
+	out := export.Markdown(session.MarkdownOptions{Reasoning: thinking})
+	interactive := term.IsTerminal(int(os.Stdout.Fd()))
+	if !raw {
+		width := viewWidth
+		if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 && w < width {
+			width = w
+		}
+		renderer, err := glamour.NewTermRenderer(glamour.WithAutoStyle(), glamour.WithWordWrap(width))
+		if err != nil {
+			return fmt.Errorf("creating renderer: %w", err)
+		}
+		if out, err = renderer.Render(out); err != nil {
+			return fmt.Errorf("rendering session: %w", err)
+		}
+	}
+
+	if noPager || !interactive {
+		_, err := fmt.Fprint(cmd.OutOrStdout(), out)
+		return err //nolint:wrapcheck // Writing to stdout
+	}
+	return page(cmd, out)
+}
+
+// viewWidth is the widest sessions view wraps text, so prose stays readable
+// on wide terminals.
+const viewWidth = 120
+
+// page shows text through $PAGER, or less, and falls back to printing it
+// when no pager can be started.
+func page(cmd *cobra.Command, text string) error {
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		pager = []string{"less"}
+	}
+	pagerCmd := exec.Command(pager[0], pager[1:]...) //nolint:gosec // G204: The pager is the user's choice.
+	pagerCmd.Stdin = strings.NewReader(text)
+	pagerCmd.Stdout = os.Stdout
+	pagerCmd.Stderr = os.Stderr
+	// Keep colors and quit at once when the text fits on one screen.
+	if os.Getenv("LESS") == "" {
+		pagerCmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	if err := pagerCmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil // The pager ran; quitting it early is not an error.
+		}
+		_, err := fmt.Fprint(cmd.OutOrStdout(), text)
+		return err //nolint:wrapcheck // Writing to stdout
+	}
+	return nil
+}
+
+// newSessionsExportCmd exports a session to JSON.
+func newSessionsExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "export <session-id> [output-file]",
+		Short:             "Export a session to a JSON file",
+		Long:              `Export a session and all of its messages to JSON. Writes to stdout when no output file is given.`,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completeSessionExport,
+		RunE:              runSessionsExport,
+	}
+
+	return cmd
+}
+
+// runSessionsExport executes the sessions export command.
+func runSessionsExport(cmd *cobra.Command, args []string) error {
+	database, err := openSessionsDB()
+	if err != nil {
+		return err
+	}
+	defer database.Close() //nolint:errcheck // Read-only use, close error is not actionable.
+
+	export, err := session.ExportSession(context.Background(), database.Conn(), args[0])
+	if err != nil {
+		return fmt.Errorf("exporting session: %w", err)
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling session: %w", err)
+	}
+
+	if len(args) < 2 {
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if err := os.WriteFile(args[1], data, 0o600); err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+
+	fmt.Printf("Exported session %q (%d messages) to: %s\n", export.Session.Title, len(export.Messages), args[1])
+	return nil
+}
+
+// newSessionsImportCmd imports a session from JSON.
+func newSessionsImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import a session from a JSON file",
+		Long: `Recreate a session exported with 'cdd sessions export', preserving message
+order, roles, tool calls, and timestamps.
+
+Importing fails if a session with the same ID already exists; use --new-ids
+to import it as a copy.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runSessionsImport,
+	}
+
+	cmd.Flags().Bool("new-ids", false, "Assign new session and message IDs")
+
+	return cmd
+}
+
+// runSessionsImport executes the sessions import command.
+func runSessionsImport(cmd *cobra.Command, args []string) error {
+	newIDs, _ := cmd.Flags().GetBool("new-ids") //nolint:errcheck // Flag is defined.
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("reading file: %w", err)
+	}
+
+	var export session.Export
+	if err := json.Unmarshal(data, &export); err != nil {
+		return fmt.Errorf("parsing session export: %w", err)
+	}
+
+	database, err := openSessionsDB()
+	if err != nil {
+		return err
+	}
+	defer database.Close() //nolint:errcheck // Import is committed before close.
+
+	sess, err := session.ImportSession(context.Background(), database.Conn(), &export, session.ImportOptions{
+		NewIDs:  newIDs,
+		Project: currentProject(),
+	})
+	if err != nil {
+		return fmt.Errorf("importing session: %w", err)
+	}
+
+	fmt.Printf("Imported session %q (%d messages) as %s\n", sess.Title, sess.MessageCount, sess.ID)
+	return nil
+}
+
+// newSessionsStatsCmd sums up a session.
+func newSessionsStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats <session-id>",
+		Short: "Show a session's message counts, tool calls, tokens and cost",
+		Long: `Sum up a saved session: its messages by role, with an estimate of their
+tokens, how long it ran, the tokens and cost its requests reported, and how
+often each tool was called and failed.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: firstArg(completeSessionIDs),
+		RunE:              runSessionsStats,
+	}
+
+	return cmd
+}
+
+// runSessionsStats executes the sessions stats command.
+func runSessionsStats(cmd *cobra.Command, args []string) error {
+	database, err := openSessionsDB()
+	if err != nil {
+		return err
+	}
+	defer database.Close() //nolint:errcheck // Read-only use, close error is not actionable.
+
+	ctx := context.Background()
+	sessionSvc := session.NewService(session.NewSQLiteStore(database.Conn()), nil)
+	sess, err := sessionSvc.Get(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("reading session: %w", err)
+	}
+	messageSvc := message.NewService(message.NewSQLiteStore(database.Conn()), nil)
+	msgs := agent.NewPersistentSessionStore(sessionSvc, messageSvc).GetMessages(sess.ID)
+
+	// Recorded costs are kept, so no prices are needed to read them.
+	totals, err := usage.NewTracker(database.Conn(), nil).Session(ctx, sess.ID)
+	if err != nil {
+		return err //nolint:wrapcheck // Already wrapped by usage
+	}
+
+	stats := agent.ComputeSessionStats(msgs, totals)
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s\n\n%s\n", sess.Title, stats.Report())
+	return err //nolint:wrapcheck // Writing to stdout
+}
+
+// newSessionsRevertCmd rolls back the file changes made in a session.
+func newSessionsRevertCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revert <session-id>",
+		Short: "Undo the file changes the agent made in a session",
+		Long: `Restore every file the agent wrote or edited in a session to its previous
+content, newest change first. Files the agent created are removed.
+
+Reverting stops at a file that was changed after the agent wrote it, so edits
+made since are never overwritten. A session another cdd is using is left
+alone unless --force is given.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: firstArg(completeSessionIDs),
+		RunE:              runSessionsRevert,
+	}
+
+	cmd.Flags().Bool("force", false, "Revert even if another cdd is using the session")
+
+	return cmd
+}
+
+// runSessionsRevert executes the sessions revert command.
+func runSessionsRevert(cmd *cobra.Command, args []string) error {
+	force, _ := cmd.Flags().GetBool("force") //nolint:errcheck // Flag is defined.
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	// The agent must not edit the files while they are put back.
+	locks := session.NewLocker(locksDir(cfg), "cdd sessions revert")
+	if err := lockSession(locks, args[0], force); err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	defer locks.UnlockAll()
+
+	reverted, err := journal.New(journalDir(cfg)).Undo(args[0], 0)
+	for _, entry := range reverted {
+		fmt.Printf("Reverted %s\n", entry.Path)
+	}
+	if errors.Is(err, journal.ErrNothingToUndo) {
+		fmt.Println("No file changes to revert in this session.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reverting session: %w", err)
+	}
+
+	fmt.Printf("Reverted %d file change(s).\n", len(reverted))
+	return nil
+}
+
+// newSessionsPruneCmd deletes old sessions.
+func newSessionsPruneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune --older-than <age>",
+		Short: "Delete sessions that have not been used for a while",
+		Long: `Delete the sessions of every project last updated longer ago than --older-than,
+along with their messages and file change journals, then compact the database.
+
+Ages are Go durations with d (days) and w (weeks) also accepted, e.g. 90d,
+12w or 720h.`,
+		Args: cobra.NoArgs,
+		RunE: runSessionsPrune,
+	}
+
+	cmd.Flags().String("older-than", "", "Delete sessions not updated within this age (required)")
+	cmd.Flags().Bool("dry-run", false, "List the sessions that would be deleted")
+	_ = cmd.MarkFlagRequired("older-than") //nolint:errcheck // Flag is defined above.
+
+	return cmd
+}
+
+// runSessionsPrune executes the sessions prune command.
+func runSessionsPrune(cmd *cobra.Command, _ []string) error {
+	olderThan, _ := cmd.Flags().GetString("older-than") //nolint:errcheck // Flag is defined.
+	dryRun, _ := cmd.Flags().GetBool("dry-run")         //nolint:errcheck // Flag is defined.
+
+	age, err := parseAge(olderThan)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	database, err := db.Open(databasePath(cfg))
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer database.Close() //nolint:errcheck // Changes are committed before close.
+
+	ctx := context.Background()
+	pruned, err := session.PruneSessions(ctx, database.Conn(), time.Now().Add(-age), dryRun)
+	if err != nil {
+		return fmt.Errorf("pruning sessions: %w", err)
+	}
+
+	verb := "Deleted"
+	if dryRun {
+		verb = "Would delete"
+	}
+	changes := journal.New(journalDir(cfg))
+	for _, s := range pruned {
+		fmt.Printf("%s %s %q (last updated %s)\n", verb, s.ID, s.Title, s.UpdatedAt.Format("2006-01-02"))
+		if dryRun {
+			continue
+		}
+		if err := changes.Remove(s.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to remove journal of %s: %v\n", s.ID, err)
+		}
+	}
+	if len(pruned) == 0 {
+		fmt.Println("No sessions to prune.")
+		return nil
+	}
+	if dryRun {
+		return nil
+	}
+
+	// Deleted rows leave free pages behind; VACUUM gives them back to the disk.
+	if _, err := database.ExecContext(ctx, "VACUUM"); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to compact database: %v\n", err)
+	}
+	fmt.Printf("Deleted %d session(s).\n", len(pruned))
+	return nil
+}
+
+// parseAge parses a duration that may also be given in days or weeks.
+func parseAge(s string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count <= 0 {
+				return 0, fmt.Errorf("invalid age %q", s)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid age %q: use e.g. 90d, 12w or 720h", s)
+	}
+	return d, nil
+}
+
+// openSessionsDB opens the session database in the configured data directory.
+func openSessionsDB() (*db.DB, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+
+	database, err := db.Open(databasePath(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	return database, nil
+}
+
+// currentProject returns the project root of the working directory, which
+// sessions are grouped by.
+func currentProject() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return session.ProjectRoot(cwd)
+}
+
+// databasePath returns the path of the session database.
+func databasePath(cfg *config.Config) string {
+	return filepath.Join(cfg.DataDir(), "cdd.db")
+}
+
+// backupDir returns the directory holding copies of the session database.
+func backupDir(cfg *config.Config) string {
+	return filepath.Join(cfg.DataDir(), "backups")
+}
+
+// messageJournalDir returns the directory holding, per process, the
+// messages queued for the session database but not yet written.
+func messageJournalDir(cfg *config.Config) string {
+	return filepath.Join(cfg.DataDir(), "pending-messages")
+}
+
+// journalDir returns the directory holding the per-session file change journals.
+func journalDir(cfg *config.Config) string {
+	return filepath.Join(cfg.DataDir(), "journal")
+}
+
+// runsDir returns the directory recording the replies in progress, so one
+// cut short by a crash can be resumed.
+func runsDir(cfg *config.Config) string {
+	return filepath.Join(cfg.DataDir(), "runs")
+}
+
+// locksDir returns the directory holding the locks of the sessions in use.
+func locksDir(cfg *config.Config) string {
+	return filepath.Join(cfg.DataDir(), "locks")
+}
+
+// jobsDir returns where the output of cdd run --background is written.
+func jobsDir(cfg *config.Config) string {
+	return filepath.Join(cfg.DataDir(), "jobs")
+}
+
+// toolOutputDir returns where the whole output of tool results cut short for
+// the model is kept.
+func toolOutputDir(cfg *config.Config) string {
+	return filepath.Join(cfg.DataDir(), "tool-output")
+}
+
+// transcriptDir returns where session transcripts are written when
+// options.transcript is set.
+func transcriptDir(cfg *config.Config) string {
+	return filepath.Join(cfg.DataDir(), "transcripts")
+}
@@ -53,6 +53,7 @@ func runStatus(cmd *cobra.Command, _ []string) error {
 
 	// Working directory
 	fmt.Printf("Working Directory: %s\n", cwd)
+	fmt.Printf("Profile: %s\n", config.Profile())
 	fmt.Println()
 
 	// Model configuration
@@ -70,6 +71,9 @@ func runStatus(cmd *cobra.Command, _ []string) error {
 			printProviderStatus(id, provider)
 		}
 	}
+	if warning := cfg.ProvidersWarning(); warning != "" {
+		fmt.Printf("  Warning: %s\n", warning)
+	}
 	fmt.Println()
 
 	// Config file location
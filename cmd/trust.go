@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/session"
+	"github.com/guilhermegouw/cdd/internal/trust"
+)
+
+func newTrustCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trust [directory]",
+		Short: "Trust a project directory",
+		Long: `Trust a project directory (the current project by default), so that cdd
+starts there without asking and loads its cdd.json, hooks and commands.
+cdd asks the first time it starts in a directory that is not trusted.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runTrust,
+	}
+	cmd.Flags().Bool("revoke", false, "Stop trusting the directory")
+	cmd.Flags().Bool("list", false, "List the trusted directories")
+	return cmd
+}
+
+func runTrust(cmd *cobra.Command, args []string) error {
+	store, err := trust.Load(config.DefaultDataDir())
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+
+	if list, _ := cmd.Flags().GetBool("list"); list { //nolint:errcheck // Flag is defined above
+		for _, dir := range store.Dirs() {
+			fmt.Fprintln(out, dir)
+		}
+		return nil
+	}
+
+	dir := currentProject()
+	if len(args) == 1 {
+		if dir, err = filepath.Abs(args[0]); err != nil {
+			return fmt.Errorf("resolving directory: %w", err)
+		}
+		dir = session.ProjectRoot(dir)
+	}
+
+	if revoke, _ := cmd.Flags().GetBool("revoke"); revoke { //nolint:errcheck // Flag is defined above
+		revoked, err := store.Revoke(dir)
+		if err != nil {
+			return err
+		}
+		if !revoked {
+			return fmt.Errorf("%s is not trusted", dir)
+		}
+		fmt.Fprintf(out, "No longer trusting %s\n", dir)
+		return nil
+	}
+
+	if err := store.Trust(dir); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Trusted %s\n", dir)
+	return nil
+}
+
+// confirmTrust asks whether to trust the current project the first time cdd
+// starts in it, before its configuration is loaded.
+func confirmTrust() error {
+	store, err := trust.Load(config.DefaultDataDir())
+	if err != nil {
+		return err
+	}
+	dir := currentProject()
+	if dir == "" || store.IsTrusted(dir) {
+		return nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("%s is not trusted; run 'cdd trust' there first", dir)
+	}
+
+	fmt.Fprintf(os.Stderr, "cdd has not been used in %s before.\n", dir)
+	fmt.Fprintln(os.Stderr, "Trusting it lets cdd load the project's cdd.json, hooks and commands, and")
+	fmt.Fprintln(os.Stderr, "lets the agent read and change its files and run commands there.")
+	fmt.Fprint(os.Stderr, "Trust this directory? [y/N] ")
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return fmt.Errorf("reading answer: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return store.Trust(dir)
+	default:
+		return errors.New("directory not trusted; cdd did not start")
+	}
+}
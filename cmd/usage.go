@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/guilhermegouw/cdd/internal/usage"
+)
+
+// newUsageCmd creates the usage command group.
+func newUsageCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Report the tokens and cost of provider requests",
+		Long: `Report the tokens and cost recorded for every provider request, across all
+sessions and projects. Records are kept when sessions are deleted.`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newUsageReportCmd())
+
+	return cmd
+}
+
+// newUsageReportCmd sums usage per day, provider and model.
+func newUsageReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Sum tokens and cost per day, provider and model",
+		Long: `Sum the tokens and cost of provider requests per day, provider and model.
+Costs come from the prices the provider list gave when each request was made,
+so models without a listed price count as free.
+
+--since takes an age (7d, 4w, 36h) or a date (2026-01-31); days are in the
+local time zone.
+
+Examples:
+  cdd usage report                      The last 7 days
+  cdd usage report --since 30d --json   The last 30 days as JSON
+  cdd usage report --since 2026-01-01   Since the start of the year`,
+		Args: cobra.NoArgs,
+		RunE: runUsageReport,
+	}
+
+	cmd.Flags().String("since", "7d", "Age or date to report from")
+	cmd.Flags().Bool("json", false, "Print the report as JSON")
+
+	return cmd
+}
+
+// usageRow is a line of the usage report in JSON.
+type usageRow struct {
+	Day                 string  `json:"day"`
+	Provider            string  `json:"provider"`
+	Model               string  `json:"model"`
+	Requests            int64   `json:"requests"`
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	Cost                float64 `json:"cost"`
+}
+
+// runUsageReport executes the usage report command.
+func runUsageReport(cmd *cobra.Command, _ []string) error {
+	sinceFlag, _ := cmd.Flags().GetString("since") //nolint:errcheck // Flag is defined.
+	jsonOut, _ := cmd.Flags().GetBool("json")      //nolint:errcheck // Flag is defined.
+
+	since, err := parseSince(sinceFlag, time.Now())
+	if err != nil {
+		return err
+	}
+
+	database, err := openSessionsDB()
+	if err != nil {
+		return err
+	}
+	defer database.Close() //nolint:errcheck // Read-only use, close error is not actionable.
+
+	days, err := usage.NewTracker(database.Conn(), nil).ByDay(cmd.Context(), since)
+	if err != nil {
+		return err //nolint:wrapcheck // Already wrapped by usage
+	}
+
+	if jsonOut {
+		rows := make([]usageRow, 0, len(days))
+		for _, d := range days {
+			rows = append(rows, usageRow{
+				Day:                 d.Day,
+				Provider:            d.Provider,
+				Model:               d.Model,
+				Requests:            d.Requests,
+				InputTokens:         d.Input,
+				OutputTokens:        d.Output,
+				CacheCreationTokens: d.CacheCreation,
+				CacheReadTokens:     d.CacheRead,
+				Cost:                d.Cost,
+			})
+		}
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding report: %w", err)
+		}
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return err //nolint:wrapcheck // Writing to stdout
+	}
+
+	if len(days) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "No requests since %s.\n", since.Format("2006-01-02 15:04"))
+		return nil
+	}
+
+	var total usage.Totals
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DAY\tPROVIDER\tMODEL\tREQUESTS\tINPUT\tOUTPUT\tCACHED\tCOST")
+	for _, d := range days {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t$%.4f\n", d.Day, d.Provider, d.Model,
+			d.Requests, d.Input, d.Output, d.CacheCreation+d.CacheRead, d.Cost)
+		total.Requests += d.Requests
+		total.Input += d.Input
+		total.Output += d.Output
+		total.CacheCreation += d.CacheCreation
+		total.CacheRead += d.CacheRead
+		total.Cost += d.Cost
+	}
+	fmt.Fprintf(w, "Total\t\t\t%d\t%d\t%d\t%d\t$%.4f\n",
+		total.Requests, total.Input, total.Output, total.CacheCreation+total.CacheRead, total.Cost)
+	return w.Flush() //nolint:wrapcheck // Writing to stdout
+}
+
+// parseSince reads --since: an age back from now, such as 7d, or a date,
+// meaning the start of that day in the local time zone.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if day, err := time.ParseInLocation(time.DateOnly, s, now.Location()); err == nil {
+		return day, nil
+	}
+	age, err := parseAge(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q: use an age such as 7d or a date such as 2026-01-31", s)
+	}
+	return now.Add(-age), nil
+}
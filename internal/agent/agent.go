@@ -7,7 +7,15 @@ import (
 
 	"charm.land/fantasy"
 
+	"github.com/guilhermegouw/cdd/internal/contextfiles"
+	"github.com/guilhermegouw/cdd/internal/hooks"
+	"github.com/guilhermegouw/cdd/internal/journal"
+	"github.com/guilhermegouw/cdd/internal/metrics"
 	"github.com/guilhermegouw/cdd/internal/pubsub"
+	"github.com/guilhermegouw/cdd/internal/session"
+	"github.com/guilhermegouw/cdd/internal/tools"
+	"github.com/guilhermegouw/cdd/internal/transcript"
+	"github.com/guilhermegouw/cdd/internal/usage"
 )
 
 // Role represents the role of a message.
@@ -29,8 +37,13 @@ type Message struct { //nolint:govet // fieldalignment: preserving logical field
 	ReasoningMetadata fantasy.ProviderMetadata // Provider-specific metadata (e.g., Claude's signature)
 	ToolCalls         []ToolCall
 	ToolResults       []ToolResult
+	Attachments       []Attachment // Images and mentioned files sent with a user message
+	Timing            *Timing      // How fast an assistant reply streamed, nil when not measured
 	CreatedAt         time.Time
 	Role              Role
+	IsSummary         bool // Replaces all earlier messages when building model history
+	Cancelled         bool // The user stopped the response before it finished
+	Interrupted       bool // cdd exited before the response finished; also Cancelled
 }
 
 // ToolCall represents a tool call made by the assistant.
@@ -46,6 +59,7 @@ type ToolResult struct {
 	Name       string
 	Content    string
 	IsError    bool
+	FullOutput string // File with the whole output when Content was cut
 }
 
 // StreamCallbacks contains callbacks for streaming responses.
@@ -62,6 +76,28 @@ type SendOptions struct { //nolint:govet // fieldalignment: preserving logical f
 	SessionID   string
 	Temperature *float64
 	MaxTokens   int64
+	MaxTurns    int          // Maximum model steps per prompt (0 means unlimited)
+	Attachments []Attachment // Images and mentioned files to send with the prompt
+
+	// ReplaceFrom is the ID of an earlier user message that the prompt
+	// replaces. That message and everything after it are removed first.
+	ReplaceFrom string
+
+	// ThinkingBudget enables extended thinking on Anthropic models for this
+	// request, with up to this many tokens (0 leaves it off).
+	ThinkingBudget int64
+
+	// IgnoreBudget runs the prompt even when a budget is used up, once the
+	// user agreed to go on.
+	IgnoreBudget bool
+
+	// Mode runs the prompt in the named mode without switching to it, such
+	// as the planning pass before an approved run.
+	Mode string
+
+	// Model runs the prompt with another model without switching to it,
+	// named as for --model: provider/model, a model ID or an alias.
+	Model string
 }
 
 // Agent is the interface for an AI agent.
@@ -112,14 +148,28 @@ type Sessions interface {
 	// AddMessage adds a message to a session.
 	AddMessage(sessionID string, msg Message) bool
 
+	// AddMessages adds messages to a session in order, saving them together.
+	AddMessages(sessionID string, msgs []Message) bool
+
 	// GetMessages returns all messages for a session.
 	GetMessages(sessionID string) []Message
 
 	// ClearMessages clears all messages from a session.
 	ClearMessages(sessionID string) bool
 
+	// TruncateMessages removes a message and every message after it.
+	TruncateMessages(sessionID, messageID string) bool
+
 	// UpdateTitle updates a session's title.
 	UpdateTitle(sessionID, title string) bool
+
+	// SetSystemPrompt sets a session's system prompt; empty restores the
+	// agent's.
+	SetSystemPrompt(sessionID, prompt string) bool
+
+	// SetWorkingDir sets a session's working directory; empty restores the
+	// agent's.
+	SetWorkingDir(sessionID, dir string) bool
 }
 
 // Config contains agent configuration.
@@ -130,6 +180,44 @@ type Config struct { //nolint:govet // fieldalignment: preserving logical field
 	WorkingDir   string
 	Hub          *pubsub.Hub // Optional pub/sub hub for event publishing
 	Sessions     Sessions    // Optional custom sessions implementation
+
+	// ModelResolver builds the model SendOptions.Model names. Without one,
+	// prompts naming a model fail with ErrUnknownModel.
+	ModelResolver func(ctx context.Context, spec string) (fantasy.LanguageModel, error)
+
+	// Optional compaction settings. Both must be set to enable summarization.
+	SummaryModel  fantasy.LanguageModel // Model used to summarize (typically the small model)
+	ContextWindow int64                 // Context window of the main model, in tokens
+
+	Retry RetryPolicy // Retry policy for transient provider errors (zero value uses defaults)
+
+	ContextFiles []contextfiles.File // Project context files appended to the system prompt
+
+	Journal *journal.Journal // Optional journal of file changes, used by /undo
+	Runs    *RunLog          // Optional record of runs in progress, to resume them after a crash
+	Locks   *session.Locker  // Optional locks keeping other cdd processes out of a running session
+	Metrics *metrics.Metrics // Optional metrics of requests and tool calls
+	Hooks   *hooks.Runner    // Optional user commands run on lifecycle events
+	Todos   *tools.TodoStore // Optional store the todo_write tool writes to
+
+	Transcript *transcript.Writer // Optional append-only copy of every message saved
+
+	Usage  *usage.Tracker // Optional record of the tokens and cost of each request
+	Budget usage.Budget   // Limits checked against Usage before and during a run
+
+	// MaxIterations stops a prompt with ErrMaxIterations after this many
+	// model steps (0 uses DefaultMaxIterations, negative means no limit).
+	MaxIterations int
+
+	// MaxToolOutput is the most characters of a tool result sent to the
+	// model; longer results lose their middle (0 uses tools.MaxOutputLength,
+	// negative means no limit). ToolOutputDir, when set, keeps the whole
+	// output of each cut result in a file there.
+	MaxToolOutput int
+	ToolOutputDir string
+
+	Modes []Mode // Optional modes with their own instructions and tools
+	Mode  string // Mode to start in; without one the agent uses Tools and SystemPrompt alone
 }
 
 // ErrSessionBusy is returned when a session is already processing a request.
@@ -138,6 +226,22 @@ var ErrSessionBusy = NewError("session is busy")
 // ErrEmptyPrompt is returned when an empty prompt is provided.
 var ErrEmptyPrompt = NewError("prompt cannot be empty")
 
+// ErrMessageNotFound is returned when SendOptions.ReplaceFrom names a message
+// that is not in the session.
+var ErrMessageNotFound = NewError("message not found")
+
+// ErrUnknownMode is returned when SendOptions.Mode names a mode the agent
+// does not have.
+var ErrUnknownMode = NewError("unknown mode")
+
+// ErrUnknownModel is returned, wrapping the reason, when SendOptions.Model
+// names a model the agent cannot build.
+var ErrUnknownModel = NewError("unknown model")
+
+// ErrPaused is returned, wrapping usage.ErrBudgetExceeded, when a run stopped
+// between steps because a budget was used up.
+var ErrPaused = NewError("paused")
+
 // Error represents an agent-specific error.
 type Error struct {
 	message string
@@ -0,0 +1,149 @@
+package agent
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/tools"
+)
+
+// MaxAttachmentSize is the largest image that can be attached to a message.
+// Providers reject larger images (Anthropic's limit is 5 MB).
+const MaxAttachmentSize = 5 * 1024 * 1024
+
+// MaxFileMentionSize is the largest text file that can be included in a
+// message by mentioning it. It matches what a single read_file call returns.
+const MaxFileMentionSize = tools.MaxReadOutputSize
+
+// textMediaType is the media type of files mentioned in a prompt.
+const textMediaType = "text/plain"
+
+// imageMediaTypes are the image formats accepted by vision models.
+var imageMediaTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Attachment is an image or text file sent along with a user message.
+type Attachment struct {
+	Filename  string
+	MediaType string
+	Data      []byte
+}
+
+// LoadAttachment reads an image file to attach to a message. The format is
+// detected from the content, so the file extension does not matter.
+func LoadAttachment(path string) (Attachment, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("reading attachment: %w", err)
+	}
+	if info.IsDir() {
+		return Attachment{}, fmt.Errorf("%s is a directory", path)
+	}
+	if info.Size() > MaxAttachmentSize {
+		return Attachment{}, fmt.Errorf("%s is too large (%d KB, max %d KB)",
+			filepath.Base(path), info.Size()/1024, MaxAttachmentSize/1024)
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // Path is chosen by the user
+	if err != nil {
+		return Attachment{}, fmt.Errorf("reading attachment: %w", err)
+	}
+
+	mediaType := http.DetectContentType(data)
+	if !imageMediaTypes[mediaType] {
+		return Attachment{}, fmt.Errorf("%s is not a supported image (PNG, JPEG, GIF or WebP), detected %s",
+			filepath.Base(path), mediaType)
+	}
+
+	return Attachment{
+		Filename:  filepath.Base(path),
+		MediaType: mediaType,
+		Data:      data,
+	}, nil
+}
+
+// LoadFileMention reads a text file mentioned in a prompt as @path. name is
+// how the file is labelled for the model, usually its path relative to the
+// working directory.
+func LoadFileMention(path, name string) (Attachment, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("reading %s: %w", name, err)
+	}
+	if info.IsDir() {
+		return Attachment{}, fmt.Errorf("%s is a directory", name)
+	}
+	if info.Size() > MaxFileMentionSize {
+		return Attachment{}, fmt.Errorf("%s is too large to include (%d KB, max %d KB)",
+			name, info.Size()/1024, MaxFileMentionSize/1024)
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // Path is chosen by the user
+	if err != nil {
+		return Attachment{}, fmt.Errorf("reading %s: %w", name, err)
+	}
+	if !strings.HasPrefix(http.DetectContentType(data), "text/") || !utf8.Valid(data) {
+		return Attachment{}, fmt.Errorf("%s is not a text file", name)
+	}
+
+	return Attachment{
+		Filename:  name,
+		MediaType: textMediaType,
+		Data:      data,
+	}, nil
+}
+
+// IsImage reports whether the attachment is an image rather than a text file.
+func (a Attachment) IsImage() bool {
+	return imageMediaTypes[a.MediaType]
+}
+
+// filePart converts an attachment for the model.
+func (a Attachment) filePart() fantasy.FilePart {
+	return fantasy.FilePart{
+		Filename:  a.Filename,
+		Data:      a.Data,
+		MediaType: a.MediaType,
+	}
+}
+
+// fileParts converts image attachments for the model. Text files are part of
+// the prompt instead; see promptWithFiles.
+func fileParts(attachments []Attachment) []fantasy.FilePart {
+	var parts []fantasy.FilePart
+	for i := range attachments {
+		if attachments[i].IsImage() {
+			parts = append(parts, attachments[i].filePart())
+		}
+	}
+	return parts
+}
+
+// promptWithFiles appends the contents of the text attachments to prompt, so
+// the model sees mentioned files without having to read them.
+func promptWithFiles(prompt string, attachments []Attachment) string {
+	var sb strings.Builder
+	sb.WriteString(prompt)
+	for i := range attachments {
+		att := &attachments[i]
+		if att.IsImage() {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n\n<file path=%q>\n%s", att.Filename, att.Data)
+		if !strings.HasSuffix(string(att.Data), "\n") {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("</file>")
+	}
+	return sb.String()
+}
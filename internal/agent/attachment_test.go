@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"charm.land/fantasy"
+)
+
+// pngHeader is enough of a PNG file for content type detection.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestLoadAttachment(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("loads an image regardless of extension", func(t *testing.T) {
+		path := filepath.Join(dir, "screenshot.dat")
+		if err := os.WriteFile(path, pngHeader, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		att, err := LoadAttachment(path)
+		if err != nil {
+			t.Fatalf("LoadAttachment() error = %v", err)
+		}
+		if att.Filename != "screenshot.dat" || att.MediaType != "image/png" || len(att.Data) != len(pngHeader) {
+			t.Errorf("unexpected attachment %+v", att)
+		}
+	})
+
+	t.Run("rejects files that are not images", func(t *testing.T) {
+		path := filepath.Join(dir, "notes.png")
+		if err := os.WriteFile(path, []byte("just text"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadAttachment(path)
+		if err == nil || !strings.Contains(err.Error(), "not a supported image") {
+			t.Errorf("expected unsupported image error, got %v", err)
+		}
+	})
+
+	t.Run("rejects large files", func(t *testing.T) {
+		path := filepath.Join(dir, "huge.png")
+		data := make([]byte, MaxAttachmentSize+1)
+		copy(data, pngHeader)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadAttachment(path)
+		if err == nil || !strings.Contains(err.Error(), "too large") {
+			t.Errorf("expected size error, got %v", err)
+		}
+	})
+
+	t.Run("reports missing files", func(t *testing.T) {
+		if _, err := LoadAttachment(filepath.Join(dir, "missing.png")); err == nil {
+			t.Error("expected error for a missing file")
+		}
+	})
+}
+
+func TestLoadFileMention(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	att, err := LoadFileMention(path, "cmd/main.go")
+	if err != nil {
+		t.Fatalf("LoadFileMention() error = %v", err)
+	}
+	if att.Filename != "cmd/main.go" || att.IsImage() || string(att.Data) != "package main\n" {
+		t.Errorf("unexpected attachment %+v", att)
+	}
+
+	binary := filepath.Join(dir, "logo.png")
+	if err := os.WriteFile(binary, pngHeader, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFileMention(binary, "logo.png"); err == nil || !strings.Contains(err.Error(), "not a text file") {
+		t.Errorf("expected binary file error, got %v", err)
+	}
+	if _, err := LoadFileMention(dir, "dir"); err == nil {
+		t.Error("expected error for a directory")
+	}
+}
+
+func TestPromptWithFiles(t *testing.T) {
+	attachments := []Attachment{
+		{Filename: "a.txt", MediaType: textMediaType, Data: []byte("one")},
+		{Filename: "cat.png", MediaType: "image/png", Data: pngHeader},
+		{Filename: "b.txt", MediaType: textMediaType, Data: []byte("two\n")},
+	}
+	got := promptWithFiles("compare these", attachments)
+	want := "compare these\n\n<file path=\"a.txt\">\none\n</file>\n\n<file path=\"b.txt\">\ntwo\n</file>"
+	if got != want {
+		t.Errorf("promptWithFiles() = %q, want %q", got, want)
+	}
+	if parts := fileParts(attachments); len(parts) != 1 || parts[0].Filename != "cat.png" {
+		t.Errorf("fileParts() should only include images, got %+v", parts)
+	}
+}
+
+func TestAgentSend_Attachments(t *testing.T) {
+	var prompts [][]fantasy.Message
+	model := &mockModel{
+		streamFunc: func(_ context.Context, call fantasy.Call) (fantasy.StreamResponse, error) {
+			prompts = append(prompts, call.Prompt)
+			return func(yield func(fantasy.StreamPart) bool) {
+				if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeTextDelta, ID: "t1", Delta: "a cat"}) {
+					return
+				}
+				yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeFinish, FinishReason: fantasy.FinishReasonStop})
+			}, nil
+		},
+	}
+	ag := New(Config{Model: model})
+	sess := ag.Sessions().Create("Test")
+
+	image := Attachment{Filename: "cat.png", MediaType: "image/png", Data: pngHeader}
+	opts := SendOptions{SessionID: sess.ID, Attachments: []Attachment{image}}
+	if err := ag.Send(context.Background(), "what is this?", opts, StreamCallbacks{}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := ag.Send(context.Background(), "and its color?", SendOptions{SessionID: sess.ID}, StreamCallbacks{}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	countFiles := func(msgs []fantasy.Message) int {
+		n := 0
+		for _, msg := range msgs {
+			for _, part := range msg.Content {
+				if file, ok := fantasy.AsMessagePart[fantasy.FilePart](part); ok {
+					if file.MediaType != "image/png" || file.Filename != "cat.png" {
+						t.Errorf("unexpected file part %+v", file)
+					}
+					n++
+				}
+			}
+		}
+		return n
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("expected 2 model calls, got %d", len(prompts))
+	}
+	if n := countFiles(prompts[0]); n != 1 {
+		t.Errorf("first prompt has %d images, want 1", n)
+	}
+	if n := countFiles(prompts[1]); n != 1 {
+		t.Errorf("history should keep the image, got %d", n)
+	}
+
+	msgs := ag.Sessions().GetMessages(sess.ID)
+	if len(msgs[0].Attachments) != 1 {
+		t.Errorf("user message should store the attachment, got %+v", msgs[0])
+	}
+}
@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"context"
+	"errors"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/usage"
+)
+
+// checkBudget returns an error wrapping usage.ErrBudgetExceeded when the
+// session or today has used up a budget. Usage that cannot be read does not
+// stop the agent.
+func (a *DefaultAgent) checkBudget(ctx context.Context, sessionID string) error {
+	err := a.usage.Check(ctx, sessionID, a.budget)
+	if err != nil && !errors.Is(err, usage.ErrBudgetExceeded) {
+		debug.Error("agent", err, "checking budget")
+		return nil
+	}
+	return err
+}
+
+// checkBudgetBetweenSteps checks the budget after a step that asked for
+// tools, so a long run pauses before its next request. A step that answered
+// ends the run anyway.
+func (a *DefaultAgent) checkBudgetBetweenSteps(ctx context.Context, sessionID string, steps []fantasy.StepResult) error {
+	if len(steps) == 0 || steps[len(steps)-1].FinishReason != fantasy.FinishReasonToolCalls {
+		return nil
+	}
+	return a.checkBudget(ctx, sessionID)
+}
+
+// recordUsage saves the tokens and cost of a finished request to model.
+func (a *DefaultAgent) recordUsage(ctx context.Context, sessionID string, model fantasy.LanguageModel, tokens fantasy.Usage) {
+	_, err := a.usage.Record(ctx, sessionID, model.Provider(), model.Model(), usage.Tokens{
+		Input:         tokens.InputTokens,
+		Output:        tokens.OutputTokens,
+		CacheCreation: tokens.CacheCreationTokens,
+		CacheRead:     tokens.CacheReadTokens,
+	})
+	if err != nil {
+		debug.Error("agent", err, "recording usage")
+	}
+}
@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/db"
+	"github.com/guilhermegouw/cdd/internal/usage"
+)
+
+func TestAgentSend_Budget(t *testing.T) {
+	database, err := db.Open(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() }) //nolint:errcheck // Test cleanup
+
+	noop := fantasy.NewAgentTool("noop", "Does nothing",
+		func(context.Context, struct{}, fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			return fantasy.NewTextResponse("ok"), nil
+		})
+	// The model keeps calling tools, using 600 tokens per request.
+	var calls atomic.Int32
+	model := &mockModel{
+		streamFunc: func(context.Context, fantasy.Call) (fantasy.StreamResponse, error) {
+			calls.Add(1)
+			return func(yield func(fantasy.StreamPart) bool) {
+				if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeToolCall, ID: "call", ToolCallName: "noop", ToolCallInput: `{}`}) {
+					return
+				}
+				yield(fantasy.StreamPart{
+					Type:         fantasy.StreamPartTypeFinish,
+					FinishReason: fantasy.FinishReasonToolCalls,
+					Usage:        fantasy.Usage{InputTokens: 500, OutputTokens: 100},
+				})
+			}, nil
+		},
+	}
+	tracker := usage.NewTracker(database.Conn(), map[string]usage.Price{"mock-model": {In: 3, Out: 15}})
+	ag := New(Config{Model: model, Tools: []fantasy.AgentTool{noop}, Usage: tracker, Budget: usage.Budget{SessionTokens: 1000}})
+	sess := ag.Sessions().Create("Test")
+
+	err = ag.Send(context.Background(), "work", SendOptions{SessionID: sess.ID}, StreamCallbacks{})
+	if !errors.Is(err, ErrPaused) || !errors.Is(err, usage.ErrBudgetExceeded) {
+		t.Fatalf("Send() error = %v, want the run paused for the budget", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("model called %d times, want 2 before the budget ran out", calls.Load())
+	}
+
+	totals, err := tracker.Session(context.Background(), sess.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totals.Requests != 2 || totals.Total() != 1200 || totals.Cost != 0.006 {
+		t.Errorf("session usage = %+v, want 2 requests, 1200 tokens and $0.006", totals)
+	}
+
+	err = ag.Send(context.Background(), "more", SendOptions{SessionID: sess.ID}, StreamCallbacks{})
+	if !errors.Is(err, usage.ErrBudgetExceeded) || errors.Is(err, ErrPaused) {
+		t.Errorf("Send() over budget error = %v, want it refused", err)
+	}
+	if calls.Load() != 2 {
+		t.Error("a prompt over budget should not reach the model")
+	}
+
+	err = ag.Send(context.Background(), "more", SendOptions{SessionID: sess.ID, IgnoreBudget: true, MaxTurns: 1}, StreamCallbacks{})
+	if err != nil {
+		t.Errorf("Send() ignoring the budget error = %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Error("the user should be able to go past the budget")
+	}
+}
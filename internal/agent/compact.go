@@ -0,0 +1,183 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/google/uuid"
+)
+
+// DefaultCompactThreshold is the fraction of the context window that triggers compaction.
+const DefaultCompactThreshold = 0.8
+
+// charsPerToken is a rough heuristic used to estimate token counts without a tokenizer.
+const charsPerToken = 4
+
+// imageTokens approximates the tokens used by an attached image, which
+// providers bill by size (about 1600 for a full-size image on Claude).
+const imageTokens = 1600
+
+// maxSummaryToolOutput caps tool output included in the transcript sent for summarization.
+const maxSummaryToolOutput = 2000
+
+// summaryMaxTokens limits the length of a generated summary.
+const summaryMaxTokens int64 = 4096
+
+// summaryHeader introduces the summary when it is replayed to the model.
+const summaryHeader = "Summary of the conversation so far:"
+
+// summarySystemPrompt instructs the small model how to summarize a conversation.
+const summarySystemPrompt = `You are summarizing a conversation between a user and an AI coding assistant so the assistant can continue the work with less context.
+
+Write a concise summary that preserves:
+- The user's goals and any requirements or constraints they stated
+- Decisions made and the reasoning behind them
+- Files that were read, created, or modified, with the relevant details
+- Commands run and their important results or errors
+- Work that is still in progress or pending
+
+Do not add commentary or invent details. Output only the summary.`
+
+// Compactor summarizes older messages when a conversation approaches the model's context window.
+type Compactor struct {
+	model         fantasy.LanguageModel
+	contextWindow int64
+	threshold     float64
+}
+
+// NewCompactor creates a compactor that summarizes with model (typically the small model).
+// Returns nil if model is nil or the context window is unknown.
+func NewCompactor(model fantasy.LanguageModel, contextWindow int64) *Compactor {
+	if model == nil || contextWindow <= 0 {
+		return nil
+	}
+	return &Compactor{
+		model:         model,
+		contextWindow: contextWindow,
+		threshold:     DefaultCompactThreshold,
+	}
+}
+
+// ShouldCompact reports whether msgs plus reserved tokens exceed the compaction threshold.
+// reserved accounts for the system prompt and the tokens kept free for the response.
+func (c *Compactor) ShouldCompact(msgs []Message, reserved int64) bool {
+	if c == nil || len(msgs) < 2 {
+		return false
+	}
+	limit := int64(float64(c.contextWindow) * c.threshold)
+	return EstimateTokens(msgs)+reserved >= limit
+}
+
+// Summarize asks the model for a summary of msgs and returns it as a summary message.
+func (c *Compactor) Summarize(ctx context.Context, msgs []Message) (Message, error) {
+	return summarize(ctx, c.model, summarySystemPrompt, msgs)
+}
+
+// summarize asks model for a summary of msgs, following instructions, and
+// returns it as a summary message.
+func summarize(ctx context.Context, model fantasy.LanguageModel, instructions string, msgs []Message) (Message, error) {
+	maxTokens := summaryMaxTokens
+	resp, err := model.Generate(ctx, fantasy.Call{
+		Prompt: fantasy.Prompt{
+			fantasy.NewSystemMessage(oauthSystemHeader, instructions),
+			fantasy.NewUserMessage(buildTranscript(msgs)),
+		},
+		MaxOutputTokens: &maxTokens,
+	})
+	if err != nil {
+		return Message{}, fmt.Errorf("generating summary: %w", err)
+	}
+
+	summary := strings.TrimSpace(resp.Content.Text())
+	if summary == "" {
+		return Message{}, fmt.Errorf("generating summary: model returned empty summary")
+	}
+
+	return Message{
+		ID:        uuid.New().String(),
+		Role:      RoleAssistant,
+		Content:   summary,
+		IsSummary: true,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// EstimateTokens returns an approximate token count for msgs.
+func EstimateTokens(msgs []Message) int64 {
+	var chars int
+	for i := range msgs {
+		msg := &msgs[i]
+		chars += len(msg.Content) + len(msg.Reasoning)
+		for _, tc := range msg.ToolCalls {
+			chars += len(tc.Name) + len(tc.Input)
+		}
+		for _, tr := range msg.ToolResults {
+			chars += len(tr.Content)
+		}
+		for _, att := range msg.Attachments {
+			if att.IsImage() {
+				chars += imageTokens * charsPerToken
+			} else {
+				chars += len(att.Data)
+			}
+		}
+	}
+	return int64(chars / charsPerToken)
+}
+
+// activeMessages returns the messages from the most recent summary onwards.
+func activeMessages(msgs []Message) []Message {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].IsSummary {
+			return msgs[i:]
+		}
+	}
+	return msgs
+}
+
+// buildTranscript renders msgs as plain text for the summarization prompt.
+func buildTranscript(msgs []Message) string {
+	var sb strings.Builder
+	for i := range msgs {
+		msg := &msgs[i]
+		switch {
+		case msg.IsSummary:
+			sb.WriteString("[Earlier summary]\n")
+		case msg.Role == RoleUser:
+			sb.WriteString("[User]\n")
+		case msg.Role == RoleAssistant:
+			sb.WriteString("[Assistant]\n")
+		case msg.Role == RoleTool:
+			sb.WriteString("[Tool results]\n")
+		default:
+			continue
+		}
+
+		if msg.Content != "" {
+			sb.WriteString(msg.Content)
+			sb.WriteString("\n")
+		}
+		for _, att := range msg.Attachments {
+			if att.IsImage() {
+				fmt.Fprintf(&sb, "(attached image %s)\n", att.Filename)
+			} else {
+				fmt.Fprintf(&sb, "(included file %s)\n", att.Filename)
+			}
+		}
+		for _, tc := range msg.ToolCalls {
+			fmt.Fprintf(&sb, "Called %s with %s\n", tc.Name, tc.Input)
+		}
+		for _, tr := range msg.ToolResults {
+			status := "ok"
+			if tr.IsError {
+				status = "error"
+			}
+			fmt.Fprintf(&sb, "%s (%s): %s\n", tr.Name, status, truncate(tr.Content, maxSummaryToolOutput))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,178 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"charm.land/fantasy"
+)
+
+func summaryModel(summary string, calls *int) *mockModel {
+	return &mockModel{
+		generateFunc: func(_ context.Context, _ fantasy.Call) (*fantasy.Response, error) {
+			if calls != nil {
+				*calls++
+			}
+			return &fantasy.Response{
+				Content: fantasy.ResponseContent{fantasy.TextContent{Text: summary}},
+			}, nil
+		},
+	}
+}
+
+func TestNewCompactor(t *testing.T) {
+	if NewCompactor(nil, 1000) != nil {
+		t.Error("expected nil compactor without a model")
+	}
+	if NewCompactor(&mockModel{}, 0) != nil {
+		t.Error("expected nil compactor without a context window")
+	}
+	if NewCompactor(&mockModel{}, 1000) == nil {
+		t.Error("expected compactor to be created")
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	msgs := []Message{
+		{Role: RoleUser, Content: strings.Repeat("a", 40)},
+		{Role: RoleAssistant, ToolCalls: []ToolCall{{Name: "read", Input: strings.Repeat("b", 36)}}},
+		{Role: RoleTool, ToolResults: []ToolResult{{Content: strings.Repeat("c", 80)}}},
+	}
+	if got := EstimateTokens(msgs); got != 40 {
+		t.Errorf("EstimateTokens() = %d, want 40", got)
+	}
+}
+
+func TestCompactorShouldCompact(t *testing.T) {
+	c := NewCompactor(&mockModel{}, 100)
+	small := []Message{{Content: "hi"}, {Content: "there"}}
+	large := []Message{{Content: strings.Repeat("x", 400)}, {Content: "y"}}
+
+	if c.ShouldCompact(small, 0) {
+		t.Error("small history should not trigger compaction")
+	}
+	if !c.ShouldCompact(large, 0) {
+		t.Error("large history should trigger compaction")
+	}
+	if !c.ShouldCompact(small, 90) {
+		t.Error("reserved tokens should count toward the threshold")
+	}
+
+	var nilCompactor *Compactor
+	if nilCompactor.ShouldCompact(large, 0) {
+		t.Error("nil compactor should never compact")
+	}
+}
+
+func TestCompactorSummarize(t *testing.T) {
+	t.Run("returns summary message", func(t *testing.T) {
+		c := NewCompactor(summaryModel("  the summary  ", nil), 1000)
+		msg, err := c.Summarize(context.Background(), []Message{{Role: RoleUser, Content: "hello"}})
+		if err != nil {
+			t.Fatalf("Summarize() error = %v", err)
+		}
+		if !msg.IsSummary || msg.Role != RoleAssistant || msg.Content != "the summary" {
+			t.Errorf("unexpected summary message: %+v", msg)
+		}
+		if msg.ID == "" {
+			t.Error("summary message should have an ID")
+		}
+	})
+
+	t.Run("propagates model errors", func(t *testing.T) {
+		c := NewCompactor(&mockModel{
+			generateFunc: func(_ context.Context, _ fantasy.Call) (*fantasy.Response, error) {
+				return nil, errors.New("boom")
+			},
+		}, 1000)
+		if _, err := c.Summarize(context.Background(), nil); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("rejects empty summary", func(t *testing.T) {
+		c := NewCompactor(summaryModel("", nil), 1000)
+		if _, err := c.Summarize(context.Background(), nil); err == nil {
+			t.Error("expected error for empty summary")
+		}
+	})
+}
+
+func TestActiveMessages(t *testing.T) {
+	msgs := []Message{
+		{ID: "1"},
+		{ID: "2", IsSummary: true},
+		{ID: "3"},
+		{ID: "4", IsSummary: true},
+		{ID: "5"},
+	}
+	got := activeMessages(msgs)
+	if len(got) != 2 || got[0].ID != "4" {
+		t.Errorf("activeMessages() = %+v, want messages from ID 4", got)
+	}
+	if len(activeMessages(msgs[:1])) != 1 {
+		t.Error("history without summary should be returned unchanged")
+	}
+}
+
+func TestContextUsage(t *testing.T) {
+	ag := New(Config{Model: &mockModel{}, SystemPrompt: strings.Repeat("s", 400), ContextWindow: 1000})
+	sess := ag.Sessions().Create("Test")
+	ag.Sessions().AddMessage(sess.ID, Message{Role: RoleUser, Content: strings.Repeat("o", 4000)})
+	ag.Sessions().AddMessage(sess.ID, Message{Role: RoleAssistant, Content: "summary", IsSummary: true})
+	ag.Sessions().AddMessage(sess.ID, Message{Role: RoleUser, Content: strings.Repeat("c", 193)})
+
+	// Only messages from the summary on are sent, plus the system prompt.
+	used, window := ag.ContextUsage(sess.ID)
+	if used != 150 || window != 1000 {
+		t.Errorf("ContextUsage() = %d, %d; want 150, 1000", used, window)
+	}
+}
+
+func TestBuildHistoryWithSummary(t *testing.T) {
+	ag := New(Config{Model: &mockModel{}})
+	sess := ag.Sessions().Create("Test")
+
+	ag.Sessions().AddMessage(sess.ID, Message{Role: RoleUser, Content: "old"})
+	ag.Sessions().AddMessage(sess.ID, Message{Role: RoleAssistant, Content: "old reply"})
+	ag.Sessions().AddMessage(sess.ID, Message{Role: RoleAssistant, Content: "summary", IsSummary: true})
+	ag.Sessions().AddMessage(sess.ID, Message{Role: RoleUser, Content: "current"})
+
+	history := ag.buildHistory(sess.ID)
+	if len(history) != 1 {
+		t.Fatalf("expected only the summary in history, got %d messages", len(history))
+	}
+	if history[0].Role != fantasy.MessageRoleUser {
+		t.Errorf("summary should be replayed as a user message, got %s", history[0].Role)
+	}
+	text, ok := history[0].Content[0].(fantasy.TextPart)
+	if !ok || !strings.Contains(text.Text, "summary") {
+		t.Errorf("unexpected summary content: %+v", history[0].Content)
+	}
+}
+
+func TestSendCompactsLongHistory(t *testing.T) {
+	calls := 0
+	ag := New(Config{
+		Model:         &mockModel{},
+		SummaryModel:  summaryModel("condensed", &calls),
+		ContextWindow: 10000,
+	})
+	sess := ag.Sessions().Create("Test")
+	ag.Sessions().AddMessage(sess.ID, Message{Role: RoleUser, Content: strings.Repeat("x", 40000)})
+	ag.Sessions().AddMessage(sess.ID, Message{Role: RoleAssistant, Content: "ok"})
+
+	if err := ag.Send(context.Background(), "next", SendOptions{SessionID: sess.ID, MaxTokens: 100}, StreamCallbacks{}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected 1 summarization call, got %d", calls)
+	}
+	msgs := ag.Sessions().GetMessages(sess.ID)
+	if len(msgs) < 4 || !msgs[2].IsSummary || msgs[3].Content != "next" {
+		t.Errorf("expected summary before the new prompt, got %+v", msgs)
+	}
+}
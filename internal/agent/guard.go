@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"charm.land/fantasy"
+)
+
+// DefaultMaxIterations bounds the model steps of a prompt when
+// Config.MaxIterations is zero.
+const DefaultMaxIterations = 100
+
+// repeatedCallLimit is how many steps in a row may make the same tool calls
+// before the run is taken to be stuck in a loop.
+const repeatedCallLimit = 3
+
+// ErrMaxIterations is returned when a run still wanted to call tools after
+// the iteration limit.
+var ErrMaxIterations = NewError("iteration limit reached")
+
+// ErrToolLoop is returned when the model kept making the same tool calls.
+var ErrToolLoop = NewError("tool call loop detected")
+
+// runGuard stops a run that takes too many steps or keeps repeating itself,
+// so a misbehaving model cannot spin forever. err says why it stopped.
+type runGuard struct {
+	maxIterations int
+	err           error
+}
+
+// stop is a fantasy.StopCondition.
+func (g *runGuard) stop(steps []fantasy.StepResult) bool {
+	n := len(steps)
+	if n == 0 || steps[n-1].FinishReason != fantasy.FinishReasonToolCalls {
+		return false
+	}
+	if g.maxIterations > 0 && n >= g.maxIterations {
+		g.err = fmt.Errorf("%w: stopped after %d steps (raise options.max_iterations to allow more)", ErrMaxIterations, n)
+		return true
+	}
+	if n < repeatedCallLimit {
+		return false
+	}
+	last := toolCallKey(steps[n-1])
+	if last == "" {
+		return false
+	}
+	for _, step := range steps[n-repeatedCallLimit : n-1] {
+		if toolCallKey(step) != last {
+			return false
+		}
+	}
+	g.err = fmt.Errorf("%w: %s was called with the same input %d times in a row",
+		ErrToolLoop, steps[n-1].Content.ToolCalls()[0].ToolName, repeatedCallLimit)
+	return true
+}
+
+// toolCallKey identifies the tool calls of a step by name and input,
+// regardless of order.
+func toolCallKey(step fantasy.StepResult) string {
+	calls := step.Content.ToolCalls()
+	keys := make([]string, len(calls))
+	for i, call := range calls {
+		keys[i] = call.ToolName + "\x00" + call.Input
+	}
+	slices.Sort(keys)
+	return strings.Join(keys, "\x01")
+}
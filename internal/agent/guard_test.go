@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"charm.land/fantasy"
+)
+
+// toolLoopModel calls the noop tool on every step, with the input input(step).
+func toolLoopModel(calls *atomic.Int32, input func(step int32) string) *mockModel {
+	return &mockModel{
+		streamFunc: func(context.Context, fantasy.Call) (fantasy.StreamResponse, error) {
+			step := calls.Add(1)
+			return func(yield func(fantasy.StreamPart) bool) {
+				if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeToolCall, ID: fmt.Sprint("call", step), ToolCallName: "noop", ToolCallInput: input(step)}) {
+					return
+				}
+				yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeFinish, FinishReason: fantasy.FinishReasonToolCalls})
+			}, nil
+		},
+	}
+}
+
+func TestAgentSend_Guard(t *testing.T) {
+	noop := fantasy.NewAgentTool("noop", "Does nothing",
+		func(context.Context, struct {
+			N int `json:"n"`
+		}, fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			return fantasy.NewTextResponse("ok"), nil
+		})
+
+	t.Run("repeated calls", func(t *testing.T) {
+		var calls atomic.Int32
+		model := toolLoopModel(&calls, func(int32) string { return `{"n": 1}` })
+		ag := New(Config{Model: model, Tools: []fantasy.AgentTool{noop}})
+		sess := ag.Sessions().Create("Test")
+
+		err := ag.Send(context.Background(), "go", SendOptions{SessionID: sess.ID}, StreamCallbacks{})
+		if !errors.Is(err, ErrToolLoop) {
+			t.Fatalf("Send() error = %v, want ErrToolLoop", err)
+		}
+		if calls.Load() != repeatedCallLimit {
+			t.Errorf("model called %d times, want the run stopped after %d identical steps", calls.Load(), repeatedCallLimit)
+		}
+		if msgs := ag.History(sess.ID); len(msgs) == 0 {
+			t.Error("the steps taken before stopping should be kept")
+		}
+	})
+
+	t.Run("iteration limit", func(t *testing.T) {
+		var calls atomic.Int32
+		model := toolLoopModel(&calls, func(step int32) string { return fmt.Sprintf(`{"n": %d}`, step) })
+		ag := New(Config{Model: model, Tools: []fantasy.AgentTool{noop}, MaxIterations: 5})
+		sess := ag.Sessions().Create("Test")
+
+		err := ag.Send(context.Background(), "go", SendOptions{SessionID: sess.ID}, StreamCallbacks{})
+		if !errors.Is(err, ErrMaxIterations) || calls.Load() != 5 {
+			t.Errorf("Send() error = %v after %d steps, want ErrMaxIterations after 5", err, calls.Load())
+		}
+
+		// An explicit turn limit below the cap stops quietly, as before.
+		calls.Store(0)
+		err = ag.Send(context.Background(), "go", SendOptions{SessionID: sess.ID, MaxTurns: 2}, StreamCallbacks{})
+		if err != nil || calls.Load() != 2 {
+			t.Errorf("Send(MaxTurns: 2) error = %v after %d steps, want none after 2", err, calls.Load())
+		}
+	})
+}
+
+func TestRunGuard(t *testing.T) {
+	step := func(reason fantasy.FinishReason, inputs ...string) fantasy.StepResult {
+		var content fantasy.ResponseContent
+		for _, input := range inputs {
+			content = append(content, fantasy.ToolCallContent{ToolName: "read_file", Input: input})
+		}
+		return fantasy.StepResult{Response: fantasy.Response{Content: content, FinishReason: reason}}
+	}
+	a, b := step(fantasy.FinishReasonToolCalls, "a", "b"), step(fantasy.FinishReasonToolCalls, "b", "a")
+
+	g := &runGuard{}
+	if g.stop([]fantasy.StepResult{a, a}) {
+		t.Error("two identical steps should not stop the run")
+	}
+	if !g.stop([]fantasy.StepResult{a, b, a}) || !errors.Is(g.err, ErrToolLoop) {
+		t.Errorf("the same calls in any order three times should stop the run, err = %v", g.err)
+	}
+
+	g = &runGuard{}
+	if g.stop([]fantasy.StepResult{a, step(fantasy.FinishReasonToolCalls, "c"), a}) {
+		t.Error("different calls in between should not stop the run")
+	}
+	if g.stop([]fantasy.StepResult{step(fantasy.FinishReasonStop), step(fantasy.FinishReasonStop), step(fantasy.FinishReasonStop)}) {
+		t.Error("steps without tool calls should not stop the run")
+	}
+
+	g = &runGuard{maxIterations: 2}
+	if g.stop([]fantasy.StepResult{a, step(fantasy.FinishReasonStop)}) {
+		t.Error("a run that finished at the limit was not cut short")
+	}
+}
@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/guilhermegouw/cdd/internal/debug"
+)
+
+// handoffSystemPrompt instructs the outgoing model how to brief the model
+// taking over a session.
+const handoffSystemPrompt = `You are handing a conversation between a user and an AI coding assistant over to another AI model, which will continue the work from your notes alone.
+
+Write a briefing that preserves:
+- The user's goals and any requirements or constraints they stated
+- Decisions made and the reasoning behind them
+- Files that were read, created, or modified, with the relevant details
+- Commands run and their important results or errors
+- What was being worked on last, and the next steps you would take
+
+Be specific: the next model has not seen the conversation. Do not add commentary or invent details. Output only the briefing.`
+
+// ErrNothingToHandOff is returned by Handoff for a session without messages.
+var ErrNothingToHandOff = NewError("session has no messages to hand off")
+
+// Handoff prepares a session to continue on another model. The current model
+// writes a briefing of the session, which is stored as a summary, so the next
+// model starts from it rather than from messages another provider produced.
+// The caller then swaps the model with SetModel. Handoff counts as a request,
+// so the session is busy, and can be cancelled, while it runs.
+func (a *DefaultAgent) Handoff(ctx context.Context, sessionID string) (Message, error) {
+	if a.IsBusy(sessionID) {
+		return Message{}, ErrSessionBusy
+	}
+	history := activeMessages(a.sessions.GetMessages(sessionID))
+	if len(history) == 0 {
+		return Message{}, ErrNothingToHandOff
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	a.setActiveRequest(sessionID, cancel)
+	defer func() {
+		a.clearActiveRequest(sessionID)
+		cancel()
+	}()
+
+	a.mu.RLock()
+	model := a.model
+	a.mu.RUnlock()
+
+	debug.Log("[HANDOFF] Briefing %d messages with %s/%s", len(history), model.Provider(), model.Model())
+	summary, err := summarize(ctx, model, handoffSystemPrompt, history)
+	if err != nil {
+		return Message{}, err
+	}
+
+	a.transcribe(sessionID, summary)
+	if !a.sessions.AddMessage(sessionID, summary) {
+		return Message{}, NewError("saving the handoff summary failed")
+	}
+	return summary, nil
+}
@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"charm.land/fantasy"
+)
+
+func TestHandoff(t *testing.T) {
+	var instructions string
+	ag := New(Config{Model: &mockModel{
+		generateFunc: func(_ context.Context, call fantasy.Call) (*fantasy.Response, error) {
+			instructions = call.Prompt[0].Content[1].(fantasy.TextPart).Text
+			return &fantasy.Response{
+				Content: fantasy.ResponseContent{fantasy.TextContent{Text: "the briefing"}},
+			}, nil
+		},
+	}})
+	sess := ag.Sessions().Create("Test")
+
+	if _, err := ag.Handoff(context.Background(), sess.ID); !errors.Is(err, ErrNothingToHandOff) {
+		t.Errorf("Handoff() of an empty session error = %v, want ErrNothingToHandOff", err)
+	}
+
+	ag.Sessions().AddMessage(sess.ID, Message{ID: "u1", Role: RoleUser, Content: "fix the parser"})
+	ag.Sessions().AddMessage(sess.ID, Message{ID: "a1", Role: RoleAssistant, Content: "on it", Reasoning: "signed thinking"})
+	summary, err := ag.Handoff(context.Background(), sess.ID)
+	if err != nil {
+		t.Fatalf("Handoff() error = %v", err)
+	}
+	if !summary.IsSummary || summary.Content != "the briefing" {
+		t.Errorf("Handoff() = %+v, want the briefing as a summary", summary)
+	}
+	if !strings.Contains(instructions, "handing a conversation") {
+		t.Errorf("the model was asked to %q, want a handoff briefing", instructions)
+	}
+	if ag.IsBusy(sess.ID) {
+		t.Error("the session should not be busy after the handoff")
+	}
+
+	// The next model only sees the briefing.
+	ag.Sessions().AddMessage(sess.ID, Message{Role: RoleUser, Content: "carry on"})
+	history := ag.buildHistory(sess.ID)
+	if len(history) != 1 || !strings.Contains(history[0].Content[0].(fantasy.TextPart).Text, "the briefing") {
+		t.Errorf("history after the handoff = %+v, want only the briefing", history)
+	}
+}
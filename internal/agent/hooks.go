@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"context"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/hooks"
+	"github.com/guilhermegouw/cdd/internal/tools"
+)
+
+// hookedTool wraps a tool so the pre_tool and post_tool hooks run around
+// each call.
+type hookedTool struct {
+	fantasy.AgentTool
+	hooks *hooks.Runner
+}
+
+// hookTools wraps every tool in list with hookedTool.
+func hookTools(list []fantasy.AgentTool, runner *hooks.Runner) []fantasy.AgentTool {
+	wrapped := make([]fantasy.AgentTool, len(list))
+	for i, tool := range list {
+		wrapped[i] = hookedTool{AgentTool: tool, hooks: runner}
+	}
+	return wrapped
+}
+
+// Run executes the tool unless a pre_tool hook blocks it, with the input the
+// hooks settled on. Failing post_tool hooks are reported in the result.
+func (t hookedTool) Run(ctx context.Context, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+	sessionID := tools.SessionIDFromContext(ctx)
+	input, err := t.hooks.PreTool(ctx, sessionID, hooks.NewTool(call.ID, call.Name, call.Input))
+	if err != nil {
+		return fantasy.NewTextErrorResponse(err.Error()), nil
+	}
+	call.Input = string(input)
+
+	resp, err := t.AgentTool.Run(ctx, call)
+	if err != nil {
+		return resp, err
+	}
+	result := hooks.Result{Content: resp.Content, IsError: resp.IsError}
+	if feedback := t.hooks.PostTool(ctx, sessionID, hooks.NewTool(call.ID, call.Name, call.Input), result); feedback != "" {
+		resp.Content += "\n\n" + feedback
+	}
+	return resp, nil
+}
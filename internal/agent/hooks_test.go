@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/hooks"
+)
+
+func TestHookedTool(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping hook tests on Windows")
+	}
+
+	type echoInput struct {
+		Text string `json:"text"`
+	}
+	var ran []string
+	echo := fantasy.NewAgentTool("echo", "Echo text",
+		func(_ context.Context, in echoInput, _ fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			ran = append(ran, in.Text)
+			return fantasy.NewTextResponse(in.Text), nil
+		})
+
+	runner := hooks.New(t.TempDir(), &config.HooksConfig{
+		PreTool: []config.HookConfig{
+			{Command: `grep -q secret && { echo "no secrets" >&2; exit 1; }; echo '{"text":"rewritten"}'`},
+		},
+		PostTool: []config.HookConfig{{Command: "echo lint failed; exit 1"}},
+	})
+	tool := hookTools([]fantasy.AgentTool{echo}, runner)[0]
+
+	resp, err := tool.Run(context.Background(), fantasy.ToolCall{ID: "1", Name: "echo", Input: `{"text":"secret"}`})
+	if err != nil || !resp.IsError || !strings.Contains(resp.Content, "no secrets") {
+		t.Errorf("blocked call = %+v, %v", resp, err)
+	}
+
+	resp, err = tool.Run(context.Background(), fantasy.ToolCall{ID: "2", Name: "echo", Input: `{"text":"hello"}`})
+	if err != nil || resp.Content != "rewritten\n\npost_tool hook failed: lint failed" {
+		t.Errorf("hooked call = %+v, %v", resp, err)
+	}
+	if len(ran) != 1 || ran[0] != "rewritten" {
+		t.Errorf("tool ran with %q, want only the rewritten input", ran)
+	}
+}
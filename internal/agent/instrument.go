@@ -0,0 +1,223 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"charm.land/fantasy"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/metrics"
+	"github.com/guilhermegouw/cdd/internal/telemetry"
+	"github.com/guilhermegouw/cdd/internal/tools"
+	"github.com/guilhermegouw/cdd/internal/transcript"
+)
+
+// requestTracker follows the provider requests of a run, one per step, and
+// records each as a span and in the metrics. It also times how fast the
+// model streams.
+type requestTracker struct {
+	agent      *DefaultAgent
+	ctx        context.Context //nolint:containedctx // Parent of the request spans
+	sessionID  string
+	model      fantasy.LanguageModel // Model the run sends its requests to
+	span       trace.Span            // Request in flight, if any
+	sent       time.Time             // When the request in flight was sent
+	firstToken time.Time             // When it streamed its first token, zero before then
+	timing     Timing                // The run's steps so far
+}
+
+// start begins tracking the request of a step.
+func (r *requestTracker) start(step int) {
+	r.done(nil)
+	r.sent = time.Now()
+	r.firstToken = time.Time{}
+	_, r.span = telemetry.Tracer().Start(r.ctx, "llm.request", trace.WithAttributes(
+		telemetry.SessionID.String(r.sessionID),
+		attribute.String("gen_ai.system", r.model.Provider()),
+		attribute.String("gen_ai.request.model", r.model.Model()),
+		attribute.Int("cdd.step", step),
+	))
+}
+
+// finish records a request that streamed to the end.
+func (r *requestTracker) finish(usage fantasy.Usage, finishReason fantasy.FinishReason) {
+	if r.span == nil {
+		return
+	}
+	r.span.SetAttributes(
+		attribute.String("gen_ai.response.finish_reason", string(finishReason)),
+		attribute.Int64("gen_ai.usage.input_tokens", usage.InputTokens),
+		attribute.Int64("gen_ai.usage.output_tokens", usage.OutputTokens),
+	)
+	r.span.End()
+	r.span = nil
+	if !r.firstToken.IsZero() {
+		r.timing.Generation += time.Since(r.firstToken)
+	}
+	r.timing.OutputTokens += usage.OutputTokens
+	r.agent.metrics.ObserveRequest(r.model.Provider(), r.model.Model(), usage.InputTokens, usage.OutputTokens, nil)
+	r.agent.recordUsage(r.ctx, r.sessionID, r.model, usage)
+}
+
+// chunk notes the first token of the request in flight: text, reasoning or
+// tool input. The first of the run is its time to first token.
+func (r *requestTracker) chunk(part fantasy.StreamPart) error {
+	if !r.firstToken.IsZero() {
+		return nil
+	}
+	//nolint:exhaustive // Other parts carry no tokens
+	switch part.Type {
+	case fantasy.StreamPartTypeTextDelta, fantasy.StreamPartTypeReasoningDelta, fantasy.StreamPartTypeToolInputDelta:
+		if part.Delta == "" {
+			return nil
+		}
+	case fantasy.StreamPartTypeToolCall:
+	default:
+		return nil
+	}
+	r.firstToken = time.Now()
+	if r.timing.FirstToken == 0 {
+		r.timing.FirstToken = r.firstToken.Sub(r.sent)
+	}
+	return nil
+}
+
+// measured returns the timing of the run, or nil if no token was streamed.
+func (r *requestTracker) measured() *Timing {
+	if r.timing.FirstToken == 0 {
+		return nil
+	}
+	timing := r.timing
+	return &timing
+}
+
+// done ends the request in flight, if any, as failed when err is set.
+func (r *requestTracker) done(err error) {
+	if r.span == nil {
+		return
+	}
+	telemetry.End(r.span, err)
+	r.span = nil
+	r.agent.metrics.ObserveRequest(r.model.Provider(), r.model.Model(), 0, 0, err)
+}
+
+// addMessage saves a message to the session inside a span, since it usually
+// means a database write.
+func (a *DefaultAgent) addMessage(ctx context.Context, sessionID string, msg Message) bool {
+	_, span := telemetry.Tracer().Start(ctx, "db.add_message", trace.WithAttributes(
+		telemetry.SessionID.String(sessionID),
+		telemetry.MessageID.String(msg.ID),
+		attribute.String("cdd.message.role", string(msg.Role)),
+	))
+	defer span.End()
+
+	a.transcribe(sessionID, msg)
+	if !a.sessions.AddMessage(sessionID, msg) {
+		span.SetStatus(codes.Error, "message not saved")
+		return false
+	}
+	return true
+}
+
+// addMessages saves the messages of a turn to the session in one write, inside
+// a span.
+func (a *DefaultAgent) addMessages(ctx context.Context, sessionID string, msgs []Message) bool {
+	if len(msgs) == 0 {
+		return true
+	}
+	_, span := telemetry.Tracer().Start(ctx, "db.add_messages", trace.WithAttributes(
+		telemetry.SessionID.String(sessionID),
+		attribute.Int("cdd.message.count", len(msgs)),
+	))
+	defer span.End()
+
+	a.transcribe(sessionID, msgs...)
+	if !a.sessions.AddMessages(sessionID, msgs) {
+		span.SetStatus(codes.Error, "messages not saved")
+		return false
+	}
+	return true
+}
+
+// transcribe appends messages to the session's transcript, if one is kept.
+// It is written before the database, so a failed save is still on record.
+func (a *DefaultAgent) transcribe(sessionID string, msgs ...Message) {
+	if a.transcript == nil {
+		return
+	}
+	records := make([]transcript.Record, len(msgs))
+	for i := range msgs {
+		records[i] = transcriptRecord(sessionID, &msgs[i])
+	}
+	if err := a.transcript.Write(sessionID, records...); err != nil {
+		debug.Error("agent", err, "writing transcript")
+	}
+}
+
+// transcriptRecord converts a message for the transcript.
+func transcriptRecord(sessionID string, msg *Message) transcript.Record {
+	r := transcript.Record{
+		Time:        msg.CreatedAt,
+		SessionID:   sessionID,
+		MessageID:   msg.ID,
+		Role:        string(msg.Role),
+		Content:     msg.Content,
+		Reasoning:   msg.Reasoning,
+		Summary:     msg.IsSummary,
+		Cancelled:   msg.Cancelled,
+		Interrupted: msg.Interrupted,
+	}
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	for _, tc := range msg.ToolCalls {
+		r.ToolCalls = append(r.ToolCalls, transcript.ToolCall{ID: tc.ID, Name: tc.Name, Input: tc.Input})
+	}
+	for _, tr := range msg.ToolResults {
+		r.ToolResults = append(r.ToolResults, transcript.ToolResult{
+			ToolCallID: tr.ToolCallID, Name: tr.Name, Content: tr.Content, IsError: tr.IsError,
+		})
+	}
+	for _, att := range msg.Attachments {
+		r.Attachments = append(r.Attachments, att.Filename)
+	}
+	return r
+}
+
+// instrumentedTool wraps a tool so each execution gets its own span and is
+// timed in the metrics.
+type instrumentedTool struct {
+	fantasy.AgentTool
+	metrics *metrics.Metrics
+}
+
+// instrumentTools wraps every tool in list with instrumentedTool.
+func instrumentTools(list []fantasy.AgentTool, m *metrics.Metrics) []fantasy.AgentTool {
+	wrapped := make([]fantasy.AgentTool, len(list))
+	for i, tool := range list {
+		wrapped[i] = instrumentedTool{AgentTool: tool, metrics: m}
+	}
+	return wrapped
+}
+
+// Run executes the tool inside a span.
+func (t instrumentedTool) Run(ctx context.Context, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+	started := time.Now()
+	ctx, span := telemetry.Tracer().Start(ctx, "tool "+call.Name, trace.WithAttributes(
+		telemetry.SessionID.String(tools.SessionIDFromContext(ctx)),
+		telemetry.ToolName.String(call.Name),
+		telemetry.ToolCallID.String(call.ID),
+	))
+
+	resp, err := t.AgentTool.Run(ctx, call)
+	if err == nil && resp.IsError {
+		span.SetStatus(codes.Error, resp.Content)
+	}
+	telemetry.End(span, err)
+	t.metrics.ObserveTool(call.Name, time.Since(started), err != nil || resp.IsError)
+	return resp, err
+}
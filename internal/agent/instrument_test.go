@@ -0,0 +1,163 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"charm.land/fantasy"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/guilhermegouw/cdd/internal/metrics"
+	"github.com/guilhermegouw/cdd/internal/telemetry"
+	"github.com/guilhermegouw/cdd/internal/transcript"
+)
+
+func TestAgentSend_Instrumentation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	type echoInput struct {
+		Text string `json:"text"`
+	}
+	echo := fantasy.NewAgentTool("echo", "Echo text",
+		func(_ context.Context, in echoInput, _ fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			return fantasy.NewTextResponse(in.Text), nil
+		})
+
+	var calls atomic.Int32
+	model := &mockModel{
+		streamFunc: func(_ context.Context, _ fantasy.Call) (fantasy.StreamResponse, error) {
+			first := calls.Add(1) == 1
+			return func(yield func(fantasy.StreamPart) bool) {
+				if first {
+					if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeToolCall, ID: "call-1", ToolCallName: "echo", ToolCallInput: `{"text":"hi"}`}) {
+						return
+					}
+					yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeFinish, FinishReason: fantasy.FinishReasonToolCalls})
+					return
+				}
+				if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeTextDelta, ID: "t1", Delta: "done"}) {
+					return
+				}
+				yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeFinish, FinishReason: fantasy.FinishReasonStop})
+			}, nil
+		},
+	}
+	m := metrics.New()
+	ag := New(Config{Model: model, Tools: []fantasy.AgentTool{echo}, Metrics: m})
+	sess := ag.Sessions().Create("Test")
+
+	if err := ag.Send(context.Background(), "say hi", SendOptions{SessionID: sess.ID}, StreamCallbacks{}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	counts := make(map[string]int)
+	var root sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		counts[span.Name()]++
+		if span.Name() == "agent.send" {
+			root = span
+		}
+	}
+	// The prompt is saved on its own, the rest of the turn in one write.
+	if counts["agent.send"] != 1 || counts["llm.request"] != 2 || counts["tool echo"] != 1 ||
+		counts["db.add_message"] != 1 || counts["db.add_messages"] != 1 {
+		t.Fatalf("unexpected spans %v", counts)
+	}
+	for _, span := range recorder.Ended() {
+		if span.Name() != "agent.send" && span.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Errorf("span %q should be a child of agent.send", span.Name())
+		}
+	}
+
+	attrs := make(map[string]string)
+	for _, kv := range root.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs[string(telemetry.SessionID)] != sess.ID || attrs[string(telemetry.MessageID)] == "" {
+		t.Errorf("agent.send should carry the session and message IDs, got %v", attrs)
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	for _, want := range []string{
+		`cdd_provider_requests_total{model="mock-model",provider="mock",status="ok"} 2`,
+		`cdd_tool_duration_seconds_count{status="ok",tool="echo"} 1`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("metrics missing %s", want)
+		}
+	}
+}
+
+func TestAgentSend_Transcript(t *testing.T) {
+	type echoInput struct {
+		Text string `json:"text"`
+	}
+	echo := fantasy.NewAgentTool("echo", "Echo text",
+		func(_ context.Context, in echoInput, _ fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			return fantasy.NewTextResponse(in.Text), nil
+		})
+
+	var calls atomic.Int32
+	model := &mockModel{
+		streamFunc: func(_ context.Context, _ fantasy.Call) (fantasy.StreamResponse, error) {
+			first := calls.Add(1) == 1
+			return func(yield func(fantasy.StreamPart) bool) {
+				if first {
+					if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeToolCall, ID: "call-1", ToolCallName: "echo", ToolCallInput: `{"text":"hi"}`}) {
+						return
+					}
+					yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeFinish, FinishReason: fantasy.FinishReasonToolCalls})
+					return
+				}
+				if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeTextDelta, ID: "t1", Delta: "done"}) {
+					return
+				}
+				yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeFinish, FinishReason: fantasy.FinishReasonStop})
+			}, nil
+		},
+	}
+	w := transcript.New(t.TempDir())
+	ag := New(Config{Model: model, Tools: []fantasy.AgentTool{echo}, Transcript: w})
+	sess := ag.Sessions().Create("Test")
+
+	if err := ag.Send(context.Background(), "say hi", SendOptions{SessionID: sess.ID}, StreamCallbacks{}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	data, err := os.ReadFile(w.Path(sess.ID))
+	if err != nil {
+		t.Fatalf("reading transcript: %v", err)
+	}
+	var records []transcript.Record
+	for line := range strings.Lines(string(data)) {
+		var r transcript.Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("transcript line %q: %v", line, err)
+		}
+		records = append(records, r)
+	}
+	var roles []string
+	for _, r := range records {
+		roles = append(roles, r.Role)
+	}
+	if got := strings.Join(roles, ","); got != "user,assistant,tool" {
+		t.Fatalf("transcript roles = %s, want the prompt, the answer and the tool result", got)
+	}
+	if records[0].Content != "say hi" || records[1].Content != "done" ||
+		records[1].ToolCalls[0].Input != `{"text":"hi"}` || records[2].ToolResults[0].Content != "hi" {
+		t.Errorf("transcript = %+v", records)
+	}
+}
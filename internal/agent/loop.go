@@ -2,17 +2,30 @@ package agent
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"strings"
 	"sync"
 	"time"
 
 	"charm.land/fantasy"
+	"charm.land/fantasy/providers/anthropic"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/guilhermegouw/cdd/internal/contextfiles"
 	"github.com/guilhermegouw/cdd/internal/debug"
 	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/hooks"
+	"github.com/guilhermegouw/cdd/internal/journal"
+	"github.com/guilhermegouw/cdd/internal/metrics"
 	"github.com/guilhermegouw/cdd/internal/pubsub"
+	"github.com/guilhermegouw/cdd/internal/session"
+	"github.com/guilhermegouw/cdd/internal/telemetry"
 	"github.com/guilhermegouw/cdd/internal/tools"
+	"github.com/guilhermegouw/cdd/internal/transcript"
+	"github.com/guilhermegouw/cdd/internal/usage"
 )
 
 // oauthSystemHeader is required as the first system content block for OAuth authentication.
@@ -22,13 +35,35 @@ const oauthSystemHeader = "You are Claude Code, Anthropic's official CLI for Cla
 // DefaultAgent implements the Agent interface using Fantasy.
 type DefaultAgent struct { //nolint:govet // fieldalignment: preserving logical field order
 	model          fantasy.LanguageModel
+	resolveModel   func(ctx context.Context, spec string) (fantasy.LanguageModel, error)
 	systemPrompt   string
 	tools          []fantasy.AgentTool
 	workingDir     string
 	sessions       Sessions
 	activeRequests map[string]context.CancelFunc
 	hub            *pubsub.Hub
+	compactor      *Compactor
+	contextWindow  int64
+	retry          RetryPolicy
+	contextFiles   []contextfiles.File
+	journal        *journal.Journal
+	runs           *RunLog
+	locks          *session.Locker
+	metrics        *metrics.Metrics
+	transcript     *transcript.Writer
+	hooks          *hooks.Runner
+	todos          *tools.TodoStore
+	usage          *usage.Tracker
+	budget         usage.Budget
+	maxIterations  int
+	maxToolOutput  int
+	toolOutputDir  string
+	modes          []Mode
+	mode           *Mode // Current mode, nil when there is none
 	mu             sync.RWMutex
+
+	running      sync.WaitGroup // Runs in progress, waited for by Interrupt
+	interrupting bool           // Interrupt was called; no new runs start
 }
 
 // New creates a new agent with the given configuration.
@@ -40,21 +75,52 @@ func New(cfg Config) *DefaultAgent {
 		sessions = NewSessionStore()
 	}
 
-	return &DefaultAgent{
+	systemPrompt := cfg.SystemPrompt
+	if projectContext := contextfiles.Prompt(cfg.ContextFiles); projectContext != "" {
+		systemPrompt += "\n\n" + projectContext
+	}
+
+	a := &DefaultAgent{
 		model:          cfg.Model,
-		systemPrompt:   cfg.SystemPrompt,
+		resolveModel:   cfg.ModelResolver,
+		systemPrompt:   systemPrompt,
 		tools:          cfg.Tools,
 		workingDir:     cfg.WorkingDir,
 		sessions:       sessions,
 		activeRequests: make(map[string]context.CancelFunc),
 		hub:            cfg.Hub,
+		compactor:      NewCompactor(cfg.SummaryModel, cfg.ContextWindow),
+		contextWindow:  cfg.ContextWindow,
+		retry:          cfg.Retry.withDefaults(),
+		contextFiles:   cfg.ContextFiles,
+		journal:        cfg.Journal,
+		runs:           cfg.Runs,
+		locks:          cfg.Locks,
+		metrics:        cfg.Metrics,
+		transcript:     cfg.Transcript,
+		hooks:          cfg.Hooks,
+		todos:          cfg.Todos,
+		usage:          cfg.Usage,
+		budget:         cfg.Budget,
+		maxIterations:  cfg.MaxIterations,
+		maxToolOutput:  cfg.MaxToolOutput,
+		toolOutputDir:  cfg.ToolOutputDir,
+		modes:          cfg.Modes,
 	}
+	if a.maxIterations == 0 {
+		a.maxIterations = DefaultMaxIterations
+	}
+	if a.maxToolOutput == 0 {
+		a.maxToolOutput = tools.MaxOutputLength
+	}
+	a.SetMode(cfg.Mode)
+	return a
 }
 
 // Send sends a prompt and streams the response.
 //
 //nolint:gocyclo // Complex function handling streaming, tools, and history management
-func (a *DefaultAgent) Send(ctx context.Context, prompt string, opts SendOptions, callbacks StreamCallbacks) error {
+func (a *DefaultAgent) Send(ctx context.Context, prompt string, opts SendOptions, callbacks StreamCallbacks) (err error) {
 	if prompt == "" {
 		return ErrEmptyPrompt
 	}
@@ -65,66 +131,153 @@ func (a *DefaultAgent) Send(ctx context.Context, prompt string, opts SendOptions
 		sessionID = session.ID
 	}
 
+	mode, err := a.sendMode(opts.Mode)
+	if err != nil {
+		return err
+	}
+	model, err := a.sendModel(ctx, opts.Model)
+	if err != nil {
+		return err
+	}
+
 	// Check if session is busy
 	if a.IsBusy(sessionID) {
 		return ErrSessionBusy
 	}
 
+	// Keep other cdd processes from writing to the session meanwhile
+	if a.locks != nil {
+		if err := a.locks.Lock(sessionID, false); err != nil {
+			return err //nolint:wrapcheck // Says which process holds the session
+		}
+		defer a.locks.Unlock(sessionID)
+	}
+
+	// Pause once a budget is used up, until the user agrees to go on
+	if !opts.IgnoreBudget {
+		if err := a.checkBudget(ctx, sessionID); err != nil {
+			return err
+		}
+	}
+
+	ctx, span := telemetry.Tracer().Start(ctx, "agent.send", trace.WithAttributes(telemetry.SessionID.String(sessionID)))
+	defer func() { telemetry.End(span, err) }()
+
 	// Create cancellable context
 	ctx, cancel := context.WithCancel(ctx)
-	a.setActiveRequest(sessionID, cancel)
+	if !a.setActiveRequest(sessionID, cancel) {
+		cancel()
+		return ErrInterrupted
+	}
 	defer func() {
 		a.clearActiveRequest(sessionID)
 		cancel()
 	}()
 
+	// Checked before any truncation below, so editing the first prompt does not
+	// start the session again.
+	newSession := a.hooks != nil && len(a.sessions.GetMessages(sessionID)) == 0
+
+	// Editing or retrying an earlier prompt drops it and everything after it
+	if opts.ReplaceFrom != "" && !a.sessions.TruncateMessages(sessionID, opts.ReplaceFrom) {
+		return ErrMessageNotFound
+	}
+
 	// Add context values for tools
 	ctx = tools.WithSessionID(ctx, sessionID)
-	ctx = tools.WithWorkingDir(ctx, a.workingDir)
+	ctx = tools.WithWorkingDir(ctx, a.SessionWorkingDir(sessionID))
+	ctx = hooks.WithWorkingDir(ctx, a.SessionWorkingDir(sessionID))
+	if a.journal != nil {
+		ctx = tools.WithChangeRecorder(ctx, a.journal)
+	}
+
+	// Set max tokens (Anthropic API requires this)
+	maxTokens := opts.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = 8192 // Default max tokens
+	}
+
+	if newSession {
+		a.hooks.SessionStart(ctx, sessionID)
+	}
+
+	// Summarize older history first if it no longer fits the context window
+	a.maybeCompact(ctx, sessionID, prompt, maxTokens)
 
 	// Add user message to history
 	userMsg := Message{
-		ID:        uuid.New().String(),
-		Role:      RoleUser,
-		Content:   prompt,
-		CreatedAt: time.Now(),
+		ID:          uuid.New().String(),
+		Role:        RoleUser,
+		Content:     prompt,
+		Attachments: opts.Attachments,
+		CreatedAt:   time.Now(),
+	}
+	a.addMessage(ctx, sessionID, userMsg)
+	span.SetAttributes(telemetry.MessageID.String(userMsg.ID))
+	if a.journal != nil {
+		a.journal.BeginTurn(sessionID, userMsg.ID, prompt)
+	}
+	if a.runs != nil {
+		a.runs.begin(InterruptedRun{SessionID: sessionID, MessageID: userMsg.ID, Prompt: prompt, StartedAt: userMsg.CreatedAt})
 	}
-	a.sessions.AddMessage(sessionID, userMsg)
 
 	// Build Fantasy agent
 	// Note: We don't use WithSystemPrompt because OAuth requires the system
 	// prompt to be sent as separate content blocks with the OAuth header first.
-	fantasyOpts := []fantasy.AgentOption{}
-	if len(a.tools) > 0 {
-		fantasyOpts = append(fantasyOpts, fantasy.WithTools(a.tools...))
+	// Retries are handled by stream so every transient error gets the same policy.
+	fantasyOpts := []fantasy.AgentOption{fantasy.WithMaxRetries(0)}
+	if agentTools := a.modeTools(mode); len(agentTools) > 0 {
+		if a.hooks != nil {
+			agentTools = hookTools(agentTools, a.hooks)
+		}
+		agentTools = limitTools(agentTools, a.maxToolOutput, a.toolOutputDir)
+		fantasyOpts = append(fantasyOpts, fantasy.WithTools(instrumentTools(agentTools, a.metrics)...))
 	}
 
-	agent := fantasy.NewAgent(a.model, fantasyOpts...)
+	agent := fantasy.NewAgent(model, fantasyOpts...)
 
 	// Prepare history with system messages at the start
 	// OAuth requires "You are Claude Code..." as a separate first block
 	messages := make([]fantasy.Message, 0, 2) //nolint:mnd // 1 system message + history
 	messages = append(messages, fantasy.NewSystemMessage(
-		oauthSystemHeader, // First block - required for OAuth
-		a.systemPrompt,    // Second block - actual system prompt
+		oauthSystemHeader,                   // First block - required for OAuth
+		a.modeSystemPrompt(sessionID, mode), // Second block - actual system prompt
 	))
 	messages = append(messages, a.buildHistory(sessionID)...)
 
 	// Stream call options
 	streamOpts := fantasy.AgentStreamCall{
-		Prompt:   prompt,
+		Prompt:   promptWithFiles(prompt, opts.Attachments),
+		Files:    fileParts(opts.Attachments),
 		Messages: messages,
 	}
 
-	// Set max tokens (Anthropic API requires this)
-	maxTokens := opts.MaxTokens
-	if maxTokens <= 0 {
-		maxTokens = 8192 // Default max tokens
-	}
 	streamOpts.MaxOutputTokens = &maxTokens
 	if opts.Temperature != nil {
 		streamOpts.Temperature = opts.Temperature
 	}
+	if opts.MaxTurns > 0 {
+		streamOpts.StopWhen = []fantasy.StopCondition{fantasy.StepCountIs(opts.MaxTurns)}
+	}
+	guard := &runGuard{maxIterations: a.maxIterations}
+	streamOpts.StopWhen = append(streamOpts.StopWhen, guard.stop)
+	var budgetErr error
+	if !opts.IgnoreBudget {
+		streamOpts.StopWhen = append(streamOpts.StopWhen, func(steps []fantasy.StepResult) bool {
+			budgetErr = a.checkBudgetBetweenSteps(ctx, sessionID, steps)
+			return budgetErr != nil
+		})
+	}
+	if opts.ThinkingBudget > 0 {
+		// Other providers ignore options keyed for Anthropic.
+		streamOpts.ProviderOptions = thinkingOptions(opts.ThinkingBudget)
+		streamOpts.Temperature = nil // Anthropic rejects sampling options while thinking
+		if maxTokens <= opts.ThinkingBudget {
+			// The budget counts towards max tokens, so leave room for the answer.
+			thinkingMax := maxTokens + opts.ThinkingBudget
+			streamOpts.MaxOutputTokens = &thinkingMax
+		}
+	}
 
 	// Track current assistant message and tool results
 	var currentAssistant *Message
@@ -138,7 +291,15 @@ func (a *DefaultAgent) Send(ctx context.Context, prompt string, opts SendOptions
 	// Track message ID for events
 	var messageID string
 
+	// Tool results can arrive from fantasy's tool goroutines while a cancelled
+	// run is being saved, so the turn state is only touched under turnMu.
+	// Once the turn is saved, late results are dropped: their calls already
+	// got a cancelled result and a second one would confuse the provider.
+	var turnMu sync.Mutex
+	saved := false
+
 	streamOpts.OnTextDelta = func(id, text string) error {
+		turnMu.Lock()
 		if currentAssistant == nil {
 			messageID = uuid.New().String()
 			currentAssistant = &Message{
@@ -150,6 +311,8 @@ func (a *DefaultAgent) Send(ctx context.Context, prompt string, opts SendOptions
 		}
 		contentBuilder.WriteString(text)
 		currentAssistant.Content = contentBuilder.String()
+		msgID := messageID
+		turnMu.Unlock()
 
 		// Debug: Log text deltas (truncated to avoid log spam)
 		debug.Log("[STREAM] TextDelta len=%d preview=%q", len(text), truncate(text, 30))
@@ -157,13 +320,14 @@ func (a *DefaultAgent) Send(ctx context.Context, prompt string, opts SendOptions
 		// Publish text delta event
 		if a.hub != nil {
 			a.hub.Agent.Publish(pubsub.EventProgress,
-				events.NewTextDeltaEvent(sessionID, messageID, text))
+				events.NewTextDeltaEvent(sessionID, msgID, text))
 		}
 
 		return nil
 	}
 
 	streamOpts.OnToolCall = func(tc fantasy.ToolCallContent) error {
+		turnMu.Lock()
 		if currentAssistant == nil {
 			messageID = uuid.New().String()
 			currentAssistant = &Message{
@@ -179,11 +343,13 @@ func (a *DefaultAgent) Send(ctx context.Context, prompt string, opts SendOptions
 			Input: tc.Input,
 		}
 		currentAssistant.ToolCalls = append(currentAssistant.ToolCalls, toolCall)
+		msgID := messageID
+		turnMu.Unlock()
 
 		// Publish tool call event
 		if a.hub != nil {
 			a.hub.Agent.Publish(pubsub.EventProgress,
-				events.NewToolCallEvent(sessionID, messageID, events.ToolCallInfo{
+				events.NewToolCallEvent(sessionID, msgID, events.ToolCallInfo{
 					ID:    tc.ToolCallID,
 					Name:  tc.ToolName,
 					Input: tc.Input,
@@ -219,6 +385,7 @@ func (a *DefaultAgent) Send(ctx context.Context, prompt string, opts SendOptions
 			// Handle other types (e.g., Media) - treat as text fallback
 			tr.Content = "[Unsupported tool result type]"
 		}
+		tr.FullOutput = a.savedFullOutput(sessionID, tr.ToolCallID)
 
 		// Collect tool result to save AFTER assistant message (preserves correct order)
 		toolMsg := Message{
@@ -227,12 +394,20 @@ func (a *DefaultAgent) Send(ctx context.Context, prompt string, opts SendOptions
 			ToolResults: []ToolResult{tr},
 			CreatedAt:   time.Now(),
 		}
+		turnMu.Lock()
+		if saved {
+			turnMu.Unlock()
+			debug.Log("[STREAM] Dropped tool result after the turn was saved call=%s", tr.ToolCallID)
+			return nil
+		}
 		pendingToolResults = append(pendingToolResults, toolMsg)
+		msgID := messageID
+		turnMu.Unlock()
 
 		// Publish tool result event
 		if a.hub != nil {
 			a.hub.Agent.Publish(pubsub.EventProgress,
-				events.NewToolResultEvent(sessionID, messageID, events.ToolResultInfo{
+				events.NewToolResultEvent(sessionID, msgID, events.ToolResultInfo{
 					ToolCallID: tr.ToolCallID,
 					Name:       tr.Name,
 					Content:    tr.Content,
@@ -258,12 +433,22 @@ func (a *DefaultAgent) Send(ctx context.Context, prompt string, opts SendOptions
 		// Reset reasoning for new block
 		reasoningBuilder.Reset()
 		reasoningBuilder.WriteString(reasoning.Text)
+
+		if a.hub != nil && reasoning.Text != "" {
+			a.hub.Agent.Publish(pubsub.EventProgress,
+				events.NewReasoningDeltaEvent(sessionID, messageID, reasoning.Text))
+		}
 		return nil
 	}
 
 	streamOpts.OnReasoningDelta = func(id, text string) error {
 		debug.Log("[REASONING] Delta id=%s text=%q", id, truncate(text, 50))
 		reasoningBuilder.WriteString(text)
+
+		if a.hub != nil && text != "" {
+			a.hub.Agent.Publish(pubsub.EventProgress,
+				events.NewReasoningDeltaEvent(sessionID, messageID, text))
+		}
 		return nil
 	}
 
@@ -281,28 +466,99 @@ func (a *DefaultAgent) Send(ctx context.Context, prompt string, opts SendOptions
 		return nil
 	}
 
-	// Execute the agent
-	_, err := agent.Stream(ctx, streamOpts)
-
-	// Store reasoning in assistant message before saving
-	reasoningContent := reasoningBuilder.String()
-	if currentAssistant != nil && reasoningContent != "" {
-		currentAssistant.Reasoning = reasoningContent
-		currentAssistant.ReasoningMetadata = reasoningMetadata
-		debug.Log("[REASONING] Stored reasoning in message: len=%d hasMetadata=%v",
-			len(reasoningContent), reasoningMetadata != nil)
+	// Each step is a provider request, traced and counted on its own.
+	requests := &requestTracker{agent: a, ctx: ctx, sessionID: sessionID, model: model}
+	streamOpts.OnStepStart = func(step int) error {
+		requests.start(step)
+		return nil
+	}
+	streamOpts.OnStreamFinish = func(usage fantasy.Usage, finishReason fantasy.FinishReason, _ fantasy.ProviderMetadata) error {
+		requests.finish(usage, finishReason)
+		return nil
 	}
+	streamOpts.OnError = requests.done
+	streamOpts.OnChunk = requests.chunk
+
+	// saveTurn saves the turn in one write: the assistant message FIRST, then
+	// the tool results (they reference tool_calls in assistant message). What
+	// was streamed before a cancel is kept, marked as stopped by the user or,
+	// when interrupted, by cdd exiting.
+	saveTurn := func(cancelled, interrupted bool) {
+		turnMu.Lock()
+		defer turnMu.Unlock()
+		saved = true
+		if cancelled {
+			if currentAssistant == nil {
+				messageID = uuid.New().String()
+				currentAssistant = &Message{ID: messageID, Role: RoleAssistant, CreatedAt: time.Now()}
+			}
+			currentAssistant.Cancelled = true
+			currentAssistant.Interrupted = interrupted
+			pendingToolResults = append(pendingToolResults, cancelledToolResults(currentAssistant.ToolCalls, pendingToolResults, interrupted)...)
+		}
+
+		// Store reasoning in assistant message before saving
+		reasoningContent := reasoningBuilder.String()
+		if currentAssistant != nil && reasoningContent != "" {
+			currentAssistant.Reasoning = reasoningContent
+			currentAssistant.ReasoningMetadata = reasoningMetadata
+			debug.Log("[REASONING] Stored reasoning in message: len=%d hasMetadata=%v",
+				len(reasoningContent), reasoningMetadata != nil)
+		}
+
+		if currentAssistant != nil {
+			currentAssistant.Timing = requests.measured()
+		}
 
-	// Save assistant message FIRST (before tool results to maintain correct order)
-	if currentAssistant != nil && (currentAssistant.Content != "" || len(currentAssistant.ToolCalls) > 0 || currentAssistant.Reasoning != "") {
-		a.sessions.AddMessage(sessionID, *currentAssistant)
+		turn := make([]Message, 0, len(pendingToolResults)+1)
+		if currentAssistant != nil && (currentAssistant.Content != "" || len(currentAssistant.ToolCalls) > 0 || currentAssistant.Reasoning != "" || currentAssistant.Cancelled) {
+			turn = append(turn, *currentAssistant)
+		}
+		turn = append(turn, pendingToolResults...)
+		a.addMessages(ctx, sessionID, turn)
+
+		// The run log now only needs to remember runs left unfinished
+		if a.runs != nil {
+			if interrupted {
+				a.runs.interrupt(sessionID)
+			} else {
+				a.runs.end(sessionID)
+			}
+		}
 	}
 
-	// Save tool results AFTER assistant message (they reference tool_calls in assistant message)
-	for i := range pendingToolResults {
-		a.sessions.AddMessage(sessionID, pendingToolResults[i])
+	// A panic, in a tool say, ends the run as if cdd had exited, so the reply
+	// so far is not lost with it.
+	defer func() {
+		if r := recover(); r != nil {
+			if !saved {
+				saveTurn(true, true)
+			}
+			panic(r)
+		}
+	}()
+
+	// Execute the agent
+	result, err := a.stream(ctx, sessionID, agent, streamOpts, func() bool {
+		turnMu.Lock()
+		defer turnMu.Unlock()
+		return currentAssistant != nil || len(pendingToolResults) > 0 || reasoningBuilder.Len() > 0
+	})
+	requests.done(err)
+	if err == nil && guard.err != nil {
+		err = guard.err
 	}
 
+	cancelled := errors.Is(err, context.Canceled)
+	saveTurn(cancelled, cancelled && a.isInterrupting())
+
+	if cancelled {
+		if a.hub != nil {
+			a.hub.Agent.Publish(pubsub.EventCompleted,
+				events.NewCancelledEvent(sessionID, messageID))
+		}
+		return err
+	}
 	if err != nil {
 		// Publish error event
 		if a.hub != nil {
@@ -315,12 +571,90 @@ func (a *DefaultAgent) Send(ctx context.Context, prompt string, opts SendOptions
 	// Publish completion event
 	if a.hub != nil {
 		a.hub.Agent.Publish(pubsub.EventCompleted,
-			events.NewCompleteEvent(sessionID, messageID))
+			events.NewCompleteEvent(sessionID, messageID, completionInfo(result)))
+	}
+	if a.hooks != nil {
+		a.hooks.Complete(ctx, sessionID)
 	}
 
+	// The run ended normally, only sooner than the model wanted
+	if budgetErr != nil {
+		return fmt.Errorf("%w: %w", ErrPaused, budgetErr)
+	}
 	return nil
 }
 
+// cancelledToolResults returns error results for the calls that never got
+// one, so the history sent to the model stays valid after a cancel.
+func cancelledToolResults(calls []ToolCall, results []Message, interrupted bool) []Message {
+	reason := "Cancelled by the user"
+	if interrupted {
+		reason = "Interrupted: cdd exited before the tool finished"
+	}
+	answered := make(map[string]bool, len(results))
+	for i := range results {
+		for _, tr := range results[i].ToolResults {
+			answered[tr.ToolCallID] = true
+		}
+	}
+	var missing []Message
+	for _, tc := range calls {
+		if answered[tc.ID] {
+			continue
+		}
+		missing = append(missing, Message{
+			ID:          uuid.New().String(),
+			Role:        RoleTool,
+			ToolResults: []ToolResult{{ToolCallID: tc.ID, Name: tc.Name, Content: reason, IsError: true}},
+			CreatedAt:   time.Now(),
+		})
+	}
+	return missing
+}
+
+// stream runs the agent, retrying transient provider errors with backoff.
+// Only failures that happen before anything was streamed are retried; once
+// text or tool activity reached the caller a retry would duplicate it.
+func (a *DefaultAgent) stream(ctx context.Context, sessionID string, agent fantasy.Agent, call fantasy.AgentStreamCall, produced func() bool) (*fantasy.AgentResult, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := agent.Stream(ctx, call)
+		if err == nil || attempt >= a.retry.MaxAttempts || produced() || !IsTransientError(err) {
+			return result, err
+		}
+
+		delay := a.retry.Delay(attempt, err)
+		debug.Log("[RETRY] Attempt %d/%d failed: %v (retrying in %s)", attempt, a.retry.MaxAttempts, err, delay)
+		if a.hub != nil {
+			a.hub.Agent.Publish(pubsub.EventProgress, events.NewRetryingEvent(sessionID, err, events.RetryInfo{
+				Attempt:     attempt + 1,
+				MaxAttempts: a.retry.MaxAttempts,
+				Delay:       delay,
+			}))
+		}
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// completionInfo summarizes how a run ended: the last step's finish reason and
+// the tokens used by all steps.
+func completionInfo(result *fantasy.AgentResult) events.CompletionInfo {
+	if result == nil {
+		return events.CompletionInfo{}
+	}
+	total := result.TotalUsage
+	return events.CompletionInfo{
+		FinishReason:        string(result.Response.FinishReason),
+		InputTokens:         total.InputTokens,
+		OutputTokens:        total.OutputTokens,
+		ReasoningTokens:     total.ReasoningTokens,
+		CacheCreationTokens: total.CacheCreationTokens,
+		CacheReadTokens:     total.CacheReadTokens,
+	}
+}
+
 // buildHistory converts session messages to Fantasy messages.
 func (a *DefaultAgent) buildHistory(sessionID string) []fantasy.Message {
 	messages := a.sessions.GetMessages(sessionID)
@@ -333,12 +667,19 @@ func (a *DefaultAgent) buildHistory(sessionID string) []fantasy.Message {
 		messages = messages[:len(messages)-1]
 	}
 
+	// A summary replaces everything before it
+	messages = activeMessages(messages)
+
 	var history []fantasy.Message
 	for i := range messages {
 		msg := &messages[i]
+		if msg.IsSummary {
+			history = append(history, fantasy.NewUserMessage(summaryHeader+"\n\n"+msg.Content))
+			continue
+		}
 		switch msg.Role {
 		case RoleUser:
-			history = append(history, fantasy.NewUserMessage(msg.Content))
+			history = append(history, fantasy.NewUserMessage(promptWithFiles(msg.Content, msg.Attachments), fileParts(msg.Attachments)...))
 
 		case RoleAssistant:
 			var parts []fantasy.MessagePart
@@ -393,6 +734,37 @@ func (a *DefaultAgent) buildHistory(sessionID string) []fantasy.Message {
 	return history
 }
 
+// maybeCompact summarizes the session's active history when it approaches the context window.
+// Failures are logged and ignored so the request can still proceed.
+func (a *DefaultAgent) maybeCompact(ctx context.Context, sessionID, prompt string, maxOutput int64) {
+	if a.compactor == nil {
+		return
+	}
+
+	history := activeMessages(a.sessions.GetMessages(sessionID))
+	reserved := int64((len(a.SystemPrompt(sessionID))+len(prompt))/charsPerToken) + maxOutput
+	if !a.compactor.ShouldCompact(history, reserved) {
+		return
+	}
+
+	debug.Log("[COMPACT] Summarizing %d messages (~%d tokens)", len(history), EstimateTokens(history))
+	summary, err := a.compactor.Summarize(ctx, history)
+	if err != nil {
+		debug.Error("agent", err, "compacting session")
+		return
+	}
+
+	a.transcribe(sessionID, summary)
+	if !a.sessions.AddMessage(sessionID, summary) {
+		debug.Log("[COMPACT] Failed to store summary for session %s", sessionID)
+		return
+	}
+
+	if a.hub != nil {
+		a.hub.Agent.Publish(pubsub.EventProgress, events.NewCompactedEvent(sessionID, summary.ID))
+	}
+}
+
 // SetModel updates the agent's language model.
 // This is used to swap in a new model after token refresh without losing session history.
 func (a *DefaultAgent) SetModel(model fantasy.LanguageModel) {
@@ -401,6 +773,24 @@ func (a *DefaultAgent) SetModel(model fantasy.LanguageModel) {
 	a.model = model
 }
 
+// sendModel returns the model a prompt runs with: the one spec names, or
+// the agent's model when spec is empty.
+func (a *DefaultAgent) sendModel(ctx context.Context, spec string) (fantasy.LanguageModel, error) {
+	if spec == "" {
+		a.mu.RLock()
+		defer a.mu.RUnlock()
+		return a.model, nil
+	}
+	if a.resolveModel == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownModel, spec)
+	}
+	model, err := a.resolveModel(ctx, spec)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnknownModel, err)
+	}
+	return model, nil
+}
+
 // SetSystemPrompt sets the system prompt.
 func (a *DefaultAgent) SetSystemPrompt(prompt string) {
 	a.mu.Lock()
@@ -408,6 +798,45 @@ func (a *DefaultAgent) SetSystemPrompt(prompt string) {
 	a.systemPrompt = prompt
 }
 
+// SystemPrompt returns the system prompt sent for a session: the session's
+// own prompt followed by the project context, or else the agent's, then the
+// instructions of the current mode.
+func (a *DefaultAgent) SystemPrompt(sessionID string) string {
+	return a.modeSystemPrompt(sessionID, a.currentMode())
+}
+
+// basePrompt returns the system prompt of a session without the mode's
+// instructions.
+func (a *DefaultAgent) basePrompt(sessionID string) string {
+	prompt := a.sessionPrompt(sessionID)
+	if sess, ok := a.sessions.Get(sessionID); ok && sess.WorkingDir != "" && sess.WorkingDir != a.workingDir {
+		prompt += "\n\nThe working directory of this session is " + sess.WorkingDir +
+			"; relative paths and commands are resolved against it."
+	}
+	return prompt
+}
+
+// sessionPrompt returns the session's own system prompt followed by the
+// project context, or else the agent's.
+func (a *DefaultAgent) sessionPrompt(sessionID string) string {
+	if sess, ok := a.sessions.Get(sessionID); ok && sess.SystemPrompt != "" {
+		if projectContext := contextfiles.Prompt(a.contextFiles); projectContext != "" {
+			return sess.SystemPrompt + "\n\n" + projectContext
+		}
+		return sess.SystemPrompt
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.systemPrompt
+}
+
+// SetSessionSystemPrompt replaces the system prompt for one session; an empty
+// prompt restores the agent's. It reports whether the prompt was saved.
+func (a *DefaultAgent) SetSessionSystemPrompt(sessionID, prompt string) bool {
+	return a.sessions.SetSystemPrompt(sessionID, prompt)
+}
+
 // SetTools sets the available tools.
 func (a *DefaultAgent) SetTools(toolList []fantasy.AgentTool) {
 	a.mu.Lock()
@@ -444,24 +873,151 @@ func (a *DefaultAgent) IsBusy(sessionID string) bool {
 	return ok
 }
 
+// WorkingDir returns the directory the agent's tools operate in.
+func (a *DefaultAgent) WorkingDir() string {
+	return a.workingDir
+}
+
+// SessionWorkingDir returns the directory a session's tools operate in: the
+// session's own, or else the agent's.
+func (a *DefaultAgent) SessionWorkingDir(sessionID string) string {
+	if sess, ok := a.sessions.Get(sessionID); ok && sess.WorkingDir != "" {
+		return sess.WorkingDir
+	}
+	return a.workingDir
+}
+
+// SetSessionWorkingDir changes the directory a session's tools operate in; an
+// empty dir restores the agent's. It reports whether the change was saved.
+func (a *DefaultAgent) SetSessionWorkingDir(sessionID, dir string) bool {
+	return a.sessions.SetWorkingDir(sessionID, dir)
+}
+
+// ContextFiles returns the project context files included in the system prompt.
+func (a *DefaultAgent) ContextFiles() []contextfiles.File {
+	return a.contextFiles
+}
+
+// Journal returns the journal of file changes, or nil when changes are not
+// recorded.
+func (a *DefaultAgent) Journal() *journal.Journal {
+	return a.journal
+}
+
+// Usage returns the record of tokens and cost, or nil when requests are not
+// recorded.
+func (a *DefaultAgent) Usage() *usage.Tracker {
+	return a.usage
+}
+
+// ContextUsage estimates the tokens the session's next request would send,
+// system prompt included, and returns them with the model's context window,
+// which is 0 when it is not known.
+func (a *DefaultAgent) ContextUsage(sessionID string) (used, window int64) {
+	history := activeMessages(a.sessions.GetMessages(sessionID))
+	used = EstimateTokens(history) + int64(len(a.SystemPrompt(sessionID))/charsPerToken)
+	return used, a.contextWindow
+}
+
+// Todos returns the todo lists the agent writes, or nil when it has no
+// todo_write tool.
+func (a *DefaultAgent) Todos() *tools.TodoStore {
+	return a.todos
+}
+
 // Sessions returns the session store.
 func (a *DefaultAgent) Sessions() Sessions {
 	return a.sessions
 }
 
-func (a *DefaultAgent) setActiveRequest(sessionID string, cancel context.CancelFunc) {
+// Interrupt stops every run in progress because cdd is exiting, and waits
+// until each has saved its reply so far, marked as interrupted, or ctx is
+// done. No run starts after it is called.
+func (a *DefaultAgent) Interrupt(ctx context.Context) error {
+	a.mu.Lock()
+	a.interrupting = true
+	for _, cancel := range a.activeRequests {
+		cancel()
+	}
+	a.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		a.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("saving interrupted runs: %w", ctx.Err())
+	}
+}
+
+// Busy returns the number of runs in progress across all sessions.
+func (a *DefaultAgent) Busy() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return len(a.activeRequests)
+}
+
+// Locks returns the session locks, or nil when sessions are not locked.
+func (a *DefaultAgent) Locks() *session.Locker {
+	return a.locks
+}
+
+// Runs returns the record of runs in progress, or nil when runs are not
+// recorded.
+func (a *DefaultAgent) Runs() *RunLog {
+	return a.runs
+}
+
+// Close waits for the session store to finish writing, for stores that
+// write in the background.
+func (a *DefaultAgent) Close() error {
+	if c, ok := a.sessions.(io.Closer); ok {
+		return c.Close() //nolint:wrapcheck // Store errors are already descriptive
+	}
+	return nil
+}
+
+// setActiveRequest registers a run, unless the agent is being interrupted.
+func (a *DefaultAgent) setActiveRequest(sessionID string, cancel context.CancelFunc) bool {
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	if a.interrupting {
+		return false
+	}
 	a.activeRequests[sessionID] = cancel
+	a.running.Add(1)
+	return true
 }
 
 func (a *DefaultAgent) clearActiveRequest(sessionID string) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	delete(a.activeRequests, sessionID)
+	a.running.Done()
+}
+
+// isInterrupting reports whether runs are being stopped because cdd is
+// exiting.
+func (a *DefaultAgent) isInterrupting() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.interrupting
 }
 
 // truncate truncates a string to maxLen characters, adding "..." if truncated.
+// thinkingOptions enables Anthropic extended thinking with the given budget.
+func thinkingOptions(budget int64) fantasy.ProviderOptions {
+	return fantasy.ProviderOptions{
+		anthropic.Name: &anthropic.ProviderOptions{
+			Thinking: &anthropic.ThinkingProviderOption{BudgetTokens: budget},
+		},
+	}
+}
+
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -3,11 +3,22 @@ package agent
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"charm.land/fantasy"
+	"charm.land/fantasy/providers/anthropic"
+
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+	"github.com/guilhermegouw/cdd/internal/session"
+	"github.com/guilhermegouw/cdd/internal/tools"
 )
 
 // mockModel implements fantasy.LanguageModel for testing.
@@ -173,6 +184,84 @@ func TestAgentSetSystemPrompt(t *testing.T) {
 	})
 }
 
+func TestAgentSessionSystemPrompt(t *testing.T) {
+	agent := New(Config{
+		Model:        &mockModel{},
+		SystemPrompt: "Default",
+	})
+	session := agent.Sessions().Create("test")
+
+	if got := agent.SystemPrompt(session.ID); got != "Default" {
+		t.Errorf("SystemPrompt() = %q, want the default", got)
+	}
+
+	if !agent.SetSessionSystemPrompt(session.ID, "Custom") {
+		t.Fatal("SetSessionSystemPrompt() failed")
+	}
+	if got := agent.SystemPrompt(session.ID); got != "Custom" {
+		t.Errorf("SystemPrompt() = %q, want the session's prompt", got)
+	}
+	if got := agent.SystemPrompt("other"); got != "Default" {
+		t.Errorf("other sessions should keep the default, got %q", got)
+	}
+
+	agent.SetSessionSystemPrompt(session.ID, "")
+	if got := agent.SystemPrompt(session.ID); got != "Default" {
+		t.Errorf("an empty prompt should restore the default, got %q", got)
+	}
+	if agent.SetSessionSystemPrompt("missing", "Custom") {
+		t.Error("SetSessionSystemPrompt() should fail for an unknown session")
+	}
+}
+
+func TestAgentSessionWorkingDir(t *testing.T) {
+	var seen string
+	probe := fantasy.NewAgentTool("probe", "Reports the working directory",
+		func(ctx context.Context, _ struct{}, _ fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			seen = tools.WorkingDirFromContext(ctx)
+			return fantasy.NewTextResponse("ok"), nil
+		})
+	var calls int
+	model := &mockModel{
+		streamFunc: func(context.Context, fantasy.Call) (fantasy.StreamResponse, error) {
+			calls++
+			return func(yield func(fantasy.StreamPart) bool) {
+				if calls%2 == 1 {
+					if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeToolCall, ID: "call", ToolCallName: "probe", ToolCallInput: "{}"}) {
+						return
+					}
+					yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeFinish, FinishReason: fantasy.FinishReasonToolCalls})
+					return
+				}
+				yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeFinish, FinishReason: fantasy.FinishReasonStop})
+			}, nil
+		},
+	}
+	agent := New(Config{Model: model, Tools: []fantasy.AgentTool{probe}, WorkingDir: "/repo", SystemPrompt: "Default"})
+	session := agent.Sessions().Create("test")
+
+	if got := agent.SessionWorkingDir(session.ID); got != "/repo" {
+		t.Errorf("SessionWorkingDir() = %q, want the agent's", got)
+	}
+	if !agent.SetSessionWorkingDir(session.ID, "/other") {
+		t.Fatal("SetSessionWorkingDir() failed")
+	}
+	if err := agent.Send(context.Background(), "where", SendOptions{SessionID: session.ID}, StreamCallbacks{}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if seen != "/other" {
+		t.Errorf("tools ran in %q, want the session's directory", seen)
+	}
+	if got := agent.SystemPrompt(session.ID); !strings.Contains(got, "/other") {
+		t.Errorf("the system prompt should name the session's directory, got %q", got)
+	}
+
+	agent.SetSessionWorkingDir(session.ID, "")
+	if got := agent.SessionWorkingDir(session.ID); got != "/repo" {
+		t.Errorf("an empty directory should restore the agent's, got %q", got)
+	}
+}
+
 func TestAgentSetTools(t *testing.T) {
 	t.Run("set tools", func(t *testing.T) {
 		agent := New(Config{
@@ -412,3 +501,455 @@ func TestBuildHistory(t *testing.T) {
 		}
 	})
 }
+
+func TestAgentSend_Thinking(t *testing.T) {
+	var calls []fantasy.Call
+	model := &mockModel{
+		streamFunc: func(_ context.Context, call fantasy.Call) (fantasy.StreamResponse, error) {
+			calls = append(calls, call)
+			return func(yield func(fantasy.StreamPart) bool) {
+				parts := []fantasy.StreamPart{
+					{Type: fantasy.StreamPartTypeReasoningStart, ID: "r1"},
+					{Type: fantasy.StreamPartTypeReasoningDelta, ID: "r1", Delta: "Thinking it over."},
+					{Type: fantasy.StreamPartTypeReasoningEnd, ID: "r1"},
+					{Type: fantasy.StreamPartTypeTextDelta, ID: "t1", Delta: "Done."},
+					{Type: fantasy.StreamPartTypeFinish, FinishReason: fantasy.FinishReasonStop},
+				}
+				for _, part := range parts {
+					if !yield(part) {
+						return
+					}
+				}
+			}, nil
+		},
+	}
+	ag := New(Config{Model: model})
+	sess := ag.Sessions().Create("Test")
+
+	temperature := 0.5
+	opts := SendOptions{SessionID: sess.ID, ThinkingBudget: 10000, Temperature: &temperature}
+	if err := ag.Send(context.Background(), "think", opts, StreamCallbacks{}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := ag.Send(context.Background(), "again", SendOptions{SessionID: sess.ID}, StreamCallbacks{}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 model calls, got %d", len(calls))
+	}
+	thinking, ok := calls[0].ProviderOptions[anthropic.Name].(*anthropic.ProviderOptions)
+	if !ok || thinking.Thinking == nil || thinking.Thinking.BudgetTokens != 10000 {
+		t.Errorf("expected an Anthropic thinking budget of 10000, got %+v", calls[0].ProviderOptions)
+	}
+	if calls[0].Temperature != nil {
+		t.Error("temperature should not be sent while thinking")
+	}
+	if calls[0].MaxOutputTokens == nil || *calls[0].MaxOutputTokens <= 10000 {
+		t.Errorf("max tokens should leave room beyond the budget, got %v", calls[0].MaxOutputTokens)
+	}
+	if _, ok := calls[1].ProviderOptions[anthropic.Name]; ok {
+		t.Error("thinking should only be enabled when a budget is set")
+	}
+
+	msgs := ag.Sessions().GetMessages(sess.ID)
+	if got := msgs[1].Reasoning; got != "Thinking it over." {
+		t.Errorf("assistant reasoning = %q, want %q", got, "Thinking it over.")
+	}
+}
+
+func TestAgentSend_CompletionInfo(t *testing.T) {
+	hub := pubsub.NewHub()
+	defer hub.Shutdown()
+	subCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := hub.Agent.Subscribe(subCtx)
+
+	model := &mockModel{
+		streamFunc: func(_ context.Context, _ fantasy.Call) (fantasy.StreamResponse, error) {
+			return func(yield func(fantasy.StreamPart) bool) {
+				if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeTextDelta, ID: "t1", Delta: "The answer is"}) {
+					return
+				}
+				yield(fantasy.StreamPart{
+					Type:         fantasy.StreamPartTypeFinish,
+					FinishReason: fantasy.FinishReasonLength,
+					Usage:        fantasy.Usage{InputTokens: 120, OutputTokens: 8192, CacheReadTokens: 100},
+				})
+			}, nil
+		},
+	}
+	ag := New(Config{Model: model, Hub: hub})
+	sess := ag.Sessions().Create("Test")
+
+	if err := ag.Send(context.Background(), "long answer", SendOptions{SessionID: sess.ID}, StreamCallbacks{}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case event := <-ch:
+			if event.Payload.Type != events.AgentEventComplete {
+				continue
+			}
+			info := event.Payload.Completion
+			if info == nil || !info.Truncated() {
+				t.Fatalf("expected a truncated completion, got %+v", info)
+			}
+			if info.InputTokens != 120 || info.OutputTokens != 8192 || info.CacheReadTokens != 100 {
+				t.Errorf("unexpected usage %+v", info)
+			}
+			return
+		case <-timeout:
+			t.Fatal("expected a complete event")
+		}
+	}
+}
+
+func TestAgentSend_ReplaceFrom(t *testing.T) {
+	var prompts [][]fantasy.Message
+	model := &mockModel{
+		streamFunc: func(_ context.Context, call fantasy.Call) (fantasy.StreamResponse, error) {
+			prompts = append(prompts, call.Prompt)
+			return func(yield func(fantasy.StreamPart) bool) {
+				if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeTextDelta, ID: "t1", Delta: "ok"}) {
+					return
+				}
+				yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeFinish, FinishReason: fantasy.FinishReasonStop})
+			}, nil
+		},
+	}
+	ag := New(Config{Model: model})
+	sess := ag.Sessions().Create("Test")
+
+	for _, prompt := range []string{"first", "second"} {
+		if err := ag.Send(context.Background(), prompt, SendOptions{SessionID: sess.ID}, StreamCallbacks{}); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	second := ag.Sessions().GetMessages(sess.ID)[2]
+
+	opts := SendOptions{SessionID: sess.ID, ReplaceFrom: second.ID}
+	if err := ag.Send(context.Background(), "second, edited", opts, StreamCallbacks{}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	msgs := ag.Sessions().GetMessages(sess.ID)
+	if len(msgs) != 4 || msgs[2].Content != "second, edited" {
+		t.Fatalf("expected the second exchange to be replaced, got %+v", msgs)
+	}
+	if got := len(prompts[2]); got != 4 { // System, first, its reply, edited prompt
+		t.Errorf("model saw %d messages, want 4", got)
+	}
+
+	opts.ReplaceFrom = second.ID
+	if err := ag.Send(context.Background(), "again", opts, StreamCallbacks{}); !errors.Is(err, ErrMessageNotFound) {
+		t.Errorf("Send() error = %v, want ErrMessageNotFound", err)
+	}
+}
+
+func TestAgentSend_Model(t *testing.T) {
+	reply := func(text string, calls *int) *mockModel {
+		return &mockModel{
+			streamFunc: func(context.Context, fantasy.Call) (fantasy.StreamResponse, error) {
+				*calls++
+				return func(yield func(fantasy.StreamPart) bool) {
+					if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeTextDelta, ID: "t1", Delta: text}) {
+						return
+					}
+					yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeFinish, FinishReason: fantasy.FinishReasonStop})
+				}, nil
+			},
+		}
+	}
+	var mainCalls, fastCalls int
+	var specs []string
+	ag := New(Config{
+		Model: reply("main", &mainCalls),
+		ModelResolver: func(_ context.Context, spec string) (fantasy.LanguageModel, error) {
+			specs = append(specs, spec)
+			if spec != "fast" {
+				return nil, fmt.Errorf("model %q not found", spec)
+			}
+			return reply("fast", &fastCalls), nil
+		},
+	})
+	sess := ag.Sessions().Create("Test")
+
+	if err := ag.Send(context.Background(), "hi", SendOptions{SessionID: sess.ID, Model: "fast"}, StreamCallbacks{}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if fastCalls != 1 || mainCalls != 0 {
+		t.Errorf("fast model called %d times, main %d; want only the fast one", fastCalls, mainCalls)
+	}
+	if err := ag.Send(context.Background(), "again", SendOptions{SessionID: sess.ID}, StreamCallbacks{}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if mainCalls != 1 {
+		t.Error("the override should last one prompt")
+	}
+
+	err := ag.Send(context.Background(), "hi", SendOptions{SessionID: sess.ID, Model: "slow"}, StreamCallbacks{})
+	if !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("Send() error = %v, want ErrUnknownModel", err)
+	}
+	if len(ag.Sessions().GetMessages(sess.ID)) != 4 {
+		t.Error("a prompt naming an unknown model should not be saved")
+	}
+	if want := []string{"fast", "slow"}; !reflect.DeepEqual(specs, want) {
+		t.Errorf("resolved %v, want %v", specs, want)
+	}
+
+	noResolver := New(Config{Model: &mockModel{}})
+	err = noResolver.Send(context.Background(), "hi", SendOptions{Model: "fast"}, StreamCallbacks{})
+	if !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("Send() without a resolver error = %v, want ErrUnknownModel", err)
+	}
+}
+
+func TestAgentSend_Timing(t *testing.T) {
+	model := &mockModel{
+		streamFunc: func(context.Context, fantasy.Call) (fantasy.StreamResponse, error) {
+			return func(yield func(fantasy.StreamPart) bool) {
+				time.Sleep(20 * time.Millisecond)
+				if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeTextDelta, ID: "t1", Delta: "Hello"}) {
+					return
+				}
+				time.Sleep(20 * time.Millisecond)
+				yield(fantasy.StreamPart{
+					Type:         fantasy.StreamPartTypeFinish,
+					FinishReason: fantasy.FinishReasonStop,
+					Usage:        fantasy.Usage{OutputTokens: 40},
+				})
+			}, nil
+		},
+	}
+	ag := New(Config{Model: model})
+	sess := ag.Sessions().Create("Test")
+
+	if err := ag.Send(context.Background(), "hi", SendOptions{SessionID: sess.ID}, StreamCallbacks{}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	msgs := ag.Sessions().GetMessages(sess.ID)
+	timing := msgs[len(msgs)-1].Timing
+	if timing == nil {
+		t.Fatal("the reply should be timed")
+	}
+	if timing.FirstToken < 20*time.Millisecond || timing.Generation < 20*time.Millisecond {
+		t.Errorf("Timing = %+v, want the delays before and after the first token", timing)
+	}
+	if timing.OutputTokens != 40 || timing.TokensPerSecond() <= 0 {
+		t.Errorf("Timing = %+v, want 40 output tokens and a speed", timing)
+	}
+}
+
+func TestAgentSend_Cancelled(t *testing.T) {
+	hub := pubsub.NewHub()
+	defer hub.Shutdown()
+	subCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := hub.Agent.Subscribe(subCtx)
+
+	var ag *DefaultAgent
+	var sessionID string
+	model := &mockModel{
+		streamFunc: func(ctx context.Context, _ fantasy.Call) (fantasy.StreamResponse, error) {
+			return func(yield func(fantasy.StreamPart) bool) {
+				if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeTextDelta, ID: "t1", Delta: "Reading the file"}) {
+					return
+				}
+				if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeToolCall, ID: "call1", ToolCallName: "view", ToolCallInput: `{}`}) {
+					return
+				}
+				ag.Cancel(sessionID) // As if the user pressed Esc
+				<-ctx.Done()
+				yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeError, Error: ctx.Err()})
+			}, nil
+		},
+	}
+	ag = New(Config{Model: model, Hub: hub})
+	sess := ag.Sessions().Create("Test")
+	sessionID = sess.ID
+
+	err := ag.Send(context.Background(), "look", SendOptions{SessionID: sess.ID}, StreamCallbacks{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Send() error = %v, want context.Canceled", err)
+	}
+
+	msgs := ag.Sessions().GetMessages(sess.ID)
+	if len(msgs) != 3 {
+		t.Fatalf("expected prompt, partial reply and tool result, got %d messages", len(msgs))
+	}
+	if reply := msgs[1]; !reply.Cancelled || reply.Content != "Reading the file" || len(reply.ToolCalls) != 1 {
+		t.Errorf("partial reply = %+v, want the streamed text marked cancelled", reply)
+	}
+	if results := msgs[2].ToolResults; len(results) != 1 || results[0].ToolCallID != "call1" || !results[0].IsError {
+		t.Errorf("the unanswered tool call should get an error result, got %+v", results)
+	}
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case event := <-ch:
+			switch event.Payload.Type {
+			case events.AgentEventError:
+				t.Fatal("a cancel should not be reported as an error")
+			case events.AgentEventCancelled:
+				return
+			}
+		case <-timeout:
+			t.Fatal("expected a cancelled event")
+		}
+	}
+}
+
+func TestAgentSend_CancelledDuringTool(t *testing.T) {
+	var ag *DefaultAgent
+	var sessionID string
+	finished := make(chan struct{})
+	slow := fantasy.NewAgentTool("slow", "Ignores cancellation",
+		func(context.Context, struct{}, fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			defer close(finished)
+			ag.Cancel(sessionID)
+			time.Sleep(50 * time.Millisecond)
+			return fantasy.NewTextResponse("done anyway"), nil
+		})
+	model := &mockModel{
+		streamFunc: func(ctx context.Context, _ fantasy.Call) (fantasy.StreamResponse, error) {
+			return func(yield func(fantasy.StreamPart) bool) {
+				if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeToolCall, ID: "call1", ToolCallName: "slow", ToolCallInput: `{}`}) {
+					return
+				}
+				// The stream ends on the cancel while the tool still runs.
+				<-ctx.Done()
+				yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeError, Error: ctx.Err()})
+			}, nil
+		},
+	}
+	ag = New(Config{Model: model, Tools: []fantasy.AgentTool{slow}})
+	sess := ag.Sessions().Create("Test")
+	sessionID = sess.ID
+
+	err := ag.Send(context.Background(), "go", SendOptions{SessionID: sess.ID}, StreamCallbacks{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Send() error = %v, want context.Canceled", err)
+	}
+	<-finished
+	time.Sleep(20 * time.Millisecond) // Let a late result reach the callbacks
+
+	var results int
+	for _, msg := range ag.Sessions().GetMessages(sess.ID) {
+		for _, tr := range msg.ToolResults {
+			if tr.ToolCallID == "call1" {
+				results++
+			}
+		}
+	}
+	if results != 1 {
+		t.Errorf("call1 has %d results, want exactly one", results)
+	}
+}
+
+func TestAgentInterrupt(t *testing.T) {
+	streaming := make(chan struct{})
+	model := &mockModel{
+		streamFunc: func(ctx context.Context, _ fantasy.Call) (fantasy.StreamResponse, error) {
+			return func(yield func(fantasy.StreamPart) bool) {
+				if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeTextDelta, ID: "t1", Delta: "Halfway there"}) {
+					return
+				}
+				close(streaming)
+				<-ctx.Done()
+				yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeError, Error: ctx.Err()})
+			}, nil
+		},
+	}
+	runs := NewRunLog(t.TempDir())
+	ag := New(Config{Model: model, Runs: runs})
+	sess := ag.Sessions().Create("Test")
+
+	sent := make(chan error, 1)
+	go func() {
+		sent <- ag.Send(context.Background(), "build it", SendOptions{SessionID: sess.ID}, StreamCallbacks{})
+	}()
+	<-streaming
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := ag.Interrupt(ctx); err != nil {
+		t.Fatalf("Interrupt() error = %v", err)
+	}
+	if err := <-sent; !errors.Is(err, context.Canceled) {
+		t.Errorf("Send() error = %v, want context.Canceled", err)
+	}
+
+	msgs := ag.Sessions().GetMessages(sess.ID)
+	if len(msgs) < 2 {
+		t.Fatalf("expected the prompt and partial reply, got %d messages", len(msgs))
+	}
+	if reply := msgs[1]; !reply.Interrupted || !reply.Cancelled || reply.Content != "Halfway there" {
+		t.Errorf("partial reply = %+v, want the streamed text marked interrupted", reply)
+	}
+
+	run, ok := runs.Get(sess.ID)
+	if !ok || run.Prompt != "build it" || run.MessageID != msgs[0].ID || run.Crashed() {
+		t.Errorf("run log = %+v, %v; want the interrupted run", run, ok)
+	}
+
+	// No run starts once cdd is exiting.
+	if err := ag.Send(context.Background(), "more", SendOptions{SessionID: sess.ID}, StreamCallbacks{}); !errors.Is(err, ErrInterrupted) {
+		t.Errorf("Send() after Interrupt error = %v, want ErrInterrupted", err)
+	}
+}
+
+func TestAgentSend_EndsRun(t *testing.T) {
+	runs := NewRunLog(t.TempDir())
+	ag := New(Config{Model: &mockModel{}, Runs: runs})
+	sess := ag.Sessions().Create("Test")
+
+	if err := ag.Send(context.Background(), "hi", SendOptions{SessionID: sess.ID}, StreamCallbacks{}); err != nil {
+		t.Fatal(err)
+	}
+	if run, ok := runs.Get(sess.ID); ok {
+		t.Errorf("a finished run is still recorded: %+v", run)
+	}
+	if entries, _ := os.ReadDir(runs.dir); len(entries) != 0 {
+		t.Errorf("run log has %d files after the run, want none", len(entries))
+	}
+}
+
+func TestCancelledToolResults(t *testing.T) {
+	calls := []ToolCall{{ID: "a", Name: "view"}, {ID: "b", Name: "bash"}}
+	answered := []Message{{Role: RoleTool, ToolResults: []ToolResult{{ToolCallID: "a"}}}}
+
+	missing := cancelledToolResults(calls, answered, false)
+	if len(missing) != 1 || missing[0].ToolResults[0].ToolCallID != "b" || missing[0].ToolResults[0].Content != "Cancelled by the user" {
+		t.Errorf("cancelledToolResults() = %+v, want a cancelled result for b", missing)
+	}
+	missing = cancelledToolResults(calls, answered, true)
+	if len(missing) != 1 || !strings.Contains(missing[0].ToolResults[0].Content, "cdd exited") {
+		t.Errorf("cancelledToolResults() = %+v, want an interrupted result for b", missing)
+	}
+}
+
+func TestAgentSend_SessionInUse(t *testing.T) {
+	dir := t.TempDir()
+	ag := New(Config{Model: &mockModel{}, Locks: session.NewLocker(dir, "cdd")})
+	sess := ag.Sessions().Create("Test")
+
+	// Another cdd holds the session: the test's parent process.
+	lock := fmt.Sprintf(`{"pid":%d,"instance":"other"}`, os.Getppid())
+	if err := os.WriteFile(filepath.Join(dir, sess.ID+".lock"), []byte(lock), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := ag.Send(context.Background(), "hi", SendOptions{SessionID: sess.ID}, StreamCallbacks{})
+	var locked *session.LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("Send() error = %v, want the session in use", err)
+	}
+	if msgs := ag.Sessions().GetMessages(sess.ID); len(msgs) != 0 {
+		t.Errorf("nothing should be written to a session in use, got %d messages", len(msgs))
+	}
+}
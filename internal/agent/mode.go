@@ -0,0 +1,81 @@
+package agent
+
+import "charm.land/fantasy"
+
+// Mode is a persona the agent can switch to, such as a read-only planner.
+type Mode struct {
+	Name        string
+	Description string
+	Prompt      string              // Instructions added to the system prompt
+	Tools       []fantasy.AgentTool // Tools the agent may use in this mode
+}
+
+// Modes returns the modes the agent can switch to.
+func (a *DefaultAgent) Modes() []Mode {
+	return a.modes
+}
+
+// Mode returns the name of the current mode, or "" when the agent has none.
+func (a *DefaultAgent) Mode() string {
+	if mode := a.currentMode(); mode != nil {
+		return mode.Name
+	}
+	return ""
+}
+
+// SetMode switches to the named mode, which applies from the next prompt. It
+// reports whether the mode exists.
+func (a *DefaultAgent) SetMode(name string) bool {
+	for i := range a.modes {
+		if a.modes[i].Name == name {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			a.mode = &a.modes[i]
+			return true
+		}
+	}
+	return false
+}
+
+// currentMode returns the current mode, or nil when the agent has none.
+func (a *DefaultAgent) currentMode() *Mode {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.mode
+}
+
+// sendMode returns the mode a prompt runs in: the named one, or the current
+// one when name is empty. It returns nil when the agent has no mode, and
+// ErrUnknownMode when the named one does not exist.
+func (a *DefaultAgent) sendMode(name string) (*Mode, error) {
+	if name == "" {
+		return a.currentMode(), nil
+	}
+	for i := range a.modes {
+		if a.modes[i].Name == name {
+			return &a.modes[i], nil
+		}
+	}
+	return nil, ErrUnknownMode
+}
+
+// modeTools returns the tools of mode, or all the agent's tools when mode is
+// nil.
+func (a *DefaultAgent) modeTools(mode *Mode) []fantasy.AgentTool {
+	if mode != nil {
+		return mode.Tools
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.tools
+}
+
+// modeSystemPrompt returns the system prompt of a session with the
+// instructions of mode added.
+func (a *DefaultAgent) modeSystemPrompt(sessionID string, mode *Mode) string {
+	prompt := a.basePrompt(sessionID)
+	if mode != nil && mode.Prompt != "" {
+		prompt += "\n\n" + mode.Prompt
+	}
+	return prompt
+}
@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"charm.land/fantasy"
+)
+
+func TestAgentModes(t *testing.T) {
+	newTool := func(name string) fantasy.AgentTool {
+		return fantasy.NewAgentTool(name, "Test tool",
+			func(context.Context, struct{}, fantasy.ToolCall) (fantasy.ToolResponse, error) {
+				return fantasy.NewTextResponse("ok"), nil
+			})
+	}
+	read, write := newTool("read"), newTool("write")
+
+	var calls []fantasy.Call
+	model := &mockModel{
+		streamFunc: func(_ context.Context, call fantasy.Call) (fantasy.StreamResponse, error) {
+			calls = append(calls, call)
+			return func(yield func(fantasy.StreamPart) bool) {
+				yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeFinish, FinishReason: fantasy.FinishReasonStop})
+			}, nil
+		},
+	}
+	ag := New(Config{
+		Model:        model,
+		SystemPrompt: "Base prompt",
+		Tools:        []fantasy.AgentTool{read, write},
+		Modes: []Mode{
+			{Name: "code", Tools: []fantasy.AgentTool{read, write}},
+			{Name: "plan", Prompt: "Only plan.", Tools: []fantasy.AgentTool{read}},
+		},
+		Mode: "plan",
+	})
+	sess := ag.Sessions().Create("Test")
+
+	if ag.Mode() != "plan" {
+		t.Fatalf("Mode() = %q, want the configured start mode", ag.Mode())
+	}
+	if got := ag.SystemPrompt(sess.ID); got != "Base prompt\n\nOnly plan." {
+		t.Errorf("SystemPrompt() = %q, want the mode's instructions after the base prompt", got)
+	}
+
+	send := func() fantasy.Call {
+		t.Helper()
+		if err := ag.Send(context.Background(), "go", SendOptions{SessionID: sess.ID}, StreamCallbacks{}); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		return calls[len(calls)-1]
+	}
+	if call := send(); len(call.Tools) != 1 || call.Tools[0].GetName() != "read" {
+		t.Errorf("plan mode sent tools %v, want only read", call.Tools)
+	}
+	if system := systemText(calls[0]); !strings.Contains(system, "Only plan.") {
+		t.Errorf("system prompt %q should include the mode's instructions", system)
+	}
+
+	// A prompt can run in another mode without switching to it.
+	err := ag.Send(context.Background(), "go", SendOptions{SessionID: sess.ID, Mode: "code"}, StreamCallbacks{})
+	if err != nil || len(calls[len(calls)-1].Tools) != 2 || ag.Mode() != "plan" {
+		t.Errorf("Send(Mode: code) = %v, sent %d tools, mode %q; want code's tools for one prompt", err, len(calls[len(calls)-1].Tools), ag.Mode())
+	}
+	if err := ag.Send(context.Background(), "go", SendOptions{SessionID: sess.ID, Mode: "missing"}, StreamCallbacks{}); !errors.Is(err, ErrUnknownMode) {
+		t.Errorf("Send(Mode: missing) error = %v, want ErrUnknownMode", err)
+	}
+
+	if ag.SetMode("missing") {
+		t.Error("SetMode() should fail for an unknown mode")
+	}
+	if !ag.SetMode("code") || ag.Mode() != "code" {
+		t.Fatalf("Mode() = %q after SetMode(code)", ag.Mode())
+	}
+	if call := send(); len(call.Tools) != 2 {
+		t.Errorf("code mode sent %d tools, want 2", len(call.Tools))
+	}
+	if strings.Contains(ag.SystemPrompt(sess.ID), "Only plan.") {
+		t.Error("switching modes should drop the previous mode's instructions")
+	}
+}
+
+// systemText returns the text of the system message of a model call.
+func systemText(call fantasy.Call) string {
+	var b strings.Builder
+	for _, msg := range call.Prompt {
+		if msg.Role != fantasy.MessageRoleSystem {
+			continue
+		}
+		for _, part := range msg.Content {
+			if text, ok := part.(fantasy.TextPart); ok {
+				b.WriteString(text.Text)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/fantasy"
+)
+
+// statusOverloaded is the non-standard status Anthropic uses when the API is overloaded.
+const statusOverloaded = 529
+
+// Default retry settings.
+const (
+	DefaultMaxAttempts = 4
+	defaultBaseDelay   = time.Second
+	defaultMaxDelay    = 30 * time.Second
+)
+
+// RetryPolicy controls how transient provider errors are retried.
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first (1 disables retries)
+	BaseDelay   time.Duration // Delay before the first retry, doubled for each attempt
+	MaxDelay    time.Duration // Upper bound for a single delay
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: DefaultMaxAttempts,
+		BaseDelay:   defaultBaseDelay,
+		MaxDelay:    defaultMaxDelay,
+	}
+}
+
+// withDefaults fills unset fields from the default policy.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	def := DefaultRetryPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = def.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = def.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = def.MaxDelay
+	}
+	return p
+}
+
+// Delay returns how long to wait before the given retry (1 for the first retry).
+// A Retry-After header on the error takes precedence over exponential backoff.
+// Backoff uses "equal jitter": half the delay is fixed and half is random.
+func (p RetryPolicy) Delay(retry int, err error) time.Duration {
+	if d, ok := retryAfter(err); ok {
+		return min(d, p.MaxDelay)
+	}
+
+	d := p.BaseDelay << min(retry-1, 16) //nolint:mnd // Cap the shift to avoid overflow
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	half := d / 2                //nolint:mnd // Equal jitter
+	return half + rand.N(half+1) //nolint:gosec // Jitter does not need a secure source
+}
+
+// IsTransientError reports whether err is a provider error worth retrying:
+// rate limits, server errors, and overloaded responses.
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var providerErr *fantasy.ProviderError
+	if errors.As(err, &providerErr) {
+		switch providerErr.StatusCode {
+		case http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+			statusOverloaded:
+			return true
+		}
+	}
+
+	// Some providers report overload in the stream body rather than the status.
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "overloaded")
+}
+
+// retryAfter extracts a server-requested delay from the error's response headers.
+func retryAfter(err error) (time.Duration, bool) {
+	var providerErr *fantasy.ProviderError
+	if !errors.As(err, &providerErr) || providerErr.ResponseHeaders == nil {
+		return 0, false
+	}
+
+	for key, value := range providerErr.ResponseHeaders {
+		if !strings.EqualFold(key, "retry-after") {
+			continue
+		}
+		if secs, parseErr := strconv.ParseFloat(strings.TrimSpace(value), 64); parseErr == nil && secs >= 0 {
+			return time.Duration(secs * float64(time.Second)), true
+		}
+		if t, parseErr := http.ParseTime(value); parseErr == nil {
+			return max(time.Until(t), 0), true
+		}
+	}
+	return 0, false
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,191 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"rate limited", &fantasy.ProviderError{StatusCode: http.StatusTooManyRequests}, true},
+		{"server error", &fantasy.ProviderError{StatusCode: http.StatusInternalServerError}, true},
+		{"bad gateway", &fantasy.ProviderError{StatusCode: http.StatusBadGateway}, true},
+		{"unavailable", &fantasy.ProviderError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"anthropic overloaded", &fantasy.ProviderError{StatusCode: statusOverloaded}, true},
+		{"overloaded message", errors.New("overloaded_error: Overloaded"), true},
+		{"wrapped", fmt.Errorf("stream: %w", &fantasy.ProviderError{StatusCode: http.StatusBadGateway}), true},
+		{"bad request", &fantasy.ProviderError{StatusCode: http.StatusBadRequest}, false},
+		{"unauthorized", &fantasy.ProviderError{StatusCode: http.StatusUnauthorized}, false},
+		{"canceled", context.Canceled, false},
+		{"plain error", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransientError(tt.err); got != tt.want {
+				t.Errorf("IsTransientError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	err := errors.New("overloaded")
+
+	t.Run("backs off exponentially with jitter", func(t *testing.T) {
+		for retry, ceiling := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second} {
+			for range 20 {
+				d := p.Delay(retry, err)
+				if d < ceiling/2 || d > ceiling {
+					t.Fatalf("Delay(%d) = %s, want within [%s, %s]", retry, d, ceiling/2, ceiling)
+				}
+			}
+		}
+	})
+
+	t.Run("caps at max delay", func(t *testing.T) {
+		if d := p.Delay(30, err); d > p.MaxDelay {
+			t.Errorf("Delay() = %s, want <= %s", d, p.MaxDelay)
+		}
+	})
+
+	t.Run("honours retry-after", func(t *testing.T) {
+		providerErr := &fantasy.ProviderError{
+			StatusCode:      http.StatusTooManyRequests,
+			ResponseHeaders: map[string]string{"Retry-After": "2"},
+		}
+		if d := p.Delay(1, providerErr); d != 2*time.Second {
+			t.Errorf("Delay() = %s, want 2s", d)
+		}
+
+		providerErr.ResponseHeaders["Retry-After"] = "120"
+		if d := p.Delay(1, providerErr); d != p.MaxDelay {
+			t.Errorf("Delay() = %s, want capped at %s", d, p.MaxDelay)
+		}
+	})
+}
+
+// failingModel fails the first failures stream calls with err, then streams "ok".
+func failingModel(failures int, err error, calls *int) *mockModel {
+	return &mockModel{
+		streamFunc: func(_ context.Context, _ fantasy.Call) (fantasy.StreamResponse, error) {
+			*calls++
+			if *calls <= failures {
+				return nil, err
+			}
+			return func(yield func(fantasy.StreamPart) bool) {
+				if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeTextDelta, ID: "t1", Delta: "ok"}) {
+					return
+				}
+				yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeFinish, FinishReason: fantasy.FinishReasonStop})
+			}, nil
+		},
+	}
+}
+
+func TestAgentSend_Retries(t *testing.T) {
+	fastPolicy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	unavailable := &fantasy.ProviderError{StatusCode: http.StatusServiceUnavailable, Message: "unavailable"}
+
+	t.Run("retries transient errors and publishes events", func(t *testing.T) {
+		hub := pubsub.NewHub()
+		defer hub.Shutdown()
+		subCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch := hub.Agent.Subscribe(subCtx)
+
+		var calls int
+		ag := New(Config{Model: failingModel(2, unavailable, &calls), Hub: hub, Retry: fastPolicy})
+		session := ag.sessions.Create("retry")
+
+		if err := ag.Send(context.Background(), "hi", SendOptions{SessionID: session.ID}, StreamCallbacks{}); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		if calls != 3 {
+			t.Errorf("model called %d times, want 3", calls)
+		}
+
+		var attempts []int
+		timeout := time.After(time.Second)
+		for len(attempts) < 2 {
+			select {
+			case event := <-ch:
+				if event.Payload.Type == events.AgentEventRetrying {
+					attempts = append(attempts, event.Payload.Retry.Attempt)
+				}
+			case <-timeout:
+				t.Fatalf("expected 2 retrying events, got %v", attempts)
+			}
+		}
+		if attempts[0] != 2 || attempts[1] != 3 {
+			t.Errorf("retry attempts = %v, want [2 3]", attempts)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		var calls int
+		ag := New(Config{Model: failingModel(10, unavailable, &calls), Retry: fastPolicy})
+		session := ag.sessions.Create("retry")
+
+		err := ag.Send(context.Background(), "hi", SendOptions{SessionID: session.ID}, StreamCallbacks{})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if calls != 3 {
+			t.Errorf("model called %d times, want 3", calls)
+		}
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		var calls int
+		badRequest := &fantasy.ProviderError{StatusCode: http.StatusBadRequest, Message: "bad"}
+		ag := New(Config{Model: failingModel(10, badRequest, &calls), Retry: fastPolicy})
+		session := ag.sessions.Create("retry")
+
+		if err := ag.Send(context.Background(), "hi", SendOptions{SessionID: session.ID}, StreamCallbacks{}); err == nil {
+			t.Fatal("expected error")
+		}
+		if calls != 1 {
+			t.Errorf("model called %d times, want 1", calls)
+		}
+	})
+
+	t.Run("does not retry after output was streamed", func(t *testing.T) {
+		var calls int
+		model := &mockModel{
+			streamFunc: func(_ context.Context, _ fantasy.Call) (fantasy.StreamResponse, error) {
+				calls++
+				return func(yield func(fantasy.StreamPart) bool) {
+					if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeTextDelta, ID: "t1", Delta: "partial"}) {
+						return
+					}
+					yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeError, Error: unavailable})
+				}, nil
+			},
+		}
+		ag := New(Config{Model: model, Retry: fastPolicy})
+		session := ag.sessions.Create("retry")
+
+		if err := ag.Send(context.Background(), "hi", SendOptions{SessionID: session.ID}, StreamCallbacks{}); err == nil {
+			t.Fatal("expected error")
+		}
+		if calls != 1 {
+			t.Errorf("model called %d times, want 1", calls)
+		}
+	})
+}
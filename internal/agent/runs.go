@@ -0,0 +1,162 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/proc"
+)
+
+// ErrInterrupted is returned by Send when a run is refused because the agent
+// is shutting down.
+var ErrInterrupted = NewError("cdd is exiting")
+
+// InterruptedRun is a reply that was still in progress when cdd last exited.
+type InterruptedRun struct { //nolint:govet // fieldalignment: preserving logical field order
+	SessionID string    `json:"session_id"`
+	MessageID string    `json:"message_id"` // User message that started the run
+	Prompt    string    `json:"prompt"`
+	StartedAt time.Time `json:"started_at"`
+	PID       int       `json:"pid"`      // Process the run was in
+	Instance  string    `json:"instance"` // Tells this process from an earlier one with the same PID
+
+	// InterruptedAt is when cdd stopped the run and saved the reply so far.
+	// It is zero when cdd crashed or was killed, and the reply was lost.
+	InterruptedAt time.Time `json:"interrupted_at,omitzero"`
+}
+
+// Crashed reports whether cdd ended without saving the partial reply.
+func (r InterruptedRun) Crashed() bool {
+	return r.InterruptedAt.IsZero()
+}
+
+// RunLog keeps a file for each run in progress, removed once the run's reply
+// is saved. A file left behind after cdd exits is a run that never finished,
+// which the next start offers to resume.
+type RunLog struct {
+	dir string
+}
+
+// NewRunLog creates a run log stored under dir.
+func NewRunLog(dir string) *RunLog {
+	return &RunLog{dir: dir}
+}
+
+// Get returns the interrupted run of a session, if it has one.
+func (l *RunLog) Get(sessionID string) (InterruptedRun, bool) {
+	run, err := l.read(l.path(sessionID))
+	if err != nil || run.running() {
+		return InterruptedRun{}, false
+	}
+	return run, true
+}
+
+// List returns the interrupted runs, most recent first.
+func (l *RunLog) List() []InterruptedRun {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil
+	}
+	var runs []InterruptedRun
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		run, err := l.read(filepath.Join(l.dir, entry.Name()))
+		if err != nil || run.running() {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	slices.SortFunc(runs, func(a, b InterruptedRun) int {
+		return b.StartedAt.Compare(a.StartedAt)
+	})
+	return runs
+}
+
+// Dismiss forgets a session's interrupted run, once it was resumed or the
+// user chose to leave it.
+func (l *RunLog) Dismiss(sessionID string) error {
+	if err := os.Remove(l.path(sessionID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing interrupted run: %w", err)
+	}
+	return nil
+}
+
+// begin records that a run started.
+func (l *RunLog) begin(run InterruptedRun) {
+	run.PID, run.Instance = proc.PID(), proc.Instance
+	if err := l.write(run); err != nil {
+		debug.Log("Recording run of session %s: %v", run.SessionID, err)
+	}
+}
+
+// interrupt records that a run was stopped with its reply so far saved.
+func (l *RunLog) interrupt(sessionID string) {
+	run, err := l.read(l.path(sessionID))
+	if err != nil {
+		return
+	}
+	run.InterruptedAt = time.Now()
+	if err := l.write(run); err != nil {
+		debug.Log("Recording interrupted run of session %s: %v", sessionID, err)
+	}
+}
+
+// end forgets a run whose reply was saved.
+func (l *RunLog) end(sessionID string) {
+	if err := l.Dismiss(sessionID); err != nil {
+		debug.Log("Ending run of session %s: %v", sessionID, err)
+	}
+}
+
+func (l *RunLog) path(sessionID string) string {
+	return filepath.Join(l.dir, filepath.Base(sessionID)+".json")
+}
+
+func (l *RunLog) read(path string) (InterruptedRun, error) {
+	var run InterruptedRun
+	data, err := os.ReadFile(path) //nolint:gosec // Path is built from the data directory
+	if err != nil {
+		return run, fmt.Errorf("reading run: %w", err)
+	}
+	if err := json.Unmarshal(data, &run); err != nil {
+		return run, fmt.Errorf("parsing run %s: %w", path, err)
+	}
+	return run, nil
+}
+
+// write saves a run through a temporary file, so a crash mid-write leaves
+// the previous record rather than half of one.
+func (l *RunLog) write(run InterruptedRun) error {
+	if err := os.MkdirAll(l.dir, 0o700); err != nil {
+		return fmt.Errorf("creating run log directory: %w", err)
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("encoding run: %w", err)
+	}
+	path := l.path(run.SessionID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("writing run: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("saving run: %w", err)
+	}
+	return nil
+}
+
+// running reports whether the run may still be going on in another cdd,
+// such as a background job, or in this one. Runs that were stopped are
+// never running.
+func (r InterruptedRun) running() bool {
+	return r.InterruptedAt.IsZero() && proc.Running(r.PID, r.Instance)
+}
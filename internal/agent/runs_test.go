@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRunLog(t *testing.T) {
+	log := NewRunLog(t.TempDir())
+	started := time.Now()
+
+	log.begin(InterruptedRun{SessionID: "s1", MessageID: "m1", Prompt: "fix it", StartedAt: started})
+	if _, ok := log.Get("s1"); ok {
+		t.Fatal("a run still going on in this process is not interrupted")
+	}
+
+	log.interrupt("s1")
+	run, ok := log.Get("s1")
+	if !ok || run.Prompt != "fix it" || run.MessageID != "m1" || run.Crashed() {
+		t.Fatalf("Get() = %+v, %v; want the interrupted run", run, ok)
+	}
+
+	log.begin(InterruptedRun{SessionID: "s2", Prompt: "other", StartedAt: started.Add(time.Second)})
+	log.end("s2")
+	if runs := log.List(); len(runs) != 1 || runs[0].SessionID != "s1" {
+		t.Errorf("List() = %+v, want only s1", runs)
+	}
+
+	if err := log.Dismiss("s1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := log.Dismiss("s1"); err != nil {
+		t.Errorf("dismissing twice: %v", err)
+	}
+	if runs := log.List(); len(runs) != 0 {
+		t.Errorf("List() = %+v after dismissing, want none", runs)
+	}
+}
+
+func TestRunLog_Crashed(t *testing.T) {
+	dir := t.TempDir()
+	log := NewRunLog(dir)
+
+	// Left behind by an earlier cdd that died mid-run.
+	earlier := InterruptedRun{SessionID: "s1", Prompt: "fix it", PID: os.Getpid(), Instance: "earlier"}
+	if err := log.write(earlier); err != nil {
+		t.Fatal(err)
+	}
+	run, ok := log.Get("s1")
+	if !ok || !run.Crashed() {
+		t.Errorf("Get() = %+v, %v; want a crashed run", run, ok)
+	}
+
+	// Unreadable records are skipped.
+	if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if runs := log.List(); len(runs) != 1 {
+		t.Errorf("List() = %+v, want the crashed run only", runs)
+	}
+}
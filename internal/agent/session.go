@@ -19,6 +19,14 @@ type Session struct { //nolint:govet // fieldalignment: preserving logical field
 	Messages  []Message
 	CreatedAt time.Time
 	UpdatedAt time.Time
+
+	// SystemPrompt replaces the agent's system prompt for this session; empty
+	// uses the agent's.
+	SystemPrompt string
+
+	// WorkingDir is the directory the session's tools run in; empty uses the
+	// agent's.
+	WorkingDir string
 }
 
 // SessionStore manages conversation sessions in memory.
@@ -124,6 +132,11 @@ func (s *SessionStore) Delete(id string) bool {
 // AddMessage adds a message to a session.
 // If the session exceeds MaxSessionMessages, older messages are trimmed.
 func (s *SessionStore) AddMessage(sessionID string, msg Message) bool {
+	return s.AddMessages(sessionID, []Message{msg})
+}
+
+// AddMessages adds messages to a session in order.
+func (s *SessionStore) AddMessages(sessionID string, msgs []Message) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -132,15 +145,16 @@ func (s *SessionStore) AddMessage(sessionID string, msg Message) bool {
 		return false
 	}
 
-	if msg.ID == "" {
-		msg.ID = uuid.New().String()
-	}
-	if msg.CreatedAt.IsZero() {
-		msg.CreatedAt = time.Now()
+	for _, msg := range msgs {
+		if msg.ID == "" {
+			msg.ID = uuid.New().String()
+		}
+		if msg.CreatedAt.IsZero() {
+			msg.CreatedAt = time.Now()
+		}
+		session.Messages = append(session.Messages, msg)
 	}
 
-	session.Messages = append(session.Messages, msg)
-
 	// Trim old messages if we exceed the limit
 	if len(session.Messages) > MaxSessionMessages {
 		// Keep the most recent messages
@@ -185,6 +199,26 @@ func (s *SessionStore) ClearMessages(sessionID string) bool {
 	return true
 }
 
+// TruncateMessages removes a message and every message after it.
+func (s *SessionStore) TruncateMessages(sessionID, messageID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return false
+	}
+
+	for i := range session.Messages {
+		if session.Messages[i].ID == messageID {
+			session.Messages = session.Messages[:i]
+			session.UpdatedAt = time.Now()
+			return true
+		}
+	}
+	return false
+}
+
 // UpdateTitle updates a session's title.
 func (s *SessionStore) UpdateTitle(sessionID, title string) bool {
 	s.mu.Lock()
@@ -200,3 +234,35 @@ func (s *SessionStore) UpdateTitle(sessionID, title string) bool {
 
 	return true
 }
+
+// SetSystemPrompt sets a session's system prompt.
+func (s *SessionStore) SetSystemPrompt(sessionID, prompt string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return false
+	}
+
+	session.SystemPrompt = prompt
+	session.UpdatedAt = time.Now()
+
+	return true
+}
+
+// SetWorkingDir sets a session's working directory.
+func (s *SessionStore) SetWorkingDir(sessionID, dir string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return false
+	}
+
+	session.WorkingDir = dir
+	session.UpdatedAt = time.Now()
+
+	return true
+}
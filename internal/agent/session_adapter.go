@@ -17,6 +17,7 @@ type PersistentSessionStore struct {
 	sessionSvc *session.Service
 	messageSvc *message.Service
 	cache      map[string]*Session // Local cache for current session messages
+	writer     *messageWriter      // Set by StartWriter; nil writes synchronously
 	mu         sync.RWMutex
 }
 
@@ -63,7 +64,8 @@ func (s *PersistentSessionStore) Get(id string) (*Session, bool) {
 	}
 	s.mu.RUnlock()
 
-	// Load from database
+	// Load from database, including anything still queued
+	s.Flush()
 	ctx := context.Background()
 	dbSess, err := s.sessionSvc.Get(ctx, id)
 	if err != nil {
@@ -77,11 +79,13 @@ func (s *PersistentSessionStore) Get(id string) (*Session, bool) {
 	}
 
 	agentSession := &Session{
-		ID:        dbSess.ID,
-		Title:     dbSess.Title,
-		Messages:  convertFromMessagePkg(msgs),
-		CreatedAt: dbSess.CreatedAt,
-		UpdatedAt: dbSess.UpdatedAt,
+		ID:           dbSess.ID,
+		Title:        dbSess.Title,
+		Messages:     convertFromMessagePkg(msgs),
+		CreatedAt:    dbSess.CreatedAt,
+		UpdatedAt:    dbSess.UpdatedAt,
+		SystemPrompt: dbSess.SystemPrompt,
+		WorkingDir:   dbSess.WorkingDir,
 	}
 
 	s.mu.Lock()
@@ -159,6 +163,7 @@ func (s *PersistentSessionStore) List() []*Session {
 
 // Delete removes a session.
 func (s *PersistentSessionStore) Delete(id string) bool {
+	s.Flush()
 	ctx := context.Background()
 	if err := s.sessionSvc.Delete(ctx, id); err != nil {
 		return false
@@ -173,33 +178,49 @@ func (s *PersistentSessionStore) Delete(id string) bool {
 
 // AddMessage adds a message to a session.
 func (s *PersistentSessionStore) AddMessage(sessionID string, msg Message) bool {
-	ctx := context.Background()
+	return s.AddMessages(sessionID, []Message{msg})
+}
 
-	// Convert to message.Message
-	dbMsg := &message.Message{
-		ID:        msg.ID,
-		SessionID: sessionID,
-		Role:      message.Role(msg.Role),
-		Parts:     convertToMessageParts(msg),
-		CreatedAt: msg.CreatedAt,
+// AddMessages adds messages to a session in order, in a single write.
+func (s *PersistentSessionStore) AddMessages(sessionID string, msgs []Message) bool {
+	if len(msgs) == 0 {
+		return true
 	}
 
-	if dbMsg.ID == "" {
-		dbMsg.ID = uuid.New().String()
-		msg.ID = dbMsg.ID
+	// Convert to message.Message
+	dbMsgs := make([]*message.Message, len(msgs))
+	for i := range msgs {
+		if msgs[i].ID == "" {
+			msgs[i].ID = uuid.New().String()
+		}
+		if msgs[i].CreatedAt.IsZero() {
+			// Stamped here rather than by the store, which may write later.
+			msgs[i].CreatedAt = time.Now()
+		}
+		dbMsgs[i] = &message.Message{
+			ID:        msgs[i].ID,
+			SessionID: sessionID,
+			Role:      message.Role(msgs[i].Role),
+			Parts:     convertToMessageParts(msgs[i]),
+			IsSummary: msgs[i].IsSummary,
+			CreatedAt: msgs[i].CreatedAt,
+		}
 	}
 
-	if err := s.messageSvc.Add(ctx, dbMsg); err != nil {
-		return false
+	batch := pendingBatch{SessionID: sessionID, Messages: dbMsgs}
+	s.mu.RLock()
+	w := s.writer
+	s.mu.RUnlock()
+	if w == nil || w.enqueue(batch) != nil {
+		if err := s.persistBatch(batch); err != nil {
+			return false
+		}
 	}
 
-	// Increment message count in session (non-critical operation)
-	_ = s.sessionSvc.IncrementMessageCount(ctx, sessionID) //nolint:errcheck // Non-critical count update
-
 	// Update cache
 	s.mu.Lock()
 	if sess, ok := s.cache[sessionID]; ok {
-		sess.Messages = append(sess.Messages, msg)
+		sess.Messages = append(sess.Messages, msgs...)
 		sess.UpdatedAt = time.Now()
 
 		// Apply MaxSessionMessages limit to cache
@@ -225,7 +246,8 @@ func (s *PersistentSessionStore) GetMessages(sessionID string) []Message {
 	}
 	s.mu.RUnlock()
 
-	// Load from database
+	// Load from database, including anything still queued
+	s.Flush()
 	ctx := context.Background()
 	dbMsgs, err := s.messageSvc.GetContext(ctx, sessionID)
 	if err != nil {
@@ -237,6 +259,7 @@ func (s *PersistentSessionStore) GetMessages(sessionID string) []Message {
 
 // ClearMessages clears all messages from a session.
 func (s *PersistentSessionStore) ClearMessages(sessionID string) bool {
+	s.Flush()
 	ctx := context.Background()
 	if err := s.messageSvc.Clear(ctx, sessionID); err != nil {
 		return false
@@ -252,6 +275,33 @@ func (s *PersistentSessionStore) ClearMessages(sessionID string) bool {
 	return true
 }
 
+// TruncateMessages removes a message and every message after it.
+func (s *PersistentSessionStore) TruncateMessages(sessionID, messageID string) bool {
+	s.Flush()
+	ctx := context.Background()
+	removed, err := s.messageSvc.DeleteFrom(ctx, sessionID, messageID)
+	for range removed {
+		_ = s.sessionSvc.DecrementMessageCount(ctx, sessionID) //nolint:errcheck // Non-critical count update
+	}
+	if err != nil {
+		return false
+	}
+
+	s.mu.Lock()
+	if sess, ok := s.cache[sessionID]; ok {
+		for i := range sess.Messages {
+			if sess.Messages[i].ID == messageID {
+				sess.Messages = sess.Messages[:i]
+				break
+			}
+		}
+		sess.UpdatedAt = time.Now()
+	}
+	s.mu.Unlock()
+
+	return true
+}
+
 // UpdateTitle updates a session's title.
 func (s *PersistentSessionStore) UpdateTitle(sessionID, title string) bool {
 	ctx := context.Background()
@@ -269,6 +319,40 @@ func (s *PersistentSessionStore) UpdateTitle(sessionID, title string) bool {
 	return true
 }
 
+// SetSystemPrompt sets a session's system prompt.
+func (s *PersistentSessionStore) SetSystemPrompt(sessionID, prompt string) bool {
+	ctx := context.Background()
+	if err := s.sessionSvc.SetSystemPrompt(ctx, sessionID, prompt); err != nil {
+		return false
+	}
+
+	s.mu.Lock()
+	if sess, ok := s.cache[sessionID]; ok {
+		sess.SystemPrompt = prompt
+		sess.UpdatedAt = time.Now()
+	}
+	s.mu.Unlock()
+
+	return true
+}
+
+// SetWorkingDir sets a session's working directory.
+func (s *PersistentSessionStore) SetWorkingDir(sessionID, dir string) bool {
+	ctx := context.Background()
+	if err := s.sessionSvc.SetWorkingDir(ctx, sessionID, dir); err != nil {
+		return false
+	}
+
+	s.mu.Lock()
+	if sess, ok := s.cache[sessionID]; ok {
+		sess.WorkingDir = dir
+		sess.UpdatedAt = time.Now()
+	}
+	s.mu.Unlock()
+
+	return true
+}
+
 // createInMemory creates an in-memory session as fallback.
 func (s *PersistentSessionStore) createInMemory(title string) *Session {
 	id := uuid.New().String()
@@ -293,11 +377,29 @@ func convertFromMessagePkg(dbMsgs []*message.Message) []Message {
 	msgs := make([]Message, len(dbMsgs))
 	for i, dbm := range dbMsgs {
 		msgs[i] = Message{
-			ID:        dbm.ID,
-			Role:      Role(dbm.Role),
-			Content:   dbm.TextContent(),
-			Reasoning: dbm.ReasoningContent(),
-			CreatedAt: dbm.CreatedAt,
+			ID:          dbm.ID,
+			Role:        Role(dbm.Role),
+			Content:     dbm.TextContent(),
+			Reasoning:   dbm.ReasoningContent(),
+			IsSummary:   dbm.IsSummary,
+			Cancelled:   dbm.Cancelled(),
+			Interrupted: dbm.Interrupted(),
+			CreatedAt:   dbm.CreatedAt,
+		}
+		if timing := dbm.Timing(); timing != nil {
+			msgs[i].Timing = &Timing{
+				FirstToken:   timing.FirstToken,
+				OutputTokens: timing.OutputTokens,
+				Generation:   timing.Generation,
+			}
+		}
+
+		for _, att := range append(dbm.Images(), dbm.Files()...) {
+			msgs[i].Attachments = append(msgs[i].Attachments, Attachment{
+				Filename:  att.Filename,
+				MediaType: att.MediaType,
+				Data:      att.Data,
+			})
 		}
 
 		// Convert tool calls from parts
@@ -316,6 +418,7 @@ func convertFromMessagePkg(dbMsgs []*message.Message) []Message {
 				Name:       tr.Name,
 				Content:    tr.Content,
 				IsError:    tr.IsError,
+				FullOutput: tr.FullOutput,
 			})
 		}
 	}
@@ -325,7 +428,7 @@ func convertFromMessagePkg(dbMsgs []*message.Message) []Message {
 // convertToMessageParts converts an agent.Message to message.Part slice.
 func convertToMessageParts(msg Message) []message.Part {
 	// Pre-allocate with estimated capacity
-	capacity := len(msg.ToolCalls) + len(msg.ToolResults)
+	capacity := len(msg.ToolCalls) + len(msg.ToolResults) + len(msg.Attachments)
 	if msg.Content != "" {
 		capacity++
 	}
@@ -342,12 +445,36 @@ func convertToMessageParts(msg Message) []message.Part {
 		parts = append(parts, message.NewReasoningPart(msg.Reasoning))
 	}
 
+	for _, att := range msg.Attachments {
+		if att.IsImage() {
+			parts = append(parts, message.NewImagePart(att.Filename, att.MediaType, att.Data))
+		} else {
+			parts = append(parts, message.NewFilePart(att.Filename, att.MediaType, att.Data))
+		}
+	}
+
 	for _, tc := range msg.ToolCalls {
 		parts = append(parts, message.NewToolCallPart(tc.ID, tc.Name, tc.Input))
 	}
 
 	for _, tr := range msg.ToolResults {
-		parts = append(parts, message.NewToolResultPart(tr.ToolCallID, tr.Name, tr.Content, tr.IsError))
+		part := message.NewToolResultPart(tr.ToolCallID, tr.Name, tr.Content, tr.IsError)
+		part.ToolResult.FullOutput = tr.FullOutput
+		parts = append(parts, part)
+	}
+
+	if msg.Cancelled {
+		parts = append(parts, message.NewCancelledPart())
+	}
+	if msg.Interrupted {
+		parts = append(parts, message.NewInterruptedPart())
+	}
+	if msg.Timing != nil {
+		parts = append(parts, message.NewTimingPart(message.Timing{
+			FirstToken:   msg.Timing.FirstToken,
+			OutputTokens: msg.Timing.OutputTokens,
+			Generation:   msg.Timing.Generation,
+		}))
 	}
 
 	return parts
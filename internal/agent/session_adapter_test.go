@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -242,6 +243,31 @@ func TestPersistentSessionStore_AddMessage(t *testing.T) {
 	})
 }
 
+func TestPersistentSessionStore_AddMessages(t *testing.T) {
+	store := setupTestStore(t)
+	sess := store.Create("Test")
+
+	ok := store.AddMessages(sess.ID, []Message{
+		{Role: RoleAssistant, ToolCalls: []ToolCall{{ID: "call-1", Name: "read_file", Input: "{}"}}, CreatedAt: time.Now()},
+		{Role: RoleTool, ToolResults: []ToolResult{{ToolCallID: "call-1", Name: "read_file", Content: "ok"}}, CreatedAt: time.Now().Add(time.Millisecond)},
+	})
+	if !ok {
+		t.Fatal("AddMessages() returned false")
+	}
+
+	msgs := store.GetMessages(sess.ID)
+	if len(msgs) != 2 || msgs[0].Role != RoleAssistant || msgs[1].Role != RoleTool {
+		t.Fatalf("expected the turn in order, got %+v", msgs)
+	}
+	dbSess, err := store.sessionSvc.Get(context.Background(), sess.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if dbSess.MessageCount != 2 {
+		t.Errorf("MessageCount = %d, want 2", dbSess.MessageCount)
+	}
+}
+
 func TestPersistentSessionStore_GetMessages(t *testing.T) {
 	store := setupTestStore(t)
 	sess := store.Create("Test")
@@ -282,6 +308,31 @@ func TestPersistentSessionStore_ClearMessages(t *testing.T) {
 	}
 }
 
+func TestPersistentSessionStore_TruncateMessages(t *testing.T) {
+	store := setupTestStore(t)
+	sess := store.Create("Test")
+	base := time.Now()
+	for i, id := range []string{"m1", "m2", "m3"} {
+		store.AddMessage(sess.ID, Message{ID: id, Role: RoleUser, Content: id, CreatedAt: base.Add(time.Duration(i) * time.Second)})
+	}
+
+	if !store.TruncateMessages(sess.ID, "m2") {
+		t.Fatal("TruncateMessages() should succeed")
+	}
+	if msgs := store.GetMessages(sess.ID); len(msgs) != 1 || msgs[0].ID != "m1" {
+		t.Errorf("cache should keep only m1, got %+v", msgs)
+	}
+
+	// A fresh store reads the truncated session from the database.
+	reloaded := NewPersistentSessionStore(store.sessionSvc, store.messageSvc)
+	if msgs := reloaded.GetMessages(sess.ID); len(msgs) != 1 || msgs[0].ID != "m1" {
+		t.Errorf("database should keep only m1, got %+v", msgs)
+	}
+	if store.TruncateMessages(sess.ID, "missing") {
+		t.Error("TruncateMessages() should fail for an unknown message")
+	}
+}
+
 func TestPersistentSessionStore_UpdateTitle(t *testing.T) {
 	store := setupTestStore(t)
 	sess := store.Create("Original Title")
@@ -410,3 +461,61 @@ func TestConvertToMessageParts_EmptyFields(t *testing.T) {
 		t.Errorf("converted %d parts for empty message, want 0", len(parts))
 	}
 }
+
+func TestConvertAttachments_RoundTrip(t *testing.T) {
+	msg := Message{
+		Role:    RoleUser,
+		Content: "what is this?",
+		Attachments: []Attachment{
+			{Filename: "cat.png", MediaType: "image/png", Data: []byte{1, 2, 3}},
+			{Filename: "notes.md", MediaType: "text/plain", Data: []byte("# Notes")},
+		},
+	}
+
+	parts := convertToMessageParts(msg)
+	if len(parts) != 3 || parts[1].Type != message.PartTypeImage || parts[2].Type != message.PartTypeFile {
+		t.Fatalf("expected text, image and file parts, got %+v", parts)
+	}
+
+	msgs := convertFromMessagePkg([]*message.Message{{Role: message.RoleUser, Parts: parts}})
+	got := msgs[0].Attachments
+	if len(got) != 2 || got[0].Filename != "cat.png" || string(got[0].Data) != "\x01\x02\x03" ||
+		got[1].Filename != "notes.md" || got[1].IsImage() {
+		t.Errorf("attachments did not round trip: %+v", got)
+	}
+	if msgs[0].Content != "what is this?" {
+		t.Errorf("Content = %q", msgs[0].Content)
+	}
+}
+
+func TestConvertCancelled_RoundTrip(t *testing.T) {
+	parts := convertToMessageParts(Message{Role: RoleAssistant, Content: "Half an ans", Cancelled: true})
+	msgs := convertFromMessagePkg([]*message.Message{{Role: message.RoleAssistant, Parts: parts}})
+	if !msgs[0].Cancelled || msgs[0].Interrupted || msgs[0].Content != "Half an ans" {
+		t.Errorf("cancelled message did not round trip: %+v", msgs[0])
+	}
+
+	parts = convertToMessageParts(Message{Role: RoleAssistant, Content: "Half", Cancelled: true, Interrupted: true})
+	if msgs := convertFromMessagePkg([]*message.Message{{Role: message.RoleAssistant, Parts: parts}}); !msgs[0].Cancelled || !msgs[0].Interrupted {
+		t.Errorf("interrupted message did not round trip: %+v", msgs[0])
+	}
+
+	parts = convertToMessageParts(Message{Role: RoleAssistant, Content: "Done"})
+	if msgs := convertFromMessagePkg([]*message.Message{{Role: message.RoleAssistant, Parts: parts}}); msgs[0].Cancelled {
+		t.Error("a finished message should not be marked cancelled")
+	}
+}
+
+func TestConvertTiming_RoundTrip(t *testing.T) {
+	timing := &Timing{FirstToken: 400 * time.Millisecond, OutputTokens: 120, Generation: 2 * time.Second}
+	parts := convertToMessageParts(Message{Role: RoleAssistant, Content: "Done", Timing: timing})
+	msgs := convertFromMessagePkg([]*message.Message{{Role: message.RoleAssistant, Parts: parts}})
+	if msgs[0].Timing == nil || *msgs[0].Timing != *timing {
+		t.Errorf("Timing = %+v, want %+v", msgs[0].Timing, timing)
+	}
+
+	parts = convertToMessageParts(Message{Role: RoleAssistant, Content: "Done"})
+	if msgs := convertFromMessagePkg([]*message.Message{{Role: message.RoleAssistant, Parts: parts}}); msgs[0].Timing != nil {
+		t.Errorf("Timing = %+v, want nil for an untimed message", msgs[0].Timing)
+	}
+}
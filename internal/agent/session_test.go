@@ -227,6 +227,25 @@ func TestSessionStoreMessages(t *testing.T) {
 		}
 	})
 
+	t.Run("truncate messages", func(t *testing.T) {
+		store := NewSessionStore()
+		session := store.Create("Test")
+		for _, id := range []string{"m1", "m2", "m3"} {
+			store.AddMessage(session.ID, Message{ID: id, Role: RoleUser, Content: id})
+		}
+
+		if !store.TruncateMessages(session.ID, "m2") {
+			t.Fatal("Expected TruncateMessages to succeed")
+		}
+		messages := store.GetMessages(session.ID)
+		if len(messages) != 1 || messages[0].ID != "m1" {
+			t.Errorf("Expected only m1 to remain, got %+v", messages)
+		}
+		if store.TruncateMessages(session.ID, "m3") {
+			t.Error("Expected TruncateMessages to fail for a removed message")
+		}
+	})
+
 	t.Run("update title", func(t *testing.T) {
 		store := NewSessionStore()
 		session := store.Create("Original")
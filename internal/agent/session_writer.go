@@ -0,0 +1,381 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/message"
+	"github.com/guilhermegouw/cdd/internal/proc"
+)
+
+const (
+	// writerQueueSize is how many batches may wait for the writer before
+	// AddMessages blocks.
+	writerQueueSize = 256
+	// writerAttempts is how many times a batch is written before it is set
+	// aside, to be retried with the next batch and kept in the journal until
+	// then.
+	writerAttempts = 3
+)
+
+// ErrWriterClosed is returned when messages are added after Close.
+var ErrWriterClosed = errors.New("message writer is closed")
+
+// pendingBatch is a journal line: messages accepted but not yet known to be
+// in the database.
+type pendingBatch struct {
+	SessionID string             `json:"session_id"`
+	Messages  []*message.Message `json:"messages"`
+}
+
+// writeJob is either a batch to persist or, when flushed is set, a barrier
+// that is signalled once every earlier batch has been handled.
+type writeJob struct {
+	batch   pendingBatch
+	flushed chan struct{}
+}
+
+// messageWriter persists message batches in a goroutine, in the order they
+// were queued. Every batch is appended to the process's journal file before
+// it is queued, and the journal is emptied whenever the queue drains, so a
+// crash loses nothing that replayJournals cannot put back.
+type messageWriter struct {
+	persist func(pendingBatch) error
+	jobs    chan writeJob
+	done    chan struct{}
+
+	// sendMu is held for reading while sending to jobs and for writing while
+	// closing it.
+	sendMu sync.RWMutex
+	closed bool
+
+	mu      sync.Mutex
+	journal *os.File
+	pending int
+
+	// failed holds the batches that could not be written. They are retried
+	// before the next batch, and the journal is kept until they are in.
+	// Only the writer goroutine touches it until done is closed.
+	failed []pendingBatch
+}
+
+// StartWriter moves message persistence to a background goroutine, so a slow
+// disk doesn't hold up the stream. Each process journals to a file of its
+// own in journalDir; batches left there by processes that are gone are
+// written first, and the journals of running ones are left alone. Call Flush
+// to wait for queued messages and Close before exiting.
+func (s *PersistentSessionStore) StartWriter(journalDir string) error {
+	if err := os.MkdirAll(journalDir, 0o700); err != nil {
+		return fmt.Errorf("creating message journal directory: %w", err)
+	}
+	if err := s.replayJournals(journalDir); err != nil {
+		return err
+	}
+	path := filepath.Join(journalDir, journalName(proc.PID(), proc.Instance, ""))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o600) //nolint:gosec // Path comes from the data directory
+	if err != nil {
+		return fmt.Errorf("opening message journal: %w", err)
+	}
+
+	w := &messageWriter{
+		persist: s.persistBatch,
+		jobs:    make(chan writeJob, writerQueueSize),
+		done:    make(chan struct{}),
+		journal: f,
+	}
+	go w.run()
+
+	s.mu.Lock()
+	s.writer = w
+	s.mu.Unlock()
+	return nil
+}
+
+// Flush waits until every queued message has been written.
+func (s *PersistentSessionStore) Flush() {
+	s.mu.RLock()
+	w := s.writer
+	s.mu.RUnlock()
+	if w != nil {
+		w.flush()
+	}
+}
+
+// Close writes any queued messages and stops the writer. Messages added
+// afterwards are written synchronously.
+func (s *PersistentSessionStore) Close() error {
+	s.mu.Lock()
+	w := s.writer
+	s.writer = nil
+	s.mu.Unlock()
+	if w == nil {
+		return nil
+	}
+	return w.close()
+}
+
+// replayJournals writes the journals in dir left by processes that are gone.
+// Each is first claimed by renaming it after this process, so two cdds
+// starting at once don't both replay it, and removed once written.
+func (s *PersistentSessionStore) replayJournals(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return fmt.Errorf("listing message journals: %w", err)
+	}
+	for i, path := range paths {
+		pid, instance, ok := journalOwner(filepath.Base(path))
+		if !ok || proc.Running(pid, instance) {
+			continue
+		}
+		claimed := filepath.Join(dir, journalName(proc.PID(), proc.Instance, strconv.Itoa(i)))
+		if err := os.Rename(path, claimed); err != nil {
+			continue // Claimed by another cdd starting up
+		}
+		if err := s.replayJournal(claimed); err != nil {
+			return err
+		}
+		if err := os.Remove(claimed); err != nil {
+			debug.Log("[WRITER] Removing replayed journal: %v", err)
+		}
+	}
+	return nil
+}
+
+// journalName names the message journal of the process with pid and
+// instance. claim tells apart the journals it took over from gone ones.
+func journalName(pid int, instance, claim string) string {
+	if claim != "" {
+		return fmt.Sprintf("%d-%s.%s.jsonl", pid, instance, claim)
+	}
+	return fmt.Sprintf("%d-%s.jsonl", pid, instance)
+}
+
+// journalOwner returns the process a journal file name belongs to.
+func journalOwner(name string) (pid int, instance string, ok bool) {
+	name, ok = strings.CutSuffix(name, ".jsonl")
+	if !ok {
+		return 0, "", false
+	}
+	pidText, rest, ok := strings.Cut(name, "-")
+	if !ok {
+		return 0, "", false
+	}
+	pid, err := strconv.Atoi(pidText)
+	if err != nil {
+		return 0, "", false
+	}
+	instance, _, _ = strings.Cut(rest, ".")
+	return pid, instance, instance != ""
+}
+
+// replayJournal writes the messages of a journal left by a run that did not
+// shut down cleanly. Messages already in the database are skipped.
+func (s *PersistentSessionStore) replayJournal(path string) error {
+	f, err := os.Open(path) //nolint:gosec // Path comes from the data directory
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("opening message journal: %w", err)
+	}
+	defer f.Close() //nolint:errcheck // Read-only
+
+	ctx := context.Background()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var batch pendingBatch
+		if err := json.Unmarshal(scanner.Bytes(), &batch); err != nil {
+			// A line cut short by the crash; nothing after it was acknowledged.
+			debug.Log("[WRITER] Skipping unreadable journal line: %v", err)
+			continue
+		}
+		missing := batch.Messages[:0]
+		for _, msg := range batch.Messages {
+			if _, err := s.messageSvc.Get(ctx, msg.ID); errors.Is(err, message.ErrNotFound) {
+				missing = append(missing, msg)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		batch.Messages = missing
+		if err := s.persistBatch(batch); err != nil {
+			debug.Log("[WRITER] Dropping journaled messages for session %s: %v", batch.SessionID, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading message journal: %w", err)
+	}
+	return nil
+}
+
+// persistBatch writes a batch and updates the session's counters.
+func (s *PersistentSessionStore) persistBatch(batch pendingBatch) error {
+	ctx := context.Background()
+	if err := s.messageSvc.AddBatch(ctx, batch.Messages); err != nil {
+		return err
+	}
+
+	// Update message count in session (non-critical operation)
+	_ = s.sessionSvc.AddMessageCount(ctx, batch.SessionID, len(batch.Messages)) //nolint:errcheck // Non-critical count update
+
+	for _, msg := range batch.Messages {
+		if msg.IsSummary {
+			_ = s.sessionSvc.SetSummaryMessage(ctx, batch.SessionID, msg.ID) //nolint:errcheck // Summary is also flagged on the message
+		}
+	}
+	return nil
+}
+
+// enqueue journals a batch and hands it to the writer goroutine.
+func (w *messageWriter) enqueue(batch pendingBatch) error {
+	line, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("encoding messages: %w", err)
+	}
+
+	w.sendMu.RLock()
+	defer w.sendMu.RUnlock()
+	if w.closed {
+		return ErrWriterClosed
+	}
+
+	w.mu.Lock()
+	if _, err := w.journal.Write(append(line, '\n')); err != nil {
+		w.mu.Unlock()
+		return fmt.Errorf("writing message journal: %w", err)
+	}
+	// The journal is only worth keeping if it survives a crash of the machine.
+	if err := w.journal.Sync(); err != nil {
+		w.mu.Unlock()
+		return fmt.Errorf("syncing message journal: %w", err)
+	}
+	w.pending++
+	w.mu.Unlock()
+
+	// The queue is buffered, so this only waits when the disk is far behind.
+	w.jobs <- writeJob{batch: batch}
+	return nil
+}
+
+func (w *messageWriter) run() {
+	defer close(w.done)
+	for job := range w.jobs {
+		w.retryFailed()
+		if job.flushed != nil {
+			w.mu.Lock()
+			if w.pending == 0 && len(w.failed) == 0 {
+				w.resetJournal()
+			}
+			w.mu.Unlock()
+			close(job.flushed)
+			continue
+		}
+
+		if err := w.write(job.batch); err != nil {
+			debug.Log("[WRITER] Keeping %d messages for session %s in the journal: %v", len(job.batch.Messages), job.batch.SessionID, err)
+			w.failed = append(w.failed, job.batch)
+		}
+
+		w.mu.Lock()
+		w.pending--
+		if w.pending == 0 && len(w.failed) == 0 {
+			w.resetJournal()
+		}
+		w.mu.Unlock()
+	}
+}
+
+// retryFailed tries once more to write the batches that failed before, in
+// the order they were queued, keeping those that fail again.
+func (w *messageWriter) retryFailed() {
+	remaining := w.failed[:0]
+	for _, batch := range w.failed {
+		if err := w.persist(batch); err != nil {
+			remaining = append(remaining, batch)
+		}
+	}
+	clear(w.failed[len(remaining):])
+	w.failed = remaining
+}
+
+// write persists a batch, retrying briefly on errors such as a locked database.
+func (w *messageWriter) write(batch pendingBatch) error {
+	var err error
+	for attempt := range writerAttempts {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+		}
+		if err = w.persist(batch); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// resetJournal empties the journal once everything in it has been written.
+// The caller holds w.mu.
+func (w *messageWriter) resetJournal() {
+	if err := w.journal.Truncate(0); err != nil {
+		debug.Log("[WRITER] Truncating journal: %v", err)
+		return
+	}
+	if _, err := w.journal.Seek(0, io.SeekStart); err != nil {
+		debug.Log("[WRITER] Rewinding journal: %v", err)
+	}
+}
+
+func (w *messageWriter) flush() {
+	flushed := make(chan struct{})
+	w.sendMu.RLock()
+	if w.closed {
+		w.sendMu.RUnlock()
+		return
+	}
+	w.jobs <- writeJob{flushed: flushed}
+	w.sendMu.RUnlock()
+	<-flushed
+}
+
+func (w *messageWriter) close() error {
+	w.sendMu.Lock()
+	if w.closed {
+		w.sendMu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.jobs)
+	w.sendMu.Unlock()
+
+	<-w.done
+	// A last chance for batches that failed, with the usual retries.
+	remaining := w.failed[:0]
+	for _, batch := range w.failed {
+		if err := w.write(batch); err != nil {
+			remaining = append(remaining, batch)
+		}
+	}
+	w.failed = remaining
+	if err := w.journal.Close(); err != nil {
+		return fmt.Errorf("closing message journal: %w", err)
+	}
+	if len(w.failed) == 0 {
+		// Everything was written; a journal left behind is replayed at the next start.
+		if err := os.Remove(w.journal.Name()); err != nil {
+			debug.Log("[WRITER] Removing journal: %v", err)
+		}
+	}
+	return nil
+}
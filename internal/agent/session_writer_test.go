@@ -0,0 +1,165 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/guilhermegouw/cdd/internal/message"
+)
+
+func TestPersistentSessionStore_Writer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("writes queued messages in order", func(t *testing.T) {
+		store := setupTestStore(t)
+		if err := store.StartWriter(t.TempDir()); err != nil {
+			t.Fatalf("StartWriter() error = %v", err)
+		}
+		journal := store.writer.journal.Name()
+		sess := store.Create("Test")
+
+		for i := range 20 {
+			if !store.AddMessage(sess.ID, Message{Role: RoleUser, Content: fmt.Sprint(i)}) {
+				t.Fatalf("AddMessage(%d) returned false", i)
+			}
+		}
+		store.Flush()
+
+		msgs, err := store.messageSvc.GetBySession(ctx, sess.ID)
+		if err != nil || len(msgs) != 20 {
+			t.Fatalf("GetBySession() = %d messages, %v; want 20", len(msgs), err)
+		}
+		for i, msg := range msgs {
+			if got := msg.TextContent(); got != fmt.Sprint(i) {
+				t.Errorf("message %d = %q, want %q", i, got, fmt.Sprint(i))
+			}
+		}
+		dbSess, err := store.sessionSvc.Get(ctx, sess.ID)
+		if err != nil || dbSess.MessageCount != 20 {
+			t.Errorf("MessageCount = %d (%v), want 20", dbSess.MessageCount, err)
+		}
+		if info, err := os.Stat(journal); err != nil || info.Size() != 0 {
+			t.Errorf("journal should be empty once everything is written, stat = %v, %v", info, err)
+		}
+
+		if err := store.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		if _, err := os.Stat(journal); !os.IsNotExist(err) {
+			t.Errorf("the journal should be removed on a clean close, stat error = %v", err)
+		}
+		if !store.AddMessage(sess.ID, Message{Role: RoleUser, Content: "after close"}) {
+			t.Fatal("AddMessage() after Close() returned false")
+		}
+		if n, _ := store.messageSvc.Count(ctx, sess.ID); n != 21 { //nolint:errcheck // Count is checked
+			t.Errorf("Count() = %d, want the message written synchronously after Close", n)
+		}
+	})
+
+	t.Run("replays the journal of an unclean exit", func(t *testing.T) {
+		store := setupTestStore(t)
+		sess := store.Create("Test")
+		store.AddMessage(sess.ID, Message{ID: "written", Role: RoleUser, Content: "already saved"})
+
+		lost := &message.Message{ID: "lost", SessionID: sess.ID, Role: message.RoleAssistant, Parts: []message.Part{message.NewTextPart("queued")}}
+		written := &message.Message{ID: "written", SessionID: sess.ID, Role: message.RoleUser, Parts: []message.Part{message.NewTextPart("already saved")}}
+		line, err := json.Marshal(pendingBatch{SessionID: sess.ID, Messages: []*message.Message{written, lost}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := t.TempDir()
+		// Left by a cdd that is gone; the last line was cut short by the crash.
+		journal := filepath.Join(dir, journalName(0, "gone", ""))
+		data := append(line, []byte("\n{\"session_id\": \"")...)
+		if err := os.WriteFile(journal, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		// Belongs to a cdd still running, which is writing it.
+		live := filepath.Join(dir, journalName(os.Getppid(), "live", ""))
+		if err := os.WriteFile(live, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := store.StartWriter(dir); err != nil {
+			t.Fatalf("StartWriter() error = %v", err)
+		}
+		t.Cleanup(func() { _ = store.Close() }) //nolint:errcheck // Test cleanup
+
+		msgs, err := store.messageSvc.GetBySession(ctx, sess.ID)
+		if err != nil || len(msgs) != 2 || msgs[0].ID != "written" || msgs[1].ID != "lost" {
+			t.Fatalf("expected the lost message added once after the saved one, got %+v (%v)", msgs, err)
+		}
+		if _, err := os.Stat(journal); !os.IsNotExist(err) {
+			t.Errorf("the replayed journal should be removed, stat error = %v", err)
+		}
+		if got, err := os.ReadFile(live); err != nil || string(got) != string(data) {
+			t.Errorf("the journal of a running cdd should be left alone, got %q, %v", got, err)
+		}
+	})
+
+	t.Run("retries a failed batch and then empties the journal", func(t *testing.T) {
+		store := setupTestStore(t)
+		if err := store.StartWriter(t.TempDir()); err != nil {
+			t.Fatalf("StartWriter() error = %v", err)
+		}
+		journal := store.writer.journal.Name()
+		sess := store.Create("Test")
+
+		// The database is locked for the first batch's every attempt.
+		persist := store.writer.persist
+		failing := true
+		store.writer.persist = func(batch pendingBatch) error {
+			if failing {
+				return errors.New("database is locked")
+			}
+			return persist(batch)
+		}
+		store.AddMessage(sess.ID, Message{Role: RoleUser, Content: "first"})
+		store.Flush()
+		if info, err := os.Stat(journal); err != nil || info.Size() == 0 {
+			t.Fatalf("the journal should keep the failed batch, stat = %v, %v", info, err)
+		}
+
+		failing = false
+		store.AddMessage(sess.ID, Message{Role: RoleUser, Content: "second"})
+		store.Flush()
+		msgs, err := store.messageSvc.GetBySession(ctx, sess.ID)
+		if err != nil || len(msgs) != 2 || msgs[0].TextContent() != "first" {
+			t.Fatalf("expected the failed batch written before the next, got %+v (%v)", msgs, err)
+		}
+		if info, err := os.Stat(journal); err != nil || info.Size() != 0 {
+			t.Errorf("the journal should be empty once the failed batch is in, stat = %v, %v", info, err)
+		}
+
+		if err := store.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		if _, err := os.Stat(journal); !os.IsNotExist(err) {
+			t.Errorf("the journal should be removed on a clean close, stat error = %v", err)
+		}
+	})
+
+	t.Run("reads wait for queued writes", func(t *testing.T) {
+		store := setupTestStore(t)
+		if err := store.StartWriter(t.TempDir()); err != nil {
+			t.Fatalf("StartWriter() error = %v", err)
+		}
+		t.Cleanup(func() { _ = store.Close() }) //nolint:errcheck // Test cleanup
+		sess := store.Create("Test")
+		store.AddMessage(sess.ID, Message{Role: RoleUser, Content: "hello"})
+
+		// Drop the cache so the messages come from the database.
+		store.mu.Lock()
+		delete(store.cache, sess.ID)
+		store.mu.Unlock()
+
+		if msgs := store.GetMessages(sess.ID); len(msgs) != 1 {
+			t.Errorf("GetMessages() = %d messages, want 1", len(msgs))
+		}
+	})
+}
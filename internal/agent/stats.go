@@ -0,0 +1,163 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/usage"
+)
+
+// SessionStats sums up a session: its messages by role, the tools it called
+// and what its requests used.
+type SessionStats struct {
+	Messages map[Role]int
+	Tokens   map[Role]int64 // Estimated from the text of the messages
+	Tools    []ToolStats    // Most called first
+	Usage    usage.Totals   // As reported by the provider for each request
+	Timed    int            // Replies whose streaming was timed
+	Speed    Timing         // Over the timed replies, with the mean time to first token
+	Started  time.Time
+	Ended    time.Time
+}
+
+// ToolStats counts the calls of one tool.
+type ToolStats struct {
+	Name   string
+	Calls  int
+	Errors int
+}
+
+// statsRoles is the order roles are reported in.
+var statsRoles = []Role{RoleUser, RoleAssistant, RoleTool, RoleSystem}
+
+// ComputeSessionStats sums up the messages of a session, with the usage
+// recorded for its requests.
+func ComputeSessionStats(msgs []Message, totals usage.Totals) SessionStats {
+	stats := SessionStats{
+		Messages: make(map[Role]int),
+		Tokens:   make(map[Role]int64),
+		Usage:    totals,
+	}
+	tools := make(map[string]*ToolStats)
+	tool := func(name string) *ToolStats {
+		if tools[name] == nil {
+			tools[name] = &ToolStats{Name: name}
+		}
+		return tools[name]
+	}
+
+	for i := range msgs {
+		msg := &msgs[i]
+		stats.Messages[msg.Role]++
+		stats.Tokens[msg.Role] += EstimateTokens(msgs[i : i+1])
+		for _, tc := range msg.ToolCalls {
+			tool(tc.Name).Calls++
+		}
+		for _, tr := range msg.ToolResults {
+			if tr.IsError {
+				tool(tr.Name).Errors++
+			}
+		}
+		if msg.Timing != nil {
+			stats.Timed++
+			stats.Speed.FirstToken += msg.Timing.FirstToken
+			stats.Speed.OutputTokens += msg.Timing.OutputTokens
+			stats.Speed.Generation += msg.Timing.Generation
+		}
+		if msg.CreatedAt.IsZero() {
+			continue
+		}
+		if stats.Started.IsZero() || msg.CreatedAt.Before(stats.Started) {
+			stats.Started = msg.CreatedAt
+		}
+		if msg.CreatedAt.After(stats.Ended) {
+			stats.Ended = msg.CreatedAt
+		}
+	}
+
+	if stats.Timed > 0 {
+		stats.Speed.FirstToken /= time.Duration(stats.Timed)
+	}
+
+	for _, t := range tools {
+		stats.Tools = append(stats.Tools, *t)
+	}
+	sort.Slice(stats.Tools, func(i, j int) bool {
+		if stats.Tools[i].Calls != stats.Tools[j].Calls {
+			return stats.Tools[i].Calls > stats.Tools[j].Calls
+		}
+		return stats.Tools[i].Name < stats.Tools[j].Name
+	})
+	return stats
+}
+
+// SessionStats sums up a session from its messages and recorded usage.
+func (a *DefaultAgent) SessionStats(ctx context.Context, sessionID string) (SessionStats, error) {
+	totals, err := a.usage.Session(ctx, sessionID)
+	if err != nil {
+		return SessionStats{}, err //nolint:wrapcheck // Already wrapped by usage
+	}
+	return ComputeSessionStats(a.sessions.GetMessages(sessionID), totals), nil
+}
+
+// Duration returns the time from the first message to the last.
+func (s SessionStats) Duration() time.Duration {
+	return s.Ended.Sub(s.Started)
+}
+
+// Report formats the stats as plain text for the terminal or the chat.
+func (s SessionStats) Report() string {
+	var b strings.Builder
+
+	total := 0
+	for _, n := range s.Messages {
+		total += n
+	}
+	fmt.Fprintf(&b, "Messages: %d", total)
+	for _, role := range statsRoles {
+		if n := s.Messages[role]; n > 0 {
+			fmt.Fprintf(&b, "\n  %-10s %5d  ~%d tokens", role, n, s.Tokens[role])
+		}
+	}
+
+	if !s.Started.IsZero() {
+		fmt.Fprintf(&b, "\n\nDuration: %s (%s to %s)", s.Duration().Round(time.Second),
+			s.Started.Local().Format("2006-01-02 15:04"), s.Ended.Local().Format("2006-01-02 15:04"))
+	}
+
+	if s.Usage.Requests > 0 {
+		fmt.Fprintf(&b, "\n\nRequests: %d", s.Usage.Requests)
+		fmt.Fprintf(&b, "\n  input      %d tokens", s.Usage.Input)
+		fmt.Fprintf(&b, "\n  output     %d tokens", s.Usage.Output)
+		if s.Usage.CacheCreation > 0 || s.Usage.CacheRead > 0 {
+			fmt.Fprintf(&b, "\n  cache      %d written, %d read", s.Usage.CacheCreation, s.Usage.CacheRead)
+		}
+		fmt.Fprintf(&b, "\n  cost       $%.4f", s.Usage.Cost)
+	}
+
+	if s.Timed > 0 {
+		fmt.Fprintf(&b, "\n\nSpeed: over %d timed replies", s.Timed)
+		fmt.Fprintf(&b, "\n  first token  %.2fs on average", s.Speed.FirstToken.Seconds())
+		if speed := s.Speed.TokensPerSecond(); speed > 0 {
+			fmt.Fprintf(&b, "\n  output       %.1f tokens/s", speed)
+		}
+	}
+
+	if len(s.Tools) > 0 {
+		calls := 0
+		for _, t := range s.Tools {
+			calls += t.Calls
+		}
+		fmt.Fprintf(&b, "\n\nTool calls: %d", calls)
+		for _, t := range s.Tools {
+			fmt.Fprintf(&b, "\n  %-16s %4d", t.Name, t.Calls)
+			if t.Errors > 0 {
+				fmt.Fprintf(&b, "  (%d failed)", t.Errors)
+			}
+		}
+	}
+	return b.String()
+}
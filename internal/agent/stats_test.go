@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/usage"
+)
+
+func TestComputeSessionStats(t *testing.T) {
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	msgs := []Message{
+		{Role: RoleUser, Content: strings.Repeat("x", 400), CreatedAt: start},
+		{Role: RoleAssistant, ToolCalls: []ToolCall{
+			{ID: "1", Name: "bash", Input: `{"command":"go test"}`},
+			{ID: "2", Name: "read_file", Input: `{"file_path":"a.go"}`},
+		}, CreatedAt: start.Add(time.Second)},
+		{Role: RoleTool, ToolResults: []ToolResult{
+			{ToolCallID: "1", Name: "bash", Content: "FAIL", IsError: true},
+			{ToolCallID: "2", Name: "read_file", Content: "package a"},
+		}, CreatedAt: start.Add(2 * time.Second)},
+		{Role: RoleAssistant, ToolCalls: []ToolCall{{ID: "3", Name: "bash", Input: `{"command":"go test"}`}}, CreatedAt: start.Add(time.Minute)},
+		{Role: RoleTool, ToolResults: []ToolResult{{ToolCallID: "3", Name: "bash", Content: "ok"}}},
+		{Role: RoleAssistant, Content: "Fixed.", CreatedAt: start.Add(90 * time.Second),
+			Timing: &Timing{FirstToken: 300 * time.Millisecond, OutputTokens: 50, Generation: time.Second}},
+		{Role: RoleAssistant, Content: "Done.", CreatedAt: start.Add(90 * time.Second),
+			Timing: &Timing{FirstToken: 500 * time.Millisecond, OutputTokens: 100, Generation: 2 * time.Second}},
+	}
+	totals := usage.Totals{Tokens: usage.Tokens{Input: 1200, Output: 300}, Requests: 3, Cost: 0.0125}
+
+	stats := ComputeSessionStats(msgs, totals)
+	if stats.Messages[RoleUser] != 1 || stats.Messages[RoleAssistant] != 4 || stats.Messages[RoleTool] != 2 {
+		t.Errorf("Messages = %v", stats.Messages)
+	}
+	if stats.Tokens[RoleUser] != 100 {
+		t.Errorf("Tokens[user] = %d, want 100", stats.Tokens[RoleUser])
+	}
+	want := []ToolStats{{Name: "bash", Calls: 2, Errors: 1}, {Name: "read_file", Calls: 1}}
+	if len(stats.Tools) != 2 || stats.Tools[0] != want[0] || stats.Tools[1] != want[1] {
+		t.Errorf("Tools = %+v, want %+v", stats.Tools, want)
+	}
+	if stats.Timed != 2 || stats.Speed.FirstToken != 400*time.Millisecond || stats.Speed.TokensPerSecond() != 50 {
+		t.Errorf("Timed = %d, Speed = %+v; want 2 replies, 0.4s to first token and 50 tokens/s", stats.Timed, stats.Speed)
+	}
+	if stats.Duration() != 90*time.Second {
+		t.Errorf("Duration() = %v, want 1m30s", stats.Duration())
+	}
+
+	report := stats.Report()
+	for _, line := range []string{"Messages: 7", "Duration: 1m30s", "first token  0.40s", "50.0 tokens/s", "Requests: 3", "cost       $0.0125", "Tool calls: 3", "(1 failed)"} {
+		if !strings.Contains(report, line) {
+			t.Errorf("Report() is missing %q:\n%s", line, report)
+		}
+	}
+}
+
+func TestComputeSessionStats_Empty(t *testing.T) {
+	report := ComputeSessionStats(nil, usage.Totals{}).Report()
+	if report != "Messages: 0" {
+		t.Errorf("Report() = %q, want only the message count", report)
+	}
+}
@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/debug"
+)
+
+// TaskToolName is the name of the task tool.
+const TaskToolName = "task"
+
+// DefaultTaskMaxSteps bounds the number of model steps a sub-agent may take.
+const DefaultTaskMaxSteps = 25
+
+// taskMaxTokens limits the output of each sub-agent step.
+const taskMaxTokens int64 = 8192
+
+// TaskParams are the parameters for the task tool.
+type TaskParams struct {
+	Description string `json:"description" description:"A short (3-5 word) description of the task"`
+	Prompt      string `json:"prompt" description:"The task for the sub-agent to perform. Include all the context it needs, since it cannot see this conversation."`
+}
+
+const taskDescription = `Launch a sub-agent to handle a self-contained research task, such as exploring the codebase, locating definitions, or answering a question that needs many searches.
+
+The sub-agent runs with its own context and a read-only toolset (read_file, glob, grep), so its intermediate results don't fill up this conversation. It returns a summary of what it found.
+
+Usage:
+- Use it for open-ended searches that may take several rounds of glob/grep/read_file
+- Launch several tasks in the same response to explore independent areas in parallel
+- The sub-agent cannot see this conversation: write a complete, specific prompt and say what information it should return
+- The sub-agent cannot modify files or run commands; do that yourself with its findings
+- For a single known file or a simple search, use read_file, glob or grep directly instead`
+
+// taskSystemPrompt instructs the sub-agent.
+const taskSystemPrompt = `You are a sub-agent of CDD, an AI coding assistant. You were given a single task by the main agent, which cannot see your intermediate steps.
+
+- Use the available tools to investigate; you cannot modify files or run commands
+- Be thorough but efficient: run independent searches in parallel
+- Finish with a concise, self-contained report of your findings for the main agent: answer the question directly, and include relevant file paths, line numbers, and short code excerpts
+- Do not pad the report with narration of the steps you took`
+
+// TaskConfig configures the task tool.
+type TaskConfig struct {
+	Model    fantasy.LanguageModel // Model used by sub-agents (typically the small model)
+	Tools    []fantasy.AgentTool   // Tools available to sub-agents; must not include the task tool
+	MaxSteps int                   // Maximum model steps per task (0 uses DefaultTaskMaxSteps)
+}
+
+// NewTaskTool creates a tool that runs a prompt in a sub-agent with its own
+// context and returns the sub-agent's final report.
+func NewTaskTool(cfg TaskConfig) fantasy.AgentTool {
+	if cfg.MaxSteps <= 0 {
+		cfg.MaxSteps = DefaultTaskMaxSteps
+	}
+
+	return fantasy.NewParallelAgentTool(
+		TaskToolName,
+		taskDescription,
+		func(ctx context.Context, params TaskParams, _ fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if strings.TrimSpace(params.Prompt) == "" {
+				return fantasy.NewTextErrorResponse("prompt is required"), nil
+			}
+
+			report, err := runTask(ctx, cfg, params.Prompt)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("Task %q failed: %v", params.Description, err)), nil
+			}
+			return fantasy.NewTextResponse(report), nil
+		},
+	)
+}
+
+// runTask runs a sub-agent to completion and returns its final text.
+func runTask(ctx context.Context, cfg TaskConfig, prompt string) (string, error) {
+	opts := []fantasy.AgentOption{fantasy.WithMaxRetries(0)}
+	if len(cfg.Tools) > 0 {
+		opts = append(opts, fantasy.WithTools(cfg.Tools...))
+	}
+	subAgent := fantasy.NewAgent(cfg.Model, opts...)
+
+	maxTokens := taskMaxTokens
+	result, err := subAgent.Generate(ctx, fantasy.AgentCall{
+		Prompt: prompt,
+		Messages: []fantasy.Message{
+			// OAuth requires the header as a separate first block, as in Send.
+			fantasy.NewSystemMessage(oauthSystemHeader, taskSystemPrompt),
+		},
+		MaxOutputTokens: &maxTokens,
+		StopWhen:        []fantasy.StopCondition{fantasy.StepCountIs(cfg.MaxSteps)},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	debug.Log("[TASK] Sub-agent finished in %d steps (%d output tokens)", len(result.Steps), result.TotalUsage.OutputTokens)
+
+	report := strings.TrimSpace(result.Response.Content.Text())
+	if report == "" {
+		return "", fmt.Errorf("sub-agent stopped after %d steps without a report", len(result.Steps))
+	}
+	return report, nil
+}
@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"charm.land/fantasy"
+)
+
+// runTaskTool invokes a task tool with the given prompt.
+func runTaskTool(t *testing.T, tool fantasy.AgentTool, prompt string) fantasy.ToolResponse {
+	t.Helper()
+	input, err := json.Marshal(TaskParams{Description: "find things", Prompt: prompt})
+	if err != nil {
+		t.Fatalf("marshaling params: %v", err)
+	}
+	resp, err := tool.Run(context.Background(), fantasy.ToolCall{ID: "call-1", Name: TaskToolName, Input: string(input)})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	return resp
+}
+
+func TestTaskTool_UsesToolsAndReturnsReport(t *testing.T) {
+	var lookups int
+	lookup := fantasy.NewAgentTool("lookup", "Look something up",
+		func(_ context.Context, _ struct{}, _ fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			lookups++
+			return fantasy.NewTextResponse("found in main.go:12"), nil
+		})
+
+	var calls int
+	var systemPrompt string
+	model := &mockModel{
+		generateFunc: func(_ context.Context, call fantasy.Call) (*fantasy.Response, error) {
+			calls++
+			if calls == 1 {
+				for _, part := range call.Prompt[0].Content {
+					if text, ok := part.(fantasy.TextPart); ok {
+						systemPrompt += text.Text
+					}
+				}
+				return &fantasy.Response{
+					Content:      fantasy.ResponseContent{fantasy.ToolCallContent{ToolCallID: "t1", ToolName: "lookup", Input: "{}"}},
+					FinishReason: fantasy.FinishReasonToolCalls,
+				}, nil
+			}
+			return &fantasy.Response{
+				Content:      fantasy.ResponseContent{fantasy.TextContent{Text: "The handler lives in main.go:12."}},
+				FinishReason: fantasy.FinishReasonStop,
+			}, nil
+		},
+	}
+
+	resp := runTaskTool(t, NewTaskTool(TaskConfig{Model: model, Tools: []fantasy.AgentTool{lookup}}), "Where is the handler?")
+
+	if resp.IsError {
+		t.Fatalf("unexpected error response: %s", resp.Content)
+	}
+	if resp.Content != "The handler lives in main.go:12." {
+		t.Errorf("Content = %q", resp.Content)
+	}
+	if lookups != 1 || calls != 2 {
+		t.Errorf("lookups = %d, calls = %d; want 1 and 2", lookups, calls)
+	}
+	if !strings.Contains(systemPrompt, "sub-agent") {
+		t.Error("sub-agent system prompt was not sent")
+	}
+}
+
+func TestTaskTool_Errors(t *testing.T) {
+	t.Run("empty prompt", func(t *testing.T) {
+		resp := runTaskTool(t, NewTaskTool(TaskConfig{Model: &mockModel{}}), "  ")
+		if !resp.IsError {
+			t.Error("expected error response for empty prompt")
+		}
+	})
+
+	t.Run("model error", func(t *testing.T) {
+		model := &mockModel{
+			generateFunc: func(_ context.Context, _ fantasy.Call) (*fantasy.Response, error) {
+				return nil, errors.New("boom")
+			},
+		}
+		resp := runTaskTool(t, NewTaskTool(TaskConfig{Model: model}), "Explore")
+		if !resp.IsError || !strings.Contains(resp.Content, "boom") {
+			t.Errorf("expected error response mentioning the cause, got %+v", resp)
+		}
+	})
+
+	t.Run("no report", func(t *testing.T) {
+		resp := runTaskTool(t, NewTaskTool(TaskConfig{Model: summaryModel("", nil)}), "Explore")
+		if !resp.IsError {
+			t.Error("expected error response without a report")
+		}
+	})
+}
@@ -0,0 +1,32 @@
+package agent
+
+import (
+	"fmt"
+	"time"
+)
+
+// Timing is how fast the model streamed a reply, over all of its steps.
+type Timing struct {
+	FirstToken   time.Duration // From sending the request to the first token
+	OutputTokens int64
+	Generation   time.Duration // Time spent streaming, from each step's first token to its end
+}
+
+// TokensPerSecond returns the output tokens per second of streaming, or 0
+// when nothing was timed.
+func (t Timing) TokensPerSecond() float64 {
+	if t.Generation <= 0 || t.OutputTokens <= 0 {
+		return 0
+	}
+	return float64(t.OutputTokens) / t.Generation.Seconds()
+}
+
+// Summary formats the timing for display, e.g. "0.42s to first token ·
+// 52.3 tokens/s".
+func (t Timing) Summary() string {
+	summary := fmt.Sprintf("%.2fs to first token", t.FirstToken.Seconds())
+	if speed := t.TokensPerSecond(); speed > 0 {
+		summary += fmt.Sprintf(" · %.1f tokens/s", speed)
+	}
+	return summary
+}
@@ -0,0 +1,17 @@
+package agent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTiming_Summary(t *testing.T) {
+	timing := Timing{FirstToken: 420 * time.Millisecond, OutputTokens: 105, Generation: 2 * time.Second}
+	if got, want := timing.Summary(), "0.42s to first token · 52.5 tokens/s"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+	timing.Generation = 0
+	if got, want := timing.Summary(), "0.42s to first token"; got != want {
+		t.Errorf("Summary() without a speed = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/tools"
+)
+
+// limitedTool wraps a tool so a long result loses its middle before the
+// model sees it. The whole output is saved to a file, when there is a
+// directory for it, which the model is told about and the chat can show.
+type limitedTool struct {
+	fantasy.AgentTool
+	limit int
+	dir   string
+}
+
+// limitTools wraps every tool in list with limitedTool.
+func limitTools(list []fantasy.AgentTool, limit int, dir string) []fantasy.AgentTool {
+	wrapped := make([]fantasy.AgentTool, len(list))
+	for i, tool := range list {
+		wrapped[i] = limitedTool{AgentTool: tool, limit: limit, dir: dir}
+	}
+	return wrapped
+}
+
+// Run executes the tool and cuts its result to the limit.
+func (t limitedTool) Run(ctx context.Context, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+	resp, err := t.AgentTool.Run(ctx, call)
+	if err != nil || resp.Type == "image" || resp.Type == "media" {
+		return resp, err
+	}
+	content, cut := tools.TruncateMiddle(resp.Content, t.limit)
+	if !cut {
+		return resp, nil
+	}
+
+	if t.dir != "" {
+		path := fullOutputPath(t.dir, tools.SessionIDFromContext(ctx), call.ID)
+		if err := saveFullOutput(path, resp.Content); err != nil {
+			debug.Error("agent", err, "saving the full output of "+call.Name)
+		} else {
+			content += "\n\n[The whole output is saved in " + path + "; read it in parts, or search it, if you need what was left out.]"
+		}
+	}
+	resp.Content = content
+	return resp, nil
+}
+
+// fullOutputPath is where the whole output of a cut tool result is saved.
+func fullOutputPath(dir, sessionID, toolCallID string) string {
+	return filepath.Join(dir, sessionID, toolCallID+".txt")
+}
+
+// saveFullOutput writes the whole output of a tool call.
+func saveFullOutput(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err //nolint:wrapcheck // Logged by the caller
+	}
+	return os.WriteFile(path, []byte(content), 0o600) //nolint:wrapcheck // Logged by the caller
+}
+
+// savedFullOutput returns the file with the whole output of a tool call, if
+// its result was cut.
+func (a *DefaultAgent) savedFullOutput(sessionID, toolCallID string) string {
+	if a.toolOutputDir == "" {
+		return ""
+	}
+	path := fullOutputPath(a.toolOutputDir, sessionID, toolCallID)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
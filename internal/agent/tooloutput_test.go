@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/tools"
+)
+
+func TestLimitedTool(t *testing.T) {
+	type echoInput struct {
+		Text string `json:"text"`
+	}
+	echo := fantasy.NewAgentTool("echo", "Echo text",
+		func(_ context.Context, in echoInput, _ fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			return fantasy.NewTextResponse(in.Text), nil
+		})
+	dir := t.TempDir()
+	tool := limitTools([]fantasy.AgentTool{echo}, 50, dir)[0]
+	ctx := tools.WithSessionID(context.Background(), "s1")
+
+	resp, err := tool.Run(ctx, fantasy.ToolCall{ID: "short", Name: "echo", Input: `{"text":"fits"}`})
+	if err != nil || resp.Content != "fits" {
+		t.Errorf("short result = %+v, %v", resp, err)
+	}
+
+	long := strings.Repeat("x", 200)
+	resp, err = tool.Run(ctx, fantasy.ToolCall{ID: "long", Name: "echo", Input: `{"text":"` + long + `"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := fullOutputPath(dir, "s1", "long")
+	if !strings.Contains(resp.Content, "omitted") || !strings.Contains(resp.Content, path) {
+		t.Errorf("long result = %q", resp.Content)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil || string(saved) != long {
+		t.Errorf("saved output = %q, %v", saved, err)
+	}
+
+	a := &DefaultAgent{toolOutputDir: dir}
+	if got := a.savedFullOutput("s1", "long"); got != path {
+		t.Errorf("savedFullOutput(long) = %q, want %q", got, path)
+	}
+	if got := a.savedFullOutput("s1", "short"); got != "" {
+		t.Errorf("savedFullOutput(short) = %q, want none", got)
+	}
+}
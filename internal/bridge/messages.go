@@ -31,8 +31,32 @@ type TodoEventMsg struct {
 	Event pubsub.Event[events.TodoEvent]
 }
 
+// JobEventMsg wraps a background job event for the TUI.
+type JobEventMsg struct {
+	Event pubsub.Event[events.JobEvent]
+}
+
+// ConfigEventMsg wraps a config reload event for the TUI.
+type ConfigEventMsg struct {
+	Event pubsub.Event[events.ConfigEvent]
+}
+
 // ErrorMsg indicates an error in the bridge.
 type ErrorMsg struct { //nolint:govet // fieldalignment: preserving logical field order
 	Source string
 	Error  error
 }
+
+// SessionOf returns the session an agent, tool or todo event is about. It
+// reports false for other messages, which concern the whole TUI.
+func SessionOf(msg any) (string, bool) {
+	switch msg := msg.(type) {
+	case AgentEventMsg:
+		return msg.Event.Payload.SessionID, true
+	case ToolEventMsg:
+		return msg.Event.Payload.SessionID, true
+	case TodoEventMsg:
+		return msg.Event.Payload.SessionID, true
+	}
+	return "", false
+}
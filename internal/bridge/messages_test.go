@@ -176,3 +176,26 @@ func TestMessageTypesAreDistinct(t *testing.T) {
 		}
 	})
 }
+
+func TestSessionOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		msg    any
+		wantID string
+		wantOK bool
+	}{
+		{"agent event", AgentEventMsg{Event: pubsub.Event[events.AgentEvent]{Payload: events.NewTextDeltaEvent("s1", "m1", "hi")}}, "s1", true},
+		{"tool event", ToolEventMsg{Event: pubsub.Event[events.ToolEvent]{Payload: events.NewToolStartedEvent("s2", "tc", "bash", "{}")}}, "s2", true},
+		{"todo event", TodoEventMsg{Event: pubsub.Event[events.TodoEvent]{Payload: events.TodoEvent{SessionID: "s3"}}}, "s3", true},
+		{"auth event", AuthEventMsg{}, "", false},
+		{"other message", "hello", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, ok := SessionOf(tt.msg)
+			if id != tt.wantID || ok != tt.wantOK {
+				t.Errorf("SessionOf() = %q, %v; want %q, %v", id, ok, tt.wantID, tt.wantOK)
+			}
+		})
+	}
+}
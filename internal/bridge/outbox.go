@@ -0,0 +1,96 @@
+package bridge
+
+import (
+	"strings"
+	"sync"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/events"
+)
+
+// outbox holds the messages taken off the agent and tool subscriptions but
+// not yet handed to the TUI. Pushing never waits, so a stalled TUI does not
+// hold up the agent; while it is behind, streamed text and tool output of
+// the same message are merged, keeping the outbox small without losing any
+// of them. Every other event, such as a reply's end, is kept as it is.
+type outbox struct {
+	mu    sync.Mutex
+	msgs  []tea.Msg
+	ready chan struct{} // Holds a signal while msgs is not empty
+}
+
+func newOutbox() *outbox {
+	return &outbox{ready: make(chan struct{}, 1)}
+}
+
+// push adds msg, merged into the last message when both carry streamed
+// content of the same message.
+func (o *outbox) push(msg tea.Msg) {
+	o.mu.Lock()
+	if n := len(o.msgs); n > 0 {
+		if merged, ok := merge(o.msgs[n-1], msg); ok {
+			o.msgs[n-1] = merged
+			o.mu.Unlock()
+			return
+		}
+	}
+	o.msgs = append(o.msgs, msg)
+	o.mu.Unlock()
+
+	select {
+	case o.ready <- struct{}{}:
+	default: // Already signalled
+	}
+}
+
+// take removes and returns the messages waiting, oldest first.
+func (o *outbox) take() []tea.Msg {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	msgs := o.msgs
+	o.msgs = nil
+	return msgs
+}
+
+// merge combines two messages of streamed content into one: text or
+// reasoning deltas of the same reply, or output chunks of the same tool call.
+func merge(prev, next tea.Msg) (tea.Msg, bool) {
+	switch p := prev.(type) {
+	case AgentEventMsg:
+		n, ok := next.(AgentEventMsg)
+		a, b := p.Event.Payload, n.Event.Payload
+		if !ok || a.Type != b.Type || a.SessionID != b.SessionID || a.MessageID != b.MessageID {
+			return nil, false
+		}
+		switch a.Type { //nolint:exhaustive // Only deltas are merged
+		case events.AgentEventTextDelta:
+			p.Event.Payload.TextDelta += b.TextDelta
+		case events.AgentEventReasoningDelta:
+			p.Event.Payload.ReasoningDelta += b.ReasoningDelta
+		default:
+			return nil, false
+		}
+		p.Event.Dropped += n.Event.Dropped
+		return p, true
+	case ToolEventMsg:
+		n, ok := next.(ToolEventMsg)
+		if !ok || !isOutputChunk(p.Event.Payload) || !isOutputChunk(n.Event.Payload) ||
+			p.Event.Payload.ToolCallID != n.Event.Payload.ToolCallID {
+			return nil, false
+		}
+		// Chunks are shown line by line, so joining them on a line break
+		// shows the same lines.
+		p.Event.Payload.Chunk = strings.TrimRight(p.Event.Payload.Chunk, "\n") + "\n" + n.Event.Payload.Chunk
+		p.Event.Dropped += n.Event.Dropped
+		return p, true
+	}
+	return nil, false
+}
+
+// isOutputChunk reports whether a tool event carries streamed output and
+// nothing else.
+func isOutputChunk(e events.ToolEvent) bool {
+	return e.Type == events.ToolEventProgress && e.Chunk != "" &&
+		e.Step == "" && e.Progress == 0 && e.FilePath == "" && e.Diff == ""
+}
@@ -0,0 +1,77 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+func agentMsg(payload events.AgentEvent) AgentEventMsg {
+	return AgentEventMsg{Event: pubsub.Event[events.AgentEvent]{Payload: payload}}
+}
+
+func toolMsg(payload events.ToolEvent) ToolEventMsg {
+	return ToolEventMsg{Event: pubsub.Event[events.ToolEvent]{Payload: payload}}
+}
+
+func TestOutbox_Merge(t *testing.T) {
+	o := newOutbox()
+	o.push(agentMsg(events.NewTextDeltaEvent("s", "m1", "Hel")))
+	o.push(agentMsg(events.NewTextDeltaEvent("s", "m1", "lo")))
+	o.push(agentMsg(events.NewTextDeltaEvent("s", "m2", "!")))
+	o.push(toolMsg(events.NewToolOutputEvent("s", "tc", "bash", "line 1\n")))
+	o.push(toolMsg(events.NewToolOutputEvent("s", "tc", "bash", "line 2\n")))
+	o.push(toolMsg(events.NewToolStepEvent("s", "tc", "bash", "5s elapsed", 0)))
+	o.push(agentMsg(events.AgentEvent{SessionID: "s", MessageID: "m2", Type: events.AgentEventComplete}))
+
+	msgs := o.take()
+	if len(msgs) != 5 {
+		t.Fatalf("take() = %d messages, want 5: %+v", len(msgs), msgs)
+	}
+	if got := msgs[0].(AgentEventMsg).Event.Payload.TextDelta; got != "Hello" {
+		t.Errorf("deltas of one reply should merge, got %q", got)
+	}
+	if got := msgs[1].(AgentEventMsg).Event.Payload.TextDelta; got != "!" {
+		t.Errorf("deltas of another reply should stay apart, got %q", got)
+	}
+	if got := msgs[2].(ToolEventMsg).Event.Payload.Chunk; got != "line 1\nline 2\n" {
+		t.Errorf("output chunks of one call should merge, got %q", got)
+	}
+	if got := msgs[3].(ToolEventMsg).Event.Payload.Step; got != "5s elapsed" {
+		t.Errorf("a step should be kept as it is, got %q", got)
+	}
+	if got := msgs[4].(AgentEventMsg).Event.Payload.Type; got != events.AgentEventComplete {
+		t.Errorf("the reply's end should come last, got %q", got)
+	}
+	if o.take() != nil {
+		t.Error("take() should empty the outbox")
+	}
+}
+
+func TestTUIBridge_StalledTUI(t *testing.T) {
+	hub := pubsub.NewHub()
+	defer hub.Shutdown()
+
+	// A program that never runs takes no messages, like a stalled TUI.
+	programCtx, stopProgram := context.WithCancel(context.Background())
+	program := tea.NewProgram(nil, tea.WithContext(programCtx))
+	bridge := NewTUIBridge(hub, program)
+	bridge.Start(context.Background())
+	defer bridge.Stop()
+	defer stopProgram()
+	time.Sleep(20 * time.Millisecond) // Let the subscriptions start
+
+	started := time.Now()
+	for range 2 * bridgeBufferSize {
+		hub.Agent.Publish(pubsub.EventProgress, events.NewTextDeltaEvent("s", "m", "x"))
+	}
+	hub.Agent.Publish(pubsub.EventCompleted, events.AgentEvent{SessionID: "s", MessageID: "m", Type: events.AgentEventComplete})
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("publishing to a stalled TUI took %s; the agent should not wait on it", elapsed)
+	}
+}
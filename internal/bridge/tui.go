@@ -2,6 +2,7 @@ package bridge
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	tea "charm.land/bubbletea/v2"
@@ -10,6 +11,12 @@ import (
 	"github.com/guilhermegouw/cdd/internal/pubsub"
 )
 
+// bridgeBufferSize is the room for bursts of agent and tool events. They
+// never wait for the TUI: the bridge moves them to its outbox as they come,
+// and should it fall behind even so, the oldest are dropped rather than the
+// agent held up.
+const bridgeBufferSize = 256
+
 // TUIBridge subscribes to all Hub brokers and forwards events to tea.Program.
 // It handles the conversion from domain events to Bubble Tea messages.
 type TUIBridge struct { //nolint:govet // fieldalignment: preserving logical field order
@@ -19,6 +26,7 @@ type TUIBridge struct { //nolint:govet // fieldalignment: preserving logical fie
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
+	outbox *outbox // Agent and tool events on their way to the TUI
 
 	// Optional filters
 	sessionFilter string // Only forward events for this session
@@ -39,6 +47,7 @@ func NewTUIBridge(hub *pubsub.Hub, program *tea.Program, opts ...TUIBridgeOption
 	b := &TUIBridge{
 		hub:     hub,
 		program: program,
+		outbox:  newOutbox(),
 	}
 
 	for _, opt := range opts {
@@ -54,12 +63,15 @@ func (b *TUIBridge) Start(ctx context.Context) {
 	b.ctx, b.cancel = context.WithCancel(ctx)
 
 	// Start subscriber goroutines for each broker
-	b.wg.Add(5)
+	b.wg.Add(8)
+	go b.send()
 	go b.subscribeAgent()
 	go b.subscribeTool()
 	go b.subscribeSession()
 	go b.subscribeAuth()
 	go b.subscribeTodo()
+	go b.subscribeJob()
+	go b.subscribeConfig()
 
 	debug.Event("bridge", "start", "TUI bridge started")
 }
@@ -76,7 +88,10 @@ func (b *TUIBridge) Stop() {
 func (b *TUIBridge) subscribeAgent() {
 	defer b.wg.Done()
 
-	events := b.hub.Agent.Subscribe(b.ctx)
+	events := b.hub.Agent.Subscribe(b.ctx,
+		pubsub.WithSubscriberName("tui"),
+		pubsub.WithSubscriberBuffer(bridgeBufferSize),
+		pubsub.WithSubscriberPolicy(pubsub.DropOldest, 0))
 	for {
 		select {
 		case <-b.ctx.Done():
@@ -85,13 +100,14 @@ func (b *TUIBridge) subscribeAgent() {
 			if !ok {
 				return
 			}
+			logDropped("agent", event.Dropped)
 
 			// Apply session filter if set
 			if b.sessionFilter != "" && event.Payload.SessionID != b.sessionFilter {
 				continue
 			}
 
-			b.program.Send(AgentEventMsg{Event: event})
+			b.outbox.push(AgentEventMsg{Event: event})
 		}
 	}
 }
@@ -99,7 +115,10 @@ func (b *TUIBridge) subscribeAgent() {
 func (b *TUIBridge) subscribeTool() {
 	defer b.wg.Done()
 
-	events := b.hub.Tool.Subscribe(b.ctx)
+	events := b.hub.Tool.Subscribe(b.ctx,
+		pubsub.WithSubscriberName("tui"),
+		pubsub.WithSubscriberBuffer(bridgeBufferSize),
+		pubsub.WithSubscriberPolicy(pubsub.DropOldest, 0))
 	for {
 		select {
 		case <-b.ctx.Done():
@@ -108,13 +127,30 @@ func (b *TUIBridge) subscribeTool() {
 			if !ok {
 				return
 			}
+			logDropped("tool", event.Dropped)
 
 			// Apply session filter if set
 			if b.sessionFilter != "" && event.Payload.SessionID != b.sessionFilter {
 				continue
 			}
 
-			b.program.Send(ToolEventMsg{Event: event})
+			b.outbox.push(ToolEventMsg{Event: event})
+		}
+	}
+}
+
+// send hands the outbox's messages to the TUI in order. It is the only part
+// of the bridge that waits on a slow TUI.
+func (b *TUIBridge) send() {
+	defer b.wg.Done()
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-b.outbox.ready:
+		}
+		for _, msg := range b.outbox.take() {
+			b.program.Send(msg)
 		}
 	}
 }
@@ -122,7 +158,7 @@ func (b *TUIBridge) subscribeTool() {
 func (b *TUIBridge) subscribeSession() {
 	defer b.wg.Done()
 
-	events := b.hub.Session.Subscribe(b.ctx)
+	events := b.hub.Session.Subscribe(b.ctx, pubsub.WithSubscriberName("tui"))
 	for {
 		select {
 		case <-b.ctx.Done():
@@ -131,6 +167,7 @@ func (b *TUIBridge) subscribeSession() {
 			if !ok {
 				return
 			}
+			logDropped("session", event.Dropped)
 
 			b.program.Send(SessionEventMsg{Event: event})
 		}
@@ -140,7 +177,7 @@ func (b *TUIBridge) subscribeSession() {
 func (b *TUIBridge) subscribeAuth() {
 	defer b.wg.Done()
 
-	events := b.hub.Auth.Subscribe(b.ctx)
+	events := b.hub.Auth.Subscribe(b.ctx, pubsub.WithSubscriberName("tui"))
 	for {
 		select {
 		case <-b.ctx.Done():
@@ -149,6 +186,7 @@ func (b *TUIBridge) subscribeAuth() {
 			if !ok {
 				return
 			}
+			logDropped("auth", event.Dropped)
 
 			b.program.Send(AuthEventMsg{Event: event})
 		}
@@ -158,7 +196,10 @@ func (b *TUIBridge) subscribeAuth() {
 func (b *TUIBridge) subscribeTodo() {
 	defer b.wg.Done()
 
-	events := b.hub.Todo.Subscribe(b.ctx)
+	// Each todo event holds the whole list, so only the latest matters.
+	events := b.hub.Todo.Subscribe(b.ctx,
+		pubsub.WithSubscriberName("tui"),
+		pubsub.WithSubscriberPolicy(pubsub.DropOldest, 0))
 	for {
 		select {
 		case <-b.ctx.Done():
@@ -167,6 +208,7 @@ func (b *TUIBridge) subscribeTodo() {
 			if !ok {
 				return
 			}
+			logDropped("todo", event.Dropped)
 
 			// Apply session filter if set
 			if b.sessionFilter != "" && event.Payload.SessionID != b.sessionFilter {
@@ -178,6 +220,54 @@ func (b *TUIBridge) subscribeTodo() {
 	}
 }
 
+// subscribeJob forwards job events whatever the session filter, since jobs
+// run in sessions of their own.
+func (b *TUIBridge) subscribeJob() {
+	defer b.wg.Done()
+
+	events := b.hub.Job.Subscribe(b.ctx, pubsub.WithSubscriberName("tui"))
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			logDropped("job", event.Dropped)
+
+			b.program.Send(JobEventMsg{Event: event})
+		}
+	}
+}
+
+func (b *TUIBridge) subscribeConfig() {
+	defer b.wg.Done()
+
+	events := b.hub.Config.Subscribe(b.ctx, pubsub.WithSubscriberName("tui"))
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			logDropped("config", event.Dropped)
+
+			b.program.Send(ConfigEventMsg{Event: event})
+		}
+	}
+}
+
+// logDropped records events of a broker that the bridge missed because the
+// TUI fell behind.
+func logDropped(broker string, dropped int) {
+	if dropped > 0 {
+		debug.Event("bridge", "dropped", fmt.Sprintf("broker=%s events=%d", broker, dropped))
+	}
+}
+
 // SetSessionFilter updates the session filter at runtime.
 func (b *TUIBridge) SetSessionFilter(sessionID string) {
 	b.sessionFilter = sessionID
@@ -0,0 +1,131 @@
+// Package commands loads custom slash commands: Markdown files under
+// .cdd/commands whose contents are sent as a prompt when the command is run.
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/tools"
+)
+
+// Dir is where commands are looked up, relative to the working directory and
+// each of its ancestors.
+const Dir = ".cdd/commands"
+
+// maxDescription caps a description taken from the first line of a command.
+const maxDescription = 80
+
+// placeholder matches the argument references in a command template.
+var placeholder = regexp.MustCompile(`\$(ARGUMENTS|[1-9])`)
+
+// Command is a custom slash command.
+type Command struct {
+	Name        string // File name without .md, run as /Name
+	Description string
+	Template    string // Prompt with $ARGUMENTS and $1..$9 placeholders
+	Path        string
+}
+
+// Load returns the commands defined in the Dir of workingDir and its
+// ancestors, sorted by name. A command in a deeper directory replaces one
+// with the same name further up. Unreadable files are skipped.
+func Load(workingDir string) []Command {
+	absDir, err := filepath.Abs(workingDir)
+	if err != nil {
+		debug.Log("[COMMANDS] Resolving %s: %v", workingDir, err)
+		return nil
+	}
+
+	byName := make(map[string]Command)
+	for _, dir := range tools.Ancestors(absDir) {
+		paths, err := filepath.Glob(filepath.Join(dir, Dir, "*.md"))
+		if err != nil {
+			continue
+		}
+		for _, path := range paths {
+			cmd, err := readCommand(path)
+			if err != nil {
+				debug.Log("[COMMANDS] Skipping %s: %v", path, err)
+				continue
+			}
+			byName[cmd.Name] = cmd
+		}
+	}
+
+	cmds := make([]Command, 0, len(byName))
+	for _, cmd := range byName {
+		cmds = append(cmds, cmd)
+	}
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
+	return cmds
+}
+
+// Expand fills the template with args: $ARGUMENTS is replaced by all of
+// them and $1 to $9 by each one. Arguments given to a template without
+// placeholders are appended to it.
+func (c Command) Expand(args []string) string {
+	joined := strings.Join(args, " ")
+	if !placeholder.MatchString(c.Template) {
+		if joined == "" {
+			return c.Template
+		}
+		return c.Template + "\n\n" + joined
+	}
+	return placeholder.ReplaceAllStringFunc(c.Template, func(ref string) string {
+		if ref == "$ARGUMENTS" {
+			return joined
+		}
+		if i := int(ref[1] - '1'); i < len(args) {
+			return args[i]
+		}
+		return ""
+	})
+}
+
+// readCommand parses a command file. An optional front matter block between
+// "---" lines may set the description; otherwise the first line is used.
+func readCommand(path string) (Command, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: Commands are the user's own files.
+	if err != nil {
+		return Command{}, err
+	}
+
+	cmd := Command{
+		Name: strings.ToLower(strings.TrimSuffix(filepath.Base(path), ".md")),
+		Path: path,
+	}
+	if strings.ContainsFunc(cmd.Name, unicode.IsSpace) {
+		return Command{}, errors.New("command names cannot contain spaces")
+	}
+	body := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if rest, ok := strings.CutPrefix(body, "---\n"); ok {
+		if header, after, found := strings.Cut(rest, "\n---\n"); found {
+			body = after
+			for line := range strings.SplitSeq(header, "\n") {
+				if key, value, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(key) == "description" {
+					cmd.Description = strings.Trim(strings.TrimSpace(value), `"'`)
+				}
+			}
+		}
+	}
+	cmd.Template = strings.TrimSpace(body)
+	if cmd.Template == "" {
+		return Command{}, errors.New("empty command")
+	}
+
+	if cmd.Description == "" {
+		first, _, _ := strings.Cut(cmd.Template, "\n")
+		cmd.Description = strings.TrimSpace(strings.TrimLeft(first, "# "))
+		if runes := []rune(cmd.Description); len(runes) > maxDescription {
+			cmd.Description = string(runes[:maxDescription-1]) + "…"
+		}
+	}
+	return cmd, nil
+}
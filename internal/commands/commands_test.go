@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "project")
+	sub := filepath.Join(project, "pkg")
+
+	writeFile(t, filepath.Join(root, Dir, "review.md"), "Review the outer code.")
+	writeFile(t, filepath.Join(project, Dir, "Review.md"), "---\ndescription: \"Review changes\"\n---\nReview $ARGUMENTS.\n")
+	writeFile(t, filepath.Join(project, Dir, "explain.md"), "# Explain a file\n\nExplain $1 in detail.")
+	writeFile(t, filepath.Join(project, Dir, "empty.md"), "  \n")
+	writeFile(t, filepath.Join(project, Dir, "notes.txt"), "not a command")
+
+	cmds := Load(sub)
+	if len(cmds) != 2 {
+		t.Fatalf("Load() = %+v, want 2 commands", cmds)
+	}
+	if cmds[0].Name != "explain" || cmds[0].Description != "Explain a file" {
+		t.Errorf("explain = %+v", cmds[0])
+	}
+	if cmds[1].Name != "review" || cmds[1].Description != "Review changes" || cmds[1].Template != "Review $ARGUMENTS." {
+		t.Errorf("review = %+v, want the project's command to replace the outer one", cmds[1])
+	}
+}
+
+func TestCommand_Expand(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		args     []string
+		want     string
+	}{
+		{"all arguments", "Review $ARGUMENTS now.", []string{"main.go", "util.go"}, "Review main.go util.go now."},
+		{"positional", "Compare $1 with $2; ignore $3.", []string{"a", "b"}, "Compare a with b; ignore ."},
+		{"no placeholders", "Run the tests.", []string{"quickly"}, "Run the tests.\n\nquickly"},
+		{"no arguments", "Run the tests.", nil, "Run the tests."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Command{Template: tt.template}).Expand(tt.args); got != tt.want {
+				t.Errorf("Expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -38,6 +38,7 @@ type SelectedModel struct {
 	ReasoningEffort  string         `json:"reasoning_effort,omitempty"`
 	MaxTokens        int64          `json:"max_tokens,omitempty"`
 	Think            bool           `json:"think,omitempty"`
+	ThinkingBudget   int64          `json:"thinking_budget,omitempty"` // Tokens the model may spend thinking when Think is set
 }
 
 // ProviderConfig holds provider authentication and settings.
@@ -45,7 +46,9 @@ type SelectedModel struct {
 //nolint:govet // Field order is intentional for JSON readability.
 type ProviderConfig struct {
 	ExtraHeaders       map[string]string `json:"extra_headers,omitempty"`
+	ExtraQuery         map[string]string `json:"extra_query,omitempty"`
 	ProviderOptions    map[string]any    `json:"provider_options,omitempty"`
+	HTTP               *HTTPOptions      `json:"http,omitempty"`
 	Models             []catwalk.Model   `json:"models,omitempty"`
 	OAuthToken         *oauth.Token      `json:"oauth,omitempty"`
 	ID                 string            `json:"id,omitempty"`
@@ -55,6 +58,21 @@ type ProviderConfig struct {
 	APIKey             string            `json:"api_key,omitempty"`
 	SystemPromptPrefix string            `json:"-"`
 	Disable            bool              `json:"disable,omitempty"`
+
+	// apiKeyRef is the environment variable reference APIKey was resolved
+	// from, and apiKeyResolved its value then, so saving can write the
+	// reference back while the key is unchanged.
+	apiKeyRef      string
+	apiKeyResolved string
+}
+
+// HTTPOptions tune how requests reach a provider, for corporate networks
+// with proxies and private certificate authorities.
+type HTTPOptions struct {
+	Proxy              string `json:"proxy,omitempty"`                // Proxy URL; HTTPS_PROXY and friends apply when empty
+	CACertFile         string `json:"ca_cert_file,omitempty"`         // PEM bundle trusted on top of the system roots
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"` // Accept any server certificate
+	TimeoutSeconds     int    `json:"timeout_seconds,omitempty"`      // How long to wait for a response to start
 }
 
 // SetupClaudeCode configures the provider for Claude Code OAuth authentication.
@@ -77,17 +95,264 @@ type Config struct {
 	Models         map[SelectedModelType]SelectedModel `json:"models"`
 	Providers      map[string]*ProviderConfig          `json:"providers"`
 	Connections    []Connection                        `json:"connections,omitempty"`
+	LSP            map[string]LSPConfig                `json:"lsp,omitempty"`
+	Hooks          *HooksConfig                        `json:"hooks,omitempty"`
+	Sandbox        *SandboxConfig                      `json:"sandbox,omitempty"`
+	Modes          map[string]ModeConfig               `json:"modes,omitempty"`
+	Integrations   *IntegrationsConfig                 `json:"integrations,omitempty"`
 	Options        *Options                            `json:"options,omitempty"`
 	knownProviders []catwalk.Provider
+	promptBaseDir  string // Directory of the config file that set Options.SystemPromptFile
+	// providersWarning says why known providers came from a fallback.
+	providersWarning string
+}
+
+// LSPConfig configures a language server used for diagnostics, keyed by name
+// in Config.LSP.
+//
+//nolint:govet // Field order is intentional for JSON readability.
+type LSPConfig struct {
+	Command     string            `json:"command"`
+	Args        []string          `json:"args,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	FileTypes   []string          `json:"filetypes"` // Extensions handled by the server, e.g. "go" or ".ts"
+	InitOptions map[string]any    `json:"init_options,omitempty"`
+	Disabled    bool              `json:"disabled,omitempty"`
+}
+
+// HooksConfig lists the shell commands run on agent lifecycle events. Each
+// command gets the event as JSON on stdin.
+//
+//nolint:govet // Field order is intentional for JSON readability.
+type HooksConfig struct {
+	PreTool        []HookConfig `json:"pre_tool,omitempty"`         // Before a tool runs; a failing hook blocks it
+	PostTool       []HookConfig `json:"post_tool,omitempty"`        // After a tool runs; a failing hook's output is shown to the model
+	OnComplete     []HookConfig `json:"on_complete,omitempty"`      // After the agent finishes replying
+	OnSessionStart []HookConfig `json:"on_session_start,omitempty"` // Before the first prompt of a session
+}
+
+// HookConfig is one hook command.
+type HookConfig struct {
+	Command string   `json:"command"`
+	Tools   []string `json:"tools,omitempty"`   // Tool names the hook applies to (all when empty)
+	Timeout int      `json:"timeout,omitempty"` // Seconds before the command is killed (default 60)
+}
+
+// SandboxConfig restricts the files tools may access to the working
+// directory and the programs the bash tool may run.
+//
+//nolint:govet // Field order is intentional for JSON readability.
+type SandboxConfig struct {
+	Enabled         bool     `json:"enabled,omitempty"`
+	AllowedPaths    []string `json:"allowed_paths,omitempty"`    // Directories outside the working directory tools may access
+	AllowedCommands []string `json:"allowed_commands,omitempty"` // Programs bash may run, e.g. "go" or "git" (any when empty)
+}
+
+// ModeConfig configures an agent mode, keyed by name in Config.Modes: a
+// persona with its own instructions and the tools it may use.
+//
+//nolint:govet // Field order is intentional for JSON readability.
+type ModeConfig struct {
+	Description string   `json:"description,omitempty"`
+	Prompt      string   `json:"prompt,omitempty"`    // Instructions added to the system prompt
+	Tools       []string `json:"tools,omitempty"`     // Tool names the mode may use (all when empty)
+	ReadOnly    bool     `json:"read_only,omitempty"` // Only tools that don't modify files or run commands, plus Tools
+}
+
+// IntegrationsConfig holds the credentials of the external services cdd
+// publishes to.
+type IntegrationsConfig struct {
+	Share  string             `json:"share,omitempty"` // Where /share publishes: "gist" (default) or "paste"
+	GitHub *GitHubIntegration `json:"github,omitempty"`
+	Paste  *PasteIntegration  `json:"paste,omitempty"`
+	Jira   *JiraIntegration   `json:"jira,omitempty"`
+	Linear *LinearIntegration `json:"linear,omitempty"`
+}
+
+// GitHubIntegration configures access to GitHub, used to create gists.
+type GitHubIntegration struct {
+	Token   string `json:"token,omitempty"`    // Token with the gist scope (default $GITHUB_TOKEN); may reference an environment variable
+	BaseURL string `json:"base_url,omitempty"` // API base URL for GitHub Enterprise (default https://api.github.com)
+	Public  bool   `json:"public,omitempty"`   // Create public gists instead of secret ones
+}
+
+// PasteIntegration configures a paste service that takes a POSTed document
+// and answers with its URL, as plain text or a JSON "url" field.
+//
+//nolint:govet // Field order is intentional for JSON readability.
+type PasteIntegration struct {
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers,omitempty"`    // Sent with the request; values may reference environment variables
+	FormField string            `json:"form_field,omitempty"` // Upload as this multipart form file field instead of the raw body
+}
+
+// JiraIntegration configures the Jira site the fetch_ticket tool reads issues
+// from.
+//
+//nolint:govet // Field order is intentional for JSON readability.
+type JiraIntegration struct {
+	BaseURL string `json:"base_url"`        // e.g. https://example.atlassian.net
+	Email   string `json:"email,omitempty"` // Account for Jira Cloud API tokens; without it the token is sent as a bearer token
+	Token   string `json:"token"`           // May reference an environment variable
+
+	// AcceptanceCriteriaField is the custom field holding acceptance
+	// criteria, e.g. customfield_10035. Without it they are taken from an
+	// "Acceptance criteria" section of the description.
+	AcceptanceCriteriaField string `json:"acceptance_criteria_field,omitempty"`
+}
+
+// LinearIntegration configures the Linear workspace the fetch_ticket tool
+// reads issues from.
+type LinearIntegration struct {
+	Token   string `json:"token"`              // Personal API key; may reference an environment variable
+	BaseURL string `json:"base_url,omitempty"` // API base URL (default https://api.linear.app)
 }
 
 // Options holds optional configuration settings.
 //
 //nolint:govet // Field order is intentional for JSON readability.
 type Options struct {
-	ContextPaths []string `json:"context_paths,omitempty"`
-	DataDir      string   `json:"data_directory,omitempty"`
-	Debug        bool     `json:"debug,omitempty"`
+	ContextPaths  []string `json:"context_paths,omitempty"`
+	DataDir       string   `json:"data_directory,omitempty"`
+	BashTimeout   int      `json:"bash_timeout,omitempty"`   // Default bash tool timeout in seconds
+	MaxAttempts   int      `json:"max_attempts,omitempty"`   // Attempts per request on transient provider errors
+	MaxIterations int      `json:"max_iterations,omitempty"` // Model steps per prompt before the agent stops (-1 for no limit)
+	Debug         bool     `json:"debug,omitempty"`
+	VimMode       bool     `json:"vim_mode,omitempty"`      // Vim-style modal editing in the chat input
+	ShowThinking  bool     `json:"show_thinking,omitempty"` // Expand model reasoning in the chat
+	Accessible    bool     `json:"accessible,omitempty"`    // Screen reader output: no spinners, box drawing or color-only cues
+	Colors        string   `json:"colors,omitempty"`        // Colors the TUI uses, one of ColorSettings (default "auto")
+	DefaultMode   string   `json:"default_mode,omitempty"`  // Agent mode at startup (default "code")
+	PlanFirst     bool     `json:"plan_first,omitempty"`    // Plan each prompt and wait for approval before changing anything
+	Transcript    bool     `json:"transcript,omitempty"`    // Append every message to a JSONL file per session in the data directory
+
+	// SystemPromptFile replaces the built-in system prompt with the file's
+	// contents. Relative paths are resolved against the config file's directory.
+	SystemPromptFile string `json:"system_prompt_file,omitempty"`
+
+	// Keybindings overrides TUI key bindings by action name, e.g. {"send": ["enter"]}.
+	Keybindings map[string][]string `json:"keybindings,omitempty"`
+
+	// ModelAliases names models for /model, --model and one-off overrides,
+	// e.g. {"fast": "groq/llama-3.1-8b-instant"}.
+	ModelAliases map[string]string `json:"model_aliases,omitempty"`
+
+	// StatusBar lists the segments of the chat status bar in order, e.g.
+	// ["model", "cost"]. Unset shows DefaultStatusBar.
+	StatusBar []string `json:"status_bar,omitempty"`
+
+	Telemetry     *TelemetryOptions    `json:"telemetry,omitempty"`
+	Metrics       *MetricsOptions      `json:"metrics,omitempty"`
+	Backup        *BackupOptions       `json:"backup,omitempty"`
+	Notifications *NotificationOptions `json:"notifications,omitempty"`
+	Index         *IndexOptions        `json:"index,omitempty"`
+	Budget        *BudgetOptions       `json:"budget,omitempty"`
+	ToolOutput    *ToolOutputOptions   `json:"tool_output,omitempty"`
+
+	// Markdown configures how replies are rendered in the chat.
+	Markdown *MarkdownOptions `json:"markdown,omitempty"`
+}
+
+// Status bar segments.
+const (
+	SegmentModel   = "model"   // Model name
+	SegmentSession = "session" // Session title
+	SegmentContext = "context" // Estimated context use against the window
+	SegmentCost    = "cost"    // Tokens and cost of the session
+	SegmentBranch  = "branch"  // Git branch of the working directory
+	SegmentElapsed = "elapsed" // Time the reply in progress has taken
+)
+
+// StatusSegments lists every status bar segment.
+var StatusSegments = []string{SegmentModel, SegmentSession, SegmentContext, SegmentCost, SegmentBranch, SegmentElapsed}
+
+// DefaultStatusBar is the status bar shown when options.status_bar is unset.
+var DefaultStatusBar = []string{SegmentModel, SegmentContext, SegmentCost, SegmentBranch, SegmentElapsed}
+
+// Color settings.
+const (
+	ColorsAuto      = "auto"      // What the terminal reports; NO_COLOR turns color off
+	ColorsTrueColor = "truecolor" // 24-bit color
+	Colors256       = "256"       // The 256-color palette
+	Colors16        = "16"        // The terminal's 16 colors
+	ColorsNone      = "none"      // No color, only bold, underline and reverse video
+)
+
+// ColorSettings lists every color setting.
+var ColorSettings = []string{ColorsAuto, ColorsTrueColor, Colors256, Colors16, ColorsNone}
+
+// Markdown styles.
+const (
+	MarkdownStyleDark  = "dark"  // Colors of the app theme, for dark backgrounds
+	MarkdownStyleLight = "light" // Colors for light backgrounds
+	MarkdownStyleNoTTY = "notty" // No colors
+)
+
+// MarkdownStyles lists every markdown style.
+var MarkdownStyles = []string{MarkdownStyleDark, MarkdownStyleLight, MarkdownStyleNoTTY}
+
+// MarkdownOptions configures the markdown renderer of the chat.
+type MarkdownOptions struct {
+	Style     string `json:"style,omitempty"`      // One of MarkdownStyles (default "dark")
+	WordWrap  int    `json:"word_wrap,omitempty"`  // Column replies wrap at, if narrower than the chat
+	CodeTheme string `json:"code_theme,omitempty"` // Chroma theme for code blocks, e.g. "github" (default the style's own)
+}
+
+// ToolOutputOptions limits how much of a tool's output the model is sent and
+// the chat shows. Longer output keeps its start and end.
+type ToolOutputOptions struct {
+	MaxChars     int `json:"max_chars,omitempty"`     // Characters sent to the model (default 30000, -1 for no limit)
+	DisplayLines int `json:"display_lines,omitempty"` // Lines of an expanded result shown in the chat (default 200)
+}
+
+// BudgetOptions caps what the agent may spend before it pauses and asks to
+// continue. Zero leaves a limit off.
+type BudgetOptions struct {
+	SessionCost   float64 `json:"session_cost,omitempty"`   // Dollars per session
+	SessionTokens int64   `json:"session_tokens,omitempty"` // Input and output tokens per session
+	DailyCost     float64 `json:"daily_cost,omitempty"`     // Dollars per day, across sessions
+	DailyTokens   int64   `json:"daily_tokens,omitempty"`   // Input and output tokens per day, across sessions
+}
+
+// IndexOptions configures the embedding index of project files searched by
+// the search_codebase tool.
+type IndexOptions struct {
+	Enabled  bool   `json:"enabled,omitempty"`
+	Provider string `json:"provider,omitempty"` // "local" (default) or "openai" for any OpenAI-compatible embeddings API
+	Model    string `json:"model,omitempty"`    // Embedding model (default text-embedding-3-small)
+	BaseURL  string `json:"base_url,omitempty"` // Embeddings API base URL (default https://api.openai.com/v1)
+	APIKey   string `json:"api_key,omitempty"`  // Embeddings API key; may reference an environment variable
+}
+
+// NotificationOptions configures how a finished reply is announced while the
+// terminal is in the background.
+type NotificationOptions struct {
+	MinSeconds int  `json:"min_seconds,omitempty"` // Shortest run worth announcing (default 10)
+	Desktop    bool `json:"desktop,omitempty"`     // Also show a desktop notification
+	Disabled   bool `json:"disabled,omitempty"`    // No bell or notification
+}
+
+// BackupOptions configures the copies of the session database taken on
+// startup.
+type BackupOptions struct {
+	Keep          int  `json:"keep,omitempty"`           // Copies to retain (default 5)
+	IntervalHours int  `json:"interval_hours,omitempty"` // Minimum hours between copies (default 24)
+	Disabled      bool `json:"disabled,omitempty"`
+}
+
+// MetricsOptions configures the local Prometheus metrics endpoint.
+type MetricsOptions struct {
+	Listen string `json:"listen,omitempty"` // Address to serve /metrics on, e.g. 127.0.0.1:9464
+}
+
+// TelemetryOptions configures OpenTelemetry trace export. Tracing is off
+// unless an endpoint is set.
+//
+//nolint:govet // Field order is intentional for JSON readability.
+type TelemetryOptions struct {
+	Endpoint    string            `json:"endpoint,omitempty"`     // OTLP/HTTP collector URL, e.g. http://localhost:4318
+	Headers     map[string]string `json:"headers,omitempty"`      // Sent with every export, e.g. an API key
+	ServiceName string            `json:"service_name,omitempty"` // Reported service name (default "cdd")
 }
 
 // NewConfig creates a new Config with initialized maps.
@@ -118,6 +383,12 @@ func (c *Config) KnownProviders() []catwalk.Provider {
 	return c.knownProviders
 }
 
+// ProvidersWarning returns why the known providers came from a stale cache
+// or the embedded list, or "" when they are current.
+func (c *Config) ProvidersWarning() string {
+	return c.providersWarning
+}
+
 // SetKnownProviders sets the list of known providers.
 func (c *Config) SetKnownProviders(providers []catwalk.Provider) {
 	c.knownProviders = providers
@@ -142,10 +413,13 @@ func (c *Config) RefreshOAuthToken(ctx context.Context, providerID string) error
 	// Persist tokens to disk IMMEDIATELY before updating in-memory state.
 	// This is critical because Anthropic uses token rotation - the old refresh token
 	// is invalidated as soon as we receive the new one.
-	if err := c.SetConfigField(fmt.Sprintf("providers.%s.oauth", providerID), newToken); err != nil {
+	prefix := credentialPrefix("providers", providerID)
+	storedToken, _ := protectToken(secretKey(prefix, "oauth"), newToken)
+	storedKey, _ := protectSecret(secretKey(prefix, "api_key"), newToken.AccessToken)
+	if err := c.SetConfigField(fmt.Sprintf("providers.%s.oauth", providerID), storedToken); err != nil {
 		return fmt.Errorf("persisting refreshed oauth token: %w", err)
 	}
-	if err := c.SetConfigField(fmt.Sprintf("providers.%s.api_key", providerID), newToken.AccessToken); err != nil {
+	if err := c.SetConfigField(fmt.Sprintf("providers.%s.api_key", providerID), storedKey); err != nil {
 		return fmt.Errorf("persisting refreshed api_key: %w", err)
 	}
 
@@ -174,11 +448,17 @@ func (c *Config) RefreshConnectionOAuthToken(ctx context.Context, connectionInde
 	// Persist tokens to disk IMMEDIATELY before updating in-memory state.
 	// This is critical because Anthropic uses token rotation - the old refresh token
 	// is invalidated as soon as we receive the new one.
-	if err := c.SetConfigField(fmt.Sprintf("connections.%d.oauth", connectionIndex), newToken); err != nil {
+	storedToken, storedKey := newToken, "Bearer "+newToken.AccessToken
+	if conn.ID != "" {
+		prefix := credentialPrefix("connections", conn.ID)
+		storedToken, _ = protectToken(secretKey(prefix, "oauth"), newToken)
+		storedKey, _ = protectSecret(secretKey(prefix, "api_key"), storedKey)
+	}
+	if err := c.SetConfigField(fmt.Sprintf("connections.%d.oauth", connectionIndex), storedToken); err != nil {
 		return fmt.Errorf("persisting refreshed oauth token: %w", err)
 	}
 	// Also update api_key field for Bearer token format.
-	if err := c.SetConfigField(fmt.Sprintf("connections.%d.api_key", connectionIndex), "Bearer "+newToken.AccessToken); err != nil {
+	if err := c.SetConfigField(fmt.Sprintf("connections.%d.api_key", connectionIndex), storedKey); err != nil {
 		return fmt.Errorf("persisting refreshed api_key: %w", err)
 	}
 
@@ -208,6 +488,7 @@ func (c *Config) SetConfigField(key string, value any) error {
 		return fmt.Errorf("setting config field %q: %w", key, err)
 	}
 
+	recordWrite(configPath, []byte(newData))
 	//nolint:gosec // 0o600 is intentionally restrictive for security.
 	if err := os.WriteFile(configPath, []byte(newData), 0o600); err != nil {
 		return fmt.Errorf("writing config file: %w", err)
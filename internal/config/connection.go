@@ -3,8 +3,11 @@ package config
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
 	"github.com/google/uuid"
 
 	"github.com/guilhermegouw/cdd/internal/oauth"
@@ -14,15 +17,18 @@ import (
 // Users can have multiple connections to the same provider type
 // (e.g., "Work Claude" and "Personal Claude" both using Anthropic).
 type Connection struct {
-	ID           string            `json:"id"`
-	Name         string            `json:"name"`
-	ProviderID   string            `json:"provider_id"`
-	APIKey       string            `json:"api_key,omitempty"`
-	OAuthToken   *oauth.Token      `json:"oauth,omitempty"`
-	BaseURL      string            `json:"base_url,omitempty"`
-	ExtraHeaders map[string]string `json:"extra_headers,omitempty"`
-	CreatedAt    time.Time         `json:"created_at"`
-	UpdatedAt    time.Time         `json:"updated_at"`
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	ProviderID      string            `json:"provider_id"`
+	APIKey          string            `json:"api_key,omitempty"`
+	OAuthToken      *oauth.Token      `json:"oauth,omitempty"`
+	BaseURL         string            `json:"base_url,omitempty"`
+	ExtraHeaders    map[string]string `json:"extra_headers,omitempty"`
+	ExtraQuery      map[string]string `json:"extra_query,omitempty"`      // Query parameters added to every request
+	ProviderOptions map[string]any    `json:"provider_options,omitempty"` // Overrides the provider's options
+	HTTP            *HTTPOptions      `json:"http,omitempty"`             // Replaces the provider's HTTP settings
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
 }
 
 // IsConfigured returns true if the connection has authentication configured.
@@ -35,6 +41,24 @@ func (c *Connection) IsOAuth() bool {
 	return c.OAuthToken != nil
 }
 
+// ConnectionModels returns the models a connection can use: those configured
+// for its provider, or else the provider's known models.
+func (c *Config) ConnectionModels(conn *Connection) []catwalk.Model {
+	// First try provider config (may have user-configured models).
+	if provider, ok := c.Providers[conn.ProviderID]; ok && len(provider.Models) > 0 {
+		return provider.Models
+	}
+
+	// Fall back to known providers from catwalk.
+	known := c.KnownProviders()
+	for i := range known {
+		if string(known[i].ID) == conn.ProviderID {
+			return known[i].Models
+		}
+	}
+	return nil
+}
+
 // ConnectionManager provides CRUD operations for connections.
 type ConnectionManager struct {
 	cfg *Config
@@ -149,6 +173,71 @@ func (m *ConnectionManager) Delete(id string) error {
 	return fmt.Errorf("connection %q not found", id)
 }
 
+// Duplicate adds a copy of connection id under a new name, with its headers,
+// query parameters and settings. A non-empty apiKey replaces the copied key,
+// e.g. for a per-project key at the same endpoint. OAuth sign-ins are not
+// copied, since both connections refreshing one token would log each other
+// out, so duplicating an OAuth connection needs an API key.
+func (m *ConnectionManager) Duplicate(id, name, apiKey string) (*Connection, error) {
+	src := m.Get(id)
+	if src == nil {
+		return nil, fmt.Errorf("connection %q not found", id)
+	}
+	if src.IsOAuth() && apiKey == "" {
+		return nil, fmt.Errorf("connection %q signs in with OAuth; give the copy an API key", src.Name)
+	}
+
+	conn := *src
+	conn.ID = ""
+	conn.Name = name
+	conn.OAuthToken = nil
+	if apiKey != "" {
+		conn.APIKey = apiKey
+	}
+	conn.ExtraHeaders = maps.Clone(src.ExtraHeaders)
+	conn.ExtraQuery = maps.Clone(src.ExtraQuery)
+	conn.ProviderOptions = maps.Clone(src.ProviderOptions)
+	if src.HTTP != nil {
+		http := *src.HTTP
+		conn.HTTP = &http
+	}
+	if err := m.Add(conn); err != nil {
+		return nil, err
+	}
+	return &m.cfg.Connections[len(m.cfg.Connections)-1], nil
+}
+
+// Unused returns the connections that no selected model uses.
+func (m *ConnectionManager) Unused() []Connection {
+	used := make(map[string]bool)
+	for _, tier := range selectedTiers {
+		if conn := m.GetActiveConnection(tier); conn != nil {
+			used[conn.ID] = true
+		}
+	}
+	var unused []Connection
+	for i := range m.cfg.Connections {
+		if !used[m.cfg.Connections[i].ID] {
+			unused = append(unused, m.cfg.Connections[i])
+		}
+	}
+	return unused
+}
+
+// DeleteMany removes several connections by ID and saves once. Nothing is
+// removed if any of them is missing.
+func (m *ConnectionManager) DeleteMany(ids []string) error {
+	for _, id := range ids {
+		if m.Get(id) == nil {
+			return fmt.Errorf("connection %q not found", id)
+		}
+	}
+	m.cfg.Connections = slices.DeleteFunc(m.cfg.Connections, func(conn Connection) bool {
+		return slices.Contains(ids, conn.ID)
+	})
+	return Save(m.cfg)
+}
+
 // GetActiveConnection returns the connection for the given model tier.
 // It first tries to use ConnectionID, then falls back to Provider for backward compatibility.
 func (m *ConnectionManager) GetActiveConnection(tier SelectedModelType) *Connection {
@@ -390,3 +390,70 @@ func TestMigrateToConnections_WithOAuth(t *testing.T) {
 		t.Errorf("Expected access token 'access-token', got '%s'", conn.OAuthToken.AccessToken)
 	}
 }
+
+func TestConnectionManager_Duplicate(t *testing.T) {
+	useTempProfiles(t)
+	cfg := NewConfig()
+	cfg.Connections = []Connection{
+		{ID: "c1", Name: "Gateway", ProviderID: "gw", APIKey: "key-a", ExtraHeaders: map[string]string{"X-Team": "core"}},
+		{ID: "c2", Name: "Claude", ProviderID: "anthropic", OAuthToken: &oauth.Token{AccessToken: "t"}},
+	}
+	manager := NewConnectionManager(cfg)
+
+	dup, err := manager.Duplicate("c1", "Gateway (project b)", "key-b")
+	if err != nil {
+		t.Fatalf("Duplicate() error = %v", err)
+	}
+	if dup.ID == "c1" || dup.ProviderID != "gw" || dup.APIKey != "key-b" || dup.ExtraHeaders["X-Team"] != "core" {
+		t.Errorf("duplicate = %+v, want a new connection to gw with the new key and the headers", dup)
+	}
+	dup.ExtraHeaders["X-Team"] = "other"
+	if manager.Get("c1").ExtraHeaders["X-Team"] != "core" {
+		t.Error("changing the duplicate's headers changed the original's")
+	}
+
+	if _, err := manager.Duplicate("c1", "Gateway", ""); err == nil {
+		t.Error("Duplicate() accepted a name already in use")
+	}
+	if _, err := manager.Duplicate("c2", "Claude 2", ""); err == nil {
+		t.Error("Duplicate() of an OAuth connection without a key should fail")
+	}
+	if dup, err := manager.Duplicate("c2", "Claude API", "sk-ant"); err != nil || dup.OAuthToken != nil {
+		t.Errorf("Duplicate() = %+v, %v, want an API key connection without the OAuth token", dup, err)
+	}
+}
+
+func TestConnectionManager_UnusedDeleteMany(t *testing.T) {
+	useTempProfiles(t)
+	cfg := NewConfig()
+	cfg.Connections = []Connection{
+		{ID: "c1", Name: "Large", ProviderID: "openai"},
+		{ID: "c2", Name: "Small", ProviderID: "anthropic"},
+		{ID: "c3", Name: "Spare", ProviderID: "openai"},
+		{ID: "c4", Name: "Old key", ProviderID: "gw"},
+	}
+	cfg.Models[SelectedModelTypeLarge] = SelectedModel{ConnectionID: "c1", Model: "gpt-4o"}
+	cfg.Models[SelectedModelTypeSmall] = SelectedModel{Provider: "anthropic", Model: "claude-haiku"}
+	manager := NewConnectionManager(cfg)
+
+	var ids []string
+	for _, conn := range manager.Unused() {
+		ids = append(ids, conn.ID)
+	}
+	if len(ids) != 2 || ids[0] != "c3" || ids[1] != "c4" {
+		t.Fatalf("Unused() = %v, want [c3 c4]", ids)
+	}
+
+	if err := manager.DeleteMany([]string{"c3", "missing"}); err == nil {
+		t.Error("DeleteMany() with a missing ID should fail")
+	}
+	if len(manager.List()) != 4 {
+		t.Error("a failed DeleteMany() should remove nothing")
+	}
+	if err := manager.DeleteMany(ids); err != nil {
+		t.Fatalf("DeleteMany() error = %v", err)
+	}
+	if len(manager.List()) != 2 || len(manager.Unused()) != 0 {
+		t.Errorf("connections = %v, want only the used ones", manager.List())
+	}
+}
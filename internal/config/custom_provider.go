@@ -2,13 +2,17 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/adrg/xdg"
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
 )
 
@@ -69,7 +73,7 @@ type CustomProviderManager struct {
 // NewCustomProviderManager creates a new CustomProviderManager.
 func NewCustomProviderManager(dataDir string) *CustomProviderManager {
 	if dataDir == "" {
-		dataDir = filepath.Join(xdg.DataHome, appName)
+		dataDir = activeDataDir()
 	}
 	return &CustomProviderManager{
 		filePath: filepath.Join(dataDir, customProvidersFile),
@@ -111,6 +115,7 @@ func (m *CustomProviderManager) Save(providers []CustomProvider) error {
 		return fmt.Errorf("marshaling custom providers: %w", err)
 	}
 
+	recordWrite(m.filePath, data)
 	//nolint:gosec // 0o600 is intentionally restrictive for security.
 	if err := os.WriteFile(m.filePath, data, 0o600); err != nil {
 		return fmt.Errorf("writing custom providers file: %w", err)
@@ -231,3 +236,90 @@ func (m *CustomProviderManager) Exists(providerID string) bool {
 func (m *CustomProviderManager) GetFilePath() string {
 	return m.filePath
 }
+
+// customProvidersTimeout bounds fetching a providers file from a URL.
+const customProvidersTimeout = 30 * time.Second
+
+// maxCustomProvidersSize is the largest providers file read.
+const maxCustomProvidersSize = 10 << 20
+
+// ReadCustomProviders reads a providers file, as written by
+// CustomProviderManager, from an http(s) URL or a local path.
+func ReadCustomProviders(ctx context.Context, source string) (*CustomProvidersFile, error) {
+	var data []byte
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, http.NoBody)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+		client := &http.Client{Timeout: customProvidersTimeout}
+		resp, err := client.Do(req) //nolint:gosec // G107: The user chose the URL.
+		if err != nil {
+			return nil, fmt.Errorf("fetching URL: %w", err)
+		}
+		defer resp.Body.Close() //nolint:errcheck // Error on close is not actionable.
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		}
+		data, err = io.ReadAll(io.LimitReader(resp.Body, maxCustomProvidersSize))
+		if err != nil {
+			return nil, fmt.Errorf("reading response: %w", err)
+		}
+	} else {
+		var err error
+		data, err = os.ReadFile(source) //nolint:gosec // G304: The user chose the file.
+		if err != nil {
+			return nil, fmt.Errorf("reading file: %w", err)
+		}
+	}
+
+	var file CustomProvidersFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing JSON: %w", err)
+	}
+	if len(file.Providers) == 0 {
+		return nil, fmt.Errorf("no providers found in %s", source)
+	}
+	return &file, nil
+}
+
+// ParseModelSpec parses a model given on the command line as
+// id[:name[:context_window[:max_tokens]]]. Empty or missing fields are left
+// zero, and the name defaults to the ID. Model IDs may themselves contain
+// colons (qwen2.5:7b), so a spec with more than four fields takes the last
+// three as name, context window and max tokens and the rest as the ID; such
+// a model is given as qwen2.5:7b::: or in full.
+func ParseModelSpec(spec string) (catwalk.Model, error) {
+	fields := strings.Split(spec, ":")
+	if n := len(fields); n > 4 { //nolint:mnd // id, name, context and max tokens
+		fields = append([]string{strings.Join(fields[:n-3], ":")}, fields[n-3:]...)
+	}
+	fields = append(fields, "", "", "")
+
+	m := catwalk.Model{ID: strings.TrimSpace(fields[0]), Name: strings.TrimSpace(fields[1])}
+	if m.ID == "" {
+		return catwalk.Model{}, fmt.Errorf("invalid model %q: ID is empty", spec)
+	}
+	if m.Name == "" {
+		m.Name = m.ID
+	}
+	for _, limit := range []struct {
+		field string
+		value *int64
+		text  string
+	}{
+		{"context window", &m.ContextWindow, fields[2]},
+		{"max tokens", &m.DefaultMaxTokens, fields[3]},
+	} {
+		text := strings.TrimSpace(limit.text)
+		if text == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil || n <= 0 {
+			return catwalk.Model{}, fmt.Errorf("invalid model %q: %s %q is not a positive number", spec, limit.field, text)
+		}
+		*limit.value = n
+	}
+	return m, nil
+}
@@ -2,8 +2,12 @@
 package config
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -369,3 +373,74 @@ func TestCustomProviderToCatwalkProvider_BaseURLFallback(t *testing.T) {
 		t.Errorf("APIEndpoint = %q, want %q (from BaseURL)", catwalkProvider.APIEndpoint, "https://api.example.com/v1")
 	}
 }
+
+func TestReadCustomProviders(t *testing.T) {
+	const body = `{"version":"1.0","providers":[{"id":"gw","name":"Gateway","type":"openai-compat","models":[{"id":"m"}]}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/providers.json":
+			_, _ = w.Write([]byte(body)) //nolint:errcheck // Test server response.
+		case "/empty.json":
+			_, _ = w.Write([]byte(`{"providers":[]}`)) //nolint:errcheck // Test server response.
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "providers.json")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, source := range []string{server.URL + "/providers.json", path} {
+		file, err := ReadCustomProviders(context.Background(), source)
+		if err != nil {
+			t.Fatalf("ReadCustomProviders(%q) error = %v", source, err)
+		}
+		if len(file.Providers) != 1 || file.Providers[0].ID != "gw" {
+			t.Errorf("ReadCustomProviders(%q) = %+v, want the gateway", source, file.Providers)
+		}
+	}
+
+	for source, want := range map[string]string{
+		server.URL + "/missing.json":       "HTTP 404",
+		server.URL + "/empty.json":         "no providers found",
+		filepath.Join(t.TempDir(), "none"): "reading file",
+	} {
+		if _, err := ReadCustomProviders(context.Background(), source); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ReadCustomProviders(%q) error = %v, want %q", source, err, want)
+		}
+	}
+}
+
+func TestParseModelSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want catwalk.Model
+	}{
+		{"llama3", catwalk.Model{ID: "llama3", Name: "llama3"}},
+		{"llama3:Llama 3", catwalk.Model{ID: "llama3", Name: "Llama 3"}},
+		{"llama3:Llama 3:128000:4096", catwalk.Model{ID: "llama3", Name: "Llama 3", ContextWindow: 128000, DefaultMaxTokens: 4096}},
+		{"llama3::8192", catwalk.Model{ID: "llama3", Name: "llama3", ContextWindow: 8192}},
+		{"qwen2.5:7b:Qwen 7B:32768:8192", catwalk.Model{ID: "qwen2.5:7b", Name: "Qwen 7B", ContextWindow: 32768, DefaultMaxTokens: 8192}},
+		{"qwen2.5:7b:::", catwalk.Model{ID: "qwen2.5:7b", Name: "qwen2.5:7b"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseModelSpec(tt.spec)
+		if err != nil {
+			t.Errorf("ParseModelSpec(%q) error = %v", tt.spec, err)
+			continue
+		}
+		if got.ID != tt.want.ID || got.Name != tt.want.Name ||
+			got.ContextWindow != tt.want.ContextWindow || got.DefaultMaxTokens != tt.want.DefaultMaxTokens {
+			t.Errorf("ParseModelSpec(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"", ":Name", "m:M:big", "m:M:1000:-1"} {
+		if _, err := ParseModelSpec(spec); err == nil {
+			t.Errorf("ParseModelSpec(%q) should fail", spec)
+		}
+	}
+}
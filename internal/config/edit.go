@@ -0,0 +1,169 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// GetValue returns the value at key in the config file at path. Keys are
+// dotted paths such as models.large.model; list items are addressed by index,
+// as in connections.0.id.
+func GetValue(path, key string) (any, error) {
+	doc, err := readDocument(path)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := splitKey(key)
+	value := any(doc)
+	for i, part := range parts {
+		next, ok := child(value, part)
+		if !ok {
+			return nil, fmt.Errorf("%s is not set", strings.Join(parts[:i+1], "."))
+		}
+		value = next
+	}
+	return value, nil
+}
+
+// SetValue sets key in the config file at path, creating the file and any
+// missing objects along the way. The value is parsed as JSON when it is valid
+// JSON and taken as a string otherwise, so true, 3 and "3" keep their types.
+// The result is checked with Validate before the file is replaced.
+func SetValue(path, key, value string) error {
+	doc, err := readDocument(path)
+	if errors.Is(err, os.ErrNotExist) {
+		doc = map[string]any{}
+	} else if err != nil {
+		return err
+	}
+
+	parts := splitKey(key)
+	if len(parts) == 0 {
+		return errors.New("empty key")
+	}
+
+	container := any(doc)
+	for i, part := range parts[:len(parts)-1] {
+		next, ok := child(container, part)
+		if !ok || next == nil {
+			obj, isObj := container.(map[string]any)
+			if !isObj {
+				return fmt.Errorf("%s is not set", strings.Join(parts[:i+1], "."))
+			}
+			next = map[string]any{}
+			obj[part] = next
+		}
+		container = next
+	}
+
+	last := parts[len(parts)-1]
+	switch c := container.(type) {
+	case map[string]any:
+		c[last] = parseValue(value)
+	case []any:
+		index, err := strconv.Atoi(last)
+		if err != nil || index < 0 || index >= len(c) {
+			return fmt.Errorf("%s: no item %s in list of %d", key, last, len(c))
+		}
+		c[index] = parseValue(value)
+	default:
+		return fmt.Errorf("%s: %s is not an object", key, strings.Join(parts[:len(parts)-1], "."))
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling config: %w", err)
+	}
+	if problems := Validate(data); len(problems) > 0 {
+		return &SchemaError{File: path, Problems: problems}
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
+
+// readDocument decodes a config file without mapping it onto Config, so
+// that values round-trip untouched.
+func readDocument(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: Path is a config file chosen by the user
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+	return doc, nil
+}
+
+func splitKey(key string) []string {
+	if key == "" {
+		return nil
+	}
+	return strings.Split(key, ".")
+}
+
+// child returns the member of an object or the item of a list named by part.
+func child(value any, part string) (any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		next, ok := v[part]
+		return next, ok
+	case []any:
+		index, err := strconv.Atoi(part)
+		if err != nil || index < 0 || index >= len(v) {
+			return nil, false
+		}
+		return v[index], true
+	default:
+		return nil, false
+	}
+}
+
+func parseValue(value string) any {
+	dec := json.NewDecoder(strings.NewReader(value))
+	dec.UseNumber()
+	var parsed any
+	if err := dec.Decode(&parsed); err != nil || dec.More() {
+		return value
+	}
+	return parsed
+}
+
+// writeFileAtomic replaces path with data by renaming a temporary file over
+// it, so readers never see a half-written config.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("creating temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // Gone after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck,gosec // Already failing
+		return fmt.Errorf("writing config file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+	recordWrite(path, data)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replacing config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSetValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cdd.json")
+
+	steps := []struct{ key, value string }{
+		{"options.debug", "true"},
+		{"options.max_attempts", "5"},
+		{"models.large", `{"model": "gpt-4o", "provider": "openai"}`},
+		{"models.large.model", "gpt-4.1"},
+		{"lsp.gopls", `{"command": "gopls", "filetypes": ["go", "mod"]}`},
+		{"lsp.gopls.filetypes", `["go"]`},
+	}
+	for _, s := range steps {
+		if err := SetValue(path, s.key, s.value); err != nil {
+			t.Fatalf("SetValue(%q, %q) error = %v", s.key, s.value, err)
+		}
+	}
+
+	cfg := NewConfig()
+	if err := loadFile(path, cfg); err != nil {
+		t.Fatalf("loadFile() error = %v", err)
+	}
+	if !cfg.Options.Debug || cfg.Options.MaxAttempts != 5 {
+		t.Errorf("Options = %+v, want debug and 5 attempts", cfg.Options)
+	}
+	if got := cfg.Models[SelectedModelTypeLarge]; got.Model != "gpt-4.1" || got.Provider != "openai" {
+		t.Errorf("Models[large] = %+v, want gpt-4.1 from openai", got)
+	}
+	if got := cfg.LSP["gopls"]; got.Command != "gopls" || !reflect.DeepEqual(got.FileTypes, []string{"go"}) {
+		t.Errorf("LSP[gopls] = %+v", got)
+	}
+}
+
+func TestSetValue_RejectsInvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cdd.json")
+	original := []byte(`{"options": {"max_attempts": 3}}`)
+	if err := os.WriteFile(path, original, 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	err := SetValue(path, "options.max_attempts", "many")
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("SetValue() error = %v, want *SchemaError", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if string(data) != string(original) {
+		t.Errorf("config changed to %s", data)
+	}
+}
+
+func TestGetValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cdd.json")
+	content := `{
+		"models": {"large": {"model": "gpt-4o", "provider": "openai"}},
+		"connections": [{"id": "c1", "provider_id": "openai"}],
+		"options": {"max_attempts": 3}
+	}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	tests := []struct {
+		key     string
+		want    any
+		wantErr string
+	}{
+		{key: "models.large.model", want: "gpt-4o"},
+		{key: "connections.0.id", want: "c1"},
+		{key: "options.max_attempts", want: json.Number("3")},
+		{key: "options.debug", wantErr: "options.debug is not set"},
+		{key: "connections.1.id", wantErr: "connections.1 is not set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := GetValue(path, tt.key)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("GetValue() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetValue() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"go.yaml.in/yaml/v3"
+)
+
+// liteLLMEntry is a model of a LiteLLM proxy, as listed under model_list in
+// its config.yaml and under data in its /model/info response.
+type liteLLMEntry struct {
+	ModelName     string `json:"model_name" yaml:"model_name"`
+	LiteLLMParams struct {
+		Model string `json:"model" yaml:"model"`
+	} `json:"litellm_params" yaml:"litellm_params"`
+	ModelInfo liteLLMModelInfo `json:"model_info" yaml:"model_info"`
+}
+
+// liteLLMModelInfo holds the model_info fields cdd uses. Costs are per token.
+type liteLLMModelInfo struct {
+	MaxTokens                   int64   `json:"max_tokens" yaml:"max_tokens"`
+	MaxInputTokens              int64   `json:"max_input_tokens" yaml:"max_input_tokens"`
+	MaxOutputTokens             int64   `json:"max_output_tokens" yaml:"max_output_tokens"`
+	InputCostPerToken           float64 `json:"input_cost_per_token" yaml:"input_cost_per_token"`
+	OutputCostPerToken          float64 `json:"output_cost_per_token" yaml:"output_cost_per_token"`
+	CacheReadInputTokenCost     float64 `json:"cache_read_input_token_cost" yaml:"cache_read_input_token_cost"`
+	CacheCreationInputTokenCost float64 `json:"cache_creation_input_token_cost" yaml:"cache_creation_input_token_cost"`
+	SupportsVision              bool    `json:"supports_vision" yaml:"supports_vision"`
+	SupportsReasoning           bool    `json:"supports_reasoning" yaml:"supports_reasoning"`
+}
+
+// ParseLiteLLMConfig returns the models of a LiteLLM proxy config.yaml.
+func ParseLiteLLMConfig(data []byte) ([]catwalk.Model, error) {
+	var file struct {
+		ModelList []liteLLMEntry `yaml:"model_list"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing LiteLLM config: %w", err)
+	}
+	return liteLLMModels(file.ModelList)
+}
+
+// ParseLiteLLMModelInfo returns the models of a LiteLLM proxy's /model/info
+// response.
+func ParseLiteLLMModelInfo(data []byte) ([]catwalk.Model, error) {
+	var resp struct {
+		Data []liteLLMEntry `json:"data"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("parsing LiteLLM model info: %w", err)
+	}
+	return liteLLMModels(resp.Data)
+}
+
+// LiteLLMProvider creates an OpenAI-compatible provider for the LiteLLM
+// proxy at baseURL serving models. The first model is the default for both
+// tiers.
+func LiteLLMProvider(id, name, baseURL string, models []catwalk.Model) CustomProvider {
+	p := CustomProvider{
+		Name:        name,
+		ID:          id,
+		Type:        catwalk.TypeOpenAICompat,
+		APIEndpoint: strings.TrimSuffix(baseURL, "/") + "/v1",
+		Models:      models,
+	}
+	if len(models) > 0 {
+		p.DefaultLargeModelID = models[0].ID
+		p.DefaultSmallModelID = models[0].ID
+	}
+	return p
+}
+
+// liteLLMModels converts entries to models. Clients ask the proxy for a
+// model by its model_name, so entries sharing one (load-balanced
+// deployments) become a single model, and wildcard routes are skipped.
+func liteLLMModels(entries []liteLLMEntry) ([]catwalk.Model, error) {
+	seen := make(map[string]bool, len(entries))
+	models := make([]catwalk.Model, 0, len(entries))
+	for i := range entries {
+		e := &entries[i]
+		if e.ModelName == "" || strings.Contains(e.ModelName, "*") || seen[e.ModelName] {
+			continue
+		}
+		seen[e.ModelName] = true
+		models = append(models, e.toModel())
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("no models found")
+	}
+	return models, nil
+}
+
+// toModel converts an entry, leaving unknown limits and costs zero.
+func (e *liteLLMEntry) toModel() catwalk.Model {
+	info := e.ModelInfo
+	m := catwalk.Model{
+		ID:               e.ModelName,
+		Name:             e.ModelName,
+		ContextWindow:    firstNonZero(info.MaxInputTokens, info.MaxTokens),
+		DefaultMaxTokens: firstNonZero(info.MaxOutputTokens, info.MaxTokens),
+		CostPer1MIn:      perMillion(info.InputCostPerToken),
+		CostPer1MOut:     perMillion(info.OutputCostPerToken),
+		// catwalk prices cache writes as "in cached" and reads as "out cached".
+		CostPer1MInCached:  perMillion(info.CacheCreationInputTokenCost),
+		CostPer1MOutCached: perMillion(info.CacheReadInputTokenCost),
+		SupportsImages:     info.SupportsVision,
+		CanReason:          info.SupportsReasoning,
+	}
+	if e.LiteLLMParams.Model != "" && e.LiteLLMParams.Model != e.ModelName {
+		m.Name = fmt.Sprintf("%s (%s)", e.ModelName, e.LiteLLMParams.Model)
+	}
+	return m
+}
+
+func firstNonZero(values ...int64) int64 {
+	for _, v := range values {
+		if v != 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+func perMillion(costPerToken float64) float64 {
+	return costPerToken * 1_000_000
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+func TestParseLiteLLMConfig(t *testing.T) {
+	data := []byte(`
+model_list:
+  - model_name: claude-sonnet
+    litellm_params:
+      model: anthropic/claude-sonnet-4-5
+      api_key: os.environ/ANTHROPIC_API_KEY
+    model_info:
+      max_input_tokens: 200000
+      max_output_tokens: 64000
+      input_cost_per_token: 0.000003
+      output_cost_per_token: 0.000015
+      supports_vision: true
+  - model_name: claude-sonnet
+    litellm_params:
+      model: bedrock/anthropic.claude-sonnet-4-5
+  - model_name: gpt-4o-mini
+    litellm_params:
+      model: gpt-4o-mini
+  - model_name: "*"
+    litellm_params:
+      model: "*"
+litellm_settings:
+  drop_params: true
+`)
+
+	models, err := ParseLiteLLMConfig(data)
+	if err != nil {
+		t.Fatalf("ParseLiteLLMConfig() error = %v", err)
+	}
+	if len(models) != 2 {
+		t.Fatalf("got %d models, want 2 (duplicates and wildcards skipped): %+v", len(models), models)
+	}
+
+	sonnet := models[0]
+	if sonnet.ID != "claude-sonnet" || sonnet.Name != "claude-sonnet (anthropic/claude-sonnet-4-5)" {
+		t.Errorf("model = %q (%q), want claude-sonnet named after its first deployment", sonnet.ID, sonnet.Name)
+	}
+	if sonnet.ContextWindow != 200000 || sonnet.DefaultMaxTokens != 64000 {
+		t.Errorf("limits = %d/%d, want 200000/64000", sonnet.ContextWindow, sonnet.DefaultMaxTokens)
+	}
+	if sonnet.CostPer1MIn != 3 || sonnet.CostPer1MOut != 15 {
+		t.Errorf("costs = %v/%v, want 3/15 per million tokens", sonnet.CostPer1MIn, sonnet.CostPer1MOut)
+	}
+	if !sonnet.SupportsImages {
+		t.Error("supports_vision should allow attachments")
+	}
+	if models[1].Name != "gpt-4o-mini" {
+		t.Errorf("name = %q, want the model name alone when it matches the upstream model", models[1].Name)
+	}
+}
+
+func TestParseLiteLLMModelInfo(t *testing.T) {
+	data := []byte(`{"data": [
+		{"model_name": "gpt-4o", "litellm_params": {"model": "openai/gpt-4o"},
+		 "model_info": {"max_tokens": 16384, "max_input_tokens": null, "cache_read_input_token_cost": 0.00000125}}
+	]}`)
+
+	models, err := ParseLiteLLMModelInfo(data)
+	if err != nil {
+		t.Fatalf("ParseLiteLLMModelInfo() error = %v", err)
+	}
+	if len(models) != 1 {
+		t.Fatalf("got %d models, want 1", len(models))
+	}
+	if models[0].ContextWindow != 16384 || models[0].DefaultMaxTokens != 16384 {
+		t.Errorf("limits = %d/%d, want max_tokens for both", models[0].ContextWindow, models[0].DefaultMaxTokens)
+	}
+	if models[0].CostPer1MOutCached != 1.25 {
+		t.Errorf("cache read cost = %v, want 1.25", models[0].CostPer1MOutCached)
+	}
+}
+
+func TestParseLiteLLM_Errors(t *testing.T) {
+	if _, err := ParseLiteLLMConfig([]byte("general_settings: {}\n")); err == nil {
+		t.Error("a config without models should fail")
+	}
+	if _, err := ParseLiteLLMConfig([]byte("model_list: [")); err == nil {
+		t.Error("invalid YAML should fail")
+	}
+	if _, err := ParseLiteLLMModelInfo([]byte("<html>")); err == nil {
+		t.Error("a response that is not JSON should fail")
+	}
+}
+
+func TestLiteLLMProvider(t *testing.T) {
+	models := []catwalk.Model{{ID: "claude-sonnet"}, {ID: "gpt-4o-mini"}}
+	p := LiteLLMProvider("litellm", "LiteLLM", "http://localhost:4000/", models)
+
+	if p.Type != catwalk.TypeOpenAICompat || p.APIEndpoint != "http://localhost:4000/v1" {
+		t.Errorf("provider = %s at %s, want openai-compat at http://localhost:4000/v1", p.Type, p.APIEndpoint)
+	}
+	if p.DefaultLargeModelID != "claude-sonnet" || p.DefaultSmallModelID != "claude-sonnet" {
+		t.Errorf("defaults = %s/%s, want the first model", p.DefaultLargeModelID, p.DefaultSmallModelID)
+	}
+	if result := ValidateCustomProvider(&p, nil); !result.IsValid {
+		t.Errorf("provider should be valid: %v", result.Errors)
+	}
+}
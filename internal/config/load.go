@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
-	"github.com/adrg/xdg"
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
 )
 
@@ -22,12 +24,26 @@ const (
 // It merges global config with project config (project takes precedence),
 // then configures providers using catwalk metadata and custom providers.
 func Load() (*Config, error) {
+	return load(true)
+}
+
+// LoadWithoutModels loads configuration like Load, but doesn't fail when no
+// provider has an API key yet. Commands that add providers use it, so a
+// fresh install can be provisioned before any model is usable.
+func LoadWithoutModels() (*Config, error) {
+	return load(false)
+}
+
+func load(requireModels bool) (*Config, error) {
 	cfg := NewConfig()
 	resolver := NewResolver()
-	globalPath := filepath.Join(xdg.ConfigHome, appName, configFileName)
+	globalPath := filepath.Join(activeConfigDir(), configFileName)
 	if err := loadFile(globalPath, cfg); err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("loading global config: %w", err)
 	}
+	if cfg.Options != nil && cfg.Options.SystemPromptFile != "" {
+		cfg.promptBaseDir = filepath.Dir(globalPath)
+	}
 
 	projectPath := findProjectConfig()
 	if projectPath != "" {
@@ -36,9 +52,13 @@ func Load() (*Config, error) {
 			return nil, fmt.Errorf("loading project config: %w", err)
 		}
 		mergeConfig(cfg, projectCfg)
+		if projectCfg.Options != nil && projectCfg.Options.SystemPromptFile != "" {
+			cfg.promptBaseDir = filepath.Dir(projectPath)
+		}
 	}
 
 	applyDefaults(cfg)
+	resolveSecrets(cfg)
 
 	// Migrate existing providers to connections (backward compatibility).
 	if err := MigrateToConnections(cfg); err != nil {
@@ -53,7 +73,7 @@ func Load() (*Config, error) {
 	}
 	cfg.SetKnownProviders(providers)
 	configureProviders(cfg, resolver)
-	if err := configureDefaultModels(cfg); err != nil {
+	if err := configureDefaultModels(cfg); err != nil && requireModels {
 		return nil, fmt.Errorf("configuring models: %w", err)
 	}
 
@@ -70,6 +90,7 @@ func LoadFromFile(path string) (*Config, error) {
 	}
 
 	applyDefaults(cfg)
+	resolveSecrets(cfg)
 
 	// Migrate existing providers to connections (backward compatibility).
 	if err := MigrateToConnections(cfg); err != nil {
@@ -99,6 +120,9 @@ func loadFile(path string, cfg *Config) error {
 	if err != nil {
 		return err
 	}
+	if problems := Validate(data); len(problems) > 0 {
+		return &SchemaError{File: path, Problems: problems}
+	}
 	return json.Unmarshal(data, cfg)
 }
 
@@ -157,6 +181,73 @@ func mergeConfig(dst, src *Config) {
 		}
 	}
 
+	// Project language servers override global ones by name.
+	if len(src.LSP) > 0 {
+		if dst.LSP == nil {
+			dst.LSP = make(map[string]LSPConfig, len(src.LSP))
+		}
+		for name := range src.LSP {
+			dst.LSP[name] = src.LSP[name]
+		}
+	}
+
+	// Project modes override global ones by name.
+	if len(src.Modes) > 0 {
+		if dst.Modes == nil {
+			dst.Modes = make(map[string]ModeConfig, len(src.Modes))
+		}
+		for name := range src.Modes {
+			dst.Modes[name] = src.Modes[name]
+		}
+	}
+
+	// Project hooks run after global ones, so both sets of policies apply.
+	if src.Hooks != nil {
+		if dst.Hooks == nil {
+			dst.Hooks = &HooksConfig{}
+		}
+		dst.Hooks.PreTool = append(dst.Hooks.PreTool, src.Hooks.PreTool...)
+		dst.Hooks.PostTool = append(dst.Hooks.PostTool, src.Hooks.PostTool...)
+		dst.Hooks.OnComplete = append(dst.Hooks.OnComplete, src.Hooks.OnComplete...)
+		dst.Hooks.OnSessionStart = append(dst.Hooks.OnSessionStart, src.Hooks.OnSessionStart...)
+	}
+
+	// A project can adjust the sandbox but not turn it off.
+	if src.Sandbox != nil {
+		if dst.Sandbox == nil {
+			dst.Sandbox = &SandboxConfig{}
+		}
+		dst.Sandbox.Enabled = dst.Sandbox.Enabled || src.Sandbox.Enabled
+		if len(src.Sandbox.AllowedPaths) > 0 {
+			dst.Sandbox.AllowedPaths = src.Sandbox.AllowedPaths
+		}
+		if len(src.Sandbox.AllowedCommands) > 0 {
+			dst.Sandbox.AllowedCommands = src.Sandbox.AllowedCommands
+		}
+	}
+
+	// Project integrations override global ones by service.
+	if src.Integrations != nil {
+		if dst.Integrations == nil {
+			dst.Integrations = &IntegrationsConfig{}
+		}
+		if src.Integrations.Share != "" {
+			dst.Integrations.Share = src.Integrations.Share
+		}
+		if src.Integrations.GitHub != nil {
+			dst.Integrations.GitHub = src.Integrations.GitHub
+		}
+		if src.Integrations.Paste != nil {
+			dst.Integrations.Paste = src.Integrations.Paste
+		}
+		if src.Integrations.Jira != nil {
+			dst.Integrations.Jira = src.Integrations.Jira
+		}
+		if src.Integrations.Linear != nil {
+			dst.Integrations.Linear = src.Integrations.Linear
+		}
+	}
+
 	if src.Options != nil {
 		if dst.Options == nil {
 			dst.Options = &Options{}
@@ -167,9 +258,69 @@ func mergeConfig(dst, src *Config) {
 		if src.Options.DataDir != "" {
 			dst.Options.DataDir = src.Options.DataDir
 		}
+		if src.Options.BashTimeout > 0 {
+			dst.Options.BashTimeout = src.Options.BashTimeout
+		}
+		if src.Options.MaxAttempts > 0 {
+			dst.Options.MaxAttempts = src.Options.MaxAttempts
+		}
+		if src.Options.MaxIterations != 0 {
+			dst.Options.MaxIterations = src.Options.MaxIterations
+		}
+		if src.Options.SystemPromptFile != "" {
+			dst.Options.SystemPromptFile = src.Options.SystemPromptFile
+		}
+		if src.Options.DefaultMode != "" {
+			dst.Options.DefaultMode = src.Options.DefaultMode
+		}
+		if src.Options.Notifications != nil {
+			dst.Options.Notifications = src.Options.Notifications
+		}
+		if src.Options.Index != nil {
+			dst.Options.Index = src.Options.Index
+		}
+		if src.Options.Budget != nil {
+			dst.Options.Budget = src.Options.Budget
+		}
+		if src.Options.StatusBar != nil {
+			dst.Options.StatusBar = src.Options.StatusBar
+		}
+		if src.Options.Markdown != nil {
+			dst.Options.Markdown = src.Options.Markdown
+		}
+		for action, keys := range src.Options.Keybindings {
+			if dst.Options.Keybindings == nil {
+				dst.Options.Keybindings = make(map[string][]string)
+			}
+			dst.Options.Keybindings[action] = keys
+		}
+		for alias, spec := range src.Options.ModelAliases {
+			if dst.Options.ModelAliases == nil {
+				dst.Options.ModelAliases = make(map[string]string)
+			}
+			dst.Options.ModelAliases[alias] = spec
+		}
 		if src.Options.Debug {
 			dst.Options.Debug = true
 		}
+		if src.Options.VimMode {
+			dst.Options.VimMode = true
+		}
+		if src.Options.ShowThinking {
+			dst.Options.ShowThinking = true
+		}
+		if src.Options.Accessible {
+			dst.Options.Accessible = true
+		}
+		if src.Options.Colors != "" {
+			dst.Options.Colors = src.Options.Colors
+		}
+		if src.Options.PlanFirst {
+			dst.Options.PlanFirst = true
+		}
+		if src.Options.Transcript {
+			dst.Options.Transcript = true
+		}
 	}
 }
 
@@ -190,6 +341,7 @@ func configureProviders(cfg *Config, resolver *Resolver) {
 			continue
 		}
 		configureProviderMetadata(userConfig, p)
+		configureProviderHeaders(userConfig, p, resolver)
 		configureProviderModels(userConfig, p)
 	}
 }
@@ -254,6 +406,9 @@ func configureProviderAuth(cfg *Config, userConfig *ProviderConfig, p *catwalk.P
 			delete(cfg.Providers, string(p.ID))
 			return false
 		}
+		if resolved != userConfig.APIKey {
+			userConfig.apiKeyRef, userConfig.apiKeyResolved = userConfig.APIKey, resolved
+		}
 		userConfig.APIKey = resolved
 	}
 	resolveBaseURL(userConfig, p, resolver)
@@ -290,6 +445,27 @@ func configureProviderMetadata(userConfig *ProviderConfig, p *catwalk.Provider)
 	}
 }
 
+// configureProviderHeaders adds the provider's default headers that the user has
+// not overridden. Only the placeholders left by a redacted export are resolved
+// from the environment, and headers whose placeholder is unset are skipped;
+// any other value is sent as written, so that an imported provider cannot send
+// the environment's secrets to its own base URL.
+func configureProviderHeaders(userConfig *ProviderConfig, p *catwalk.Provider, resolver *Resolver) {
+	for name, value := range p.DefaultHeaders {
+		if _, ok := userConfig.ExtraHeaders[name]; ok {
+			continue
+		}
+		if scheme, secret := splitAuthScheme(value); isSecretPlaceholder(secret, string(p.ID), name) {
+			resolved, err := resolver.Resolve(secret)
+			if err != nil {
+				continue
+			}
+			value = scheme + resolved
+		}
+		userConfig.ExtraHeaders[name] = value
+	}
+}
+
 func configureProviderModels(userConfig *ProviderConfig, p *catwalk.Provider) {
 	if len(userConfig.Models) == 0 {
 		userConfig.Models = p.Models
@@ -379,7 +555,7 @@ func applyDefaults(cfg *Config) {
 		cfg.Options = &Options{}
 	}
 	if cfg.Options.DataDir == "" {
-		cfg.Options.DataDir = filepath.Join(xdg.DataHome, appName)
+		cfg.Options.DataDir = activeDataDir()
 	}
 }
 
@@ -406,7 +582,13 @@ func GlobalConfigPath() string {
 	if globalConfigPathOverride != "" {
 		return globalConfigPathOverride
 	}
-	return filepath.Join(xdg.ConfigHome, appName, configFileName)
+	return filepath.Join(activeConfigDir(), configFileName)
+}
+
+// ProjectConfigPath returns the project configuration file that applies to
+// the working directory, or "" when there is none.
+func ProjectConfigPath() string {
+	return findProjectConfig()
 }
 
 // SetGlobalConfigPath sets an override for GlobalConfigPath (for testing only).
@@ -419,7 +601,266 @@ func (c *Config) DataDir() string {
 	if c.Options != nil && c.Options.DataDir != "" {
 		return c.Options.DataDir
 	}
-	return filepath.Join(xdg.DataHome, appName)
+	return activeDataDir()
+}
+
+// BashTimeout returns the configured default bash tool timeout, or zero if unset.
+func (c *Config) BashTimeout() time.Duration {
+	if c.Options == nil || c.Options.BashTimeout <= 0 {
+		return 0
+	}
+	return time.Duration(c.Options.BashTimeout) * time.Second
+}
+
+// MaxIterations returns the configured model steps per prompt, zero if unset
+// or negative for no limit.
+func (c *Config) MaxIterations() int {
+	if c.Options == nil {
+		return 0
+	}
+	return c.Options.MaxIterations
+}
+
+// defaultToolOutputDisplayLines is how much of an expanded tool result the
+// chat shows when options.tool_output.display_lines is unset.
+const defaultToolOutputDisplayLines = 200
+
+// MaxToolOutput returns the most characters of a tool result sent to the
+// model, zero when unset and negative for no limit.
+func (c *Config) MaxToolOutput() int {
+	if c.Options == nil || c.Options.ToolOutput == nil {
+		return 0
+	}
+	return c.Options.ToolOutput.MaxChars
+}
+
+// ToolOutputDisplayLines returns how many lines of an expanded tool result the
+// chat shows.
+func (c *Config) ToolOutputDisplayLines() int {
+	if c.Options == nil || c.Options.ToolOutput == nil || c.Options.ToolOutput.DisplayLines <= 0 {
+		return defaultToolOutputDisplayLines
+	}
+	return c.Options.ToolOutput.DisplayLines
+}
+
+// MaxAttempts returns the configured attempt count for transient provider errors, or zero if unset.
+func (c *Config) MaxAttempts() int {
+	if c.Options == nil || c.Options.MaxAttempts <= 0 {
+		return 0
+	}
+	return c.Options.MaxAttempts
+}
+
+// VimMode reports whether vim-style editing is enabled for the chat input.
+func (c *Config) VimMode() bool {
+	return c.Options != nil && c.Options.VimMode
+}
+
+// ShowThinking reports whether model reasoning is expanded in the chat by default.
+func (c *Config) ShowThinking() bool {
+	return c.Options != nil && c.Options.ShowThinking
+}
+
+// Accessible reports whether the TUI's output is meant for a screen reader.
+func (c *Config) Accessible() bool {
+	return c.Options != nil && c.Options.Accessible
+}
+
+// Transcript reports whether every message is also appended to a transcript
+// file per session.
+func (c *Config) Transcript() bool {
+	return c.Options != nil && c.Options.Transcript
+}
+
+// SystemPromptFile returns the file that replaces the built-in system prompt,
+// or "" when none is set. A relative path is resolved against the directory
+// of the config file that set it.
+func (c *Config) SystemPromptFile() string {
+	if c.Options == nil || c.Options.SystemPromptFile == "" {
+		return ""
+	}
+	path := c.Options.SystemPromptFile
+	if filepath.IsAbs(path) || c.promptBaseDir == "" {
+		return path
+	}
+	return filepath.Join(c.promptBaseDir, path)
+}
+
+// Telemetry returns the trace export settings, or nil when tracing is off.
+func (c *Config) Telemetry() *TelemetryOptions {
+	if c.Options == nil || c.Options.Telemetry == nil || c.Options.Telemetry.Endpoint == "" {
+		return nil
+	}
+	return c.Options.Telemetry
+}
+
+// MetricsListen returns the address of the metrics endpoint, or "" when it is
+// disabled.
+func (c *Config) MetricsListen() string {
+	if c.Options == nil || c.Options.Metrics == nil {
+		return ""
+	}
+	return c.Options.Metrics.Listen
+}
+
+// Backup defaults used when options.backup leaves them unset.
+const (
+	defaultBackupKeep     = 5
+	defaultBackupInterval = 24 * time.Hour
+)
+
+// BackupKeep returns how many database backups to retain, or zero when
+// backups are disabled.
+func (c *Config) BackupKeep() int {
+	if c.Options == nil || c.Options.Backup == nil {
+		return defaultBackupKeep
+	}
+	if c.Options.Backup.Disabled {
+		return 0
+	}
+	if c.Options.Backup.Keep <= 0 {
+		return defaultBackupKeep
+	}
+	return c.Options.Backup.Keep
+}
+
+// BackupInterval returns the minimum time between database backups.
+func (c *Config) BackupInterval() time.Duration {
+	if c.Options == nil || c.Options.Backup == nil || c.Options.Backup.IntervalHours <= 0 {
+		return defaultBackupInterval
+	}
+	return time.Duration(c.Options.Backup.IntervalHours) * time.Hour
+}
+
+// defaultNotifyAfter is the shortest run announced when
+// options.notifications.min_seconds is unset.
+const defaultNotifyAfter = 10 * time.Second
+
+// NotifyAfter returns how long a run must take before its end is announced,
+// or zero when notifications are disabled.
+func (c *Config) NotifyAfter() time.Duration {
+	if c.Options == nil || c.Options.Notifications == nil {
+		return defaultNotifyAfter
+	}
+	if c.Options.Notifications.Disabled {
+		return 0
+	}
+	if c.Options.Notifications.MinSeconds <= 0 {
+		return defaultNotifyAfter
+	}
+	return time.Duration(c.Options.Notifications.MinSeconds) * time.Second
+}
+
+// DesktopNotifications reports whether desktop notifications are enabled on
+// top of the terminal bell.
+func (c *Config) DesktopNotifications() bool {
+	return c.NotifyAfter() > 0 && c.Options != nil && c.Options.Notifications != nil && c.Options.Notifications.Desktop
+}
+
+// CodebaseIndex returns the settings of the codebase index, or nil when it
+// is not enabled.
+func (c *Config) CodebaseIndex() *IndexOptions {
+	if c.Options == nil || c.Options.Index == nil || !c.Options.Index.Enabled {
+		return nil
+	}
+	return c.Options.Index
+}
+
+// Budget returns the configured spending limits, all zero when none are set.
+func (c *Config) Budget() BudgetOptions {
+	if c.Options == nil || c.Options.Budget == nil {
+		return BudgetOptions{}
+	}
+	return *c.Options.Budget
+}
+
+// DefaultThinkingBudget is the thinking budget used when a model has think
+// set without a thinking_budget.
+const DefaultThinkingBudget = 4096
+
+// ThinkingBudget returns the tokens the selected model of the given type may
+// spend thinking, or zero when thinking is off.
+func (c *Config) ThinkingBudget(modelType SelectedModelType) int64 {
+	model, ok := c.Models[modelType]
+	if !ok || !model.Think {
+		return 0
+	}
+	if model.ThinkingBudget > 0 {
+		return model.ThinkingBudget
+	}
+	return DefaultThinkingBudget
+}
+
+// Keybindings returns the configured key binding overrides, if any.
+func (c *Config) Keybindings() map[string][]string {
+	if c.Options == nil {
+		return nil
+	}
+	return c.Options.Keybindings
+}
+
+// ModelAliases returns the configured model aliases, if any.
+func (c *Config) ModelAliases() map[string]string {
+	if c.Options == nil {
+		return nil
+	}
+	return c.Options.ModelAliases
+}
+
+// ResolveModelAlias returns the model spec an alias stands for, or spec
+// itself when it is not an alias. Aliases do not refer to other aliases.
+func (c *Config) ResolveModelAlias(spec string) string {
+	if target, ok := c.ModelAliases()[strings.TrimSpace(spec)]; ok && target != "" {
+		return target
+	}
+	return spec
+}
+
+// StatusBar returns the status bar segments to show, in order, and any
+// configured names that are not segments. An empty list hides them all.
+func (c *Config) StatusBar() (segments, unknown []string) {
+	if c.Options == nil || c.Options.StatusBar == nil {
+		return DefaultStatusBar, nil
+	}
+	segments = []string{}
+	for _, name := range c.Options.StatusBar {
+		if slices.Contains(StatusSegments, name) {
+			segments = append(segments, name)
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	return segments, unknown
+}
+
+// Markdown returns the markdown renderer options, with an unset or unknown
+// style read as MarkdownStyleDark, and whether the configured style is known.
+func (c *Config) Markdown() (MarkdownOptions, bool) {
+	var opts MarkdownOptions
+	if c.Options != nil && c.Options.Markdown != nil {
+		opts = *c.Options.Markdown
+	}
+	opts.WordWrap = max(opts.WordWrap, 0)
+	if opts.Style == "" {
+		opts.Style = MarkdownStyleDark
+	}
+	if !slices.Contains(MarkdownStyles, opts.Style) {
+		opts.Style = MarkdownStyleDark
+		return opts, false
+	}
+	return opts, true
+}
+
+// Colors returns the color setting of the TUI, with an unset or unknown
+// setting read as ColorsAuto, and whether the configured setting is known.
+func (c *Config) Colors() (string, bool) {
+	if c.Options == nil || c.Options.Colors == "" {
+		return ColorsAuto, true
+	}
+	if !slices.Contains(ColorSettings, c.Options.Colors) {
+		return ColorsAuto, false
+	}
+	return c.Options.Colors, true
 }
 
 // Resolve resolves environment variables in a configuration value.
@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
 )
@@ -163,6 +165,192 @@ func TestMergeConfig_NilOptions(t *testing.T) {
 	}
 }
 
+func TestMergeConfig_Keybindings(t *testing.T) {
+	dst := NewConfig()
+	dst.Options = &Options{Keybindings: map[string][]string{
+		"newline": {"shift+enter"},
+		"search":  {"ctrl+f"},
+	}}
+
+	src := NewConfig()
+	src.Options = &Options{Keybindings: map[string][]string{
+		"search": {},
+		"help":   {"f1"},
+	}}
+
+	mergeConfig(dst, src)
+
+	got := dst.Keybindings()
+	if len(got["newline"]) != 1 || got["newline"][0] != "shift+enter" {
+		t.Errorf("newline = %v, want global binding kept", got["newline"])
+	}
+	if keys, ok := got["search"]; !ok || len(keys) != 0 {
+		t.Errorf("search = %v, %v; want project unbinding", keys, ok)
+	}
+	if len(got["help"]) != 1 || got["help"][0] != "f1" {
+		t.Errorf("help = %v, want project binding", got["help"])
+	}
+}
+
+func TestConfig_ResolveModelAlias(t *testing.T) {
+	dst := NewConfig()
+	dst.Options = &Options{ModelAliases: map[string]string{
+		"fast":  "groq/llama-3.1-8b-instant",
+		"smart": "anthropic/claude-sonnet-4",
+	}}
+	src := NewConfig()
+	src.Options = &Options{ModelAliases: map[string]string{"smart": "openai/gpt-5"}}
+	mergeConfig(dst, src)
+
+	tests := map[string]string{
+		"fast":          "groq/llama-3.1-8b-instant",
+		" fast ":        "groq/llama-3.1-8b-instant",
+		"smart":         "openai/gpt-5",
+		"openai/gpt-4o": "openai/gpt-4o",
+		"":              "",
+	}
+	for spec, want := range tests {
+		if got := dst.ResolveModelAlias(spec); got != want {
+			t.Errorf("ResolveModelAlias(%q) = %q, want %q", spec, got, want)
+		}
+	}
+	if got := NewConfig().ResolveModelAlias("fast"); got != "fast" {
+		t.Errorf("ResolveModelAlias() without aliases = %q, want the spec unchanged", got)
+	}
+}
+
+func TestConfig_ThinkingBudget(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Models[SelectedModelTypeLarge] = SelectedModel{Model: "claude", Think: true}
+	cfg.Models[SelectedModelTypeSmall] = SelectedModel{Model: "haiku", ThinkingBudget: 2048}
+
+	if got := cfg.ThinkingBudget(SelectedModelTypeLarge); got != DefaultThinkingBudget {
+		t.Errorf("large budget = %d, want the default %d", got, DefaultThinkingBudget)
+	}
+	if got := cfg.ThinkingBudget(SelectedModelTypeSmall); got != 0 {
+		t.Errorf("small budget = %d, want 0 without think", got)
+	}
+
+	cfg.Models[SelectedModelTypeLarge] = SelectedModel{Model: "claude", Think: true, ThinkingBudget: 16000}
+	if got := cfg.ThinkingBudget(SelectedModelTypeLarge); got != 16000 {
+		t.Errorf("large budget = %d, want 16000", got)
+	}
+}
+
+func TestConfig_Notifications(t *testing.T) {
+	cfg := &Config{}
+	if cfg.NotifyAfter() != 10*time.Second || cfg.DesktopNotifications() {
+		t.Errorf("defaults = %s, %v, want a bell after 10s", cfg.NotifyAfter(), cfg.DesktopNotifications())
+	}
+
+	cfg.Options = &Options{Notifications: &NotificationOptions{MinSeconds: 30, Desktop: true}}
+	if cfg.NotifyAfter() != 30*time.Second || !cfg.DesktopNotifications() {
+		t.Errorf("configured = %s, %v", cfg.NotifyAfter(), cfg.DesktopNotifications())
+	}
+
+	cfg.Options.Notifications.Disabled = true
+	if cfg.NotifyAfter() != 0 || cfg.DesktopNotifications() {
+		t.Error("disabled notifications should turn off the bell and desktop notifications")
+	}
+}
+
+func TestConfig_StatusBar(t *testing.T) {
+	cfg := &Config{}
+	if got, _ := cfg.StatusBar(); !slices.Equal(got, DefaultStatusBar) {
+		t.Errorf("StatusBar() = %v, want the default", got)
+	}
+
+	cfg.Options = &Options{StatusBar: []string{"cost", "weather", "model"}}
+	got, unknown := cfg.StatusBar()
+	if !slices.Equal(got, []string{"cost", "model"}) || !slices.Equal(unknown, []string{"weather"}) {
+		t.Errorf("StatusBar() = %v, %v; want the known segments in order and the unknown name", got, unknown)
+	}
+
+	cfg.Options.StatusBar = []string{}
+	if got, _ := cfg.StatusBar(); len(got) != 0 {
+		t.Errorf("StatusBar() = %v, want no segments", got)
+	}
+}
+
+func TestConfig_Markdown(t *testing.T) {
+	cfg := &Config{}
+	if got, ok := cfg.Markdown(); got != (MarkdownOptions{Style: MarkdownStyleDark}) || !ok {
+		t.Errorf("Markdown() = %+v, %v; want the dark style", got, ok)
+	}
+
+	dst := NewConfig()
+	dst.Options = &Options{Markdown: &MarkdownOptions{Style: "light", WordWrap: 100}}
+	src := NewConfig()
+	src.Options = &Options{Markdown: &MarkdownOptions{Style: "notty", CodeTheme: "github"}}
+	mergeConfig(dst, src)
+	want := MarkdownOptions{Style: MarkdownStyleNoTTY, CodeTheme: "github"}
+	if got, ok := dst.Markdown(); got != want || !ok {
+		t.Errorf("Markdown() = %+v, %v; want the project's options %+v", got, ok, want)
+	}
+
+	cfg.Options = &Options{Markdown: &MarkdownOptions{Style: "solarized", WordWrap: -1}}
+	if got, ok := cfg.Markdown(); got != (MarkdownOptions{Style: MarkdownStyleDark}) || ok {
+		t.Errorf("Markdown() = %+v, %v; want the dark style and the unknown one reported", got, ok)
+	}
+}
+
+func TestConfig_Colors(t *testing.T) {
+	cfg := &Config{}
+	if got, ok := cfg.Colors(); got != ColorsAuto || !ok {
+		t.Errorf("Colors() = %q, %v; want auto", got, ok)
+	}
+
+	dst := NewConfig()
+	dst.Options = &Options{Colors: "256"}
+	src := NewConfig()
+	src.Options = &Options{Colors: "none"}
+	mergeConfig(dst, src)
+	if got, ok := dst.Colors(); got != ColorsNone || !ok {
+		t.Errorf("Colors() = %q, %v; want the project's setting", got, ok)
+	}
+
+	cfg.Options = &Options{Colors: "8"}
+	if got, ok := cfg.Colors(); got != ColorsAuto || ok {
+		t.Errorf("Colors() = %q, %v; want auto and the unknown setting reported", got, ok)
+	}
+}
+
+func TestConfig_CodebaseIndex(t *testing.T) {
+	cfg := &Config{}
+	if cfg.CodebaseIndex() != nil {
+		t.Error("the index should be off by default")
+	}
+
+	cfg.Options = &Options{Index: &IndexOptions{Provider: "openai"}}
+	if cfg.CodebaseIndex() != nil {
+		t.Error("the index should stay off until enabled")
+	}
+
+	cfg.Options.Index.Enabled = true
+	if got := cfg.CodebaseIndex(); got == nil || got.Provider != "openai" {
+		t.Errorf("CodebaseIndex() = %+v, want the enabled settings", got)
+	}
+}
+
+func TestConfig_SystemPromptFile(t *testing.T) {
+	cfg := NewConfig()
+	if got := cfg.SystemPromptFile(); got != "" {
+		t.Errorf("SystemPromptFile() = %q, want empty when unset", got)
+	}
+
+	cfg.Options.SystemPromptFile = "prompts/system.md"
+	cfg.promptBaseDir = "project"
+	if got, want := cfg.SystemPromptFile(), filepath.Join("project", "prompts", "system.md"); got != want {
+		t.Errorf("SystemPromptFile() = %q, want %q", got, want)
+	}
+
+	abs := filepath.Join(t.TempDir(), "system.md")
+	cfg.Options.SystemPromptFile = abs
+	if got := cfg.SystemPromptFile(); got != abs {
+		t.Errorf("SystemPromptFile() = %q, want the absolute path kept", got)
+	}
+}
+
 func TestMergeConfig_SrcNilOptions(t *testing.T) {
 	dst := NewConfig()
 	dst.Options = &Options{Debug: true}
@@ -178,6 +366,63 @@ func TestMergeConfig_SrcNilOptions(t *testing.T) {
 	}
 }
 
+func TestMergeConfig_LSP(t *testing.T) {
+	dst := NewConfig()
+	dst.LSP = map[string]LSPConfig{
+		"gopls":   {Command: "gopls", FileTypes: []string{"go"}},
+		"pyright": {Command: "pyright-langserver", FileTypes: []string{"py"}},
+	}
+
+	src := NewConfig()
+	src.LSP = map[string]LSPConfig{"gopls": {Command: "gopls", FileTypes: []string{"go"}, Disabled: true}}
+
+	mergeConfig(dst, src)
+
+	if !dst.LSP["gopls"].Disabled {
+		t.Error("project gopls entry should override the global one")
+	}
+	if dst.LSP["pyright"].Command != "pyright-langserver" {
+		t.Error("global pyright entry should be kept")
+	}
+}
+
+func TestMergeConfig_Hooks(t *testing.T) {
+	dst := NewConfig()
+	dst.Hooks = &HooksConfig{PostTool: []HookConfig{{Command: "global"}}}
+
+	src := NewConfig()
+	src.Hooks = &HooksConfig{
+		PostTool:   []HookConfig{{Command: "project"}},
+		OnComplete: []HookConfig{{Command: "notify"}},
+	}
+
+	mergeConfig(dst, src)
+
+	if len(dst.Hooks.PostTool) != 2 || dst.Hooks.PostTool[0].Command != "global" || dst.Hooks.PostTool[1].Command != "project" {
+		t.Errorf("PostTool = %+v, want the global hook then the project one", dst.Hooks.PostTool)
+	}
+	if len(dst.Hooks.OnComplete) != 1 {
+		t.Errorf("OnComplete = %+v, want the project hook", dst.Hooks.OnComplete)
+	}
+}
+
+func TestMergeConfig_Sandbox(t *testing.T) {
+	dst := NewConfig()
+	dst.Sandbox = &SandboxConfig{Enabled: true, AllowedCommands: []string{"go"}}
+
+	src := NewConfig()
+	src.Sandbox = &SandboxConfig{AllowedCommands: []string{"npm", "node"}}
+
+	mergeConfig(dst, src)
+
+	if !dst.Sandbox.Enabled {
+		t.Error("a project should not turn the sandbox off")
+	}
+	if len(dst.Sandbox.AllowedCommands) != 2 || dst.Sandbox.AllowedCommands[0] != "npm" {
+		t.Errorf("AllowedCommands = %v, want the project's list", dst.Sandbox.AllowedCommands)
+	}
+}
+
 func TestConfigureProviders(t *testing.T) {
 	t.Setenv("TEST_API_KEY", "resolved-key")
 
@@ -287,6 +532,45 @@ func TestConfigureProviders_CustomBaseURL(t *testing.T) {
 	}
 }
 
+func TestConfigureProviders_DefaultHeaders(t *testing.T) {
+	t.Setenv("TEST_KEY", "key")
+	t.Setenv(secretEnvVar(testProviderID, "Authorization"), "secret")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "aws-secret")
+
+	cfg := NewConfig()
+	cfg.Providers[testProviderID] = &ProviderConfig{
+		APIKey:       "$TEST_KEY",
+		ExtraHeaders: map[string]string{"X-Team": "mine"},
+	}
+
+	providers := []catwalk.Provider{{
+		ID: testProviderID,
+		DefaultHeaders: map[string]string{
+			"Authorization": "Bearer $" + secretEnvVar(testProviderID, "Authorization"),
+			"X-Team":        "default",
+			"X-Missing":     "${" + secretEnvVar(testProviderID, "X-Missing") + "}",
+			"X-Leak":        "$AWS_SECRET_ACCESS_KEY",
+		},
+	}}
+	cfg.SetKnownProviders(providers)
+
+	configureProviders(cfg, NewResolver())
+
+	headers := cfg.Providers[testProviderID].ExtraHeaders
+	if headers["Authorization"] != "Bearer secret" {
+		t.Errorf("Authorization = %q, want %q", headers["Authorization"], "Bearer secret")
+	}
+	if headers["X-Team"] != "mine" {
+		t.Errorf("X-Team = %q, want user override %q", headers["X-Team"], "mine")
+	}
+	if _, ok := headers["X-Missing"]; ok {
+		t.Error("header with unset variable should be skipped")
+	}
+	if headers["X-Leak"] != "$AWS_SECRET_ACCESS_KEY" {
+		t.Errorf("X-Leak = %q; only the export's placeholders should be resolved", headers["X-Leak"])
+	}
+}
+
 func TestConfigureProviders_MergeModels(t *testing.T) {
 	t.Setenv("TEST_KEY", "key")
 
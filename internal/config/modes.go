@@ -0,0 +1,67 @@
+package config
+
+import "maps"
+
+// Built-in agent modes.
+const (
+	ModeCode   = "code"
+	ModePlan   = "plan"
+	ModeReview = "review"
+)
+
+// builtinModes are the modes available without configuration. A mode of the
+// same name in Config.Modes replaces one of these.
+var builtinModes = map[string]ModeConfig{
+	ModeCode: {
+		Description: "Read, write and run code with every tool",
+	},
+	ModePlan: {
+		Description: "Explore the code and propose a plan without changing anything",
+		ReadOnly:    true,
+		Prompt: `# Plan Mode
+
+You are in plan mode: you can read and search the code but not change files or run commands.
+
+- Investigate until you understand the relevant code and its conventions
+- Ask clarifying questions when the request is ambiguous
+- Finish with a concrete, step-by-step implementation plan: the files to change, what changes in each, and how to verify the result
+- Record the steps with todo_write, one pending todo per step, so the user can approve the plan and follow its progress
+- Do not write out complete implementations; the user switches to code mode to carry out the plan`,
+	},
+	ModeReview: {
+		Description: "Review changes and report problems without editing files",
+		ReadOnly:    true,
+		Tools:       []string{"bash"},
+		Prompt: `# Review Mode
+
+You are in review mode: you review code and report findings; you do not edit files.
+
+- Unless told otherwise, review the uncommitted changes (git diff, git diff --staged) or the commits the user names
+- Use bash only to inspect: git log, git diff, git show, and read-only test or lint runs
+- Look for bugs, missed edge cases, security problems, unclear code and missing tests, in that order of importance
+- Report each finding with its file and line, why it matters, and a suggested fix
+- Say plainly when the changes look good; don't invent problems`,
+	},
+}
+
+// AgentModes returns the built-in agent modes merged with the configured
+// ones, keyed by name.
+func (c *Config) AgentModes() map[string]ModeConfig {
+	modes := maps.Clone(builtinModes)
+	maps.Copy(modes, c.Modes)
+	return modes
+}
+
+// DefaultMode returns the agent mode to start in.
+func (c *Config) DefaultMode() string {
+	if c.Options == nil || c.Options.DefaultMode == "" {
+		return ModeCode
+	}
+	return c.Options.DefaultMode
+}
+
+// PlanFirst reports whether prompts are planned in plan mode and wait for
+// approval before they run.
+func (c *Config) PlanFirst() bool {
+	return c.Options != nil && c.Options.PlanFirst
+}
@@ -0,0 +1,37 @@
+package config
+
+import "testing"
+
+func TestAgentModes(t *testing.T) {
+	cfg := NewConfig()
+	modes := cfg.AgentModes()
+	if len(modes) != 3 || !modes[ModePlan].ReadOnly || modes[ModeCode].ReadOnly {
+		t.Errorf("modes = %+v, want the built-in code, plan and review", modes)
+	}
+	if cfg.DefaultMode() != ModeCode {
+		t.Errorf("DefaultMode() = %q, want code", cfg.DefaultMode())
+	}
+
+	global := &Config{
+		Modes:   map[string]ModeConfig{"docs": {Description: "Write docs", Tools: []string{"read_file", "edit"}}},
+		Options: &Options{DefaultMode: ModePlan},
+	}
+	project := &Config{Modes: map[string]ModeConfig{ModePlan: {Prompt: "Plan in bullet points."}}}
+	cfg = NewConfig()
+	mergeConfig(cfg, global)
+	mergeConfig(cfg, project)
+
+	modes = cfg.AgentModes()
+	if len(modes) != 4 || modes["docs"].Description != "Write docs" {
+		t.Errorf("modes = %+v, want docs added to the built-in ones", modes)
+	}
+	if plan := modes[ModePlan]; plan.Prompt != "Plan in bullet points." || plan.ReadOnly {
+		t.Errorf("plan = %+v, want the project's definition in place of the built-in one", plan)
+	}
+	if cfg.DefaultMode() != ModePlan {
+		t.Errorf("DefaultMode() = %q, want plan", cfg.DefaultMode())
+	}
+	if len(builtinModes[ModePlan].Prompt) == 0 {
+		t.Error("configuring a mode should not change the built-in one")
+	}
+}
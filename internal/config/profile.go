@@ -0,0 +1,163 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/adrg/xdg"
+)
+
+// DefaultProfile is the profile used when none is selected. It keeps its
+// files directly in the cdd config and data directories, as before profiles
+// existed.
+const DefaultProfile = "default"
+
+// ProfileEnv is the environment variable that selects a profile.
+const ProfileEnv = "CDD_PROFILE"
+
+const (
+	// profilesDir holds the config and data directories of named profiles.
+	profilesDir = "profiles"
+	// currentProfileFile records the profile chosen with SwitchProfile.
+	currentProfileFile = "profile"
+)
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// activeProfile is the profile whose files Load and Save use.
+var activeProfile = DefaultProfile
+
+// Profile returns the active profile.
+func Profile() string {
+	return activeProfile
+}
+
+// SetProfile makes name the active profile. Named profiles must have been
+// created with CreateProfile.
+func SetProfile(name string) error {
+	if err := checkProfileName(name); err != nil {
+		return err
+	}
+	if name != DefaultProfile && !profileExists(name) {
+		return fmt.Errorf("profile %q does not exist (create it with 'cdd profiles create %s')", name, name)
+	}
+	activeProfile = name
+	return nil
+}
+
+// ResolveProfile picks the profile to use: flag when set, then the
+// CDD_PROFILE environment variable, then the profile last chosen with
+// SwitchProfile, then the default.
+func ResolveProfile(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	if env := os.Getenv(ProfileEnv); env != "" {
+		return env
+	}
+	if current := CurrentProfile(); current != "" {
+		return current
+	}
+	return DefaultProfile
+}
+
+// CurrentProfile returns the profile chosen with SwitchProfile, or "" when
+// none has been.
+func CurrentProfile() string {
+	data, err := os.ReadFile(filepath.Join(baseConfigDir(), currentProfileFile)) //nolint:gosec // G304: Fixed file in the config directory
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// SwitchProfile makes name the profile used when neither --profile nor
+// CDD_PROFILE is given.
+func SwitchProfile(name string) error {
+	if err := checkProfileName(name); err != nil {
+		return err
+	}
+	if name != DefaultProfile && !profileExists(name) {
+		return fmt.Errorf("profile %q does not exist", name)
+	}
+	return writeFileAtomic(filepath.Join(baseConfigDir(), currentProfileFile), []byte(name+"\n"))
+}
+
+// CreateProfile creates the config and data directories of a new profile.
+func CreateProfile(name string) error {
+	if err := checkProfileName(name); err != nil {
+		return err
+	}
+	if name == DefaultProfile || profileExists(name) {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+	for _, dir := range []string{profileConfigDir(name), profileDataDir(name)} {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("creating profile directory: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListProfiles returns the default profile followed by the named profiles in
+// alphabetical order.
+func ListProfiles() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(baseConfigDir(), profilesDir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading profiles: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && checkProfileName(e.Name()) == nil && e.Name() != DefaultProfile {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...), nil
+}
+
+func checkProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+func profileExists(name string) bool {
+	info, err := os.Stat(profileConfigDir(name))
+	return err == nil && info.IsDir()
+}
+
+func baseConfigDir() string {
+	return filepath.Join(xdg.ConfigHome, appName)
+}
+
+func profileConfigDir(name string) string {
+	if name == DefaultProfile {
+		return baseConfigDir()
+	}
+	return filepath.Join(baseConfigDir(), profilesDir, name)
+}
+
+func profileDataDir(name string) string {
+	base := filepath.Join(xdg.DataHome, appName)
+	if name == DefaultProfile {
+		return base
+	}
+	return filepath.Join(base, profilesDir, name)
+}
+
+// activeConfigDir returns the config directory of the active profile.
+func activeConfigDir() string {
+	return profileConfigDir(activeProfile)
+}
+
+// activeDataDir returns the default data directory of the active profile.
+func activeDataDir() string {
+	return profileDataDir(activeProfile)
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/adrg/xdg"
+)
+
+// useTempProfiles points the config and data directories at a temporary
+// directory and restores them, and the active profile, afterwards.
+func useTempProfiles(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	configHome, dataHome, profile := xdg.ConfigHome, xdg.DataHome, activeProfile
+	xdg.ConfigHome = filepath.Join(dir, "config")
+	xdg.DataHome = filepath.Join(dir, "data")
+	t.Cleanup(func() {
+		xdg.ConfigHome, xdg.DataHome, activeProfile = configHome, dataHome, profile
+	})
+	t.Setenv(ProfileEnv, "")
+	return dir
+}
+
+func TestProfiles_CreateSwitchList(t *testing.T) {
+	useTempProfiles(t)
+
+	if err := SetProfile("work"); err == nil {
+		t.Fatal("SetProfile() of a missing profile succeeded")
+	}
+	if err := CreateProfile("work"); err != nil {
+		t.Fatalf("CreateProfile() error = %v", err)
+	}
+	if err := CreateProfile("work"); err == nil {
+		t.Error("CreateProfile() of an existing profile succeeded")
+	}
+	if err := CreateProfile("../escape"); err == nil {
+		t.Error("CreateProfile() accepted an invalid name")
+	}
+
+	names, err := ListProfiles()
+	if err != nil {
+		t.Fatalf("ListProfiles() error = %v", err)
+	}
+	if want := []string{DefaultProfile, "work"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ListProfiles() = %v, want %v", names, want)
+	}
+
+	if got := ResolveProfile(""); got != DefaultProfile {
+		t.Errorf("ResolveProfile() before switch = %q, want %q", got, DefaultProfile)
+	}
+	if err := SwitchProfile("work"); err != nil {
+		t.Fatalf("SwitchProfile() error = %v", err)
+	}
+	if got := ResolveProfile(""); got != "work" {
+		t.Errorf("ResolveProfile() after switch = %q, want work", got)
+	}
+	t.Setenv(ProfileEnv, "home")
+	if got := ResolveProfile(""); got != "home" {
+		t.Errorf("ResolveProfile() with %s = %q, want home", ProfileEnv, got)
+	}
+	if got := ResolveProfile("flag"); got != "flag" {
+		t.Errorf("ResolveProfile(flag) = %q, want flag", got)
+	}
+}
+
+func TestProfiles_SeparateFiles(t *testing.T) {
+	dir := useTempProfiles(t)
+
+	if err := CreateProfile("work"); err != nil {
+		t.Fatalf("CreateProfile() error = %v", err)
+	}
+
+	defaultConfig := GlobalConfigPath()
+	if err := SetProfile("work"); err != nil {
+		t.Fatalf("SetProfile() error = %v", err)
+	}
+
+	if got, want := GlobalConfigPath(), filepath.Join(dir, "config", "cdd", "profiles", "work", "cdd.json"); got != want {
+		t.Errorf("GlobalConfigPath() = %q, want %q", got, want)
+	}
+	if got := GlobalConfigPath(); got == defaultConfig {
+		t.Error("profile shares the default config file")
+	}
+	if got, want := NewConfig().DataDir(), filepath.Join(dir, "data", "cdd", "profiles", "work"); got != want {
+		t.Errorf("DataDir() = %q, want %q", got, want)
+	}
+	if got, want := credentialPrefix("providers", "openai"), "profiles/work/providers/openai"; got != want {
+		t.Errorf("credentialPrefix() = %q, want %q", got, want)
+	}
+}
@@ -61,32 +61,11 @@ func (pl *ProviderLoader) LoadAllProviders(cfg *Config) ([]catwalk.Provider, err
 	return pl.mergeProviders(catwalkProviders, customProviders), nil
 }
 
-// loadCatwalkProviders loads providers from catwalk API, cache, or embedded fallback.
+// loadCatwalkProviders loads providers from cache, catwalk API, or embedded
+// fallback. Catwalk is not contacted when auto-updates are disabled or cdd is
+// offline.
 func (pl *ProviderLoader) loadCatwalkProviders(cfg *Config) ([]catwalk.Provider, error) {
-	// If auto-updates are disabled, only use cache or embedded.
-	if pl.disableUpdates {
-		return pl.loadCachedOrEmbedded(cfg)
-	}
-
-	// Try to fetch from catwalk API.
-	client := catwalk.NewWithURL(pl.catwalkURL)
-	providers, err := client.GetProviders()
-	if err == nil {
-		// Successfully fetched, update cache.
-		dataDir := cfg.DataDir()
-		cachePath := getProvidersCachePath(dataDir)
-		// Cache write failure is non-fatal, ignore error.
-		_ = saveProvidersCache(cachePath, providers) //nolint:errcheck // intentionally ignoring cache write error
-		return providers, nil
-	}
-
-	// Fetch failed, try cache or embedded.
-	return pl.loadCachedOrEmbedded(cfg)
-}
-
-// loadCachedOrEmbedded loads providers from cache or falls back to embedded.
-func (pl *ProviderLoader) loadCachedOrEmbedded(cfg *Config) ([]catwalk.Provider, error) {
-	return LoadProviders(cfg)
+	return loadProviders(cfg, pl.catwalkURL, !pl.disableUpdates && !Offline())
 }
 
 // mergeProviders merges catwalk providers with custom providers.
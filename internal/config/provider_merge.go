@@ -0,0 +1,177 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// Ways to import a custom provider whose ID is already taken.
+const (
+	ConflictSkip      = "skip"      // Keep the provider already saved
+	ConflictOverwrite = "overwrite" // Replace it with the imported one
+	ConflictRename    = "rename"    // Add the imported one under a free ID
+)
+
+// ConflictStrategies lists the values of --on-conflict.
+var ConflictStrategies = []string{ConflictSkip, ConflictOverwrite, ConflictRename}
+
+// What merging did with an imported provider.
+const (
+	ProviderAdded     = "added"
+	ProviderUpdated   = "updated"
+	ProviderRenamed   = "renamed"
+	ProviderUnchanged = "unchanged"
+	ProviderSkipped   = "skipped"
+)
+
+// ProviderActions lists the actions in the order they are summed up.
+var ProviderActions = []string{ProviderAdded, ProviderUpdated, ProviderRenamed, ProviderUnchanged, ProviderSkipped}
+
+// ProviderChange is what merging did with one imported provider.
+type ProviderChange struct {
+	ID      string   // ID in the imported file
+	Name    string   // Name in the imported file
+	Action  string   // One of the Provider* actions
+	NewID   string   // ID a renamed provider was added under
+	Details []string // What an update changed, or why the provider was skipped
+}
+
+// MergeCustomProviders adds incoming providers to current, the saved custom
+// providers, and reports what happened to each. An ID already taken by a
+// custom provider is handled by onConflict; IDs in reserved, such as those of
+// catwalk's providers, are never overwritten. Invalid providers are skipped.
+func MergeCustomProviders(current, incoming []CustomProvider, reserved []string, onConflict string) ([]CustomProvider, []ProviderChange) {
+	merged := slices.Clone(current)
+	taken := make(map[string]bool, len(current)+len(reserved))
+	for _, id := range reserved {
+		taken[id] = true
+	}
+	for i := range current {
+		taken[current[i].ID] = true
+	}
+
+	now := time.Now()
+	changes := make([]ProviderChange, 0, len(incoming))
+	for i := range incoming {
+		p := incoming[i]
+		change := ProviderChange{ID: p.ID, Name: p.Name}
+
+		if result := ValidateCustomProvider(&p, nil); !result.IsValid {
+			change.Action = ProviderSkipped
+			for _, e := range result.Errors {
+				change.Details = append(change.Details, e.Error())
+			}
+			changes = append(changes, change)
+			continue
+		}
+
+		existing := slices.IndexFunc(merged, func(c CustomProvider) bool { return c.ID == p.ID })
+		switch {
+		case !taken[p.ID]:
+			p.CreatedAt, p.UpdatedAt = now, now
+			merged = append(merged, p)
+			change.Action = ProviderAdded
+		case existing >= 0 && sameCustomProvider(merged[existing], p):
+			change.Action = ProviderUnchanged
+		case onConflict == ConflictRename:
+			p.ID = freeProviderID(p.ID, taken)
+			p.CreatedAt, p.UpdatedAt = now, now
+			merged = append(merged, p)
+			change.Action, change.NewID = ProviderRenamed, p.ID
+		case onConflict == ConflictOverwrite && existing >= 0:
+			change.Details = diffCustomProviders(merged[existing], p)
+			p.CreatedAt, p.UpdatedAt = merged[existing].CreatedAt, now
+			merged[existing] = p
+			change.Action = ProviderUpdated
+		case existing < 0:
+			change.Action = ProviderSkipped
+			change.Details = []string{"a built-in provider has this ID; import it with --on-conflict rename"}
+		default:
+			change.Action = ProviderSkipped
+			change.Details = []string{"already exists; use --on-conflict overwrite or rename"}
+		}
+		taken[p.ID] = true
+		changes = append(changes, change)
+	}
+	return merged, changes
+}
+
+// freeProviderID returns id with the lowest numeric suffix not in taken.
+func freeProviderID(id string, taken map[string]bool) string {
+	for n := 2; ; n++ {
+		if candidate := fmt.Sprintf("%s-%d", id, n); !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+// sameCustomProvider reports whether two providers match, apart from when
+// they were saved.
+func sameCustomProvider(a, b CustomProvider) bool {
+	a.CreatedAt, a.UpdatedAt = time.Time{}, time.Time{}
+	b.CreatedAt, b.UpdatedAt = time.Time{}, time.Time{}
+	return reflect.DeepEqual(a, b)
+}
+
+// diffCustomProviders describes how to differs from from, one line per
+// changed field.
+func diffCustomProviders(from, to CustomProvider) []string {
+	var diff []string
+	field := func(name, a, b string) {
+		if a != b {
+			diff = append(diff, fmt.Sprintf("%s: %q → %q", name, a, b))
+		}
+	}
+	field("name", from.Name, to.Name)
+	field("type", string(from.Type), string(to.Type))
+	field("api_endpoint", from.APIEndpoint, to.APIEndpoint)
+	field("base_url", from.BaseURL, to.BaseURL)
+	field("default_large_model_id", from.DefaultLargeModelID, to.DefaultLargeModelID)
+	field("default_small_model_id", from.DefaultSmallModelID, to.DefaultSmallModelID)
+	if !maps.Equal(from.DefaultHeaders, to.DefaultHeaders) {
+		diff = append(diff, "default_headers: "+strings.Join(slices.Sorted(maps.Keys(from.DefaultHeaders)), ", ")+
+			" → "+strings.Join(slices.Sorted(maps.Keys(to.DefaultHeaders)), ", "))
+	}
+
+	fromModels := modelsByID(from.Models)
+	toModels := modelsByID(to.Models)
+	var added, removed, changed []string
+	for _, m := range to.Models {
+		old, ok := fromModels[m.ID]
+		switch {
+		case !ok:
+			added = append(added, m.ID)
+		case !reflect.DeepEqual(old, m):
+			changed = append(changed, m.ID)
+		}
+	}
+	for _, m := range from.Models {
+		if _, ok := toModels[m.ID]; !ok {
+			removed = append(removed, m.ID)
+		}
+	}
+	for _, models := range []struct {
+		label string
+		ids   []string
+	}{{"models added", added}, {"models removed", removed}, {"models changed", changed}} {
+		if len(models.ids) > 0 {
+			diff = append(diff, models.label+": "+strings.Join(models.ids, ", "))
+		}
+	}
+	return diff
+}
+
+// modelsByID indexes models by their ID.
+func modelsByID(models []catwalk.Model) map[string]catwalk.Model {
+	byID := make(map[string]catwalk.Model, len(models))
+	for _, m := range models {
+		byID[m.ID] = m
+	}
+	return byID
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+func TestMergeCustomProviders(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	saved := LiteLLMProvider("proxy", "Proxy", "http://localhost:4000", []catwalk.Model{{ID: "a"}, {ID: "b"}})
+	saved.CreatedAt, saved.UpdatedAt = created, created
+
+	changed := LiteLLMProvider("proxy", "Proxy", "http://proxy:4000", []catwalk.Model{{ID: "a"}, {ID: "c"}})
+	fresh := LiteLLMProvider("other", "Other", "http://other:4000", []catwalk.Model{{ID: "x"}})
+	builtin := LiteLLMProvider("openai", "Not OpenAI", "http://fake:4000", []catwalk.Model{{ID: "x"}})
+	invalid := CustomProvider{ID: "broken"}
+	reserved := []string{"openai"}
+
+	tests := []struct {
+		name       string
+		onConflict string
+		incoming   []CustomProvider
+		actions    []string
+		ids        []string
+	}{
+		{"skip", ConflictSkip, []CustomProvider{changed, fresh}, []string{ProviderSkipped, ProviderAdded}, []string{"proxy", "other"}},
+		{"overwrite", ConflictOverwrite, []CustomProvider{changed}, []string{ProviderUpdated}, []string{"proxy"}},
+		{"rename", ConflictRename, []CustomProvider{changed, changed}, []string{ProviderRenamed, ProviderRenamed}, []string{"proxy", "proxy-2", "proxy-3"}},
+		{"unchanged", ConflictOverwrite, []CustomProvider{saved}, []string{ProviderUnchanged}, []string{"proxy"}},
+		{"built-in", ConflictOverwrite, []CustomProvider{builtin}, []string{ProviderSkipped}, []string{"proxy"}},
+		{"built-in rename", ConflictRename, []CustomProvider{builtin}, []string{ProviderRenamed}, []string{"proxy", "openai-2"}},
+		{"invalid", ConflictOverwrite, []CustomProvider{invalid}, []string{ProviderSkipped}, []string{"proxy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, changes := MergeCustomProviders([]CustomProvider{saved}, tt.incoming, reserved, tt.onConflict)
+
+			var actions []string
+			for _, c := range changes {
+				actions = append(actions, c.Action)
+			}
+			if !slices.Equal(actions, tt.actions) {
+				t.Errorf("actions = %v, want %v", actions, tt.actions)
+			}
+			var ids []string
+			for i := range merged {
+				ids = append(ids, merged[i].ID)
+			}
+			if !slices.Equal(ids, tt.ids) {
+				t.Errorf("ids = %v, want %v", ids, tt.ids)
+			}
+		})
+	}
+}
+
+func TestMergeCustomProviders_OverwriteDiff(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	saved := LiteLLMProvider("proxy", "Proxy", "http://localhost:4000", []catwalk.Model{{ID: "a"}, {ID: "b"}})
+	saved.CreatedAt = created
+	incoming := LiteLLMProvider("proxy", "Proxy", "http://proxy:4000", []catwalk.Model{{ID: "a", Name: "A"}, {ID: "c"}})
+
+	merged, changes := MergeCustomProviders([]CustomProvider{saved}, []CustomProvider{incoming}, nil, ConflictOverwrite)
+
+	if got := merged[0]; got.APIEndpoint != "http://proxy:4000/v1" || !got.CreatedAt.Equal(created) || got.UpdatedAt.IsZero() {
+		t.Errorf("merged = %s created %v updated %v, want the import with the saved creation time",
+			got.APIEndpoint, got.CreatedAt, got.UpdatedAt)
+	}
+	details := strings.Join(changes[0].Details, "\n")
+	for _, want := range []string{
+		`api_endpoint: "http://localhost:4000/v1" → "http://proxy:4000/v1"`,
+		"models added: c",
+		"models removed: b",
+		"models changed: a",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("details missing %q:\n%s", want, details)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import "slices"
+
+// selectedTiers are the model tiers, in the order they are shown.
+var selectedTiers = []SelectedModelType{SelectedModelTypeLarge, SelectedModelTypeSmall}
+
+// ProviderUsage lists what in the configuration refers to a provider, and
+// would be left dangling if it were removed.
+type ProviderUsage struct {
+	Connections []Connection        // Connections to the provider
+	Tiers       []SelectedModelType // Selected models it serves
+	Settings    bool                // cdd.json has credentials or settings for it under providers
+}
+
+// Empty reports whether nothing refers to the provider.
+func (u ProviderUsage) Empty() bool {
+	return len(u.Connections) == 0 && len(u.Tiers) == 0 && !u.Settings
+}
+
+// ProviderUsage returns the connections, selected models and settings that
+// refer to providerID.
+func (c *Config) ProviderUsage(providerID string) ProviderUsage {
+	var usage ProviderUsage
+	for i := range c.Connections {
+		if c.Connections[i].ProviderID == providerID {
+			usage.Connections = append(usage.Connections, c.Connections[i])
+		}
+	}
+	for _, tier := range selectedTiers {
+		model, ok := c.Models[tier]
+		if !ok {
+			continue
+		}
+		// The connection decides the provider when there is one, as in
+		// GetActiveConnection.
+		served := model.Provider == providerID
+		if model.ConnectionID != "" {
+			served = slices.ContainsFunc(usage.Connections, func(conn Connection) bool {
+				return conn.ID == model.ConnectionID
+			})
+		}
+		if served {
+			usage.Tiers = append(usage.Tiers, tier)
+		}
+	}
+	if pc, ok := c.Providers[providerID]; ok && pc != nil {
+		usage.Settings = pc.hasSavedSettings()
+	}
+	return usage
+}
+
+// PurgeProvider removes the connections to providerID, the selected models
+// they serve and the provider's settings, and returns what was removed. The
+// configuration is not saved.
+func (c *Config) PurgeProvider(providerID string) ProviderUsage {
+	usage := c.ProviderUsage(providerID)
+	c.Connections = slices.DeleteFunc(c.Connections, func(conn Connection) bool {
+		return conn.ProviderID == providerID
+	})
+	for _, tier := range usage.Tiers {
+		delete(c.Models, tier)
+	}
+	delete(c.Providers, providerID)
+	return usage
+}
+
+// ReassignProvider points the connections to providerID, and the selected
+// models they serve, at newProviderID instead, and removes the provider's
+// settings. It returns what was moved. The selected models keep their model
+// IDs, which newProviderID may not offer. The configuration is not saved.
+func (c *Config) ReassignProvider(providerID, newProviderID string) ProviderUsage {
+	usage := c.ProviderUsage(providerID)
+	for i := range c.Connections {
+		if c.Connections[i].ProviderID == providerID {
+			c.Connections[i].ProviderID = newProviderID
+		}
+	}
+	for _, tier := range usage.Tiers {
+		model := c.Models[tier]
+		model.Provider = newProviderID
+		c.Models[tier] = model
+	}
+	delete(c.Providers, providerID)
+	return usage
+}
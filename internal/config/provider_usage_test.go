@@ -0,0 +1,92 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+// usageConfig has a custom provider "gw" serving the large model through a
+// connection and the small model through a legacy provider selection.
+func usageConfig() *Config {
+	return &Config{
+		Models: map[SelectedModelType]SelectedModel{
+			SelectedModelTypeLarge: {ConnectionID: "c-gw", Provider: "gw", Model: "llama3"},
+			SelectedModelTypeSmall: {Provider: "gw", Model: "llama3-mini"},
+		},
+		Providers: map[string]*ProviderConfig{
+			"gw":     {ProviderOptions: map[string]any{"region": "eu"}},
+			"openai": {APIKey: "sk-test"},
+		},
+		Connections: []Connection{
+			{ID: "c-gw", Name: "Gateway", ProviderID: "gw"},
+			{ID: "c-openai", Name: "OpenAI", ProviderID: "openai"},
+		},
+	}
+}
+
+func TestConfig_ProviderUsage(t *testing.T) {
+	cfg := usageConfig()
+
+	usage := cfg.ProviderUsage("gw")
+	if len(usage.Connections) != 1 || usage.Connections[0].ID != "c-gw" {
+		t.Errorf("Connections = %v, want the gateway connection", usage.Connections)
+	}
+	if want := []SelectedModelType{SelectedModelTypeLarge, SelectedModelTypeSmall}; !reflect.DeepEqual(usage.Tiers, want) {
+		t.Errorf("Tiers = %v, want %v", usage.Tiers, want)
+	}
+	if !usage.Settings {
+		t.Error("Settings = false, want the provider's cdd.json settings reported")
+	}
+
+	// A model selected through another connection is not served by gw, even
+	// if its provider field says so.
+	cfg.Models[SelectedModelTypeLarge] = SelectedModel{ConnectionID: "c-openai", Provider: "gw", Model: "gpt-4o"}
+	if got := cfg.ProviderUsage("gw").Tiers; !reflect.DeepEqual(got, []SelectedModelType{SelectedModelTypeSmall}) {
+		t.Errorf("Tiers = %v, want only the small model", got)
+	}
+
+	if !cfg.ProviderUsage("ollama").Empty() {
+		t.Error("an unused provider should have no usage")
+	}
+}
+
+func TestConfig_PurgeProvider(t *testing.T) {
+	cfg := usageConfig()
+
+	removed := cfg.PurgeProvider("gw")
+	if removed.Empty() {
+		t.Fatal("PurgeProvider() reported nothing removed")
+	}
+	if len(cfg.Connections) != 1 || cfg.Connections[0].ID != "c-openai" {
+		t.Errorf("Connections = %v, want only the OpenAI connection", cfg.Connections)
+	}
+	if len(cfg.Models) != 0 {
+		t.Errorf("Models = %v, want the selections removed", cfg.Models)
+	}
+	if _, ok := cfg.Providers["gw"]; ok {
+		t.Error("the provider's settings should be removed")
+	}
+	if !cfg.ProviderUsage("gw").Empty() {
+		t.Error("nothing should refer to the provider after the purge")
+	}
+}
+
+func TestConfig_ReassignProvider(t *testing.T) {
+	cfg := usageConfig()
+
+	cfg.ReassignProvider("gw", "openrouter")
+	if got := cfg.Connections[0]; got.ID != "c-gw" || got.ProviderID != "openrouter" {
+		t.Errorf("connection = %+v, want it moved to openrouter", got)
+	}
+	for _, tier := range []SelectedModelType{SelectedModelTypeLarge, SelectedModelTypeSmall} {
+		if got := cfg.Models[tier].Provider; got != "openrouter" {
+			t.Errorf("%s provider = %q, want openrouter", tier, got)
+		}
+	}
+	if got := cfg.Models[SelectedModelTypeLarge].ConnectionID; got != "c-gw" {
+		t.Errorf("large connection = %q, want it kept", got)
+	}
+	if !cfg.ProviderUsage("gw").Empty() {
+		t.Error("nothing should refer to the provider after the reassignment")
+	}
+}
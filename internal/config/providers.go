@@ -1,12 +1,15 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
-	"github.com/adrg/xdg"
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
 	"github.com/charmbracelet/catwalk/pkg/embedded"
 )
@@ -15,8 +18,12 @@ const (
 	providersCacheFile = "providers.json"
 	defaultCatwalkURL  = "https://catwalk.charm.sh"
 	cacheMaxAge        = 24 * time.Hour
+	fetchTimeout       = 10 * time.Second
 )
 
+// offline is set by SetOffline.
+var offline bool
+
 // ProvidersCache holds cached provider metadata from catwalk.
 type ProvidersCache struct {
 	UpdatedAt time.Time          `json:"updated_at"`
@@ -24,39 +31,88 @@ type ProvidersCache struct {
 }
 
 // LoadProviders loads provider metadata from catwalk.
-// It tries: 1) fetch from URL, 2) cached data, 3) embedded fallback.
+// It tries: 1) the cache while it is fresh, 2) fetch from URL, 3) cached data
+// of any age, 4) embedded fallback. Falling back sets a warning on cfg
+// instead of failing, so cdd still starts when catwalk is unreachable.
 func LoadProviders(cfg *Config) ([]catwalk.Provider, error) {
-	dataDir := cfg.DataDir()
-	cachePath := filepath.Join(dataDir, providersCacheFile)
-
-	// Try to fetch from catwalk API.
 	catwalkURL := os.Getenv("CATWALK_URL")
 	if catwalkURL == "" {
 		catwalkURL = defaultCatwalkURL
 	}
+	return loadProviders(cfg, catwalkURL, !Offline())
+}
 
-	client := catwalk.NewWithURL(catwalkURL)
-	providers, err := client.GetProviders()
-	if err == nil {
-		// Successfully fetched, update cache (ignore cache write errors).
-		if cacheErr := saveProvidersCache(cachePath, providers); cacheErr != nil {
-			// Cache write failure is non-fatal, continue with fetched data.
-			_ = cacheErr
-		}
-		return providers, nil
+// loadProviders loads provider metadata, fetching from catwalkURL only when
+// fetch is set and the cache is missing or older than cacheMaxAge.
+func loadProviders(cfg *Config, catwalkURL string, fetch bool) ([]catwalk.Provider, error) {
+	cachePath := getProvidersCachePath(cfg.DataDir())
+	cache, cacheErr := loadProvidersCache(cachePath)
+	if cacheErr == nil && (!fetch || time.Since(cache.UpdatedAt) < cacheMaxAge) {
+		return cache.Providers, nil
 	}
 
-	// Fetch failed, try cache.
-	if cache, err := loadProvidersCache(cachePath); err == nil {
-		if time.Since(cache.UpdatedAt) < cacheMaxAge {
-			return cache.Providers, nil
+	if !fetch {
+		if Offline() {
+			cfg.providersWarning = "Offline with no cached provider list; using the built-in one"
 		}
+		return embedded.GetAll(), nil
+	}
+
+	providers, err := fetchProviders(catwalkURL)
+	if err == nil {
+		// Cache write failure is non-fatal, continue with fetched data.
+		_ = saveProvidersCache(cachePath, providers) //nolint:errcheck // Intentionally ignoring cache write error.
+		return providers, nil
 	}
 
-	// Fall back to embedded providers.
+	if cacheErr == nil {
+		cfg.providersWarning = fmt.Sprintf("Provider list could not be updated (%v); using the one cached %s",
+			err, cache.UpdatedAt.Format("2006-01-02 15:04"))
+		return cache.Providers, nil
+	}
+	cfg.providersWarning = fmt.Sprintf("Provider list could not be fetched (%v); using the built-in one", err)
 	return embedded.GetAll(), nil
 }
 
+// fetchProviders gets the provider list from the catwalk service at baseURL.
+// The catwalk client has no timeout, so an unreachable service would hold up
+// startup indefinitely.
+func fetchProviders(baseURL string) ([]catwalk.Provider, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(baseURL, "/")+"/v2/providers", http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // Read-only response body.
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("catwalk returned %s", resp.Status)
+	}
+	var providers []catwalk.Provider
+	if err := json.NewDecoder(resp.Body).Decode(&providers); err != nil {
+		return nil, fmt.Errorf("decoding providers: %w", err)
+	}
+	return providers, nil
+}
+
+// SetOffline turns offline mode on or off. Offline, the provider list comes
+// from the cache or the embedded copy and catwalk is never contacted.
+func SetOffline(on bool) {
+	offline = on
+}
+
+// Offline reports whether offline mode is on, through SetOffline or
+// CDD_OFFLINE=1.
+func Offline() bool {
+	return offline || os.Getenv("CDD_OFFLINE") == "1"
+}
+
 // UpdateProviders fetches and caches provider metadata from the given source.
 // Source can be "embedded", an HTTP URL, or a local file path.
 func UpdateProviders(cfg *Config, source string) error {
@@ -67,8 +123,7 @@ func UpdateProviders(cfg *Config, source string) error {
 	case source == "embedded":
 		providers = embedded.GetAll()
 	case len(source) > 4 && source[:4] == "http":
-		client := catwalk.NewWithURL(source)
-		providers, err = client.GetProviders()
+		providers, err = fetchProviders(source)
 		if err != nil {
 			return err
 		}
@@ -83,9 +138,7 @@ func UpdateProviders(cfg *Config, source string) error {
 		}
 	}
 
-	dataDir := cfg.DataDir()
-	cachePath := filepath.Join(dataDir, providersCacheFile)
-	return saveProvidersCache(cachePath, providers)
+	return saveProvidersCache(getProvidersCachePath(cfg.DataDir()), providers)
 }
 
 // loadProvidersCache reads cached provider data.
@@ -123,7 +176,8 @@ func saveProvidersCache(path string, providers []catwalk.Provider) error {
 	return os.WriteFile(path, data, 0o600)
 }
 
-// DefaultDataDir returns the default data directory path.
+// DefaultDataDir returns the default data directory path of the active
+// profile.
 func DefaultDataDir() string {
-	return filepath.Join(xdg.DataHome, appName)
+	return activeDataDir()
 }
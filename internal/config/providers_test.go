@@ -2,8 +2,11 @@ package config
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -334,23 +337,100 @@ func TestLoadProviders_StaleCache(t *testing.T) {
 		t.Fatalf("LoadProviders() error = %v", err)
 	}
 
-	// Stale cache should be skipped, falling back to embedded.
-	// Embedded providers should have standard providers like openai, anthropic.
-	hasStandardProvider := false
-	for _, p := range providers {
-		if p.ID == testOpenAIProviderID || p.ID == testAnthropicProviderID {
-			hasStandardProvider = true
-			break
-		}
-	}
-	if !hasStandardProvider && len(providers) > 0 {
-		// If we got providers but none are standard, that's fine - embedded might have different ones.
-		// Just ensure we didn't get the stale "stale-provider".
-		for _, p := range providers {
-			if p.ID == "stale-provider" {
-				t.Error("Loaded stale provider instead of falling back to embedded")
-			}
-		}
+	// An unreachable catwalk should not throw away the list it last served.
+	if len(providers) != 1 || providers[0].ID != "stale-provider" {
+		t.Errorf("providers = %v, want the stale cache", providers)
+	}
+	if !strings.Contains(cfg.ProvidersWarning(), "could not be updated") {
+		t.Errorf("warning = %q, want a note that the list is out of date", cfg.ProvidersWarning())
+	}
+}
+
+func TestLoadProviders_CacheTTL(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.Write([]byte(`[{"id": "fetched-provider"}]`)) //nolint:errcheck,gosec // Test server
+	}))
+	defer server.Close()
+	t.Setenv("CATWALK_URL", server.URL)
+
+	tempDir := t.TempDir()
+	cfg := NewConfig()
+	cfg.Options = &Options{DataDir: tempDir}
+	cachePath := filepath.Join(tempDir, "providers.json")
+	if err := saveProvidersCache(cachePath, []catwalk.Provider{{ID: "cached-provider"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	providers, err := LoadProviders(cfg)
+	if err != nil {
+		t.Fatalf("LoadProviders() error = %v", err)
+	}
+	if requests != 0 || providers[0].ID != "cached-provider" {
+		t.Errorf("got %v after %d requests, want the fresh cache without a fetch", providers, requests)
+	}
+
+	cache := ProvidersCache{UpdatedAt: time.Now().Add(-48 * time.Hour), Providers: providers}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cachePath, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	providers, err = LoadProviders(cfg)
+	if err != nil {
+		t.Fatalf("LoadProviders() error = %v", err)
+	}
+	if requests != 1 || providers[0].ID != "fetched-provider" {
+		t.Errorf("got %v after %d requests, want an expired cache refetched", providers, requests)
+	}
+	if cfg.ProvidersWarning() != "" {
+		t.Errorf("warning = %q, want none after a successful fetch", cfg.ProvidersWarning())
+	}
+	if refreshed, err := loadProvidersCache(cachePath); err != nil || time.Since(refreshed.UpdatedAt) > time.Minute {
+		t.Error("a successful fetch should refresh the cache")
+	}
+}
+
+func TestLoadProviders_Offline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("offline mode should not contact catwalk")
+	}))
+	defer server.Close()
+	t.Setenv("CATWALK_URL", server.URL)
+	t.Setenv("CDD_OFFLINE", "1")
+
+	tempDir := t.TempDir()
+	cfg := NewConfig()
+	cfg.Options = &Options{DataDir: tempDir}
+
+	providers, err := LoadProviders(cfg)
+	if err != nil {
+		t.Fatalf("LoadProviders() error = %v", err)
+	}
+	if len(providers) == 0 || cfg.ProvidersWarning() == "" {
+		t.Errorf("got %d providers and warning %q, want the embedded list with a warning", len(providers), cfg.ProvidersWarning())
+	}
+
+	// Any cached list is used offline, however old.
+	cache := ProvidersCache{UpdatedAt: time.Now().Add(-30 * 24 * time.Hour), Providers: []catwalk.Provider{{ID: "old-provider"}}}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "providers.json"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg = NewConfig()
+	cfg.Options = &Options{DataDir: tempDir}
+	providers, err = LoadProviders(cfg)
+	if err != nil {
+		t.Fatalf("LoadProviders() error = %v", err)
+	}
+	if len(providers) != 1 || providers[0].ID != "old-provider" {
+		t.Errorf("providers = %v, want the old cache", providers)
 	}
 }
 
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// authSchemes are Authorization header schemes kept in front of redacted credentials.
+var authSchemes = []string{"Bearer ", "Basic ", "Token "}
+
+// RedactCustomProviders returns copies of providers with the credentials in
+// their headers replaced by environment variable placeholders, so that the
+// result can be shared safely. It also returns the placeholders introduced,
+// sorted, which must be set wherever the providers are imported.
+func RedactCustomProviders(providers []CustomProvider) ([]CustomProvider, []string) {
+	redacted := make([]CustomProvider, len(providers))
+	var placeholders []string
+	for i := range providers {
+		redacted[i] = providers[i]
+		if len(providers[i].DefaultHeaders) == 0 {
+			continue
+		}
+		headers := maps.Clone(providers[i].DefaultHeaders)
+		for name, value := range headers {
+			if !isSecretHeader(name) {
+				continue
+			}
+			scheme, secret := splitAuthScheme(value)
+			if secret == "" || strings.HasPrefix(secret, "$") {
+				continue
+			}
+			placeholder := "$" + secretEnvVar(providers[i].ID, name)
+			headers[name] = scheme + placeholder
+			placeholders = append(placeholders, placeholder)
+		}
+		redacted[i].DefaultHeaders = headers
+	}
+	slices.Sort(placeholders)
+	return redacted, placeholders
+}
+
+// isSecretHeader reports whether a header usually carries a credential.
+func isSecretHeader(name string) bool {
+	name = strings.ToLower(name)
+	switch name {
+	case "authorization", "proxy-authorization", "cookie":
+		return true
+	}
+	return strings.Contains(name, "key") || strings.Contains(name, "token") || strings.Contains(name, "secret")
+}
+
+// splitAuthScheme splits an authorization scheme such as "Bearer " from the credential.
+func splitAuthScheme(value string) (scheme, secret string) {
+	for _, s := range authSchemes {
+		if len(value) > len(s) && strings.EqualFold(value[:len(s)], s) {
+			return value[:len(s)], value[len(s):]
+		}
+	}
+	return "", value
+}
+
+// secretEnvVar names the environment variable holding a redacted header, e.g.
+// MY_PROXY_API_KEY for the Authorization header of provider "my-proxy".
+func secretEnvVar(providerID, header string) string {
+	suffix := header
+	if strings.EqualFold(header, "authorization") {
+		suffix = "api-key"
+	}
+	return envVarName(providerID) + "_" + envVarName(suffix)
+}
+
+// isSecretPlaceholder reports whether value is the placeholder a redacted
+// export leaves for the header of provider, as $VAR or ${VAR}.
+func isSecretPlaceholder(value, providerID, header string) bool {
+	name := secretEnvVar(providerID, header)
+	return value == "$"+name || value == "${"+name+"}"
+}
+
+// envVarName upper-cases s and replaces anything but letters and digits with underscores.
+func envVarName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, s)
+}
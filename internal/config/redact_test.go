@@ -0,0 +1,50 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRedactCustomProviders(t *testing.T) {
+	providers := []CustomProvider{{
+		ID: "my-proxy",
+		DefaultHeaders: map[string]string{
+			"Authorization": "Bearer sk-live-123",
+			"X-API-Key":     "abc",
+			"X-Team":        "platform",
+		},
+	}, {
+		ID: "env-proxy",
+		DefaultHeaders: map[string]string{
+			"Authorization": "Bearer $ENV_PROXY_TOKEN",
+		},
+	}, {
+		ID: "plain",
+	}}
+
+	redacted, placeholders := RedactCustomProviders(providers)
+
+	headers := redacted[0].DefaultHeaders
+	if got := headers["Authorization"]; got != "Bearer $MY_PROXY_API_KEY" {
+		t.Errorf("Authorization = %q, want Bearer $MY_PROXY_API_KEY", got)
+	}
+	if got := headers["X-API-Key"]; got != "$MY_PROXY_X_API_KEY" {
+		t.Errorf("X-API-Key = %q, want $MY_PROXY_X_API_KEY", got)
+	}
+	if got := headers["X-Team"]; got != "platform" {
+		t.Errorf("X-Team = %q, want platform", got)
+	}
+	if got := redacted[1].DefaultHeaders["Authorization"]; got != "Bearer $ENV_PROXY_TOKEN" {
+		t.Errorf("existing placeholder changed to %q", got)
+	}
+
+	want := []string{"$MY_PROXY_API_KEY", "$MY_PROXY_X_API_KEY"}
+	if !slices.Equal(placeholders, want) {
+		t.Errorf("placeholders = %v, want %v", placeholders, want)
+	}
+
+	// The input is left untouched.
+	if providers[0].DefaultHeaders["Authorization"] != "Bearer sk-live-123" {
+		t.Error("RedactCustomProviders() modified its input")
+	}
+}
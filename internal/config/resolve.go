@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -70,3 +71,33 @@ func (r *Resolver) MustResolve(value string) string {
 	}
 	return resolved
 }
+
+// minEnvSecretLength is the shortest value VarWithValue matches, so that a
+// short key doesn't match an unrelated variable such as SHLVL=1.
+const minEnvSecretLength = 8
+
+// VarWithValue returns the name of an environment variable holding exactly
+// value, or "" if none does. Names that look like credentials (containing
+// KEY or TOKEN) are preferred when several variables match.
+func (r *Resolver) VarWithValue(value string) string {
+	if len(value) < minEnvSecretLength || strings.HasPrefix(value, "$") {
+		return ""
+	}
+	var names []string
+	for name, v := range r.env {
+		if v == value {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		upper := strings.ToUpper(name)
+		if strings.Contains(upper, "KEY") || strings.Contains(upper, "TOKEN") {
+			return name
+		}
+	}
+	return names[0]
+}
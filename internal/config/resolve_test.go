@@ -236,3 +236,31 @@ func TestResolver_Resolve_VariableNamePatterns(t *testing.T) {
 		})
 	}
 }
+
+func TestResolver_VarWithValue(t *testing.T) {
+	r := NewResolverWithEnv(map[string]string{
+		"SHLVL":          "1",
+		"BACKUP_SECRET":  "sk-test-12345678",
+		"OPENAI_API_KEY": "sk-test-12345678",
+		"OTHER":          "other-value-123",
+	})
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"prefers credential names", "sk-test-12345678", "OPENAI_API_KEY"},
+		{"any name otherwise", "other-value-123", "OTHER"},
+		{"no match", "sk-unknown-key", ""},
+		{"too short to match", "1", ""},
+		{"already a reference", "$OPENAI_API_KEY", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.VarWithValue(tt.value); got != tt.want {
+				t.Errorf("VarWithValue(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/guilhermegouw/cdd/internal/oauth"
 )
@@ -15,6 +16,7 @@ type SaveConfig struct {
 	Models      map[SelectedModelType]SelectedModel `json:"models,omitempty"`
 	Providers   map[string]*SaveProviderConfig      `json:"providers,omitempty"`
 	Connections []Connection                        `json:"connections,omitempty"`
+	LSP         map[string]LSPConfig                `json:"lsp,omitempty"`
 	Options     *Options                            `json:"options,omitempty"`
 }
 
@@ -22,8 +24,10 @@ type SaveConfig struct {
 // It stores the API key template (e.g., "$OPENAI_API_KEY") rather than resolved values.
 // Custom providers are stored separately in custom-providers.json via CustomProviderManager.
 type SaveProviderConfig struct {
-	OAuthToken *oauth.Token `json:"oauth,omitempty"`
-	APIKey     string       `json:"api_key,omitempty"`
+	ProviderOptions map[string]any `json:"provider_options,omitempty"`
+	HTTP            *HTTPOptions   `json:"http,omitempty"`
+	OAuthToken      *oauth.Token   `json:"oauth,omitempty"`
+	APIKey          string         `json:"api_key,omitempty"`
 }
 
 // Save writes the configuration to the global config file.
@@ -32,42 +36,73 @@ func Save(cfg *Config) error {
 }
 
 // SaveToFile writes the configuration to a specific file path.
+// Credentials are stored in the OS keyring when one is available, leaving
+// only references in the file.
 func SaveToFile(cfg *Config, path string) error {
+	_, err := writeConfig(cfg, path)
+	return err
+}
+
+// writeConfig writes the configuration to path and returns how many
+// credentials were moved into the keyring.
+func writeConfig(cfg *Config, path string) (int, error) {
 	// Ensure the directory exists.
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0o750); err != nil {
-		return fmt.Errorf("creating config directory: %w", err)
+		return 0, fmt.Errorf("creating config directory: %w", err)
 	}
 
-	// Create a minimal save config.
+	// Create a minimal save config. Connections are copied so that storing
+	// credentials in the keyring does not touch the in-memory values.
 	saveCfg := &SaveConfig{
 		Models:      cfg.Models,
 		Providers:   make(map[string]*SaveProviderConfig),
-		Connections: cfg.Connections,
+		Connections: slices.Clone(cfg.Connections),
+		LSP:         cfg.LSP,
 		Options:     cfg.Options,
 	}
 
-	// Save provider configs (only API key/OAuth for standard providers).
+	// Save provider configs (only credentials, provider options and HTTP settings for standard providers).
+	// Provider options carry settings such as the Vertex AI project and location.
 	// Custom providers are stored separately via CustomProviderManager.
 	for id, p := range cfg.Providers {
-		if p.APIKey != "" || p.OAuthToken != nil {
+		if p.hasSavedSettings() {
 			saveCfg.Providers[id] = &SaveProviderConfig{
-				APIKey:     p.APIKey,
-				OAuthToken: p.OAuthToken,
+				ProviderOptions: p.ProviderOptions,
+				HTTP:            p.HTTP,
+				APIKey:          p.savedAPIKey(),
+				OAuthToken:      p.OAuthToken,
 			}
 		}
 	}
+	moved := protectSaveConfig(saveCfg)
 
 	data, err := json.MarshalIndent(saveCfg, "", "  ")
 	if err != nil {
-		return fmt.Errorf("marshaling config: %w", err)
+		return 0, fmt.Errorf("marshaling config: %w", err)
 	}
 
+	recordWrite(path, data)
 	if err := os.WriteFile(path, data, 0o600); err != nil { //nolint:gosec // Restrictive permissions for security.
-		return fmt.Errorf("writing config file: %w", err)
+		return 0, fmt.Errorf("writing config file: %w", err)
 	}
 
-	return nil
+	return moved, nil
+}
+
+// hasSavedSettings reports whether the provider has anything to write to
+// cdd.json.
+func (pc *ProviderConfig) hasSavedSettings() bool {
+	return pc.APIKey != "" || pc.OAuthToken != nil || len(pc.ProviderOptions) > 0 || pc.HTTP != nil
+}
+
+// savedAPIKey returns the API key to write to disk: the environment variable
+// reference it was loaded from, unless the key has been changed since.
+func (pc *ProviderConfig) savedAPIKey() string {
+	if pc.apiKeyRef != "" && pc.APIKey == pc.apiKeyResolved {
+		return pc.apiKeyRef
+	}
+	return pc.APIKey
 }
 
 // SaveWizardResult saves the result of the setup wizard with API key authentication.
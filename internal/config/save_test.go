@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+
 	"github.com/guilhermegouw/cdd/internal/oauth"
 )
 
@@ -80,7 +82,7 @@ func TestSaveToFile_CreatesDirectory(t *testing.T) {
 	}
 }
 
-func TestSaveToFile_OnlySavesProvidersWithAPIKeyOrOAuth(t *testing.T) {
+func TestSaveToFile_OnlySavesProvidersWithCredentialsOrOptions(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "config.json")
 
@@ -96,6 +98,14 @@ func TestSaveToFile_OnlySavesProvidersWithAPIKeyOrOAuth(t *testing.T) {
 	cfg.Providers["empty"] = &ProviderConfig{
 		ID: "empty",
 	}
+	cfg.Providers["with-options"] = &ProviderConfig{
+		ID:              "with-options",
+		ProviderOptions: map[string]any{"project": "$VERTEXAI_PROJECT"},
+	}
+	cfg.Providers["with-http"] = &ProviderConfig{
+		ID:   "with-http",
+		HTTP: &HTTPOptions{CACertFile: "/etc/ssl/corp.pem"},
+	}
 
 	err := SaveToFile(cfg, configPath)
 	if err != nil {
@@ -121,6 +131,50 @@ func TestSaveToFile_OnlySavesProvidersWithAPIKeyOrOAuth(t *testing.T) {
 	if saved.Providers["empty"] != nil {
 		t.Error("Provider 'empty' should not be saved")
 	}
+	if p := saved.Providers["with-options"]; p == nil || p.ProviderOptions["project"] != "$VERTEXAI_PROJECT" {
+		t.Errorf("Provider 'with-options' should be saved with its options, got %+v", p)
+	}
+	if p := saved.Providers["with-http"]; p == nil || p.HTTP == nil || p.HTTP.CACertFile != "/etc/ssl/corp.pem" {
+		t.Errorf("Provider 'with-http' should be saved with its HTTP settings, got %+v", p)
+	}
+}
+
+func TestSaveToFile_KeepsEnvReferences(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	resolver := NewResolverWithEnv(map[string]string{"OPENAI_API_KEY": "sk-from-env"})
+
+	cfg := NewConfig()
+	cfg.Providers["openai"] = &ProviderConfig{APIKey: "$OPENAI_API_KEY"}
+	cfg.Providers["groq"] = &ProviderConfig{APIKey: "$OPENAI_API_KEY"}
+	for _, id := range []string{"openai", "groq"} {
+		p := &catwalk.Provider{ID: catwalk.InferenceProvider(id), APIEndpoint: "https://api.example.com"}
+		if !configureProviderAuth(cfg, cfg.Providers[id], p, resolver) {
+			t.Fatalf("configureProviderAuth(%s) removed the provider", id)
+		}
+	}
+	if cfg.Providers["openai"].APIKey != "sk-from-env" {
+		t.Fatalf("APIKey = %q, want the resolved key in memory", cfg.Providers["openai"].APIKey)
+	}
+	// A key changed after loading is saved as given.
+	cfg.Providers["groq"].APIKey = "$GROQ_API_KEY"
+
+	if err := SaveToFile(cfg, configPath); err != nil {
+		t.Fatalf("SaveToFile() error = %v", err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved SaveConfig
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if got := saved.Providers["openai"].APIKey; got != "$OPENAI_API_KEY" {
+		t.Errorf("saved openai APIKey = %q, want the reference", got)
+	}
+	if got := saved.Providers["groq"].APIKey; got != "$GROQ_API_KEY" {
+		t.Errorf("saved groq APIKey = %q, want the changed key", got)
+	}
 }
 
 func TestSaveWizardResult(t *testing.T) {
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/guilhermegouw/cdd/internal/oauth"
+	"github.com/guilhermegouw/cdd/internal/secrets"
+)
+
+// secretStore holds the credentials referenced from the config file.
+// Tests swap it for an in-memory store.
+var secretStore secrets.Store = secrets.NewKeyring()
+
+// secretKey builds the keyring key for a credential, e.g. "connections/<id>/api_key".
+func secretKey(parts ...string) string {
+	return strings.Join(parts, "/")
+}
+
+// credentialPrefix builds the keyring prefix for the credentials of a
+// connection or provider. Named profiles get a namespace of their own, so the
+// same provider can hold a different key in each profile.
+func credentialPrefix(kind, id string) string {
+	if activeProfile != DefaultProfile {
+		return secretKey(profilesDir, activeProfile, kind, id)
+	}
+	return secretKey(kind, id)
+}
+
+// protectSecret stores value in the keyring under key and returns the reference
+// to write to disk instead. Empty values, environment variable references, and
+// existing keyring references are returned unchanged, as is the plaintext value
+// when the keyring cannot be written. The boolean reports whether the value moved.
+func protectSecret(key, value string) (string, bool) {
+	if value == "" || strings.HasPrefix(value, "$") || secrets.IsRef(value) {
+		return value, false
+	}
+	if err := secretStore.Set(key, value); err != nil {
+		return value, false
+	}
+	return secrets.Ref(key), true
+}
+
+// protectToken returns a copy of token whose access and refresh tokens are keyring references.
+func protectToken(prefix string, token *oauth.Token) (*oauth.Token, int) {
+	if token == nil {
+		return nil, 0
+	}
+	protected := *token
+	moved := 0
+	var ok bool
+	if protected.AccessToken, ok = protectSecret(secretKey(prefix, "access_token"), token.AccessToken); ok {
+		moved++
+	}
+	if protected.RefreshToken, ok = protectSecret(secretKey(prefix, "refresh_token"), token.RefreshToken); ok {
+		moved++
+	}
+	return &protected, moved
+}
+
+// protectSaveConfig moves the credentials of saveCfg into the keyring, in place,
+// and returns how many were moved. Connections and tokens must already be copies
+// of the in-memory config, which keeps its resolved values.
+func protectSaveConfig(saveCfg *SaveConfig) int {
+	moved := 0
+	var ok bool
+	for i := range saveCfg.Connections {
+		conn := &saveCfg.Connections[i]
+		if conn.ID == "" {
+			continue
+		}
+		prefix := credentialPrefix("connections", conn.ID)
+		if conn.APIKey, ok = protectSecret(secretKey(prefix, "api_key"), conn.APIKey); ok {
+			moved++
+		}
+		var n int
+		conn.OAuthToken, n = protectToken(secretKey(prefix, "oauth"), conn.OAuthToken)
+		moved += n
+	}
+	for id, p := range saveCfg.Providers {
+		prefix := credentialPrefix("providers", id)
+		if p.APIKey, ok = protectSecret(secretKey(prefix, "api_key"), p.APIKey); ok {
+			moved++
+		}
+		var n int
+		p.OAuthToken, n = protectToken(secretKey(prefix, "oauth"), p.OAuthToken)
+		moved += n
+	}
+	return moved
+}
+
+// resolveSecret returns the secret a keyring reference points to. References
+// that cannot be resolved are kept as-is so that saving does not lose them.
+func resolveSecret(value string) string {
+	resolved, err := secrets.Resolve(secretStore, value)
+	if err != nil {
+		return value
+	}
+	return resolved
+}
+
+// resolveToken resolves the keyring references of an OAuth token in place.
+func resolveToken(token *oauth.Token) {
+	if token == nil {
+		return
+	}
+	token.AccessToken = resolveSecret(token.AccessToken)
+	token.RefreshToken = resolveSecret(token.RefreshToken)
+}
+
+// resolveSecrets replaces the keyring references in cfg with the stored secrets.
+func resolveSecrets(cfg *Config) {
+	for i := range cfg.Connections {
+		cfg.Connections[i].APIKey = resolveSecret(cfg.Connections[i].APIKey)
+		resolveToken(cfg.Connections[i].OAuthToken)
+	}
+	for _, p := range cfg.Providers {
+		p.APIKey = resolveSecret(p.APIKey)
+		resolveToken(p.OAuthToken)
+	}
+}
+
+// MigrateSecretsToKeyring moves the plaintext credentials of the global config
+// file into the OS keyring, leaving references in their place. It returns the
+// number of secrets moved.
+func MigrateSecretsToKeyring() (int, error) {
+	if !secrets.Available(secretStore) {
+		return 0, fmt.Errorf("no OS keyring available")
+	}
+
+	cfg, err := loadExistingConfig()
+	if err != nil {
+		return 0, fmt.Errorf("loading config: %w", err)
+	}
+
+	moved, err := writeConfig(cfg, GlobalConfigPath())
+	if err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+// minSecretLength is the shortest credential Secrets returns; shorter values
+// would match ordinary words in the text being redacted.
+const minSecretLength = 8
+
+// Secrets returns the credentials in the configuration, with environment
+// variable references resolved and the longest first, so that text leaving
+// the machine can be scrubbed of them.
+func (c *Config) Secrets() []string {
+	resolver := NewResolver()
+	seen := make(map[string]bool)
+	var found []string
+	add := func(values ...string) {
+		for _, v := range values {
+			if resolved, err := resolver.Resolve(v); err == nil {
+				v = resolved
+			}
+			_, v = splitAuthScheme(v)
+			if len(v) >= minSecretLength && !seen[v] {
+				seen[v] = true
+				found = append(found, v)
+			}
+		}
+	}
+	addToken := func(token *oauth.Token) {
+		if token != nil {
+			add(token.AccessToken, token.RefreshToken)
+		}
+	}
+	addHeaders := func(headers map[string]string) {
+		for name, value := range headers {
+			if isSecretHeader(name) {
+				add(value)
+			}
+		}
+	}
+
+	for i := range c.Connections {
+		add(c.Connections[i].APIKey)
+		addToken(c.Connections[i].OAuthToken)
+		addHeaders(c.Connections[i].ExtraHeaders)
+	}
+	for _, p := range c.Providers {
+		add(p.APIKey)
+		addToken(p.OAuthToken)
+		addHeaders(p.ExtraHeaders)
+	}
+	if c.Options != nil && c.Options.Index != nil {
+		add(c.Options.Index.APIKey)
+	}
+	if i := c.Integrations; i != nil {
+		if i.GitHub != nil {
+			add(i.GitHub.Token)
+		}
+		if i.Paste != nil {
+			addHeaders(i.Paste.Headers)
+		}
+		if i.Jira != nil {
+			add(i.Jira.Token)
+		}
+		if i.Linear != nil {
+			add(i.Linear.Token)
+		}
+	}
+
+	slices.SortFunc(found, func(a, b string) int { return cmp.Compare(len(b), len(a)) })
+	return found
+}
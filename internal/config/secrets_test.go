@@ -0,0 +1,181 @@
+//nolint:goconst,gosec // Test file uses repeated string literals and reads test files.
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/guilhermegouw/cdd/internal/oauth"
+	"github.com/guilhermegouw/cdd/internal/secrets"
+)
+
+func TestMain(m *testing.M) {
+	// Never touch the real OS keyring from tests.
+	secretStore = secrets.Unavailable{}
+	os.Exit(m.Run())
+}
+
+// useMemoryStore replaces the secret store with an in-memory one for the test.
+func useMemoryStore(t *testing.T) *secrets.Memory {
+	t.Helper()
+	store := secrets.NewMemory()
+	previous := secretStore
+	secretStore = store
+	t.Cleanup(func() { secretStore = previous })
+	return store
+}
+
+func TestSaveToFile_StoresSecretsInKeyring(t *testing.T) {
+	store := useMemoryStore(t)
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	cfg := NewConfig()
+	cfg.Connections = []Connection{{
+		ID:         "conn-1",
+		Name:       "work",
+		ProviderID: "openai",
+		APIKey:     "sk-secret",
+	}, {
+		ID:         "conn-2",
+		Name:       "env",
+		ProviderID: "openai",
+		APIKey:     "$OPENAI_API_KEY",
+	}, {
+		ID:         "conn-3",
+		Name:       "claude",
+		ProviderID: "anthropic",
+		OAuthToken: &oauth.Token{AccessToken: "access", RefreshToken: "refresh"},
+	}}
+
+	if err := SaveToFile(cfg, configPath); err != nil {
+		t.Fatalf("SaveToFile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("reading config: %v", err)
+	}
+	for _, secret := range []string{"sk-secret", `"access"`, `"refresh"`} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("config file contains plaintext %s", secret)
+		}
+	}
+	if !strings.Contains(string(data), "$OPENAI_API_KEY") {
+		t.Error("environment variable reference should be kept as-is")
+	}
+
+	if got, _ := store.Get("connections/conn-1/api_key"); got != "sk-secret" {
+		t.Errorf("keyring api_key = %q, want sk-secret", got)
+	}
+	if got, _ := store.Get("connections/conn-3/oauth/refresh_token"); got != "refresh" {
+		t.Errorf("keyring refresh_token = %q, want refresh", got)
+	}
+
+	// The in-memory config keeps the resolved values.
+	if cfg.Connections[0].APIKey != "sk-secret" || cfg.Connections[2].OAuthToken.AccessToken != "access" {
+		t.Error("SaveToFile() modified the in-memory config")
+	}
+
+	loaded := NewConfig()
+	if err := loadFile(configPath, loaded); err != nil {
+		t.Fatalf("loadFile() error = %v", err)
+	}
+	resolveSecrets(loaded)
+	if loaded.Connections[0].APIKey != "sk-secret" {
+		t.Errorf("resolved api_key = %q, want sk-secret", loaded.Connections[0].APIKey)
+	}
+	if loaded.Connections[2].OAuthToken.RefreshToken != "refresh" {
+		t.Errorf("resolved refresh_token = %q, want refresh", loaded.Connections[2].OAuthToken.RefreshToken)
+	}
+}
+
+func TestSaveToFile_FallsBackToPlaintext(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	cfg := NewConfig()
+	cfg.Providers["openai"] = &ProviderConfig{ID: "openai", APIKey: "sk-plain"}
+
+	if err := SaveToFile(cfg, configPath); err != nil {
+		t.Fatalf("SaveToFile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("reading config: %v", err)
+	}
+	if !strings.Contains(string(data), "sk-plain") {
+		t.Error("expected plaintext api_key without a keyring")
+	}
+}
+
+func TestResolveSecrets_KeepsUnresolvedRefs(t *testing.T) {
+	useMemoryStore(t)
+
+	cfg := NewConfig()
+	cfg.Connections = []Connection{{ID: "conn-1", APIKey: secrets.Ref("connections/conn-1/api_key")}}
+	resolveSecrets(cfg)
+
+	if !secrets.IsRef(cfg.Connections[0].APIKey) {
+		t.Errorf("api_key = %q, want the unresolved reference", cfg.Connections[0].APIKey)
+	}
+}
+
+func TestMigrateSecretsToKeyring(t *testing.T) {
+	store := useMemoryStore(t)
+	configPath := filepath.Join(t.TempDir(), "cdd.json")
+	SetGlobalConfigPath(configPath)
+	t.Cleanup(func() { SetGlobalConfigPath("") })
+
+	plain := `{"connections":[{"id":"conn-1","name":"work","provider_id":"openai","api_key":"sk-secret"}],` +
+		`"providers":{"openai":{"api_key":"sk-legacy"}}}`
+	if err := os.WriteFile(configPath, []byte(plain), 0o600); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+
+	moved, err := MigrateSecretsToKeyring()
+	if err != nil {
+		t.Fatalf("MigrateSecretsToKeyring() error = %v", err)
+	}
+	if moved != 2 {
+		t.Errorf("moved = %d, want 2", moved)
+	}
+	if got, _ := store.Get("providers/openai/api_key"); got != "sk-legacy" {
+		t.Errorf("keyring provider api_key = %q, want sk-legacy", got)
+	}
+
+	// A second run finds nothing left to move.
+	moved, err = MigrateSecretsToKeyring()
+	if err != nil {
+		t.Fatalf("second MigrateSecretsToKeyring() error = %v", err)
+	}
+	if moved != 0 {
+		t.Errorf("second run moved = %d, want 0", moved)
+	}
+}
+
+func TestMigrateSecretsToKeyring_NoKeyring(t *testing.T) {
+	if _, err := MigrateSecretsToKeyring(); err == nil {
+		t.Error("expected error without a keyring")
+	}
+}
+
+func TestConfigSecrets(t *testing.T) {
+	t.Setenv("CDD_TEST_GITHUB_TOKEN", "ghp_token_from_env")
+	cfg := &Config{
+		Providers: map[string]*ProviderConfig{"proxy": {APIKey: "provider-key-1", ExtraHeaders: map[string]string{"Authorization": "Bearer header-secret", "User-Agent": "cdd-agent"}}},
+		Connections: []Connection{
+			{ID: "a", APIKey: "provider-key-1"},
+			{ID: "b", APIKey: "short"},
+		},
+		Integrations: &IntegrationsConfig{GitHub: &GitHubIntegration{Token: "$CDD_TEST_GITHUB_TOKEN"}},
+	}
+
+	got := cfg.Secrets()
+	want := []string{"ghp_token_from_env", "provider-key-1", "header-secret"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Secrets() = %v, want %v (deduplicated, resolved, longest first)", got, want)
+	}
+}
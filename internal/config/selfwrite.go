@@ -0,0 +1,30 @@
+package config
+
+import (
+	"crypto/sha256"
+	"path/filepath"
+	"sync"
+)
+
+// ownWrites holds a hash of what this process last wrote to each config
+// file, so a watcher can tell cdd's own saves, such as a model switch or a
+// token refresh, from edits made elsewhere.
+var ownWrites = struct {
+	mu     sync.Mutex
+	hashes map[string][sha256.Size]byte
+}{hashes: make(map[string][sha256.Size]byte)}
+
+// recordWrite notes that this process is writing data to path.
+func recordWrite(path string, data []byte) {
+	ownWrites.mu.Lock()
+	defer ownWrites.mu.Unlock()
+	ownWrites.hashes[filepath.Clean(path)] = sha256.Sum256(data)
+}
+
+// IsOwnWrite reports whether data is what this process last wrote to path.
+func IsOwnWrite(path string, data []byte) bool {
+	ownWrites.mu.Lock()
+	defer ownWrites.mu.Unlock()
+	hash, ok := ownWrites.hashes[filepath.Clean(path)]
+	return ok && hash == sha256.Sum256(data)
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// snapshotsDir holds the named configuration snapshots in the data directory.
+	snapshotsDir = "config-snapshots"
+	// snapshotMetaFile records when a snapshot was taken and what it holds.
+	snapshotMetaFile = "snapshot.json"
+	// PreRestoreSnapshot is the snapshot Restore takes of the configuration it
+	// replaces, so a restore can itself be undone.
+	PreRestoreSnapshot = "pre-restore"
+)
+
+// ErrSnapshotExists is returned when saving over a snapshot without overwrite.
+var ErrSnapshotExists = errors.New("snapshot already exists")
+
+// Snapshot describes a saved copy of the configuration.
+type Snapshot struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     []string  `json:"files"` // The files that existed when it was taken
+}
+
+// Snapshots saves and restores named copies of the global cdd.json and
+// custom-providers.json, so provider setups can be tried out and put back.
+// Credentials kept in the keyring are referenced by the copies, not copied.
+type Snapshots struct {
+	dir   string
+	files map[string]string // File name in a snapshot -> the file it copies
+}
+
+// NewSnapshots creates the snapshots of the active profile, kept in its
+// default data directory. dataDir is where custom-providers.json lives; empty
+// means the default data directory too.
+func NewSnapshots(dataDir string) *Snapshots {
+	if dataDir == "" {
+		dataDir = activeDataDir()
+	}
+	return &Snapshots{
+		dir: filepath.Join(activeDataDir(), snapshotsDir),
+		files: map[string]string{
+			appName + ".json":   GlobalConfigPath(),
+			customProvidersFile: filepath.Join(dataDir, customProvidersFile),
+		},
+	}
+}
+
+// Save copies the current configuration into the snapshot name. An existing
+// snapshot is only replaced when overwrite is set.
+func (s *Snapshots) Save(name string, overwrite bool) (Snapshot, error) {
+	if err := checkSnapshotName(name); err != nil {
+		return Snapshot{}, err
+	}
+	dir := s.path(name)
+	if _, err := os.Stat(dir); err == nil {
+		if !overwrite {
+			return Snapshot{}, fmt.Errorf("%w: %s", ErrSnapshotExists, name)
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return Snapshot{}, fmt.Errorf("replacing snapshot: %w", err)
+		}
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return Snapshot{}, fmt.Errorf("creating snapshot directory: %w", err)
+	}
+
+	snap := Snapshot{Name: name, CreatedAt: time.Now(), Files: []string{}}
+	for _, file := range s.fileNames() {
+		data, err := os.ReadFile(s.files[file]) //nolint:gosec // Paths are the config files
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("reading %s: %w", file, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), data, 0o600); err != nil {
+			return Snapshot{}, fmt.Errorf("saving %s: %w", file, err)
+		}
+		snap.Files = append(snap.Files, file)
+	}
+
+	meta, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("encoding snapshot: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, snapshotMetaFile), meta, 0o600); err != nil {
+		return Snapshot{}, fmt.Errorf("saving snapshot: %w", err)
+	}
+	return snap, nil
+}
+
+// Restore puts the configuration saved as name back in place. Files the
+// snapshot does not have are removed, so the result matches it exactly. The
+// configuration replaced is saved as PreRestoreSnapshot first.
+func (s *Snapshots) Restore(name string) (Snapshot, error) {
+	snap, err := s.Get(name)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if name != PreRestoreSnapshot {
+		if _, err := s.Save(PreRestoreSnapshot, true); err != nil {
+			return Snapshot{}, fmt.Errorf("saving the current configuration: %w", err)
+		}
+	}
+
+	saved := make(map[string]bool, len(snap.Files))
+	for _, file := range snap.Files {
+		saved[file] = true
+	}
+	for _, file := range s.fileNames() {
+		target := s.files[file]
+		if !saved[file] {
+			if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return Snapshot{}, fmt.Errorf("removing %s: %w", file, err)
+			}
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.path(name), file)) //nolint:gosec // Path is built from the data directory
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("reading snapshot: %w", err)
+		}
+		if err := writeFileAtomic(target, data); err != nil {
+			return Snapshot{}, fmt.Errorf("restoring %s: %w", file, err)
+		}
+	}
+	return snap, nil
+}
+
+// Get returns the snapshot called name.
+func (s *Snapshots) Get(name string) (Snapshot, error) {
+	if err := checkSnapshotName(name); err != nil {
+		return Snapshot{}, err
+	}
+	data, err := os.ReadFile(filepath.Join(s.path(name), snapshotMetaFile)) //nolint:gosec // Path is built from the data directory
+	if errors.Is(err, os.ErrNotExist) {
+		return Snapshot{}, fmt.Errorf("snapshot %q not found", name)
+	}
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("reading snapshot: %w", err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return Snapshot{}, fmt.Errorf("reading snapshot %q: %w", name, err)
+	}
+	snap.Name = name
+	return snap, nil
+}
+
+// List returns the snapshots, newest first.
+func (s *Snapshots) List() ([]Snapshot, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshots: %w", err)
+	}
+	var snaps []Snapshot
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if snap, err := s.Get(e.Name()); err == nil {
+			snaps = append(snaps, snap)
+		}
+	}
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].CreatedAt.After(snaps[j].CreatedAt) })
+	return snaps, nil
+}
+
+// Delete removes the snapshot called name.
+func (s *Snapshots) Delete(name string) error {
+	if _, err := s.Get(name); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(s.path(name)); err != nil {
+		return fmt.Errorf("deleting snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *Snapshots) path(name string) string {
+	return filepath.Join(s.dir, name)
+}
+
+// fileNames returns the names of the files a snapshot copies, in a stable
+// order.
+func (s *Snapshots) fileNames() []string {
+	names := make([]string, 0, len(s.files))
+	for name := range s.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func checkSnapshotName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid snapshot name %q: use letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshots_SaveRestore(t *testing.T) {
+	useTempProfiles(t)
+	dataDir := activeDataDir()
+	configPath := GlobalConfigPath()
+	customPath := filepath.Join(dataDir, customProvidersFile)
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(path string) string {
+		t.Helper()
+		data, err := os.ReadFile(path) //nolint:gosec // Test file
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	write(configPath, `{"models":{"large":{"model":"gpt-4o"}}}`)
+	snaps := NewSnapshots(dataDir)
+	snap, err := snaps.Save("openai", false)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if len(snap.Files) != 1 {
+		t.Errorf("Files = %v, want only cdd.json", snap.Files)
+	}
+	if _, err := snaps.Save("openai", false); err == nil {
+		t.Error("Save() replaced an existing snapshot without overwrite")
+	}
+	if _, err := snaps.Save("../escape", false); err == nil {
+		t.Error("Save() accepted an invalid name")
+	}
+
+	// Experiment with a custom provider.
+	write(configPath, `{"models":{"large":{"model":"llama3"}}}`)
+	write(customPath, `[{"id":"ollama"}]`)
+
+	if _, err := snaps.Restore("openai"); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if got := read(configPath); got != `{"models":{"large":{"model":"gpt-4o"}}}` {
+		t.Errorf("cdd.json = %s, want the saved config", got)
+	}
+	if _, err := os.Stat(customPath); !os.IsNotExist(err) {
+		t.Error("custom-providers.json should be removed, as the snapshot had none")
+	}
+
+	// The replaced configuration can be put back.
+	if _, err := snaps.Restore(PreRestoreSnapshot); err != nil {
+		t.Fatalf("Restore(%s) error = %v", PreRestoreSnapshot, err)
+	}
+	if got := read(customPath); got != `[{"id":"ollama"}]` {
+		t.Errorf("custom-providers.json = %s, want the experiment back", got)
+	}
+
+	list, err := snaps.List()
+	if err != nil || len(list) != 2 {
+		t.Fatalf("List() = %v, %v, want two snapshots", list, err)
+	}
+	if err := snaps.Delete("openai"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := snaps.Restore("openai"); err == nil {
+		t.Error("Restore() of a deleted snapshot succeeded")
+	}
+}
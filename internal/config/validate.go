@@ -0,0 +1,295 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schemaKey may appear at the top of a config file to point editors at a
+// JSON schema; it is not a setting.
+const schemaKey = "$schema"
+
+// requiredKeys lists the keys that must be present in each object type.
+var requiredKeys = map[reflect.Type][]string{
+	reflect.TypeFor[SelectedModel]():     {"model", "provider"},
+	reflect.TypeFor[LSPConfig]():         {"command", "filetypes"},
+	reflect.TypeFor[Connection]():        {"id", "provider_id"},
+	reflect.TypeFor[PasteIntegration]():  {"url"},
+	reflect.TypeFor[JiraIntegration]():   {"base_url", "token"},
+	reflect.TypeFor[LinearIntegration](): {"token"},
+}
+
+// plainKey matches keys that can be written after a dot in a path.
+var plainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// Problem is a single mistake in a config file.
+type Problem struct {
+	Path    string // Location of the value, e.g. options.metrics.listen
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return p.Path + ": " + p.Message
+}
+
+// SchemaError reports every problem found in a config file.
+type SchemaError struct {
+	File     string
+	Problems []Problem
+}
+
+func (e *SchemaError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid config %s:", e.File)
+	for _, p := range e.Problems {
+		b.WriteString("\n  " + p.String())
+	}
+	return b.String()
+}
+
+// ValidateFile checks a config file against the configuration structure. It
+// returns an error only when the file cannot be read.
+func ValidateFile(path string) ([]Problem, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: Path is a config file chosen by the user
+	if err != nil {
+		return nil, err
+	}
+	return Validate(data), nil
+}
+
+// Validate checks config JSON against the configuration structure: unknown
+// keys, values of the wrong type and missing required keys are all reported,
+// ordered by key.
+func Validate(data []byte) []Problem {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return []Problem{{Message: "not valid JSON: " + err.Error()}}
+	}
+
+	v := validator{}
+	if obj, ok := doc.(map[string]any); ok {
+		delete(obj, schemaKey)
+	}
+	v.check("", doc, reflect.TypeFor[Config]())
+	return v.problems
+}
+
+type validator struct {
+	problems []Problem
+}
+
+func (v *validator) add(path, format string, args ...any) {
+	v.problems = append(v.problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// check validates value, found at path, against the Go type it decodes into.
+//
+//nolint:gocyclo // One case per JSON kind
+func (v *validator) check(path string, value any, t reflect.Type) {
+	if t.Kind() == reflect.Pointer {
+		if value == nil {
+			return
+		}
+		t = t.Elem()
+	}
+
+	switch {
+	case t == reflect.TypeFor[time.Time]():
+		s, ok := value.(string)
+		if !ok {
+			v.add(path, "expected a timestamp string, got %s", kindOf(value))
+			return
+		}
+		if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+			v.add(path, "invalid timestamp %q", s)
+		}
+		return
+	case value == nil && (t.Kind() == reflect.Map || t.Kind() == reflect.Slice):
+		return
+	}
+
+	//nolint:exhaustive // Other kinds do not occur in the configuration
+	switch t.Kind() {
+	case reflect.Interface:
+		// Free-form, such as provider_options.
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			v.add(path, "expected a string, got %s", kindOf(value))
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			v.add(path, "expected true or false, got %s", kindOf(value))
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := value.(json.Number)
+		if !ok {
+			v.add(path, "expected an integer, got %s", kindOf(value))
+		} else if _, err := strconv.ParseInt(n.String(), 10, 64); err != nil {
+			v.add(path, "expected an integer, got %s", n)
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := value.(json.Number); !ok {
+			v.add(path, "expected a number, got %s", kindOf(value))
+		}
+	case reflect.Slice:
+		items, ok := value.([]any)
+		if !ok {
+			v.add(path, "expected a list, got %s", kindOf(value))
+			return
+		}
+		for i, item := range items {
+			v.check(fmt.Sprintf("%s[%d]", path, i), item, t.Elem())
+		}
+	case reflect.Map:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			v.add(path, "expected an object, got %s", kindOf(value))
+			return
+		}
+		for _, key := range sortedKeys(obj) {
+			v.check(joinPath(path, key), obj[key], t.Elem())
+		}
+	case reflect.Struct:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			v.add(path, "expected an object, got %s", kindOf(value))
+			return
+		}
+		v.checkObject(path, obj, t)
+	}
+}
+
+// checkObject validates the keys of an object decoded into struct type t.
+func (v *validator) checkObject(path string, obj map[string]any, t reflect.Type) {
+	fields := jsonFields(t)
+	for _, key := range sortedKeys(obj) {
+		field, ok := fields[key]
+		if !ok {
+			v.add(joinPath(path, key), "unknown key%s", suggestion(key, fields))
+			continue
+		}
+		v.check(joinPath(path, key), obj[key], field)
+	}
+	for _, key := range requiredKeys[t] {
+		if _, ok := obj[key]; !ok {
+			v.add(path, "missing required key %q", key)
+		}
+	}
+}
+
+// jsonFields maps the JSON names of t's fields to their types.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// suggestion proposes the known key closest to a misspelt one, if any is
+// within maxTypoDistance edits once case and separators are ignored.
+func suggestion(key string, fields map[string]reflect.Type) string {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(s))
+	}
+	best, bestDistance := "", maxTypoDistance+1
+	for name := range fields {
+		d := editDistance(normalize(name), normalize(key))
+		if d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// maxTypoDistance is how far an unknown key may be from a known one to be
+// suggested in its place.
+const maxTypoDistance = 2
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func joinPath(path, key string) string {
+	if !plainKey.MatchString(key) {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func sortedKeys(obj map[string]any) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// kindOf names the JSON kind of a decoded value for error messages.
+func kindOf(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case json.Number:
+		return "a number"
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "an object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want []Problem
+	}{
+		{
+			name: "valid config",
+			json: `{
+				"$schema": "https://example.com/cdd.schema.json",
+				"models": {"large": {"model": "gpt-4o", "provider": "openai"}},
+				"providers": {"openai": {"api_key": "$OPENAI_API_KEY", "models": [{"id": "gpt-4o", "context_window": 128000}]}},
+				"options": {"max_attempts": 3, "vim_mode": true, "keybindings": {"search": []}}
+			}`,
+		},
+		{
+			name: "unknown key with suggestion",
+			json: `{"options": {"vim_mod": true}}`,
+			want: []Problem{{Path: "options.vim_mod", Message: `unknown key (did you mean "vim_mode"?)`}},
+		},
+		{
+			name: "unknown key without suggestion",
+			json: `{"colour_scheme": "dark"}`,
+			want: []Problem{{Path: "colour_scheme", Message: "unknown key"}},
+		},
+		{
+			name: "wrong type",
+			json: `{"options": {"max_attempts": "3"}}`,
+			want: []Problem{{Path: "options.max_attempts", Message: "expected an integer, got a string"}},
+		},
+		{
+			name: "fractional integer",
+			json: `{"options": {"max_attempts": 2.5}}`,
+			want: []Problem{{Path: "options.max_attempts", Message: "expected an integer, got 2.5"}},
+		},
+		{
+			name: "missing required key",
+			json: `{"models": {"large": {"model": "gpt-4o"}}}`,
+			want: []Problem{{Path: "models.large", Message: `missing required key "provider"`}},
+		},
+		{
+			name: "nested path",
+			json: `{"providers": {"openai": {"models": [{"id": "a"}, {"id": "b", "context_window": "big"}]}}}`,
+			want: []Problem{{Path: "providers.openai.models[1].context_window", Message: "expected an integer, got a string"}},
+		},
+		{
+			name: "quoted map key",
+			json: `{"providers": {"my provider": {"base_url": 1}}}`,
+			want: []Problem{{Path: `providers["my provider"].base_url`, Message: "expected a string, got a number"}},
+		},
+		{
+			name: "all problems reported",
+			json: `{"options": {"debug": "yes", "vim_mode": 1}, "models": []}`,
+			want: []Problem{
+				{Path: "models", Message: "expected an object, got a list"},
+				{Path: "options.debug", Message: "expected true or false, got a string"},
+				{Path: "options.vim_mode", Message: "expected true or false, got a number"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Validate([]byte(tt.json))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_InvalidJSON(t *testing.T) {
+	problems := Validate([]byte(`{"options": `))
+	if len(problems) != 1 || problems[0].Path != "" {
+		t.Fatalf("Validate() = %v, want one problem without a path", problems)
+	}
+}
+
+func TestLoadFromFile_SchemaError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cdd.json")
+	if err := os.WriteFile(path, []byte(`{"options": {"vim_mod": true}}`), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := LoadFromFile(path)
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("LoadFromFile() error = %v, want *SchemaError", err)
+	}
+	if schemaErr.File != path || len(schemaErr.Problems) != 1 {
+		t.Errorf("SchemaError = %+v, want one problem in %s", schemaErr, path)
+	}
+}
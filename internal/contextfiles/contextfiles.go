@@ -0,0 +1,122 @@
+// Package contextfiles loads project context files (CDD.md, AGENTS.md) that
+// are injected into the agent's system prompt.
+package contextfiles
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/tools"
+)
+
+// DefaultFileNames are looked up in the working directory and each of its ancestors.
+var DefaultFileNames = []string{"CDD.md", "AGENTS.md"}
+
+// MaxFileSize caps how much of a single context file is loaded.
+const MaxFileSize = 64 << 10
+
+// File is a loaded context file.
+type File struct {
+	Path      string // Absolute path
+	Content   string
+	Truncated bool // Content was cut at MaxFileSize
+}
+
+// Load returns the context files that apply to workingDir: the default files
+// found in the filesystem root down to workingDir, in that order so that the
+// most specific file comes last, followed by extraPaths (the configured
+// context_paths). Relative extra paths are resolved against workingDir and
+// "~/" expands to the home directory. Missing or unreadable files are skipped.
+func Load(workingDir string, extraPaths []string) []File {
+	absDir, err := filepath.Abs(workingDir)
+	if err != nil {
+		debug.Log("[CONTEXT] Resolving %s: %v", workingDir, err)
+		return nil
+	}
+
+	var candidates []string
+	for _, dir := range tools.Ancestors(absDir) {
+		for _, name := range DefaultFileNames {
+			candidates = append(candidates, filepath.Join(dir, name))
+		}
+	}
+	for _, p := range extraPaths {
+		candidates = append(candidates, tools.ResolvePath(absDir, tools.ExpandHome(p)))
+	}
+
+	var files []File
+	seen := make(map[string]bool, len(candidates))
+	for _, path := range candidates {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		file, err := readFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				debug.Log("[CONTEXT] Skipping %s: %v", path, err)
+			}
+			continue
+		}
+		if strings.TrimSpace(file.Content) == "" {
+			continue
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// Prompt formats files as a system prompt section. It returns an empty
+// string when there are no files.
+func Prompt(files []File) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("# Project Context\n\n")
+	b.WriteString("The following files were provided by the user with instructions and context for this project. ")
+	b.WriteString("Follow them. When they conflict, files listed later are more specific and take precedence.\n")
+	for i := range files {
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", files[i].Path, strings.TrimSpace(files[i].Content))
+		if files[i].Truncated {
+			b.WriteString("\n(File truncated.)\n")
+		}
+	}
+	return b.String()
+}
+
+// readFile reads up to MaxFileSize bytes of a regular file.
+func readFile(path string) (File, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: Context files are chosen by the user.
+	if err != nil {
+		return File{}, err
+	}
+	defer f.Close() //nolint:errcheck // Read-only file, close error is not actionable.
+
+	info, err := f.Stat()
+	if err != nil {
+		return File{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return File{}, fmt.Errorf("not a regular file")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(f, MaxFileSize+1))
+	if err != nil {
+		return File{}, err
+	}
+
+	file := File{Path: path}
+	if len(data) > MaxFileSize {
+		data = data[:MaxFileSize]
+		file.Truncated = true
+	}
+	file.Content = string(data)
+	return file, nil
+}
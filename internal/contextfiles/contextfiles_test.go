@@ -0,0 +1,76 @@
+package contextfiles
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "project")
+	sub := filepath.Join(project, "pkg")
+
+	writeFile(t, filepath.Join(root, "AGENTS.md"), "outer rules")
+	writeFile(t, filepath.Join(project, "CDD.md"), "project rules")
+	writeFile(t, filepath.Join(project, "AGENTS.md"), "   \n")
+	writeFile(t, filepath.Join(sub, "AGENTS.md"), "package rules")
+	writeFile(t, filepath.Join(project, "docs", "STYLE.md"), "style guide")
+
+	files := Load(sub, []string{"../docs/STYLE.md", "missing.md", filepath.Join(sub, "AGENTS.md")})
+
+	var got []string
+	for _, f := range files {
+		got = append(got, f.Content)
+	}
+	want := []string{"outer rules", "project rules", "package rules", "style guide"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Load() contents = %q, want %q", got, want)
+	}
+	if files[1].Path != filepath.Join(project, "CDD.md") {
+		t.Errorf("Path = %q, want absolute path", files[1].Path)
+	}
+}
+
+func TestLoad_Truncates(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "CDD.md"), strings.Repeat("x", MaxFileSize+10))
+
+	files := Load(dir, nil)
+	if len(files) != 1 {
+		t.Fatalf("Load() returned %d files, want 1", len(files))
+	}
+	if !files[0].Truncated || len(files[0].Content) != MaxFileSize {
+		t.Errorf("Truncated = %v, len = %d", files[0].Truncated, len(files[0].Content))
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	if Prompt(nil) != "" {
+		t.Error("Prompt(nil) should be empty")
+	}
+
+	prompt := Prompt([]File{
+		{Path: "/p/CDD.md", Content: "Use tabs.\n"},
+		{Path: "/p/pkg/AGENTS.md", Content: "Run go test.", Truncated: true},
+	})
+	for _, want := range []string{"# Project Context", "## /p/CDD.md\n\nUse tabs.", "## /p/pkg/AGENTS.md", "(File truncated.)"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Prompt() missing %q:\n%s", want, prompt)
+		}
+	}
+	if strings.Index(prompt, "/p/CDD.md") > strings.Index(prompt, "/p/pkg/AGENTS.md") {
+		t.Error("files should keep their order")
+	}
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupTimeFormat stamps backup file names, e.g. cdd-20260102-150405.db.
+const backupTimeFormat = "20060102-150405"
+
+// Backup copies the database into dir unless the newest copy there is less
+// than interval old, then deletes all but the keep newest copies. It returns
+// the path of the new copy, or "" when none was due.
+func (d *DB) Backup(ctx context.Context, dir string, keep int, interval time.Duration) (string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("creating backup directory: %w", err)
+	}
+
+	backups, err := d.backups(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var path string
+	now := time.Now()
+	if len(backups) == 0 || now.Sub(backups[0].taken) >= interval {
+		path = filepath.Join(dir, d.backupPrefix()+now.Format(backupTimeFormat)+".db")
+		// VACUUM INTO writes a consistent, compacted copy while the database
+		// stays usable.
+		if _, err := d.conn.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+			return "", fmt.Errorf("backing up database: %w", err)
+		}
+		backups = append([]backup{{path: path, taken: now}}, backups...)
+	}
+
+	for _, old := range backups[min(keep, len(backups)):] {
+		if err := os.Remove(old.path); err != nil {
+			return path, fmt.Errorf("removing old backup: %w", err)
+		}
+	}
+	return path, nil
+}
+
+// backup is a database copy found in a backup directory.
+type backup struct {
+	path  string
+	taken time.Time
+}
+
+// backups returns the copies of this database in dir, newest first.
+func (d *DB) backups(dir string) ([]backup, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading backup directory: %w", err)
+	}
+
+	prefix := d.backupPrefix()
+	var found []backup
+	for _, e := range entries {
+		stamp, ok := strings.CutPrefix(e.Name(), prefix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		taken, err := time.ParseInLocation(backupTimeFormat, strings.TrimSuffix(stamp, ".db"), time.Local)
+		if err != nil {
+			continue
+		}
+		found = append(found, backup{path: filepath.Join(dir, e.Name()), taken: taken})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].taken.After(found[j].taken) })
+	return found, nil
+}
+
+// backupPrefix starts the names of this database's backups.
+func (d *DB) backupPrefix() string {
+	return strings.TrimSuffix(filepath.Base(d.path), filepath.Ext(d.path)) + "-"
+}
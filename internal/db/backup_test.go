@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDB_Backup(t *testing.T) {
+	tmpDir := t.TempDir()
+	database, err := Open(filepath.Join(tmpDir, "cdd.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() }) //nolint:errcheck // Intentionally ignoring close error in test cleanup
+
+	ctx := context.Background()
+	backupDir := filepath.Join(tmpDir, "backups")
+
+	// Older copies, oldest first.
+	for _, stamp := range []string{"20240101-000000", "20240102-000000", "20240103-000000"} {
+		if err := os.MkdirAll(backupDir, 0o750); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(backupDir, "cdd-"+stamp+".db"), nil, 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	path, err := database.Backup(ctx, backupDir, 2, time.Hour)
+	if err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	if path == "" {
+		t.Fatal("Backup() made no copy")
+	}
+
+	copyDB, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open(backup) error = %v", err)
+	}
+	var tables int
+	if err := copyDB.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'sessions'").Scan(&tables); err != nil || tables != 1 {
+		t.Errorf("backup has %d sessions tables (err %v), want 1", tables, err)
+	}
+	_ = copyDB.Close() //nolint:errcheck // Test cleanup
+
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if len(names) != 2 || names[0] != "cdd-20240103-000000.db" || filepath.Join(backupDir, names[1]) != path {
+		t.Errorf("backups = %v, want the newest old copy and %s", names, filepath.Base(path))
+	}
+
+	t.Run("skips a copy within the interval", func(t *testing.T) {
+		again, err := database.Backup(ctx, backupDir, 2, time.Hour)
+		if err != nil {
+			t.Fatalf("Backup() error = %v", err)
+		}
+		if again != "" {
+			t.Errorf("Backup() = %q, want no new copy", again)
+		}
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	_ "github.com/ncruces/go-sqlite3/driver" // SQLite driver import
 	_ "github.com/ncruces/go-sqlite3/embed"
@@ -18,6 +19,10 @@ import (
 //go:embed migrations/*.sql
 var migrations embed.FS
 
+// busyTimeout is how long a write waits for the database to be unlocked
+// before failing with "database is locked".
+const busyTimeout = 10 * time.Second
+
 // DB wraps a SQLite database connection with query helpers.
 type DB struct {
 	conn *sql.DB
@@ -34,8 +39,12 @@ func Open(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("creating database directory: %w", err)
 	}
 
-	// Open connection with WAL mode and foreign keys enabled
-	dsn := fmt.Sprintf("file:%s?_pragma=journal_mode(wal)&_pragma=foreign_keys(on)&_pragma=busy_timeout(5000)", dbPath)
+	// Open connection with WAL mode and foreign keys enabled. Several cdd
+	// processes may share the database: writers wait up to busyTimeout for
+	// each other, and transactions take the write lock when they begin, since
+	// upgrading a read lock fails at once when another process holds it.
+	dsn := fmt.Sprintf("file:%s?_pragma=journal_mode(wal)&_pragma=foreign_keys(on)&_pragma=busy_timeout(%d)&_txlock=immediate",
+		dbPath, busyTimeout.Milliseconds())
 	conn, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("opening database: %w", err)
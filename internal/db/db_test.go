@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestOpen(t *testing.T) {
@@ -106,6 +107,48 @@ func TestOpen(t *testing.T) {
 			t.Errorf("foreign_keys = %d, want 1", foreignKeys)
 		}
 	})
+
+	t.Run("waits for another process's write", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "test.db")
+		first, err := Open(dbPath)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer func() { _ = first.Close() }() //nolint:errcheck // Intentionally ignoring close error in test cleanup
+		second, err := Open(dbPath)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer func() { _ = second.Close() }() //nolint:errcheck // Intentionally ignoring close error in test cleanup
+
+		ctx := context.Background()
+		insert := "INSERT INTO sessions (id, title, message_count, created_at, updated_at) VALUES (?, 'Test', 0, 0, 0)"
+		done := make(chan error, 1)
+		err = first.WithTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, insert, "first"); err != nil {
+				return err
+			}
+			// Reads first, so without an immediate lock it would fail to upgrade.
+			go func() {
+				done <- second.WithTx(ctx, func(tx *sql.Tx) error {
+					var count int
+					if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM sessions").Scan(&count); err != nil {
+						return err
+					}
+					_, err := tx.ExecContext(ctx, insert, "second")
+					return err
+				})
+			}()
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("WithTx() error = %v", err)
+		}
+		if err := <-done; err != nil {
+			t.Errorf("the second write should wait for the first, got %v", err)
+		}
+	})
 }
 
 func TestDB_Path(t *testing.T) {
@@ -0,0 +1,15 @@
+-- +goose Up
+
+-- Prompts sent from the chat input, per project directory
+CREATE TABLE prompt_history (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    project    TEXT NOT NULL,
+    session_id TEXT NOT NULL DEFAULT '',
+    prompt     TEXT NOT NULL,
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX idx_prompt_history_project ON prompt_history(project, id DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS prompt_history;
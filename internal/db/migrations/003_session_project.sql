@@ -0,0 +1,10 @@
+-- +goose Up
+
+-- Project root each session was started in; empty for older sessions
+ALTER TABLE sessions ADD COLUMN project TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_sessions_project ON sessions(project, updated_at DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_sessions_project;
+ALTER TABLE sessions DROP COLUMN project;
@@ -0,0 +1,7 @@
+-- +goose Up
+
+-- System prompt set for the session with /system; empty uses the default
+ALTER TABLE sessions ADD COLUMN system_prompt TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE sessions DROP COLUMN system_prompt;
@@ -0,0 +1,15 @@
+-- +goose Up
+
+-- Todo list written by the agent with todo_write, per session
+CREATE TABLE todos (
+    session_id  TEXT NOT NULL,
+    position    INTEGER NOT NULL,
+    content     TEXT NOT NULL,
+    active_form TEXT NOT NULL,
+    status      TEXT NOT NULL CHECK (status IN ('pending', 'in_progress', 'completed')),
+    PRIMARY KEY (session_id, position),
+    FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS todos;
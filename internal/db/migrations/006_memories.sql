@@ -0,0 +1,15 @@
+-- +goose Up
+
+-- Durable notes the agent saves with memory_write, per project directory
+CREATE TABLE memories (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    project    TEXT NOT NULL,
+    session_id TEXT NOT NULL DEFAULT '',
+    content    TEXT NOT NULL,
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX idx_memories_project ON memories(project, id DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS memories;
@@ -0,0 +1,30 @@
+-- +goose Up
+
+-- Project files in the codebase index, with the content hash and embedding
+-- model they were indexed with
+CREATE TABLE index_files (
+    project    TEXT NOT NULL,
+    path       TEXT NOT NULL,
+    hash       TEXT NOT NULL,
+    model      TEXT NOT NULL,
+    indexed_at INTEGER NOT NULL,
+    PRIMARY KEY (project, path)
+);
+
+-- Line ranges of indexed files and their embeddings
+CREATE TABLE index_chunks (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    project    TEXT NOT NULL,
+    path       TEXT NOT NULL,
+    start_line INTEGER NOT NULL,
+    end_line   INTEGER NOT NULL,
+    content    TEXT NOT NULL,
+    embedding  BLOB NOT NULL,
+    FOREIGN KEY (project, path) REFERENCES index_files(project, path) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_index_chunks_file ON index_chunks(project, path);
+
+-- +goose Down
+DROP TABLE IF EXISTS index_chunks;
+DROP TABLE IF EXISTS index_files;
@@ -0,0 +1,22 @@
+-- +goose Up
+
+-- Tokens and cost of each provider request, kept when sessions are deleted
+-- so daily budgets still count them
+CREATE TABLE usage (
+    id                    INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id            TEXT NOT NULL,
+    provider              TEXT NOT NULL,
+    model                 TEXT NOT NULL,
+    input_tokens          INTEGER NOT NULL DEFAULT 0,
+    output_tokens         INTEGER NOT NULL DEFAULT 0,
+    cache_creation_tokens INTEGER NOT NULL DEFAULT 0,
+    cache_read_tokens     INTEGER NOT NULL DEFAULT 0,
+    cost                  REAL NOT NULL DEFAULT 0,
+    created_at            INTEGER NOT NULL
+);
+
+CREATE INDEX idx_usage_session ON usage(session_id);
+CREATE INDEX idx_usage_created_at ON usage(created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS usage;
@@ -0,0 +1,8 @@
+-- +goose Up
+
+-- Directory the session's tools run in, set with /cd; empty uses the one cdd
+-- was started in
+ALTER TABLE sessions ADD COLUMN working_dir TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE sessions DROP COLUMN working_dir;
@@ -0,0 +1,27 @@
+-- name: ListIndexFiles :many
+SELECT * FROM index_files
+WHERE project = ?;
+
+-- name: UpsertIndexFile :exec
+INSERT INTO index_files (project, path, hash, model, indexed_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (project, path) DO UPDATE SET
+    hash = excluded.hash,
+    model = excluded.model,
+    indexed_at = excluded.indexed_at;
+
+-- name: DeleteIndexFile :exec
+DELETE FROM index_files
+WHERE project = ? AND path = ?;
+
+-- name: DeleteIndexChunks :exec
+DELETE FROM index_chunks
+WHERE project = ? AND path = ?;
+
+-- name: AddIndexChunk :exec
+INSERT INTO index_chunks (project, path, start_line, end_line, content, embedding)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: ListIndexChunks :many
+SELECT * FROM index_chunks
+WHERE project = ?;
@@ -0,0 +1,13 @@
+-- name: AddMemory :one
+INSERT INTO memories (project, session_id, content, created_at)
+VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: ListMemories :many
+SELECT * FROM memories
+WHERE project = ?
+ORDER BY id DESC;
+
+-- name: DeleteMemory :exec
+DELETE FROM memories
+WHERE project = ? AND id = ?;
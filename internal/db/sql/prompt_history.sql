@@ -0,0 +1,18 @@
+-- name: AddPrompt :exec
+INSERT INTO prompt_history (project, session_id, prompt, created_at)
+VALUES (?, ?, ?, ?);
+
+-- name: ListPrompts :many
+SELECT * FROM prompt_history
+WHERE project = ?
+ORDER BY id DESC
+LIMIT ?;
+
+-- name: PrunePrompts :exec
+DELETE FROM prompt_history
+WHERE id IN (
+    SELECT id FROM prompt_history
+    WHERE project = ?
+    ORDER BY id DESC
+    LIMIT -1 OFFSET ?
+);
@@ -1,11 +1,16 @@
 -- name: CreateSession :one
-INSERT INTO sessions (id, title, message_count, created_at, updated_at)
-VALUES (?, ?, 0, ?, ?)
+INSERT INTO sessions (id, title, project, message_count, created_at, updated_at)
+VALUES (?, ?, ?, 0, ?, ?)
 RETURNING *;
 
 -- name: GetSession :one
 SELECT * FROM sessions WHERE id = ?;
 
+-- name: ImportSession :one
+INSERT INTO sessions (id, title, project, message_count, summary_message_id, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
 -- name: ListSessions :many
 SELECT * FROM sessions ORDER BY updated_at DESC;
 
@@ -15,15 +20,30 @@ UPDATE sessions SET title = ?, updated_at = ? WHERE id = ?;
 -- name: UpdateSessionMessageCount :exec
 UPDATE sessions SET message_count = message_count + 1, updated_at = ? WHERE id = ?;
 
+-- name: AddSessionMessageCount :exec
+UPDATE sessions SET message_count = message_count + ?, updated_at = ? WHERE id = ?;
+
 -- name: DecrementSessionMessageCount :exec
 UPDATE sessions SET message_count = CASE WHEN message_count > 0 THEN message_count - 1 ELSE 0 END, updated_at = ? WHERE id = ?;
 
 -- name: SetSessionSummary :exec
 UPDATE sessions SET summary_message_id = ?, updated_at = ? WHERE id = ?;
 
+-- name: SetSessionSystemPrompt :exec
+UPDATE sessions SET system_prompt = ?, updated_at = ? WHERE id = ?;
+
+-- name: SetSessionWorkingDir :exec
+UPDATE sessions SET working_dir = ?, updated_at = ? WHERE id = ?;
+
 -- name: DeleteSession :exec
 DELETE FROM sessions WHERE id = ?;
 
+-- name: ListSessionsUpdatedBefore :many
+SELECT * FROM sessions WHERE updated_at < ? ORDER BY updated_at;
+
+-- name: DeleteSessionsUpdatedBefore :execrows
+DELETE FROM sessions WHERE updated_at < ?;
+
 -- name: SearchSessions :many
 SELECT * FROM sessions
 WHERE LOWER(title) LIKE '%' || LOWER(?) || '%'
@@ -37,6 +57,7 @@ SELECT
     s.summary_message_id,
     s.created_at,
     s.updated_at,
+    s.project,
     COALESCE((SELECT m.parts FROM messages m WHERE m.session_id = s.id AND m.role = 'user' ORDER BY m.created_at ASC LIMIT 1), '') as first_message
 FROM sessions s
 ORDER BY s.updated_at DESC;
@@ -49,7 +70,37 @@ SELECT
     s.summary_message_id,
     s.created_at,
     s.updated_at,
+    s.project,
     COALESCE((SELECT m.parts FROM messages m WHERE m.session_id = s.id AND m.role = 'user' ORDER BY m.created_at ASC LIMIT 1), '') as first_message
 FROM sessions s
 WHERE LOWER(s.title) LIKE '%' || LOWER(?) || '%'
 ORDER BY s.updated_at DESC;
+
+-- name: ListProjectSessionsWithPreview :many
+-- Sessions from before projects were recorded belong to every project.
+SELECT
+    s.id,
+    s.title,
+    s.message_count,
+    s.summary_message_id,
+    s.created_at,
+    s.updated_at,
+    s.project,
+    COALESCE((SELECT m.parts FROM messages m WHERE m.session_id = s.id AND m.role = 'user' ORDER BY m.created_at ASC LIMIT 1), '') as first_message
+FROM sessions s
+WHERE s.project IN (?, '')
+ORDER BY s.updated_at DESC;
+
+-- name: SearchProjectSessionsWithPreview :many
+SELECT
+    s.id,
+    s.title,
+    s.message_count,
+    s.summary_message_id,
+    s.created_at,
+    s.updated_at,
+    s.project,
+    COALESCE((SELECT m.parts FROM messages m WHERE m.session_id = s.id AND m.role = 'user' ORDER BY m.created_at ASC LIMIT 1), '') as first_message
+FROM sessions s
+WHERE s.project IN (?, '') AND LOWER(s.title) LIKE '%' || LOWER(?) || '%'
+ORDER BY s.updated_at DESC;
@@ -0,0 +1,12 @@
+-- name: AddTodo :exec
+INSERT INTO todos (session_id, position, content, active_form, status)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: ListTodos :many
+SELECT * FROM todos
+WHERE session_id = ?
+ORDER BY position;
+
+-- name: DeleteTodos :exec
+DELETE FROM todos
+WHERE session_id = ?;
@@ -0,0 +1,45 @@
+-- name: AddUsage :exec
+INSERT INTO usage (
+    session_id, provider, model,
+    input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens,
+    cost, created_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: SumSessionUsage :one
+SELECT
+    CAST(COUNT(*) AS INTEGER) AS requests,
+    CAST(COALESCE(SUM(input_tokens), 0) AS INTEGER) AS input_tokens,
+    CAST(COALESCE(SUM(output_tokens), 0) AS INTEGER) AS output_tokens,
+    CAST(COALESCE(SUM(cache_creation_tokens), 0) AS INTEGER) AS cache_creation_tokens,
+    CAST(COALESCE(SUM(cache_read_tokens), 0) AS INTEGER) AS cache_read_tokens,
+    CAST(COALESCE(SUM(cost), 0) AS REAL) AS cost
+FROM usage
+WHERE session_id = ?;
+
+-- name: SumUsageSince :one
+SELECT
+    CAST(COUNT(*) AS INTEGER) AS requests,
+    CAST(COALESCE(SUM(input_tokens), 0) AS INTEGER) AS input_tokens,
+    CAST(COALESCE(SUM(output_tokens), 0) AS INTEGER) AS output_tokens,
+    CAST(COALESCE(SUM(cache_creation_tokens), 0) AS INTEGER) AS cache_creation_tokens,
+    CAST(COALESCE(SUM(cache_read_tokens), 0) AS INTEGER) AS cache_read_tokens,
+    CAST(COALESCE(SUM(cost), 0) AS REAL) AS cost
+FROM usage
+WHERE created_at >= ?;
+
+-- name: UsageByDay :many
+SELECT
+    CAST(date(created_at / 1000 + CAST(sqlc.arg(utc_offset) AS INTEGER), 'unixepoch') AS TEXT) AS day,
+    provider,
+    model,
+    CAST(COUNT(*) AS INTEGER) AS requests,
+    CAST(COALESCE(SUM(input_tokens), 0) AS INTEGER) AS input_tokens,
+    CAST(COALESCE(SUM(output_tokens), 0) AS INTEGER) AS output_tokens,
+    CAST(COALESCE(SUM(cache_creation_tokens), 0) AS INTEGER) AS cache_creation_tokens,
+    CAST(COALESCE(SUM(cache_read_tokens), 0) AS INTEGER) AS cache_read_tokens,
+    CAST(COALESCE(SUM(cost), 0) AS REAL) AS cost
+FROM usage
+WHERE created_at >= sqlc.arg(created_at)
+GROUP BY day, provider, model
+ORDER BY day, provider, model;
@@ -8,6 +8,32 @@ import (
 	"database/sql"
 )
 
+type IndexChunk struct {
+	ID        int64  `json:"id"`
+	Project   string `json:"project"`
+	Path      string `json:"path"`
+	StartLine int64  `json:"start_line"`
+	EndLine   int64  `json:"end_line"`
+	Content   string `json:"content"`
+	Embedding []byte `json:"embedding"`
+}
+
+type IndexFile struct {
+	Project   string `json:"project"`
+	Path      string `json:"path"`
+	Hash      string `json:"hash"`
+	Model     string `json:"model"`
+	IndexedAt int64  `json:"indexed_at"`
+}
+
+type Memory struct {
+	ID        int64  `json:"id"`
+	Project   string `json:"project"`
+	SessionID string `json:"session_id"`
+	Content   string `json:"content"`
+	CreatedAt int64  `json:"created_at"`
+}
+
 type Message struct {
 	ID        string         `json:"id"`
 	SessionID string         `json:"session_id"`
@@ -20,6 +46,14 @@ type Message struct {
 	UpdatedAt int64          `json:"updated_at"`
 }
 
+type PromptHistory struct {
+	ID        int64  `json:"id"`
+	Project   string `json:"project"`
+	SessionID string `json:"session_id"`
+	Prompt    string `json:"prompt"`
+	CreatedAt int64  `json:"created_at"`
+}
+
 type Session struct {
 	ID               string         `json:"id"`
 	Title            string         `json:"title"`
@@ -27,4 +61,28 @@ type Session struct {
 	SummaryMessageID sql.NullString `json:"summary_message_id"`
 	CreatedAt        int64          `json:"created_at"`
 	UpdatedAt        int64          `json:"updated_at"`
+	Project          string         `json:"project"`
+	SystemPrompt     string         `json:"system_prompt"`
+	WorkingDir       string         `json:"working_dir"`
+}
+
+type Todo struct {
+	SessionID  string `json:"session_id"`
+	Position   int64  `json:"position"`
+	Content    string `json:"content"`
+	ActiveForm string `json:"active_form"`
+	Status     string `json:"status"`
+}
+
+type Usage struct {
+	ID                  int64   `json:"id"`
+	SessionID           string  `json:"session_id"`
+	Provider            string  `json:"provider"`
+	Model               string  `json:"model"`
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	Cost                float64 `json:"cost"`
+	CreatedAt           int64   `json:"created_at"`
 }
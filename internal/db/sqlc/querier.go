@@ -9,28 +9,56 @@ import (
 )
 
 type Querier interface {
+	AddIndexChunk(ctx context.Context, arg AddIndexChunkParams) error
+	AddMemory(ctx context.Context, arg AddMemoryParams) (Memory, error)
+	AddPrompt(ctx context.Context, arg AddPromptParams) error
+	AddSessionMessageCount(ctx context.Context, arg AddSessionMessageCountParams) error
+	AddTodo(ctx context.Context, arg AddTodoParams) error
+	AddUsage(ctx context.Context, arg AddUsageParams) error
 	CountSessionMessages(ctx context.Context, sessionID string) (int64, error)
 	CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	DecrementSessionMessageCount(ctx context.Context, arg DecrementSessionMessageCountParams) error
+	DeleteIndexChunks(ctx context.Context, arg DeleteIndexChunksParams) error
+	DeleteIndexFile(ctx context.Context, arg DeleteIndexFileParams) error
+	DeleteMemory(ctx context.Context, arg DeleteMemoryParams) error
 	DeleteMessage(ctx context.Context, id string) error
 	DeleteOldMessages(ctx context.Context, arg DeleteOldMessagesParams) error
 	DeleteSession(ctx context.Context, id string) error
 	DeleteSessionMessages(ctx context.Context, sessionID string) error
+	DeleteSessionsUpdatedBefore(ctx context.Context, updatedAt int64) (int64, error)
+	DeleteTodos(ctx context.Context, sessionID string) error
 	GetMessage(ctx context.Context, id string) (Message, error)
 	GetMessagesFromID(ctx context.Context, arg GetMessagesFromIDParams) ([]Message, error)
 	GetSession(ctx context.Context, id string) (Session, error)
 	GetSessionMessages(ctx context.Context, sessionID string) ([]Message, error)
 	GetSessionMessagesWithLimit(ctx context.Context, arg GetSessionMessagesWithLimitParams) ([]Message, error)
 	GetSummaryMessage(ctx context.Context, sessionID string) (Message, error)
+	ImportSession(ctx context.Context, arg ImportSessionParams) (Session, error)
+	ListIndexChunks(ctx context.Context, project string) ([]IndexChunk, error)
+	ListIndexFiles(ctx context.Context, project string) ([]IndexFile, error)
+	ListMemories(ctx context.Context, project string) ([]Memory, error)
+	ListPrompts(ctx context.Context, arg ListPromptsParams) ([]PromptHistory, error)
+	// Sessions from before projects were recorded belong to every project.
+	ListProjectSessionsWithPreview(ctx context.Context, project string) ([]ListProjectSessionsWithPreviewRow, error)
 	ListSessions(ctx context.Context) ([]Session, error)
+	ListSessionsUpdatedBefore(ctx context.Context, updatedAt int64) ([]Session, error)
 	ListSessionsWithPreview(ctx context.Context) ([]ListSessionsWithPreviewRow, error)
+	ListTodos(ctx context.Context, sessionID string) ([]Todo, error)
+	PrunePrompts(ctx context.Context, arg PrunePromptsParams) error
+	SearchProjectSessionsWithPreview(ctx context.Context, arg SearchProjectSessionsWithPreviewParams) ([]SearchProjectSessionsWithPreviewRow, error)
 	SearchSessions(ctx context.Context, lower string) ([]Session, error)
 	SearchSessionsWithPreview(ctx context.Context, lower string) ([]SearchSessionsWithPreviewRow, error)
 	SetSessionSummary(ctx context.Context, arg SetSessionSummaryParams) error
+	SetSessionSystemPrompt(ctx context.Context, arg SetSessionSystemPromptParams) error
+	SetSessionWorkingDir(ctx context.Context, arg SetSessionWorkingDirParams) error
+	SumSessionUsage(ctx context.Context, sessionID string) (SumSessionUsageRow, error)
+	SumUsageSince(ctx context.Context, createdAt int64) (SumUsageSinceRow, error)
 	UpdateMessageParts(ctx context.Context, arg UpdateMessagePartsParams) error
 	UpdateSessionMessageCount(ctx context.Context, arg UpdateSessionMessageCountParams) error
 	UpdateSessionTitle(ctx context.Context, arg UpdateSessionTitleParams) error
+	UpsertIndexFile(ctx context.Context, arg UpsertIndexFileParams) error
+	UsageByDay(ctx context.Context, arg UsageByDayParams) ([]UsageByDayRow, error)
 }
 
 var _ Querier = (*Queries)(nil)
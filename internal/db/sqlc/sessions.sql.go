@@ -10,15 +10,31 @@ import (
 	"database/sql"
 )
 
+const addSessionMessageCount = `-- name: AddSessionMessageCount :exec
+UPDATE sessions SET message_count = message_count + ?, updated_at = ? WHERE id = ?
+`
+
+type AddSessionMessageCountParams struct {
+	MessageCount int64  `json:"message_count"`
+	UpdatedAt    int64  `json:"updated_at"`
+	ID           string `json:"id"`
+}
+
+func (q *Queries) AddSessionMessageCount(ctx context.Context, arg AddSessionMessageCountParams) error {
+	_, err := q.db.ExecContext(ctx, addSessionMessageCount, arg.MessageCount, arg.UpdatedAt, arg.ID)
+	return err
+}
+
 const createSession = `-- name: CreateSession :one
-INSERT INTO sessions (id, title, message_count, created_at, updated_at)
-VALUES (?, ?, 0, ?, ?)
-RETURNING id, title, message_count, summary_message_id, created_at, updated_at
+INSERT INTO sessions (id, title, project, message_count, created_at, updated_at)
+VALUES (?, ?, ?, 0, ?, ?)
+RETURNING id, title, message_count, summary_message_id, created_at, updated_at, project, system_prompt, working_dir
 `
 
 type CreateSessionParams struct {
 	ID        string `json:"id"`
 	Title     string `json:"title"`
+	Project   string `json:"project"`
 	CreatedAt int64  `json:"created_at"`
 	UpdatedAt int64  `json:"updated_at"`
 }
@@ -27,6 +43,7 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 	row := q.db.QueryRowContext(ctx, createSession,
 		arg.ID,
 		arg.Title,
+		arg.Project,
 		arg.CreatedAt,
 		arg.UpdatedAt,
 	)
@@ -38,6 +55,9 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.SummaryMessageID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Project,
+		&i.SystemPrompt,
+		&i.WorkingDir,
 	)
 	return i, err
 }
@@ -65,8 +85,20 @@ func (q *Queries) DeleteSession(ctx context.Context, id string) error {
 	return err
 }
 
+const deleteSessionsUpdatedBefore = `-- name: DeleteSessionsUpdatedBefore :execrows
+DELETE FROM sessions WHERE updated_at < ?
+`
+
+func (q *Queries) DeleteSessionsUpdatedBefore(ctx context.Context, updatedAt int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSessionsUpdatedBefore, updatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getSession = `-- name: GetSession :one
-SELECT id, title, message_count, summary_message_id, created_at, updated_at FROM sessions WHERE id = ?
+SELECT id, title, message_count, summary_message_id, created_at, updated_at, project, system_prompt, working_dir FROM sessions WHERE id = ?
 `
 
 func (q *Queries) GetSession(ctx context.Context, id string) (Session, error) {
@@ -79,12 +111,115 @@ func (q *Queries) GetSession(ctx context.Context, id string) (Session, error) {
 		&i.SummaryMessageID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Project,
+		&i.SystemPrompt,
+		&i.WorkingDir,
+	)
+	return i, err
+}
+
+const importSession = `-- name: ImportSession :one
+INSERT INTO sessions (id, title, project, message_count, summary_message_id, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING id, title, message_count, summary_message_id, created_at, updated_at, project, system_prompt, working_dir
+`
+
+type ImportSessionParams struct {
+	ID               string         `json:"id"`
+	Title            string         `json:"title"`
+	Project          string         `json:"project"`
+	MessageCount     int64          `json:"message_count"`
+	SummaryMessageID sql.NullString `json:"summary_message_id"`
+	CreatedAt        int64          `json:"created_at"`
+	UpdatedAt        int64          `json:"updated_at"`
+}
+
+func (q *Queries) ImportSession(ctx context.Context, arg ImportSessionParams) (Session, error) {
+	row := q.db.QueryRowContext(ctx, importSession,
+		arg.ID,
+		arg.Title,
+		arg.Project,
+		arg.MessageCount,
+		arg.SummaryMessageID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.MessageCount,
+		&i.SummaryMessageID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Project,
+		&i.SystemPrompt,
+		&i.WorkingDir,
 	)
 	return i, err
 }
 
+const listProjectSessionsWithPreview = `-- name: ListProjectSessionsWithPreview :many
+SELECT
+    s.id,
+    s.title,
+    s.message_count,
+    s.summary_message_id,
+    s.created_at,
+    s.updated_at,
+    s.project,
+    COALESCE((SELECT m.parts FROM messages m WHERE m.session_id = s.id AND m.role = 'user' ORDER BY m.created_at ASC LIMIT 1), '') as first_message
+FROM sessions s
+WHERE s.project IN (?, '')
+ORDER BY s.updated_at DESC
+`
+
+type ListProjectSessionsWithPreviewRow struct {
+	ID               string         `json:"id"`
+	Title            string         `json:"title"`
+	MessageCount     int64          `json:"message_count"`
+	SummaryMessageID sql.NullString `json:"summary_message_id"`
+	CreatedAt        int64          `json:"created_at"`
+	UpdatedAt        int64          `json:"updated_at"`
+	Project          string         `json:"project"`
+	FirstMessage     interface{}    `json:"first_message"`
+}
+
+// Sessions from before projects were recorded belong to every project.
+func (q *Queries) ListProjectSessionsWithPreview(ctx context.Context, project string) ([]ListProjectSessionsWithPreviewRow, error) {
+	rows, err := q.db.QueryContext(ctx, listProjectSessionsWithPreview, project)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListProjectSessionsWithPreviewRow{}
+	for rows.Next() {
+		var i ListProjectSessionsWithPreviewRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.MessageCount,
+			&i.SummaryMessageID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Project,
+			&i.FirstMessage,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listSessions = `-- name: ListSessions :many
-SELECT id, title, message_count, summary_message_id, created_at, updated_at FROM sessions ORDER BY updated_at DESC
+SELECT id, title, message_count, summary_message_id, created_at, updated_at, project, system_prompt, working_dir FROM sessions ORDER BY updated_at DESC
 `
 
 func (q *Queries) ListSessions(ctx context.Context) ([]Session, error) {
@@ -103,6 +238,46 @@ func (q *Queries) ListSessions(ctx context.Context) ([]Session, error) {
 			&i.SummaryMessageID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Project,
+			&i.SystemPrompt,
+			&i.WorkingDir,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSessionsUpdatedBefore = `-- name: ListSessionsUpdatedBefore :many
+SELECT id, title, message_count, summary_message_id, created_at, updated_at, project, system_prompt, working_dir FROM sessions WHERE updated_at < ? ORDER BY updated_at
+`
+
+func (q *Queries) ListSessionsUpdatedBefore(ctx context.Context, updatedAt int64) ([]Session, error) {
+	rows, err := q.db.QueryContext(ctx, listSessionsUpdatedBefore, updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.MessageCount,
+			&i.SummaryMessageID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Project,
+			&i.SystemPrompt,
+			&i.WorkingDir,
 		); err != nil {
 			return nil, err
 		}
@@ -125,6 +300,7 @@ SELECT
     s.summary_message_id,
     s.created_at,
     s.updated_at,
+    s.project,
     COALESCE((SELECT m.parts FROM messages m WHERE m.session_id = s.id AND m.role = 'user' ORDER BY m.created_at ASC LIMIT 1), '') as first_message
 FROM sessions s
 ORDER BY s.updated_at DESC
@@ -137,6 +313,7 @@ type ListSessionsWithPreviewRow struct {
 	SummaryMessageID sql.NullString `json:"summary_message_id"`
 	CreatedAt        int64          `json:"created_at"`
 	UpdatedAt        int64          `json:"updated_at"`
+	Project          string         `json:"project"`
 	FirstMessage     interface{}    `json:"first_message"`
 }
 
@@ -156,6 +333,70 @@ func (q *Queries) ListSessionsWithPreview(ctx context.Context) ([]ListSessionsWi
 			&i.SummaryMessageID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Project,
+			&i.FirstMessage,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchProjectSessionsWithPreview = `-- name: SearchProjectSessionsWithPreview :many
+SELECT
+    s.id,
+    s.title,
+    s.message_count,
+    s.summary_message_id,
+    s.created_at,
+    s.updated_at,
+    s.project,
+    COALESCE((SELECT m.parts FROM messages m WHERE m.session_id = s.id AND m.role = 'user' ORDER BY m.created_at ASC LIMIT 1), '') as first_message
+FROM sessions s
+WHERE s.project IN (?, '') AND LOWER(s.title) LIKE '%' || LOWER(?) || '%'
+ORDER BY s.updated_at DESC
+`
+
+type SearchProjectSessionsWithPreviewParams struct {
+	Project string `json:"project"`
+	Lower   string `json:"lower"`
+}
+
+type SearchProjectSessionsWithPreviewRow struct {
+	ID               string         `json:"id"`
+	Title            string         `json:"title"`
+	MessageCount     int64          `json:"message_count"`
+	SummaryMessageID sql.NullString `json:"summary_message_id"`
+	CreatedAt        int64          `json:"created_at"`
+	UpdatedAt        int64          `json:"updated_at"`
+	Project          string         `json:"project"`
+	FirstMessage     interface{}    `json:"first_message"`
+}
+
+func (q *Queries) SearchProjectSessionsWithPreview(ctx context.Context, arg SearchProjectSessionsWithPreviewParams) ([]SearchProjectSessionsWithPreviewRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchProjectSessionsWithPreview, arg.Project, arg.Lower)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchProjectSessionsWithPreviewRow{}
+	for rows.Next() {
+		var i SearchProjectSessionsWithPreviewRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.MessageCount,
+			&i.SummaryMessageID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Project,
 			&i.FirstMessage,
 		); err != nil {
 			return nil, err
@@ -172,7 +413,7 @@ func (q *Queries) ListSessionsWithPreview(ctx context.Context) ([]ListSessionsWi
 }
 
 const searchSessions = `-- name: SearchSessions :many
-SELECT id, title, message_count, summary_message_id, created_at, updated_at FROM sessions
+SELECT id, title, message_count, summary_message_id, created_at, updated_at, project, system_prompt, working_dir FROM sessions
 WHERE LOWER(title) LIKE '%' || LOWER(?) || '%'
 ORDER BY updated_at DESC
 `
@@ -193,6 +434,9 @@ func (q *Queries) SearchSessions(ctx context.Context, lower string) ([]Session,
 			&i.SummaryMessageID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Project,
+			&i.SystemPrompt,
+			&i.WorkingDir,
 		); err != nil {
 			return nil, err
 		}
@@ -215,6 +459,7 @@ SELECT
     s.summary_message_id,
     s.created_at,
     s.updated_at,
+    s.project,
     COALESCE((SELECT m.parts FROM messages m WHERE m.session_id = s.id AND m.role = 'user' ORDER BY m.created_at ASC LIMIT 1), '') as first_message
 FROM sessions s
 WHERE LOWER(s.title) LIKE '%' || LOWER(?) || '%'
@@ -228,6 +473,7 @@ type SearchSessionsWithPreviewRow struct {
 	SummaryMessageID sql.NullString `json:"summary_message_id"`
 	CreatedAt        int64          `json:"created_at"`
 	UpdatedAt        int64          `json:"updated_at"`
+	Project          string         `json:"project"`
 	FirstMessage     interface{}    `json:"first_message"`
 }
 
@@ -247,6 +493,7 @@ func (q *Queries) SearchSessionsWithPreview(ctx context.Context, lower string) (
 			&i.SummaryMessageID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Project,
 			&i.FirstMessage,
 		); err != nil {
 			return nil, err
@@ -277,6 +524,36 @@ func (q *Queries) SetSessionSummary(ctx context.Context, arg SetSessionSummaryPa
 	return err
 }
 
+const setSessionSystemPrompt = `-- name: SetSessionSystemPrompt :exec
+UPDATE sessions SET system_prompt = ?, updated_at = ? WHERE id = ?
+`
+
+type SetSessionSystemPromptParams struct {
+	SystemPrompt string `json:"system_prompt"`
+	UpdatedAt    int64  `json:"updated_at"`
+	ID           string `json:"id"`
+}
+
+func (q *Queries) SetSessionSystemPrompt(ctx context.Context, arg SetSessionSystemPromptParams) error {
+	_, err := q.db.ExecContext(ctx, setSessionSystemPrompt, arg.SystemPrompt, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const setSessionWorkingDir = `-- name: SetSessionWorkingDir :exec
+UPDATE sessions SET working_dir = ?, updated_at = ? WHERE id = ?
+`
+
+type SetSessionWorkingDirParams struct {
+	WorkingDir string `json:"working_dir"`
+	UpdatedAt  int64  `json:"updated_at"`
+	ID         string `json:"id"`
+}
+
+func (q *Queries) SetSessionWorkingDir(ctx context.Context, arg SetSessionWorkingDirParams) error {
+	_, err := q.db.ExecContext(ctx, setSessionWorkingDir, arg.WorkingDir, arg.UpdatedAt, arg.ID)
+	return err
+}
+
 const updateSessionMessageCount = `-- name: UpdateSessionMessageCount :exec
 UPDATE sessions SET message_count = message_count + 1, updated_at = ? WHERE id = ?
 `
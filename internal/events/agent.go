@@ -10,12 +10,15 @@ type AgentEventType string
 
 // Agent event type constants.
 const (
-	AgentEventTextDelta  AgentEventType = "text_delta"
-	AgentEventToolCall   AgentEventType = "tool_call"
-	AgentEventToolResult AgentEventType = "tool_result"
-	AgentEventComplete   AgentEventType = "complete"
-	AgentEventError      AgentEventType = "error"
-	AgentEventCancelled  AgentEventType = "cancelled"
+	AgentEventTextDelta      AgentEventType = "text_delta"
+	AgentEventReasoningDelta AgentEventType = "reasoning_delta"
+	AgentEventToolCall       AgentEventType = "tool_call"
+	AgentEventToolResult     AgentEventType = "tool_result"
+	AgentEventComplete       AgentEventType = "complete"
+	AgentEventError          AgentEventType = "error"
+	AgentEventCancelled      AgentEventType = "cancelled"
+	AgentEventCompacted      AgentEventType = "compacted"
+	AgentEventRetrying       AgentEventType = "retrying"
 )
 
 // AgentEvent represents an agent streaming event.
@@ -26,10 +29,13 @@ type AgentEvent struct { //nolint:govet // fieldalignment: preserving logical fi
 	Timestamp time.Time
 
 	// Payload fields (only one populated per event type)
-	TextDelta  string          // For TextDelta
-	ToolCall   *ToolCallInfo   // For ToolCall
-	ToolResult *ToolResultInfo // For ToolResult
-	Error      error           // For Error
+	TextDelta      string          // For TextDelta
+	ReasoningDelta string          // For ReasoningDelta
+	ToolCall       *ToolCallInfo   // For ToolCall
+	ToolResult     *ToolResultInfo // For ToolResult
+	Error          error           // For Error and Retrying
+	Retry          *RetryInfo      // For Retrying
+	Completion     *CompletionInfo // For Complete
 }
 
 // ToolCallInfo contains tool call details.
@@ -48,6 +54,37 @@ type ToolResultInfo struct {
 	Duration   time.Duration
 }
 
+// RetryInfo describes an upcoming retry after a transient provider error.
+type RetryInfo struct {
+	Attempt     int           // The attempt about to be made (2 for the first retry)
+	MaxAttempts int           // Total attempts allowed
+	Delay       time.Duration // Time until the attempt starts
+}
+
+// Finish reasons reported in CompletionInfo.
+const (
+	FinishReasonStop          = "stop"
+	FinishReasonLength        = "length" // Cut off by the max tokens limit
+	FinishReasonContentFilter = "content-filter"
+	FinishReasonToolCalls     = "tool-calls" // Stopped on tool use, e.g. at the max turns limit
+)
+
+// CompletionInfo describes how a reply ended and the tokens it used across
+// all of its steps.
+type CompletionInfo struct {
+	FinishReason        string
+	InputTokens         int64
+	OutputTokens        int64
+	ReasoningTokens     int64
+	CacheCreationTokens int64
+	CacheReadTokens     int64
+}
+
+// Truncated reports whether the reply was cut off by the max tokens limit.
+func (c CompletionInfo) Truncated() bool {
+	return c.FinishReason == FinishReasonLength
+}
+
 // NewTextDeltaEvent creates a text delta event.
 func NewTextDeltaEvent(sessionID, messageID, text string) AgentEvent {
 	return AgentEvent{
@@ -59,6 +96,17 @@ func NewTextDeltaEvent(sessionID, messageID, text string) AgentEvent {
 	}
 }
 
+// NewReasoningDeltaEvent creates a reasoning (thinking) delta event.
+func NewReasoningDeltaEvent(sessionID, messageID, text string) AgentEvent {
+	return AgentEvent{
+		SessionID:      sessionID,
+		MessageID:      messageID,
+		Type:           AgentEventReasoningDelta,
+		ReasoningDelta: text,
+		Timestamp:      time.Now(),
+	}
+}
+
 // NewToolCallEvent creates a tool call event.
 func NewToolCallEvent(sessionID, messageID string, tc ToolCallInfo) AgentEvent {
 	return AgentEvent{
@@ -82,12 +130,13 @@ func NewToolResultEvent(sessionID, messageID string, tr ToolResultInfo) AgentEve
 }
 
 // NewCompleteEvent creates a completion event.
-func NewCompleteEvent(sessionID, messageID string) AgentEvent {
+func NewCompleteEvent(sessionID, messageID string, info CompletionInfo) AgentEvent {
 	return AgentEvent{
-		SessionID: sessionID,
-		MessageID: messageID,
-		Type:      AgentEventComplete,
-		Timestamp: time.Now(),
+		SessionID:  sessionID,
+		MessageID:  messageID,
+		Type:       AgentEventComplete,
+		Completion: &info,
+		Timestamp:  time.Now(),
 	}
 }
 
@@ -111,3 +160,24 @@ func NewCancelledEvent(sessionID, messageID string) AgentEvent {
 		Timestamp: time.Now(),
 	}
 }
+
+// NewCompactedEvent creates an event signalling that older history was summarized.
+func NewCompactedEvent(sessionID, messageID string) AgentEvent {
+	return AgentEvent{
+		SessionID: sessionID,
+		MessageID: messageID,
+		Type:      AgentEventCompacted,
+		Timestamp: time.Now(),
+	}
+}
+
+// NewRetryingEvent creates an event signalling that a failed request will be retried after a delay.
+func NewRetryingEvent(sessionID string, err error, info RetryInfo) AgentEvent {
+	return AgentEvent{
+		SessionID: sessionID,
+		Type:      AgentEventRetrying,
+		Error:     err,
+		Retry:     &info,
+		Timestamp: time.Now(),
+	}
+}
@@ -11,11 +11,14 @@ func TestAgentEventTypes(t *testing.T) {
 	// Verify all event types are distinct
 	types := []AgentEventType{
 		AgentEventTextDelta,
+		AgentEventReasoningDelta,
 		AgentEventToolCall,
 		AgentEventToolResult,
 		AgentEventComplete,
 		AgentEventError,
 		AgentEventCancelled,
+		AgentEventCompacted,
+		AgentEventRetrying,
 	}
 
 	seen := make(map[AgentEventType]bool)
@@ -74,6 +77,32 @@ func TestNewTextDeltaEvent(t *testing.T) {
 	})
 }
 
+func TestNewReasoningDeltaEvent(t *testing.T) {
+	event := NewReasoningDeltaEvent("session-1", "msg-1", "Let me think.")
+
+	if event.Type != AgentEventReasoningDelta {
+		t.Errorf("expected Type AgentEventReasoningDelta, got %q", event.Type)
+	}
+	if event.SessionID != "session-1" || event.MessageID != "msg-1" {
+		t.Errorf("unexpected IDs: %q, %q", event.SessionID, event.MessageID)
+	}
+	if event.ReasoningDelta != "Let me think." {
+		t.Errorf("expected ReasoningDelta 'Let me think.', got %q", event.ReasoningDelta)
+	}
+	if event.TextDelta != "" {
+		t.Error("TextDelta should be empty")
+	}
+}
+
+func TestCompletionInfo_Truncated(t *testing.T) {
+	if !(CompletionInfo{FinishReason: FinishReasonLength}).Truncated() {
+		t.Error("a length finish should be truncated")
+	}
+	if (CompletionInfo{FinishReason: FinishReasonStop}).Truncated() {
+		t.Error("a stop finish should not be truncated")
+	}
+}
+
 func TestNewToolCallEvent(t *testing.T) {
 	t.Run("creates tool call event with correct fields", func(t *testing.T) {
 		tc := ToolCallInfo{
@@ -186,7 +215,7 @@ func TestNewToolResultEvent(t *testing.T) {
 func TestNewCompleteEvent(t *testing.T) {
 	t.Run("creates complete event with correct fields", func(t *testing.T) {
 		before := time.Now()
-		event := NewCompleteEvent("session-1", "msg-1")
+		event := NewCompleteEvent("session-1", "msg-1", CompletionInfo{FinishReason: FinishReasonStop, OutputTokens: 12})
 		after := time.Now()
 
 		if event.SessionID != "session-1" {
@@ -201,8 +230,11 @@ func TestNewCompleteEvent(t *testing.T) {
 		if event.Timestamp.Before(before) || event.Timestamp.After(after) {
 			t.Error("timestamp should be within test bounds")
 		}
+		if event.Completion == nil || event.Completion.FinishReason != FinishReasonStop || event.Completion.OutputTokens != 12 {
+			t.Errorf("unexpected Completion: %+v", event.Completion)
+		}
 
-		// All payload fields should be zero
+		// Other payload fields should be zero
 		if event.TextDelta != "" {
 			t.Error("TextDelta should be empty")
 		}
@@ -255,6 +287,24 @@ func TestNewErrorEvent(t *testing.T) {
 	})
 }
 
+func TestNewRetryingEvent(t *testing.T) {
+	testErr := errors.New("overloaded")
+	event := NewRetryingEvent("session-1", testErr, RetryInfo{Attempt: 2, MaxAttempts: 4, Delay: 3 * time.Second})
+
+	if event.Type != AgentEventRetrying {
+		t.Errorf("expected Type AgentEventRetrying, got %q", event.Type)
+	}
+	if event.SessionID != "session-1" {
+		t.Errorf("expected SessionID 'session-1', got %q", event.SessionID)
+	}
+	if event.Error != testErr {
+		t.Errorf("expected Error to be testErr, got %v", event.Error)
+	}
+	if event.Retry == nil || event.Retry.Attempt != 2 || event.Retry.MaxAttempts != 4 || event.Retry.Delay != 3*time.Second {
+		t.Errorf("unexpected retry info %+v", event.Retry)
+	}
+}
+
 func TestNewCancelledEvent(t *testing.T) {
 	t.Run("creates cancelled event with correct fields", func(t *testing.T) {
 		before := time.Now()
@@ -0,0 +1,48 @@
+package events
+
+import (
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+// ConfigEventType represents config event types.
+type ConfigEventType string
+
+// Config event type constants.
+const (
+	ConfigEventReloaded     ConfigEventType = "reloaded"
+	ConfigEventReloadFailed ConfigEventType = "reload_failed"
+)
+
+// ConfigEvent reports that cdd.json or custom-providers.json changed on disk
+// and the configuration was loaded again.
+type ConfigEvent struct { //nolint:govet // fieldalignment: preserving logical field order
+	Path      string // The file that changed
+	Type      ConfigEventType
+	Timestamp time.Time
+
+	// Optional fields
+	Config *config.Config // For Reloaded; its known providers are those now available
+	Error  error          // For ReloadFailed
+}
+
+// NewConfigReloadedEvent creates a config reloaded event.
+func NewConfigReloadedEvent(path string, cfg *config.Config) ConfigEvent {
+	return ConfigEvent{
+		Path:      path,
+		Type:      ConfigEventReloaded,
+		Config:    cfg,
+		Timestamp: time.Now(),
+	}
+}
+
+// NewConfigReloadFailedEvent creates a config reload failed event.
+func NewConfigReloadFailedEvent(path string, err error) ConfigEvent {
+	return ConfigEvent{
+		Path:      path,
+		Type:      ConfigEventReloadFailed,
+		Error:     err,
+		Timestamp: time.Now(),
+	}
+}
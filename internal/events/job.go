@@ -0,0 +1,43 @@
+package events
+
+import "time"
+
+// JobEventType represents background job event types.
+type JobEventType string
+
+// Job event type constants.
+const (
+	JobEventStarted   JobEventType = "started"
+	JobEventProgress  JobEventType = "progress"
+	JobEventCompleted JobEventType = "completed"
+	JobEventFailed    JobEventType = "failed"
+	JobEventCancelled JobEventType = "cancelled"
+)
+
+// JobEvent reports on a prompt running in the background, in a session of
+// its own.
+type JobEvent struct {
+	JobID     int
+	SessionID string
+	Prompt    string
+	Type      JobEventType
+	Activity  string // What the job is doing, e.g. the tool it called last
+	Error     error
+	Timestamp time.Time
+}
+
+// NewJobEvent creates a job event of the given type.
+func NewJobEvent(eventType JobEventType, jobID int, sessionID, prompt string) JobEvent {
+	return JobEvent{
+		JobID:     jobID,
+		SessionID: sessionID,
+		Prompt:    prompt,
+		Type:      eventType,
+		Timestamp: time.Now(),
+	}
+}
+
+// Finished reports whether the event ends the job.
+func (e JobEvent) Finished() bool {
+	return e.Type == JobEventCompleted || e.Type == JobEventFailed || e.Type == JobEventCancelled
+}
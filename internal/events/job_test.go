@@ -0,0 +1,22 @@
+package events
+
+import "testing"
+
+func TestJobEvent_Finished(t *testing.T) {
+	tests := []struct {
+		eventType JobEventType
+		want      bool
+	}{
+		{JobEventStarted, false},
+		{JobEventProgress, false},
+		{JobEventCompleted, true},
+		{JobEventFailed, true},
+		{JobEventCancelled, true},
+	}
+	for _, tt := range tests {
+		e := NewJobEvent(tt.eventType, 1, "session-1", "build")
+		if got := e.Finished(); got != tt.want {
+			t.Errorf("Finished() for %s = %v, want %v", tt.eventType, got, tt.want)
+		}
+	}
+}
@@ -29,6 +29,10 @@ type ToolEvent struct { //nolint:govet // fieldalignment: preserving logical fie
 	Error    error         // For Failed
 	Duration time.Duration // For Completed/Failed
 	Progress float64       // For Progress (0.0-1.0)
+	Step     string        // For Progress (what the tool is doing, e.g. "12s elapsed")
+	Chunk    string        // For Progress (streamed output)
+	FilePath string        // For Progress (file read or changed)
+	Diff     string        // For Progress (unified diff of file changes)
 }
 
 // NewToolStartedEvent creates a tool started event.
@@ -80,3 +84,48 @@ func NewToolProgressEvent(sessionID, toolCallID, toolName string, progress float
 		Timestamp:  time.Now(),
 	}
 }
+
+// NewToolStepEvent creates a progress event describing what a long-running tool
+// is doing, with the fraction done when it is known and 0 otherwise.
+func NewToolStepEvent(sessionID, toolCallID, toolName, step string, progress float64) ToolEvent {
+	event := NewToolProgressEvent(sessionID, toolCallID, toolName, progress)
+	event.Step = step
+	return event
+}
+
+// NewToolOutputEvent creates a progress event carrying a chunk of streamed output.
+func NewToolOutputEvent(sessionID, toolCallID, toolName, chunk string) ToolEvent {
+	return ToolEvent{
+		SessionID:  sessionID,
+		ToolCallID: toolCallID,
+		ToolName:   toolName,
+		Type:       ToolEventProgress,
+		Chunk:      chunk,
+		Timestamp:  time.Now(),
+	}
+}
+
+// NewToolDiffEvent creates a progress event carrying a unified diff of a file change.
+func NewToolDiffEvent(sessionID, toolCallID, toolName, filePath, diff string) ToolEvent {
+	return ToolEvent{
+		SessionID:  sessionID,
+		ToolCallID: toolCallID,
+		ToolName:   toolName,
+		Type:       ToolEventProgress,
+		FilePath:   filePath,
+		Diff:       diff,
+		Timestamp:  time.Now(),
+	}
+}
+
+// NewToolFileEvent creates a progress event recording a file the tool read or wrote.
+func NewToolFileEvent(sessionID, toolCallID, toolName, filePath string) ToolEvent {
+	return ToolEvent{
+		SessionID:  sessionID,
+		ToolCallID: toolCallID,
+		ToolName:   toolName,
+		Type:       ToolEventProgress,
+		FilePath:   filePath,
+		Timestamp:  time.Now(),
+	}
+}
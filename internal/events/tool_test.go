@@ -232,6 +232,54 @@ func TestNewToolProgressEvent(t *testing.T) {
 	})
 }
 
+func TestNewToolStepEvent(t *testing.T) {
+	event := NewToolStepEvent("session-1", "tc-1", "read_file", "1.0 MB of 4.0 MB", 0.25)
+
+	if event.Type != ToolEventProgress {
+		t.Errorf("expected Type ToolEventProgress, got %q", event.Type)
+	}
+	if event.Step != "1.0 MB of 4.0 MB" || event.Progress != 0.25 {
+		t.Errorf("expected the step and progress, got %q, %f", event.Step, event.Progress)
+	}
+	if event.Chunk != "" || event.Diff != "" {
+		t.Error("Chunk and Diff should be empty")
+	}
+}
+
+func TestNewToolDiffEvent(t *testing.T) {
+	event := NewToolDiffEvent("session-1", "tc-1", "edit_file", "/tmp/a.go", "@@ -1 +1 @@\n-a\n+b\n")
+
+	if event.Type != ToolEventProgress {
+		t.Errorf("expected Type ToolEventProgress, got %q", event.Type)
+	}
+	if event.ToolCallID != "tc-1" || event.ToolName != "edit_file" {
+		t.Errorf("unexpected identifiers: %+v", event)
+	}
+	if event.FilePath != "/tmp/a.go" {
+		t.Errorf("expected FilePath '/tmp/a.go', got %q", event.FilePath)
+	}
+	if event.Diff == "" {
+		t.Error("Diff should be set")
+	}
+	if event.Chunk != "" || event.Progress != 0 {
+		t.Error("Chunk and Progress should be zero")
+	}
+}
+
+func TestNewToolFileEvent(t *testing.T) {
+	event := NewToolFileEvent("session-1", "tc-1", "read_file", "/tmp/a.go")
+
+	if event.Type != ToolEventProgress {
+		t.Errorf("expected Type ToolEventProgress, got %q", event.Type)
+	}
+	if event.FilePath != "/tmp/a.go" {
+		t.Errorf("expected FilePath '/tmp/a.go', got %q", event.FilePath)
+	}
+	if event.Diff != "" || event.Chunk != "" {
+		t.Error("Diff and Chunk should be empty")
+	}
+}
+
 func TestToolEventStruct(t *testing.T) {
 	t.Run("all fields accessible", func(t *testing.T) {
 		testErr := errors.New("test error")
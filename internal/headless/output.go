@@ -0,0 +1,185 @@
+package headless
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/events"
+)
+
+// textWriter streams assistant text to out and tool activity to errOut.
+type textWriter struct {
+	out      io.Writer
+	errOut   io.Writer
+	lastByte byte
+}
+
+func newTextWriter(out, errOut io.Writer) *textWriter {
+	return &textWriter{out: out, errOut: errOut}
+}
+
+func (w *textWriter) event(e events.AgentEvent) {
+	//nolint:exhaustive // Terminal events are reported by finish
+	switch e.Type {
+	case events.AgentEventTextDelta:
+		if e.TextDelta == "" {
+			return
+		}
+		fmt.Fprint(w.out, e.TextDelta)
+		w.lastByte = e.TextDelta[len(e.TextDelta)-1]
+	case events.AgentEventToolCall:
+		if e.ToolCall != nil {
+			fmt.Fprintf(w.errOut, "→ %s %s\n", e.ToolCall.Name, oneLine(e.ToolCall.Input))
+		}
+	case events.AgentEventToolResult:
+		if e.ToolResult != nil && e.ToolResult.IsError {
+			fmt.Fprintf(w.errOut, "✗ %s: %s\n", e.ToolResult.Name, oneLine(e.ToolResult.Content))
+		}
+	case events.AgentEventComplete:
+		if e.Completion != nil && e.Completion.Truncated() {
+			fmt.Fprintln(w.errOut, "⚠ reply cut off by the max tokens limit")
+		}
+	case events.AgentEventRetrying:
+		if e.Retry != nil {
+			fmt.Fprintf(w.errOut, "↻ retrying in %s (attempt %d/%d): %s\n",
+				e.Retry.Delay.Round(time.Millisecond), e.Retry.Attempt, e.Retry.MaxAttempts, oneLine(errorString(e.Error)))
+		}
+	}
+}
+
+func (w *textWriter) finish(_, _ string, _ error) {
+	// Terminate the streamed answer so shell prompts start on a fresh line.
+	if w.lastByte != 0 && w.lastByte != '\n' {
+		fmt.Fprintln(w.out)
+	}
+}
+
+// jsonEvent is a single line of JSON output.
+type jsonEvent struct {
+	Type       string `json:"type"`
+	SessionID  string `json:"session_id"`
+	MessageID  string `json:"message_id,omitempty"`
+	Text       string `json:"text,omitempty"`
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	ToolName   string `json:"tool_name,omitempty"`
+	Input      string `json:"input,omitempty"`
+	Content    string `json:"content,omitempty"`
+	IsError    bool   `json:"is_error,omitempty"`
+	Status     string `json:"status,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Attempt    int    `json:"attempt,omitempty"`
+	DelayMS    int64  `json:"delay_ms,omitempty"`
+
+	// Set on the result
+	FinishReason string     `json:"finish_reason,omitempty"`
+	Usage        *jsonUsage `json:"usage,omitempty"`
+}
+
+// jsonUsage is the token usage of a run.
+type jsonUsage struct {
+	InputTokens         int64 `json:"input_tokens"`
+	OutputTokens        int64 `json:"output_tokens"`
+	ReasoningTokens     int64 `json:"reasoning_tokens,omitempty"`
+	CacheCreationTokens int64 `json:"cache_creation_tokens,omitempty"`
+	CacheReadTokens     int64 `json:"cache_read_tokens,omitempty"`
+}
+
+// jsonWriter emits newline-delimited JSON events.
+type jsonWriter struct {
+	enc        *json.Encoder
+	completion *events.CompletionInfo
+	text       strings.Builder
+}
+
+func newJSONWriter(out io.Writer) *jsonWriter {
+	return &jsonWriter{enc: json.NewEncoder(out)}
+}
+
+func (w *jsonWriter) event(e events.AgentEvent) {
+	out := jsonEvent{
+		Type:      string(e.Type),
+		SessionID: e.SessionID,
+		MessageID: e.MessageID,
+	}
+
+	//nolint:exhaustive // Terminal events are reported by finish
+	switch e.Type {
+	case events.AgentEventTextDelta:
+		out.Text = e.TextDelta
+		w.text.WriteString(e.TextDelta)
+	case events.AgentEventToolCall:
+		if e.ToolCall == nil {
+			return
+		}
+		out.ToolCallID = e.ToolCall.ID
+		out.ToolName = e.ToolCall.Name
+		out.Input = e.ToolCall.Input
+	case events.AgentEventToolResult:
+		if e.ToolResult == nil {
+			return
+		}
+		out.ToolCallID = e.ToolResult.ToolCallID
+		out.ToolName = e.ToolResult.Name
+		out.Content = e.ToolResult.Content
+		out.IsError = e.ToolResult.IsError
+	case events.AgentEventRetrying:
+		if e.Retry == nil {
+			return
+		}
+		out.Attempt = e.Retry.Attempt
+		out.DelayMS = e.Retry.Delay.Milliseconds()
+		out.Error = errorString(e.Error)
+	case events.AgentEventComplete:
+		// Reported with the result
+		w.completion = e.Completion
+		return
+	default:
+		return
+	}
+
+	w.enc.Encode(out) //nolint:errcheck,gosec // Best effort output
+}
+
+func (w *jsonWriter) finish(sessionID, status string, err error) {
+	out := jsonEvent{
+		Type:      "result",
+		SessionID: sessionID,
+		Text:      w.text.String(),
+		Status:    status,
+	}
+	if err != nil {
+		out.Error = err.Error()
+	}
+	if c := w.completion; c != nil {
+		out.FinishReason = c.FinishReason
+		out.Usage = &jsonUsage{
+			InputTokens:         c.InputTokens,
+			OutputTokens:        c.OutputTokens,
+			ReasoningTokens:     c.ReasoningTokens,
+			CacheCreationTokens: c.CacheCreationTokens,
+			CacheReadTokens:     c.CacheReadTokens,
+		}
+	}
+	w.enc.Encode(out) //nolint:errcheck,gosec // Best effort output
+}
+
+// errorString returns err's message, or an empty string for nil.
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// oneLine collapses whitespace so tool activity fits on a single line.
+func oneLine(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	const maxLen = 120
+	if len(s) > maxLen {
+		return s[:maxLen-3] + "..."
+	}
+	return s
+}
@@ -0,0 +1,143 @@
+// Package headless runs the agent without the TUI, for scripts and CI pipelines.
+package headless
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+// Run status values reported in the final result.
+const (
+	StatusSuccess   = "success"
+	StatusError     = "error"
+	StatusCancelled = "cancelled"
+)
+
+// maxTitleLength is the maximum length of a session title derived from the prompt.
+const maxTitleLength = 50
+
+// Options configures a single headless run.
+type Options struct {
+	SessionID      string // Continue this session instead of creating a new one
+	MaxTurns       int    // Maximum model steps (0 means unlimited)
+	ThinkingBudget int64  // Extended thinking tokens for Anthropic models (0 disables)
+	JSON           bool   // Emit newline-delimited JSON events instead of plain text
+}
+
+// Runner executes prompts against an agent and writes the streamed output.
+type Runner struct {
+	agent  *agent.DefaultAgent
+	hub    *pubsub.Hub
+	out    io.Writer
+	errOut io.Writer
+}
+
+// NewRunner creates a new headless runner.
+// Assistant output goes to out; tool activity in text mode goes to errOut.
+func NewRunner(ag *agent.DefaultAgent, hub *pubsub.Hub, out, errOut io.Writer) *Runner {
+	return &Runner{
+		agent:  ag,
+		hub:    hub,
+		out:    out,
+		errOut: errOut,
+	}
+}
+
+// Run sends prompt to the agent and streams the response until it finishes.
+// The returned error is the agent's final error, if any.
+func (r *Runner) Run(ctx context.Context, prompt string, opts Options) error {
+	sessionID, err := r.resolveSession(opts.SessionID, prompt)
+	if err != nil {
+		return err
+	}
+
+	var w eventWriter
+	if opts.JSON {
+		w = newJSONWriter(r.out)
+	} else {
+		w = newTextWriter(r.out, r.errOut)
+	}
+
+	// Subscribe before sending so no early events are missed. The output is
+	// built from the events, so a slow stdout holds up the agent rather than
+	// losing text.
+	subCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	agentEvents := r.hub.Agent.Subscribe(subCtx,
+		pubsub.WithSubscriberName("headless"),
+		pubsub.WithSubscriberPolicy(pubsub.Block, 0))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range agentEvents {
+			if event.Payload.SessionID != sessionID {
+				continue
+			}
+			w.event(event.Payload)
+		}
+	}()
+
+	sendErr := r.agent.Send(ctx, prompt, agent.SendOptions{
+		SessionID:      sessionID,
+		MaxTurns:       opts.MaxTurns,
+		ThinkingBudget: opts.ThinkingBudget,
+	}, agent.StreamCallbacks{})
+
+	// Closing the subscription lets the reader drain buffered events and exit.
+	stop()
+	<-done
+
+	w.finish(sessionID, runStatus(sendErr), sendErr)
+	return sendErr
+}
+
+// resolveSession returns the session to run in, creating one if needed.
+func (r *Runner) resolveSession(sessionID, prompt string) (string, error) {
+	sessions := r.agent.Sessions()
+	if sessionID != "" {
+		if _, ok := sessions.Get(sessionID); !ok {
+			return "", fmt.Errorf("session %q not found", sessionID)
+		}
+		sessions.SetCurrent(sessionID)
+		return sessionID, nil
+	}
+	return sessions.Create(sessionTitle(prompt)).ID, nil
+}
+
+// runStatus maps the agent's final error to a run status.
+func runStatus(err error) string {
+	switch {
+	case err == nil:
+		return StatusSuccess
+	case errors.Is(err, context.Canceled):
+		return StatusCancelled
+	default:
+		return StatusError
+	}
+}
+
+// sessionTitle derives a session title from the first line of the prompt.
+func sessionTitle(prompt string) string {
+	title := strings.TrimSpace(prompt)
+	if idx := strings.IndexByte(title, '\n'); idx >= 0 {
+		title = strings.TrimSpace(title[:idx])
+	}
+	if len(title) > maxTitleLength {
+		title = title[:maxTitleLength-3] + "..."
+	}
+	return title
+}
+
+// eventWriter renders agent events for a headless run.
+type eventWriter interface {
+	event(e events.AgentEvent)
+	finish(sessionID, status string, err error)
+}
@@ -0,0 +1,201 @@
+package headless
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+// streamModel is a fantasy.LanguageModel that streams fixed text.
+type streamModel struct {
+	chunks []string
+	err    error
+}
+
+func (m *streamModel) Generate(_ context.Context, _ fantasy.Call) (*fantasy.Response, error) {
+	return &fantasy.Response{}, nil
+}
+
+func (m *streamModel) Stream(_ context.Context, _ fantasy.Call) (fantasy.StreamResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return func(yield func(fantasy.StreamPart) bool) {
+		if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeTextStart, ID: "t1"}) {
+			return
+		}
+		for _, c := range m.chunks {
+			if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeTextDelta, ID: "t1", Delta: c}) {
+				return
+			}
+		}
+		if !yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeTextEnd, ID: "t1"}) {
+			return
+		}
+		yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeFinish, FinishReason: fantasy.FinishReasonStop})
+	}, nil
+}
+
+func (m *streamModel) GenerateObject(_ context.Context, _ fantasy.ObjectCall) (*fantasy.ObjectResponse, error) {
+	return &fantasy.ObjectResponse{}, nil
+}
+
+func (m *streamModel) StreamObject(_ context.Context, _ fantasy.ObjectCall) (fantasy.ObjectStreamResponse, error) {
+	return func(yield func(fantasy.ObjectStreamPart) bool) {}, nil
+}
+
+func (m *streamModel) Provider() string { return "mock" }
+func (m *streamModel) Model() string    { return "mock-model" }
+
+func newTestRunner(t *testing.T, model fantasy.LanguageModel) (*Runner, *agent.DefaultAgent, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	hub := pubsub.NewHub()
+	t.Cleanup(hub.Shutdown)
+
+	ag := agent.New(agent.Config{Model: model, Hub: hub})
+	var out, errOut bytes.Buffer
+	return NewRunner(ag, hub, &out, &errOut), ag, &out, &errOut
+}
+
+func TestRunner_TextOutput(t *testing.T) {
+	r, _, out, _ := newTestRunner(t, &streamModel{chunks: []string{"Hello", ", world"}})
+
+	if err := r.Run(context.Background(), "hi", Options{}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := out.String(); got != "Hello, world\n" {
+		t.Errorf("output = %q, want %q", got, "Hello, world\n")
+	}
+}
+
+// slowWriter is a stdout that takes a while to accept each write.
+type slowWriter struct {
+	bytes.Buffer
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(100 * time.Microsecond)
+	return w.Buffer.Write(p)
+}
+
+func TestRunner_SlowOutput(t *testing.T) {
+	chunks := make([]string, 500) // Well past a subscription's buffer
+	for i := range chunks {
+		chunks[i] = "x"
+	}
+	hub := pubsub.NewHub()
+	defer hub.Shutdown()
+	ag := agent.New(agent.Config{Model: &streamModel{chunks: chunks}, Hub: hub})
+	var out slowWriter
+	r := NewRunner(ag, hub, &out, &bytes.Buffer{})
+
+	if err := r.Run(context.Background(), "hi", Options{}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := strings.Repeat("x", len(chunks)) + "\n"; out.String() != want {
+		t.Errorf("output has %d bytes, want all %d", out.Len(), len(want))
+	}
+}
+
+func TestRunner_JSONOutput(t *testing.T) {
+	r, _, out, _ := newTestRunner(t, &streamModel{chunks: []string{"a", "b"}})
+
+	if err := r.Run(context.Background(), "hi", Options{JSON: true}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) < 1 {
+		t.Fatal("expected JSON output")
+	}
+
+	var last jsonEvent
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &last); err != nil {
+		t.Fatalf("invalid JSON line %q: %v", lines[len(lines)-1], err)
+	}
+	if last.Type != "result" || last.Status != StatusSuccess {
+		t.Errorf("last event = %+v, want success result", last)
+	}
+	if last.Text != "ab" {
+		t.Errorf("result text = %q, want %q", last.Text, "ab")
+	}
+	if last.FinishReason != "stop" || last.Usage == nil {
+		t.Errorf("result should report the finish reason and usage, got %+v", last)
+	}
+	if last.SessionID == "" {
+		t.Error("result should carry session ID")
+	}
+}
+
+func TestRunner_Error(t *testing.T) {
+	wantErr := errors.New("boom")
+	r, _, out, _ := newTestRunner(t, &streamModel{err: wantErr})
+
+	err := r.Run(context.Background(), "hi", Options{JSON: true})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("Run() error = %v, want %v", err, wantErr)
+	}
+
+	var last jsonEvent
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &last); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if last.Status != StatusError || last.Error == "" {
+		t.Errorf("last event = %+v, want error result", last)
+	}
+}
+
+func TestRunner_ExistingSession(t *testing.T) {
+	r, ag, _, _ := newTestRunner(t, &streamModel{chunks: []string{"ok"}})
+	sess := ag.Sessions().Create("existing")
+
+	if err := r.Run(context.Background(), "hi", Options{SessionID: sess.ID}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := len(ag.Sessions().GetMessages(sess.ID)); got != 2 {
+		t.Errorf("session has %d messages, want 2", got)
+	}
+}
+
+func TestRunner_UnknownSession(t *testing.T) {
+	r, _, _, _ := newTestRunner(t, &streamModel{})
+
+	if err := r.Run(context.Background(), "hi", Options{SessionID: "missing"}); err == nil {
+		t.Error("expected error for unknown session")
+	}
+}
+
+func TestRunStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, StatusSuccess},
+		{context.Canceled, StatusCancelled},
+		{errors.New("x"), StatusError},
+	}
+	for _, tt := range tests {
+		if got := runStatus(tt.err); got != tt.want {
+			t.Errorf("runStatus(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSessionTitle(t *testing.T) {
+	if got := sessionTitle("  fix the bug\nmore details"); got != "fix the bug" {
+		t.Errorf("sessionTitle() = %q", got)
+	}
+	if got := sessionTitle(strings.Repeat("x", 80)); len(got) != maxTitleLength {
+		t.Errorf("sessionTitle() length = %d, want %d", len(got), maxTitleLength)
+	}
+}
@@ -0,0 +1,67 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/db/sqlc"
+)
+
+// SQLiteStore implements Store using SQLite.
+type SQLiteStore struct {
+	queries *sqlc.Queries
+	keep    int // Prompts kept per project
+}
+
+// NewSQLiteStore creates a new SQLite-backed prompt history store.
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{
+		queries: sqlc.New(db),
+		keep:    MaxEntries,
+	}
+}
+
+// Add records a prompt and prunes the project's oldest prompts.
+func (s *SQLiteStore) Add(ctx context.Context, project, sessionID, prompt string) error {
+	err := s.queries.AddPrompt(ctx, sqlc.AddPromptParams{
+		Project:   project,
+		SessionID: sessionID,
+		Prompt:    prompt,
+		CreatedAt: time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return fmt.Errorf("adding prompt: %w", err)
+	}
+
+	err = s.queries.PrunePrompts(ctx, sqlc.PrunePromptsParams{
+		Project: project,
+		Offset:  int64(s.keep),
+	})
+	if err != nil {
+		return fmt.Errorf("pruning prompt history: %w", err)
+	}
+	return nil
+}
+
+// List returns up to limit prompts of project, newest first.
+func (s *SQLiteStore) List(ctx context.Context, project string, limit int) ([]Entry, error) {
+	rows, err := s.queries.ListPrompts(ctx, sqlc.ListPromptsParams{
+		Project: project,
+		Limit:   int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing prompt history: %w", err)
+	}
+
+	entries := make([]Entry, len(rows))
+	for i, row := range rows {
+		entries[i] = Entry{
+			Prompt:    row.Prompt,
+			SessionID: row.SessionID,
+			CreatedAt: time.UnixMilli(row.CreatedAt),
+		}
+	}
+	return entries, nil
+}
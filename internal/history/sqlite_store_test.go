@@ -0,0 +1,76 @@
+package history
+
+import (
+	"context"
+	"testing"
+
+	"github.com/guilhermegouw/cdd/internal/db"
+)
+
+func setupTestStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+
+	database, err := db.Open(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() }) //nolint:errcheck // Intentionally ignoring close error in test cleanup
+
+	return NewSQLiteStore(database.Conn())
+}
+
+func TestSQLiteStore_AddAndList(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+
+	for _, add := range []struct{ project, session, prompt string }{
+		{"/repo/a", "s1", "first"},
+		{"/repo/b", "s2", "other project"},
+		{"/repo/a", "s3", "second"},
+	} {
+		if err := store.Add(ctx, add.project, add.session, add.prompt); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	entries, err := store.List(ctx, "/repo/a", 10)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Prompt != "second" || entries[1].Prompt != "first" {
+		t.Fatalf("List() = %+v, want second then first", entries)
+	}
+	if entries[0].SessionID != "s3" || entries[0].CreatedAt.IsZero() {
+		t.Errorf("unexpected entry %+v", entries[0])
+	}
+
+	if entries, _ := store.List(ctx, "/repo/a", 1); len(entries) != 1 || entries[0].Prompt != "second" {
+		t.Errorf("List() with limit 1 = %+v", entries)
+	}
+}
+
+func TestSQLiteStore_Prunes(t *testing.T) {
+	store := setupTestStore(t)
+	store.keep = 3
+	ctx := context.Background()
+
+	for range 5 {
+		if err := store.Add(ctx, "/repo", "s1", "prompt"); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	if err := store.Add(ctx, "/other", "s2", "kept"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	entries, err := store.List(ctx, "/repo", 10)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("kept %d prompts, want 3", len(entries))
+	}
+	if entries, _ := store.List(ctx, "/other", 10); len(entries) != 1 {
+		t.Errorf("pruning should not touch other projects, got %d", len(entries))
+	}
+}
@@ -0,0 +1,28 @@
+// Package history persists the prompts sent from the chat input so they can
+// be recalled later, per project.
+package history
+
+import (
+	"context"
+	"time"
+)
+
+// MaxEntries is how many prompts are kept per project. Older ones are pruned
+// as new prompts are added.
+const MaxEntries = 1000
+
+// Entry is a prompt sent from the chat input.
+type Entry struct {
+	Prompt    string
+	SessionID string
+	CreatedAt time.Time
+}
+
+// Store defines the interface for prompt history persistence.
+type Store interface {
+	// Add records a prompt sent in a session of project.
+	Add(ctx context.Context, project, sessionID, prompt string) error
+
+	// List returns up to limit prompts of project, newest first.
+	List(ctx context.Context, project string, limit int) ([]Entry, error)
+}
@@ -0,0 +1,216 @@
+// Package hooks runs user-configured shell commands on agent lifecycle
+// events, such as formatting files after every write or vetoing risky tool
+// calls.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/debug"
+)
+
+// defaultTimeout bounds a hook that sets no timeout of its own.
+const defaultTimeout = 60 * time.Second
+
+// Event names a point in the agent's lifecycle where hooks run.
+type Event string
+
+// Hook events.
+const (
+	EventPreTool      Event = "pre_tool"
+	EventPostTool     Event = "post_tool"
+	EventComplete     Event = "on_complete"
+	EventSessionStart Event = "on_session_start"
+)
+
+// ErrBlocked is returned when a pre_tool hook refuses a tool call.
+var ErrBlocked = errors.New("blocked by hook")
+
+// Tool describes the tool call a hook runs for.
+type Tool struct {
+	ID    string          `json:"id"`
+	Name  string          `json:"name"`
+	Input json.RawMessage `json:"input"`
+}
+
+// NewTool describes a tool call whose input is normally a JSON object. Input
+// that is not valid JSON is passed to hooks as a string.
+func NewTool(id, name, input string) Tool {
+	raw := json.RawMessage(input)
+	if !json.Valid(raw) {
+		raw, _ = json.Marshal(input) //nolint:errcheck // Strings always encode
+	}
+	return Tool{ID: id, Name: name, Input: raw}
+}
+
+// Result is the outcome of a tool call, given to post_tool hooks.
+type Result struct {
+	Content string `json:"content"`
+	IsError bool   `json:"is_error"`
+}
+
+// Payload is the JSON a hook reads from stdin.
+type Payload struct {
+	Event      Event   `json:"event"`
+	SessionID  string  `json:"session_id"`
+	WorkingDir string  `json:"working_dir"`
+	Tool       *Tool   `json:"tool,omitempty"`
+	Result     *Result `json:"result,omitempty"`
+}
+
+// Runner runs the configured hooks in the working directory.
+type Runner struct {
+	workingDir string // Used unless the context names the session's, see WithWorkingDir
+	hooks      config.HooksConfig
+}
+
+// workingDirKey is the context key of the session's working directory.
+type workingDirKey struct{}
+
+// WithWorkingDir sets the directory hooks run in for the session behind
+// ctx, when /cd moved it away from the runner's.
+func WithWorkingDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, workingDirKey{}, dir)
+}
+
+// dir returns the directory hooks run in for ctx.
+func (r *Runner) dir(ctx context.Context) string {
+	if dir, ok := ctx.Value(workingDirKey{}).(string); ok && dir != "" {
+		return dir
+	}
+	return r.workingDir
+}
+
+// New creates a runner for cfg, or returns nil when no hooks are configured.
+func New(workingDir string, cfg *config.HooksConfig) *Runner {
+	if cfg == nil || len(cfg.PreTool)+len(cfg.PostTool)+len(cfg.OnComplete)+len(cfg.OnSessionStart) == 0 {
+		return nil
+	}
+	return &Runner{workingDir: workingDir, hooks: *cfg}
+}
+
+// PreTool runs the pre_tool hooks for a tool call and returns the input to
+// call the tool with. A hook that exits non-zero blocks the call: the error
+// wraps ErrBlocked and carries the hook's output as the reason. A hook that
+// prints a JSON object replaces the input for the hooks after it and the tool.
+func (r *Runner) PreTool(ctx context.Context, sessionID string, tool Tool) (json.RawMessage, error) {
+	for _, hook := range r.hooks.PreTool {
+		if !appliesTo(hook, tool.Name) {
+			continue
+		}
+		out, err := r.run(ctx, hook, Payload{Event: EventPreTool, SessionID: sessionID, Tool: &tool})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrBlocked, err)
+		}
+		if replaced := bytes.TrimSpace(out); len(replaced) > 0 && replaced[0] == '{' && json.Valid(replaced) {
+			tool.Input = replaced
+		}
+	}
+	return tool.Input, nil
+}
+
+// PostTool runs the post_tool hooks for a finished tool call and returns the
+// output of the ones that failed, for the model to act on, or "" when all
+// succeeded.
+func (r *Runner) PostTool(ctx context.Context, sessionID string, tool Tool, result Result) string {
+	var failures []string
+	for _, hook := range r.hooks.PostTool {
+		if !appliesTo(hook, tool.Name) {
+			continue
+		}
+		payload := Payload{Event: EventPostTool, SessionID: sessionID, Tool: &tool, Result: &result}
+		if _, err := r.run(ctx, hook, payload); err != nil {
+			failures = append(failures, "post_tool hook failed: "+err.Error())
+		}
+	}
+	return strings.Join(failures, "\n")
+}
+
+// Complete runs the on_complete hooks after the agent finished a reply.
+func (r *Runner) Complete(ctx context.Context, sessionID string) {
+	r.runAll(ctx, r.hooks.OnComplete, Payload{Event: EventComplete, SessionID: sessionID})
+}
+
+// SessionStart runs the on_session_start hooks before a session's first
+// prompt.
+func (r *Runner) SessionStart(ctx context.Context, sessionID string) {
+	r.runAll(ctx, r.hooks.OnSessionStart, Payload{Event: EventSessionStart, SessionID: sessionID})
+}
+
+// runAll runs hooks whose outcome does not affect the agent, logging failures.
+func (r *Runner) runAll(ctx context.Context, hooks []config.HookConfig, payload Payload) {
+	for _, hook := range hooks {
+		if _, err := r.run(ctx, hook, payload); err != nil {
+			debug.Log("[HOOKS] %s hook %q: %v", payload.Event, hook.Command, err)
+		}
+	}
+}
+
+// run executes a hook with payload on stdin and returns its stdout. A
+// non-zero exit is an error carrying the hook's stderr, or its stdout when
+// stderr is empty.
+func (r *Runner) run(ctx context.Context, hook config.HookConfig, payload Payload) ([]byte, error) {
+	payload.WorkingDir = r.dir(ctx)
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encoding event: %w", err)
+	}
+
+	timeout := defaultTimeout
+	if hook.Timeout > 0 {
+		timeout = time.Duration(hook.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/c", hook.Command) //nolint:gosec // G204: Hooks are configured by the user.
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", hook.Command) //nolint:gosec // G204: Hooks are configured by the user.
+	}
+	cmd.Dir = payload.WorkingDir
+	cmd.WaitDelay = time.Second // Don't wait on children holding the output pipes
+	cmd.Env = append(os.Environ(), "CDD_EVENT="+string(payload.Event), "CDD_SESSION_ID="+payload.SessionID)
+	if payload.Tool != nil {
+		cmd.Env = append(cmd.Env, "CDD_TOOL_NAME="+payload.Tool.Name)
+	}
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	started := time.Now()
+	err = cmd.Run()
+	debug.Log("[HOOKS] %s %q took %s: %v", payload.Event, hook.Command, time.Since(started).Round(time.Millisecond), err)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %s", timeout)
+		}
+		reason := strings.TrimSpace(stderr.String())
+		if reason == "" {
+			reason = strings.TrimSpace(stdout.String())
+		}
+		if reason == "" {
+			return nil, err
+		}
+		return nil, errors.New(reason)
+	}
+	return stdout.Bytes(), nil
+}
+
+// appliesTo reports whether hook runs for the named tool.
+func appliesTo(hook config.HookConfig, toolName string) bool {
+	return len(hook.Tools) == 0 || slices.Contains(hook.Tools, toolName)
+}
@@ -0,0 +1,115 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+func skipOnWindows(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping hook tests on Windows")
+	}
+}
+
+func TestNew_NoHooks(t *testing.T) {
+	if New(t.TempDir(), nil) != nil || New(t.TempDir(), &config.HooksConfig{}) != nil {
+		t.Error("New() should return nil without hooks")
+	}
+}
+
+func TestRunner_PreTool(t *testing.T) {
+	skipOnWindows(t)
+	ctx := context.Background()
+	tool := NewTool("call-1", "bash", `{"command":"rm -rf /"}`)
+
+	r := New(t.TempDir(), &config.HooksConfig{PreTool: []config.HookConfig{
+		{Command: `grep -q 'rm -rf' && { echo "destructive command" >&2; exit 1; }; exit 0`, Tools: []string{"bash"}},
+	}})
+	if _, err := r.PreTool(ctx, "s1", tool); !errors.Is(err, ErrBlocked) || !strings.Contains(err.Error(), "destructive command") {
+		t.Errorf("PreTool() error = %v, want a block with the hook's reason", err)
+	}
+	if _, err := r.PreTool(ctx, "s1", NewTool("call-2", "read_file", `{"file_path":"rm -rf"}`)); err != nil {
+		t.Errorf("hook limited to bash should not run for read_file, got %v", err)
+	}
+
+	r = New(t.TempDir(), &config.HooksConfig{PreTool: []config.HookConfig{
+		{Command: `echo '{"command":"ls"}'`},
+		{Command: `echo "not json"`},
+	}})
+	input, err := r.PreTool(ctx, "s1", tool)
+	if err != nil || string(input) != `{"command":"ls"}` {
+		t.Errorf("PreTool() = %s, %v, want the input printed by the first hook", input, err)
+	}
+}
+
+func TestRunner_PostTool(t *testing.T) {
+	skipOnWindows(t)
+	dir := t.TempDir()
+	r := New(dir, &config.HooksConfig{PostTool: []config.HookConfig{
+		{Command: `cat > event.json`},
+		{Command: `echo "gofmt: syntax error"; exit 3`, Tools: []string{"write_file"}},
+	}})
+
+	feedback := r.PostTool(context.Background(), "s1", NewTool("call-1", "write_file", `{"file_path":"x.go"}`), Result{Content: "written"})
+	if feedback != "post_tool hook failed: gofmt: syntax error" {
+		t.Errorf("PostTool() = %q", feedback)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "event.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload Payload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Event != EventPostTool || payload.SessionID != "s1" || payload.WorkingDir != dir ||
+		payload.Tool.Name != "write_file" || payload.Result.Content != "written" {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestRunner_Timeout(t *testing.T) {
+	skipOnWindows(t)
+	r := New(t.TempDir(), &config.HooksConfig{PreTool: []config.HookConfig{{Command: "sleep 3", Timeout: 1}}})
+	if _, err := r.PreTool(context.Background(), "s1", NewTool("c", "bash", "{}")); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("PreTool() error = %v, want a timeout", err)
+	}
+}
+
+func TestRunner_SessionStart(t *testing.T) {
+	skipOnWindows(t)
+	dir := t.TempDir()
+	r := New(dir, &config.HooksConfig{OnSessionStart: []config.HookConfig{{Command: `echo "$CDD_EVENT $CDD_SESSION_ID" > started`}}})
+	r.SessionStart(context.Background(), "s1")
+
+	data, err := os.ReadFile(filepath.Join(dir, "started"))
+	if err != nil || strings.TrimSpace(string(data)) != "on_session_start s1" {
+		t.Errorf("hook output = %q, %v", data, err)
+	}
+}
+
+func TestRunner_SessionWorkingDir(t *testing.T) {
+	skipOnWindows(t)
+	moved := t.TempDir()
+	r := New(t.TempDir(), &config.HooksConfig{OnComplete: []config.HookConfig{{Command: "pwd > done"}}})
+	r.Complete(WithWorkingDir(context.Background(), moved), "s1")
+
+	data, err := os.ReadFile(filepath.Join(moved, "done"))
+	if err != nil {
+		t.Fatalf("the hook should run in the session's directory: %v", err)
+	}
+	got, _ := filepath.EvalSymlinks(strings.TrimSpace(string(data))) //nolint:errcheck // Compared below
+	if want, _ := filepath.EvalSymlinks(moved); got != want {        //nolint:errcheck // Compared below
+		t.Errorf("hook ran in %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,216 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+// Embedding providers.
+const (
+	ProviderLocal  = "local"
+	ProviderOpenAI = "openai"
+)
+
+const (
+	defaultEmbeddingModel = "text-embedding-3-small"
+	defaultEmbeddingURL   = "https://api.openai.com/v1"
+	// embedTimeout bounds one embeddings request.
+	embedTimeout = 2 * time.Minute
+	// localDimensions is the size of the vectors the local embedder makes.
+	localDimensions = 512
+)
+
+// Embedder turns texts into vectors whose cosine similarity reflects how
+// related the texts are.
+type Embedder interface {
+	// Name identifies the embedder and model. Files indexed under another
+	// name are embedded again.
+	Name() string
+
+	// Embed returns one vector per text, in order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// NewEmbedder creates the embedder configured in opts.
+func NewEmbedder(opts *config.IndexOptions) (Embedder, error) {
+	switch opts.Provider {
+	case "", ProviderLocal:
+		return localEmbedder{}, nil
+	case ProviderOpenAI:
+		apiKey, err := config.NewResolver().Resolve(opts.APIKey)
+		if err != nil {
+			return nil, fmt.Errorf("resolving options.index.api_key: %w", err)
+		}
+		e := &openAIEmbedder{
+			baseURL: strings.TrimRight(opts.BaseURL, "/"),
+			model:   opts.Model,
+			apiKey:  apiKey,
+			client:  &http.Client{Timeout: embedTimeout},
+		}
+		if e.baseURL == "" {
+			e.baseURL = defaultEmbeddingURL
+		}
+		if e.model == "" {
+			e.model = defaultEmbeddingModel
+		}
+		return e, nil
+	default:
+		return nil, fmt.Errorf("unknown options.index.provider %q (want %q or %q)", opts.Provider, ProviderLocal, ProviderOpenAI)
+	}
+}
+
+// openAIEmbedder calls an OpenAI-compatible /embeddings endpoint, as served
+// by OpenAI, Ollama, LM Studio, vLLM and most gateways.
+type openAIEmbedder struct {
+	baseURL string
+	model   string
+	apiKey  string
+	client  *http.Client
+}
+
+func (e *openAIEmbedder) Name() string {
+	return ProviderOpenAI + ":" + e.model
+}
+
+func (e *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]any{"model": e.model, "input": texts})
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting embeddings: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // Response body
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512)) //nolint:errcheck,mnd // Best-effort error detail
+		return nil, fmt.Errorf("embeddings request failed: %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decoding embeddings: %w", err)
+	}
+	vectors := make([][]float32, len(texts))
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embeddings response has index %d for %d inputs", d.Index, len(texts))
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("embeddings response is missing input %d", i)
+		}
+	}
+	return vectors, nil
+}
+
+// localEmbedder hashes the words of a text into a fixed-size vector. It
+// needs no model or network, but only relates texts that share words.
+type localEmbedder struct{}
+
+func (localEmbedder) Name() string {
+	return ProviderLocal
+}
+
+// wordPattern matches identifiers and words.
+var wordPattern = regexp.MustCompile(`[A-Za-z0-9_]+`)
+
+func (localEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		counts := make(map[string]int)
+		for _, word := range wordPattern.FindAllString(text, -1) {
+			for _, term := range terms(word) {
+				counts[term]++
+			}
+		}
+
+		v := make([]float32, localDimensions)
+		for term, n := range counts {
+			h := fnv.New32a()
+			h.Write([]byte(term)) //nolint:errcheck // Hash writes never fail
+			sum := h.Sum32()
+			weight := float32(1 + math.Log(float64(n)))
+			if sum&(1<<31) != 0 {
+				weight = -weight
+			}
+			v[sum%localDimensions] += weight
+		}
+		vectors[i] = normalize(v)
+	}
+	return vectors, nil
+}
+
+// terms returns a word in lower case together with the parts of a
+// camelCase or snake_case identifier, so that "parseConfig" relates to
+// "config".
+func terms(word string) []string {
+	out := []string{strings.ToLower(word)}
+	var parts []string
+	var current []rune
+	prev := rune(0)
+	for _, r := range word {
+		if r == '_' || (unicode.IsUpper(r) && unicode.IsLower(prev)) {
+			if len(current) > 0 {
+				parts = append(parts, strings.ToLower(string(current)))
+			}
+			current = current[:0]
+		}
+		if r != '_' {
+			current = append(current, r)
+		}
+		prev = r
+	}
+	if len(current) > 0 {
+		parts = append(parts, strings.ToLower(string(current)))
+	}
+	if len(parts) > 1 {
+		out = append(out, parts...)
+	}
+	return out
+}
+
+// normalize scales v to unit length, so that a dot product is the cosine.
+func normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
@@ -0,0 +1,66 @@
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+func TestOpenAIEmbedder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model != "nomic-embed-text" {
+			http.Error(w, "bad body", http.StatusBadRequest)
+			return
+		}
+		// Answer out of order, as the API allows.
+		w.Write([]byte(`{"data": [{"index": 1, "embedding": [0, 1]}, {"index": 0, "embedding": [1, 0]}]}`)) //nolint:errcheck // Test server
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_EMBED_KEY", "secret")
+	e, err := NewEmbedder(&config.IndexOptions{
+		Provider: ProviderOpenAI,
+		Model:    "nomic-embed-text",
+		BaseURL:  server.URL + "/v1/",
+		APIKey:   "$TEST_EMBED_KEY",
+	})
+	if err != nil {
+		t.Fatalf("NewEmbedder() error = %v", err)
+	}
+	if e.Name() != "openai:nomic-embed-text" {
+		t.Errorf("Name() = %q", e.Name())
+	}
+
+	vectors, err := e.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if !slices.Equal(vectors[0], []float32{1, 0}) || !slices.Equal(vectors[1], []float32{0, 1}) {
+		t.Errorf("Embed() = %v, want the vectors in input order", vectors)
+	}
+
+	if _, err := NewEmbedder(&config.IndexOptions{Provider: "word2vec"}); err == nil {
+		t.Error("an unknown provider should be an error")
+	}
+}
+
+func TestTerms(t *testing.T) {
+	got := terms("parseConfig_file")
+	want := []string{"parseconfig_file", "parse", "config", "file"}
+	if !slices.Equal(got, want) {
+		t.Errorf("terms() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,88 @@
+package index
+
+import (
+	"bytes"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// maxFileBytes skips generated and data files too large to be useful.
+	maxFileBytes = 256 * 1024
+	// binaryProbeBytes is how much of a file is checked for NUL bytes.
+	binaryProbeBytes = 8000
+	// chunkLines is how many lines a chunk spans.
+	chunkLines = 60
+	// chunkOverlap is how many lines consecutive chunks share, so that code
+	// at a boundary is whole in one of them.
+	chunkOverlap = 10
+)
+
+// skippedDirs are not walked outside git repositories.
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"dist":         true,
+	"build":        true,
+	"target":       true,
+}
+
+// listFiles returns the files under root, relative to it. In a git
+// repository these are the tracked files and the untracked ones that are
+// not ignored; elsewhere hidden and dependency directories are skipped.
+func listFiles(root string) ([]string, error) {
+	cmd := exec.Command("git", "ls-files", "-z", "--cached", "--others", "--exclude-standard")
+	cmd.Dir = root
+	if out, err := cmd.Output(); err == nil {
+		var paths []string
+		for _, p := range bytes.Split(out, []byte{0}) {
+			if len(p) > 0 {
+				paths = append(paths, filepath.FromSlash(string(p)))
+			}
+		}
+		return paths, nil
+	}
+
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr // Skip unreadable entries
+		}
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || skippedDirs[d.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(d.Name(), ".") {
+			paths = append(paths, rel)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// chunk is a range of lines of a file, numbered from 1.
+type chunk struct {
+	start, end int
+	text       string
+}
+
+// chunkFile splits content into overlapping chunks of chunkLines lines.
+func chunkFile(content string) []chunk {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	var chunks []chunk
+	for start := 0; start < len(lines); start += chunkLines - chunkOverlap {
+		end := min(start+chunkLines, len(lines))
+		text := strings.Join(lines[start:end], "\n")
+		if strings.TrimSpace(text) != "" {
+			chunks = append(chunks, chunk{start: start + 1, end: end, text: text})
+		}
+		if end == len(lines) {
+			break
+		}
+	}
+	return chunks
+}
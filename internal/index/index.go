@@ -0,0 +1,256 @@
+// Package index keeps an embedding index of a project's files in SQLite, so
+// that the agent can find the code relevant to a question without reading
+// the whole repository.
+package index
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/db/sqlc"
+)
+
+// embedBatch is how many chunks are embedded per request.
+const embedBatch = 64
+
+// Result is an indexed chunk matching a search.
+type Result struct {
+	Path      string // Relative to the project root
+	StartLine int
+	EndLine   int
+	Content   string
+	Score     float64 // Cosine similarity to the query
+}
+
+// Stats summarizes an update of the index.
+type Stats struct {
+	Files   int // Files in the index after the update
+	Indexed int // Files embedded by the update
+	Removed int // Files dropped from the index
+	Chunks  int // Chunks embedded by the update
+}
+
+// Index is the embedding index of the files under a project root.
+type Index struct {
+	conn     *sql.DB
+	queries  *sqlc.Queries
+	root     string
+	embedder Embedder
+	mu       sync.Mutex // Serializes updates
+}
+
+// New creates the index of the project at root, stored in conn.
+func New(conn *sql.DB, root string, embedder Embedder) *Index {
+	return &Index{
+		conn:     conn,
+		queries:  sqlc.New(conn),
+		root:     root,
+		embedder: embedder,
+	}
+}
+
+// Update embeds the files that are new or changed since they were last
+// indexed, or were indexed by another embedder, and drops deleted files.
+// progress, when not nil, is called after each file that is embedded.
+func (x *Index) Update(ctx context.Context, progress func(done, total int)) (Stats, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	paths, err := listFiles(x.root)
+	if err != nil {
+		return Stats{}, err
+	}
+	rows, err := x.queries.ListIndexFiles(ctx, x.root)
+	if err != nil {
+		return Stats{}, fmt.Errorf("listing indexed files: %w", err)
+	}
+	indexed := make(map[string]sqlc.IndexFile, len(rows))
+	for _, row := range rows {
+		indexed[row.Path] = row
+	}
+
+	type pending struct {
+		path, hash string
+		content    []byte
+	}
+	var todo []pending
+	current := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		content, ok := readIndexable(filepath.Join(x.root, path))
+		if !ok {
+			continue
+		}
+		current[path] = true
+		sum := sha256.Sum256(content)
+		hash := hex.EncodeToString(sum[:])
+		if row, ok := indexed[path]; ok && row.Hash == hash && row.Model == x.embedder.Name() {
+			continue
+		}
+		todo = append(todo, pending{path: path, hash: hash, content: content})
+	}
+
+	var stats Stats
+	for path := range indexed {
+		if current[path] {
+			continue
+		}
+		if err := x.queries.DeleteIndexFile(ctx, sqlc.DeleteIndexFileParams{Project: x.root, Path: path}); err != nil {
+			return stats, fmt.Errorf("removing %s from the index: %w", path, err)
+		}
+		stats.Removed++
+	}
+
+	for i, file := range todo {
+		chunks, err := x.indexFile(ctx, file.path, file.hash, file.content)
+		if err != nil {
+			return stats, err
+		}
+		stats.Indexed++
+		stats.Chunks += chunks
+		if progress != nil {
+			progress(i+1, len(todo))
+		}
+	}
+	stats.Files = len(current)
+	return stats, nil
+}
+
+// indexFile embeds the chunks of a file and replaces its entries in the
+// index. It returns the number of chunks.
+func (x *Index) indexFile(ctx context.Context, path, hash string, content []byte) (int, error) {
+	chunks := chunkFile(string(content))
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = path + "\n" + c.text // The path often says what the code is about
+	}
+	var vectors [][]float32
+	for start := 0; start < len(texts); start += embedBatch {
+		batch, err := x.embedder.Embed(ctx, texts[start:min(start+embedBatch, len(texts))])
+		if err != nil {
+			return 0, fmt.Errorf("embedding %s: %w", path, err)
+		}
+		vectors = append(vectors, batch...)
+	}
+
+	tx, err := x.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit.
+
+	queries := x.queries.WithTx(tx)
+	if err := queries.DeleteIndexChunks(ctx, sqlc.DeleteIndexChunksParams{Project: x.root, Path: path}); err != nil {
+		return 0, fmt.Errorf("clearing %s: %w", path, err)
+	}
+	err = queries.UpsertIndexFile(ctx, sqlc.UpsertIndexFileParams{
+		Project:   x.root,
+		Path:      path,
+		Hash:      hash,
+		Model:     x.embedder.Name(),
+		IndexedAt: time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("recording %s: %w", path, err)
+	}
+	for i, c := range chunks {
+		err := queries.AddIndexChunk(ctx, sqlc.AddIndexChunkParams{
+			Project:   x.root,
+			Path:      path,
+			StartLine: int64(c.start),
+			EndLine:   int64(c.end),
+			Content:   c.text,
+			Embedding: encodeVector(vectors[i]),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("storing chunk of %s: %w", path, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing %s: %w", path, err)
+	}
+	return len(chunks), nil
+}
+
+// Search returns up to limit indexed chunks most similar to query.
+func (x *Index) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	vectors, err := x.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embedding query: %w", err)
+	}
+	q := normalize(vectors[0])
+
+	rows, err := x.queries.ListIndexChunks(ctx, x.root)
+	if err != nil {
+		return nil, fmt.Errorf("listing indexed chunks: %w", err)
+	}
+	results := make([]Result, 0, len(rows))
+	for _, row := range rows {
+		v := decodeVector(row.Embedding)
+		if len(v) != len(q) {
+			continue // Embedded by another model and not updated yet
+		}
+		results = append(results, Result{
+			Path:      row.Path,
+			StartLine: int(row.StartLine),
+			EndLine:   int(row.EndLine),
+			Content:   row.Content,
+			Score:     dot(q, normalize(v)),
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+// encodeVector stores a vector as little-endian float32s.
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v)) //nolint:mnd // Bytes per float32
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(x))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) []float32 {
+	v := make([]float32, len(buf)/4) //nolint:mnd // Bytes per float32
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v
+}
+
+// readIndexable returns the contents of a text file small enough to index.
+func readIndexable(path string) ([]byte, bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() == 0 || info.Size() > maxFileBytes {
+		return nil, false
+	}
+	content, err := os.ReadFile(path) //nolint:gosec // G304: Files of the user's project.
+	if err != nil || bytes.IndexByte(content[:min(len(content), binaryProbeBytes)], 0) >= 0 {
+		return nil, false
+	}
+	return content, true
+}
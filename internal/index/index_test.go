@@ -0,0 +1,109 @@
+package index
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/guilhermegouw/cdd/internal/db"
+)
+
+func setupTestIndex(t *testing.T) (*Index, string) {
+	t.Helper()
+
+	database, err := db.Open(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() }) //nolint:errcheck // Intentionally ignoring close error in test cleanup
+
+	root := t.TempDir()
+	return New(database.Conn(), root, localEmbedder{}), root
+}
+
+func writeFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIndex_Update(t *testing.T) {
+	idx, root := setupTestIndex(t)
+	ctx := context.Background()
+
+	writeFile(t, root, "config/load.go", "func LoadConfig(path string) (*Config, error) {\n\treturn parseJSON(path)\n}\n")
+	writeFile(t, root, "server/http.go", "func ListenAndServe(addr string, handler Handler) error {\n\treturn nil\n}\n")
+	writeFile(t, root, "node_modules/dep/index.js", "module.exports = {}\n")
+	writeFile(t, root, "logo.png", "\x89PNG\x00\x00")
+
+	stats, err := idx.Update(ctx, nil)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if stats.Files != 2 || stats.Indexed != 2 || stats.Chunks != 2 {
+		t.Errorf("first Update() = %+v, want 2 files indexed in 2 chunks", stats)
+	}
+
+	if stats, _ := idx.Update(ctx, nil); stats.Indexed != 0 {
+		t.Errorf("Update() without changes embedded %d files, want 0", stats.Indexed)
+	}
+
+	writeFile(t, root, "server/http.go", "func Shutdown() {}\n")
+	if err := os.Remove(filepath.Join(root, "config", "load.go")); err != nil {
+		t.Fatal(err)
+	}
+	stats, err = idx.Update(ctx, nil)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if stats.Files != 1 || stats.Indexed != 1 || stats.Removed != 1 {
+		t.Errorf("Update() after changes = %+v, want 1 file re-embedded and 1 removed", stats)
+	}
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx, root := setupTestIndex(t)
+	ctx := context.Background()
+
+	writeFile(t, root, "config/load.go", "func LoadConfig(path string) (*Config, error) {\n\treturn parseJSON(path)\n}\n")
+	writeFile(t, root, "server/http.go", "func ListenAndServe(addr string, handler Handler) error {\n\treturn nil\n}\n")
+	if _, err := idx.Update(ctx, nil); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	results, err := idx.Search(ctx, "where is the config loaded", 1)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Path != filepath.Join("config", "load.go") {
+		t.Fatalf("Search() = %+v, want config/load.go", results)
+	}
+	if results[0].StartLine != 1 || results[0].EndLine != 3 || !strings.Contains(results[0].Content, "LoadConfig") {
+		t.Errorf("result = %+v, want lines 1-3 of the file", results[0])
+	}
+}
+
+func TestChunkFile(t *testing.T) {
+	lines := make([]string, 130)
+	for i := range lines {
+		lines[i] = "line"
+	}
+	chunks := chunkFile(strings.Join(lines, "\n") + "\n")
+
+	want := [][2]int{{1, 60}, {51, 110}, {101, 130}}
+	if len(chunks) != len(want) {
+		t.Fatalf("got %d chunks, want %d", len(chunks), len(want))
+	}
+	for i, c := range chunks {
+		if c.start != want[i][0] || c.end != want[i][1] {
+			t.Errorf("chunk %d covers lines %d-%d, want %d-%d", i, c.start, c.end, want[i][0], want[i][1])
+		}
+	}
+}
@@ -0,0 +1,325 @@
+// Package jobs runs prompts in the background, each in a session of its
+// own, so a long task can go on while the user works in another session.
+// Progress and completion are published as job events.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+// maxTitleLength is the most characters of the prompt used in a job
+// session's title.
+const maxTitleLength = 40
+
+// Status is the state of a job.
+type Status string
+
+// Job statuses.
+const (
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Job is a prompt running, or run, in the background.
+type Job struct {
+	ID        int
+	SessionID string
+	Prompt    string
+	Status    Status
+	Activity  string // What the job is doing, e.g. the tool it called last
+	ToolCalls int
+	Err       error
+	Started   time.Time
+	Finished  time.Time
+
+	cleared bool // Left out of the list once finished
+}
+
+// Elapsed is how long the job ran, or has been running.
+func (j *Job) Elapsed() time.Duration {
+	if j.Finished.IsZero() {
+		return time.Since(j.Started)
+	}
+	return j.Finished.Sub(j.Started)
+}
+
+// Agent is the part of the agent that runs jobs.
+type Agent interface {
+	Send(ctx context.Context, prompt string, opts agent.SendOptions, callbacks agent.StreamCallbacks) error
+	Sessions() agent.Sessions
+	SetSessionWorkingDir(sessionID, dir string) bool
+}
+
+// Options configure a job.
+type Options struct {
+	WorkingDir     string // Directory the job's tools work in; the agent's when empty
+	ThinkingBudget int64
+	MaxTurns       int
+}
+
+// Manager starts jobs and keeps track of them.
+type Manager struct {
+	agent Agent
+	hub   *pubsub.Hub
+
+	mu      sync.Mutex
+	jobs    []*Job
+	cancels map[int]context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewManager creates a manager running jobs with ag. Job events are
+// published to hub, which may be nil.
+func NewManager(ag Agent, hub *pubsub.Hub) *Manager {
+	return &Manager{
+		agent:   ag,
+		hub:     hub,
+		cancels: make(map[int]context.CancelFunc),
+	}
+}
+
+// Start runs a prompt in a new session in the background and returns the
+// job. The current session stays current.
+func (m *Manager) Start(prompt string, opts Options) Job {
+	sessions := m.agent.Sessions()
+	current := sessions.Current().ID
+	sess := sessions.Create(jobTitle(prompt))
+	sessions.SetCurrent(current)
+	if opts.WorkingDir != "" {
+		m.agent.SetSessionWorkingDir(sess.ID, opts.WorkingDir)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.mu.Lock()
+	job := &Job{
+		ID:        len(m.jobs) + 1,
+		SessionID: sess.ID,
+		Prompt:    prompt,
+		Status:    StatusRunning,
+		Started:   time.Now(),
+	}
+	m.jobs = append(m.jobs, job)
+	m.cancels[job.ID] = cancel
+	started := *job
+	m.mu.Unlock()
+
+	m.publish(events.JobEventStarted, started)
+
+	// The job's events are followed until the run has returned and every
+	// event it published has been seen, and only then is the job finished.
+	followed := make(chan struct{})
+	stopFollow := func() {}
+	if m.hub != nil {
+		var followCtx context.Context
+		followCtx, stopFollow = context.WithCancel(context.Background())
+		agentEvents := m.hub.Agent.Subscribe(followCtx,
+			pubsub.WithSubscriberName("jobs"),
+			pubsub.WithSubscriberPolicy(pubsub.Block, 0))
+		go func() {
+			defer close(followed)
+			m.follow(agentEvents, job.ID, sess.ID)
+		}()
+	} else {
+		close(followed)
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		err := m.agent.Send(ctx, prompt, agent.SendOptions{
+			SessionID:      sess.ID,
+			MaxTurns:       opts.MaxTurns,
+			ThinkingBudget: opts.ThinkingBudget,
+		}, agent.StreamCallbacks{})
+		stopFollow()
+		<-followed
+		m.finish(job.ID, err)
+	}()
+
+	return started
+}
+
+// follow turns the agent events of a job's session into progress events
+// until the subscription ends. The subscription blocks the agent rather than
+// miss a tool call.
+func (m *Manager) follow(agentEvents <-chan pubsub.Event[events.AgentEvent], id int, sessionID string) {
+	for event := range agentEvents {
+		e := event.Payload
+		if e.SessionID != sessionID {
+			continue
+		}
+		var activity string
+		//nolint:exhaustive // Other events don't change what the job is doing
+		switch e.Type {
+		case events.AgentEventToolCall:
+			if e.ToolCall != nil {
+				activity = "→ " + e.ToolCall.Name
+			}
+		case events.AgentEventTextDelta:
+			activity = "writing"
+		case events.AgentEventRetrying:
+			activity = "retrying"
+		}
+		if activity == "" {
+			continue
+		}
+
+		m.mu.Lock()
+		job := m.find(id)
+		if job == nil || job.Status != StatusRunning ||
+			(job.Activity == activity && e.Type != events.AgentEventToolCall) {
+			m.mu.Unlock()
+			continue
+		}
+		job.Activity = activity
+		if e.Type == events.AgentEventToolCall {
+			job.ToolCalls++
+		}
+		snapshot := *job
+		m.mu.Unlock()
+		m.publish(events.JobEventProgress, snapshot)
+	}
+}
+
+// finish records how a job ended and announces it.
+func (m *Manager) finish(id int, err error) {
+	m.mu.Lock()
+	job := m.find(id)
+	if cancel, ok := m.cancels[id]; ok {
+		cancel()
+		delete(m.cancels, id)
+	}
+	job.Finished = time.Now()
+	job.Activity = ""
+	eventType := events.JobEventCompleted
+	switch {
+	case errors.Is(err, context.Canceled):
+		job.Status = StatusCancelled
+		eventType = events.JobEventCancelled
+	case err != nil:
+		job.Status = StatusFailed
+		job.Err = err
+		eventType = events.JobEventFailed
+	default:
+		job.Status = StatusCompleted
+	}
+	snapshot := *job
+	m.mu.Unlock()
+
+	m.publish(eventType, snapshot)
+}
+
+// publish sends a job event, when there is a hub to send it to.
+func (m *Manager) publish(eventType events.JobEventType, job Job) {
+	if m.hub == nil {
+		return
+	}
+	e := events.NewJobEvent(eventType, job.ID, job.SessionID, job.Prompt)
+	e.Activity = job.Activity
+	e.Error = job.Err
+
+	kind := pubsub.EventProgress
+	switch eventType { //nolint:exhaustive // Progress is the default
+	case events.JobEventStarted:
+		kind = pubsub.EventStarted
+	case events.JobEventCompleted:
+		kind = pubsub.EventCompleted
+	case events.JobEventFailed, events.JobEventCancelled:
+		kind = pubsub.EventFailed
+	}
+	m.hub.Job.Publish(kind, e)
+}
+
+// find returns the job with an ID. The caller holds m.mu.
+func (m *Manager) find(id int) *Job {
+	if id < 1 || id > len(m.jobs) {
+		return nil
+	}
+	return m.jobs[id-1]
+}
+
+// Get returns the job with an ID.
+func (m *Manager) Get(id int) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if job := m.find(id); job != nil {
+		return *job, true
+	}
+	return Job{}, false
+}
+
+// List returns the jobs started so far, oldest first, leaving out those
+// cleared.
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := make([]Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		if !job.cleared {
+			list = append(list, *job)
+		}
+	}
+	return list
+}
+
+// Running returns how many jobs are still running.
+func (m *Manager) Running() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.cancels)
+}
+
+// Cancel stops a running job.
+func (m *Manager) Cancel(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.find(id) == nil {
+		return fmt.Errorf("no job #%d", id)
+	}
+	cancel, ok := m.cancels[id]
+	if !ok {
+		return fmt.Errorf("job #%d is not running", id)
+	}
+	cancel()
+	return nil
+}
+
+// Clear takes the jobs that have finished off the list. Their sessions, and
+// their IDs, are kept.
+func (m *Manager) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, job := range m.jobs {
+		job.cleared = job.Status != StatusRunning
+	}
+}
+
+// Wait blocks until every job has finished.
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
+// jobTitle names a job's session after its prompt.
+func jobTitle(prompt string) string {
+	title := []rune(prompt)
+	for i, r := range title {
+		if r == '\n' {
+			title = title[:i]
+			break
+		}
+	}
+	if len(title) > maxTitleLength {
+		title = append(title[:maxTitleLength-1], '…')
+	}
+	return "Background: " + string(title)
+}
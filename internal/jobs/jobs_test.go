@@ -0,0 +1,177 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+// fakeAgent runs prompts with send, which sees the session they run in.
+type fakeAgent struct {
+	sessions *agent.SessionStore
+	dirs     map[string]string
+	send     func(ctx context.Context, sessionID string) error
+}
+
+func newFakeAgent(send func(ctx context.Context, sessionID string) error) *fakeAgent {
+	return &fakeAgent{sessions: agent.NewSessionStore(), dirs: make(map[string]string), send: send}
+}
+
+func (a *fakeAgent) Send(ctx context.Context, _ string, opts agent.SendOptions, _ agent.StreamCallbacks) error {
+	return a.send(ctx, opts.SessionID)
+}
+
+func (a *fakeAgent) Sessions() agent.Sessions {
+	return a.sessions
+}
+
+func (a *fakeAgent) SetSessionWorkingDir(sessionID, dir string) bool {
+	a.dirs[sessionID] = dir
+	return true
+}
+
+// nextJobEvent waits for the next job event.
+func nextJobEvent(t *testing.T, ch <-chan pubsub.Event[events.JobEvent]) events.JobEvent {
+	t.Helper()
+	select {
+	case e := <-ch:
+		return e.Payload
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a job event")
+		return events.JobEvent{}
+	}
+}
+
+func TestManager_Start(t *testing.T) {
+	hub := pubsub.NewHub()
+	defer hub.Shutdown()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobEvents := hub.Job.Subscribe(ctx)
+
+	ag := newFakeAgent(func(_ context.Context, _ string) error { return nil })
+	current := ag.Sessions().Current().ID
+	m := NewManager(ag, hub)
+
+	job := m.Start("run the whole test suite\nand fix what fails", Options{WorkingDir: "/repo"})
+	m.Wait()
+
+	if ag.Sessions().Current().ID != current {
+		t.Error("starting a job should leave the current session current")
+	}
+	sess, ok := ag.Sessions().Get(job.SessionID)
+	if !ok || sess.Title != "Background: run the whole test suite" {
+		t.Errorf("job session = %v, want one named after the prompt's first line", sess)
+	}
+	if ag.dirs[job.SessionID] != "/repo" {
+		t.Errorf("working dir = %q, want /repo", ag.dirs[job.SessionID])
+	}
+
+	if e := nextJobEvent(t, jobEvents); e.Type != events.JobEventStarted || e.JobID != 1 {
+		t.Errorf("first event = %+v, want job 1 started", e)
+	}
+	if e := nextJobEvent(t, jobEvents); e.Type != events.JobEventCompleted || !e.Finished() {
+		t.Errorf("last event = %+v, want job 1 completed", e)
+	}
+	if got, _ := m.Get(1); got.Status != StatusCompleted || got.Finished.IsZero() {
+		t.Errorf("job = %+v, want it completed", got)
+	}
+}
+
+func TestManager_Progress(t *testing.T) {
+	hub := pubsub.NewHub()
+	defer hub.Shutdown()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobEvents := hub.Job.Subscribe(ctx)
+
+	release := make(chan struct{})
+	ag := newFakeAgent(func(_ context.Context, sessionID string) error {
+		<-release
+		hub.Agent.Publish(pubsub.EventProgress, events.NewToolCallEvent(sessionID, "m1", events.ToolCallInfo{ID: "c1", Name: "bash"}))
+		hub.Agent.Publish(pubsub.EventProgress, events.NewToolCallEvent("other", "m2", events.ToolCallInfo{ID: "c2", Name: "edit"}))
+		<-release
+		return nil
+	})
+	m := NewManager(ag, hub)
+	m.Start("build", Options{})
+	nextJobEvent(t, jobEvents) // Started
+
+	// Give the manager time to subscribe to the agent's events.
+	time.Sleep(50 * time.Millisecond)
+	release <- struct{}{}
+	e := nextJobEvent(t, jobEvents)
+	if e.Type != events.JobEventProgress || e.Activity != "→ bash" {
+		t.Errorf("progress = %+v, want the bash call", e)
+	}
+	if job, _ := m.Get(1); job.ToolCalls != 1 || m.Running() != 1 {
+		t.Errorf("tool calls = %d, running = %d; want 1 and 1", job.ToolCalls, m.Running())
+	}
+	release <- struct{}{}
+	m.Wait()
+}
+
+func TestManager_ProgressUnderLoad(t *testing.T) {
+	hub := pubsub.NewHub()
+	defer hub.Shutdown()
+
+	const calls = 500 // Well past a subscription's buffer
+	release := make(chan struct{})
+	ag := newFakeAgent(func(_ context.Context, sessionID string) error {
+		<-release
+		for i := range calls {
+			hub.Agent.Publish(pubsub.EventProgress, events.NewToolCallEvent(sessionID, "m1", events.ToolCallInfo{ID: fmt.Sprint(i), Name: "bash"}))
+		}
+		return nil
+	})
+	m := NewManager(ag, hub)
+	m.Start("build", Options{})
+	close(release)
+	m.Wait()
+	if job, _ := m.Get(1); job.ToolCalls != calls {
+		t.Errorf("tool calls = %d, want all %d", job.ToolCalls, calls)
+	}
+}
+
+func TestManager_CancelAndFail(t *testing.T) {
+	ag := newFakeAgent(func(ctx context.Context, _ string) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	m := NewManager(ag, nil)
+	m.Start("wait", Options{})
+	if err := m.Cancel(1); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	m.Wait()
+	if job, _ := m.Get(1); job.Status != StatusCancelled {
+		t.Errorf("status = %s, want cancelled", job.Status)
+	}
+	if err := m.Cancel(1); err == nil {
+		t.Error("cancelling a finished job should fail")
+	}
+	if err := m.Cancel(7); err == nil {
+		t.Error("cancelling an unknown job should fail")
+	}
+
+	ag.send = func(context.Context, string) error { return errors.New("boom") }
+	m.Start("fail", Options{})
+	m.Wait()
+	if job, _ := m.Get(2); job.Status != StatusFailed || job.Err == nil {
+		t.Errorf("job = %+v, want it failed with the error", job)
+	}
+
+	m.Clear()
+	if list := m.List(); len(list) != 0 {
+		t.Errorf("List() after Clear() = %v, want no finished jobs", list)
+	}
+	if _, ok := m.Get(2); !ok {
+		t.Error("a cleared job should still be found by ID")
+	}
+}
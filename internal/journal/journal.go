@@ -0,0 +1,354 @@
+// Package journal records the file changes the agent makes in each session so
+// they can be rolled back with /undo or 'cdd sessions revert', or back to the
+// checkpoint taken before any prompt that changed files.
+package journal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const indexFile = "journal.json"
+
+var (
+	// ErrNothingToUndo is returned when a session has no recorded changes.
+	ErrNothingToUndo = errors.New("no file changes to undo")
+
+	// ErrModified is returned when a file changed after the agent wrote it, so
+	// reverting it would lose those changes.
+	ErrModified = errors.New("file changed since the agent edited it")
+)
+
+// Entry is a single file change made by a tool.
+type Entry struct { //nolint:govet // fieldalignment: preserving logical field order
+	Seq       int         `json:"seq"`
+	Path      string      `json:"path"`
+	Tool      string      `json:"tool"`
+	Existed   bool        `json:"existed"` // False when the change created the file
+	Mode      os.FileMode `json:"mode,omitempty"`
+	After     string      `json:"after"` // SHA-256 of the content the tool wrote
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Checkpoint marks the workspace state before an agent turn changed files.
+// Restoring it reverts the changes made from that turn on.
+type Checkpoint struct { //nolint:govet // fieldalignment: preserving logical field order
+	MessageID string    `json:"message_id"` // User message that started the turn
+	Prompt    string    `json:"prompt"`
+	Seq       int       `json:"seq"` // First change made in the turn
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// turn is the prompt the agent is currently working on in a session.
+type turn struct {
+	messageID string
+	prompt    string
+}
+
+// index is the contents of a session's journal file.
+type index struct {
+	Checkpoints []Checkpoint `json:"checkpoints,omitempty"`
+	Entries     []Entry      `json:"entries"`
+}
+
+// Journal keeps one directory per session under dir, holding an index of the
+// changes and a snapshot of each file as it was before the change.
+type Journal struct { //nolint:govet // fieldalignment: preserving logical field order
+	dir   string
+	turns map[string]turn // Session ID -> current turn
+	mu    sync.Mutex
+}
+
+// New creates a journal stored under dir.
+func New(dir string) *Journal {
+	return &Journal{dir: dir, turns: make(map[string]turn)}
+}
+
+// BeginTurn notes that the agent started working on a prompt. A checkpoint is
+// saved the first time the turn changes a file, so turns that only read
+// cost nothing.
+func (j *Journal) BeginTurn(sessionID, messageID, prompt string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.turns[sessionID] = turn{messageID: messageID, prompt: prompt}
+}
+
+// Record adds a change to the session's journal. before is the file content
+// prior to the change and is ignored when the file did not exist.
+func (j *Journal) Record(sessionID, path, tool string, before []byte, existed bool, after []byte) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	dir, err := j.sessionDir(sessionID)
+	if err != nil {
+		return err
+	}
+	idx, err := readIndex(dir)
+	if err != nil {
+		return err
+	}
+
+	entry := Entry{
+		Seq:       1,
+		Path:      path,
+		Tool:      tool,
+		Existed:   existed,
+		After:     checksum(after),
+		CreatedAt: time.Now(),
+	}
+	if n := len(idx.Entries); n > 0 {
+		entry.Seq = idx.Entries[n-1].Seq + 1
+	}
+	if t, ok := j.turns[sessionID]; ok && !idx.hasCheckpoint(t.messageID) {
+		idx.Checkpoints = append(idx.Checkpoints, Checkpoint{
+			MessageID: t.messageID,
+			Prompt:    t.prompt,
+			Seq:       entry.Seq,
+			CreatedAt: entry.CreatedAt,
+		})
+	}
+	if info, err := os.Stat(path); err == nil {
+		entry.Mode = info.Mode().Perm()
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("creating journal directory: %w", err)
+	}
+	if existed {
+		if err := os.WriteFile(snapshotPath(dir, entry.Seq), before, 0o600); err != nil {
+			return fmt.Errorf("saving snapshot: %w", err)
+		}
+	}
+	idx.Entries = append(idx.Entries, entry)
+	return writeIndex(dir, idx)
+}
+
+// Entries returns the session's recorded changes, oldest first.
+func (j *Journal) Entries(sessionID string) ([]Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	dir, err := j.sessionDir(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := readIndex(dir)
+	return idx.Entries, err
+}
+
+// Checkpoints returns the session's checkpoints, oldest first.
+func (j *Journal) Checkpoints(sessionID string) ([]Checkpoint, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	dir, err := j.sessionDir(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := readIndex(dir)
+	return idx.Checkpoints, err
+}
+
+// Undo reverts the session's last n changes, newest first, or all of them
+// when n is not positive. Files the agent created are removed. It stops at
+// the first file that changed since the agent wrote it, returning the
+// changes reverted so far with an error wrapping ErrModified.
+func (j *Journal) Undo(sessionID string, n int) ([]Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	dir, err := j.sessionDir(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := readIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 || n > len(idx.Entries) {
+		n = len(idx.Entries)
+	}
+	return undo(dir, idx, n)
+}
+
+// RestoreCheckpoint returns the files to how they were before the turn started
+// by messageID, reverting its changes and every later one. Like Undo, it
+// stops at a file changed since the agent wrote it.
+func (j *Journal) RestoreCheckpoint(sessionID, messageID string) ([]Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	dir, err := j.sessionDir(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := readIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cp := range idx.Checkpoints {
+		if cp.MessageID != messageID {
+			continue
+		}
+		n := 0
+		for _, entry := range idx.Entries {
+			if entry.Seq >= cp.Seq {
+				n++
+			}
+		}
+		return undo(dir, idx, n)
+	}
+	return nil, fmt.Errorf("%w: no checkpoint for message %s", ErrNothingToUndo, messageID)
+}
+
+// undo reverts the last n entries of idx, newest first, and saves what is
+// left. Checkpoints whose changes are all reverted are dropped.
+func undo(dir string, idx index, n int) ([]Entry, error) {
+	if len(idx.Entries) == 0 || n == 0 {
+		return nil, ErrNothingToUndo
+	}
+
+	var reverted []Entry
+	var err error
+	for range n {
+		entry := idx.Entries[len(idx.Entries)-1]
+		if err = revert(dir, entry); err != nil {
+			break
+		}
+		idx.Entries = idx.Entries[:len(idx.Entries)-1]
+		reverted = append(reverted, entry)
+	}
+
+	next := 1 // Seq of the next change
+	if len(idx.Entries) > 0 {
+		next = idx.Entries[len(idx.Entries)-1].Seq + 1
+	}
+	for len(idx.Checkpoints) > 0 && idx.Checkpoints[len(idx.Checkpoints)-1].Seq >= next {
+		idx.Checkpoints = idx.Checkpoints[:len(idx.Checkpoints)-1]
+	}
+
+	if saveErr := writeIndex(dir, idx); saveErr != nil {
+		return reverted, saveErr
+	}
+	return reverted, err
+}
+
+// revert restores a file to its state before entry, then drops the snapshot.
+func revert(dir string, entry Entry) error {
+	current, err := os.ReadFile(entry.Path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if entry.Existed {
+			return fmt.Errorf("%w: %s was deleted", ErrModified, entry.Path)
+		}
+		// Already gone; nothing to remove.
+	case err != nil:
+		return fmt.Errorf("reading %s: %w", entry.Path, err)
+	case checksum(current) != entry.After:
+		return fmt.Errorf("%w: %s", ErrModified, entry.Path)
+	case !entry.Existed:
+		if err := os.Remove(entry.Path); err != nil {
+			return fmt.Errorf("removing %s: %w", entry.Path, err)
+		}
+	}
+
+	if entry.Existed {
+		before, err := os.ReadFile(snapshotPath(dir, entry.Seq))
+		if err != nil {
+			return fmt.Errorf("reading snapshot: %w", err)
+		}
+		mode := entry.Mode
+		if mode == 0 {
+			mode = 0o644
+		}
+		if err := os.WriteFile(entry.Path, before, mode); err != nil {
+			return fmt.Errorf("restoring %s: %w", entry.Path, err)
+		}
+		_ = os.Remove(snapshotPath(dir, entry.Seq)) //nolint:errcheck // A stale snapshot is harmless
+	}
+	return nil
+}
+
+// Remove deletes everything recorded for a session, once its changes no
+// longer need undoing.
+func (j *Journal) Remove(sessionID string) error {
+	dir, err := j.sessionDir(sessionID)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.turns, sessionID)
+	return os.RemoveAll(dir)
+}
+
+// sessionDir returns the directory holding a session's journal.
+func (j *Journal) sessionDir(sessionID string) (string, error) {
+	if sessionID == "" || sessionID != filepath.Base(sessionID) || sessionID == ".." {
+		return "", fmt.Errorf("invalid session ID %q", sessionID)
+	}
+	return filepath.Join(j.dir, sessionID), nil
+}
+
+// hasCheckpoint reports whether the turn started by messageID has a checkpoint.
+func (idx *index) hasCheckpoint(messageID string) bool {
+	for _, cp := range idx.Checkpoints {
+		if cp.MessageID == messageID {
+			return true
+		}
+	}
+	return false
+}
+
+func readIndex(dir string) (index, error) {
+	var idx index
+	data, err := os.ReadFile(filepath.Join(dir, indexFile)) //nolint:gosec // G304: Path is built from the journal directory
+	if errors.Is(err, os.ErrNotExist) {
+		return idx, nil
+	}
+	if err != nil {
+		return idx, fmt.Errorf("reading journal: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return idx, fmt.Errorf("parsing journal: %w", err)
+	}
+	return idx, nil
+}
+
+func writeIndex(dir string, idx index) error {
+	if len(idx.Entries) == 0 {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("removing journal: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding journal: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, indexFile), data, 0o600); err != nil {
+		return fmt.Errorf("writing journal: %w", err)
+	}
+	return nil
+}
+
+func snapshotPath(dir string, seq int) string {
+	return filepath.Join(dir, strconv.Itoa(seq)+".before")
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
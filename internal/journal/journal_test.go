@@ -0,0 +1,151 @@
+package journal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// write changes path to content and records it like a tool would.
+func write(t *testing.T, j *Journal, sessionID, path, content string) {
+	t.Helper()
+	before, err := os.ReadFile(path)
+	existed := err == nil
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Record(sessionID, path, "write_file", before, existed, []byte(content)); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestJournal_Undo(t *testing.T) {
+	dir := t.TempDir()
+	j := New(filepath.Join(dir, "journal"))
+	existing := filepath.Join(dir, "main.go")
+	created := filepath.Join(dir, "new.go")
+	if err := os.WriteFile(existing, []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	write(t, j, "s1", existing, "v2")
+	write(t, j, "s1", created, "new")
+	write(t, j, "s1", existing, "v3")
+
+	entries, err := j.Entries("s1")
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("Entries() = %d entries, %v; want 3", len(entries), err)
+	}
+
+	reverted, err := j.Undo("s1", 1)
+	if err != nil || len(reverted) != 1 {
+		t.Fatalf("Undo(1) = %v, %v", reverted, err)
+	}
+	if got := readFile(t, existing); got != "v2" {
+		t.Errorf("after undoing one change main.go = %q, want v2", got)
+	}
+
+	if _, err := j.Undo("s1", 0); err != nil {
+		t.Fatalf("Undo(all) error = %v", err)
+	}
+	if got := readFile(t, existing); got != "v1" {
+		t.Errorf("after undoing everything main.go = %q, want v1", got)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Error("a file the agent created should be removed")
+	}
+
+	if _, err := j.Undo("s1", 0); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Undo() on an empty journal error = %v, want ErrNothingToUndo", err)
+	}
+}
+
+func TestJournal_UndoStopsAtModifiedFile(t *testing.T) {
+	dir := t.TempDir()
+	j := New(filepath.Join(dir, "journal"))
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+
+	write(t, j, "s1", a, "agent a")
+	write(t, j, "s1", b, "agent b")
+	if err := os.WriteFile(a, []byte("user edit"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	reverted, err := j.Undo("s1", 0)
+	if !errors.Is(err, ErrModified) {
+		t.Fatalf("Undo() error = %v, want ErrModified", err)
+	}
+	if len(reverted) != 1 || reverted[0].Path != b {
+		t.Errorf("only b.txt should be reverted, got %+v", reverted)
+	}
+	if got := readFile(t, a); got != "user edit" {
+		t.Errorf("the user's edit should be kept, got %q", got)
+	}
+	if entries, _ := j.Entries("s1"); len(entries) != 1 {
+		t.Errorf("the change to a.txt should stay in the journal, got %d entries", len(entries))
+	}
+}
+
+func TestJournal_SessionIsolation(t *testing.T) {
+	dir := t.TempDir()
+	j := New(filepath.Join(dir, "journal"))
+	path := filepath.Join(dir, "f.txt")
+
+	write(t, j, "s1", path, "one")
+	if _, err := j.Undo("s2", 0); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("another session should have nothing to undo, got %v", err)
+	}
+	if _, err := j.Entries("../s1"); err == nil {
+		t.Error("a session ID with a path should be rejected")
+	}
+}
+
+func TestJournal_RestoreCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	j := New(filepath.Join(dir, "journal"))
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("v0"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	j.BeginTurn("s1", "m1", "first")
+	write(t, j, "s1", path, "v1")
+	j.BeginTurn("s1", "m2", "only reads")
+	j.BeginTurn("s1", "m3", "second")
+	write(t, j, "s1", path, "v2")
+	write(t, j, "s1", path, "v3")
+
+	checkpoints, err := j.Checkpoints("s1")
+	if err != nil || len(checkpoints) != 2 {
+		t.Fatalf("Checkpoints() = %+v, %v; want m1 and m3", checkpoints, err)
+	}
+	if checkpoints[0].MessageID != "m1" || checkpoints[1].MessageID != "m3" || checkpoints[1].Seq != 2 {
+		t.Errorf("unexpected checkpoints %+v", checkpoints)
+	}
+
+	reverted, err := j.RestoreCheckpoint("s1", "m3")
+	if err != nil || len(reverted) != 2 {
+		t.Fatalf("RestoreCheckpoint(m3) = %d changes, %v; want 2", len(reverted), err)
+	}
+	if got := readFile(t, path); got != "v1" {
+		t.Errorf("main.go = %q, want v1", got)
+	}
+	if checkpoints, _ := j.Checkpoints("s1"); len(checkpoints) != 1 {
+		t.Errorf("the restored checkpoint should be dropped, got %+v", checkpoints)
+	}
+
+	if _, err := j.RestoreCheckpoint("s1", "m2"); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("a turn without changes has no checkpoint, got %v", err)
+	}
+}
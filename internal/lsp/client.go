@@ -0,0 +1,475 @@
+// Package lsp runs language servers and collects the diagnostics they publish,
+// so the agent can check its edits for compile and lint errors.
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/debug"
+)
+
+// Timing of diagnostics collection.
+const (
+	// DefaultDiagnosticsTimeout bounds the wait for a server to publish
+	// diagnostics after a file is synced.
+	DefaultDiagnosticsTimeout = 10 * time.Second
+
+	// settleDelay is how long to wait for further publications once the first
+	// arrives; servers often publish syntax errors before type errors.
+	settleDelay = 500 * time.Millisecond
+
+	// shutdownTimeout bounds the shutdown handshake before the process is killed.
+	shutdownTimeout = 2 * time.Second
+)
+
+// errClientClosed is returned for calls on a client whose connection has ended.
+var errClientClosed = errors.New("language server connection closed")
+
+// Client is a connection to a single language server.
+type Client struct { //nolint:govet // fieldalignment: preserving logical field order
+	name    string
+	cmd     *exec.Cmd // Nil when the connection was not started by Start
+	writer  io.WriteCloser
+	writeMu sync.Mutex
+	nextID  atomic.Int64
+
+	diagnosticsTimeout time.Duration
+	settleDelay        time.Duration
+
+	mu          sync.Mutex
+	pending     map[string]chan *message      // Response channels by request ID
+	diagnostics map[DocumentURI][]Diagnostic  // Latest diagnostics per document
+	published   map[DocumentURI]chan struct{} // Closed when diagnostics for a document arrive
+	versions    map[DocumentURI]int           // Versions of the open documents
+	done        chan struct{}
+	err         error
+}
+
+// newClient creates a client speaking the protocol over r and w and starts
+// reading server messages.
+func newClient(name string, r io.Reader, w io.WriteCloser) *Client {
+	c := &Client{
+		name:               name,
+		writer:             w,
+		diagnosticsTimeout: DefaultDiagnosticsTimeout,
+		settleDelay:        settleDelay,
+		pending:            make(map[string]chan *message),
+		diagnostics:        make(map[DocumentURI][]Diagnostic),
+		published:          make(map[DocumentURI]chan struct{}),
+		versions:           make(map[DocumentURI]int),
+		done:               make(chan struct{}),
+	}
+	go c.readLoop(r)
+	return c
+}
+
+// Start launches the language server described by cfg in rootDir and
+// performs the initialize handshake.
+func Start(ctx context.Context, name string, cfg config.LSPConfig, rootDir string) (*Client, error) {
+	if cfg.Command == "" {
+		return nil, fmt.Errorf("language server %s: command is required", name)
+	}
+
+	cmd := exec.Command(cfg.Command, cfg.Args...) //nolint:gosec // G204: Servers are configured by the user.
+	cmd.Dir = rootDir
+	cmd.Env = os.Environ()
+	for k, v := range cfg.Env {
+		cmd.Env = append(cmd.Env, k+"="+os.ExpandEnv(v))
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("creating stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("creating stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %s: %w", cfg.Command, err)
+	}
+
+	c := newClient(name, stdout, stdin)
+	c.cmd = cmd
+	if err := c.initialize(ctx, rootDir, cfg.InitOptions); err != nil {
+		c.kill()
+		_ = cmd.Wait() //nolint:errcheck // The process was killed
+		return nil, fmt.Errorf("initializing %s: %w", name, err)
+	}
+	debug.Log("[LSP] Started %s (%s)", name, cfg.Command)
+	return c, nil
+}
+
+// Name returns the configured name of the server.
+func (c *Client) Name() string {
+	return c.name
+}
+
+// initialize performs the initialize/initialized handshake.
+func (c *Client) initialize(ctx context.Context, rootDir string, initOptions map[string]any) error {
+	rootURI := URIFromPath(rootDir)
+	params := initializeParams{
+		ProcessID:        os.Getpid(),
+		RootURI:          rootURI,
+		WorkspaceFolders: []workspaceFolder{{URI: rootURI, Name: filepath.Base(rootDir)}},
+		Capabilities: map[string]any{
+			"textDocument": map[string]any{
+				"synchronization":    map[string]any{"didSave": true},
+				"publishDiagnostics": map[string]any{"relatedInformation": false},
+			},
+			"workspace": map[string]any{
+				"configuration":    true,
+				"workspaceFolders": true,
+			},
+		},
+	}
+	if len(initOptions) > 0 {
+		params.InitializationOptions = initOptions
+	}
+
+	if err := c.Call(ctx, "initialize", params, nil); err != nil {
+		return err
+	}
+	return c.Notify("initialized", struct{}{})
+}
+
+// Call sends a request and decodes its result into result, which may be nil.
+func (c *Client) Call(ctx context.Context, method string, params, result any) error {
+	id := json.RawMessage(strconv.FormatInt(c.nextID.Add(1), 10))
+	ch := make(chan *message, 1)
+
+	c.mu.Lock()
+	c.pending[string(id)] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, string(id))
+		c.mu.Unlock()
+	}()
+
+	if err := c.send(&message{ID: id, Method: method}, params); err != nil {
+		return err
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return fmt.Errorf("%s: %w", method, resp.Error)
+		}
+		if result != nil && len(resp.Result) > 0 {
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				return fmt.Errorf("decoding %s result: %w", method, err)
+			}
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		return c.closeErr()
+	}
+}
+
+// Notify sends a notification.
+func (c *Client) Notify(method string, params any) error {
+	return c.send(&message{Method: method}, params)
+}
+
+// send encodes params into msg and writes it to the server.
+func (c *Client) send(msg *message, params any) error {
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("encoding %s params: %w", msg.Method, err)
+		}
+		msg.Params = data
+	}
+
+	select {
+	case <-c.done:
+		return c.closeErr()
+	default:
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return writeMessage(c.writer, msg)
+}
+
+// reply sends the response to a server request.
+func (c *Client) reply(id json.RawMessage, result any, respErr *responseError) {
+	msg := &message{ID: id, Error: respErr}
+	if respErr == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return
+		}
+		msg.Result = data
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := writeMessage(c.writer, msg); err != nil {
+		debug.Log("[LSP] %s: replying to server request: %v", c.name, err)
+	}
+}
+
+// readLoop dispatches server messages until the connection ends.
+func (c *Client) readLoop(r io.Reader) {
+	br := bufio.NewReader(r)
+	for {
+		msg, err := readMessage(br)
+		if err != nil {
+			c.close(err)
+			return
+		}
+
+		switch {
+		case msg.isRequest():
+			go c.handleRequest(msg)
+		case msg.isNotification():
+			c.handleNotification(msg)
+		default:
+			c.mu.Lock()
+			ch := c.pending[string(msg.ID)]
+			c.mu.Unlock()
+			if ch != nil {
+				ch <- msg
+			}
+		}
+	}
+}
+
+// handleRequest answers the requests servers send to clients. CDD has no
+// settings to offer, so configuration requests get empty values.
+func (c *Client) handleRequest(msg *message) {
+	switch msg.Method {
+	case "workspace/configuration":
+		var params struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		_ = json.Unmarshal(msg.Params, &params) //nolint:errcheck // Malformed params get an empty answer
+		c.reply(msg.ID, make([]any, len(params.Items)), nil)
+	case "client/registerCapability", "client/unregisterCapability",
+		"window/workDoneProgress/create", "window/showMessageRequest":
+		c.reply(msg.ID, nil, nil)
+	case "workspace/workspaceFolders":
+		c.reply(msg.ID, []workspaceFolder{}, nil)
+	default:
+		c.reply(msg.ID, nil, &responseError{Code: codeMethodNotFound, Message: "method not supported: " + msg.Method})
+	}
+}
+
+// handleNotification records published diagnostics; other notifications are ignored.
+func (c *Client) handleNotification(msg *message) {
+	switch msg.Method {
+	case "textDocument/publishDiagnostics":
+		var params publishDiagnosticsParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			debug.Log("[LSP] %s: decoding diagnostics: %v", c.name, err)
+			return
+		}
+		for i := range params.Diagnostics {
+			if params.Diagnostics[i].Severity == 0 {
+				params.Diagnostics[i].Severity = SeverityError // Clients decide; treat as an error
+			}
+		}
+		c.mu.Lock()
+		c.diagnostics[params.URI] = params.Diagnostics
+		if ch, ok := c.published[params.URI]; ok {
+			close(ch)
+			delete(c.published, params.URI)
+		}
+		c.mu.Unlock()
+	case "window/logMessage", "window/showMessage":
+		var params struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err == nil {
+			debug.Log("[LSP] %s: %s", c.name, params.Message)
+		}
+	}
+}
+
+// publishedChan returns a channel that is closed when diagnostics for uri are next published.
+func (c *Client) publishedChan(uri DocumentURI) chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch, ok := c.published[uri]
+	if !ok {
+		ch = make(chan struct{})
+		c.published[uri] = ch
+	}
+	return ch
+}
+
+// Sync sends the current text of the file at path to the server, opening the
+// document on first use.
+func (c *Client) Sync(path, text string) error {
+	uri := URIFromPath(path)
+
+	c.mu.Lock()
+	version, open := c.versions[uri]
+	version++
+	c.versions[uri] = version
+	c.mu.Unlock()
+
+	if !open {
+		return c.Notify("textDocument/didOpen", map[string]any{
+			"textDocument": textDocumentItem{URI: uri, LanguageID: LanguageID(path), Version: version, Text: text},
+		})
+	}
+
+	if err := c.Notify("textDocument/didChange", map[string]any{
+		"textDocument":   versionedTextDocumentIdentifier{URI: uri, Version: version},
+		"contentChanges": []contentChange{{Text: text}},
+	}); err != nil {
+		return err
+	}
+	// Some servers only run their full checks on save.
+	return c.Notify("textDocument/didSave", map[string]any{
+		"textDocument": textDocumentIdentifier{URI: uri},
+	})
+}
+
+// Diagnostics syncs the file at path with the given text and returns the
+// diagnostics the server publishes for it. When the server publishes nothing
+// within the timeout, the last known diagnostics are returned.
+func (c *Client) Diagnostics(ctx context.Context, path, text string) ([]Diagnostic, error) {
+	uri := URIFromPath(path)
+	published := c.publishedChan(uri)
+	if err := c.Sync(path, text); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(c.diagnosticsTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-published:
+	case <-timer.C:
+		debug.Log("[LSP] %s: no diagnostics published for %s", c.name, path)
+		return c.cachedDiagnostics(uri), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.done:
+		return nil, c.closeErr()
+	}
+
+	// Wait for follow-up publications until the server goes quiet.
+	for {
+		next := c.publishedChan(uri)
+		select {
+		case <-next:
+			continue
+		case <-time.After(c.settleDelay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return c.cachedDiagnostics(uri), nil
+	}
+}
+
+// cachedDiagnostics returns a copy of the latest diagnostics for uri.
+func (c *Client) cachedDiagnostics(uri DocumentURI) []Diagnostic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.diagnostics[uri])
+}
+
+// AllDiagnostics returns the latest diagnostics of every document, keyed by file path.
+// Documents without diagnostics are omitted.
+func (c *Client) AllDiagnostics() map[string][]Diagnostic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	all := make(map[string][]Diagnostic, len(c.diagnostics))
+	for uri, diags := range c.diagnostics {
+		if len(diags) > 0 {
+			all[uri.Path()] = slices.Clone(diags)
+		}
+	}
+	return all
+}
+
+// Shutdown asks the server to exit and waits for the process, killing it if
+// it does not exit in time.
+func (c *Client) Shutdown(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+	defer cancel()
+
+	if err := c.Call(ctx, "shutdown", nil, nil); err == nil {
+		_ = c.Notify("exit", nil) //nolint:errcheck // The process is killed below if it does not exit
+	}
+	_ = c.writer.Close() //nolint:errcheck // Closing stdin also signals exit
+
+	if c.cmd == nil {
+		return
+	}
+	exited := make(chan struct{})
+	go func() {
+		_ = c.cmd.Wait() //nolint:errcheck // Exit status of a stopped server is irrelevant
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-ctx.Done():
+		c.kill()
+		<-exited
+	}
+}
+
+// kill terminates the server process, if any.
+func (c *Client) kill() {
+	if c.cmd != nil && c.cmd.Process != nil {
+		_ = c.cmd.Process.Kill() //nolint:errcheck // The process may already have exited
+	}
+}
+
+// close marks the connection as ended with err.
+func (c *Client) close(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	if errors.Is(err, io.EOF) {
+		err = errClientClosed
+	}
+	c.err = err
+	close(c.done)
+}
+
+// isClosed reports whether the connection has ended.
+func (c *Client) isClosed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// closeErr returns why the connection ended.
+func (c *Client) closeErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil || errors.Is(c.err, errClientClosed) {
+		return errClientClosed
+	}
+	return fmt.Errorf("%w: %v", errClientClosed, c.err) //nolint:errorlint // The cause is informational
+}
@@ -0,0 +1,212 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer is an in-process language server that reports one error for
+// every line of a document containing "bad".
+type fakeServer struct {
+	t      *testing.T
+	reader *bufio.Reader
+	writer io.WriteCloser
+
+	mu      sync.Mutex
+	methods []string
+	config  json.RawMessage // Client's answer to workspace/configuration
+}
+
+// newFakeConnection connects a client to a fake server.
+func newFakeConnection(t *testing.T) (*Client, *fakeServer) {
+	t.Helper()
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	server := &fakeServer{t: t, reader: bufio.NewReader(serverReader), writer: serverWriter}
+	go server.serve()
+
+	client := newClient("fake", clientReader, clientWriter)
+	client.settleDelay = 20 * time.Millisecond
+	t.Cleanup(func() {
+		client.Shutdown(context.Background())
+		_ = serverWriter.Close() //nolint:errcheck // Test cleanup
+	})
+	return client, server
+}
+
+func (s *fakeServer) serve() {
+	for {
+		msg, err := readMessage(s.reader)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.methods = append(s.methods, msg.Method)
+		s.mu.Unlock()
+
+		switch msg.Method {
+		case "initialize":
+			s.send(&message{ID: msg.ID, Result: json.RawMessage(`{"capabilities":{}}`)})
+			// Servers ask for settings right after initializing.
+			s.send(&message{ID: json.RawMessage(`"cfg-1"`), Method: "workspace/configuration",
+				Params: json.RawMessage(`{"items":[{"section":"gopls"}]}`)})
+		case "shutdown":
+			s.send(&message{ID: msg.ID, Result: json.RawMessage(`null`)})
+		case "textDocument/didOpen":
+			var params struct {
+				TextDocument textDocumentItem `json:"textDocument"`
+			}
+			_ = json.Unmarshal(msg.Params, &params) //nolint:errcheck // Test server
+			s.publish(params.TextDocument.URI, params.TextDocument.Text)
+		case "textDocument/didChange":
+			var params struct {
+				TextDocument   versionedTextDocumentIdentifier `json:"textDocument"`
+				ContentChanges []contentChange                 `json:"contentChanges"`
+			}
+			_ = json.Unmarshal(msg.Params, &params) //nolint:errcheck // Test server
+			s.publish(params.TextDocument.URI, params.ContentChanges[0].Text)
+		case "":
+			// Response to the configuration request.
+			s.mu.Lock()
+			s.config = msg.Result
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *fakeServer) publish(uri DocumentURI, text string) {
+	params := publishDiagnosticsParams{URI: uri, Diagnostics: []Diagnostic{}}
+	for i, line := range strings.Split(text, "\n") {
+		if col := strings.Index(line, "bad"); col >= 0 {
+			params.Diagnostics = append(params.Diagnostics, Diagnostic{
+				Range:   Range{Start: Position{Line: i, Character: col}, End: Position{Line: i, Character: col + 3}},
+				Source:  "fake",
+				Message: "bad code",
+			})
+		}
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		s.t.Errorf("marshal diagnostics: %v", err)
+		return
+	}
+	s.send(&message{Method: "textDocument/publishDiagnostics", Params: data})
+}
+
+func (s *fakeServer) send(msg *message) {
+	if err := writeMessage(s.writer, msg); err != nil {
+		s.t.Errorf("fake server write: %v", err)
+	}
+}
+
+func (s *fakeServer) received(method string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func TestClient_Diagnostics(t *testing.T) {
+	client, server := newFakeConnection(t)
+	ctx := context.Background()
+
+	if err := client.initialize(ctx, "/project", nil); err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+
+	diags, err := client.Diagnostics(ctx, "/project/main.go", "package main\n\nvar x = bad\n")
+	if err != nil {
+		t.Fatalf("Diagnostics: %v", err)
+	}
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %+v", diags)
+	}
+	if !server.received("initialized") {
+		t.Error("expected initialized notification")
+	}
+	if diags[0].Severity != SeverityError {
+		t.Errorf("missing severity should default to error, got %v", diags[0].Severity)
+	}
+	if got := diags[0].Format("main.go"); got != "main.go:3:9: error: bad code (fake)" {
+		t.Errorf("unexpected format %q", got)
+	}
+
+	// A second sync sends the change and sees the error fixed.
+	diags, err = client.Diagnostics(ctx, "/project/main.go", "package main\n\nvar x = 1\n")
+	if err != nil {
+		t.Fatalf("Diagnostics: %v", err)
+	}
+	if len(diags) != 0 {
+		t.Errorf("expected no diagnostics after fix, got %+v", diags)
+	}
+	if !server.received("textDocument/didChange") {
+		t.Error("expected didChange for an open document")
+	}
+	if len(client.AllDiagnostics()) != 0 {
+		t.Errorf("AllDiagnostics should omit clean files, got %v", client.AllDiagnostics())
+	}
+
+	// The configuration reply is sent concurrently with the document syncs.
+	var config string
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		server.mu.Lock()
+		config = string(server.config)
+		server.mu.Unlock()
+		if config != "" {
+			break
+		}
+	}
+	if config != "[null]" {
+		t.Errorf("expected one empty configuration item, got %s", config)
+	}
+}
+
+func TestClient_ClosedConnection(t *testing.T) {
+	clientReader, serverWriter := io.Pipe()
+	_, clientWriter := io.Pipe()
+	client := newClient("fake", clientReader, clientWriter)
+
+	_ = serverWriter.Close() //nolint:errcheck // Simulates the server exiting
+	<-client.done
+
+	if !client.isClosed() {
+		t.Error("client should be closed")
+	}
+	if err := client.Call(context.Background(), "initialize", nil, nil); err == nil {
+		t.Error("expected error calling a closed client")
+	}
+}
+
+func TestURIFromPath(t *testing.T) {
+	uri := URIFromPath("/home/user/my project/main.go")
+	if uri != "file:///home/user/my%20project/main.go" {
+		t.Errorf("unexpected URI %q", uri)
+	}
+	if uri.Path() != "/home/user/my project/main.go" {
+		t.Errorf("round trip gave %q", uri.Path())
+	}
+}
+
+func TestDiagnosticFormat_Code(t *testing.T) {
+	d := Diagnostic{
+		Severity: SeverityWarning,
+		Code:     json.RawMessage(`"SA4006"`),
+		Source:   "staticcheck",
+		Message:  "value never used\nsecond line",
+	}
+	want := "a.go:1:1: warning: value never used second line (staticcheck SA4006)"
+	if got := d.Format("a.go"); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,169 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/debug"
+)
+
+// startTimeout bounds how long a server may take to initialize.
+const startTimeout = 30 * time.Second
+
+// Manager starts the configured language servers on demand, one per server,
+// and routes files to them by extension.
+type Manager struct { //nolint:govet // fieldalignment: preserving logical field order
+	rootDir string
+	servers map[string]config.LSPConfig
+	names   []string // Server names in lookup order
+
+	mu      sync.Mutex
+	clients map[string]*Client
+	failed  map[string]error // Servers that could not be started are not retried
+}
+
+// NewManager creates a manager for the servers in cfg, rooted at rootDir.
+// Disabled servers and servers without a command are skipped.
+func NewManager(rootDir string, servers map[string]config.LSPConfig) *Manager {
+	m := &Manager{
+		rootDir: rootDir,
+		servers: make(map[string]config.LSPConfig, len(servers)),
+		clients: make(map[string]*Client),
+		failed:  make(map[string]error),
+	}
+	for name, cfg := range servers {
+		if cfg.Disabled || cfg.Command == "" {
+			continue
+		}
+		m.servers[name] = cfg
+		m.names = append(m.names, name)
+	}
+	sort.Strings(m.names)
+	return m
+}
+
+// HasServers reports whether any language server is configured.
+func (m *Manager) HasServers() bool {
+	return len(m.names) > 0
+}
+
+// ServerFor returns the name of the server that handles path, matching its
+// extension against each server's file types.
+func (m *Manager) ServerFor(path string) (string, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		return "", false
+	}
+	for _, name := range m.names {
+		for _, ft := range m.servers[name].FileTypes {
+			if normalizeFileType(ft) == ext {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// normalizeFileType turns "go", ".go", and "*.go" into ".go".
+func normalizeFileType(ft string) string {
+	ft = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ft), "*"))
+	if !strings.HasPrefix(ft, ".") {
+		ft = "." + ft
+	}
+	return ft
+}
+
+// Diagnostics returns the current diagnostics for the file at path, starting
+// its language server if needed. The file is read from disk so the server
+// sees the latest edits.
+func (m *Manager) Diagnostics(ctx context.Context, path string) ([]Diagnostic, error) {
+	name, ok := m.ServerFor(path)
+	if !ok {
+		return nil, fmt.Errorf("no language server configured for %s files", filepath.Ext(path))
+	}
+
+	client, err := m.client(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path) //nolint:gosec // G304: Paths come from the agent's tools
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	return client.Diagnostics(ctx, path, string(content))
+}
+
+// AllDiagnostics returns the latest diagnostics known to the running servers,
+// keyed by file path.
+func (m *Manager) AllDiagnostics() map[string][]Diagnostic {
+	m.mu.Lock()
+	clients := make([]*Client, 0, len(m.clients))
+	for _, c := range m.clients {
+		clients = append(clients, c)
+	}
+	m.mu.Unlock()
+
+	all := make(map[string][]Diagnostic)
+	for _, c := range clients {
+		for path, diags := range c.AllDiagnostics() {
+			all[path] = append(all[path], diags...)
+		}
+	}
+	return all
+}
+
+// client returns the running client for the named server, starting it on first use.
+func (m *Manager) client(ctx context.Context, name string) (*Client, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if c, ok := m.clients[name]; ok {
+		if !c.isClosed() {
+			return c, nil
+		}
+		debug.Log("[LSP] %s exited, restarting", name)
+		delete(m.clients, name)
+	}
+	if err, ok := m.failed[name]; ok {
+		return nil, err
+	}
+
+	startCtx, cancel := context.WithTimeout(ctx, startTimeout)
+	defer cancel()
+	c, err := Start(startCtx, name, m.servers[name], m.rootDir)
+	if err != nil {
+		debug.Log("[LSP] %v", err)
+		if ctx.Err() == nil {
+			m.failed[name] = err
+		}
+		return nil, err
+	}
+	m.clients[name] = c
+	return c, nil
+}
+
+// Shutdown stops every running server.
+func (m *Manager) Shutdown(ctx context.Context) {
+	m.mu.Lock()
+	clients := m.clients
+	m.clients = make(map[string]*Client)
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Shutdown(ctx)
+		}()
+	}
+	wg.Wait()
+}
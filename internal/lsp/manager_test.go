@@ -0,0 +1,63 @@
+package lsp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+func TestManager_ServerFor(t *testing.T) {
+	m := NewManager("/project", map[string]config.LSPConfig{
+		"gopls":    {Command: "gopls", FileTypes: []string{"go"}},
+		"tsserver": {Command: "typescript-language-server", Args: []string{"--stdio"}, FileTypes: []string{".ts", "*.TSX"}},
+		"pyright":  {Command: "pyright-langserver", FileTypes: []string{"py"}, Disabled: true},
+		"broken":   {FileTypes: []string{"rs"}},
+	})
+
+	tests := map[string]string{
+		"/project/main.go":       "gopls",
+		"/project/web/app.ts":    "tsserver",
+		"/project/web/View.tsx":  "tsserver",
+		"/project/script.py":     "",
+		"/project/src/lib.rs":    "",
+		"/project/Makefile":      "",
+		"/project/docs/guide.md": "",
+	}
+	for path, want := range tests {
+		got, ok := m.ServerFor(path)
+		if got != want || ok != (want != "") {
+			t.Errorf("ServerFor(%s) = %q, %v; want %q", path, got, ok, want)
+		}
+	}
+	if !m.HasServers() {
+		t.Error("expected servers")
+	}
+}
+
+func TestManager_NoServers(t *testing.T) {
+	m := NewManager("/project", nil)
+	if m.HasServers() {
+		t.Error("expected no servers")
+	}
+	if _, err := m.Diagnostics(context.Background(), "/project/main.go"); err == nil {
+		t.Error("expected error without a server")
+	}
+}
+
+func TestManager_StartFailureIsRemembered(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(dir, map[string]config.LSPConfig{
+		"missing": {Command: "cdd-test-no-such-language-server", FileTypes: []string{"go"}},
+	})
+
+	_, err := m.Diagnostics(context.Background(), dir+"/main.go")
+	if err == nil || !strings.Contains(err.Error(), "starting") {
+		t.Fatalf("expected start error, got %v", err)
+	}
+	if _, ok := m.failed["missing"]; !ok {
+		t.Error("failed start should be remembered")
+	}
+	m.Shutdown(context.Background())
+}
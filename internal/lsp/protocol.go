@@ -0,0 +1,182 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// DocumentURI is a file:// URI identifying a document.
+type DocumentURI string
+
+// URIFromPath converts an absolute file path to a document URI.
+func URIFromPath(path string) DocumentURI {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return DocumentURI(u.String())
+}
+
+// Path converts the URI back to a file path.
+func (u DocumentURI) Path() string {
+	parsed, err := url.Parse(string(u))
+	if err != nil || parsed.Scheme != "file" {
+		return string(u)
+	}
+	return filepath.FromSlash(parsed.Path)
+}
+
+// Position is a zero-based line and character offset in a document.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a span between two positions in a document.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// DiagnosticSeverity is the severity of a diagnostic.
+type DiagnosticSeverity int
+
+// Diagnostic severities, as defined by the protocol.
+const (
+	SeverityError       DiagnosticSeverity = 1
+	SeverityWarning     DiagnosticSeverity = 2
+	SeverityInformation DiagnosticSeverity = 3
+	SeverityHint        DiagnosticSeverity = 4
+)
+
+// String returns the lowercase name of the severity.
+func (s DiagnosticSeverity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInformation:
+		return "info"
+	case SeverityHint:
+		return "hint"
+	default:
+		return "error"
+	}
+}
+
+// Diagnostic is a compiler or linter message about a range of a document.
+type Diagnostic struct { //nolint:govet // fieldalignment: preserving protocol field order
+	Range    Range              `json:"range"`
+	Severity DiagnosticSeverity `json:"severity,omitempty"`
+	Code     json.RawMessage    `json:"code,omitempty"` // Number or string
+	Source   string             `json:"source,omitempty"`
+	Message  string             `json:"message"`
+}
+
+// CodeString returns the diagnostic code as text, or "" when there is none.
+func (d *Diagnostic) CodeString() string {
+	if len(d.Code) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(d.Code, &s); err == nil {
+		return s
+	}
+	return strings.Trim(string(d.Code), `"`)
+}
+
+// Format renders the diagnostic as "path:line:col: severity: message (source)",
+// with one-based line and column numbers.
+func (d *Diagnostic) Format(path string) string {
+	msg := strings.ReplaceAll(strings.TrimSpace(d.Message), "\n", " ")
+	out := fmt.Sprintf("%s:%d:%d: %s: %s", path, d.Range.Start.Line+1, d.Range.Start.Character+1, d.Severity, msg)
+	switch code := d.CodeString(); {
+	case d.Source != "" && code != "":
+		out += fmt.Sprintf(" (%s %s)", d.Source, code)
+	case d.Source != "":
+		out += fmt.Sprintf(" (%s)", d.Source)
+	}
+	return out
+}
+
+// publishDiagnosticsParams is the payload of textDocument/publishDiagnostics.
+type publishDiagnosticsParams struct {
+	URI         DocumentURI  `json:"uri"`
+	Version     *int         `json:"version,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// workspaceFolder is a root folder of the workspace.
+type workspaceFolder struct {
+	URI  DocumentURI `json:"uri"`
+	Name string      `json:"name"`
+}
+
+// initializeParams is the payload of the initialize request. Only the
+// capabilities CDD relies on are declared.
+type initializeParams struct {
+	ProcessID             int               `json:"processId"`
+	RootURI               DocumentURI       `json:"rootUri"`
+	WorkspaceFolders      []workspaceFolder `json:"workspaceFolders"`
+	InitializationOptions any               `json:"initializationOptions,omitempty"`
+	Capabilities          map[string]any    `json:"capabilities"`
+}
+
+// textDocumentItem describes a document opened with textDocument/didOpen.
+type textDocumentItem struct {
+	URI        DocumentURI `json:"uri"`
+	LanguageID string      `json:"languageId"`
+	Version    int         `json:"version"`
+	Text       string      `json:"text"`
+}
+
+// versionedTextDocumentIdentifier identifies a document version in textDocument/didChange.
+type versionedTextDocumentIdentifier struct {
+	URI     DocumentURI `json:"uri"`
+	Version int         `json:"version"`
+}
+
+// textDocumentIdentifier identifies a document in textDocument/didClose.
+type textDocumentIdentifier struct {
+	URI DocumentURI `json:"uri"`
+}
+
+// contentChange replaces the full text of a document.
+type contentChange struct {
+	Text string `json:"text"`
+}
+
+// languageIDs maps file extensions to LSP language identifiers where they differ
+// from the extension itself.
+var languageIDs = map[string]string{
+	".go":   "go",
+	".py":   "python",
+	".ts":   "typescript",
+	".tsx":  "typescriptreact",
+	".js":   "javascript",
+	".jsx":  "javascriptreact",
+	".mjs":  "javascript",
+	".cjs":  "javascript",
+	".rs":   "rust",
+	".rb":   "ruby",
+	".sh":   "shellscript",
+	".bash": "shellscript",
+	".c":    "c",
+	".h":    "c",
+	".cpp":  "cpp",
+	".hpp":  "cpp",
+	".cs":   "csharp",
+	".md":   "markdown",
+	".yml":  "yaml",
+	".yaml": "yaml",
+}
+
+// LanguageID returns the LSP language identifier for a file path.
+func LanguageID(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if id, ok := languageIDs[ext]; ok {
+		return id
+	}
+	return strings.TrimPrefix(ext, ".")
+}
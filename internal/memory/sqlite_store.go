@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/db/sqlc"
+)
+
+// SQLiteStore implements Store using SQLite.
+type SQLiteStore struct {
+	queries *sqlc.Queries
+}
+
+// NewSQLiteStore creates a new SQLite-backed memory store.
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{queries: sqlc.New(db)}
+}
+
+// Add saves a note to project unless the same note is already saved.
+func (s *SQLiteStore) Add(ctx context.Context, project, sessionID, content string) (Memory, error) {
+	content = strings.TrimSpace(content)
+	existing, err := s.list(ctx, project)
+	if err != nil {
+		return Memory{}, err
+	}
+	for _, m := range existing {
+		if m.Content == content {
+			return m, nil
+		}
+	}
+
+	row, err := s.queries.AddMemory(ctx, sqlc.AddMemoryParams{
+		Project:   project,
+		SessionID: sessionID,
+		Content:   content,
+		CreatedAt: time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return Memory{}, fmt.Errorf("adding memory: %w", err)
+	}
+	return toMemory(row), nil
+}
+
+// Delete removes a note from project.
+func (s *SQLiteStore) Delete(ctx context.Context, project string, id int64) error {
+	existing, err := s.list(ctx, project)
+	if err != nil {
+		return err
+	}
+	for _, m := range existing {
+		if m.ID == id {
+			if err := s.queries.DeleteMemory(ctx, sqlc.DeleteMemoryParams{Project: project, ID: id}); err != nil {
+				return fmt.Errorf("deleting memory: %w", err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %d", ErrNotFound, id)
+}
+
+// Search returns the notes of project that best match query.
+func (s *SQLiteStore) Search(ctx context.Context, project, query string, limit int) ([]Memory, error) {
+	memories, err := s.list(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	return rank(memories, query, limit), nil
+}
+
+// list returns every note of project, newest first.
+func (s *SQLiteStore) list(ctx context.Context, project string) ([]Memory, error) {
+	rows, err := s.queries.ListMemories(ctx, project)
+	if err != nil {
+		return nil, fmt.Errorf("listing memories: %w", err)
+	}
+	memories := make([]Memory, len(rows))
+	for i, row := range rows {
+		memories[i] = toMemory(row)
+	}
+	return memories, nil
+}
+
+func toMemory(row sqlc.Memory) Memory {
+	return Memory{
+		ID:        row.ID,
+		Content:   row.Content,
+		SessionID: row.SessionID,
+		CreatedAt: time.UnixMilli(row.CreatedAt),
+	}
+}
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/guilhermegouw/cdd/internal/db"
+)
+
+func setupTestStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+
+	database, err := db.Open(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() }) //nolint:errcheck // Intentionally ignoring close error in test cleanup
+
+	return NewSQLiteStore(database.Conn())
+}
+
+func TestSQLiteStore_AddAndSearch(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+
+	for _, add := range []struct{ project, content string }{
+		{"/repo/a", "Tests run with make test"},
+		{"/repo/a", "The config loader lives in internal/config"},
+		{"/repo/b", "Tests run with npm test"},
+		{"/repo/a", "Integration tests need the test database running"},
+	} {
+		if _, err := store.Add(ctx, add.project, "s1", add.content); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	got, err := store.Search(ctx, "/repo/a", "test database", 10)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Search() returned %d memories, want 2: %+v", len(got), got)
+	}
+	if got[0].Content != "Integration tests need the test database running" {
+		t.Errorf("best match = %q, want the note matching both words", got[0].Content)
+	}
+
+	all, err := store.Search(ctx, "/repo/a", "", 2)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(all) != 2 || all[0].Content != "Integration tests need the test database running" {
+		t.Errorf("empty query should list the newest notes, got %+v", all)
+	}
+}
+
+func TestSQLiteStore_AddDuplicate(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+
+	first, err := store.Add(ctx, "/repo", "s1", "Use tabs")
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	second, err := store.Add(ctx, "/repo", "s2", "  Use tabs\n")
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("duplicate note got ID %d, want the existing %d", second.ID, first.ID)
+	}
+}
+
+func TestSQLiteStore_Delete(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+
+	m, err := store.Add(ctx, "/repo/a", "s1", "Old fact")
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := store.Delete(ctx, "/repo/b", m.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() from another project error = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, "/repo/a", m.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got, _ := store.Search(ctx, "/repo/a", "", 10); len(got) != 0 {
+		t.Errorf("Search() after Delete = %+v, want none", got)
+	}
+}
@@ -0,0 +1,65 @@
+// Package memory keeps durable notes the agent writes for itself, per
+// project, so that later sessions can build on what earlier ones learned.
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when a memory to replace does not exist.
+var ErrNotFound = errors.New("memory not found")
+
+// Memory is a note saved in a session of a project.
+type Memory struct {
+	ID        int64
+	Content   string
+	SessionID string
+	CreatedAt time.Time
+}
+
+// Store defines the interface for memory persistence.
+type Store interface {
+	// Add saves a note to project and returns it. A note whose content is
+	// already saved is returned as is.
+	Add(ctx context.Context, project, sessionID, content string) (Memory, error)
+
+	// Delete removes the note with the given ID from project.
+	Delete(ctx context.Context, project string, id int64) error
+
+	// Search returns up to limit notes of project matching query, best match
+	// first, or the newest notes when query is empty.
+	Search(ctx context.Context, project, query string, limit int) ([]Memory, error)
+}
+
+// rank orders memories, newest first, by how many of the query's words each
+// contains and drops those that contain none. An empty query keeps them all.
+func rank(memories []Memory, query string, limit int) []Memory {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) > 0 {
+		scores := make(map[int64]int, len(memories))
+		matched := memories[:0:0]
+		for _, m := range memories {
+			content := strings.ToLower(m.Content)
+			for _, word := range words {
+				if strings.Contains(content, word) {
+					scores[m.ID]++
+				}
+			}
+			if scores[m.ID] > 0 {
+				matched = append(matched, m)
+			}
+		}
+		sort.SliceStable(matched, func(i, j int) bool {
+			return scores[matched[i].ID] > scores[matched[j].ID]
+		})
+		memories = matched
+	}
+	if limit > 0 && len(memories) > limit {
+		memories = memories[:limit]
+	}
+	return memories
+}
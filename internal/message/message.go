@@ -34,10 +34,15 @@ type PartType string
 
 // Part type constants.
 const (
-	PartTypeText       PartType = "text"
-	PartTypeReasoning  PartType = "reasoning"
-	PartTypeToolCall   PartType = "tool_call"
-	PartTypeToolResult PartType = "tool_result"
+	PartTypeText        PartType = "text"
+	PartTypeReasoning   PartType = "reasoning"
+	PartTypeToolCall    PartType = "tool_call"
+	PartTypeToolResult  PartType = "tool_result"
+	PartTypeImage       PartType = "image"
+	PartTypeFile        PartType = "file"
+	PartTypeCancelled   PartType = "cancelled"
+	PartTypeInterrupted PartType = "interrupted" // Cut short by cdd exiting; also cancelled
+	PartTypeTiming      PartType = "timing"      // How fast the reply streamed
 )
 
 // Part represents a content part of a message.
@@ -47,6 +52,9 @@ type Part struct {
 	Reasoning  string      `json:"reasoning,omitempty"`
 	ToolCall   *ToolCall   `json:"tool_call,omitempty"`
 	ToolResult *ToolResult `json:"tool_result,omitempty"`
+	Image      *Attachment `json:"image,omitempty"`
+	File       *Attachment `json:"file,omitempty"`
+	Timing     *Timing     `json:"timing,omitempty"`
 }
 
 // ToolCall represents a tool invocation.
@@ -62,6 +70,22 @@ type ToolResult struct {
 	Name       string `json:"name"`
 	Content    string `json:"content"`
 	IsError    bool   `json:"is_error"`
+	FullOutput string `json:"full_output,omitempty"` // File with the whole output when Content was cut
+}
+
+// Timing is how fast the model streamed a reply.
+type Timing struct {
+	FirstToken   time.Duration `json:"first_token"`
+	OutputTokens int64         `json:"output_tokens"`
+	Generation   time.Duration `json:"generation"`
+}
+
+// Attachment is a file, such as an image, attached to a message.
+// The data is stored inline with the message.
+type Attachment struct {
+	Filename  string `json:"filename"`
+	MediaType string `json:"media_type"`
+	Data      []byte `json:"data"`
 }
 
 // TextContent returns the concatenated text content from all text parts.
@@ -106,6 +130,58 @@ func (m *Message) ToolResults() []*ToolResult {
 	return results
 }
 
+// Images returns all image attachments from the message parts.
+func (m *Message) Images() []*Attachment {
+	var images []*Attachment
+	for _, p := range m.Parts {
+		if p.Type == PartTypeImage && p.Image != nil {
+			images = append(images, p.Image)
+		}
+	}
+	return images
+}
+
+// Files returns all text file attachments from the message parts.
+func (m *Message) Files() []*Attachment {
+	var files []*Attachment
+	for _, p := range m.Parts {
+		if p.Type == PartTypeFile && p.File != nil {
+			files = append(files, p.File)
+		}
+	}
+	return files
+}
+
+// Cancelled reports whether the message was cut short by the user.
+func (m *Message) Cancelled() bool {
+	for _, p := range m.Parts {
+		if p.Type == PartTypeCancelled {
+			return true
+		}
+	}
+	return false
+}
+
+// Interrupted reports whether the message was cut short because cdd exited.
+func (m *Message) Interrupted() bool {
+	for _, p := range m.Parts {
+		if p.Type == PartTypeInterrupted {
+			return true
+		}
+	}
+	return false
+}
+
+// Timing returns how fast the reply streamed, or nil when it was not timed.
+func (m *Message) Timing() *Timing {
+	for _, p := range m.Parts {
+		if p.Type == PartTypeTiming && p.Timing != nil {
+			return p.Timing
+		}
+	}
+	return nil
+}
+
 // NewTextPart creates a new text part.
 func NewTextPart(text string) Part {
 	return Part{
@@ -146,3 +222,43 @@ func NewToolResultPart(toolCallID, name, content string, isError bool) Part {
 		},
 	}
 }
+
+// NewImagePart creates a new image part.
+func NewImagePart(filename, mediaType string, data []byte) Part {
+	return Part{
+		Type: PartTypeImage,
+		Image: &Attachment{
+			Filename:  filename,
+			MediaType: mediaType,
+			Data:      data,
+		},
+	}
+}
+
+// NewFilePart creates a new text file part.
+func NewFilePart(filename, mediaType string, data []byte) Part {
+	return Part{
+		Type: PartTypeFile,
+		File: &Attachment{
+			Filename:  filename,
+			MediaType: mediaType,
+			Data:      data,
+		},
+	}
+}
+
+// NewCancelledPart creates a part marking a message the user cut short.
+func NewCancelledPart() Part {
+	return Part{Type: PartTypeCancelled}
+}
+
+// NewInterruptedPart creates a part marking a message cut short by cdd
+// exiting.
+func NewInterruptedPart() Part {
+	return Part{Type: PartTypeInterrupted}
+}
+
+// NewTimingPart creates a part recording how fast a reply streamed.
+func NewTimingPart(timing Timing) Part {
+	return Part{Type: PartTypeTiming, Timing: &timing}
+}
@@ -131,6 +131,31 @@ func TestMessage_ToolResults(t *testing.T) {
 	})
 }
 
+func TestMessage_Images(t *testing.T) {
+	m := &Message{
+		Parts: []Part{
+			NewTextPart("what is this?"),
+			NewImagePart("screenshot.png", "image/png", []byte{0x89, 'P', 'N', 'G'}),
+			NewFilePart("main.go", "text/plain", []byte("package main")),
+		},
+	}
+
+	images := m.Images()
+	if len(images) != 1 {
+		t.Fatalf("Images() returned %d images, want 1", len(images))
+	}
+	if images[0].Filename != "screenshot.png" || images[0].MediaType != "image/png" {
+		t.Errorf("unexpected image %+v", images[0])
+	}
+	files := m.Files()
+	if len(files) != 1 || files[0].Filename != "main.go" || string(files[0].Data) != "package main" {
+		t.Errorf("Files() = %+v, want main.go", files)
+	}
+	if m.TextContent() != "what is this?" {
+		t.Errorf("TextContent() = %q", m.TextContent())
+	}
+}
+
 func TestNewTextPart(t *testing.T) {
 	part := NewTextPart("hello")
 
@@ -205,6 +230,8 @@ func TestPart_JSONSerialization(t *testing.T) {
 		NewReasoningPart("thinking"),
 		NewToolCallPart("id-1", "tool", "input"),
 		NewToolResultPart("id-1", "tool", "output", false),
+		NewImagePart("a.png", "image/png", []byte{0, 1, 2, 255}),
+		NewFilePart("a.txt", "text/plain", []byte("text")),
 	}
 
 	// Serialize
@@ -236,4 +263,10 @@ func TestPart_JSONSerialization(t *testing.T) {
 	if decoded[3].Type != PartTypeToolResult || decoded[3].ToolResult.ToolCallID != "id-1" {
 		t.Errorf("tool result part mismatch: %+v", decoded[3])
 	}
+	if decoded[4].Type != PartTypeImage || string(decoded[4].Image.Data) != string([]byte{0, 1, 2, 255}) {
+		t.Errorf("image part mismatch: %+v", decoded[4])
+	}
+	if decoded[5].Type != PartTypeFile || decoded[5].File.Filename != "a.txt" || string(decoded[5].File.Data) != "text" {
+		t.Errorf("file part mismatch: %+v", decoded[5])
+	}
 }
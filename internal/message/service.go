@@ -45,6 +45,22 @@ func (s *Service) Add(ctx context.Context, msg *Message) error {
 	return nil
 }
 
+// AddBatch creates messages in order in a single write.
+func (s *Service) AddBatch(ctx context.Context, msgs []*Message) error {
+	if err := s.store.CreateBatch(ctx, msgs); err != nil {
+		return err
+	}
+
+	if s.broker != nil {
+		for _, msg := range msgs {
+			s.broker.Publish(pubsub.EventProgress,
+				events.NewSessionMessageAddedEvent(msg.SessionID, string(msg.Role), msg.TextContent()))
+		}
+	}
+
+	return nil
+}
+
 // Get retrieves a message by ID.
 func (s *Service) Get(ctx context.Context, id string) (*Message, error) {
 	return s.store.Get(ctx, id)
@@ -103,6 +119,33 @@ func (s *Service) Delete(ctx context.Context, id string) error {
 	return s.store.Delete(ctx, id)
 }
 
+// DeleteFrom removes a message and every later message in its session. It
+// returns how many messages were removed.
+func (s *Service) DeleteFrom(ctx context.Context, sessionID, messageID string) (int, error) {
+	msgs, err := s.store.GetBySession(ctx, sessionID)
+	if err != nil {
+		return 0, err
+	}
+
+	start := -1
+	for i, msg := range msgs {
+		if msg.ID == messageID {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return 0, ErrNotFound
+	}
+
+	for i, msg := range msgs[start:] {
+		if err := s.store.Delete(ctx, msg.ID); err != nil {
+			return i, err
+		}
+	}
+	return len(msgs) - start, nil
+}
+
 // TrimOldMessages removes old messages keeping only the most recent ones.
 func (s *Service) TrimOldMessages(ctx context.Context, sessionID string, keepCount int) error {
 	return s.store.DeleteOldMessages(ctx, sessionID, keepCount)
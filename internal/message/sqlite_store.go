@@ -18,18 +18,47 @@ var ErrNotFound = errors.New("message not found")
 
 // SQLiteStore implements Store using SQLite.
 type SQLiteStore struct {
+	db      *sql.DB
 	queries *sqlc.Queries
 }
 
 // NewSQLiteStore creates a new SQLite-backed message store.
 func NewSQLiteStore(db *sql.DB) *SQLiteStore {
 	return &SQLiteStore{
+		db:      db,
 		queries: sqlc.New(db),
 	}
 }
 
 // Create creates a new message.
 func (s *SQLiteStore) Create(ctx context.Context, msg *Message) error {
+	return createMessage(ctx, s.queries, msg)
+}
+
+// CreateBatch creates messages in order in a single transaction, so a turn's
+// messages cost one write and are saved together or not at all.
+func (s *SQLiteStore) CreateBatch(ctx context.Context, msgs []*Message) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit
+
+	queries := s.queries.WithTx(tx)
+	for _, msg := range msgs {
+		if err := createMessage(ctx, queries, msg); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing messages: %w", err)
+	}
+	return nil
+}
+
+// createMessage inserts msg, filling in its ID and timestamps.
+func createMessage(ctx context.Context, queries *sqlc.Queries, msg *Message) error {
 	if msg.ID == "" {
 		msg.ID = uuid.New().String()
 	}
@@ -50,7 +79,7 @@ func (s *SQLiteStore) Create(ctx context.Context, msg *Message) error {
 		isSummary = 1
 	}
 
-	_, err = s.queries.CreateMessage(ctx, sqlc.CreateMessageParams{
+	_, err = queries.CreateMessage(ctx, sqlc.CreateMessageParams{
 		ID:        msg.ID,
 		SessionID: msg.SessionID,
 		Role:      string(msg.Role),
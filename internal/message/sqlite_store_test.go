@@ -106,6 +106,41 @@ func TestSQLiteStore_Create(t *testing.T) {
 	})
 }
 
+func TestSQLiteStore_CreateBatch(t *testing.T) {
+	database := setupTestDB(t)
+	store := NewSQLiteStore(database.Conn())
+	ctx := context.Background()
+	createTestSession(t, database, "sess-1")
+
+	now := time.Now()
+	err := store.CreateBatch(ctx, []*Message{
+		{SessionID: "sess-1", Role: RoleAssistant, Parts: []Part{NewToolCallPart("call-1", "read_file", "{}")}, CreatedAt: now},
+		{SessionID: "sess-1", Role: RoleTool, Parts: []Part{NewToolResultPart("call-1", "read_file", "ok", false)}, CreatedAt: now.Add(time.Millisecond)},
+	})
+	if err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	msgs, err := store.GetBySession(ctx, "sess-1")
+	if err != nil {
+		t.Fatalf("GetBySession() error = %v", err)
+	}
+	if len(msgs) != 2 || msgs[0].Role != RoleAssistant || msgs[1].Role != RoleTool || msgs[0].ID == "" {
+		t.Fatalf("expected both messages in order with IDs, got %+v", msgs)
+	}
+
+	// A failure part way saves none of the batch.
+	err = store.CreateBatch(ctx, []*Message{
+		{SessionID: "sess-1", Role: RoleUser, Parts: []Part{NewTextPart("kept?")}},
+		{ID: msgs[0].ID, SessionID: "sess-1", Role: RoleUser, Parts: []Part{NewTextPart("duplicate")}},
+	})
+	if err == nil {
+		t.Fatal("CreateBatch() with a duplicate ID should fail")
+	}
+	if count, _ := store.Count(ctx, "sess-1"); count != 2 { //nolint:errcheck // Checked through the count
+		t.Errorf("Count() = %d after a failed batch, want 2", count)
+	}
+}
+
 func TestSQLiteStore_Get(t *testing.T) {
 	database := setupTestDB(t)
 	store := NewSQLiteStore(database.Conn())
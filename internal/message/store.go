@@ -9,6 +9,9 @@ type Store interface {
 	// Create creates a new message.
 	Create(ctx context.Context, msg *Message) error
 
+	// CreateBatch creates messages in order, all or none of them.
+	CreateBatch(ctx context.Context, msgs []*Message) error
+
 	// Get retrieves a message by ID.
 	Get(ctx context.Context, id string) (*Message, error)
 
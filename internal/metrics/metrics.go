@@ -0,0 +1,114 @@
+// Package metrics exposes counters and histograms of agent activity in the
+// Prometheus text format, served locally when options.metrics.listen is set.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/guilhermegouw/cdd/internal/debug"
+)
+
+// Request and tool outcomes used as the status label.
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// readHeaderTimeout guards the endpoint against clients that never finish
+// their request headers.
+const readHeaderTimeout = 5 * time.Second
+
+// Metrics holds the collected metrics. A nil *Metrics discards observations,
+// so callers need not check whether metrics are enabled.
+type Metrics struct {
+	registry     *prometheus.Registry
+	requests     *prometheus.CounterVec
+	tokens       *prometheus.CounterVec
+	toolDuration *prometheus.HistogramVec
+}
+
+// New creates an empty set of metrics with its own registry.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cdd_provider_requests_total",
+			Help: "Requests sent to model providers, by outcome.",
+		}, []string{"provider", "model", "status"}),
+		tokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cdd_tokens_total",
+			Help: "Tokens sent to and received from model providers.",
+		}, []string{"provider", "model", "direction"}),
+		toolDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "cdd_tool_duration_seconds",
+			Help:    "Time taken by tool executions.",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+		}, []string{"tool", "status"}),
+	}
+	m.registry.MustRegister(m.requests, m.tokens, m.toolDuration)
+	return m
+}
+
+// ObserveRequest records a provider request and the tokens it used. A
+// non-nil err counts it as failed.
+func (m *Metrics) ObserveRequest(provider, model string, inputTokens, outputTokens int64, err error) {
+	if m == nil {
+		return
+	}
+	m.requests.WithLabelValues(provider, model, status(err != nil)).Inc()
+	m.tokens.WithLabelValues(provider, model, "input").Add(float64(inputTokens))
+	m.tokens.WithLabelValues(provider, model, "output").Add(float64(outputTokens))
+}
+
+// ObserveTool records how long a tool execution took.
+func (m *Metrics) ObserveTool(tool string, duration time.Duration, failed bool) {
+	if m == nil {
+		return
+	}
+	m.toolDuration.WithLabelValues(tool, status(failed)).Observe(duration.Seconds())
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Serve exposes the metrics at /metrics on addr until ctx is done. It returns
+// the address listened on once the listener is open, so a bad address is
+// reported straight away.
+func (m *Metrics) Serve(ctx context.Context, addr string) (string, error) {
+	listener, err := (&net.ListenConfig{}).Listen(ctx, "tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("listening on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: readHeaderTimeout}
+
+	go func() {
+		<-ctx.Done()
+		server.Close() //nolint:errcheck,gosec // Shutting down; nothing to report
+	}()
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			debug.Error("metrics", err, "serving metrics")
+		}
+	}()
+	return listener.Addr().String(), nil
+}
+
+func status(failed bool) string {
+	if failed {
+		return StatusError
+	}
+	return StatusOK
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scrape returns the metrics as Prometheus would see them.
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	return rec.Body.String()
+}
+
+func TestMetrics_Observe(t *testing.T) {
+	m := New()
+	m.ObserveRequest("anthropic", "claude", 100, 20, nil)
+	m.ObserveRequest("anthropic", "claude", 50, 10, nil)
+	m.ObserveRequest("openai", "gpt", 0, 0, errors.New("overloaded"))
+	m.ObserveTool("bash", 1500*time.Millisecond, false)
+	m.ObserveTool("bash", time.Second, true)
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		`cdd_provider_requests_total{model="claude",provider="anthropic",status="ok"} 2`,
+		`cdd_provider_requests_total{model="gpt",provider="openai",status="error"} 1`,
+		`cdd_tokens_total{direction="input",model="claude",provider="anthropic"} 150`,
+		`cdd_tokens_total{direction="output",model="claude",provider="anthropic"} 30`,
+		`cdd_tool_duration_seconds_count{status="ok",tool="bash"} 1`,
+		`cdd_tool_duration_seconds_bucket{status="error",tool="bash",le="1"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %s\n%s", want, body)
+		}
+	}
+}
+
+func TestMetrics_NilDiscards(t *testing.T) {
+	var m *Metrics
+	m.ObserveRequest("p", "m", 1, 1, nil)
+	m.ObserveTool("bash", time.Second, false)
+}
+
+func TestMetrics_Serve(t *testing.T) {
+	m := New()
+	m.ObserveRequest("anthropic", "claude", 1, 1, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	addr, err := m.Serve(ctx, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	resp, err := http.Get("http://" + addr + "/metrics") //nolint:noctx // Test request
+	if err != nil {
+		t.Fatalf("GET /metrics error = %v", err)
+	}
+	defer resp.Body.Close()          //nolint:errcheck // Test cleanup
+	body, _ := io.ReadAll(resp.Body) //nolint:errcheck // Checked through the content
+	if !strings.Contains(string(body), "cdd_provider_requests_total") {
+		t.Errorf("unexpected response:\n%s", body)
+	}
+
+	if _, err := m.Serve(ctx, "not an address"); err == nil {
+		t.Error("Serve() with a bad address should fail")
+	}
+}
@@ -0,0 +1,31 @@
+// Package proc tells this cdd process apart from others, so the records cdd
+// leaves on disk while it works, such as runs in progress and session locks,
+// can be recognised as live or left behind by a cdd that is gone.
+package proc
+
+import (
+	"os"
+
+	"github.com/google/uuid"
+)
+
+// Instance identifies this process. Unlike the PID, it is never reused by a
+// later cdd, as happens in containers where every cdd gets the same PID.
+var Instance = uuid.New().String()
+
+// PID returns this process's ID.
+func PID() int {
+	return os.Getpid()
+}
+
+// Running reports whether the cdd that recorded pid and instance is still
+// running: either this process or another live one.
+func Running(pid int, instance string) bool {
+	if instance == Instance {
+		return true
+	}
+	if pid <= 0 || pid == os.Getpid() {
+		return false // An earlier cdd that had the same PID
+	}
+	return alive(pid)
+}
@@ -0,0 +1,27 @@
+package proc
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestRunning(t *testing.T) {
+	if !Running(os.Getpid(), Instance) {
+		t.Error("this process should be running")
+	}
+	if Running(os.Getpid(), "earlier") {
+		t.Error("an earlier cdd with this PID is not running")
+	}
+	if Running(0, "") {
+		t.Error("a record without a PID is not running")
+	}
+
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skip("true is not available")
+	}
+	if Running(cmd.Process.Pid, "other") {
+		t.Error("a process that exited is not running")
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package proc
+
+import (
+	"os"
+	"syscall"
+)
+
+// alive reports whether a process with pid exists, by sending it signal 0.
+func alive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
@@ -0,0 +1,33 @@
+//go:build windows
+
+package proc
+
+import (
+	"errors"
+	"syscall"
+)
+
+const (
+	// processQueryLimitedInformation is the least access that lets
+	// GetExitCodeProcess read a process's exit code.
+	processQueryLimitedInformation = 0x1000
+	// stillActive is the exit code of a process that has not exited.
+	stillActive = 259
+)
+
+// alive reports whether a process with pid exists. Windows has no signal 0,
+// so the process is opened and its exit code checked instead.
+func alive(pid int) bool {
+	handle, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		// A process of another user cannot be opened, but it is there.
+		return errors.Is(err, syscall.ERROR_ACCESS_DENIED)
+	}
+	defer syscall.CloseHandle(handle) //nolint:errcheck // Nothing to do if it fails
+
+	var code uint32
+	if err := syscall.GetExitCodeProcess(handle, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package proc
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestAlive(t *testing.T) {
+	cmd := exec.Command("ping", "-n", "10", "127.0.0.1")
+	if err := cmd.Start(); err != nil {
+		t.Skip("ping is not available")
+	}
+	if !alive(cmd.Process.Pid) {
+		t.Error("a running process should be alive")
+	}
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+	if alive(cmd.Process.Pid) {
+		t.Error("a process that exited should not be alive")
+	}
+}
@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+// Defaults applied to discovered models, which come without metadata.
+const (
+	DiscoveredContextWindow = 128000
+	DiscoveredMaxTokens     = 4096
+)
+
+// DiscoverModels fetches the models served by an OpenAI-compatible endpoint
+// (Ollama, LM Studio, vLLM, ...) and returns them as catwalk models with
+// default limits. Environment variable references in the API key and header
+// values are resolved first.
+func DiscoverModels(ctx context.Context, baseURL, apiKey string, headers map[string]string) ([]catwalk.Model, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("no endpoint to discover models from")
+	}
+
+	resolver := config.NewResolver()
+	resolve := func(v string) string {
+		if resolved, err := resolver.Resolve(v); err == nil {
+			return resolved
+		}
+		return v
+	}
+
+	resolvedHeaders := make(map[string]string, len(headers))
+	for k, v := range headers {
+		resolvedHeaders[k] = resolve(v)
+	}
+
+	ids, _, err := ListModels(ctx, resolve(baseURL), resolve(apiKey), resolvedHeaders)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("endpoint returned no models")
+	}
+
+	models := make([]catwalk.Model, 0, len(ids))
+	for _, id := range ids {
+		models = append(models, catwalk.Model{
+			ID:               id,
+			Name:             id,
+			ContextWindow:    DiscoveredContextWindow,
+			DefaultMaxTokens: DiscoveredMaxTokens,
+		})
+	}
+	return models, nil
+}
+
+// MergeModels returns the discovered models, keeping the metadata of any known
+// model with the same ID (context window, costs, ...).
+func MergeModels(discovered, known []catwalk.Model) []catwalk.Model {
+	byID := make(map[string]catwalk.Model, len(known))
+	for i := range known {
+		byID[known[i].ID] = known[i]
+	}
+
+	merged := make([]catwalk.Model, 0, len(discovered))
+	for i := range discovered {
+		if k, ok := byID[discovered[i].ID]; ok {
+			merged = append(merged, k)
+			continue
+		}
+		merged = append(merged, discovered[i])
+	}
+	return merged
+}
+
+// SupportsDiscovery reports whether models can be discovered for a provider type.
+func SupportsDiscovery(t catwalk.Type) bool {
+	return isOpenAICompatible(t)
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+func TestDiscoverModels(t *testing.T) {
+	t.Setenv("DISCOVER_TEST_KEY", "secret")
+
+	var gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Api-Key")
+		_, _ = w.Write([]byte(`{"data":[{"id":"llama3.2"},{"id":"qwen2.5:7b"}]}`)) //nolint:errcheck // Test server response.
+	}))
+	defer server.Close()
+
+	models, err := DiscoverModels(context.Background(), server.URL+"/v1", "", map[string]string{"X-Api-Key": "$DISCOVER_TEST_KEY"})
+	if err != nil {
+		t.Fatalf("DiscoverModels() error = %v", err)
+	}
+	if len(models) != 2 || models[0].ID != "llama3.2" || models[1].ID != "qwen2.5:7b" {
+		t.Fatalf("unexpected models %+v", models)
+	}
+	if models[0].ContextWindow != DiscoveredContextWindow || models[0].DefaultMaxTokens != DiscoveredMaxTokens {
+		t.Errorf("expected default limits, got %+v", models[0])
+	}
+	if gotHeader != "secret" {
+		t.Errorf("header = %q, want resolved env value", gotHeader)
+	}
+}
+
+func TestDiscoverModels_Errors(t *testing.T) {
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data":[]}`)) //nolint:errcheck // Test server response.
+	}))
+	defer empty.Close()
+
+	if _, err := DiscoverModels(context.Background(), empty.URL, "", nil); err == nil {
+		t.Error("expected error for empty model list")
+	}
+	if _, err := DiscoverModels(context.Background(), "", "", nil); err == nil {
+		t.Error("expected error without endpoint")
+	}
+}
+
+func TestMergeModels(t *testing.T) {
+	discovered := []catwalk.Model{{ID: "a", Name: "a"}, {ID: "b", Name: "b"}}
+	known := []catwalk.Model{{ID: "b", Name: "Model B", ContextWindow: 32768}, {ID: "c", Name: "Model C"}}
+
+	merged := MergeModels(discovered, known)
+	if len(merged) != 2 {
+		t.Fatalf("got %d models, want 2", len(merged))
+	}
+	if merged[0].Name != "a" || merged[1].Name != "Model B" || merged[1].ContextWindow != 32768 {
+		t.Errorf("unexpected merge result %+v", merged)
+	}
+}
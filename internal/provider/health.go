@@ -0,0 +1,323 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+
+	"charm.land/fantasy"
+	"charm.land/fantasy/providers/openai"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+// checkTimeout bounds a single health check.
+const checkTimeout = 30 * time.Second
+
+// checkMaxTokens keeps the completion probe as cheap as possible while staying
+// above the minimum some providers enforce.
+const checkMaxTokens = 16
+
+// defaultOpenAIBaseURL is used when an OpenAI provider has no base URL configured.
+const defaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+// CheckMethod identifies how a provider was probed.
+type CheckMethod string
+
+// Check methods.
+const (
+	CheckMethodModels     CheckMethod = "models"
+	CheckMethodCompletion CheckMethod = "completion"
+)
+
+// CheckResult reports the outcome of a provider health check.
+type CheckResult struct { //nolint:govet // fieldalignment: preserving logical field order
+	ProviderID     string
+	ConnectionID   string // Set when a connection was checked
+	Endpoint       string
+	Model          string
+	Method         CheckMethod
+	Latency        time.Duration
+	AuthValid      bool
+	ModelAvailable bool
+	Models         []string // Models listed by the endpoint (models method only)
+	Err            error    // Why the check failed, if it did
+}
+
+// OK reports whether the endpoint is reachable, accepted the credentials, and serves the model.
+func (r *CheckResult) OK() bool {
+	return r.Err == nil && r.AuthValid && r.ModelAvailable
+}
+
+// Check probes a provider or connection, identified by ID (or connection name),
+// with a models-list call where the API supports one, falling back to a minimal
+// completion. An empty model checks the provider's default model.
+func (b *Builder) Check(ctx context.Context, id, model string) (*CheckResult, error) {
+	return b.check(ctx, id, model, true)
+}
+
+// CheckCompletion is Check without the models-list shortcut: it always sends
+// the model a minimal prompt, so a pass means the model itself answered.
+func (b *Builder) CheckCompletion(ctx context.Context, id, model string) (*CheckResult, error) {
+	return b.check(ctx, id, model, false)
+}
+
+func (b *Builder) check(ctx context.Context, id, model string, listFirst bool) (*CheckResult, error) {
+	if err := b.refreshExpiredTokens(ctx); err != nil {
+		return nil, fmt.Errorf("refreshing tokens: %w", err)
+	}
+
+	providerCfg, conn, err := b.resolveTarget(id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CheckResult{
+		ProviderID: providerCfg.ID,
+		Endpoint:   providerCfg.BaseURL,
+		Model:      model,
+	}
+	if conn != nil {
+		result.ConnectionID = conn.ID
+	}
+	if result.Model == "" {
+		result.Model = b.defaultCheckModel(providerCfg, conn)
+	}
+	if result.Model == "" {
+		return nil, fmt.Errorf("provider %q has no models; pass a model to check", providerCfg.ID)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	if listFirst && isOpenAICompatible(providerCfg.Type) && checkModelsList(ctx, providerCfg, result) {
+		return result, nil
+	}
+	b.checkCompletion(ctx, providerCfg, result)
+	return result, nil
+}
+
+// resolveTarget finds the provider configuration for a connection ID, connection name, or provider ID.
+func (b *Builder) resolveTarget(id string) (*config.ProviderConfig, *config.Connection, error) {
+	connManager := config.NewConnectionManager(b.cfg)
+	conn := connManager.Get(id)
+	if conn == nil {
+		conn = connManager.GetByName(id)
+	}
+
+	providerID := id
+	if conn != nil {
+		providerID = conn.ProviderID
+	}
+
+	providerCfg, ok := b.cfg.Providers[providerID]
+	if !ok {
+		return nil, nil, fmt.Errorf("provider or connection %q not configured", id)
+	}
+	if conn != nil {
+		providerCfg = applyConnectionCredentials(providerCfg, conn)
+	}
+	return providerCfg, conn, nil
+}
+
+// defaultCheckModel picks the model to check: the selected model using this
+// provider or connection, then the provider's default small, default large,
+// or first model.
+func (b *Builder) defaultCheckModel(providerCfg *config.ProviderConfig, conn *config.Connection) string {
+	for _, tier := range []config.SelectedModelType{config.SelectedModelTypeSmall, config.SelectedModelTypeLarge} {
+		selected, ok := b.cfg.Models[tier]
+		if !ok || selected.Model == "" {
+			continue
+		}
+		if conn != nil && selected.ConnectionID == conn.ID {
+			return selected.Model
+		}
+		if conn == nil && selected.Provider == providerCfg.ID {
+			return selected.Model
+		}
+	}
+
+	if known := b.knownProvider(providerCfg.ID); known != nil {
+		if known.DefaultSmallModelID != "" {
+			return known.DefaultSmallModelID
+		}
+		if known.DefaultLargeModelID != "" {
+			return known.DefaultLargeModelID
+		}
+	}
+	if len(providerCfg.Models) > 0 {
+		return providerCfg.Models[0].ID
+	}
+	return ""
+}
+
+// knownProvider returns the catwalk metadata for a provider, if any.
+func (b *Builder) knownProvider(id string) *catwalk.Provider {
+	known := b.cfg.KnownProviders()
+	for i := range known {
+		if string(known[i].ID) == id {
+			return &known[i]
+		}
+	}
+	return nil
+}
+
+// checkModelsList probes GET {base}/models. It returns false when the endpoint
+// does not support model listing, so the caller can fall back to a completion.
+func checkModelsList(ctx context.Context, providerCfg *config.ProviderConfig, result *CheckResult) bool {
+	baseURL := providerCfg.BaseURL
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+	result.Endpoint = baseURL
+
+	client, err := httpClient(providerCfg.HTTP, nil)
+	if err != nil {
+		result.Method = CheckMethodModels
+		result.Err = err
+		return true
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	start := time.Now()
+	models, status, err := listModels(ctx, client, baseURL, providerCfg.APIKey, providerCfg.ExtraHeaders)
+	result.Latency = time.Since(start)
+
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		result.Method = CheckMethodModels
+		result.Err = err
+		return true
+	case err != nil && status == 0:
+		// The endpoint is unreachable; a completion would fail the same way.
+		result.Method = CheckMethodModels
+		result.Err = err
+		return true
+	case err != nil:
+		return false
+	}
+
+	result.Method = CheckMethodModels
+	result.AuthValid = true
+	result.Models = models
+	result.ModelAvailable = slices.Contains(models, result.Model)
+	if !result.ModelAvailable {
+		result.Err = fmt.Errorf("model %q is not served by this endpoint", result.Model)
+	}
+	return true
+}
+
+// checkCompletion sends a minimal prompt to the model.
+func (b *Builder) checkCompletion(ctx context.Context, providerCfg *config.ProviderConfig, result *CheckResult) {
+	result.Method = CheckMethodCompletion
+
+	provider, err := b.buildProvider(providerCfg, config.SelectedModel{Model: result.Model})
+	if err != nil {
+		result.Err = err
+		return
+	}
+	lm, err := provider.LanguageModel(ctx, languageModelID(providerCfg, result.Model))
+	if err != nil {
+		result.Err = err
+		return
+	}
+
+	var prompt fantasy.Prompt
+	if providerCfg.SystemPromptPrefix != "" {
+		prompt = append(prompt, fantasy.NewSystemMessage(providerCfg.SystemPromptPrefix))
+	}
+	prompt = append(prompt, fantasy.NewUserMessage("Reply with OK."))
+	maxTokens := int64(checkMaxTokens)
+
+	start := time.Now()
+	_, err = lm.Generate(ctx, fantasy.Call{Prompt: prompt, MaxOutputTokens: &maxTokens})
+	result.Latency = time.Since(start)
+
+	if err == nil {
+		result.AuthValid = true
+		result.ModelAvailable = true
+		return
+	}
+
+	result.Err = err
+	var providerErr *fantasy.ProviderError
+	if !errors.As(err, &providerErr) {
+		return
+	}
+	// Any HTTP error other than 401/403 means the credentials were accepted.
+	if providerErr.StatusCode != 0 &&
+		providerErr.StatusCode != http.StatusUnauthorized &&
+		providerErr.StatusCode != http.StatusForbidden {
+		result.AuthValid = true
+	}
+}
+
+// isOpenAICompatible reports whether the provider speaks the OpenAI API and so serves GET /models.
+func isOpenAICompatible(t catwalk.Type) bool {
+	return t == openai.Name || t == catwalk.TypeOpenAICompat
+}
+
+// modelsResponse is the OpenAI-style response of GET /models.
+type modelsResponse struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+// ListModels fetches the model IDs served by an OpenAI-compatible endpoint.
+// The returned status is the HTTP status code, or zero if no response was received.
+func ListModels(ctx context.Context, baseURL, apiKey string, headers map[string]string) ([]string, int, error) {
+	return listModels(ctx, http.DefaultClient, baseURL, apiKey, headers)
+}
+
+// listModels is ListModels with the client to send the request with.
+func listModels(ctx context.Context, client *http.Client, baseURL, apiKey string, headers map[string]string) ([]string, int, error) {
+	url := strings.TrimRight(baseURL, "/") + "/models"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, 0, fmt.Errorf("creating request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+strings.TrimPrefix(apiKey, "Bearer "))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("requesting %s: %w", url, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // Response body close error is not actionable
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20)) //nolint:mnd // 10 MiB is plenty for a model list
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, fmt.Errorf("GET %s: HTTP %d: %s", url, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var parsed modelsResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("parsing model list: %w", err)
+	}
+
+	models := make([]string, 0, len(parsed.Data))
+	for _, m := range parsed.Data {
+		if m.ID != "" {
+			models = append(models, m.ID)
+		}
+	}
+	return models, resp.StatusCode, nil
+}
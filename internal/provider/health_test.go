@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+// newCheckConfig returns a config with a single OpenAI-compatible provider at baseURL.
+func newCheckConfig(baseURL string) *config.Config {
+	cfg := config.NewConfig()
+	cfg.Providers["local"] = &config.ProviderConfig{
+		ID:      "local",
+		Type:    catwalk.TypeOpenAICompat,
+		APIKey:  "local-key",
+		BaseURL: baseURL,
+		Models:  []catwalk.Model{{ID: "llama3"}},
+	}
+	return cfg
+}
+
+func TestBuilder_Check_ModelsList(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			http.NotFound(w, r)
+			return
+		}
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"llama3"},{"id":"qwen2.5"}]}`)) //nolint:errcheck // Test server response.
+	}))
+	defer server.Close()
+
+	t.Run("reports available model", func(t *testing.T) {
+		result, err := NewBuilder(newCheckConfig(server.URL+"/v1")).Check(context.Background(), "local", "")
+		if err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+		if !result.OK() {
+			t.Fatalf("expected healthy result, got %+v", result)
+		}
+		if result.Method != CheckMethodModels || result.Model != "llama3" || len(result.Models) != 2 {
+			t.Errorf("unexpected result %+v", result)
+		}
+		if gotAuth != "Bearer local-key" {
+			t.Errorf("Authorization = %q", gotAuth)
+		}
+	})
+
+	t.Run("reports missing model", func(t *testing.T) {
+		result, err := NewBuilder(newCheckConfig(server.URL+"/v1")).Check(context.Background(), "local", "mistral")
+		if err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+		if !result.AuthValid || result.ModelAvailable || result.OK() {
+			t.Errorf("expected valid auth without model, got %+v", result)
+		}
+	})
+}
+
+func TestBuilder_Check_Unauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"error":"invalid api key"}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	result, err := NewBuilder(newCheckConfig(server.URL)).Check(context.Background(), "local", "")
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if result.AuthValid || result.Err == nil {
+		t.Errorf("expected auth failure, got %+v", result)
+	}
+}
+
+func TestBuilder_Check_FallsBackToCompletion(t *testing.T) {
+	var completions int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		completions++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","model":"llama3",` + //nolint:errcheck // Test server response.
+			`"choices":[{"index":0,"message":{"role":"assistant","content":"OK"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	result, err := NewBuilder(newCheckConfig(server.URL)).Check(context.Background(), "local", "")
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if result.Method != CheckMethodCompletion || !result.OK() {
+		t.Errorf("expected healthy completion check, got %+v", result)
+	}
+	if completions != 1 {
+		t.Errorf("completions = %d, want 1", completions)
+	}
+}
+
+func TestBuilder_CheckCompletion(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path != "/chat/completions" {
+			_, _ = w.Write([]byte(`{"data":[{"id":"llama3"}]}`)) //nolint:errcheck // Test server response.
+			return
+		}
+		http.Error(w, `{"error":{"message":"model not found"}}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	// The model list would pass; the completion shows the model does not answer.
+	result, err := NewBuilder(newCheckConfig(server.URL)).CheckCompletion(context.Background(), "local", "")
+	if err != nil {
+		t.Fatalf("CheckCompletion() error = %v", err)
+	}
+	if result.Method != CheckMethodCompletion || result.OK() || !result.AuthValid {
+		t.Errorf("expected a failed completion with valid auth, got %+v", result)
+	}
+	if len(paths) != 1 || paths[0] != "/chat/completions" {
+		t.Errorf("requests = %v, want only the completion", paths)
+	}
+}
+
+func TestBuilder_Check_Connection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer conn-key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"llama3"}]}`)) //nolint:errcheck // Test server response.
+	}))
+	defer server.Close()
+
+	cfg := newCheckConfig("http://unused.invalid")
+	cfg.Connections = []config.Connection{{
+		ID:         "conn-1",
+		Name:       "Home Ollama",
+		ProviderID: "local",
+		APIKey:     "conn-key",
+		BaseURL:    server.URL,
+	}}
+
+	for _, id := range []string{"conn-1", "Home Ollama"} {
+		result, err := NewBuilder(cfg).Check(context.Background(), id, "")
+		if err != nil {
+			t.Fatalf("Check(%q) error = %v", id, err)
+		}
+		if !result.OK() || result.ConnectionID != "conn-1" {
+			t.Errorf("Check(%q) = %+v, want healthy connection", id, result)
+		}
+	}
+}
+
+func TestBuilder_Check_UnknownTarget(t *testing.T) {
+	if _, err := NewBuilder(config.NewConfig()).Check(context.Background(), "missing", ""); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+}
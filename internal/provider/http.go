@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+// httpClient returns the client for a provider's requests, with its HTTP
+// settings and extra query parameters applied. It returns nil when there are
+// neither, so each SDK keeps its default client.
+func httpClient(opts *config.HTTPOptions, query map[string]string) (*http.Client, error) {
+	if opts == nil && len(query) == 0 {
+		return nil, nil
+	}
+
+	var base http.RoundTripper = http.DefaultTransport
+	if opts != nil {
+		transport, err := newTransport(opts)
+		if err != nil {
+			return nil, err
+		}
+		base = transport
+	}
+	if len(query) > 0 {
+		base = &queryTransport{base: base, query: query}
+	}
+	return &http.Client{Transport: base}, nil
+}
+
+// newTransport creates a transport with the proxy, TLS and timeout settings
+// of opts. The proxy URL and CA file may reference environment variables.
+func newTransport(opts *config.HTTPOptions) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:errcheck // The default transport is an *http.Transport.
+	resolver := config.NewResolver()
+	resolve := func(v string) string {
+		if resolved, err := resolver.Resolve(v); err == nil {
+			return resolved
+		}
+		return v
+	}
+
+	if opts.Proxy != "" {
+		proxyURL, err := url.Parse(resolve(opts.Proxy))
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", opts.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if opts.CACertFile != "" || opts.InsecureSkipVerify {
+		tlsConfig := &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: opts.InsecureSkipVerify, //nolint:gosec // G402: Opt-in for self-signed certificates.
+		}
+		if opts.CACertFile != "" {
+			pool, err := certPool(resolve(opts.CACertFile))
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	if opts.TimeoutSeconds > 0 {
+		// Only the wait for the response headers is bounded, as a streamed
+		// reply can take much longer than any sensible request timeout.
+		transport.ResponseHeaderTimeout = time.Duration(opts.TimeoutSeconds) * time.Second
+	}
+	return transport, nil
+}
+
+// certPool returns the system roots plus the certificates in the PEM file at
+// path, which may start with ~/.
+func certPool(path string) (*x509.CertPool, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+	pem, err := os.ReadFile(path) //nolint:gosec // G304: CA bundle chosen by the user.
+	if err != nil {
+		return nil, fmt.Errorf("reading CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
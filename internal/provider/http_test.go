@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+func TestHTTPClient(t *testing.T) {
+	if client, err := httpClient(nil, nil); client != nil || err != nil {
+		t.Errorf("httpClient(nil, nil) = %v, %v, want no client so SDKs keep theirs", client, err)
+	}
+
+	transport, err := newTransport(&config.HTTPOptions{
+		Proxy:              "http://proxy.corp:3128",
+		InsecureSkipVerify: true,
+		TimeoutSeconds:     30,
+	})
+	if err != nil {
+		t.Fatalf("newTransport() error = %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com", http.NoBody) //nolint:errcheck,noctx // Test request
+	if proxy, err := transport.Proxy(req); err != nil || proxy.String() != "http://proxy.corp:3128" {
+		t.Errorf("proxy = %v, %v, want http://proxy.corp:3128", proxy, err)
+	}
+	if !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("insecure_skip_verify should turn off certificate checks")
+	}
+	if transport.ResponseHeaderTimeout != 30*time.Second {
+		t.Errorf("timeout = %v, want 30s", transport.ResponseHeaderTimeout)
+	}
+
+	if _, err := newTransport(&config.HTTPOptions{Proxy: "not a url"}); err == nil {
+		t.Error("an invalid proxy URL should fail")
+	}
+	if _, err := newTransport(&config.HTTPOptions{CACertFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("a missing CA bundle should fail")
+	}
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newTransport(&config.HTTPOptions{CACertFile: empty}); err == nil {
+		t.Error("a CA bundle without certificates should fail")
+	}
+}
+
+func TestHTTPClient_CACertFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"data": [{"id": "llama3"}]}`)) //nolint:errcheck,gosec // Test server
+	}))
+	defer server.Close()
+
+	// The test server's self-signed certificate is not trusted by default.
+	if _, _, err := ListModels(context.Background(), server.URL, "", nil); err == nil {
+		t.Fatal("a self-signed certificate should be refused without its CA")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := httpClient(&config.HTTPOptions{CACertFile: caFile}, nil)
+	if err != nil {
+		t.Fatalf("httpClient() error = %v", err)
+	}
+	models, _, err := listModels(context.Background(), client, server.URL, "", nil)
+	if err != nil {
+		t.Fatalf("listModels() with the CA bundle error = %v", err)
+	}
+	if len(models) != 1 || models[0] != "llama3" {
+		t.Errorf("models = %v, want [llama3]", models)
+	}
+}
+
+func TestApplyConnectionCredentials_HTTP(t *testing.T) {
+	providerCfg := &config.ProviderConfig{ID: "gateway", HTTP: &config.HTTPOptions{Proxy: "http://a:1"}}
+
+	got := applyConnectionCredentials(providerCfg, &config.Connection{ProviderID: "gateway"})
+	if got.HTTP == nil || got.HTTP.Proxy != "http://a:1" {
+		t.Errorf("HTTP = %+v, want the provider's settings", got.HTTP)
+	}
+
+	conn := &config.Connection{ProviderID: "gateway", HTTP: &config.HTTPOptions{InsecureSkipVerify: true}}
+	got = applyConnectionCredentials(providerCfg, conn)
+	if got.HTTP.Proxy != "" || !got.HTTP.InsecureSkipVerify {
+		t.Errorf("HTTP = %+v, want the connection's settings in place of the provider's", got.HTTP)
+	}
+}
@@ -16,12 +16,23 @@ import (
 
 	"charm.land/fantasy"
 	"charm.land/fantasy/providers/anthropic"
+	"charm.land/fantasy/providers/google"
 	"charm.land/fantasy/providers/openai"
+	azureopenai "github.com/openai/openai-go/v2/azure"
 
 	"github.com/guilhermegouw/cdd/internal/config"
 	"github.com/guilhermegouw/cdd/internal/debug"
 )
 
+// defaultAzureAPIVersion is used when an Azure provider does not set provider_options.api_version.
+const defaultAzureAPIVersion = "2024-10-21"
+
+// Vertex AI environment variables, checked when the provider options do not set project or location.
+var (
+	vertexProjectEnvVars  = []string{"VERTEXAI_PROJECT", "GOOGLE_CLOUD_PROJECT"}
+	vertexLocationEnvVars = []string{"VERTEXAI_LOCATION", "GOOGLE_CLOUD_LOCATION"}
+)
+
 // Model wraps a fantasy language model with its metadata.
 type Model struct {
 	// Model is the fantasy language model interface.
@@ -178,7 +189,7 @@ func (b *Builder) buildModel(ctx context.Context, modelCfg config.SelectedModel)
 	}
 
 	// Get language model from provider.
-	lm, err := provider.LanguageModel(ctx, modelCfg.Model)
+	lm, err := provider.LanguageModel(ctx, languageModelID(providerCfg, modelCfg.Model))
 	if err != nil {
 		return Model{}, fmt.Errorf("getting language model %q: %w", modelCfg.Model, err)
 	}
@@ -230,6 +241,23 @@ func applyConnectionCredentials(providerCfg *config.ProviderConfig, conn *config
 			providerCfgCopy.ExtraHeaders[k] = v
 		}
 	}
+	if len(conn.ExtraQuery) > 0 {
+		providerCfgCopy.ExtraQuery = maps.Clone(providerCfgCopy.ExtraQuery)
+		if providerCfgCopy.ExtraQuery == nil {
+			providerCfgCopy.ExtraQuery = make(map[string]string)
+		}
+		maps.Copy(providerCfgCopy.ExtraQuery, conn.ExtraQuery)
+	}
+	if conn.HTTP != nil {
+		providerCfgCopy.HTTP = conn.HTTP
+	}
+	if len(conn.ProviderOptions) > 0 {
+		providerCfgCopy.ProviderOptions = maps.Clone(providerCfgCopy.ProviderOptions)
+		if providerCfgCopy.ProviderOptions == nil {
+			providerCfgCopy.ProviderOptions = make(map[string]any)
+		}
+		maps.Copy(providerCfgCopy.ProviderOptions, conn.ProviderOptions)
+	}
 	return &providerCfgCopy
 }
 
@@ -267,20 +295,34 @@ func (b *Builder) buildProvider(providerCfg *config.ProviderConfig, modelCfg con
 	apiKey := providerCfg.APIKey
 	baseURL := providerCfg.BaseURL
 
-	//nolint:exhaustive // Only openai and anthropic are supported initially.
+	client, err := httpClient(providerCfg.HTTP, providerCfg.ExtraQuery)
+	if err != nil {
+		return nil, fmt.Errorf("configuring HTTP for provider %q: %w", providerCfg.ID, err)
+	}
+
+	//nolint:exhaustive // Remaining provider types are not supported yet.
 	switch providerCfg.Type {
 	case openai.Name, catwalk.TypeOpenAICompat:
-		return b.buildOpenAIProvider(baseURL, apiKey, headers)
+		return b.buildOpenAIProvider(baseURL, apiKey, headers, client)
 	case anthropic.Name:
-		return b.buildAnthropicProvider(baseURL, apiKey, headers)
+		return b.buildAnthropicProvider(baseURL, apiKey, headers, client)
+	case catwalk.TypeGoogle:
+		return b.buildGoogleProvider(baseURL, apiKey, headers, client)
+	case catwalk.TypeVertexAI:
+		return b.buildVertexProvider(providerCfg.ProviderOptions, baseURL, headers, client)
+	case catwalk.TypeAzure:
+		return b.buildAzureProvider(providerCfg.ProviderOptions, baseURL, apiKey, headers, client)
 	default:
 		return nil, fmt.Errorf("unsupported provider type: %q", providerCfg.Type)
 	}
 }
 
 // buildOpenAIProvider creates an OpenAI fantasy provider.
-func (b *Builder) buildOpenAIProvider(baseURL, apiKey string, headers map[string]string) (fantasy.Provider, error) {
+func (b *Builder) buildOpenAIProvider(baseURL, apiKey string, headers map[string]string, client *http.Client) (fantasy.Provider, error) {
 	var opts []openai.Option
+	if client != nil {
+		opts = append(opts, openai.WithHTTPClient(client))
+	}
 
 	if apiKey != "" {
 		opts = append(opts, openai.WithAPIKey(apiKey))
@@ -296,7 +338,7 @@ func (b *Builder) buildOpenAIProvider(baseURL, apiKey string, headers map[string
 }
 
 // buildAnthropicProvider creates an Anthropic fantasy provider.
-func (b *Builder) buildAnthropicProvider(baseURL, apiKey string, headers map[string]string) (fantasy.Provider, error) {
+func (b *Builder) buildAnthropicProvider(baseURL, apiKey string, headers map[string]string, client *http.Client) (fantasy.Provider, error) {
 	var opts []anthropic.Option
 	isOAuth := strings.HasPrefix(apiKey, "Bearer ")
 
@@ -309,15 +351,24 @@ func (b *Builder) buildAnthropicProvider(baseURL, apiKey string, headers map[str
 		headers["Authorization"] = apiKey
 
 		// Use custom HTTP client to strip x-stainless-* headers for OAuth
+		base := http.DefaultTransport
+		if client != nil {
+			base = client.Transport
+		}
 		httpClient := &http.Client{
 			Transport: &oauthTransport{
-				base:    http.DefaultTransport,
+				base:    base,
 				headers: headers,
 			},
 		}
 		opts = append(opts, anthropic.WithHTTPClient(httpClient))
-	} else if apiKey != "" {
-		opts = append(opts, anthropic.WithAPIKey(apiKey))
+	} else {
+		if apiKey != "" {
+			opts = append(opts, anthropic.WithAPIKey(apiKey))
+		}
+		if client != nil {
+			opts = append(opts, anthropic.WithHTTPClient(client))
+		}
 	}
 
 	// Only add headers via WithHeaders if not using OAuth (OAuth uses custom transport)
@@ -331,6 +382,144 @@ func (b *Builder) buildAnthropicProvider(baseURL, apiKey string, headers map[str
 	return anthropic.New(opts...)
 }
 
+// buildAzureProvider creates an Azure OpenAI fantasy provider.
+// Requests are sent to {endpoint}/openai/deployments/{deployment}/...?api-version={version},
+// where the deployment is resolved from the model ID by languageModelID.
+func (b *Builder) buildAzureProvider(providerOpts map[string]any, endpoint, apiKey string, headers map[string]string, client *http.Client) (fantasy.Provider, error) {
+	if endpoint == "" {
+		return nil, fmt.Errorf("azure provider requires an endpoint (base_url), e.g. https://your-resource.openai.azure.com")
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("azure provider requires an API key")
+	}
+
+	apiVersion, _ := providerOpts["api_version"].(string) //nolint:errcheck // Non-string values fall back to the default.
+	if apiVersion == "" {
+		apiVersion = defaultAzureAPIVersion
+	}
+
+	opts := []openai.Option{
+		openai.WithName(string(catwalk.TypeAzure)),
+		openai.WithSDKOptions(
+			azureopenai.WithEndpoint(azureEndpoint(endpoint), apiVersion),
+			azureopenai.WithAPIKey(apiKey),
+		),
+	}
+	if len(headers) > 0 {
+		opts = append(opts, openai.WithHeaders(headers))
+	}
+	if client != nil {
+		opts = append(opts, openai.WithHTTPClient(client))
+	}
+
+	return openai.New(opts...)
+}
+
+// azureEndpoint strips any path from an Azure endpoint so the deployment path can be appended.
+// Accepts values like "https://res.openai.azure.com/" or "https://res.openai.azure.com/openai/v1".
+func azureEndpoint(endpoint string) string {
+	endpoint = strings.TrimRight(endpoint, "/")
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	if idx := strings.Index(endpoint, "/openai"); idx >= 0 {
+		endpoint = endpoint[:idx]
+	}
+	return endpoint
+}
+
+// languageModelID returns the ID sent to the provider for a model.
+// Azure addresses models by deployment name, configured in provider_options.deployments
+// as a map of model ID to deployment; models without an entry use the model ID.
+func languageModelID(providerCfg *config.ProviderConfig, model string) string {
+	if providerCfg.Type != catwalk.TypeAzure {
+		return model
+	}
+	deployments, _ := providerCfg.ProviderOptions["deployments"].(map[string]any) //nolint:errcheck // Missing or invalid map means no overrides.
+	if deployment, ok := deployments[model].(string); ok && deployment != "" {
+		return deployment
+	}
+	return model
+}
+
+// buildGoogleProvider creates a Gemini API fantasy provider authenticated with an API key.
+func (b *Builder) buildGoogleProvider(baseURL, apiKey string, headers map[string]string, client *http.Client) (fantasy.Provider, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("google provider requires an API key")
+	}
+
+	opts := []google.Option{google.WithGeminiAPIKey(apiKey)}
+	if len(headers) > 0 {
+		opts = append(opts, google.WithHeaders(headers))
+	}
+	if client != nil {
+		opts = append(opts, google.WithHTTPClient(client))
+	}
+	if baseURL != "" {
+		opts = append(opts, google.WithBaseURL(baseURL))
+	}
+
+	return google.New(opts...)
+}
+
+// buildVertexProvider creates a Vertex AI fantasy provider.
+// Authentication uses Google Application Default Credentials
+// (e.g. "gcloud auth application-default login").
+func (b *Builder) buildVertexProvider(providerOpts map[string]any, baseURL string, headers map[string]string, client *http.Client) (fantasy.Provider, error) {
+	project := vertexSetting(providerOpts, "project", vertexProjectEnvVars)
+	location := vertexSetting(providerOpts, "location", vertexLocationEnvVars)
+	if project == "" || location == "" {
+		return nil, fmt.Errorf("vertex AI requires a project and location: set provider_options.project and provider_options.location, or %s and %s",
+			vertexProjectEnvVars[0], vertexLocationEnvVars[0])
+	}
+
+	opts := []google.Option{google.WithVertex(project, location)}
+	if len(headers) > 0 {
+		opts = append(opts, google.WithHeaders(headers))
+	}
+	if client != nil {
+		opts = append(opts, google.WithHTTPClient(client))
+	}
+	if baseURL != "" {
+		opts = append(opts, google.WithBaseURL(baseURL))
+	}
+
+	return google.New(opts...)
+}
+
+// vertexSetting returns a Vertex AI setting from provider options, falling back to environment variables.
+// Option values may reference environment variables (e.g. "$VERTEXAI_PROJECT").
+func vertexSetting(providerOpts map[string]any, key string, envVars []string) string {
+	if v, ok := providerOpts[key].(string); ok && v != "" {
+		if resolved, err := config.NewResolver().Resolve(v); err == nil && resolved != "" {
+			return resolved
+		}
+	}
+	for _, name := range envVars {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// queryTransport adds query parameters to every request, for gateways and
+// proxies that expect them.
+type queryTransport struct {
+	base  http.RoundTripper
+	query map[string]string
+}
+
+func (t *queryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqCopy := req.Clone(req.Context())
+	query := reqCopy.URL.Query()
+	for key, value := range t.query {
+		query.Set(key, value)
+	}
+	reqCopy.URL.RawQuery = query.Encode()
+	return t.base.RoundTrip(reqCopy)
+}
+
 // oauthTransport is a custom HTTP transport for OAuth that removes
 // x-api-key and x-stainless-* headers and adds OAuth headers.
 type oauthTransport struct {
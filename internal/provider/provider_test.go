@@ -3,8 +3,11 @@ package provider
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"charm.land/fantasy"
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
 
 	"github.com/guilhermegouw/cdd/internal/config"
@@ -260,6 +263,95 @@ func TestBuilder_buildProvider_AnthropicWithBearerToken(t *testing.T) {
 	}
 }
 
+func TestBuilder_buildProvider_Google(t *testing.T) {
+	builder := NewBuilder(config.NewConfig())
+	modelCfg := config.SelectedModel{Model: "gemini-2.5-pro", Provider: "gemini"}
+
+	provider, err := builder.buildProvider(&config.ProviderConfig{
+		ID:     "gemini",
+		Type:   catwalk.TypeGoogle,
+		APIKey: "test-key",
+	}, modelCfg)
+	if err != nil {
+		t.Fatalf("buildProvider() error = %v", err)
+	}
+	if provider == nil {
+		t.Error("buildProvider() returned nil provider")
+	}
+
+	_, err = builder.buildProvider(&config.ProviderConfig{
+		ID:   "gemini",
+		Type: catwalk.TypeGoogle,
+	}, modelCfg)
+	if err == nil {
+		t.Error("buildProvider() expected error without API key")
+	}
+}
+
+func TestBuilder_buildProvider_Vertex(t *testing.T) {
+	for _, name := range append(vertexProjectEnvVars, vertexLocationEnvVars...) {
+		t.Setenv(name, "")
+	}
+	builder := NewBuilder(config.NewConfig())
+	modelCfg := config.SelectedModel{Model: "gemini-2.5-pro", Provider: "vertexai"}
+
+	t.Run("from provider options", func(t *testing.T) {
+		t.Setenv("TEST_VERTEX_PROJECT", "my-project")
+		provider, err := builder.buildProvider(&config.ProviderConfig{
+			ID:   "vertexai",
+			Type: catwalk.TypeVertexAI,
+			ProviderOptions: map[string]any{
+				"project":  "$TEST_VERTEX_PROJECT",
+				"location": "us-central1",
+			},
+		}, modelCfg)
+		if err != nil {
+			t.Fatalf("buildProvider() error = %v", err)
+		}
+		if provider == nil {
+			t.Error("buildProvider() returned nil provider")
+		}
+	})
+
+	t.Run("from environment", func(t *testing.T) {
+		t.Setenv("VERTEXAI_PROJECT", "env-project")
+		t.Setenv("GOOGLE_CLOUD_LOCATION", "europe-west4")
+		if _, err := builder.buildProvider(&config.ProviderConfig{
+			ID:   "vertexai",
+			Type: catwalk.TypeVertexAI,
+		}, modelCfg); err != nil {
+			t.Fatalf("buildProvider() error = %v", err)
+		}
+	})
+
+	t.Run("missing project", func(t *testing.T) {
+		_, err := builder.buildProvider(&config.ProviderConfig{
+			ID:              "vertexai",
+			Type:            catwalk.TypeVertexAI,
+			ProviderOptions: map[string]any{"location": "us-central1"},
+		}, modelCfg)
+		if err == nil {
+			t.Error("buildProvider() expected error without project")
+		}
+	})
+}
+
+func TestVertexSetting(t *testing.T) {
+	t.Setenv("PRIMARY_VAR", "")
+	t.Setenv("FALLBACK_VAR", "from-env")
+	envVars := []string{"PRIMARY_VAR", "FALLBACK_VAR"}
+
+	if got := vertexSetting(map[string]any{"project": "explicit"}, "project", envVars); got != "explicit" {
+		t.Errorf("vertexSetting() = %q, want %q", got, "explicit")
+	}
+	if got := vertexSetting(nil, "project", envVars); got != "from-env" {
+		t.Errorf("vertexSetting() = %q, want %q", got, "from-env")
+	}
+	if got := vertexSetting(map[string]any{"project": 42}, "project", nil); got != "" {
+		t.Errorf("vertexSetting() = %q, want empty for non-string option", got)
+	}
+}
+
 func TestBuilder_buildProvider_UnsupportedType(t *testing.T) {
 	cfg := config.NewConfig()
 	builder := NewBuilder(cfg)
@@ -305,6 +397,165 @@ func TestBuilder_buildProvider_WithExtraHeaders(t *testing.T) {
 	}
 }
 
+func TestBuilder_BuildModels_Azure(t *testing.T) {
+	var gotPath, gotVersion, gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotVersion = r.URL.Query().Get("api-version")
+		gotKey = r.Header.Get("Api-Key")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","model":"gpt-4o",` + //nolint:errcheck // Test server response.
+			`"choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	cfg := config.NewConfig()
+	cfg.Models[config.SelectedModelTypeLarge] = config.SelectedModel{Model: "gpt-4o", Provider: "azure-openai"}
+	cfg.Providers["azure-openai"] = &config.ProviderConfig{
+		ID:      "azure-openai",
+		Type:    catwalk.TypeAzure,
+		APIKey:  "azure-key",
+		BaseURL: server.URL + "/",
+		ProviderOptions: map[string]any{
+			"api_version": "2024-02-01",
+			"deployments": map[string]any{"gpt-4o": "my-gpt4o"},
+		},
+	}
+
+	large, _, err := NewBuilder(cfg).BuildModels(context.Background())
+	if err != nil {
+		t.Fatalf("BuildModels() error = %v", err)
+	}
+	if _, err := large.Model.Generate(context.Background(), fantasy.Call{
+		Prompt: fantasy.Prompt{fantasy.NewUserMessage("hello")},
+	}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if gotPath != "/openai/deployments/my-gpt4o/chat/completions" {
+		t.Errorf("request path = %q", gotPath)
+	}
+	if gotVersion != "2024-02-01" {
+		t.Errorf("api-version = %q, want %q", gotVersion, "2024-02-01")
+	}
+	if gotKey != "azure-key" {
+		t.Errorf("api-key header = %q, want %q", gotKey, "azure-key")
+	}
+}
+
+func TestBuilder_buildAzureProvider_Validation(t *testing.T) {
+	builder := NewBuilder(config.NewConfig())
+
+	if _, err := builder.buildAzureProvider(nil, "", "key", nil, nil); err == nil {
+		t.Error("expected error without endpoint")
+	}
+	if _, err := builder.buildAzureProvider(nil, "https://res.openai.azure.com", "", nil, nil); err == nil {
+		t.Error("expected error without API key")
+	}
+	if _, err := builder.buildAzureProvider(nil, "https://res.openai.azure.com", "key", nil, nil); err != nil {
+		t.Errorf("unexpected error with default API version: %v", err)
+	}
+}
+
+func TestAzureEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"https://res.openai.azure.com":           "https://res.openai.azure.com",
+		"https://res.openai.azure.com/":          "https://res.openai.azure.com",
+		"https://res.openai.azure.com/openai/v1": "https://res.openai.azure.com",
+		"res.openai.azure.com":                   "https://res.openai.azure.com",
+	}
+	for in, want := range tests {
+		if got := azureEndpoint(in); got != want {
+			t.Errorf("azureEndpoint(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLanguageModelID(t *testing.T) {
+	azure := &config.ProviderConfig{
+		Type:            catwalk.TypeAzure,
+		ProviderOptions: map[string]any{"deployments": map[string]any{"gpt-4o": "prod-4o"}},
+	}
+	if got := languageModelID(azure, "gpt-4o"); got != "prod-4o" {
+		t.Errorf("languageModelID() = %q, want deployment name", got)
+	}
+	if got := languageModelID(azure, "gpt-4o-mini"); got != "gpt-4o-mini" {
+		t.Errorf("languageModelID() = %q, want model ID without mapping", got)
+	}
+	openAI := &config.ProviderConfig{Type: catwalk.TypeOpenAI, ProviderOptions: azure.ProviderOptions}
+	if got := languageModelID(openAI, "gpt-4o"); got != "gpt-4o" {
+		t.Errorf("languageModelID() = %q, deployments should only apply to Azure", got)
+	}
+}
+
+func TestApplyConnectionCredentials_ProviderOptions(t *testing.T) {
+	providerCfg := &config.ProviderConfig{
+		ID:              "azure-openai",
+		ProviderOptions: map[string]any{"api_version": "2024-02-01", "deployments": map[string]any{}},
+	}
+	conn := &config.Connection{ProviderOptions: map[string]any{"api_version": "2025-01-01"}}
+
+	got := applyConnectionCredentials(providerCfg, conn)
+	if got.ProviderOptions["api_version"] != "2025-01-01" {
+		t.Errorf("connection option should override, got %v", got.ProviderOptions["api_version"])
+	}
+	if _, ok := got.ProviderOptions["deployments"]; !ok {
+		t.Error("provider options without overrides should be kept")
+	}
+	if providerCfg.ProviderOptions["api_version"] != "2024-02-01" {
+		t.Error("original provider options should not be mutated")
+	}
+}
+
+func TestBuilder_BuildModels_ConnectionExtraQuery(t *testing.T) {
+	var gotTenant, gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTenant = r.URL.Query().Get("tenant")
+		gotHeader = r.Header.Get("X-Gateway")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","model":"gpt-4o",` + //nolint:errcheck // Test server response.
+			`"choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	cfg := config.NewConfig()
+	cfg.Providers["gateway"] = &config.ProviderConfig{
+		ID:         "gateway",
+		Type:       catwalk.TypeOpenAICompat,
+		BaseURL:    server.URL,
+		ExtraQuery: map[string]string{"tenant": "default"},
+	}
+	cfg.Connections = []config.Connection{{
+		ID:           "conn-1",
+		Name:         "Team gateway",
+		ProviderID:   "gateway",
+		APIKey:       "key",
+		ExtraHeaders: map[string]string{"X-Gateway": "team"},
+		ExtraQuery:   map[string]string{"tenant": "team"},
+	}}
+	cfg.Models[config.SelectedModelTypeLarge] = config.SelectedModel{Model: "gpt-4o", ConnectionID: "conn-1"}
+
+	large, _, err := NewBuilder(cfg).BuildModels(context.Background())
+	if err != nil {
+		t.Fatalf("BuildModels() error = %v", err)
+	}
+	if _, err := large.Model.Generate(context.Background(), fantasy.Call{
+		Prompt: fantasy.Prompt{fantasy.NewUserMessage("hello")},
+	}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if gotTenant != "team" {
+		t.Errorf("tenant query param = %q, want %q", gotTenant, "team")
+	}
+	if gotHeader != "team" {
+		t.Errorf("X-Gateway header = %q, want %q", gotHeader, "team")
+	}
+	if cfg.Providers["gateway"].ExtraQuery["tenant"] != "default" {
+		t.Error("provider query params should not be mutated")
+	}
+}
+
 func TestBuilder_getOrBuildProvider_Caching(t *testing.T) {
 	cfg := config.NewConfig()
 	builder := NewBuilder(cfg)
@@ -342,7 +593,7 @@ func TestBuilder_buildOpenAIProvider_MinimalConfig(t *testing.T) {
 	builder := NewBuilder(cfg)
 
 	// Test with minimal config (no API key, no base URL, no headers).
-	provider, err := builder.buildOpenAIProvider("", "", nil)
+	provider, err := builder.buildOpenAIProvider("", "", nil, nil)
 	if err != nil {
 		t.Fatalf("buildOpenAIProvider() error = %v", err)
 	}
@@ -356,7 +607,7 @@ func TestBuilder_buildAnthropicProvider_MinimalConfig(t *testing.T) {
 	builder := NewBuilder(cfg)
 
 	// Test with minimal config.
-	provider, err := builder.buildAnthropicProvider("", "", nil)
+	provider, err := builder.buildAnthropicProvider("", "", nil, nil)
 	if err != nil {
 		t.Fatalf("buildAnthropicProvider() error = %v", err)
 	}
@@ -369,7 +620,7 @@ func TestBuilder_buildAnthropicProvider_WithBaseURL(t *testing.T) {
 	cfg := config.NewConfig()
 	builder := NewBuilder(cfg)
 
-	provider, err := builder.buildAnthropicProvider("https://custom.api.com", "sk-ant-test", nil)
+	provider, err := builder.buildAnthropicProvider("https://custom.api.com", "sk-ant-test", nil, nil)
 	if err != nil {
 		t.Fatalf("buildAnthropicProvider() error = %v", err)
 	}
@@ -385,7 +636,7 @@ func TestBuilder_buildOpenAIProvider_WithAllOptions(t *testing.T) {
 	headers := map[string]string{
 		"X-Custom": "value",
 	}
-	provider, err := builder.buildOpenAIProvider("https://api.openai.com/v1", "sk-test", headers)
+	provider, err := builder.buildOpenAIProvider("https://api.openai.com/v1", "sk-test", headers, nil)
 	if err != nil {
 		t.Fatalf("buildOpenAIProvider() error = %v", err)
 	}
@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/oauth"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+const (
+	// refreshLead is how long before expiry a token is refreshed.
+	refreshLead = 5 * time.Minute
+	// minRefreshCheck keeps a failing refresh from being retried in a loop.
+	minRefreshCheck = time.Minute
+	// idleRefreshCheck is how often config is checked when it has no OAuth
+	// tokens, in case the user signs in.
+	idleRefreshCheck = time.Hour
+)
+
+// Refresher refreshes OAuth tokens shortly before they expire, so that a long
+// session never sends a request with a stale token. When a token nears expiry
+// it publishes AuthEventTokenExpiring and, on receiving that event, refreshes
+// the token and publishes AuthEventTokenRefreshed or AuthEventRefreshFailed.
+// Subscribers rebuild their models on AuthEventTokenRefreshed.
+type Refresher struct {
+	broker *pubsub.Broker[events.AuthEvent]
+	load   func() (*config.Config, error)
+	lead   time.Duration
+}
+
+// NewRefresher creates a refresher that reads tokens with load, which should
+// return the config as currently saved so that rotated tokens are seen.
+func NewRefresher(broker *pubsub.Broker[events.AuthEvent], load func() (*config.Config, error)) *Refresher {
+	return &Refresher{broker: broker, load: load, lead: refreshLead}
+}
+
+// Run watches the tokens until ctx is done.
+func (r *Refresher) Run(ctx context.Context) {
+	expiring := r.broker.Subscribe(ctx)
+	timer := time.NewTimer(r.untilNext())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			r.announce()
+			timer.Reset(r.untilNext())
+		case event, ok := <-expiring:
+			if !ok {
+				return
+			}
+			if event.Payload.Type == events.AuthEventTokenExpiring {
+				r.refresh(ctx, event.Payload.ProviderID)
+				timer.Reset(r.untilNext())
+			}
+		}
+	}
+}
+
+// tokenOwner is a provider or connection signed in with OAuth.
+type tokenOwner struct {
+	id      string
+	token   *oauth.Token
+	refresh func(ctx context.Context) error
+}
+
+// expiresAt returns when the owner's token expires.
+func (o tokenOwner) expiresAt() time.Time {
+	return time.Unix(o.token.ExpiresAt, 0)
+}
+
+// tokenOwners lists the providers and connections of cfg that hold OAuth
+// tokens. Connections are identified by ID, or by name when they have none.
+func tokenOwners(cfg *config.Config) []tokenOwner {
+	var owners []tokenOwner
+	for id, p := range cfg.Providers {
+		if p.OAuthToken != nil {
+			owners = append(owners, tokenOwner{
+				id:      id,
+				token:   p.OAuthToken,
+				refresh: func(ctx context.Context) error { return cfg.RefreshOAuthToken(ctx, id) },
+			})
+		}
+	}
+	for i := range cfg.Connections {
+		conn := &cfg.Connections[i]
+		if conn.OAuthToken == nil {
+			continue
+		}
+		id := conn.ID
+		if id == "" {
+			id = conn.Name
+		}
+		owners = append(owners, tokenOwner{
+			id:      id,
+			token:   conn.OAuthToken,
+			refresh: func(ctx context.Context) error { return cfg.RefreshConnectionOAuthToken(ctx, i) },
+		})
+	}
+	return owners
+}
+
+// untilNext returns how long to wait before the next token needs refreshing.
+func (r *Refresher) untilNext() time.Duration {
+	cfg, err := r.load()
+	if err != nil {
+		debug.Token("refresher_load_failed", err.Error())
+		return idleRefreshCheck
+	}
+	wait := idleRefreshCheck
+	for _, owner := range tokenOwners(cfg) {
+		wait = min(wait, time.Until(owner.expiresAt())-r.lead)
+	}
+	return max(wait, minRefreshCheck)
+}
+
+// announce publishes AuthEventTokenExpiring for every token that is due.
+func (r *Refresher) announce() {
+	cfg, err := r.load()
+	if err != nil {
+		debug.Token("refresher_load_failed", err.Error())
+		return
+	}
+	for _, owner := range tokenOwners(cfg) {
+		if time.Until(owner.expiresAt()) <= r.lead {
+			r.broker.Publish(pubsub.EventUpdated, events.NewTokenExpiringEvent(owner.id, owner.expiresAt()))
+		}
+	}
+}
+
+// refresh refreshes the token of the provider or connection with the given
+// ID, unless it was refreshed elsewhere since it was announced.
+func (r *Refresher) refresh(ctx context.Context, id string) {
+	cfg, err := r.load()
+	if err != nil {
+		r.broker.Publish(pubsub.EventFailed, events.NewRefreshFailedEvent(id, err))
+		return
+	}
+	for _, owner := range tokenOwners(cfg) {
+		if owner.id != id || time.Until(owner.expiresAt()) > r.lead {
+			continue
+		}
+		debug.Token("proactive_refresh", fmt.Sprintf("id=%s expiry=%s", id, owner.expiresAt().Format(time.RFC3339)))
+		if err := owner.refresh(ctx); err != nil {
+			r.broker.Publish(pubsub.EventFailed, events.NewRefreshFailedEvent(id, err))
+			return
+		}
+		// The refresh replaced the token, so look it up again for its expiry.
+		for _, refreshed := range tokenOwners(cfg) {
+			if refreshed.id == id {
+				r.broker.Publish(pubsub.EventUpdated, events.NewTokenRefreshedEvent(id, refreshed.expiresAt()))
+			}
+		}
+		return
+	}
+}
@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/oauth"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+// newTokenConfig returns a config with an OAuth provider and connection whose
+// tokens expire after the given durations.
+func newTokenConfig(providerExpiry, connectionExpiry time.Duration) *config.Config {
+	cfg := config.NewConfig()
+	cfg.Providers["anthropic"] = &config.ProviderConfig{
+		ID:         "anthropic",
+		OAuthToken: &oauth.Token{RefreshToken: "r1", ExpiresAt: time.Now().Add(providerExpiry).Unix()},
+	}
+	cfg.Connections = []config.Connection{
+		{ID: "work", ProviderID: "anthropic", OAuthToken: &oauth.Token{RefreshToken: "r2", ExpiresAt: time.Now().Add(connectionExpiry).Unix()}},
+	}
+	return cfg
+}
+
+func newTestRefresher(t *testing.T, cfg *config.Config) (*Refresher, <-chan pubsub.Event[events.AuthEvent]) {
+	t.Helper()
+	broker := pubsub.NewBroker[events.AuthEvent]("auth")
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return NewRefresher(broker, func() (*config.Config, error) { return cfg, nil }), broker.Subscribe(ctx)
+}
+
+func TestRefresher_UntilNext(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		min, max time.Duration
+	}{
+		{name: "earliest token", cfg: newTokenConfig(2*time.Hour, 30*time.Minute), min: 24 * time.Minute, max: 25 * time.Minute},
+		{name: "token already due", cfg: newTokenConfig(time.Minute, 2*time.Hour), min: minRefreshCheck, max: minRefreshCheck},
+		{name: "no tokens", cfg: config.NewConfig(), min: idleRefreshCheck, max: idleRefreshCheck},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestRefresher(t, tt.cfg)
+			if got := r.untilNext(); got < tt.min || got > tt.max {
+				t.Errorf("untilNext() = %s, want between %s and %s", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestRefresher_Announce(t *testing.T) {
+	r, sub := newTestRefresher(t, newTokenConfig(2*time.Hour, 3*time.Minute))
+
+	r.announce()
+
+	select {
+	case event := <-sub:
+		if event.Payload.Type != events.AuthEventTokenExpiring || event.Payload.ProviderID != "work" {
+			t.Errorf("announced %+v, want the work connection expiring", event.Payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no token was announced")
+	}
+	select {
+	case event := <-sub:
+		t.Errorf("unexpected second event %+v", event.Payload)
+	default:
+	}
+}
+
+func TestRefresher_RefreshSkipsFreshTokens(t *testing.T) {
+	r, sub := newTestRefresher(t, newTokenConfig(2*time.Hour, 2*time.Hour))
+
+	// Already refreshed elsewhere since the event was published.
+	r.refresh(context.Background(), "anthropic")
+
+	select {
+	case event := <-sub:
+		t.Errorf("unexpected event %+v", event.Payload)
+	default:
+	}
+}
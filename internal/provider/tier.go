@@ -2,6 +2,8 @@ package provider
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/guilhermegouw/cdd/internal/config"
 )
@@ -50,3 +52,63 @@ func AllTiers() []config.SelectedModelType {
 		config.SelectedModelTypeSmall,
 	}
 }
+
+// OverrideModel points a tier at the model described by spec.
+// The spec is either "provider/model", a bare model ID, which is looked up
+// in the tier's current provider first and then in every configured provider,
+// or an alias from options.model_aliases standing for one of those.
+func OverrideModel(cfg *config.Config, tier config.SelectedModelType, spec string) error {
+	spec = strings.TrimSpace(cfg.ResolveModelAlias(spec))
+	if spec == "" {
+		return fmt.Errorf("model spec is empty")
+	}
+
+	current := cfg.Models[tier]
+
+	providerID, modelID := "", spec
+	if idx := strings.Index(spec, "/"); idx > 0 {
+		if _, ok := cfg.Providers[spec[:idx]]; ok {
+			providerID, modelID = spec[:idx], spec[idx+1:]
+		}
+	}
+
+	if providerID == "" {
+		providerID = findProviderForModel(cfg, current.Provider, modelID)
+		if providerID == "" {
+			return fmt.Errorf("model %q not found in any configured provider", spec)
+		}
+	}
+
+	if _, ok := cfg.Providers[providerID]; !ok {
+		return fmt.Errorf("provider %q not configured", providerID)
+	}
+
+	if current.Provider != providerID {
+		// Connection belongs to the old provider; let the builder pick credentials again.
+		current.ConnectionID = ""
+	}
+	current.Provider = providerID
+	current.Model = modelID
+	cfg.Models[tier] = current
+	return nil
+}
+
+// findProviderForModel returns the ID of a provider offering modelID, preferring preferred.
+func findProviderForModel(cfg *config.Config, preferred, modelID string) string {
+	if preferred != "" && cfg.GetModel(preferred, modelID) != nil {
+		return preferred
+	}
+
+	ids := make([]string, 0, len(cfg.Providers))
+	for id := range cfg.Providers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if cfg.GetModel(id, modelID) != nil {
+			return id
+		}
+	}
+	return ""
+}
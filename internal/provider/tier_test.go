@@ -3,6 +3,8 @@ package provider
 import (
 	"testing"
 
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+
 	"github.com/guilhermegouw/cdd/internal/config"
 )
 
@@ -239,3 +241,104 @@ func TestAllTiers(t *testing.T) {
 		t.Error("AllTiers() missing small tier")
 	}
 }
+
+func TestOverrideModel(t *testing.T) {
+	newCfg := func() *config.Config {
+		cfg := config.NewConfig()
+		cfg.Providers["openai"] = &config.ProviderConfig{
+			ID:     "openai",
+			Models: []catwalk.Model{{ID: "gpt-4o"}, {ID: "gpt-4o-mini"}},
+		}
+		cfg.Providers["openrouter"] = &config.ProviderConfig{
+			ID:     "openrouter",
+			Models: []catwalk.Model{{ID: "anthropic/claude-sonnet"}},
+		}
+		cfg.Models[config.SelectedModelTypeLarge] = config.SelectedModel{
+			Model:        "gpt-4o",
+			Provider:     "openai",
+			ConnectionID: "conn-1",
+		}
+		cfg.Options = &config.Options{ModelAliases: map[string]string{
+			"smart": "openrouter/anthropic/claude-sonnet",
+			"typo":  "does-not-exist",
+		}}
+		return cfg
+	}
+
+	tests := []struct {
+		name         string
+		spec         string
+		wantProvider string
+		wantModel    string
+		wantConnID   string
+		wantErr      bool
+	}{
+		{
+			name:         "bare model in current provider",
+			spec:         "gpt-4o-mini",
+			wantProvider: "openai",
+			wantModel:    "gpt-4o-mini",
+			wantConnID:   "conn-1",
+		},
+		{
+			name:         "provider prefix",
+			spec:         "openai/gpt-4o-mini",
+			wantProvider: "openai",
+			wantModel:    "gpt-4o-mini",
+			wantConnID:   "conn-1",
+		},
+		{
+			name:         "model ID containing slash",
+			spec:         "anthropic/claude-sonnet",
+			wantProvider: "openrouter",
+			wantModel:    "anthropic/claude-sonnet",
+		},
+		{
+			name:         "provider prefix with slash in model",
+			spec:         "openrouter/anthropic/claude-sonnet",
+			wantProvider: "openrouter",
+			wantModel:    "anthropic/claude-sonnet",
+		},
+		{
+			name:         "alias",
+			spec:         "smart",
+			wantProvider: "openrouter",
+			wantModel:    "anthropic/claude-sonnet",
+		},
+		{
+			name:    "alias to unknown model",
+			spec:    "typo",
+			wantErr: true,
+		},
+		{
+			name:    "unknown model",
+			spec:    "does-not-exist",
+			wantErr: true,
+		},
+		{
+			name:    "empty spec",
+			spec:    "  ",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newCfg()
+			err := OverrideModel(cfg, config.SelectedModelTypeLarge, tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OverrideModel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := cfg.Models[config.SelectedModelTypeLarge]
+			if got.Provider != tt.wantProvider || got.Model != tt.wantModel {
+				t.Errorf("got %s/%s, want %s/%s", got.Provider, got.Model, tt.wantProvider, tt.wantModel)
+			}
+			if got.ConnectionID != tt.wantConnID {
+				t.Errorf("ConnectionID = %q, want %q", got.ConnectionID, tt.wantConnID)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+// reloadDelay lets the writes of one save, such as a truncate then a write,
+// or a temporary file renamed into place, settle into a single reload.
+const reloadDelay = 200 * time.Millisecond
+
+// Watcher reloads the configuration when cdd.json or custom-providers.json
+// changes on disk, as when edited from another terminal or rewritten by a
+// config management tool, and publishes ConfigEventReloaded with the new
+// config, or ConfigEventReloadFailed when it no longer loads.
+type Watcher struct {
+	broker *pubsub.Broker[events.ConfigEvent]
+	load   func() (*config.Config, error)
+	paths  []string
+	delay  time.Duration
+}
+
+// NewWatcher creates a watcher for the given files; empty paths are ignored.
+// Their directories are watched rather than the files, so that files replaced
+// by a rename, or created after cdd started, are seen too.
+func NewWatcher(broker *pubsub.Broker[events.ConfigEvent], load func() (*config.Config, error), paths ...string) *Watcher {
+	cleaned := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if path != "" {
+			cleaned = append(cleaned, filepath.Clean(path))
+		}
+	}
+	return &Watcher{broker: broker, load: load, paths: cleaned, delay: reloadDelay}
+}
+
+// Run watches the files until ctx is done.
+func (w *Watcher) Run(ctx context.Context) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		debug.Log("Config watcher unavailable: %v", err)
+		return
+	}
+	defer fsw.Close() //nolint:errcheck // Nothing to do if closing fails
+
+	contents := make(map[string][]byte, len(w.paths))
+	for _, path := range w.paths {
+		contents[path] = readIfExists(path)
+		if err := fsw.Add(filepath.Dir(path)); err != nil {
+			debug.Log("Not watching %s: %v", path, err)
+		}
+	}
+
+	timer := time.NewTimer(w.delay)
+	timer.Stop()
+	defer timer.Stop()
+	var changed string
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return
+			}
+			if _, watched := contents[event.Name]; watched && !event.Has(fsnotify.Chmod) {
+				changed = event.Name
+				timer.Reset(w.delay)
+			}
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return
+			}
+			debug.Log("Config watcher: %v", err)
+		case <-timer.C:
+			if w.modified(contents) {
+				w.reload(changed)
+			}
+		}
+	}
+}
+
+// modified reports whether any watched file now reads differently, and
+// records what it reads. Saves that leave a file as it was cause no reload,
+// nor do cdd's own saves, whose config is already in use.
+func (w *Watcher) modified(contents map[string][]byte) bool {
+	modified := false
+	for path, old := range contents {
+		data := readIfExists(path)
+		if !bytes.Equal(data, old) {
+			contents[path] = data
+			modified = modified || !config.IsOwnWrite(path, data)
+		}
+	}
+	return modified
+}
+
+// reload loads the configuration and publishes the outcome.
+func (w *Watcher) reload(path string) {
+	cfg, err := w.load()
+	if err != nil {
+		debug.Log("Reloading config after %s changed: %v", path, err)
+		w.broker.Publish(pubsub.EventFailed, events.NewConfigReloadFailedEvent(path, err))
+		return
+	}
+	debug.Log("Reloaded config after %s changed", path)
+	w.broker.Publish(pubsub.EventUpdated, events.NewConfigReloadedEvent(path, cfg))
+}
+
+// readIfExists returns the content of path, or nil when it cannot be read.
+func readIfExists(path string) []byte {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: Paths are cdd's own config files
+	if err != nil {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+// startTestWatcher watches path with a load that fails while failing is set.
+func startTestWatcher(t *testing.T, path string, failing *atomic.Bool) <-chan pubsub.Event[events.ConfigEvent] {
+	t.Helper()
+	broker := pubsub.NewBroker[events.ConfigEvent]("config")
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	sub := broker.Subscribe(ctx)
+
+	w := NewWatcher(broker, func() (*config.Config, error) {
+		if failing.Load() {
+			return nil, errors.New("invalid character")
+		}
+		return config.NewConfig(), nil
+	}, path, "")
+	w.delay = 20 * time.Millisecond
+	go w.Run(ctx)
+	time.Sleep(50 * time.Millisecond) // Let the watch start
+	return sub
+}
+
+func nextConfigEvent(t *testing.T, sub <-chan pubsub.Event[events.ConfigEvent]) (events.ConfigEvent, bool) {
+	t.Helper()
+	select {
+	case event := <-sub:
+		return event.Payload, true
+	case <-time.After(500 * time.Millisecond):
+		return events.ConfigEvent{}, false
+	}
+}
+
+func TestWatcher_ReloadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cdd.json")
+	if err := os.WriteFile(path, []byte(`{}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var failing atomic.Bool
+	sub := startTestWatcher(t, path, &failing)
+
+	if err := os.WriteFile(path, []byte(`{"models":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	event, ok := nextConfigEvent(t, sub)
+	if !ok || event.Type != events.ConfigEventReloaded || event.Path != path || event.Config == nil {
+		t.Fatalf("event = %+v (received %v), want a reload of %s", event, ok, path)
+	}
+
+	// Rewriting the same content is not a change.
+	if err := os.WriteFile(path, []byte(`{"models":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if event, ok := nextConfigEvent(t, sub); ok {
+		t.Errorf("unexpected event %+v for an unchanged file", event)
+	}
+
+	failing.Store(true)
+	if err := os.WriteFile(path, []byte(`{`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	event, ok = nextConfigEvent(t, sub)
+	if !ok || event.Type != events.ConfigEventReloadFailed || event.Error == nil {
+		t.Errorf("event = %+v (received %v), want a failed reload", event, ok)
+	}
+}
+
+func TestWatcher_SeesNewFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "custom-providers.json")
+	var failing atomic.Bool
+	sub := startTestWatcher(t, path, &failing)
+
+	// Saved the way editors do: written aside, then renamed into place.
+	tmp := filepath.Join(dir, "custom-providers.json.tmp")
+	if err := os.WriteFile(tmp, []byte(`{"providers":[]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	if event, ok := nextConfigEvent(t, sub); !ok || event.Type != events.ConfigEventReloaded {
+		t.Errorf("event = %+v (received %v), want a reload", event, ok)
+	}
+}
+
+func TestWatcher_IgnoresOwnWrites(t *testing.T) {
+	dir := t.TempDir()
+	manager := config.NewCustomProviderManager(dir)
+	path := manager.GetFilePath()
+	var failing atomic.Bool
+	sub := startTestWatcher(t, path, &failing)
+
+	if err := manager.Save([]config.CustomProvider{}); err != nil {
+		t.Fatal(err)
+	}
+	if event, ok := nextConfigEvent(t, sub); ok {
+		t.Errorf("unexpected event %+v for cdd's own save", event)
+	}
+
+	// An edit from elsewhere after it still reloads.
+	if err := os.WriteFile(path, []byte(`{"providers":[]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if event, ok := nextConfigEvent(t, sub); !ok || event.Type != events.ConfigEventReloaded {
+		t.Errorf("event = %+v (received %v), want a reload", event, ok)
+	}
+}
@@ -10,36 +10,146 @@ import (
 // DefaultBufferSize is the default channel buffer for subscribers.
 const DefaultBufferSize = 64
 
+// DefaultBlockTimeout is how long a publish waits on a full subscriber under
+// the Block policy before dropping the event for it.
+const DefaultBlockTimeout = time.Second
+
+// OverflowPolicy decides what happens to an event published while a
+// subscriber's buffer is full.
+type OverflowPolicy int
+
+// Overflow policies.
+const (
+	// DropNewest drops the event being published (the default).
+	DropNewest OverflowPolicy = iota
+	// DropOldest drops the oldest buffered event to make room, for
+	// subscribers that only care about the latest state.
+	DropOldest
+	// Block waits for room, up to the block timeout, then drops the event.
+	Block
+)
+
+// String returns the policy's name.
+func (p OverflowPolicy) String() string {
+	switch p {
+	case DropNewest:
+		return "drop-newest"
+	case DropOldest:
+		return "drop-oldest"
+	case Block:
+		return "block"
+	}
+	return "unknown"
+}
+
 // BrokerOption configures a Broker.
 type BrokerOption[T any] func(*Broker[T])
 
 // WithBufferSize sets the subscriber channel buffer size.
 func WithBufferSize[T any](size int) BrokerOption[T] {
 	return func(b *Broker[T]) {
-		b.bufferSize = size
+		b.defaults.bufferSize = size
 	}
 }
 
 // WithDropPolicy sets whether to drop events when subscriber is full.
+// It is shorthand for DropNewest (true) or Block (false).
 func WithDropPolicy[T any](drop bool) BrokerOption[T] {
 	return func(b *Broker[T]) {
-		b.dropOnFull = drop
+		b.defaults.policy = DropNewest
+		if !drop {
+			b.defaults.policy = Block
+		}
+	}
+}
+
+// WithOverflowPolicy sets the overflow policy of subscribers that do not
+// choose their own.
+func WithOverflowPolicy[T any](policy OverflowPolicy) BrokerOption[T] {
+	return func(b *Broker[T]) {
+		b.defaults.policy = policy
+	}
+}
+
+// WithBlockTimeout sets how long the Block policy waits for room. Zero waits
+// until the subscriber takes the event or goes away.
+func WithBlockTimeout[T any](timeout time.Duration) BrokerOption[T] {
+	return func(b *Broker[T]) {
+		b.defaults.blockTimeout = timeout
 	}
 }
 
+// WithReplay keeps the last size events of each key for Replay.
+func WithReplay[T any](size int, key func(T) string) BrokerOption[T] {
+	return func(b *Broker[T]) {
+		b.replay = NewReplayBuffer(size, key)
+	}
+}
+
+// SubscribeOption configures a single subscription, overriding the broker's
+// defaults.
+type SubscribeOption func(*subscribeOptions)
+
+// subscribeOptions are the settings of a subscription.
+type subscribeOptions struct {
+	name         string
+	bufferSize   int
+	policy       OverflowPolicy
+	blockTimeout time.Duration
+}
+
+// WithSubscriberName names the subscription in metrics.
+func WithSubscriberName(name string) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.name = name
+	}
+}
+
+// WithSubscriberBuffer sets the subscription's channel buffer size.
+func WithSubscriberBuffer(size int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.bufferSize = size
+	}
+}
+
+// WithSubscriberPolicy sets what happens when the subscription's buffer is
+// full, and for Block how long to wait for room.
+func WithSubscriberPolicy(policy OverflowPolicy, blockTimeout time.Duration) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.policy = policy
+		o.blockTimeout = blockTimeout
+	}
+}
+
+// subscription is a subscriber's channel and its delivery state.
+type subscription[T any] struct {
+	subscribeOptions
+	ch   chan Event[T]
+	done chan struct{} // Closed when the subscription ends
+
+	// mu is held while sending, so that ch is never closed mid-send.
+	mu      sync.Mutex
+	missed  int // Events dropped since the last delivered one
+	removed bool
+
+	delivered atomic.Int64
+	dropped   atomic.Int64
+}
+
 // Broker is a type-safe pub/sub broker using Go generics.
 // It is thread-safe and supports context-based subscription lifecycle.
 type Broker[T any] struct { //nolint:govet // fieldalignment: preserving logical field order
-	name       string
-	subs       map[chan Event[T]]struct{}
-	mu         sync.RWMutex
-	done       chan struct{}
-	bufferSize int
-	dropOnFull bool
+	name     string
+	subs     map[*subscription[T]]struct{}
+	mu       sync.RWMutex
+	done     chan struct{}
+	defaults subscribeOptions
+	replay   *ReplayBuffer[T] // Nil unless WithReplay was given
 
 	// Metrics (atomic for lock-free reads)
 	publishCount   atomic.Int64
 	dropCount      atomic.Int64
+	panicCount     atomic.Int64
 	subscriberPeak atomic.Int32
 	subscriberCurr atomic.Int32
 }
@@ -47,11 +157,14 @@ type Broker[T any] struct { //nolint:govet // fieldalignment: preserving logical
 // NewBroker creates a new typed broker with optional configuration.
 func NewBroker[T any](name string, opts ...BrokerOption[T]) *Broker[T] {
 	b := &Broker[T]{
-		name:       name,
-		subs:       make(map[chan Event[T]]struct{}),
-		done:       make(chan struct{}),
-		bufferSize: DefaultBufferSize,
-		dropOnFull: true, // Default: non-blocking
+		name: name,
+		subs: make(map[*subscription[T]]struct{}),
+		done: make(chan struct{}),
+		defaults: subscribeOptions{
+			bufferSize:   DefaultBufferSize,
+			policy:       DropNewest, // Default: non-blocking
+			blockTimeout: DefaultBlockTimeout,
+		},
 	}
 
 	for _, opt := range opts {
@@ -68,7 +181,7 @@ func (b *Broker[T]) Name() string {
 
 // Subscribe creates a new subscription that receives events until context is cancelled.
 // The returned channel is closed when the context is done or the broker shuts down.
-func (b *Broker[T]) Subscribe(ctx context.Context) <-chan Event[T] {
+func (b *Broker[T]) Subscribe(ctx context.Context, opts ...SubscribeOption) <-chan Event[T] {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -81,7 +194,11 @@ func (b *Broker[T]) Subscribe(ctx context.Context) <-chan Event[T] {
 	default:
 	}
 
-	sub := make(chan Event[T], b.bufferSize)
+	sub := &subscription[T]{subscribeOptions: b.defaults, done: make(chan struct{})}
+	for _, opt := range opts {
+		opt(&sub.subscribeOptions)
+	}
+	sub.ch = make(chan Event[T], sub.bufferSize)
 	b.subs[sub] = struct{}{}
 
 	// Update subscriber metrics
@@ -109,16 +226,25 @@ func (b *Broker[T]) Subscribe(ctx context.Context) <-chan Event[T] {
 		}
 
 		delete(b.subs, sub)
-		close(sub)
+		sub.close()
 		b.subscriberCurr.Add(-1)
 	}()
 
-	return sub
+	return sub.ch
+}
+
+// close ends the subscription. A publish blocked on it gives up first.
+func (s *subscription[T]) close() {
+	close(s.done)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removed = true
+	close(s.ch)
 }
 
-// Publish sends an event to all subscribers.
-// If dropOnFull is true (default), events are dropped for slow subscribers.
-// If dropOnFull is false, this blocks until all subscribers receive the event.
+// Publish sends an event to all subscribers. What happens when a subscriber's
+// buffer is full depends on its overflow policy; only Block makes Publish
+// wait, and then no longer than the block timeout.
 func (b *Broker[T]) Publish(eventType EventType, payload T) {
 	b.mu.RLock()
 
@@ -131,35 +257,113 @@ func (b *Broker[T]) Publish(eventType EventType, payload T) {
 	}
 
 	// Snapshot subscribers for lock-free publishing
-	subscribers := make([]chan Event[T], 0, len(b.subs))
+	subscribers := make([]*subscription[T], 0, len(b.subs))
 	for sub := range b.subs {
 		subscribers = append(subscribers, sub)
 	}
 	b.mu.RUnlock()
 
-	if len(subscribers) == 0 {
-		return
-	}
-
 	event := Event[T]{
 		Type:      eventType,
 		Payload:   payload,
 		Timestamp: time.Now(),
 	}
 
+	// Kept for late subscribers even when nobody is listening yet.
+	if b.replay != nil {
+		b.replay.Add(event)
+	}
+
+	if len(subscribers) == 0 {
+		return
+	}
+
 	b.publishCount.Add(1)
 
 	for _, sub := range subscribers {
-		if b.dropOnFull {
-			select {
-			case sub <- event:
-			default:
-				b.dropCount.Add(1)
-			}
-		} else {
-			sub <- event // Blocking
+		b.deliver(sub, event)
+	}
+}
+
+// deliver sends event to one subscriber under its overflow policy. A panic
+// while doing so is counted and contained, so that it neither reaches the
+// publisher nor keeps the event from the other subscribers.
+func (b *Broker[T]) deliver(sub *subscription[T], event Event[T]) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			b.panicCount.Add(1)
+			b.drop(sub)
 		}
+	}()
+
+	if sub.removed {
+		return
 	}
+	event.Dropped = sub.missed
+
+	select {
+	case sub.ch <- event:
+		b.delivered(sub)
+		return
+	default:
+	}
+
+	switch sub.policy {
+	case DropOldest:
+		select {
+		case <-sub.ch:
+			b.drop(sub)
+			event.Dropped = sub.missed
+		default: // The subscriber just made room itself
+		}
+		select {
+		case sub.ch <- event:
+			b.delivered(sub)
+		default:
+			b.drop(sub)
+		}
+	case Block:
+		var timeout <-chan time.Time
+		if sub.blockTimeout > 0 {
+			timer := time.NewTimer(sub.blockTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case sub.ch <- event:
+			b.delivered(sub)
+		case <-sub.done:
+		case <-timeout:
+			b.drop(sub)
+		}
+	default:
+		b.drop(sub)
+	}
+}
+
+// delivered records that sub received an event.
+func (b *Broker[T]) delivered(sub *subscription[T]) {
+	sub.missed = 0
+	sub.delivered.Add(1)
+}
+
+// drop records that sub missed an event.
+func (b *Broker[T]) drop(sub *subscription[T]) {
+	sub.missed++
+	sub.dropped.Add(1)
+	b.dropCount.Add(1)
+}
+
+// Replay returns the recent events with the given key, oldest first, or nil
+// when the broker keeps none. evicted reports whether older events with the
+// key no longer fit in the buffer.
+func (b *Broker[T]) Replay(key string) (recent []Event[T], evicted bool) {
+	if b.replay == nil {
+		return nil, false
+	}
+	return b.replay.Recent(key)
 }
 
 // PublishAsync publishes an event asynchronously and returns immediately.
@@ -180,9 +384,9 @@ func (b *Broker[T]) Shutdown() {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	for ch := range b.subs {
-		delete(b.subs, ch)
-		close(ch)
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		sub.close()
 	}
 	b.subscriberCurr.Store(0)
 }
@@ -204,12 +408,28 @@ func (b *Broker[T]) SubscriberCount() int {
 
 // Metrics returns the broker's metrics for debugging.
 func (b *Broker[T]) Metrics() BrokerMetrics {
+	b.mu.RLock()
+	subscribers := make([]SubscriberMetrics, 0, len(b.subs))
+	for sub := range b.subs {
+		subscribers = append(subscribers, SubscriberMetrics{
+			Name:      sub.name,
+			Policy:    sub.policy,
+			Buffered:  len(sub.ch),
+			Capacity:  cap(sub.ch),
+			Delivered: sub.delivered.Load(),
+			Dropped:   sub.dropped.Load(),
+		})
+	}
+	b.mu.RUnlock()
+
 	return BrokerMetrics{
 		Name:            b.name,
 		PublishCount:    b.publishCount.Load(),
 		DropCount:       b.dropCount.Load(),
+		PanicCount:      b.panicCount.Load(),
 		SubscriberCount: int(b.subscriberCurr.Load()),
 		SubscriberPeak:  int(b.subscriberPeak.Load()),
+		Subscribers:     subscribers,
 	}
 }
 
@@ -218,6 +438,18 @@ type BrokerMetrics struct {
 	Name            string
 	PublishCount    int64
 	DropCount       int64
+	PanicCount      int64 // Deliveries that panicked, also counted as drops
 	SubscriberCount int
 	SubscriberPeak  int
+	Subscribers     []SubscriberMetrics
+}
+
+// SubscriberMetrics contains the statistics of one subscription.
+type SubscriberMetrics struct {
+	Name      string
+	Policy    OverflowPolicy
+	Buffered  int // Events waiting to be received
+	Capacity  int
+	Delivered int64
+	Dropped   int64
 }
@@ -309,3 +309,140 @@ func TestBrokerIsShutdown(t *testing.T) {
 		t.Error("broker should still be shut down")
 	}
 }
+
+func TestBrokerOverflowPolicies(t *testing.T) {
+	receive := func(ch <-chan Event[int]) []Event[int] {
+		var got []Event[int]
+		for {
+			select {
+			case e := <-ch:
+				got = append(got, e)
+			default:
+				return got
+			}
+		}
+	}
+
+	t.Run("drop newest keeps the first events", func(t *testing.T) {
+		broker := NewBroker[int]("test", WithBufferSize[int](2))
+		defer broker.Shutdown()
+		ch := broker.Subscribe(context.Background())
+
+		for i := 1; i <= 4; i++ {
+			broker.Publish(EventCreated, i)
+		}
+		got := receive(ch)
+		if len(got) != 2 || got[0].Payload != 1 || got[1].Payload != 2 {
+			t.Errorf("received %+v, want 1 and 2", got)
+		}
+		if m := broker.Metrics(); m.DropCount != 2 {
+			t.Errorf("DropCount = %d, want 2", m.DropCount)
+		}
+
+		broker.Publish(EventCreated, 5)
+		if got := receive(ch); len(got) != 1 || got[0].Dropped != 2 {
+			t.Errorf("received %+v, want 5 reporting 2 dropped", got)
+		}
+	})
+
+	t.Run("drop oldest keeps the latest events", func(t *testing.T) {
+		broker := NewBroker[int]("test", WithBufferSize[int](2), WithOverflowPolicy[int](DropOldest))
+		defer broker.Shutdown()
+		ch := broker.Subscribe(context.Background())
+
+		for i := 1; i <= 4; i++ {
+			broker.Publish(EventCreated, i)
+		}
+		got := receive(ch)
+		if len(got) != 2 || got[0].Payload != 3 || got[1].Payload != 4 {
+			t.Errorf("received %+v, want 3 and 4", got)
+		}
+		if dropped := got[0].Dropped + got[1].Dropped; dropped != 2 {
+			t.Errorf("Dropped sums to %d, want 2", dropped)
+		}
+	})
+
+	t.Run("block gives up after the timeout", func(t *testing.T) {
+		broker := NewBroker[int]("test", WithBufferSize[int](1))
+		defer broker.Shutdown()
+		ch := broker.Subscribe(context.Background(), WithSubscriberPolicy(Block, 20*time.Millisecond))
+
+		broker.Publish(EventCreated, 1)
+		start := time.Now()
+		broker.Publish(EventCreated, 2)
+		if elapsed := time.Since(start); elapsed < 20*time.Millisecond || elapsed > time.Second {
+			t.Errorf("publish took %s, want about the 20ms timeout", elapsed)
+		}
+		if got := receive(ch); len(got) != 1 || got[0].Payload != 1 {
+			t.Errorf("received %+v, want 1", got)
+		}
+	})
+
+	t.Run("blocked publish ends with the subscription", func(t *testing.T) {
+		broker := NewBroker[int]("test", WithBufferSize[int](1), WithOverflowPolicy[int](Block), WithBlockTimeout[int](0))
+		defer broker.Shutdown()
+		ctx, cancel := context.WithCancel(context.Background())
+		_ = broker.Subscribe(ctx)
+
+		broker.Publish(EventCreated, 1)
+		done := make(chan struct{})
+		go func() {
+			broker.Publish(EventCreated, 2)
+			close(done)
+		}()
+
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("publish still blocked after the subscriber left")
+		}
+	})
+}
+
+func TestBrokerSubscriberMetrics(t *testing.T) {
+	broker := NewBroker[int]("test", WithBufferSize[int](1))
+	defer broker.Shutdown()
+
+	_ = broker.Subscribe(context.Background(), WithSubscriberName("slow"))
+	fast := broker.Subscribe(context.Background(), WithSubscriberName("fast"), WithSubscriberBuffer(8))
+
+	broker.Publish(EventCreated, 1)
+	broker.Publish(EventCreated, 2)
+	<-fast
+
+	byName := make(map[string]SubscriberMetrics)
+	for _, s := range broker.Metrics().Subscribers {
+		byName[s.Name] = s
+	}
+	if s := byName["slow"]; s.Delivered != 1 || s.Dropped != 1 || s.Buffered != 1 || s.Capacity != 1 {
+		t.Errorf("slow = %+v, want 1 delivered, 1 dropped, 1 of 1 buffered", s)
+	}
+	if s := byName["fast"]; s.Delivered != 2 || s.Dropped != 0 || s.Buffered != 1 || s.Policy != DropNewest {
+		t.Errorf("fast = %+v, want 2 delivered and 1 still buffered", s)
+	}
+}
+
+func TestBrokerPublishWhileSubscribersLeave(t *testing.T) {
+	broker := NewBroker[int]("test", WithBufferSize[int](1), WithOverflowPolicy[int](Block))
+	defer broker.Shutdown()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		_ = broker.Subscribe(ctx)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 20; n++ {
+				broker.Publish(EventCreated, n)
+			}
+		}()
+		go cancel()
+	}
+	wg.Wait()
+
+	if m := broker.Metrics(); m.PanicCount != 0 {
+		t.Errorf("PanicCount = %d, want 0", m.PanicCount)
+	}
+}
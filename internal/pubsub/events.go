@@ -25,6 +25,10 @@ type Event[T any] struct { //nolint:govet // fieldalignment: preserving logical
 	Type      EventType
 	Payload   T
 	Timestamp time.Time
+
+	// Dropped counts the events this subscriber lost to a full buffer since
+	// it was sent the previous one.
+	Dropped int
 }
 
 // Publisher is the interface for publishing events.
@@ -35,7 +39,7 @@ type Publisher[T any] interface {
 
 // Subscriber is the interface for subscribing to events.
 type Subscriber[T any] interface {
-	Subscribe(context.Context) <-chan Event[T]
+	Subscribe(context.Context, ...SubscribeOption) <-chan Event[T]
 }
 
 // PubSub combines Publisher and Subscriber interfaces.
@@ -14,19 +14,32 @@ type Hub struct { //nolint:govet // fieldalignment: preserving logical field ord
 	Session *Broker[events.SessionEvent]
 	Auth    *Broker[events.AuthEvent]
 	Todo    *Broker[events.TodoEvent]
+	Job     *Broker[events.JobEvent]
+	Config  *Broker[events.ConfigEvent]
 
 	registry *Registry
 	done     chan struct{}
 }
 
+// Events kept per session and broker for replay to late subscribers. A
+// streamed reply publishes an event per text delta, so the agent broker
+// keeps the most.
+const (
+	AgentReplaySize = 4096
+	ToolReplaySize  = 256
+	TodoReplaySize  = 64
+)
+
 // NewHub creates a new Hub with all domain brokers initialized.
 func NewHub() *Hub {
 	h := &Hub{
-		Agent:    NewBroker[events.AgentEvent]("agent"),
-		Tool:     NewBroker[events.ToolEvent]("tool"),
+		Agent:    NewBroker("agent", WithReplay(AgentReplaySize, func(e events.AgentEvent) string { return e.SessionID })),
+		Tool:     NewBroker("tool", WithReplay(ToolReplaySize, func(e events.ToolEvent) string { return e.SessionID })),
 		Session:  NewBroker[events.SessionEvent]("session"),
 		Auth:     NewBroker[events.AuthEvent]("auth"),
-		Todo:     NewBroker[events.TodoEvent]("todo"),
+		Todo:     NewBroker("todo", WithReplay(TodoReplaySize, func(e events.TodoEvent) string { return e.SessionID })),
+		Job:      NewBroker[events.JobEvent]("job"),
+		Config:   NewBroker[events.ConfigEvent]("config"),
 		registry: NewRegistry(),
 		done:     make(chan struct{}),
 	}
@@ -37,6 +50,8 @@ func NewHub() *Hub {
 	h.registry.Register("session", h.Session)
 	h.registry.Register("auth", h.Auth)
 	h.registry.Register("todo", h.Todo)
+	h.registry.Register("job", h.Job)
+	h.registry.Register("config", h.Config)
 
 	return h
 }
@@ -52,13 +67,15 @@ func (h *Hub) Shutdown() {
 
 	// Shutdown all brokers concurrently
 	var wg sync.WaitGroup
-	wg.Add(5)
+	wg.Add(7)
 
 	go func() { defer wg.Done(); h.Agent.Shutdown() }()
 	go func() { defer wg.Done(); h.Tool.Shutdown() }()
 	go func() { defer wg.Done(); h.Session.Shutdown() }()
 	go func() { defer wg.Done(); h.Auth.Shutdown() }()
 	go func() { defer wg.Done(); h.Todo.Shutdown() }()
+	go func() { defer wg.Done(); h.Job.Shutdown() }()
+	go func() { defer wg.Done(); h.Config.Shutdown() }()
 
 	wg.Wait()
 }
@@ -91,6 +108,8 @@ func (h *Hub) AllMetrics() []BrokerMetrics {
 		h.Session.Metrics(),
 		h.Auth.Metrics(),
 		h.Todo.Metrics(),
+		h.Job.Metrics(),
+		h.Config.Metrics(),
 	}
 }
 
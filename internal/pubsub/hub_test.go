@@ -106,8 +106,8 @@ func TestHubAllMetrics(t *testing.T) {
 
 		metrics := hub.AllMetrics()
 
-		if len(metrics) != 5 {
-			t.Errorf("expected 5 broker metrics, got %d", len(metrics))
+		if len(metrics) != 7 {
+			t.Errorf("expected 7 broker metrics, got %d", len(metrics))
 		}
 
 		// Verify broker names
@@ -116,7 +116,7 @@ func TestHubAllMetrics(t *testing.T) {
 			names[m.Name] = true
 		}
 
-		expectedNames := []string{"agent", "tool", "session", "auth", "todo"}
+		expectedNames := []string{"agent", "tool", "session", "auth", "todo", "job", "config"}
 		for _, name := range expectedNames {
 			if !names[name] {
 				t.Errorf("expected broker %q in metrics", name)
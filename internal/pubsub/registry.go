@@ -84,10 +84,20 @@ func (r *Registry) DebugString() string {
 	for name, broker := range r.brokers {
 		m := broker.Metrics()
 		sb.WriteString(fmt.Sprintf(
-			"  %s: subs=%d (peak=%d), published=%d, dropped=%d, shutdown=%v\n",
+			"  %s: subs=%d (peak=%d), published=%d, dropped=%d, panics=%d, shutdown=%v\n",
 			name, m.SubscriberCount, m.SubscriberPeak,
-			m.PublishCount, m.DropCount, broker.IsShutdown(),
+			m.PublishCount, m.DropCount, m.PanicCount, broker.IsShutdown(),
 		))
+		for _, s := range m.Subscribers {
+			subName := s.Name
+			if subName == "" {
+				subName = "(unnamed)"
+			}
+			sb.WriteString(fmt.Sprintf(
+				"    %s: %s, buffered=%d/%d, delivered=%d, dropped=%d\n",
+				subName, s.Policy, s.Buffered, s.Capacity, s.Delivered, s.Dropped,
+			))
+		}
 	}
 
 	return sb.String()
@@ -0,0 +1,91 @@
+package pubsub
+
+import "sync"
+
+// maxReplayKeys is how many keys a replay buffer keeps events for. When a
+// new key arrives past it, the key that has been quiet the longest is
+// forgotten.
+const maxReplayKeys = 64
+
+// ReplayBuffer keeps a broker's most recent events, so that a subscriber
+// arriving late, such as a chat page switching to a session that is already
+// running, can catch up on what is still in progress. Each key has a ring of
+// its own, so a busy session does not push out the events of a quiet one.
+type ReplayBuffer[T any] struct {
+	mu    sync.Mutex
+	size  int
+	key   func(T) string
+	rings map[string]*replayRing[T]
+	added uint64 // Events added so far, to tell which key was quiet longest
+}
+
+// replayRing holds the last events of one key.
+type replayRing[T any] struct {
+	events  []Event[T]
+	next    int    // Where the next event goes once the ring is full
+	evicted bool   // Older events were dropped to make room
+	last    uint64 // When the last event was added, in ReplayBuffer.added
+}
+
+// NewReplayBuffer creates a buffer of the last size events of each key.
+// key groups the events, e.g. by session.
+func NewReplayBuffer[T any](size int, key func(T) string) *ReplayBuffer[T] {
+	return &ReplayBuffer[T]{size: max(size, 1), key: key, rings: make(map[string]*replayRing[T])}
+}
+
+// Add records an event, replacing the oldest of its key when the key's ring
+// is full.
+func (r *ReplayBuffer[T]) Add(event Event[T]) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := r.key(event.Payload)
+	ring := r.rings[k]
+	if ring == nil {
+		if len(r.rings) >= maxReplayKeys {
+			r.forgetQuietest()
+		}
+		ring = &replayRing[T]{}
+		r.rings[k] = ring
+	}
+	r.added++
+	ring.last = r.added
+
+	if len(ring.events) < r.size {
+		ring.events = append(ring.events, event)
+		return
+	}
+	ring.events[ring.next] = event
+	ring.next = (ring.next + 1) % len(ring.events)
+	ring.evicted = true
+}
+
+// forgetQuietest drops the ring of the key that has gone longest without an
+// event. The caller holds r.mu.
+func (r *ReplayBuffer[T]) forgetQuietest() {
+	var quietest string
+	var oldest uint64
+	for k, ring := range r.rings {
+		if oldest == 0 || ring.last < oldest {
+			quietest, oldest = k, ring.last
+		}
+	}
+	delete(r.rings, quietest)
+}
+
+// Recent returns the buffered events with the given key, oldest first.
+// evicted reports whether older events with the key were dropped to make
+// room, so the first event returned may not be the first published.
+func (r *ReplayBuffer[T]) Recent(key string) (recent []Event[T], evicted bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ring := r.rings[key]
+	if ring == nil {
+		return nil, false
+	}
+	recent = make([]Event[T], 0, len(ring.events))
+	recent = append(recent, ring.events[ring.next:]...)
+	recent = append(recent, ring.events[:ring.next]...)
+	return recent, ring.evicted
+}
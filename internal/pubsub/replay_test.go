@@ -0,0 +1,83 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReplayBuffer(t *testing.T) {
+	r := NewReplayBuffer(3, func(s string) string { return s[:1] })
+
+	payloads := func(events []Event[string]) []string {
+		var out []string
+		for _, e := range events {
+			out = append(out, e.Payload)
+		}
+		return out
+	}
+
+	r.Add(Event[string]{Payload: "a1"})
+	r.Add(Event[string]{Payload: "b1"})
+	if got, evicted := r.Recent("a"); !slices.Equal(payloads(got), []string{"a1"}) || evicted {
+		t.Errorf("Recent(a) = %v, %v; want [a1] and nothing evicted", payloads(got), evicted)
+	}
+
+	// The oldest events of a key make way once its ring is full; other keys
+	// keep theirs.
+	r.Add(Event[string]{Payload: "a2"})
+	r.Add(Event[string]{Payload: "a3"})
+	r.Add(Event[string]{Payload: "a4"})
+	if got, evicted := r.Recent("a"); !slices.Equal(payloads(got), []string{"a2", "a3", "a4"}) || !evicted {
+		t.Errorf("Recent(a) = %v, %v; want [a2 a3 a4] and a1 evicted", payloads(got), evicted)
+	}
+	if got, evicted := r.Recent("b"); !slices.Equal(payloads(got), []string{"b1"}) || evicted {
+		t.Errorf("Recent(b) = %v, %v; want [b1] kept", payloads(got), evicted)
+	}
+	if got, _ := r.Recent("c"); len(got) != 0 {
+		t.Errorf("Recent(c) = %v, want none", payloads(got))
+	}
+}
+
+func TestReplayBuffer_ForgetsQuietKeys(t *testing.T) {
+	r := NewReplayBuffer(2, func(s string) string { return s })
+	for i := range maxReplayKeys + 1 {
+		r.Add(Event[string]{Payload: fmt.Sprint(i)})
+	}
+	if got, _ := r.Recent("0"); len(got) != 0 {
+		t.Errorf("the quietest key should be forgotten, got %v", got)
+	}
+	if got, _ := r.Recent(fmt.Sprint(maxReplayKeys)); len(got) != 1 {
+		t.Errorf("the newest key should be kept, got %v", got)
+	}
+}
+
+func TestBrokerReplay(t *testing.T) {
+	broker := NewBroker("test", WithReplay(8, func(s string) string { return strings.Split(s, ":")[0] }))
+	defer broker.Shutdown()
+
+	// Published before anyone subscribed.
+	broker.Publish(EventStarted, "s1:read")
+	broker.Publish(EventStarted, "s2:bash")
+	broker.Publish(EventCompleted, "s1:read")
+
+	got, _ := broker.Replay("s1")
+	if len(got) != 2 || got[0].Type != EventStarted || got[1].Type != EventCompleted {
+		t.Errorf("Replay(s1) = %+v, want started then completed", got)
+	}
+
+	ch := broker.Subscribe(context.Background())
+	broker.Publish(EventStarted, "s1:edit")
+	if e := <-ch; e.Payload != "s1:edit" {
+		t.Errorf("subscriber got %q, want s1:edit", e.Payload)
+	}
+	if got, _ := broker.Replay("s1"); len(got) != 3 {
+		t.Errorf("Replay(s1) has %d events, want 3", len(got))
+	}
+
+	if got, _ := NewBroker[string]("plain").Replay("s1"); got != nil {
+		t.Errorf("Replay without WithReplay = %v, want nil", got)
+	}
+}
@@ -0,0 +1,65 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var errKeyringUnavailable = errors.New("no keyring available")
+
+// Memory is an in-memory Store, used in tests and when secrets must not
+// leave the process.
+type Memory struct {
+	values map[string]string
+	mu     sync.RWMutex
+}
+
+// NewMemory creates an empty in-memory Store.
+func NewMemory() *Memory {
+	return &Memory{values: make(map[string]string)}
+}
+
+// Get returns the secret stored under key.
+func (m *Memory) Get(key string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	value, ok := m.values[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return value, nil
+}
+
+// Set stores value under key.
+func (m *Memory) Set(key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = value
+	return nil
+}
+
+// Delete removes the secret stored under key.
+func (m *Memory) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.values[key]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	delete(m.values, key)
+	return nil
+}
+
+// Unavailable is a Store that always fails, standing in for a system without a keyring.
+type Unavailable struct{}
+
+// Get always fails.
+func (Unavailable) Get(string) (string, error) { return "", errKeyringUnavailable }
+
+// Set always fails.
+func (Unavailable) Set(string, string) error { return errKeyringUnavailable }
+
+// Delete always fails.
+func (Unavailable) Delete(string) error { return errKeyringUnavailable }
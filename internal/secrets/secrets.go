@@ -0,0 +1,101 @@
+// Package secrets stores credentials in the operating system keyring
+// (macOS Keychain, libsecret on Linux, Windows Credential Manager).
+//
+// Config files refer to stored secrets with references of the form
+// "keyring:<key>", which are resolved when the configuration is loaded.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// Service is the keyring service name under which all cdd secrets are stored.
+const Service = "cdd"
+
+// refPrefix marks a config value as a reference to a keyring secret.
+const refPrefix = "keyring:"
+
+// probeKey is looked up to check whether the keyring can be reached.
+const probeKey = "__cdd_probe__"
+
+// ErrNotFound is returned when a secret does not exist in the store.
+var ErrNotFound = errors.New("secret not found")
+
+// Store reads and writes secrets by key.
+type Store interface {
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+}
+
+// Keyring is a Store backed by the operating system keyring.
+type Keyring struct {
+	service string
+}
+
+// NewKeyring creates a Store backed by the operating system keyring.
+func NewKeyring() *Keyring {
+	return &Keyring{service: Service}
+}
+
+// Get returns the secret stored under key.
+func (k *Keyring) Get(key string) (string, error) {
+	value, err := keyring.Get(k.service, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading %s from keyring: %w", key, err)
+	}
+	return value, nil
+}
+
+// Set stores value under key, replacing any existing secret.
+func (k *Keyring) Set(key, value string) error {
+	if err := keyring.Set(k.service, key, value); err != nil {
+		return fmt.Errorf("writing %s to keyring: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes the secret stored under key.
+func (k *Keyring) Delete(key string) error {
+	err := keyring.Delete(k.service, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if err != nil {
+		return fmt.Errorf("deleting %s from keyring: %w", key, err)
+	}
+	return nil
+}
+
+// Available reports whether the store can be reached. A missing probe secret
+// counts as available; any other error means there is no usable keyring.
+func Available(store Store) bool {
+	_, err := store.Get(probeKey)
+	return err == nil || errors.Is(err, ErrNotFound)
+}
+
+// IsRef reports whether value is a keyring reference.
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, refPrefix)
+}
+
+// Ref returns the config reference for key.
+func Ref(key string) string {
+	return refPrefix + key
+}
+
+// Resolve returns the secret a reference points to. Values that are not
+// references are returned unchanged.
+func Resolve(store Store, value string) (string, error) {
+	if !IsRef(value) {
+		return value, nil
+	}
+	return store.Get(strings.TrimPrefix(value, refPrefix))
+}
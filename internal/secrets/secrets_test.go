@@ -0,0 +1,73 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestKeyring(t *testing.T) {
+	keyring.MockInit()
+	store := NewKeyring()
+
+	if _, err := store.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+	if err := store.Set("connections/a/api_key", "sk-test"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	got, err := store.Get("connections/a/api_key")
+	if err != nil || got != "sk-test" {
+		t.Errorf("Get() = %q, %v; want sk-test", got, err)
+	}
+	if err := store.Delete("connections/a/api_key"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := store.Delete("connections/a/api_key"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete() error = %v, want ErrNotFound", err)
+	}
+	if !Available(store) {
+		t.Error("mock keyring should be available")
+	}
+}
+
+func TestAvailable(t *testing.T) {
+	if !Available(NewMemory()) {
+		t.Error("memory store should be available")
+	}
+	if Available(Unavailable{}) {
+		t.Error("unavailable store should not be available")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	store := NewMemory()
+	if err := store.Set("providers/openai/api_key", "sk-stored"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain value", value: "sk-plain", want: "sk-plain"},
+		{name: "env reference", value: "$OPENAI_API_KEY", want: "$OPENAI_API_KEY"},
+		{name: "keyring reference", value: Ref("providers/openai/api_key"), want: "sk-stored"},
+		{name: "missing reference", value: Ref("providers/other/api_key"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(store, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/proc"
+)
+
+// Holder is the cdd process holding a session's lock.
+type Holder struct { //nolint:govet // fieldalignment: preserving logical field order
+	PID        int       `json:"pid"`
+	Instance   string    `json:"instance"`
+	Command    string    `json:"command,omitempty"` // What the holder is doing, e.g. "cdd run"
+	AcquiredAt time.Time `json:"acquired_at"`
+}
+
+// LockedError is returned when a session is in use by another cdd.
+type LockedError struct {
+	SessionID string
+	Holder    Holder
+}
+
+func (e *LockedError) Error() string {
+	by := "cdd"
+	if e.Holder.Command != "" {
+		by = e.Holder.Command
+	}
+	return fmt.Sprintf("session %s is in use by %s (PID %d) since %s",
+		e.SessionID, by, e.Holder.PID, e.Holder.AcquiredAt.Local().Format("Jan 2 15:04"))
+}
+
+// Locker keeps advisory locks on sessions, one file per session, so two cdd
+// processes do not write to the same conversation at once. Locks held by a
+// process that is gone are taken over without asking. Within a process a
+// session may be locked several times, e.g. by a tab and the run in it, and
+// is unlocked when the last of them lets go.
+type Locker struct {
+	dir      string
+	command  string
+	pid      int    // Recorded as the holder; proc.PID, but for tests
+	instance string // Recorded as the holder; proc.Instance, but for tests
+	mu       sync.Mutex
+	held     map[string]int // Session ID -> times locked by this process
+}
+
+// NewLocker creates a locker keeping its files under dir. command tells
+// other processes what holds the lock.
+func NewLocker(dir, command string) *Locker {
+	return &Locker{
+		dir:      dir,
+		command:  command,
+		pid:      proc.PID(),
+		instance: proc.Instance,
+		held:     make(map[string]int),
+	}
+}
+
+// Lock locks a session for this process. A session in use by another live
+// cdd is a *LockedError, unless force is set, in which case the lock is
+// taken over and the other cdd refuses to write to it from then on.
+func (l *Locker) Lock(sessionID string, force bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	holder, err := l.read(sessionID)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		err = l.write(sessionID, false)
+		if errors.Is(err, os.ErrExist) { // Another cdd got there first
+			if holder, readErr := l.read(sessionID); readErr == nil {
+				return &LockedError{SessionID: sessionID, Holder: holder}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	case holder.Instance == l.instance: // Already ours
+	case proc.Running(holder.PID, holder.Instance) && !force:
+		return &LockedError{SessionID: sessionID, Holder: holder}
+	default: // Left by a cdd that is gone, or taken over
+		if err := l.write(sessionID, true); err != nil {
+			return err
+		}
+	}
+	l.held[sessionID]++
+	return nil
+}
+
+// Unlock lets go of a session locked with Lock. The lock is removed once
+// every Lock of the session in this process is undone.
+func (l *Locker) Unlock(sessionID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held[sessionID] == 0 {
+		return
+	}
+	l.held[sessionID]--
+	if l.held[sessionID] == 0 {
+		delete(l.held, sessionID)
+		l.remove(sessionID)
+	}
+}
+
+// UnlockAll removes every lock this process holds, on exit.
+func (l *Locker) UnlockAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for sessionID := range l.held {
+		l.remove(sessionID)
+	}
+	clear(l.held)
+}
+
+// Check returns a *LockedError when another cdd holds the session's lock,
+// as after it took the session over with force.
+func (l *Locker) Check(sessionID string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	holder, err := l.read(sessionID)
+	if err != nil || holder.Instance == l.instance || !proc.Running(holder.PID, holder.Instance) {
+		return nil
+	}
+	return &LockedError{SessionID: sessionID, Holder: holder}
+}
+
+// Holder returns the live cdd holding a session's lock, if any.
+func (l *Locker) Holder(sessionID string) (Holder, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	holder, err := l.read(sessionID)
+	if err != nil || !proc.Running(holder.PID, holder.Instance) {
+		return Holder{}, false
+	}
+	return holder, true
+}
+
+func (l *Locker) path(sessionID string) string {
+	return filepath.Join(l.dir, filepath.Base(sessionID)+".lock")
+}
+
+func (l *Locker) read(sessionID string) (Holder, error) {
+	var holder Holder
+	data, err := os.ReadFile(l.path(sessionID)) //nolint:gosec // Path is built from the data directory
+	if err != nil {
+		return holder, fmt.Errorf("reading session lock: %w", err)
+	}
+	if err := json.Unmarshal(data, &holder); err != nil {
+		return Holder{}, nil //nolint:nilerr // A garbled lock belongs to nobody
+	}
+	return holder, nil
+}
+
+// write records this process as the session's holder. The holder is written
+// to a temporary file first and then moved into place, so other processes
+// never read a lock that is only half written: a new lock is linked in only
+// if there is none, and replace swaps in the new holder in one rename.
+func (l *Locker) write(sessionID string, replace bool) error {
+	if err := os.MkdirAll(l.dir, 0o700); err != nil {
+		return fmt.Errorf("creating session lock directory: %w", err)
+	}
+	data, err := json.Marshal(Holder{
+		PID:        l.pid,
+		Instance:   l.instance,
+		Command:    l.command,
+		AcquiredAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("encoding session lock: %w", err)
+	}
+	path := l.path(sessionID)
+	f, err := os.CreateTemp(l.dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating session lock: %w", err)
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) //nolint:errcheck // Gone after a rename; a leftover is harmless
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("writing session lock: %w", err)
+	}
+	if !replace {
+		if err := os.Link(tmp, path); err != nil {
+			return fmt.Errorf("creating session lock: %w", err)
+		}
+		return nil
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("saving session lock: %w", err)
+	}
+	return nil
+}
+
+// remove deletes the session's lock if this process still holds it; one
+// taken over by another cdd is left alone.
+func (l *Locker) remove(sessionID string) {
+	if holder, err := l.read(sessionID); err == nil && holder.Instance == l.instance {
+		_ = os.Remove(l.path(sessionID)) //nolint:errcheck // A stale lock is taken over next time
+	}
+}
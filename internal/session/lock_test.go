@@ -0,0 +1,137 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedElsewhere leaves a lock on sessionID held by another live process,
+// the test's parent.
+func lockedElsewhere(t *testing.T, dir, sessionID string) Holder {
+	t.Helper()
+	holder := Holder{PID: os.Getppid(), Instance: "other", Command: "cdd run", AcquiredAt: time.Now()}
+	data, err := json.Marshal(holder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, sessionID+".lock"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return holder
+}
+
+func TestLocker_LockUnlock(t *testing.T) {
+	dir := t.TempDir()
+	locks := NewLocker(dir, "cdd")
+	path := filepath.Join(dir, "s1.lock")
+
+	// A tab and the run in it both lock the session.
+	for range 2 {
+		if err := locks.Lock("s1", false); err != nil {
+			t.Fatalf("Lock() error = %v", err)
+		}
+	}
+	if _, ok := locks.Holder("s1"); !ok {
+		t.Error("Holder() should report this process")
+	}
+	if err := locks.Check("s1"); err != nil {
+		t.Errorf("Check() error = %v for a session this process holds", err)
+	}
+
+	locks.Unlock("s1")
+	if _, err := os.Stat(path); err != nil {
+		t.Fatal("the lock should stay until every Lock is undone")
+	}
+	locks.Unlock("s1")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the lock should be removed, stat error = %v", err)
+	}
+}
+
+func TestLocker_InUseElsewhere(t *testing.T) {
+	dir := t.TempDir()
+	holder := lockedElsewhere(t, dir, "s1")
+	locks := NewLocker(dir, "cdd")
+
+	err := locks.Lock("s1", false)
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.Holder.PID != holder.PID {
+		t.Fatalf("Lock() error = %v, want the session in use by PID %d", err, holder.PID)
+	}
+	if !strings.Contains(err.Error(), "in use by cdd run") {
+		t.Errorf("error %q should say what holds the session", err)
+	}
+
+	// Taking it over makes the lock this process's.
+	if err := locks.Lock("s1", true); err != nil {
+		t.Fatalf("Lock(force) error = %v", err)
+	}
+	if got, ok := locks.Holder("s1"); !ok || got.Instance == holder.Instance {
+		t.Errorf("Holder() = %+v, want this process after the takeover", got)
+	}
+
+	// Unlocking a session taken over from this process leaves the new lock.
+	lockedElsewhere(t, dir, "s1")
+	locks.Unlock("s1")
+	if err := locks.Check("s1"); err == nil {
+		t.Error("Check() should report the session taken over by another cdd")
+	}
+}
+
+func TestLocker_StaleLock(t *testing.T) {
+	dir := t.TempDir()
+	stale := Holder{PID: os.Getpid(), Instance: "earlier", AcquiredAt: time.Now()}
+	data, err := json.Marshal(stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "s1.lock"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	locks := NewLocker(dir, "cdd")
+	if err := locks.Lock("s1", false); err != nil {
+		t.Errorf("a lock left by a cdd that is gone should be taken over, got %v", err)
+	}
+	locks.UnlockAll()
+	if _, err := os.Stat(filepath.Join(dir, "s1.lock")); !os.IsNotExist(err) {
+		t.Errorf("UnlockAll() should remove the lock, stat error = %v", err)
+	}
+}
+
+func TestLocker_ConcurrentLock(t *testing.T) {
+	for range 50 {
+		dir := t.TempDir()
+		const processes = 8
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var owners []int
+		for i := range processes {
+			// Each locker stands in for another live cdd.
+			locks := NewLocker(dir, "cdd")
+			locks.pid = os.Getppid()
+			locks.instance = fmt.Sprintf("cdd-%d", i)
+			wg.Go(func() {
+				if err := locks.Lock("s1", false); err == nil {
+					mu.Lock()
+					owners = append(owners, i)
+					mu.Unlock()
+				}
+			})
+		}
+		wg.Wait()
+		if len(owners) != 1 {
+			t.Fatalf("%d lockers own the session (%v), want exactly one", len(owners), owners)
+		}
+	}
+}
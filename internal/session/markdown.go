@@ -0,0 +1,111 @@
+package session
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/guilhermegouw/cdd/internal/message"
+)
+
+// MarkdownOptions controls what Markdown includes.
+type MarkdownOptions struct {
+	Reasoning bool // Include the model's thinking
+}
+
+// Markdown renders the session as a Markdown transcript for reading:
+// prompts, replies, tool calls with their input, and tool output, in order.
+func (e *Export) Markdown(opts MarkdownOptions) string {
+	var sb strings.Builder
+
+	title := e.Session.Title
+	if title == "" {
+		title = "Session " + e.Session.ID
+	}
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+	fmt.Fprintf(&sb, "*%s · started %s · updated %s · %d messages*\n\n",
+		e.Session.ID, e.Session.CreatedAt.Local().Format("2006-01-02 15:04"),
+		e.Session.UpdatedAt.Local().Format("2006-01-02 15:04"), len(e.Messages))
+
+	for i := range e.Messages {
+		writeMessageMarkdown(&sb, &e.Messages[i], opts)
+	}
+	return sb.String()
+}
+
+// writeMessageMarkdown renders one message, its parts in the order they were
+// produced.
+func writeMessageMarkdown(sb *strings.Builder, msg *ExportedMessage, opts MarkdownOptions) {
+	at := msg.CreatedAt.Local().Format("15:04")
+	switch {
+	case msg.IsSummary:
+		fmt.Fprintf(sb, "---\n\n## Summary of the earlier conversation · %s\n\n", at)
+	case msg.Role == message.RoleUser:
+		fmt.Fprintf(sb, "---\n\n## You · %s\n\n", at)
+	case msg.Role == message.RoleAssistant:
+		heading := "Assistant"
+		if msg.Model != "" {
+			heading += " (" + msg.Model + ")"
+		}
+		fmt.Fprintf(sb, "## %s · %s\n\n", heading, at)
+	}
+
+	// An interrupted reply says why it stopped instead of blaming the user
+	interrupted := slices.ContainsFunc(msg.Parts, func(p message.Part) bool {
+		return p.Type == message.PartTypeInterrupted
+	})
+	for _, part := range msg.Parts {
+		switch part.Type {
+		case message.PartTypeText:
+			if text := strings.TrimSpace(part.Text); text != "" {
+				sb.WriteString(text + "\n\n")
+			}
+		case message.PartTypeReasoning:
+			if opts.Reasoning && strings.TrimSpace(part.Reasoning) != "" {
+				sb.WriteString("> **Thinking**\n>\n")
+				for line := range strings.Lines(strings.TrimSpace(part.Reasoning)) {
+					sb.WriteString("> " + strings.TrimRight(line, "\n") + "\n")
+				}
+				sb.WriteString("\n")
+			}
+		case message.PartTypeToolCall:
+			if part.ToolCall != nil {
+				fmt.Fprintf(sb, "**→ %s**\n\n%s", part.ToolCall.Name, codeBlock("json", part.ToolCall.Input))
+			}
+		case message.PartTypeToolResult:
+			if r := part.ToolResult; r != nil {
+				label := "←"
+				if r.IsError {
+					label = "✗"
+				}
+				fmt.Fprintf(sb, "**%s %s**\n\n%s", label, r.Name, codeBlock("", r.Content))
+			}
+		case message.PartTypeImage:
+			if part.Image != nil {
+				fmt.Fprintf(sb, "*[image: %s]*\n\n", part.Image.Filename)
+			}
+		case message.PartTypeFile:
+			if part.File != nil {
+				fmt.Fprintf(sb, "*[file: %s]*\n\n", part.File.Filename)
+			}
+		case message.PartTypeCancelled:
+			if !interrupted {
+				sb.WriteString("*Stopped by the user.*\n\n")
+			}
+		case message.PartTypeInterrupted:
+			sb.WriteString("*Interrupted when cdd exited.*\n\n")
+		case message.PartTypeTiming:
+			// Streaming speed says more about the machine than the session
+		}
+	}
+}
+
+// codeBlock fences content, with a fence longer than any run of backticks
+// inside it so the block can't end early.
+func codeBlock(lang, content string) string {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + strings.TrimRight(content, "\n") + "\n" + fence + "\n\n"
+}
@@ -0,0 +1,55 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/guilhermegouw/cdd/internal/message"
+)
+
+func TestExport_Markdown(t *testing.T) {
+	db := setupTestDB(t)
+	seedSession(t, db.Conn())
+
+	export, err := ExportSession(context.Background(), db.Conn(), "sess-1")
+	if err != nil {
+		t.Fatalf("ExportSession() error = %v", err)
+	}
+	export.Messages[1].Parts = append(export.Messages[1].Parts, message.NewReasoningPart("Use ls."))
+
+	md := export.Markdown(MarkdownOptions{})
+	order := []string{
+		"# Exported Session",
+		"## You",
+		"list files",
+		"## Assistant (gpt-4o)",
+		"Listing.",
+		"**→ bash**",
+		`{"command":"ls"}`,
+		"**← bash**",
+		"main.go",
+		"One file.",
+	}
+	last := -1
+	for _, want := range order {
+		i := strings.Index(md, want)
+		if i < 0 || i < last {
+			t.Fatalf("Markdown() should have %q after the previous parts, got:\n%s", want, md)
+		}
+		last = i
+	}
+	if strings.Contains(md, "Use ls.") {
+		t.Error("reasoning should be left out by default")
+	}
+	if !strings.Contains(export.Markdown(MarkdownOptions{Reasoning: true}), "> Use ls.") {
+		t.Error("reasoning should be quoted when asked for")
+	}
+}
+
+func TestCodeBlock(t *testing.T) {
+	got := codeBlock("", "see ```go\nx\n```")
+	if !strings.HasPrefix(got, "````\n") || !strings.HasSuffix(got, "\n````\n\n") {
+		t.Errorf("codeBlock() = %q, want a longer fence than the content's", got)
+	}
+}
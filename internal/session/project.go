@@ -0,0 +1,66 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProjectRoot returns the root of the git repository containing dir, or dir
+// itself when it is not inside one. Sessions are grouped by this path.
+func ProjectRoot(dir string) string {
+	dir = filepath.Clean(dir)
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
+
+// shortSHA is how much of a commit hash Branch shows for a detached HEAD.
+const shortSHA = 7
+
+// Branch returns the checked-out branch of the git repository containing dir,
+// the short commit hash when HEAD is detached, or "" outside a repository.
+// It reads .git directly rather than running git, so it is cheap to call.
+func Branch(dir string) string {
+	root := ProjectRoot(dir)
+	gitDir := filepath.Join(root, ".git")
+	info, err := os.Stat(gitDir)
+	if err != nil {
+		return ""
+	}
+	// In a worktree or submodule .git is a file pointing at the real one.
+	if !info.IsDir() {
+		data, err := os.ReadFile(gitDir) //nolint:gosec // G304: Path is inside the working directory
+		if err != nil {
+			return ""
+		}
+		target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+		if !ok {
+			return ""
+		}
+		gitDir = strings.TrimSpace(target)
+		if !filepath.IsAbs(gitDir) {
+			gitDir = filepath.Join(root, gitDir)
+		}
+	}
+
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD")) //nolint:gosec // G304: Path is inside the repository
+	if err != nil {
+		return ""
+	}
+	ref := strings.TrimSpace(string(head))
+	if branch, ok := strings.CutPrefix(ref, "ref: refs/heads/"); ok {
+		return branch
+	}
+	if len(ref) > shortSHA {
+		return ref[:shortSHA]
+	}
+	return ref
+}
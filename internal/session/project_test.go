@@ -0,0 +1,66 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectRoot(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	nested := filepath.Join(repo, "internal", "pkg")
+	plain := filepath.Join(root, "plain")
+	for _, dir := range []string{filepath.Join(repo, ".git"), nested, plain} {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+	}
+
+	if got := ProjectRoot(nested); got != repo {
+		t.Errorf("ProjectRoot(nested) = %q, want %q", got, repo)
+	}
+	if got := ProjectRoot(repo); got != repo {
+		t.Errorf("ProjectRoot(repo) = %q, want %q", got, repo)
+	}
+	if got := ProjectRoot(plain); got != plain {
+		t.Errorf("ProjectRoot(plain) = %q, want %q", got, plain)
+	}
+}
+
+func TestBranch(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	worktree := filepath.Join(root, "worktree")
+	detached := filepath.Join(root, "detached")
+	for _, dir := range []string{filepath.Join(repo, ".git"), filepath.Join(repo, "sub"), worktree, filepath.Join(detached, ".git")} {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+	}
+	files := map[string]string{
+		filepath.Join(repo, ".git", "HEAD"):     "ref: refs/heads/feature/status\n",
+		filepath.Join(detached, ".git", "HEAD"): "0123456789abcdef0123456789abcdef01234567\n",
+		filepath.Join(worktree, ".git"):         "gitdir: ../repo/.git\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{filepath.Join(repo, "sub"), "feature/status"},
+		{worktree, "feature/status"},
+		{detached, "0123456"},
+		{root, ""},
+	}
+	for _, tt := range tests {
+		if got := Branch(tt.dir); got != tt.want {
+			t.Errorf("Branch(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/db/sqlc"
+)
+
+// PruneSessions deletes the sessions last updated before cutoff, with their
+// messages, and returns them oldest first. With dryRun set nothing is deleted.
+func PruneSessions(ctx context.Context, conn *sql.DB, cutoff time.Time, dryRun bool) ([]*Session, error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit.
+
+	queries := sqlc.New(tx)
+	dbSessions, err := queries.ListSessionsUpdatedBefore(ctx, cutoff.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("listing old sessions: %w", err)
+	}
+	sessions := make([]*Session, len(dbSessions))
+	for i, dbs := range dbSessions {
+		sessions[i] = sessionFromDB(dbs)
+	}
+	if dryRun || len(sessions) == 0 {
+		return sessions, nil
+	}
+
+	// Messages go with their sessions through ON DELETE CASCADE.
+	if _, err := queries.DeleteSessionsUpdatedBefore(ctx, cutoff.UnixMilli()); err != nil {
+		return nil, fmt.Errorf("deleting old sessions: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+	return sessions, nil
+}
@@ -15,6 +15,7 @@ type Service struct {
 	store   Store
 	broker  *pubsub.Broker[events.SessionEvent]
 	current string
+	project string // Project root new sessions belong to
 	mu      sync.RWMutex
 }
 
@@ -30,7 +31,7 @@ func NewService(store Store, broker *pubsub.Broker[events.SessionEvent]) *Servic
 func (s *Service) Create(ctx context.Context, title string) (*Session, error) {
 	id := uuid.New().String()
 
-	session, err := s.store.Create(ctx, id, title)
+	session, err := s.store.Create(ctx, id, title, s.Project())
 	if err != nil {
 		return nil, err
 	}
@@ -57,9 +58,25 @@ func (s *Service) List(ctx context.Context) ([]*Session, error) {
 	return s.store.List(ctx)
 }
 
-// ListWithPreview returns all sessions with first message preview.
-func (s *Service) ListWithPreview(ctx context.Context) ([]*SessionWithPreview, error) {
-	return s.store.ListWithPreview(ctx)
+// SetProject sets the project root that new sessions belong to and that
+// listings are limited to.
+func (s *Service) SetProject(project string) {
+	s.mu.Lock()
+	s.project = project
+	s.mu.Unlock()
+}
+
+// Project returns the project root set with SetProject.
+func (s *Service) Project() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.project
+}
+
+// ListWithPreview returns the sessions of the current project with first
+// message preview, or those of every project when allProjects is set.
+func (s *Service) ListWithPreview(ctx context.Context, allProjects bool) ([]*SessionWithPreview, error) {
+	return s.store.ListWithPreview(ctx, s.scope(allProjects))
 }
 
 // Search searches sessions by title keyword.
@@ -67,9 +84,18 @@ func (s *Service) Search(ctx context.Context, keyword string) ([]*Session, error
 	return s.store.Search(ctx, keyword)
 }
 
-// SearchWithPreview searches sessions with first message preview.
-func (s *Service) SearchWithPreview(ctx context.Context, keyword string) ([]*SessionWithPreview, error) {
-	return s.store.SearchWithPreview(ctx, keyword)
+// SearchWithPreview searches sessions with first message preview, limited
+// as in ListWithPreview.
+func (s *Service) SearchWithPreview(ctx context.Context, keyword string, allProjects bool) ([]*SessionWithPreview, error) {
+	return s.store.SearchWithPreview(ctx, keyword, s.scope(allProjects))
+}
+
+// scope returns the project to limit listings to, or "" for all projects.
+func (s *Service) scope(allProjects bool) string {
+	if allProjects {
+		return ""
+	}
+	return s.Project()
 }
 
 // Current returns the current session, creating one if none exists.
@@ -140,7 +166,27 @@ func (s *Service) IncrementMessageCount(ctx context.Context, id string) error {
 	return s.store.IncrementMessageCount(ctx, id)
 }
 
+// AddMessageCount adds n to the message count for a session.
+func (s *Service) AddMessageCount(ctx context.Context, id string, n int) error {
+	return s.store.AddMessageCount(ctx, id, n)
+}
+
+// DecrementMessageCount decrements the message count for a session.
+func (s *Service) DecrementMessageCount(ctx context.Context, id string) error {
+	return s.store.DecrementMessageCount(ctx, id)
+}
+
 // SetSummaryMessage sets the summary message ID for a session.
 func (s *Service) SetSummaryMessage(ctx context.Context, sessionID, messageID string) error {
 	return s.store.SetSummaryMessage(ctx, sessionID, messageID)
 }
+
+// SetSystemPrompt sets the system prompt of a session; empty restores the default.
+func (s *Service) SetSystemPrompt(ctx context.Context, sessionID, prompt string) error {
+	return s.store.SetSystemPrompt(ctx, sessionID, prompt)
+}
+
+// SetWorkingDir sets the working directory of a session; empty restores the default.
+func (s *Service) SetWorkingDir(ctx context.Context, sessionID, dir string) error {
+	return s.store.SetWorkingDir(ctx, sessionID, dir)
+}
@@ -27,13 +27,14 @@ func NewSQLiteStore(db *sql.DB) *SQLiteStore {
 	}
 }
 
-// Create creates a new session with the given ID and title.
-func (s *SQLiteStore) Create(ctx context.Context, id, title string) (*Session, error) {
+// Create creates a new session with the given ID and title in project.
+func (s *SQLiteStore) Create(ctx context.Context, id, title, project string) (*Session, error) {
 	now := time.Now().UnixMilli()
 
 	dbSession, err := s.queries.CreateSession(ctx, sqlc.CreateSessionParams{
 		ID:        id,
 		Title:     title,
+		Project:   project,
 		CreatedAt: now,
 		UpdatedAt: now,
 	})
@@ -103,6 +104,20 @@ func (s *SQLiteStore) IncrementMessageCount(ctx context.Context, id string) erro
 	return nil
 }
 
+// AddMessageCount adds n to the message count for a session.
+func (s *SQLiteStore) AddMessageCount(ctx context.Context, id string, n int) error {
+	err := s.queries.AddSessionMessageCount(ctx, sqlc.AddSessionMessageCountParams{
+		MessageCount: int64(n),
+		UpdatedAt:    time.Now().UnixMilli(),
+		ID:           id,
+	})
+	if err != nil {
+		return fmt.Errorf("adding to message count: %w", err)
+	}
+
+	return nil
+}
+
 // DecrementMessageCount decrements the message count for a session.
 func (s *SQLiteStore) DecrementMessageCount(ctx context.Context, id string) error {
 	now := time.Now().UnixMilli()
@@ -134,6 +149,34 @@ func (s *SQLiteStore) SetSummaryMessage(ctx context.Context, sessionID, messageI
 	return nil
 }
 
+// SetSystemPrompt sets the system prompt of a session.
+func (s *SQLiteStore) SetSystemPrompt(ctx context.Context, sessionID, prompt string) error {
+	err := s.queries.SetSessionSystemPrompt(ctx, sqlc.SetSessionSystemPromptParams{
+		SystemPrompt: prompt,
+		UpdatedAt:    time.Now().UnixMilli(),
+		ID:           sessionID,
+	})
+	if err != nil {
+		return fmt.Errorf("setting system prompt: %w", err)
+	}
+
+	return nil
+}
+
+// SetWorkingDir sets the working directory of a session.
+func (s *SQLiteStore) SetWorkingDir(ctx context.Context, sessionID, dir string) error {
+	err := s.queries.SetSessionWorkingDir(ctx, sqlc.SetSessionWorkingDirParams{
+		WorkingDir: dir,
+		UpdatedAt:  time.Now().UnixMilli(),
+		ID:         sessionID,
+	})
+	if err != nil {
+		return fmt.Errorf("setting working directory: %w", err)
+	}
+
+	return nil
+}
+
 // Delete removes a session by ID.
 func (s *SQLiteStore) Delete(ctx context.Context, id string) error {
 	err := s.queries.DeleteSession(ctx, id)
@@ -144,11 +187,23 @@ func (s *SQLiteStore) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-// ListWithPreview returns all sessions with first message preview.
-func (s *SQLiteStore) ListWithPreview(ctx context.Context) ([]*SessionWithPreview, error) {
-	dbSessions, err := s.queries.ListSessionsWithPreview(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("listing sessions with preview: %w", err)
+// ListWithPreview returns the sessions of project, and those with no project,
+// with first message preview. An empty project lists all sessions.
+func (s *SQLiteStore) ListWithPreview(ctx context.Context, project string) ([]*SessionWithPreview, error) {
+	var dbSessions []sqlc.ListSessionsWithPreviewRow
+	if project == "" {
+		var err error
+		if dbSessions, err = s.queries.ListSessionsWithPreview(ctx); err != nil {
+			return nil, fmt.Errorf("listing sessions with preview: %w", err)
+		}
+	} else {
+		rows, err := s.queries.ListProjectSessionsWithPreview(ctx, project)
+		if err != nil {
+			return nil, fmt.Errorf("listing sessions with preview: %w", err)
+		}
+		for _, row := range rows {
+			dbSessions = append(dbSessions, sqlc.ListSessionsWithPreviewRow(row))
+		}
 	}
 
 	sessions := make([]*SessionWithPreview, len(dbSessions))
@@ -179,12 +234,26 @@ func (s *SQLiteStore) Search(ctx context.Context, keyword string) ([]*Session, e
 
 // SearchWithPreview searches sessions by title with first message preview.
 // Supports multi-word search: "bug auth" matches "Authentication Bug Fix".
-func (s *SQLiteStore) SearchWithPreview(ctx context.Context, keyword string) ([]*SessionWithPreview, error) {
+func (s *SQLiteStore) SearchWithPreview(ctx context.Context, keyword, project string) ([]*SessionWithPreview, error) {
 	// Preprocess keyword for multi-word search
 	searchTerm := prepareSearchTerm(keyword)
-	dbSessions, err := s.queries.SearchSessionsWithPreview(ctx, searchTerm)
-	if err != nil {
-		return nil, fmt.Errorf("searching sessions with preview: %w", err)
+	var dbSessions []sqlc.SearchSessionsWithPreviewRow
+	if project == "" {
+		var err error
+		if dbSessions, err = s.queries.SearchSessionsWithPreview(ctx, searchTerm); err != nil {
+			return nil, fmt.Errorf("searching sessions with preview: %w", err)
+		}
+	} else {
+		rows, err := s.queries.SearchProjectSessionsWithPreview(ctx, sqlc.SearchProjectSessionsWithPreviewParams{
+			Project: project,
+			Lower:   searchTerm,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("searching sessions with preview: %w", err)
+		}
+		for _, row := range rows {
+			dbSessions = append(dbSessions, sqlc.SearchSessionsWithPreviewRow(row))
+		}
 	}
 
 	sessions := make([]*SessionWithPreview, len(dbSessions))
@@ -220,6 +289,9 @@ func sessionFromDB(dbs sqlc.Session) *Session {
 		Title:            dbs.Title,
 		MessageCount:     int(dbs.MessageCount),
 		SummaryMessageID: summaryID,
+		Project:          dbs.Project,
+		SystemPrompt:     dbs.SystemPrompt,
+		WorkingDir:       dbs.WorkingDir,
 		CreatedAt:        time.UnixMilli(dbs.CreatedAt),
 		UpdatedAt:        time.UnixMilli(dbs.UpdatedAt),
 	}
@@ -233,6 +305,7 @@ type sessionPreviewData struct {
 	SummaryMessageID sql.NullString
 	CreatedAt        int64
 	UpdatedAt        int64
+	Project          string
 	FirstMessage     any
 }
 
@@ -258,6 +331,7 @@ func buildSessionWithPreview(data sessionPreviewData) *SessionWithPreview {
 			Title:            data.Title,
 			MessageCount:     int(data.MessageCount),
 			SummaryMessageID: summaryID,
+			Project:          data.Project,
 			CreatedAt:        time.UnixMilli(data.CreatedAt),
 			UpdatedAt:        time.UnixMilli(data.UpdatedAt),
 		},
@@ -274,6 +348,7 @@ func sessionWithPreviewFromDB(dbs sqlc.ListSessionsWithPreviewRow) *SessionWithP
 		SummaryMessageID: dbs.SummaryMessageID,
 		CreatedAt:        dbs.CreatedAt,
 		UpdatedAt:        dbs.UpdatedAt,
+		Project:          dbs.Project,
 		FirstMessage:     dbs.FirstMessage,
 	})
 }
@@ -287,6 +362,7 @@ func searchSessionWithPreviewFromDB(dbs sqlc.SearchSessionsWithPreviewRow) *Sess
 		SummaryMessageID: dbs.SummaryMessageID,
 		CreatedAt:        dbs.CreatedAt,
 		UpdatedAt:        dbs.UpdatedAt,
+		Project:          dbs.Project,
 		FirstMessage:     dbs.FirstMessage,
 	})
 }
@@ -29,7 +29,7 @@ func TestSQLiteStore_Create(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("creates session with ID and title", func(t *testing.T) {
-		session, err := store.Create(ctx, "test-id", "Test Session")
+		session, err := store.Create(ctx, "test-id", "Test Session", "")
 		if err != nil {
 			t.Fatalf("Create() error = %v", err)
 		}
@@ -52,12 +52,12 @@ func TestSQLiteStore_Create(t *testing.T) {
 	})
 
 	t.Run("fails on duplicate ID", func(t *testing.T) {
-		_, err := store.Create(ctx, "dup-id", "First")
+		_, err := store.Create(ctx, "dup-id", "First", "")
 		if err != nil {
 			t.Fatalf("first Create() error = %v", err)
 		}
 
-		_, err = store.Create(ctx, "dup-id", "Second")
+		_, err = store.Create(ctx, "dup-id", "Second", "")
 		if err == nil {
 			t.Error("expected error for duplicate ID, got nil")
 		}
@@ -70,7 +70,7 @@ func TestSQLiteStore_Get(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("returns existing session", func(t *testing.T) {
-		created, err := store.Create(ctx, "get-test", "Test Session")
+		created, err := store.Create(ctx, "get-test", "Test Session", "")
 		if err != nil {
 			t.Fatalf("Create() error = %v", err)
 		}
@@ -112,15 +112,15 @@ func TestSQLiteStore_List(t *testing.T) {
 	})
 
 	t.Run("returns sessions ordered by updated_at desc", func(t *testing.T) {
-		if _, err := store.Create(ctx, "list-1", "First"); err != nil {
+		if _, err := store.Create(ctx, "list-1", "First", ""); err != nil {
 			t.Fatalf("Create() error = %v", err)
 		}
 		time.Sleep(10 * time.Millisecond)
-		if _, err := store.Create(ctx, "list-2", "Second"); err != nil {
+		if _, err := store.Create(ctx, "list-2", "Second", ""); err != nil {
 			t.Fatalf("Create() error = %v", err)
 		}
 		time.Sleep(10 * time.Millisecond)
-		if _, err := store.Create(ctx, "list-3", "Third"); err != nil {
+		if _, err := store.Create(ctx, "list-3", "Third", ""); err != nil {
 			t.Fatalf("Create() error = %v", err)
 		}
 
@@ -149,7 +149,7 @@ func TestSQLiteStore_UpdateTitle(t *testing.T) {
 	store := NewSQLiteStore(database.Conn())
 	ctx := context.Background()
 
-	if _, err := store.Create(ctx, "update-title", "Original Title"); err != nil {
+	if _, err := store.Create(ctx, "update-title", "Original Title", ""); err != nil {
 		t.Fatalf("Create() error = %v", err)
 	}
 
@@ -172,7 +172,7 @@ func TestSQLiteStore_MessageCount(t *testing.T) {
 	store := NewSQLiteStore(database.Conn())
 	ctx := context.Background()
 
-	if _, err := store.Create(ctx, "msg-count", "Test"); err != nil {
+	if _, err := store.Create(ctx, "msg-count", "Test", ""); err != nil {
 		t.Fatalf("Create() error = %v", err)
 	}
 
@@ -217,7 +217,7 @@ func TestSQLiteStore_SetSummaryMessage(t *testing.T) {
 	store := NewSQLiteStore(database.Conn())
 	ctx := context.Background()
 
-	if _, err := store.Create(ctx, "summary-test", "Test"); err != nil {
+	if _, err := store.Create(ctx, "summary-test", "Test", ""); err != nil {
 		t.Fatalf("Create() error = %v", err)
 	}
 
@@ -235,12 +235,54 @@ func TestSQLiteStore_SetSummaryMessage(t *testing.T) {
 	}
 }
 
+func TestSQLiteStore_SetSystemPrompt(t *testing.T) {
+	database := setupTestDB(t)
+	store := NewSQLiteStore(database.Conn())
+	ctx := context.Background()
+
+	if _, err := store.Create(ctx, "prompt", "Test", ""); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := store.SetSystemPrompt(ctx, "prompt", "Answer in French."); err != nil {
+		t.Fatalf("SetSystemPrompt() error = %v", err)
+	}
+	session, err := store.Get(ctx, "prompt")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if session.SystemPrompt != "Answer in French." {
+		t.Errorf("SystemPrompt = %q, want %q", session.SystemPrompt, "Answer in French.")
+	}
+}
+
+func TestSQLiteStore_SetWorkingDir(t *testing.T) {
+	database := setupTestDB(t)
+	store := NewSQLiteStore(database.Conn())
+	ctx := context.Background()
+
+	if _, err := store.Create(ctx, "dir", "Test", "/repo"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := store.SetWorkingDir(ctx, "dir", "/other"); err != nil {
+		t.Fatalf("SetWorkingDir() error = %v", err)
+	}
+	session, err := store.Get(ctx, "dir")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if session.WorkingDir != "/other" || session.Project != "/repo" {
+		t.Errorf("WorkingDir = %q, Project = %q, want /other in /repo", session.WorkingDir, session.Project)
+	}
+}
+
 func TestSQLiteStore_Delete(t *testing.T) {
 	database := setupTestDB(t)
 	store := NewSQLiteStore(database.Conn())
 	ctx := context.Background()
 
-	if _, err := store.Create(ctx, "delete-test", "Test"); err != nil {
+	if _, err := store.Create(ctx, "delete-test", "Test", ""); err != nil {
 		t.Fatalf("Create() error = %v", err)
 	}
 
@@ -260,13 +302,13 @@ func TestSQLiteStore_Search(t *testing.T) {
 	store := NewSQLiteStore(database.Conn())
 	ctx := context.Background()
 
-	if _, err := store.Create(ctx, "s1", "Authentication Bug Fix"); err != nil {
+	if _, err := store.Create(ctx, "s1", "Authentication Bug Fix", ""); err != nil {
 		t.Fatalf("Create() error = %v", err)
 	}
-	if _, err := store.Create(ctx, "s2", "Add Login Feature"); err != nil {
+	if _, err := store.Create(ctx, "s2", "Add Login Feature", ""); err != nil {
 		t.Fatalf("Create() error = %v", err)
 	}
-	if _, err := store.Create(ctx, "s3", "Database Migration"); err != nil {
+	if _, err := store.Create(ctx, "s3", "Database Migration", ""); err != nil {
 		t.Fatalf("Create() error = %v", err)
 	}
 
@@ -378,3 +420,104 @@ func TestExtractTextFromParts(t *testing.T) {
 		})
 	}
 }
+
+func TestSQLiteStore_ProjectScope(t *testing.T) {
+	database := setupTestDB(t)
+	store := NewSQLiteStore(database.Conn())
+	ctx := context.Background()
+
+	for _, s := range []struct{ id, title, project string }{
+		{"app-1", "Fix login bug", "/src/app"},
+		{"app-2", "Add tests", "/src/app"},
+		{"lib-1", "Fix parser bug", "/src/lib"},
+		{"old-1", "Older bug hunt", ""},
+	} {
+		if _, err := store.Create(ctx, s.id, s.title, s.project); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	ids := func(sessions []*SessionWithPreview) map[string]bool {
+		got := make(map[string]bool, len(sessions))
+		for _, s := range sessions {
+			got[s.ID] = true
+		}
+		return got
+	}
+
+	t.Run("lists the project and older sessions", func(t *testing.T) {
+		sessions, err := store.ListWithPreview(ctx, "/src/app")
+		if err != nil {
+			t.Fatalf("ListWithPreview() error = %v", err)
+		}
+		got := ids(sessions)
+		if len(got) != 3 || !got["app-1"] || !got["app-2"] || !got["old-1"] {
+			t.Errorf("ListWithPreview() = %v, want app-1, app-2 and old-1", got)
+		}
+		for _, s := range sessions {
+			if s.ID == "app-1" && s.Project != "/src/app" {
+				t.Errorf("Project = %q, want /src/app", s.Project)
+			}
+		}
+	})
+
+	t.Run("lists all projects", func(t *testing.T) {
+		sessions, err := store.ListWithPreview(ctx, "")
+		if err != nil {
+			t.Fatalf("ListWithPreview() error = %v", err)
+		}
+		if len(sessions) != 4 {
+			t.Errorf("ListWithPreview() returned %d sessions, want 4", len(sessions))
+		}
+	})
+
+	t.Run("searches within the project", func(t *testing.T) {
+		sessions, err := store.SearchWithPreview(ctx, "bug", "/src/lib")
+		if err != nil {
+			t.Fatalf("SearchWithPreview() error = %v", err)
+		}
+		got := ids(sessions)
+		if len(got) != 2 || !got["lib-1"] || !got["old-1"] {
+			t.Errorf("SearchWithPreview() = %v, want lib-1 and old-1", got)
+		}
+	})
+}
+
+func TestPruneSessions(t *testing.T) {
+	database := setupTestDB(t)
+	conn := database.Conn()
+	store := NewSQLiteStore(conn)
+	ctx := context.Background()
+
+	for _, id := range []string{"old", "new"} {
+		if _, err := store.Create(ctx, id, id, ""); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	if _, err := conn.ExecContext(ctx, "UPDATE sessions SET updated_at = ? WHERE id = 'old'",
+		time.Now().Add(-100*24*time.Hour).UnixMilli()); err != nil {
+		t.Fatalf("aging session: %v", err)
+	}
+	cutoff := time.Now().Add(-90 * 24 * time.Hour)
+
+	pruned, err := PruneSessions(ctx, conn, cutoff, true)
+	if err != nil {
+		t.Fatalf("PruneSessions(dry run) error = %v", err)
+	}
+	if len(pruned) != 1 || pruned[0].ID != "old" {
+		t.Fatalf("PruneSessions(dry run) = %v, want the old session", pruned)
+	}
+	if _, err := store.Get(ctx, "old"); err != nil {
+		t.Errorf("dry run deleted the session: %v", err)
+	}
+
+	if _, err := PruneSessions(ctx, conn, cutoff, false); err != nil {
+		t.Fatalf("PruneSessions() error = %v", err)
+	}
+	if _, err := store.Get(ctx, "old"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(old) error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Get(ctx, "new"); err != nil {
+		t.Errorf("Get(new) error = %v", err)
+	}
+}
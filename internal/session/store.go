@@ -12,6 +12,9 @@ type Session struct {
 	Title            string
 	MessageCount     int
 	SummaryMessageID string
+	Project          string // Project root the session was started in; empty for older sessions
+	SystemPrompt     string // Replaces the default system prompt; empty uses the default
+	WorkingDir       string // Directory tools run in; empty uses the one cdd was started in
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 }
@@ -26,8 +29,8 @@ type SessionWithPreview struct {
 
 // Store defines the interface for session persistence.
 type Store interface {
-	// Create creates a new session with the given title.
-	Create(ctx context.Context, id, title string) (*Session, error)
+	// Create creates a new session with the given title in project.
+	Create(ctx context.Context, id, title, project string) (*Session, error)
 
 	// Get retrieves a session by ID.
 	Get(ctx context.Context, id string) (*Session, error)
@@ -35,14 +38,16 @@ type Store interface {
 	// List returns all sessions ordered by updated_at descending.
 	List(ctx context.Context) ([]*Session, error)
 
-	// ListWithPreview returns all sessions with first message preview.
-	ListWithPreview(ctx context.Context) ([]*SessionWithPreview, error)
+	// ListWithPreview returns the sessions of project, and those with no
+	// project, with first message preview. An empty project lists all sessions.
+	ListWithPreview(ctx context.Context, project string) ([]*SessionWithPreview, error)
 
 	// Search searches sessions by title keyword.
 	Search(ctx context.Context, keyword string) ([]*Session, error)
 
-	// SearchWithPreview searches sessions by title with first message preview.
-	SearchWithPreview(ctx context.Context, keyword string) ([]*SessionWithPreview, error)
+	// SearchWithPreview searches sessions by title with first message preview,
+	// limited to project as in ListWithPreview.
+	SearchWithPreview(ctx context.Context, keyword, project string) ([]*SessionWithPreview, error)
 
 	// UpdateTitle updates the title of a session.
 	UpdateTitle(ctx context.Context, id, title string) error
@@ -50,12 +55,21 @@ type Store interface {
 	// IncrementMessageCount increments the message count for a session.
 	IncrementMessageCount(ctx context.Context, id string) error
 
+	// AddMessageCount adds n to the message count for a session.
+	AddMessageCount(ctx context.Context, id string, n int) error
+
 	// DecrementMessageCount decrements the message count for a session.
 	DecrementMessageCount(ctx context.Context, id string) error
 
 	// SetSummaryMessage sets the summary message ID for a session.
 	SetSummaryMessage(ctx context.Context, sessionID, messageID string) error
 
+	// SetSystemPrompt sets the system prompt of a session; empty restores the default.
+	SetSystemPrompt(ctx context.Context, sessionID, prompt string) error
+
+	// SetWorkingDir sets the working directory of a session; empty restores the default.
+	SetWorkingDir(ctx context.Context, sessionID, dir string) error
+
 	// Delete removes a session by ID.
 	Delete(ctx context.Context, id string) error
 }
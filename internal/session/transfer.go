@@ -0,0 +1,238 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/guilhermegouw/cdd/internal/db/sqlc"
+	"github.com/guilhermegouw/cdd/internal/message"
+)
+
+// ExportVersion is the current version of the session export format.
+const ExportVersion = 1
+
+// ErrSessionExists is returned when importing a session whose ID is already in the store.
+var ErrSessionExists = errors.New("session already exists")
+
+// Export is the portable JSON representation of a session and its messages.
+type Export struct {
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Session    ExportedSession   `json:"session"`
+	Messages   []ExportedMessage `json:"messages"`
+}
+
+// ExportedSession holds the session fields included in an export.
+type ExportedSession struct {
+	ID               string    `json:"id"`
+	Title            string    `json:"title"`
+	SummaryMessageID string    `json:"summary_message_id,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// ExportedMessage holds a single message in an export.
+type ExportedMessage struct {
+	ID        string         `json:"id"`
+	Role      message.Role   `json:"role"`
+	Parts     []message.Part `json:"parts"`
+	Model     string         `json:"model,omitempty"`
+	Provider  string         `json:"provider,omitempty"`
+	IsSummary bool           `json:"is_summary,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// ImportOptions controls how an export is imported.
+type ImportOptions struct {
+	// NewIDs assigns fresh session and message IDs so an export can be
+	// imported alongside the session it came from.
+	NewIDs bool
+
+	// Project is the project root the imported session belongs to. Exports
+	// do not carry one, since paths rarely match across machines.
+	Project string
+}
+
+// ExportSession reads a session and its messages, in order, from the database.
+func ExportSession(ctx context.Context, conn *sql.DB, id string) (*Export, error) {
+	sess, err := NewSQLiteStore(conn).Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs, err := message.NewSQLiteStore(conn).GetBySession(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting messages: %w", err)
+	}
+
+	export := &Export{
+		Version:    ExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Session: ExportedSession{
+			ID:               sess.ID,
+			Title:            sess.Title,
+			SummaryMessageID: sess.SummaryMessageID,
+			CreatedAt:        sess.CreatedAt,
+			UpdatedAt:        sess.UpdatedAt,
+		},
+		Messages: make([]ExportedMessage, 0, len(msgs)),
+	}
+	for _, msg := range msgs {
+		export.Messages = append(export.Messages, ExportedMessage{
+			ID:        msg.ID,
+			Role:      msg.Role,
+			Parts:     msg.Parts,
+			Model:     msg.Model,
+			Provider:  msg.Provider,
+			IsSummary: msg.IsSummary,
+			CreatedAt: msg.CreatedAt,
+			UpdatedAt: msg.UpdatedAt,
+		})
+	}
+
+	return export, nil
+}
+
+// ImportSession recreates an exported session and its messages in a single transaction.
+// Message order is preserved even when timestamps collide, since messages are read back
+// ordered by creation time.
+func ImportSession(ctx context.Context, conn *sql.DB, export *Export, opts ImportOptions) (*Session, error) {
+	if err := export.Validate(); err != nil {
+		return nil, err
+	}
+
+	sessionID := export.Session.ID
+	messageIDs := make(map[string]string, len(export.Messages))
+	for _, msg := range export.Messages {
+		messageIDs[msg.ID] = msg.ID
+	}
+	if opts.NewIDs {
+		sessionID = uuid.New().String()
+		for oldID := range messageIDs {
+			messageIDs[oldID] = uuid.New().String()
+		}
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit.
+
+	queries := sqlc.New(tx)
+
+	if _, err := queries.GetSession(ctx, sessionID); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSessionExists, sessionID)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("checking session: %w", err)
+	}
+
+	summaryID := sql.NullString{}
+	if id, ok := messageIDs[export.Session.SummaryMessageID]; ok {
+		summaryID = sql.NullString{String: id, Valid: true}
+	}
+
+	createdAt := timeOrNow(export.Session.CreatedAt)
+	dbSession, err := queries.ImportSession(ctx, sqlc.ImportSessionParams{
+		ID:               sessionID,
+		Title:            export.Session.Title,
+		Project:          opts.Project,
+		MessageCount:     int64(len(export.Messages)),
+		SummaryMessageID: summaryID,
+		CreatedAt:        createdAt.UnixMilli(),
+		UpdatedAt:        max(timeOrNow(export.Session.UpdatedAt).UnixMilli(), createdAt.UnixMilli()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating session: %w", err)
+	}
+
+	var prevCreated int64
+	for i := range export.Messages {
+		msg := &export.Messages[i]
+
+		parts := msg.Parts
+		if parts == nil {
+			parts = []message.Part{}
+		}
+		partsJSON, err := json.Marshal(parts)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling parts: %w", err)
+		}
+
+		// Keep creation times strictly increasing so the original order survives.
+		created := timeOrNow(msg.CreatedAt).UnixMilli()
+		if i > 0 && created <= prevCreated {
+			created = prevCreated + 1
+		}
+		prevCreated = created
+
+		isSummary := int64(0)
+		if msg.IsSummary {
+			isSummary = 1
+		}
+
+		if _, err := queries.CreateMessage(ctx, sqlc.CreateMessageParams{
+			ID:        messageIDs[msg.ID],
+			SessionID: sessionID,
+			Role:      string(msg.Role),
+			Parts:     string(partsJSON),
+			Model:     sql.NullString{String: msg.Model, Valid: msg.Model != ""},
+			Provider:  sql.NullString{String: msg.Provider, Valid: msg.Provider != ""},
+			IsSummary: isSummary,
+			CreatedAt: created,
+			UpdatedAt: max(timeOrNow(msg.UpdatedAt).UnixMilli(), created),
+		}); err != nil {
+			return nil, fmt.Errorf("creating message %d: %w", i+1, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return sessionFromDB(dbSession), nil
+}
+
+// Validate checks that an export can be imported.
+func (e *Export) Validate() error {
+	if e.Version < 1 || e.Version > ExportVersion {
+		return fmt.Errorf("unsupported export version %d (supported: %d)", e.Version, ExportVersion)
+	}
+	if e.Session.ID == "" {
+		return fmt.Errorf("export is missing a session ID")
+	}
+
+	seen := make(map[string]bool, len(e.Messages))
+	for i := range e.Messages {
+		msg := &e.Messages[i]
+		if msg.ID == "" {
+			return fmt.Errorf("message %d is missing an ID", i+1)
+		}
+		if seen[msg.ID] {
+			return fmt.Errorf("message %d has duplicate ID %q", i+1, msg.ID)
+		}
+		seen[msg.ID] = true
+
+		switch msg.Role {
+		case message.RoleUser, message.RoleAssistant, message.RoleSystem, message.RoleTool:
+		default:
+			return fmt.Errorf("message %d has invalid role %q", i+1, msg.Role)
+		}
+	}
+	return nil
+}
+
+// timeOrNow returns t, or the current time if t is zero.
+func timeOrNow(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Now()
+	}
+	return t
+}
@@ -0,0 +1,217 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/message"
+)
+
+// seedSession creates a session with a user prompt, a tool call, its result, and a reply.
+func seedSession(t *testing.T, conn *sql.DB) time.Time {
+	t.Helper()
+	ctx := context.Background()
+	store := NewSQLiteStore(conn)
+	msgStore := message.NewSQLiteStore(conn)
+
+	if _, err := store.Create(ctx, "sess-1", "Exported Session", "/src/app"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	base := time.UnixMilli(time.Now().Add(-time.Hour).UnixMilli())
+	msgs := []*message.Message{
+		{ID: "m1", Role: message.RoleUser, Parts: []message.Part{message.NewTextPart("list files")}},
+		{ID: "m2", Role: message.RoleAssistant, Model: "gpt-4o", Provider: "openai", Parts: []message.Part{
+			message.NewTextPart("Listing."),
+			message.NewToolCallPart("call-1", "bash", `{"command":"ls"}`),
+		}},
+		{ID: "m3", Role: message.RoleTool, Parts: []message.Part{
+			message.NewToolResultPart("call-1", "bash", "main.go", false),
+		}},
+		{ID: "m4", Role: message.RoleAssistant, Parts: []message.Part{message.NewTextPart("One file.")}},
+	}
+	for i, msg := range msgs {
+		msg.SessionID = "sess-1"
+		msg.CreatedAt = base.Add(time.Duration(i) * time.Second)
+		if err := msgStore.Create(ctx, msg); err != nil {
+			t.Fatalf("message Create() error = %v", err)
+		}
+	}
+	return base
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := setupTestDB(t)
+	base := seedSession(t, src.Conn())
+
+	export, err := ExportSession(ctx, src.Conn(), "sess-1")
+	if err != nil {
+		t.Fatalf("ExportSession() error = %v", err)
+	}
+
+	// Round trip through JSON as the CLI does.
+	data, err := json.Marshal(export)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var decoded Export
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	dst := setupTestDB(t)
+	sess, err := ImportSession(ctx, dst.Conn(), &decoded, ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportSession() error = %v", err)
+	}
+	if sess.ID != "sess-1" || sess.Title != "Exported Session" || sess.MessageCount != 4 {
+		t.Errorf("unexpected session %+v", sess)
+	}
+
+	msgs, err := message.NewSQLiteStore(dst.Conn()).GetBySession(ctx, "sess-1")
+	if err != nil {
+		t.Fatalf("GetBySession() error = %v", err)
+	}
+	if len(msgs) != 4 {
+		t.Fatalf("got %d messages, want 4", len(msgs))
+	}
+
+	wantRoles := []message.Role{message.RoleUser, message.RoleAssistant, message.RoleTool, message.RoleAssistant}
+	for i, msg := range msgs {
+		if msg.Role != wantRoles[i] {
+			t.Errorf("message %d role = %q, want %q", i, msg.Role, wantRoles[i])
+		}
+		if want := base.Add(time.Duration(i) * time.Second); !msg.CreatedAt.Equal(want) {
+			t.Errorf("message %d CreatedAt = %v, want %v", i, msg.CreatedAt, want)
+		}
+	}
+
+	calls := msgs[1].ToolCalls()
+	if len(calls) != 1 || calls[0].ID != "call-1" || calls[0].Input != `{"command":"ls"}` {
+		t.Errorf("tool call not preserved: %+v", calls)
+	}
+	if msgs[1].Model != "gpt-4o" || msgs[1].Provider != "openai" {
+		t.Errorf("model/provider not preserved: %q/%q", msgs[1].Model, msgs[1].Provider)
+	}
+	results := msgs[2].ToolResults()
+	if len(results) != 1 || results[0].ToolCallID != "call-1" || results[0].Content != "main.go" {
+		t.Errorf("tool result not preserved: %+v", results)
+	}
+}
+
+func TestImportSession_Existing(t *testing.T) {
+	ctx := context.Background()
+	database := setupTestDB(t)
+	seedSession(t, database.Conn())
+
+	export, err := ExportSession(ctx, database.Conn(), "sess-1")
+	if err != nil {
+		t.Fatalf("ExportSession() error = %v", err)
+	}
+
+	t.Run("rejects duplicate session", func(t *testing.T) {
+		_, err := ImportSession(ctx, database.Conn(), export, ImportOptions{})
+		if !errors.Is(err, ErrSessionExists) {
+			t.Errorf("ImportSession() error = %v, want ErrSessionExists", err)
+		}
+	})
+
+	t.Run("imports a copy with new IDs", func(t *testing.T) {
+		export.Session.SummaryMessageID = "m4"
+
+		sess, err := ImportSession(ctx, database.Conn(), export, ImportOptions{NewIDs: true, Project: "/src/other"})
+		if err != nil {
+			t.Fatalf("ImportSession() error = %v", err)
+		}
+		if sess.ID == "sess-1" {
+			t.Error("expected a new session ID")
+		}
+		if sess.Project != "/src/other" {
+			t.Errorf("Project = %q, want /src/other", sess.Project)
+		}
+
+		msgs, err := message.NewSQLiteStore(database.Conn()).GetBySession(ctx, sess.ID)
+		if err != nil {
+			t.Fatalf("GetBySession() error = %v", err)
+		}
+		if len(msgs) != 4 {
+			t.Fatalf("got %d messages, want 4", len(msgs))
+		}
+		for _, msg := range msgs {
+			if msg.ID == "m1" || msg.ID == "m2" || msg.ID == "m3" || msg.ID == "m4" {
+				t.Errorf("message kept original ID %q", msg.ID)
+			}
+		}
+		if sess.SummaryMessageID != msgs[3].ID {
+			t.Errorf("SummaryMessageID = %q, want remapped %q", sess.SummaryMessageID, msgs[3].ID)
+		}
+	})
+}
+
+func TestImportSession_PreservesOrderWithEqualTimestamps(t *testing.T) {
+	ctx := context.Background()
+	database := setupTestDB(t)
+	ts := time.Now().Add(-time.Minute)
+
+	export := &Export{
+		Version: ExportVersion,
+		Session: ExportedSession{ID: "same-time", Title: "Same time"},
+		Messages: []ExportedMessage{
+			{ID: "a", Role: message.RoleUser, CreatedAt: ts},
+			{ID: "b", Role: message.RoleAssistant, CreatedAt: ts},
+			{ID: "c", Role: message.RoleUser, CreatedAt: ts},
+		},
+	}
+	if _, err := ImportSession(ctx, database.Conn(), export, ImportOptions{}); err != nil {
+		t.Fatalf("ImportSession() error = %v", err)
+	}
+
+	msgs, err := message.NewSQLiteStore(database.Conn()).GetBySession(ctx, "same-time")
+	if err != nil {
+		t.Fatalf("GetBySession() error = %v", err)
+	}
+	for i, want := range []string{"a", "b", "c"} {
+		if msgs[i].ID != want {
+			t.Errorf("message %d = %q, want %q", i, msgs[i].ID, want)
+		}
+	}
+}
+
+func TestExport_Validate(t *testing.T) {
+	valid := func() *Export {
+		return &Export{
+			Version:  ExportVersion,
+			Session:  ExportedSession{ID: "s"},
+			Messages: []ExportedMessage{{ID: "m", Role: message.RoleUser}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*Export)
+	}{
+		{"unsupported version", func(e *Export) { e.Version = ExportVersion + 1 }},
+		{"missing session ID", func(e *Export) { e.Session.ID = "" }},
+		{"missing message ID", func(e *Export) { e.Messages[0].ID = "" }},
+		{"duplicate message ID", func(e *Export) { e.Messages = append(e.Messages, e.Messages[0]) }},
+		{"invalid role", func(e *Export) { e.Messages[0].Role = "robot" }},
+	}
+
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("Validate() on valid export error = %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			export := valid()
+			tt.mutate(export)
+			if err := export.Validate(); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
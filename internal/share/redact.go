@@ -0,0 +1,55 @@
+package share
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces every secret removed from a shared transcript.
+const Redacted = "[REDACTED]"
+
+// secretPatterns match credentials by their shape: well-known key formats
+// and private keys.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`),                                         // OpenAI and Anthropic
+	regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{30,}|github_pat_[A-Za-z0-9_]{30,})`),   // GitHub
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),                                   // AWS access key IDs
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`),                                       // Google
+	regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`),                                 // Slack
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`), // JWTs
+}
+
+// assignedSecret matches a value given to a key whose name suggests a
+// credential, as in API_KEY=... or "password": "...". The key is kept.
+var assignedSecret = regexp.MustCompile(`(?i)((?:api[_-]?key|secret|token|password|passwd|authorization)["']?\s*[:=]\s*["']?(?:bearer\s+)?)([^\s"'` + "`" + `,;]{8,})`)
+
+// bearerToken matches the credential of a Bearer authorization header.
+var bearerToken = regexp.MustCompile(`(?i)(\bbearer\s+)([A-Za-z0-9._~+/-]{16,}=*)`)
+
+// Redact replaces the known secrets, such as the configured API keys, and
+// anything shaped like a credential in text with Redacted.
+func Redact(text string, known []string) string {
+	for _, secret := range known {
+		if secret != "" {
+			text = strings.ReplaceAll(text, secret, Redacted)
+		}
+	}
+	for _, pattern := range secretPatterns {
+		text = pattern.ReplaceAllString(text, Redacted)
+	}
+	text = assignedSecret.ReplaceAllStringFunc(text, keepPrefix(assignedSecret))
+	return bearerToken.ReplaceAllStringFunc(text, keepPrefix(bearerToken))
+}
+
+// keepPrefix returns a replacer that keeps the first group of a match and
+// redacts the second, unless it already was.
+func keepPrefix(re *regexp.Regexp) func(string) string {
+	return func(match string) string {
+		groups := re.FindStringSubmatch(match)
+		if groups[2] == Redacted || strings.HasPrefix(groups[2], Redacted) {
+			return match
+		}
+		return groups[1] + Redacted
+	}
+}
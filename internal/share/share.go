@@ -0,0 +1,240 @@
+// Package share publishes conversation transcripts to services that hand
+// back a link, such as GitHub Gists or a paste bin.
+package share
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+// Share targets.
+const (
+	TargetGist  = "gist"
+	TargetPaste = "paste"
+)
+
+const (
+	defaultGitHubURL = "https://api.github.com"
+	// publishTimeout bounds one upload.
+	publishTimeout = 30 * time.Second
+	// maxResponse is the most of a response body read.
+	maxResponse = 64 * 1024
+)
+
+// ErrNotConfigured is returned by New when no share target is set up.
+var ErrNotConfigured = errors.New("sharing is not configured: set integrations.github.token (or GITHUB_TOKEN) or integrations.paste.url in cdd.json")
+
+// Document is a transcript to publish.
+type Document struct {
+	Filename    string // e.g. session-1a2b3c4d.md
+	Description string
+	Content     string
+}
+
+// Publisher uploads documents and returns where they can be read.
+type Publisher interface {
+	// Name describes the service, e.g. "GitHub Gist".
+	Name() string
+
+	// Publish uploads doc and returns its URL.
+	Publish(ctx context.Context, doc Document) (string, error)
+}
+
+// New creates the publisher configured in cfg, which may be nil. Without an
+// explicit integrations.share, a configured paste service is used when there
+// is no GitHub token.
+func New(cfg *config.IntegrationsConfig) (Publisher, error) {
+	if cfg == nil {
+		cfg = &config.IntegrationsConfig{}
+	}
+	target := cfg.Share
+	if target == "" {
+		target = TargetGist
+		if cfg.Paste != nil && (cfg.GitHub == nil || cfg.GitHub.Token == "") {
+			target = TargetPaste
+		}
+	}
+
+	resolver := config.NewResolver()
+	client := &http.Client{Timeout: publishTimeout}
+	switch target {
+	case TargetGist:
+		gh := cfg.GitHub
+		if gh == nil {
+			gh = &config.GitHubIntegration{}
+		}
+		token, err := resolver.Resolve(gh.Token)
+		if err != nil {
+			return nil, fmt.Errorf("resolving integrations.github.token: %w", err)
+		}
+		if token == "" {
+			token = os.Getenv("GITHUB_TOKEN")
+		}
+		if token == "" {
+			return nil, ErrNotConfigured
+		}
+		p := &gistPublisher{baseURL: strings.TrimRight(gh.BaseURL, "/"), token: token, public: gh.Public, client: client}
+		if p.baseURL == "" {
+			p.baseURL = defaultGitHubURL
+		}
+		return p, nil
+	case TargetPaste:
+		if cfg.Paste == nil || cfg.Paste.URL == "" {
+			return nil, ErrNotConfigured
+		}
+		headers := make(map[string]string, len(cfg.Paste.Headers))
+		for name, value := range cfg.Paste.Headers {
+			resolved, err := resolver.Resolve(value)
+			if err != nil {
+				return nil, fmt.Errorf("resolving integrations.paste.headers.%s: %w", name, err)
+			}
+			headers[name] = resolved
+		}
+		return &pastePublisher{url: cfg.Paste.URL, headers: headers, formField: cfg.Paste.FormField, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown integrations.share %q (want %q or %q)", target, TargetGist, TargetPaste)
+	}
+}
+
+// gistPublisher creates a gist through the GitHub REST API.
+type gistPublisher struct {
+	baseURL string
+	token   string
+	public  bool
+	client  *http.Client
+}
+
+func (p *gistPublisher) Name() string {
+	return "GitHub Gist"
+}
+
+func (p *gistPublisher) Publish(ctx context.Context, doc Document) (string, error) {
+	body, err := json.Marshal(map[string]any{
+		"description": doc.Description,
+		"public":      p.public,
+		"files":       map[string]any{doc.Filename: map[string]string{"content": doc.Content}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("encoding gist: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/gists", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.token)
+
+	data, err := do(p.client, req)
+	if err != nil {
+		return "", fmt.Errorf("creating gist: %w", err)
+	}
+	var result struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil || result.HTMLURL == "" {
+		return "", fmt.Errorf("creating gist: no html_url in the response")
+	}
+	return result.HTMLURL, nil
+}
+
+// pastePublisher posts the document to a paste service.
+type pastePublisher struct {
+	url       string
+	headers   map[string]string
+	formField string
+	client    *http.Client
+}
+
+func (p *pastePublisher) Name() string {
+	return "the paste service"
+}
+
+func (p *pastePublisher) Publish(ctx context.Context, doc Document) (string, error) {
+	body := bytes.NewBufferString(doc.Content)
+	contentType := "text/markdown; charset=utf-8"
+	if p.formField != "" {
+		body = &bytes.Buffer{}
+		form := multipart.NewWriter(body)
+		part, err := form.CreateFormFile(p.formField, doc.Filename)
+		if err != nil {
+			return "", fmt.Errorf("encoding form: %w", err)
+		}
+		if _, err := io.WriteString(part, doc.Content); err != nil {
+			return "", fmt.Errorf("encoding form: %w", err)
+		}
+		if err := form.Close(); err != nil {
+			return "", fmt.Errorf("encoding form: %w", err)
+		}
+		contentType = form.FormDataContentType()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, body)
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	for name, value := range p.headers {
+		req.Header.Set(name, value)
+	}
+
+	data, err := do(p.client, req)
+	if err != nil {
+		return "", fmt.Errorf("uploading paste: %w", err)
+	}
+	url := pasteURL(data)
+	if url == "" {
+		return "", fmt.Errorf("uploading paste: no URL in the response")
+	}
+	return url, nil
+}
+
+// pasteURL finds the link in a paste service's response: a JSON "url" field,
+// or the first line of a plain text body.
+func pasteURL(data []byte) string {
+	var result struct {
+		URL string `json:"url"`
+	}
+	if json.Unmarshal(data, &result) == nil {
+		return result.URL
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "http://") && !strings.HasPrefix(line, "https://") {
+		return ""
+	}
+	return line
+}
+
+// do sends req and returns the body of a successful response.
+func do(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // Wrapped by the caller
+	}
+	defer resp.Body.Close() //nolint:errcheck // Response body
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail := strings.TrimSpace(string(data))
+		if len(detail) > 512 { //nolint:mnd // Enough to show the error message
+			detail = detail[:512]
+		}
+		return nil, fmt.Errorf("%s: %s", resp.Status, detail)
+	}
+	return data, nil
+}
@@ -0,0 +1,92 @@
+// Package telemetry exports OpenTelemetry traces of agent runs. Export is off
+// unless options.telemetry.endpoint is set; until Setup installs a provider,
+// spans go to the global no-op tracer and cost next to nothing.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+const (
+	instrumentationName = "github.com/guilhermegouw/cdd"
+	defaultServiceName  = "cdd"
+)
+
+// Span attributes shared by the instrumented packages.
+const (
+	SessionID  = attribute.Key("cdd.session.id")
+	MessageID  = attribute.Key("cdd.message.id")
+	ToolName   = attribute.Key("cdd.tool.name")
+	ToolCallID = attribute.Key("cdd.tool.call_id")
+)
+
+// Tracer returns the tracer used for cdd's spans.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup starts exporting traces over OTLP/HTTP as configured by opts and
+// returns a function that flushes pending spans and stops the exporter. With
+// nil opts it does nothing.
+func Setup(ctx context.Context, opts *config.TelemetryOptions, version string) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	if opts == nil || opts.Endpoint == "" {
+		return noop, nil
+	}
+
+	exporterOpts := []otlptracehttp.Option{otlptracehttp.WithEndpointURL(opts.Endpoint)}
+	if len(opts.Headers) > 0 {
+		resolver := config.NewResolver()
+		headers := make(map[string]string, len(opts.Headers))
+		for name, value := range opts.Headers {
+			resolved, err := resolver.Resolve(value)
+			if err != nil {
+				return noop, fmt.Errorf("resolving telemetry header %s: %w", name, err)
+			}
+			headers[name] = resolved
+		}
+		exporterOpts = append(exporterOpts, otlptracehttp.WithHeaders(headers))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, exporterOpts...)
+	if err != nil {
+		return noop, fmt.Errorf("creating trace exporter: %w", err)
+	}
+
+	serviceName := opts.ServiceName
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version),
+	)
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// End records err on span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
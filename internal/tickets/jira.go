@@ -0,0 +1,91 @@
+package tickets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// jiraTracker reads issues through the Jira REST API, version 2, which
+// returns descriptions as wiki markup rather than document trees.
+type jiraTracker struct {
+	baseURL string
+	email   string
+	token   string
+	acField string
+	client  *http.Client
+}
+
+func (t *jiraTracker) name() string {
+	return TrackerJira
+}
+
+func (t *jiraTracker) fetch(ctx context.Context, id string) (*Ticket, error) {
+	fields := "summary,description,status,issuetype"
+	if t.acField != "" {
+		fields += "," + t.acField
+	}
+	endpoint := fmt.Sprintf("%s/rest/api/2/issue/%s?fields=%s", t.baseURL, url.PathEscape(id), url.QueryEscape(fields))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if t.email != "" {
+		req.SetBasicAuth(t.email, t.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+
+	data, err := do(t.client, req)
+	if err != nil {
+		return nil, err
+	}
+	var issue struct {
+		Key    string                     `json:"key"`
+		Fields map[string]json.RawMessage `json:"fields"`
+	}
+	if err := json.Unmarshal(data, &issue); err != nil {
+		return nil, fmt.Errorf("decoding issue: %w", err)
+	}
+
+	ticket := &Ticket{
+		ID:          issue.Key,
+		Tracker:     TrackerJira,
+		Title:       jiraText(issue.Fields["summary"]),
+		Type:        jiraName(issue.Fields["issuetype"]),
+		Status:      jiraName(issue.Fields["status"]),
+		URL:         t.baseURL + "/browse/" + issue.Key,
+		Description: jiraText(issue.Fields["description"]),
+	}
+	if t.acField != "" {
+		ticket.AcceptanceCriteria = jiraText(issue.Fields[t.acField])
+	}
+	if ticket.AcceptanceCriteria == "" {
+		ticket.Description, ticket.AcceptanceCriteria = splitCriteria(ticket.Description)
+	}
+	return ticket, nil
+}
+
+// jiraText returns a text field, or "" when it is empty or not text.
+func jiraText(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) != nil {
+		return ""
+	}
+	return strings.TrimSpace(s)
+}
+
+// jiraName returns the name of a field such as status or issuetype.
+func jiraName(raw json.RawMessage) string {
+	var v struct {
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(raw, &v) != nil {
+		return ""
+	}
+	return v.Name
+}
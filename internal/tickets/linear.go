@@ -0,0 +1,84 @@
+package tickets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// linearIssueQuery looks an issue up by its identifier, e.g. ENG-123.
+const linearIssueQuery = `query Issue($id: String!) {
+  issue(id: $id) { identifier title description url state { name } }
+}`
+
+// linearTracker reads issues through Linear's GraphQL API.
+type linearTracker struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+func (t *linearTracker) name() string {
+	return TrackerLinear
+}
+
+func (t *linearTracker) fetch(ctx context.Context, id string) (*Ticket, error) {
+	body, err := json.Marshal(map[string]any{"query": linearIssueQuery, "variables": map[string]string{"id": id}})
+	if err != nil {
+		return nil, fmt.Errorf("encoding query: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/graphql", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Personal API keys are sent as is; OAuth tokens need the scheme.
+	req.Header.Set("Authorization", t.token)
+
+	data, err := do(t.client, req)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Data struct {
+			Issue *struct {
+				Identifier  string `json:"identifier"`
+				Title       string `json:"title"`
+				Description string `json:"description"`
+				URL         string `json:"url"`
+				State       struct {
+					Name string `json:"name"`
+				} `json:"state"`
+			} `json:"issue"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("decoding issue: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		if strings.Contains(strings.ToLower(result.Errors[0].Message), "not found") {
+			return nil, ErrNotFound
+		}
+		return nil, errors.New(result.Errors[0].Message)
+	}
+	issue := result.Data.Issue
+	if issue == nil {
+		return nil, ErrNotFound
+	}
+	ticket := &Ticket{
+		ID:      issue.Identifier,
+		Tracker: TrackerLinear,
+		Title:   issue.Title,
+		Status:  issue.State.Name,
+		URL:     issue.URL,
+	}
+	ticket.Description, ticket.AcceptanceCriteria = splitCriteria(strings.TrimSpace(issue.Description))
+	return ticket, nil
+}
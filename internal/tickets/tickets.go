@@ -0,0 +1,205 @@
+// Package tickets fetches issues from the trackers set up under integrations
+// in cdd.json, so a prompt can name a ticket instead of pasting it.
+package tickets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+// Tracker names.
+const (
+	TrackerJira   = "jira"
+	TrackerLinear = "linear"
+)
+
+const (
+	defaultLinearURL = "https://api.linear.app"
+	// fetchTimeout bounds one request to a tracker.
+	fetchTimeout = 30 * time.Second
+	// maxResponse is the most of a response body read.
+	maxResponse = 1 << 20
+)
+
+// ErrNotFound is returned when no tracker has the ticket.
+var ErrNotFound = errors.New("ticket not found")
+
+// Ticket is an issue as the agent sees it.
+type Ticket struct {
+	ID                 string
+	Tracker            string
+	Title              string
+	Type               string
+	Status             string
+	URL                string
+	Description        string
+	AcceptanceCriteria string
+}
+
+// String formats the ticket as context for the model.
+func (t *Ticket) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s\n", t.ID, t.Title)
+	for _, field := range []struct{ name, value string }{
+		{"Type", t.Type}, {"Status", t.Status}, {"URL", t.URL},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", field.name, field.value)
+		}
+	}
+	if t.Description != "" {
+		b.WriteString("\nDescription:\n" + strings.TrimSpace(t.Description) + "\n")
+	}
+	if t.AcceptanceCriteria != "" {
+		b.WriteString("\nAcceptance criteria:\n" + strings.TrimSpace(t.AcceptanceCriteria) + "\n")
+	}
+	return b.String()
+}
+
+// tracker fetches tickets from one service.
+type tracker interface {
+	name() string
+	fetch(ctx context.Context, id string) (*Ticket, error)
+}
+
+// Client fetches tickets from the configured trackers.
+type Client struct {
+	trackers []tracker
+}
+
+// New creates a client for the trackers configured in cfg, which may be nil.
+// It returns nil when none is configured.
+func New(cfg *config.IntegrationsConfig) (*Client, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	resolver := config.NewResolver()
+	httpClient := &http.Client{Timeout: fetchTimeout}
+	c := &Client{}
+
+	if jira := cfg.Jira; jira != nil {
+		token, err := resolver.Resolve(jira.Token)
+		if err != nil {
+			return nil, fmt.Errorf("resolving integrations.jira.token: %w", err)
+		}
+		c.trackers = append(c.trackers, &jiraTracker{
+			baseURL: strings.TrimRight(jira.BaseURL, "/"),
+			email:   jira.Email,
+			token:   token,
+			acField: jira.AcceptanceCriteriaField,
+			client:  httpClient,
+		})
+	}
+	if linear := cfg.Linear; linear != nil {
+		token, err := resolver.Resolve(linear.Token)
+		if err != nil {
+			return nil, fmt.Errorf("resolving integrations.linear.token: %w", err)
+		}
+		t := &linearTracker{baseURL: strings.TrimRight(linear.BaseURL, "/"), token: token, client: httpClient}
+		if t.baseURL == "" {
+			t.baseURL = defaultLinearURL
+		}
+		c.trackers = append(c.trackers, t)
+	}
+
+	if len(c.trackers) == 0 {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// Trackers returns the names of the configured trackers.
+func (c *Client) Trackers() []string {
+	names := make([]string, len(c.trackers))
+	for i, t := range c.trackers {
+		names[i] = t.name()
+	}
+	return names
+}
+
+// Fetch returns the ticket with the given ID, such as PROJ-123, from the
+// named tracker, or from the first configured tracker that has it when
+// trackerName is empty.
+func (c *Client) Fetch(ctx context.Context, id, trackerName string) (*Ticket, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, fmt.Errorf("ticket ID is empty")
+	}
+	if trackerName != "" && !slices.Contains(c.Trackers(), trackerName) {
+		return nil, fmt.Errorf("%s is not configured (have %s)", trackerName, strings.Join(c.Trackers(), ", "))
+	}
+	for _, t := range c.trackers {
+		if trackerName != "" && t.name() != trackerName {
+			continue
+		}
+		ticket, err := t.fetch(ctx, id)
+		if errors.Is(err, ErrNotFound) && trackerName == "" {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t.name(), err)
+		}
+		return ticket, nil
+	}
+	return nil, fmt.Errorf("%s: %w", id, ErrNotFound)
+}
+
+// criteriaHeading matches the heading of an acceptance criteria section in
+// markdown ("## Acceptance criteria"), Jira wiki markup ("h3. Acceptance
+// Criteria") or bold text ("*Acceptance criteria:*").
+var criteriaHeading = regexp.MustCompile(`(?im)^[ \t]*(?:#{1,6}[ \t]*|h[1-6]\.[ \t]*)?[*_]{0,2}acceptance criteria[*_:]*[ \t]*[*_]{0,2}[ \t]*$`)
+
+// sectionHeading matches any markdown or Jira wiki heading.
+var sectionHeading = regexp.MustCompile(`(?m)^[ \t]*(?:#{1,6}[ \t]+|h[1-6]\.[ \t]+)\S`)
+
+// splitCriteria takes the acceptance criteria section, up to the next
+// heading, out of a ticket description. It returns the description unchanged
+// and no criteria when there is no such section.
+func splitCriteria(description string) (rest, criteria string) {
+	loc := criteriaHeading.FindStringIndex(description)
+	if loc == nil {
+		return description, ""
+	}
+	end := len(description)
+	if next := sectionHeading.FindStringIndex(description[loc[1]:]); next != nil {
+		end = loc[1] + next[0]
+	}
+	criteria = strings.TrimSpace(description[loc[1]:end])
+	rest = strings.TrimSpace(description[:loc[0]] + description[end:])
+	return rest, criteria
+}
+
+// do sends req and returns the body of the response, with ErrNotFound for a
+// 404.
+func do(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // Wrapped by the caller
+	}
+	defer resp.Body.Close() //nolint:errcheck // Response body
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		detail := strings.TrimSpace(string(data))
+		if len(detail) > 512 { //nolint:mnd // Enough to show the error message
+			detail = detail[:512]
+		}
+		return nil, fmt.Errorf("%s: %s", resp.Status, detail)
+	}
+	return data, nil
+}
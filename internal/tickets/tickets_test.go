@@ -0,0 +1,151 @@
+package tickets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+// jiraServer serves PROJ-1 with a description holding acceptance criteria.
+func jiraServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me@example.com" || pass != "jira-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/rest/api/2/issue/PROJ-1" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, `{"key": "PROJ-1", "fields": {
+			"summary": "Add dark mode",
+			"issuetype": {"name": "Story"},
+			"status": {"name": "To Do"},
+			"description": "Users want a dark theme.\n\nh3. Acceptance Criteria\n* Toggle in settings\n* Remembered on restart\n\nh3. Notes\nSee the mockups."
+		}}`) //nolint:errcheck // Test server
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// linearServer serves ENG-7 and reports any other issue as not found.
+func linearServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graphql" || r.Header.Get("Authorization") != "lin_api_key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Variables map[string]string `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Variables["id"] != "ENG-7" {
+			_, _ = io.WriteString(w, `{"data": {"issue": null}, "errors": [{"message": "Entity not found: Issue"}]}`) //nolint:errcheck // Test server
+			return
+		}
+		_, _ = io.WriteString(w, `{"data": {"issue": {
+			"identifier": "ENG-7", "title": "Fix login", "url": "https://linear.app/x/issue/ENG-7",
+			"description": "Login fails on Safari.\n\n## Acceptance criteria\n- Works on Safari 17",
+			"state": {"name": "In Progress"}
+		}}}`) //nolint:errcheck // Test server
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNew(t *testing.T) {
+	if c, err := New(nil); c != nil || err != nil {
+		t.Errorf("New(nil) = %v, %v; want no client", c, err)
+	}
+	if c, err := New(&config.IntegrationsConfig{}); c != nil || err != nil {
+		t.Errorf("New() without trackers = %v, %v; want no client", c, err)
+	}
+	if _, err := New(&config.IntegrationsConfig{Linear: &config.LinearIntegration{Token: "$CDD_TEST_UNSET_LINEAR"}}); err == nil {
+		t.Error("New() should report an unset environment variable")
+	}
+}
+
+func TestClient_Fetch(t *testing.T) {
+	jira, linear := jiraServer(t), linearServer(t)
+	c, err := New(&config.IntegrationsConfig{
+		Jira:   &config.JiraIntegration{BaseURL: jira.URL + "/", Email: "me@example.com", Token: "jira-token"},
+		Linear: &config.LinearIntegration{BaseURL: linear.URL, Token: "lin_api_key"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	t.Run("jira", func(t *testing.T) {
+		ticket, err := c.Fetch(ctx, "PROJ-1", "")
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		want := &Ticket{
+			ID: "PROJ-1", Tracker: TrackerJira, Title: "Add dark mode", Type: "Story", Status: "To Do",
+			URL:                jira.URL + "/browse/PROJ-1",
+			Description:        "Users want a dark theme.\n\nh3. Notes\nSee the mockups.",
+			AcceptanceCriteria: "* Toggle in settings\n* Remembered on restart",
+		}
+		if *ticket != *want {
+			t.Errorf("Fetch() = %+v, want %+v", ticket, want)
+		}
+	})
+
+	t.Run("falls through to linear", func(t *testing.T) {
+		ticket, err := c.Fetch(ctx, "ENG-7", "")
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		if ticket.Tracker != TrackerLinear || ticket.Status != "In Progress" || ticket.AcceptanceCriteria != "- Works on Safari 17" {
+			t.Errorf("Fetch() = %+v, want the Linear issue with its criteria", ticket)
+		}
+		out := ticket.String()
+		if !strings.HasPrefix(out, "ENG-7: Fix login\n") || !strings.Contains(out, "\nAcceptance criteria:\n- Works on Safari 17") {
+			t.Errorf("String() = %q", out)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		if _, err := c.Fetch(ctx, "NOPE-1", ""); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Fetch() error = %v, want ErrNotFound", err)
+		}
+		if _, err := c.Fetch(ctx, "PROJ-1", TrackerLinear); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Fetch(linear) error = %v, want ErrNotFound from the named tracker only", err)
+		}
+		if _, err := c.Fetch(ctx, "PROJ-1", "github"); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Fetch(github) error = %v, want an unconfigured tracker error", err)
+		}
+	})
+}
+
+func TestSplitCriteria(t *testing.T) {
+	tests := []struct {
+		name, in, rest, criteria string
+	}{
+		{name: "none", in: "Just a bug.", rest: "Just a bug."},
+		{name: "markdown at the end", in: "Intro\n\n## Acceptance Criteria\n- a\n- b", rest: "Intro", criteria: "- a\n- b"},
+		{name: "bold label", in: "*Acceptance criteria:*\n- one\n\nmore", rest: "", criteria: "- one\n\nmore"},
+		{name: "followed by a section", in: "### Acceptance criteria\n- a\n### Design\nx", rest: "### Design\nx", criteria: "- a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rest, criteria := splitCriteria(tt.in)
+			if rest != tt.rest || criteria != tt.criteria {
+				t.Errorf("splitCriteria(%q) = %q, %q; want %q, %q", tt.in, rest, criteria, tt.rest, tt.criteria)
+			}
+		})
+	}
+}
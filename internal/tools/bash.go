@@ -5,22 +5,53 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os/exec"
 	"runtime"
 	"strings"
 	"time"
 
 	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/pubsub"
 )
 
 // Tool constants for bash execution.
 const (
-	BashToolName    = "bash"
-	MaxOutputLength = 30000
-	DefaultTimeout  = 2 * time.Minute
-	osWindows       = "windows"
+	BashToolName     = "bash"
+	MaxOutputLength  = 30000   // Default for the characters of a tool result sent to the model
+	maxCommandOutput = 1 << 20 // Characters of a command's stdout or stderr kept
+	DefaultTimeout   = 2 * time.Minute
+	MaxTimeout       = 10 * time.Minute
+	osWindows        = "windows"
 )
 
+// BashOption configures the bash tool.
+type BashOption func(*bashConfig)
+
+// bashConfig holds optional bash tool settings.
+type bashConfig struct {
+	hub            *pubsub.Hub
+	defaultTimeout time.Duration
+}
+
+// WithBashHub streams command output as tool progress events on the hub.
+func WithBashHub(hub *pubsub.Hub) BashOption {
+	return func(c *bashConfig) {
+		c.hub = hub
+	}
+}
+
+// WithBashTimeout overrides the default command timeout.
+// Values outside (0, MaxTimeout] are ignored.
+func WithBashTimeout(timeout time.Duration) BashOption {
+	return func(c *bashConfig) {
+		if timeout > 0 && timeout <= MaxTimeout {
+			c.defaultTimeout = timeout
+		}
+	}
+}
+
 // BashParams are the parameters for the bash tool.
 type BashParams struct {
 	Command     string `json:"command" description:"The command to execute"`
@@ -61,17 +92,23 @@ Usage:
 - The command is executed in a shell (bash on Unix, cmd on Windows)
 - Commands are subject to safety restrictions
 - Output is truncated if it exceeds 30000 characters
-- Default timeout is 2 minutes, maximum is 10 minutes
+- Default timeout is 2 minutes unless configured otherwise, maximum is 10 minutes
 - Use working_dir to specify a different working directory
 
 Banned commands include: network tools, browsers, sudo/su, package managers, and system modification tools.`
 
 // NewBashTool creates a new bash tool.
-func NewBashTool(workingDir string) fantasy.AgentTool {
+func NewBashTool(workingDir string, opts ...BashOption) fantasy.AgentTool {
+	cfg := bashConfig{defaultTimeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	return fantasy.NewAgentTool(
 		BashToolName,
 		bashDescription,
 		func(ctx context.Context, params BashParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			workingDir := sessionDir(ctx, workingDir)
 			if params.Command == "" {
 				return fantasy.NewTextErrorResponse("command is required"), nil
 			}
@@ -93,11 +130,11 @@ func NewBashTool(workingDir string) fantasy.AgentTool {
 			}
 
 			// Determine timeout
-			timeout := DefaultTimeout
+			timeout := cfg.defaultTimeout
 			if params.Timeout > 0 {
 				timeout = time.Duration(params.Timeout) * time.Millisecond
-				if timeout > 10*time.Minute {
-					timeout = 10 * time.Minute
+				if timeout > MaxTimeout {
+					timeout = MaxTimeout
 				}
 			}
 
@@ -121,20 +158,36 @@ func NewBashTool(workingDir string) fantasy.AgentTool {
 			cmd.Stdout = &stdout
 			cmd.Stderr = &stderr
 
+			// Mirror output and the time taken to progress events so the UI
+			// can show them live.
+			var stream *outputStreamer
+			stopElapsed := func() {}
+			if cfg.hub != nil {
+				stream = newOutputStreamer(cfg.hub, SessionIDFromContext(ctx), call.ID)
+				cmd.Stdout = io.MultiWriter(&stdout, stream)
+				cmd.Stderr = io.MultiWriter(&stderr, stream)
+				stopElapsed = reportElapsed(cfg.hub, SessionIDFromContext(ctx), call.ID, BashToolName)
+			}
+
 			err := cmd.Run()
 			endTime := time.Now()
+			stopElapsed()
+			if stream != nil {
+				stream.Flush()
+			}
 
 			exitCode := 0
 			if err != nil {
 				var exitErr *exec.ExitError
+				// Check the context first: a killed process also reports an ExitError.
 				switch {
-				case errors.As(err, &exitErr):
-					exitCode = exitErr.ExitCode()
 				case errors.Is(ctx.Err(), context.DeadlineExceeded):
 					return fantasy.NewTextErrorResponse(fmt.Sprintf(
 						"Command timed out after %v", timeout)), nil
 				case errors.Is(ctx.Err(), context.Canceled):
 					return fantasy.NewTextErrorResponse("Command was cancelled"), nil
+				case errors.As(err, &exitErr):
+					exitCode = exitErr.ExitCode()
 				default:
 					// Handle other errors (e.g., executable not found, permission denied)
 					return fantasy.NewTextErrorResponse(fmt.Sprintf(
@@ -220,15 +273,9 @@ func formatBashOutput(stdout, stderr string, exitCode int) string {
 	return output.String()
 }
 
+// truncateOutput bounds the output of a command kept in the result. The
+// agent cuts results further, to what the model is sent.
 func truncateOutput(content string) string {
-	if len(content) <= MaxOutputLength {
-		return content
-	}
-
-	halfLength := MaxOutputLength / 2
-	start := content[:halfLength]
-	end := content[len(content)-halfLength:]
-
-	truncatedLines := strings.Count(content[halfLength:len(content)-halfLength], "\n")
-	return fmt.Sprintf("%s\n\n... [%d lines truncated] ...\n\n%s", start, truncatedLines, end)
+	truncated, _ := TruncateMiddle(content, maxCommandOutput)
+	return truncated
 }
@@ -8,8 +8,12 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
 )
 
 //nolint:gocyclo // Test functions naturally have high complexity
@@ -212,3 +216,85 @@ func invokeBashTool(ctx context.Context, tool fantasy.AgentTool, params BashPara
 	}
 	return tool.Run(ctx, call)
 }
+
+func TestBashToolStreamsOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping bash tests on Windows")
+	}
+
+	hub := pubsub.NewHub()
+	defer hub.Shutdown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	toolEvents := hub.Tool.Subscribe(ctx)
+
+	tool := NewBashTool(t.TempDir(), WithBashHub(hub))
+	resp, err := invokeBashTool(WithSessionID(ctx, "session-1"), tool, BashParams{
+		Command: "echo first; echo second >&2; printf partial",
+	})
+	if err != nil || resp.IsError {
+		t.Fatalf("unexpected failure: %v %s", err, getTextContent(resp))
+	}
+
+	var streamed strings.Builder
+	for len(toolEvents) > 0 {
+		event := <-toolEvents
+		if event.Payload.Type != events.ToolEventProgress {
+			continue
+		}
+		if event.Payload.SessionID != "session-1" || event.Payload.ToolCallID != "test-call" {
+			t.Errorf("unexpected event identity: %+v", event.Payload)
+		}
+		streamed.WriteString(event.Payload.Chunk)
+	}
+
+	for _, want := range []string{"first\n", "second\n", "partial"} {
+		if !strings.Contains(streamed.String(), want) {
+			t.Errorf("expected streamed output to contain %q, got %q", want, streamed.String())
+		}
+	}
+}
+
+func TestBashToolReportsElapsed(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping bash tests on Windows")
+	}
+
+	hub := pubsub.NewHub()
+	defer hub.Shutdown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	toolEvents := hub.Tool.Subscribe(ctx)
+
+	tool := NewBashTool(t.TempDir(), WithBashHub(hub))
+	if _, err := invokeBashTool(WithSessionID(ctx, "session-1"), tool, BashParams{Command: "sleep 1.2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var steps []string
+	for len(toolEvents) > 0 {
+		if event := <-toolEvents; event.Payload.Step != "" {
+			steps = append(steps, event.Payload.Step)
+		}
+	}
+	if len(steps) != 1 || steps[0] != "1s elapsed" {
+		t.Errorf("steps = %q, want one after a second", steps)
+	}
+}
+
+func TestBashToolConfiguredTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping bash tests on Windows")
+	}
+
+	tool := NewBashTool(t.TempDir(), WithBashTimeout(100*time.Millisecond))
+	resp, err := invokeBashTool(context.Background(), tool, BashParams{Command: "sleep 5"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !resp.IsError || !strings.Contains(getTextContent(resp), "timed out") {
+		t.Errorf("expected timeout error, got: %s", getTextContent(resp))
+	}
+}
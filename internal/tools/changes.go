@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/guilhermegouw/cdd/internal/debug"
+)
+
+// ChangeRecorder records the file changes tools make so they can be undone.
+type ChangeRecorder interface {
+	Record(sessionID, path, tool string, before []byte, existed bool, after []byte) error
+}
+
+type changeRecorderContextKey string
+
+// ChangeRecorderContextKey is the context key for the ChangeRecorder.
+const ChangeRecorderContextKey changeRecorderContextKey = "change_recorder"
+
+// WithChangeRecorder adds a change recorder to the context.
+func WithChangeRecorder(ctx context.Context, recorder ChangeRecorder) context.Context {
+	return context.WithValue(ctx, ChangeRecorderContextKey, recorder)
+}
+
+// ChangeRecorderFromContext retrieves the change recorder from the context.
+func ChangeRecorderFromContext(ctx context.Context) ChangeRecorder {
+	recorder, _ := ctx.Value(ChangeRecorderContextKey).(ChangeRecorder) //nolint:errcheck // Missing recorder is nil
+	return recorder
+}
+
+// recordChange reports a file change to the context's recorder, if any. A
+// failure to record is logged rather than failing the tool, since the file
+// has already been written.
+func recordChange(ctx context.Context, path, tool string, before []byte, existed bool, after []byte) {
+	recorder := ChangeRecorderFromContext(ctx)
+	if recorder == nil {
+		return
+	}
+	if err := recorder.Record(SessionIDFromContext(ctx), path, tool, before, existed, after); err != nil {
+		debug.Error("tools", err, "recording change to "+path)
+	}
+}
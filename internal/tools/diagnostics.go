@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/lsp"
+)
+
+// DiagnosticsToolName is the name of the diagnostics tool.
+const DiagnosticsToolName = "diagnostics"
+
+// maxDiagnostics caps how many diagnostics a single call reports.
+const maxDiagnostics = 50
+
+// DiagnosticsProvider reports compiler and linter diagnostics, typically from
+// language servers. *lsp.Manager implements it.
+type DiagnosticsProvider interface {
+	ServerFor(path string) (string, bool)
+	Diagnostics(ctx context.Context, path string) ([]lsp.Diagnostic, error)
+	AllDiagnostics() map[string][]lsp.Diagnostic
+}
+
+// DiagnosticsParams are the parameters for the diagnostics tool.
+type DiagnosticsParams struct {
+	FilePath string `json:"file_path,omitempty" description:"The file to check. Omit to list the problems already reported for every file checked so far."`
+}
+
+// DiagnosticsResponseMetadata provides metadata about the diagnostics operation.
+type DiagnosticsResponseMetadata struct {
+	FilePath string `json:"file_path,omitempty"`
+	Errors   int    `json:"errors"`
+	Warnings int    `json:"warnings"`
+	Total    int    `json:"total"`
+}
+
+const diagnosticsDescription = `Reports compile errors, type errors, and lint warnings for a file, as seen by the project's language server (for example gopls, typescript-language-server, or pyright).
+
+Usage:
+- Run it after editing or writing a source file to check that the change compiles, then fix any errors it reports
+- The file is checked as it currently is on disk
+- Omit file_path to list the problems already reported for all files checked so far, including files affected by your edits
+- Hints are omitted; errors and warnings are listed as path:line:column: severity: message`
+
+// NewDiagnosticsTool creates a new diagnostics tool backed by provider.
+func NewDiagnosticsTool(workingDir string, provider DiagnosticsProvider) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		DiagnosticsToolName,
+		diagnosticsDescription,
+		func(ctx context.Context, params DiagnosticsParams, _ fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			workingDir := sessionDir(ctx, workingDir)
+			if params.FilePath == "" {
+				return diagnosticsResponse(workingDir, "", provider.AllDiagnostics()), nil
+			}
+
+			filePath := ResolvePath(workingDir, params.FilePath)
+			if _, err := os.Stat(filePath); err != nil {
+				if os.IsNotExist(err) {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("File not found: %s", filePath)), nil
+				}
+				return fantasy.ToolResponse{}, fmt.Errorf("error accessing file: %w", err)
+			}
+			if _, ok := provider.ServerFor(filePath); !ok {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf(
+					"No language server is configured for %s. Configure one under \"lsp\" in cdd.json, "+
+						"or check the file by building or running its tests with bash.", displayPath(workingDir, filePath))), nil
+			}
+
+			diags, err := provider.Diagnostics(ctx, filePath)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("Error getting diagnostics: %v", err)), nil
+			}
+			return diagnosticsResponse(workingDir, filePath, map[string][]lsp.Diagnostic{filePath: diags}), nil
+		})
+}
+
+// diagnosticsResponse formats diagnostics grouped by file, errors first.
+func diagnosticsResponse(workingDir, filePath string, byFile map[string][]lsp.Diagnostic) fantasy.ToolResponse {
+	meta := DiagnosticsResponseMetadata{FilePath: filePath}
+
+	paths := make([]string, 0, len(byFile))
+	for path := range byFile {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var lines []string
+	for _, path := range paths {
+		diags := make([]lsp.Diagnostic, 0, len(byFile[path]))
+		for i := range byFile[path] {
+			if byFile[path][i].Severity != lsp.SeverityHint {
+				diags = append(diags, byFile[path][i])
+			}
+		}
+		sort.SliceStable(diags, func(i, j int) bool {
+			if diags[i].Severity != diags[j].Severity {
+				return diags[i].Severity < diags[j].Severity
+			}
+			return diags[i].Range.Start.Line < diags[j].Range.Start.Line
+		})
+
+		shown := displayPath(workingDir, path)
+		for i := range diags {
+			switch diags[i].Severity {
+			case lsp.SeverityError:
+				meta.Errors++
+			case lsp.SeverityWarning:
+				meta.Warnings++
+			}
+			meta.Total++
+			if len(lines) < maxDiagnostics {
+				lines = append(lines, diags[i].Format(shown))
+			}
+		}
+	}
+
+	var output string
+	switch {
+	case meta.Total == 0 && filePath != "":
+		output = fmt.Sprintf("No problems found in %s", displayPath(workingDir, filePath))
+	case meta.Total == 0:
+		output = "No problems reported"
+	default:
+		output = fmt.Sprintf("Found %s and %s\n%s",
+			countNoun(meta.Errors, "error"), countNoun(meta.Warnings, "warning"), strings.Join(lines, "\n"))
+		if meta.Total > len(lines) {
+			output += fmt.Sprintf("\n\n(Showing %d of %d problems. Fix the errors above and check again.)", len(lines), meta.Total)
+		}
+	}
+
+	return fantasy.WithResponseMetadata(fantasy.NewTextResponse(output), meta)
+}
+
+// countNoun formats n with noun, adding an "s" unless n is one.
+func countNoun(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
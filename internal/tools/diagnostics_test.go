@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/lsp"
+)
+
+// fakeDiagnostics serves fixed diagnostics for .go files.
+type fakeDiagnostics struct {
+	diags map[string][]lsp.Diagnostic
+}
+
+func (f *fakeDiagnostics) ServerFor(path string) (string, bool) {
+	return "fake", strings.HasSuffix(path, ".go")
+}
+
+func (f *fakeDiagnostics) Diagnostics(_ context.Context, path string) ([]lsp.Diagnostic, error) {
+	return f.diags[path], nil
+}
+
+func (f *fakeDiagnostics) AllDiagnostics() map[string][]lsp.Diagnostic {
+	return f.diags
+}
+
+func TestDiagnosticsTool(t *testing.T) {
+	tmpDir := t.TempDir()
+	mainGo := filepath.Join(tmpDir, "main.go")
+	utilGo := filepath.Join(tmpDir, "util.go")
+	readme := filepath.Join(tmpDir, "README.md")
+	for _, path := range []string{mainGo, utilGo, readme} {
+		if err := os.WriteFile(path, []byte("x"), 0o600); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	at := func(line int, sev lsp.DiagnosticSeverity, msg string) lsp.Diagnostic {
+		return lsp.Diagnostic{Range: lsp.Range{Start: lsp.Position{Line: line, Character: 1}}, Severity: sev, Message: msg, Source: "compiler"}
+	}
+	provider := &fakeDiagnostics{diags: map[string][]lsp.Diagnostic{
+		mainGo: {
+			at(9, lsp.SeverityWarning, "unused result"),
+			at(4, lsp.SeverityError, "undefined: foo"),
+			at(2, lsp.SeverityHint, "could be simplified"),
+		},
+		utilGo: {at(0, lsp.SeverityError, "expected declaration")},
+	}}
+	tool := NewDiagnosticsTool(tmpDir, provider)
+
+	t.Run("single file", func(t *testing.T) {
+		resp := runDiagnosticsTool(t, tool, DiagnosticsParams{FilePath: "main.go"})
+		want := "Found 1 error and 1 warning\n" +
+			"main.go:5:2: error: undefined: foo (compiler)\n" +
+			"main.go:10:2: warning: unused result (compiler)"
+		if resp.Content != want {
+			t.Errorf("got:\n%s\nwant:\n%s", resp.Content, want)
+		}
+
+		var meta DiagnosticsResponseMetadata
+		if err := json.Unmarshal([]byte(resp.Metadata), &meta); err != nil {
+			t.Fatalf("Failed to decode metadata: %v", err)
+		}
+		if meta.Errors != 1 || meta.Warnings != 1 || meta.Total != 2 {
+			t.Errorf("unexpected metadata %+v", meta)
+		}
+	})
+
+	t.Run("all files", func(t *testing.T) {
+		resp := runDiagnosticsTool(t, tool, DiagnosticsParams{})
+		if !strings.HasPrefix(resp.Content, "Found 2 errors and 1 warning") || !strings.Contains(resp.Content, "util.go:1:2: error") {
+			t.Errorf("unexpected output:\n%s", resp.Content)
+		}
+	})
+
+	t.Run("clean file", func(t *testing.T) {
+		provider.diags[mainGo] = nil
+		resp := runDiagnosticsTool(t, tool, DiagnosticsParams{FilePath: mainGo})
+		if resp.IsError || resp.Content != "No problems found in main.go" {
+			t.Errorf("unexpected response: %s", resp.Content)
+		}
+	})
+
+	t.Run("no server for file type", func(t *testing.T) {
+		resp := runDiagnosticsTool(t, tool, DiagnosticsParams{FilePath: readme})
+		if !resp.IsError || !strings.Contains(resp.Content, "No language server") {
+			t.Errorf("expected missing server error, got: %s", resp.Content)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		resp := runDiagnosticsTool(t, tool, DiagnosticsParams{FilePath: "nope.go"})
+		if !resp.IsError || !strings.Contains(resp.Content, "not found") {
+			t.Errorf("expected not found error, got: %s", resp.Content)
+		}
+	})
+}
+
+func runDiagnosticsTool(t *testing.T, tool fantasy.AgentTool, params DiagnosticsParams) fantasy.ToolResponse {
+	t.Helper()
+	input, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("Failed to marshal params: %v", err)
+	}
+	resp, err := tool.Run(context.Background(), fantasy.ToolCall{ID: "test-call", Name: DiagnosticsToolName, Input: string(input)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return resp
+}
@@ -0,0 +1,337 @@
+package tools
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// diffContextLines is the number of unchanged lines shown around each hunk.
+const diffContextLines = 3
+
+// maxDiffCells bounds the LCS table; larger inputs fall back to a whole-file diff.
+const maxDiffCells = 4_000_000
+
+// diffOp is a single line operation in an edit script.
+type diffOp struct {
+	kind byte // ' ', '-', or '+'
+	line string
+}
+
+// UnifiedDiff returns a unified diff between oldContent and newContent.
+// Returns an empty string if the contents are identical.
+func UnifiedDiff(path, oldContent, newContent string) string {
+	if oldContent == newContent {
+		return ""
+	}
+
+	ops := diffLines(splitLines(oldContent), splitLines(newContent))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", path, path)
+	writeHunks(&sb, ops)
+	return sb.String()
+}
+
+// splitLines splits content into lines without trailing newline characters.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// diffLines computes a line-based edit script using longest common subsequence.
+func diffLines(a, b []string) []diffOp {
+	// Trim common prefix and suffix to keep the table small.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, l := range a[:prefix] {
+		ops = append(ops, diffOp{' ', l})
+	}
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, l := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', l})
+	}
+	return ops
+}
+
+// diffMiddle diffs the region between the common prefix and suffix.
+func diffMiddle(a, b []string) []diffOp {
+	ops := make([]diffOp, 0, len(a)+len(b))
+	if len(a)*len(b) > maxDiffCells {
+		for _, l := range a {
+			ops = append(ops, diffOp{'-', l})
+		}
+		for _, l := range b {
+			ops = append(ops, diffOp{'+', l})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// writeHunks groups an edit script into unified diff hunks.
+func writeHunks(sb *strings.Builder, ops []diffOp) {
+	oldLine, newLine := 1, 1
+	for start := 0; start < len(ops); {
+		// Find the next change.
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+			oldLine++
+			newLine++
+		}
+		if start >= len(ops) {
+			return
+		}
+
+		// Extend the hunk until a run of unchanged lines is long enough to split on.
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*diffContextLines {
+				break
+			}
+			end = run
+		}
+
+		lead := min(diffContextLines, start)
+		trail := 0
+		for end+trail < len(ops) && trail < diffContextLines && ops[end+trail].kind == ' ' {
+			trail++
+		}
+
+		hunk := ops[start-lead : end+trail]
+		oldCount, newCount := 0, 0
+		for _, op := range hunk {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+
+		fmt.Fprintf(sb, "@@ -%s +%s @@\n",
+			hunkRange(oldLine-lead, oldCount), hunkRange(newLine-lead, newCount))
+		for _, op := range hunk {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.line)
+			sb.WriteByte('\n')
+		}
+
+		// Advance line counters past the hunk body (context before start was already counted).
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		start = end
+	}
+}
+
+// hunkRange formats a hunk range, following diff's convention for empty ranges.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// DiffStats counts added and removed lines in a unified diff.
+func DiffStats(diff string) (additions, removals int) {
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			additions++
+		case strings.HasPrefix(line, "-"):
+			removals++
+		}
+	}
+	return additions, removals
+}
+
+// patchHunk is a parsed hunk from a unified diff.
+type patchHunk struct {
+	oldStart int
+	oldLines []string // Context and removed lines
+	newLines []string // Context and added lines
+}
+
+// ApplyUnifiedDiff applies a unified diff patch to content.
+// Hunks are matched at their stated position first, then searched for nearby,
+// so patches with slightly stale line numbers still apply.
+func ApplyUnifiedDiff(content, patch string) (string, error) {
+	hunks, err := parseHunks(patch)
+	if err != nil {
+		return "", err
+	}
+	if len(hunks) == 0 {
+		return "", fmt.Errorf("patch contains no hunks")
+	}
+
+	trailingNewline := content == "" || strings.HasSuffix(content, "\n")
+	lines := splitLines(content)
+
+	offset := 0
+	searchFrom := 0
+	for n, h := range hunks {
+		want := h.oldStart - 1 + offset
+		if len(h.oldLines) == 0 {
+			// Pure insertion: "-N,0" means insert after line N.
+			want = h.oldStart + offset
+		}
+		pos := findHunk(lines, h.oldLines, want, searchFrom)
+		if pos < 0 {
+			return "", fmt.Errorf("hunk %d does not match the file contents", n+1)
+		}
+
+		updated := make([]string, 0, len(lines)-len(h.oldLines)+len(h.newLines))
+		updated = append(updated, lines[:pos]...)
+		updated = append(updated, h.newLines...)
+		updated = append(updated, lines[pos+len(h.oldLines):]...)
+		lines = updated
+
+		offset += len(h.newLines) - len(h.oldLines)
+		searchFrom = pos + len(h.newLines)
+	}
+
+	result := strings.Join(lines, "\n")
+	if trailingNewline && len(lines) > 0 {
+		result += "\n"
+	}
+	return result, nil
+}
+
+// findHunk returns the index where block matches lines, preferring positions close to want.
+func findHunk(lines, block []string, want, from int) int {
+	matches := func(pos int) bool {
+		if pos < from || pos < 0 || pos+len(block) > len(lines) {
+			return false
+		}
+		for i := range block {
+			if lines[pos+i] != block[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	for delta := 0; delta <= len(lines); delta++ {
+		if matches(want - delta) {
+			return want - delta
+		}
+		if delta > 0 && matches(want+delta) {
+			return want + delta
+		}
+	}
+	return -1
+}
+
+// parseHunks parses the hunks of a unified diff, ignoring file headers.
+func parseHunks(patch string) ([]patchHunk, error) {
+	var hunks []patchHunk
+	var current *patchHunk
+
+	for _, line := range strings.Split(normalizeLineEndings(patch), "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			start, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			hunks = append(hunks, patchHunk{oldStart: start})
+			current = &hunks[len(hunks)-1]
+		case current == nil:
+			// File headers (---/+++/diff/index) before the first hunk.
+		case strings.HasPrefix(line, "+"):
+			current.newLines = append(current.newLines, line[1:])
+		case strings.HasPrefix(line, "-"):
+			current.oldLines = append(current.oldLines, line[1:])
+		case strings.HasPrefix(line, " "):
+			current.oldLines = append(current.oldLines, line[1:])
+			current.newLines = append(current.newLines, line[1:])
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file"
+		case line == "":
+			// Blank lines between hunks or a trailing newline.
+		default:
+			return nil, fmt.Errorf("invalid patch line: %q", line)
+		}
+	}
+	return hunks, nil
+}
+
+// parseHunkHeader extracts the old start line from "@@ -a,b +c,d @@".
+func parseHunkHeader(line string) (int, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") {
+		return 0, fmt.Errorf("invalid hunk header: %q", line)
+	}
+	startStr, _, _ := strings.Cut(fields[1][1:], ",")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hunk header: %q", line)
+	}
+	return start, nil
+}
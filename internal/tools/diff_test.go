@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Run("identical content", func(t *testing.T) {
+		if got := UnifiedDiff("a.txt", "x\n", "x\n"); got != "" {
+			t.Errorf("expected empty diff, got %q", got)
+		}
+	})
+
+	t.Run("single line change", func(t *testing.T) {
+		got := UnifiedDiff("a.txt", "one\ntwo\nthree\n", "one\n2\nthree\n")
+		want := "--- a/a.txt\n+++ b/a.txt\n@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n"
+		if got != want {
+			t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("distant changes produce separate hunks", func(t *testing.T) {
+		var oldLines []string
+		for i := range 30 {
+			oldLines = append(oldLines, strings.Repeat("x", i+1))
+		}
+		newLines := append([]string(nil), oldLines...)
+		newLines[1] = "changed"
+		newLines[25] = "changed too"
+
+		diff := UnifiedDiff("f", strings.Join(oldLines, "\n")+"\n", strings.Join(newLines, "\n")+"\n")
+		if n := strings.Count(diff, "@@ -"); n != 2 {
+			t.Errorf("expected 2 hunks, got %d:\n%s", n, diff)
+		}
+		if !strings.Contains(diff, "@@ -23,7 +23,7 @@") {
+			t.Errorf("unexpected second hunk header:\n%s", diff)
+		}
+	})
+
+	t.Run("new file", func(t *testing.T) {
+		got := UnifiedDiff("f", "", "a\nb\n")
+		if !strings.Contains(got, "@@ -0,0 +1,2 @@") {
+			t.Errorf("unexpected diff for new content:\n%s", got)
+		}
+	})
+}
+
+func TestDiffStats(t *testing.T) {
+	diff := UnifiedDiff("f", "a\nb\nc\n", "a\nB\nc\nd\n")
+	additions, removals := DiffStats(diff)
+	if additions != 2 || removals != 1 {
+		t.Errorf("DiffStats() = (%d, %d), want (2, 1)", additions, removals)
+	}
+}
+
+func TestApplyUnifiedDiff(t *testing.T) {
+	t.Run("round trips a generated diff", func(t *testing.T) {
+		oldContent := "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n"
+		newContent := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n"
+
+		got, err := ApplyUnifiedDiff(oldContent, UnifiedDiff("main.go", oldContent, newContent))
+		if err != nil {
+			t.Fatalf("ApplyUnifiedDiff() error = %v", err)
+		}
+		if got != newContent {
+			t.Errorf("ApplyUnifiedDiff() = %q, want %q", got, newContent)
+		}
+	})
+
+	t.Run("tolerates drifted line numbers", func(t *testing.T) {
+		content := "extra\nextra\none\ntwo\nthree\n"
+		patch := "@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n"
+
+		got, err := ApplyUnifiedDiff(content, patch)
+		if err != nil {
+			t.Fatalf("ApplyUnifiedDiff() error = %v", err)
+		}
+		if got != "extra\nextra\none\n2\nthree\n" {
+			t.Errorf("ApplyUnifiedDiff() = %q", got)
+		}
+	})
+
+	t.Run("pure insertion", func(t *testing.T) {
+		got, err := ApplyUnifiedDiff("a\nb\n", "@@ -1,0 +2 @@\n+inserted\n")
+		if err != nil {
+			t.Fatalf("ApplyUnifiedDiff() error = %v", err)
+		}
+		if got != "a\ninserted\nb\n" {
+			t.Errorf("ApplyUnifiedDiff() = %q", got)
+		}
+	})
+
+	t.Run("rejects mismatched context", func(t *testing.T) {
+		if _, err := ApplyUnifiedDiff("a\nb\n", "@@ -1,2 +1,2 @@\n a\n-c\n+d\n"); err == nil {
+			t.Error("expected error for non-matching hunk")
+		}
+	})
+
+	t.Run("rejects patch without hunks", func(t *testing.T) {
+		if _, err := ApplyUnifiedDiff("a\n", "--- a/f\n+++ b/f\n"); err == nil {
+			t.Error("expected error for patch without hunks")
+		}
+	})
+
+	t.Run("rejects invalid lines", func(t *testing.T) {
+		if _, err := ApplyUnifiedDiff("a\n", "@@ -1 +1 @@\n-a\n+b\ngarbage\n"); err == nil {
+			t.Error("expected error for invalid patch line")
+		}
+	})
+}
@@ -33,7 +33,7 @@ type EditResponseMetadata struct {
 const editDescription = `Performs exact string replacements in files.
 
 Usage:
-- You must use the Read tool at least once before editing a file
+- You must use the read_file tool at least once before editing a file
 - The edit will FAIL if old_string is not unique in the file (unless replace_all=true)
 - Use replace_all for replacing and renaming strings across the file
 - When old_string is empty, creates a new file with new_string as content
@@ -45,6 +45,7 @@ func NewEditTool(workingDir string) fantasy.AgentTool {
 		EditToolName,
 		editDescription,
 		func(ctx context.Context, params EditParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			workingDir := sessionDir(ctx, workingDir)
 			if params.FilePath == "" {
 				return fantasy.NewTextErrorResponse("file_path is required"), nil
 			}
@@ -55,20 +56,20 @@ func NewEditTool(workingDir string) fantasy.AgentTool {
 			// Handle different edit modes
 			if params.OldString == "" {
 				// Create new file mode
-				return createNewFile(filePath, params.NewString)
+				return createNewFile(ctx, filePath, params.NewString)
 			}
 
 			if params.NewString == "" {
 				// Delete content mode
-				return deleteContent(filePath, params.OldString, params.ReplaceAll)
+				return deleteContent(ctx, filePath, params.OldString, params.ReplaceAll)
 			}
 
 			// Replace content mode
-			return replaceContent(filePath, params.OldString, params.NewString, params.ReplaceAll)
+			return replaceContent(ctx, filePath, params.OldString, params.NewString, params.ReplaceAll)
 		})
 }
 
-func createNewFile(filePath, content string) (fantasy.ToolResponse, error) {
+func createNewFile(ctx context.Context, filePath, content string) (fantasy.ToolResponse, error) {
 	if content == "" {
 		return fantasy.NewTextErrorResponse("new_string is required when creating a new file"), nil
 	}
@@ -96,6 +97,7 @@ func createNewFile(filePath, content string) (fantasy.ToolResponse, error) {
 
 	RecordFileWrite(filePath)
 	RecordFileRead(filePath)
+	recordChange(ctx, filePath, EditToolName, nil, false, []byte(content))
 
 	return fantasy.WithResponseMetadata(
 		fantasy.NewTextResponse(fmt.Sprintf("File created: %s", filePath)),
@@ -108,7 +110,7 @@ func createNewFile(filePath, content string) (fantasy.ToolResponse, error) {
 	), nil
 }
 
-func deleteContent(filePath, oldString string, replaceAll bool) (fantasy.ToolResponse, error) {
+func deleteContent(ctx context.Context, filePath, oldString string, replaceAll bool) (fantasy.ToolResponse, error) {
 	// Check file exists
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
@@ -124,7 +126,7 @@ func deleteContent(filePath, oldString string, replaceAll bool) (fantasy.ToolRes
 
 	// Check if file was read first
 	if GetLastReadTime(filePath).IsZero() {
-		return fantasy.NewTextErrorResponse("you must read the file before editing it. Use the Read tool first"), nil
+		return fantasy.NewTextErrorResponse("you must read the file before editing it. Use the read_file tool first"), nil
 	}
 
 	// Check modification time
@@ -174,6 +176,7 @@ func deleteContent(filePath, oldString string, replaceAll bool) (fantasy.ToolRes
 
 	RecordFileWrite(filePath)
 	RecordFileRead(filePath)
+	recordChange(ctx, filePath, EditToolName, content, true, []byte(newContent))
 
 	additions, removals := generateSimpleDiff(oldContent, newContent)
 
@@ -188,7 +191,7 @@ func deleteContent(filePath, oldString string, replaceAll bool) (fantasy.ToolRes
 	), nil
 }
 
-func replaceContent(filePath, oldString, newString string, replaceAll bool) (fantasy.ToolResponse, error) {
+func replaceContent(ctx context.Context, filePath, oldString, newString string, replaceAll bool) (fantasy.ToolResponse, error) {
 	// Check file exists
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
@@ -204,7 +207,7 @@ func replaceContent(filePath, oldString, newString string, replaceAll bool) (fan
 
 	// Check if file was read first
 	if GetLastReadTime(filePath).IsZero() {
-		return fantasy.NewTextErrorResponse("you must read the file before editing it. Use the Read tool first"), nil
+		return fantasy.NewTextErrorResponse("you must read the file before editing it. Use the read_file tool first"), nil
 	}
 
 	// Check modification time
@@ -258,6 +261,7 @@ func replaceContent(filePath, oldString, newString string, replaceAll bool) (fan
 
 	RecordFileWrite(filePath)
 	RecordFileRead(filePath)
+	recordChange(ctx, filePath, EditToolName, content, true, []byte(newContent))
 
 	additions, removals := generateSimpleDiff(oldContent, newContent)
 
@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+// EditFileToolName is the name of the edit_file tool.
+const EditFileToolName = "edit_file"
+
+// SearchReplace is a single targeted replacement applied by the edit_file tool.
+type SearchReplace struct {
+	Search  string `json:"search" description:"Exact text to find; must match exactly once in the file"`
+	Replace string `json:"replace" description:"Text to replace it with"`
+}
+
+// EditFileParams are the parameters for the edit_file tool.
+type EditFileParams struct {
+	FilePath string          `json:"file_path" description:"The absolute path to the file to modify"`
+	Edits    []SearchReplace `json:"edits,omitempty" description:"Search/replace blocks applied in order"`
+	Patch    string          `json:"patch,omitempty" description:"A unified diff to apply to the file"`
+}
+
+// EditFileResponseMetadata provides metadata about the edit_file operation.
+type EditFileResponseMetadata struct {
+	FilePath  string `json:"file_path"`
+	Diff      string `json:"diff"`
+	Additions int    `json:"additions"`
+	Removals  int    `json:"removals"`
+}
+
+const editFileDescription = `Applies targeted changes to an existing file using search/replace blocks or a unified diff.
+
+Usage:
+- You must use the read_file tool at least once before editing a file
+- Provide either "edits" or "patch", not both
+- edits: each block's "search" text must appear exactly once in the file at the time it is applied; blocks are applied in order
+- patch: a unified diff (as produced by "diff -u" or "git diff") with @@ hunk headers and context lines; hunks whose line numbers have drifted are located by their context
+- The whole change is applied atomically: if any block or hunk fails, the file is left untouched
+- Prefer this tool over rewriting the whole file for small or scattered changes`
+
+// NewEditFileTool creates a new edit_file tool.
+// When hub is non-nil, the resulting diff is published as a tool progress event.
+func NewEditFileTool(workingDir string, hub *pubsub.Hub) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		EditFileToolName,
+		editFileDescription,
+		func(ctx context.Context, params EditFileParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			workingDir := sessionDir(ctx, workingDir)
+			if params.FilePath == "" {
+				return fantasy.NewTextErrorResponse("file_path is required"), nil
+			}
+			if (len(params.Edits) == 0) == (params.Patch == "") {
+				return fantasy.NewTextErrorResponse("provide exactly one of edits or patch"), nil
+			}
+
+			filePath := ResolvePath(workingDir, params.FilePath)
+
+			fileInfo, err := os.Stat(filePath)
+			if err != nil {
+				if os.IsNotExist(err) {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("file not found: %s", filePath)), nil
+				}
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to access file: %w", err)
+			}
+			if fileInfo.IsDir() {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("path is a directory, not a file: %s", filePath)), nil
+			}
+
+			// Check if file was read first
+			lastRead := GetLastReadTime(filePath)
+			if lastRead.IsZero() {
+				return fantasy.NewTextErrorResponse("you must read the file before editing it. Use the read_file tool first"), nil
+			}
+			if modTime := fileInfo.ModTime(); modTime.After(lastRead) {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf(
+					"file %s has been modified since it was last read (mod time: %s, last read: %s)",
+					filePath, modTime.Format(time.RFC3339), lastRead.Format(time.RFC3339))), nil
+			}
+
+			content, err := os.ReadFile(filePath) //nolint:gosec // G304: File path is validated above
+			if err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to read file: %w", err)
+			}
+			oldContent := normalizeLineEndings(string(content))
+
+			var newContent string
+			if params.Patch != "" {
+				newContent, err = ApplyUnifiedDiff(oldContent, params.Patch)
+			} else {
+				newContent, err = applySearchReplace(oldContent, params.Edits)
+			}
+			if err != nil {
+				return fantasy.NewTextErrorResponse(err.Error()), nil
+			}
+
+			if oldContent == newContent {
+				return fantasy.NewTextErrorResponse("new content is the same as old content. No changes made."), nil
+			}
+
+			if err := os.WriteFile(filePath, []byte(newContent), fileInfo.Mode().Perm()); err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to write file: %w", err)
+			}
+
+			RecordFileWrite(filePath)
+			RecordFileRead(filePath)
+			recordChange(ctx, filePath, EditFileToolName, content, true, []byte(newContent))
+
+			diff := UnifiedDiff(params.FilePath, oldContent, newContent)
+			additions, removals := DiffStats(diff)
+
+			if hub != nil {
+				hub.Tool.Publish(pubsub.EventProgress,
+					events.NewToolDiffEvent(SessionIDFromContext(ctx), call.ID, EditFileToolName, filePath, diff))
+			}
+
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(fmt.Sprintf("File edited: %s (+%d -%d)", filePath, additions, removals)),
+				EditFileResponseMetadata{
+					FilePath:  filePath,
+					Diff:      diff,
+					Additions: additions,
+					Removals:  removals,
+				},
+			), nil
+		})
+}
+
+// applySearchReplace applies each edit in order, requiring every search text to match exactly once.
+func applySearchReplace(content string, edits []SearchReplace) (string, error) {
+	for i, edit := range edits {
+		search := normalizeLineEndings(edit.Search)
+		if search == "" {
+			return "", fmt.Errorf("edit %d: search must not be empty", i+1)
+		}
+
+		switch n := strings.Count(content, search); n {
+		case 0:
+			return "", fmt.Errorf("edit %d: search text not found in file. Make sure it matches exactly, including whitespace and line breaks", i+1)
+		case 1:
+			content = strings.Replace(content, search, normalizeLineEndings(edit.Replace), 1)
+		default:
+			return "", fmt.Errorf("edit %d: search text appears %d times in the file. Include more surrounding context to make it unique", i+1, n)
+		}
+	}
+	return content, nil
+}
@@ -0,0 +1,230 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+func TestEditFileTool(t *testing.T) {
+	tmpDir := t.TempDir()
+	tool := NewEditFileTool(tmpDir, nil)
+	ctx := context.Background()
+
+	writeFile := func(t *testing.T, name, content string) string {
+		t.Helper()
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		RecordFileRead(path)
+		return path
+	}
+
+	readFile := func(t *testing.T, path string) string {
+		t.Helper()
+		data, err := os.ReadFile(path) //nolint:gosec // G304: Test file path is controlled
+		if err != nil {
+			t.Fatalf("Failed to read file: %v", err)
+		}
+		return string(data)
+	}
+
+	t.Run("applies search/replace blocks in order", func(t *testing.T) {
+		ClearFileRecords()
+		path := writeFile(t, "edits.txt", "alpha\nbeta\ngamma\n")
+
+		resp, err := invokeEditFileTool(ctx, tool, EditFileParams{
+			FilePath: path,
+			Edits: []SearchReplace{
+				{Search: "alpha", Replace: "ALPHA"},
+				{Search: "ALPHA\nbeta", Replace: "ALPHA\nBETA"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.IsError {
+			t.Fatalf("Unexpected error response: %s", getTextContent(resp))
+		}
+		if got := readFile(t, path); got != "ALPHA\nBETA\ngamma\n" {
+			t.Errorf("unexpected content %q", got)
+		}
+		if !strings.Contains(getTextContent(resp), "+2 -2") {
+			t.Errorf("expected diff stats in response, got %q", getTextContent(resp))
+		}
+	})
+
+	t.Run("applies a unified diff", func(t *testing.T) {
+		ClearFileRecords()
+		path := writeFile(t, "patch.txt", "one\ntwo\nthree\n")
+
+		resp, err := invokeEditFileTool(ctx, tool, EditFileParams{
+			FilePath: path,
+			Patch:    "--- a/patch.txt\n+++ b/patch.txt\n@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.IsError {
+			t.Fatalf("Unexpected error response: %s", getTextContent(resp))
+		}
+		if got := readFile(t, path); got != "one\n2\nthree\n" {
+			t.Errorf("unexpected content %q", got)
+		}
+	})
+
+	t.Run("leaves file untouched when a block fails", func(t *testing.T) {
+		ClearFileRecords()
+		path := writeFile(t, "atomic.txt", "a\nb\n")
+
+		resp, err := invokeEditFileTool(ctx, tool, EditFileParams{
+			FilePath: path,
+			Edits: []SearchReplace{
+				{Search: "a", Replace: "A"},
+				{Search: "missing", Replace: "x"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !resp.IsError {
+			t.Error("Expected error response for missing search text")
+		}
+		if got := readFile(t, path); got != "a\nb\n" {
+			t.Errorf("file should be unchanged, got %q", got)
+		}
+	})
+
+	t.Run("rejects ambiguous search", func(t *testing.T) {
+		ClearFileRecords()
+		path := writeFile(t, "ambiguous.txt", "x\nx\n")
+
+		resp, err := invokeEditFileTool(ctx, tool, EditFileParams{
+			FilePath: path,
+			Edits:    []SearchReplace{{Search: "x", Replace: "y"}},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !resp.IsError || !strings.Contains(getTextContent(resp), "2 times") {
+			t.Errorf("Expected ambiguity error, got %q", getTextContent(resp))
+		}
+	})
+
+	t.Run("requires exactly one mode", func(t *testing.T) {
+		ClearFileRecords()
+		path := writeFile(t, "modes.txt", "a\n")
+
+		for _, params := range []EditFileParams{
+			{FilePath: path},
+			{FilePath: path, Patch: "@@ -1 +1 @@\n-a\n+b\n", Edits: []SearchReplace{{Search: "a", Replace: "b"}}},
+		} {
+			resp, err := invokeEditFileTool(ctx, tool, params)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !resp.IsError {
+				t.Errorf("Expected error response for params %+v", params)
+			}
+		}
+	})
+
+	t.Run("requires read before edit", func(t *testing.T) {
+		ClearFileRecords()
+		path := filepath.Join(tmpDir, "unread.txt")
+		if err := os.WriteFile(path, []byte("a\n"), 0o600); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		resp, err := invokeEditFileTool(ctx, tool, EditFileParams{
+			FilePath: path,
+			Edits:    []SearchReplace{{Search: "a", Replace: "b"}},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !resp.IsError {
+			t.Error("Expected error response for unread file")
+		}
+	})
+
+	t.Run("file not found", func(t *testing.T) {
+		resp, err := invokeEditFileTool(ctx, tool, EditFileParams{
+			FilePath: filepath.Join(tmpDir, "missing.txt"),
+			Edits:    []SearchReplace{{Search: "a", Replace: "b"}},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !resp.IsError {
+			t.Error("Expected error response for missing file")
+		}
+	})
+}
+
+func TestEditFileTool_PublishesDiff(t *testing.T) {
+	ClearFileRecords()
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	RecordFileRead(path)
+
+	hub := pubsub.NewHub()
+	defer hub.Shutdown()
+
+	subCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := hub.Tool.Subscribe(subCtx)
+
+	tool := NewEditFileTool(tmpDir, hub)
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "session-1")
+	resp, err := invokeEditFileTool(ctx, tool, EditFileParams{
+		FilePath: "main.go",
+		Edits:    []SearchReplace{{Search: "main", Replace: "app"}},
+	})
+	if err != nil || resp.IsError {
+		t.Fatalf("Unexpected failure: %v %s", err, getTextContent(resp))
+	}
+
+	select {
+	case event := <-ch:
+		payload := event.Payload
+		if payload.Type != events.ToolEventProgress || payload.SessionID != "session-1" {
+			t.Errorf("unexpected event %+v", payload)
+		}
+		if payload.FilePath != path {
+			t.Errorf("expected FilePath %q, got %q", path, payload.FilePath)
+		}
+		if !strings.Contains(payload.Diff, "-package main") || !strings.Contains(payload.Diff, "+package app") {
+			t.Errorf("unexpected diff:\n%s", payload.Diff)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected diff event")
+	}
+}
+
+func invokeEditFileTool(ctx context.Context, tool fantasy.AgentTool, params EditFileParams) (fantasy.ToolResponse, error) {
+	inputJSON, err := json.Marshal(params)
+	if err != nil {
+		return fantasy.ToolResponse{}, err
+	}
+
+	call := fantasy.ToolCall{
+		ID:    "test-call",
+		Name:  EditFileToolName,
+		Input: string(inputJSON),
+	}
+	return tool.Run(ctx, call)
+}
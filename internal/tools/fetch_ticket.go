@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/tickets"
+)
+
+// FetchTicketToolName is the name of the ticket fetch tool.
+const FetchTicketToolName = "fetch_ticket"
+
+// FetchTicketParams are the parameters for the fetch_ticket tool.
+type FetchTicketParams struct {
+	ID      string `json:"id" description:"The ticket ID, e.g. PROJ-123"`
+	Tracker string `json:"tracker,omitempty" description:"Tracker to read from, 'jira' or 'linear' (default: each configured one in turn)"`
+}
+
+const fetchTicketDescription = `Fetches an issue from the team's issue tracker (%s) with its title, status, description and acceptance criteria.
+
+Usage:
+- Use it whenever the user refers to a ticket by ID, e.g. "implement PROJ-123", before planning the work
+- Treat the acceptance criteria as the definition of done
+- Pass tracker only when the same ID could exist in more than one tracker`
+
+// NewFetchTicketTool creates a tool that reads tickets through client.
+func NewFetchTicketTool(client *tickets.Client) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		FetchTicketToolName,
+		fmt.Sprintf(fetchTicketDescription, strings.Join(client.Trackers(), ", ")),
+		func(ctx context.Context, params FetchTicketParams, _ fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if strings.TrimSpace(params.ID) == "" {
+				return fantasy.NewTextErrorResponse("id cannot be empty"), nil
+			}
+			ticket, err := client.Fetch(ctx, params.ID, params.Tracker)
+			if errors.Is(err, tickets.ErrNotFound) {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("Ticket %s was not found in %s", params.ID, strings.Join(client.Trackers(), " or "))), nil
+			}
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("Error fetching ticket %s: %v", params.ID, err)), nil
+			}
+			return fantasy.NewTextResponse(ticket.String()), nil
+		})
+}
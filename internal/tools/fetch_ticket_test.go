@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/tickets"
+)
+
+func TestFetchTicketTool(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/issue/PROJ-123" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, `{"key": "PROJ-123", "fields": {"summary": "Export to CSV", "description": "Add an export button."}}`) //nolint:errcheck // Test server
+	}))
+	defer server.Close()
+
+	client, err := tickets.New(&config.IntegrationsConfig{Jira: &config.JiraIntegration{BaseURL: server.URL, Token: "t"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := NewFetchTicketTool(client)
+
+	resp, err := tool.Run(context.Background(), fantasy.ToolCall{ID: "call", Name: FetchTicketToolName, Input: `{"id": "PROJ-123"}`})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if resp.IsError || !strings.HasPrefix(resp.Content, "PROJ-123: Export to CSV") || !strings.Contains(resp.Content, "Add an export button.") {
+		t.Errorf("fetch_ticket = %q, want the ticket", resp.Content)
+	}
+
+	resp, _ = tool.Run(context.Background(), fantasy.ToolCall{ID: "call", Name: FetchTicketToolName, Input: `{"id": "PROJ-9"}`})
+	if !resp.IsError || !strings.Contains(resp.Content, "not found") {
+		t.Errorf("fetch_ticket for a missing ticket = %q, want a not found error", resp.Content)
+	}
+}
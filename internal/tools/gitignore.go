@@ -0,0 +1,182 @@
+package tools
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule is a single pattern from a .gitignore file.
+type ignoreRule struct {
+	base     string // Directory of the .gitignore, relative to the repository root
+	pattern  string
+	negate   bool // Pattern started with "!"
+	dirOnly  bool // Pattern ended with "/"
+	anchored bool // Pattern contains a slash, so it matches relative to base
+}
+
+// ignoreMatcher applies the .gitignore files of a repository while walking it.
+// Directories must be visited before their contents, as filepath.Walk does.
+type ignoreMatcher struct {
+	root   string
+	rules  []ignoreRule
+	loaded map[string]bool
+}
+
+// newIgnoreMatcher creates a matcher for a walk starting at searchPath. The
+// .gitignore files of the enclosing repository down to searchPath are loaded
+// up front; the ones below it are loaded by enterDir during the walk.
+func newIgnoreMatcher(searchPath string) *ignoreMatcher {
+	root := findRepoRoot(searchPath)
+	m := &ignoreMatcher{root: root, loaded: make(map[string]bool)}
+
+	rel, err := filepath.Rel(root, searchPath)
+	if err != nil {
+		return m
+	}
+	dir := root
+	m.enterDir(dir)
+	if rel != "." {
+		for _, part := range strings.Split(rel, string(filepath.Separator)) {
+			dir = filepath.Join(dir, part)
+			m.enterDir(dir)
+		}
+	}
+	return m
+}
+
+// findRepoRoot returns the closest directory at or above dir that contains
+// .git, or dir itself when it is not inside a repository.
+func findRepoRoot(dir string) string {
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// enterDir loads the .gitignore of dir, if any.
+func (m *ignoreMatcher) enterDir(dir string) {
+	if m.loaded[dir] {
+		return
+	}
+	m.loaded[dir] = true
+
+	base, err := filepath.Rel(m.root, dir)
+	if err != nil {
+		return
+	}
+	base = filepath.ToSlash(base)
+
+	f, err := os.Open(filepath.Join(dir, ".gitignore")) //nolint:gosec // G304: Path comes from directory walk
+	if err != nil {
+		return
+	}
+	defer f.Close() //nolint:errcheck // Error on close for read-only file is ignorable
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(base, scanner.Text()); ok {
+			m.rules = append(m.rules, rule)
+		}
+	}
+}
+
+// parseIgnoreRule parses a .gitignore line. Blank lines and comments yield false.
+func parseIgnoreRule(base, line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, `\`)
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+	rule.pattern = line
+	return rule, true
+}
+
+// ignored reports whether the file or directory at p is ignored. The last
+// matching rule wins, so negations can re-include paths.
+func (m *ignoreMatcher) ignored(p string, isDir bool) bool {
+	rel, err := filepath.Rel(m.root, p)
+	if err != nil || rel == "." {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	ignored := false
+	for i := range m.rules {
+		rule := &m.rules[i]
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.matches(rel) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// matches reports whether the rule applies to rel, a slash-separated path
+// relative to the repository root.
+func (r *ignoreRule) matches(rel string) bool {
+	sub := rel
+	if r.base != "." {
+		var ok bool
+		if sub, ok = strings.CutPrefix(rel, r.base+"/"); !ok {
+			return false
+		}
+	}
+	if !r.anchored {
+		// Parent directories are checked as the walk enters them, so
+		// unanchored patterns only need to match the last component.
+		matched, _ := path.Match(r.pattern, path.Base(sub)) //nolint:errcheck // Malformed patterns never match
+		return matched
+	}
+	return matchPathSegments(strings.Split(r.pattern, "/"), strings.Split(sub, "/"))
+}
+
+// matchPathSegments matches path segments against pattern segments, where a
+// "**" segment matches zero or more path segments.
+func matchPathSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchPathSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], segments[0]); !matched { //nolint:errcheck // Malformed patterns never match
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestIgnoreRuleMatches(t *testing.T) {
+	tests := []struct {
+		line    string
+		base    string
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{line: "*.log", base: ".", path: "logs/app.log", ignored: true},
+		{line: "*.log", base: ".", path: "app.go"},
+		{line: "build/", base: ".", path: "build", isDir: true, ignored: true},
+		{line: "build/", base: ".", path: "build"},
+		{line: "/dist", base: ".", path: "dist", isDir: true, ignored: true},
+		{line: "/dist", base: ".", path: "web/dist", isDir: true},
+		{line: "docs/*.md", base: ".", path: "docs/a.md", ignored: true},
+		{line: "docs/*.md", base: ".", path: "docs/sub/a.md"},
+		{line: "a/**/z.txt", base: ".", path: "a/b/c/z.txt", ignored: true},
+		{line: "a/**/z.txt", base: ".", path: "a/z.txt", ignored: true},
+		{line: "gen.go", base: "pkg", path: "pkg/sub/gen.go", ignored: true},
+		{line: "gen.go", base: "pkg", path: "other/gen.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line+" "+tt.path, func(t *testing.T) {
+			rule, ok := parseIgnoreRule(tt.base, tt.line)
+			if !ok {
+				t.Fatalf("parseIgnoreRule(%q) rejected the line", tt.line)
+			}
+			m := &ignoreMatcher{root: "/repo", rules: []ignoreRule{rule}}
+			if got := m.ignored(filepath.Join("/repo", tt.path), tt.isDir); got != tt.ignored {
+				t.Errorf("ignored(%q) = %v, want %v", tt.path, got, tt.ignored)
+			}
+		})
+	}
+}
+
+func TestParseIgnoreRule_SkipsCommentsAndBlanks(t *testing.T) {
+	for _, line := range []string{"", "   ", "# comment", "!"} {
+		if _, ok := parseIgnoreRule(".", line); ok {
+			t.Errorf("parseIgnoreRule(%q) should be skipped", line)
+		}
+	}
+}
+
+func TestWalkSearchFiles_RespectsGitignore(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":          "*.log\nbuild/\n!keep.log\n",
+		"main.go":             "package main",
+		"debug.log":           "noise",
+		"keep.log":            "kept",
+		"build/out.go":        "package build",
+		"pkg/.gitignore":      "generated.go\n",
+		"pkg/generated.go":    "package pkg",
+		"pkg/pkg.go":          "package pkg",
+		"other/generated.go":  "package other",
+		"node_modules/x/a.js": "x",
+	}
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	collect := func(start string) []string {
+		var got []string
+		err := walkSearchFiles(context.Background(), start, func(path string, _ os.FileInfo) error {
+			got = append(got, displayPath(root, path))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("walkSearchFiles() error = %v", err)
+		}
+		slices.Sort(got)
+		return got
+	}
+
+	want := []string{"keep.log", "main.go", "other/generated.go", "pkg/pkg.go"}
+	if got := collect(root); !slices.Equal(got, want) {
+		t.Errorf("walk from root = %v, want %v", got, want)
+	}
+
+	// Starting below the repository root still applies the root .gitignore.
+	if got := collect(filepath.Join(root, "pkg")); !slices.Equal(got, []string{"pkg/pkg.go"}) {
+		t.Errorf("walk from pkg = %v, want [pkg/pkg.go]", got)
+	}
+}
+
+func TestListFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, path := range []string{".gitignore", "a.go", "b.go", "sub/c.go", "out.log"} {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte("*.log\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := ListFiles(context.Background(), root, 10)
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	slices.Sort(got)
+	if want := []string{"a.go", "b.go", "sub/c.go"}; !slices.Equal(got, want) {
+		t.Errorf("ListFiles() = %v, want %v", got, want)
+	}
+
+	if got, _ := ListFiles(context.Background(), root, 2); len(got) != 2 {
+		t.Errorf("ListFiles() with limit 2 returned %v", got)
+	}
+}
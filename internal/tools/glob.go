@@ -2,7 +2,6 @@ package tools
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -19,12 +18,14 @@ const GlobToolName = "glob"
 type GlobParams struct {
 	Pattern string `json:"pattern" description:"The glob pattern to match files against (e.g., '**/*.go', 'src/**/*.ts')"`
 	Path    string `json:"path,omitempty" description:"The directory to search in. Defaults to the current working directory."`
+	Limit   int    `json:"limit,omitempty" description:"Maximum number of files to return (default 100, max 1000)"`
 }
 
 // GlobResponseMetadata provides metadata about the glob operation.
 type GlobResponseMetadata struct {
-	NumberOfFiles int  `json:"number_of_files"`
-	Truncated     bool `json:"truncated"`
+	NumberOfFiles int      `json:"number_of_files"`
+	Truncated     bool     `json:"truncated"`
+	Files         []string `json:"files,omitempty"`
 }
 
 const globDescription = `Fast file pattern matching tool that works with any codebase size.
@@ -32,10 +33,14 @@ const globDescription = `Fast file pattern matching tool that works with any cod
 Usage:
 - Supports glob patterns like "**/*.js" or "src/**/*.ts"
 - Returns matching file paths sorted by modification time
+- Skips hidden files, dependency directories, and files ignored by .gitignore
 - Use this tool when you need to find files by name patterns
-- Results are limited to 100 files by default`
+- Results are limited to 100 files by default; raise limit (up to 1000) when truncated`
 
-const globLimit = 100
+const (
+	globLimit    = 100
+	maxGlobLimit = 1000
+)
 
 // NewGlobTool creates a new glob tool.
 func NewGlobTool(workingDir string) fantasy.AgentTool {
@@ -43,6 +48,7 @@ func NewGlobTool(workingDir string) fantasy.AgentTool {
 		GlobToolName,
 		globDescription,
 		func(ctx context.Context, params GlobParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			workingDir := sessionDir(ctx, workingDir)
 			if params.Pattern == "" {
 				return fantasy.NewTextErrorResponse("pattern is required"), nil
 			}
@@ -62,7 +68,8 @@ func NewGlobTool(workingDir string) fantasy.AgentTool {
 				return fantasy.ToolResponse{}, fmt.Errorf("error accessing directory: %w", err)
 			}
 
-			files, truncated, err := globFiles(ctx, params.Pattern, searchPath, globLimit)
+			limit := clampLimit(params.Limit, globLimit, maxGlobLimit)
+			files, truncated, err := globFiles(ctx, params.Pattern, searchPath, limit)
 			if err != nil {
 				return fantasy.NewTextErrorResponse(fmt.Sprintf("Error finding files: %v", err)), nil
 			}
@@ -73,11 +80,11 @@ func NewGlobTool(workingDir string) fantasy.AgentTool {
 			} else {
 				// Normalize paths for output
 				for i, f := range files {
-					files[i] = filepath.ToSlash(f)
+					files[i] = displayPath(workingDir, f)
 				}
 				output = strings.Join(files, "\n")
 				if truncated {
-					output += "\n\n(Results are truncated. Consider using a more specific path or pattern.)"
+					output += "\n\n(Results are truncated. Raise limit or use a more specific path or pattern.)"
 				}
 			}
 
@@ -86,6 +93,7 @@ func NewGlobTool(workingDir string) fantasy.AgentTool {
 				GlobResponseMetadata{
 					NumberOfFiles: len(files),
 					Truncated:     truncated,
+					Files:         files,
 				},
 			), nil
 		})
@@ -96,44 +104,13 @@ type fileInfo struct {
 	modTime int64
 }
 
-//nolint:gocyclo // Complex file walking logic with multiple skip conditions
 func globFiles(ctx context.Context, pattern, searchPath string, limit int) (files []string, truncated bool, err error) {
 	var matches []fileInfo
 
 	// Handle ** patterns
 	hasDoublestar := strings.Contains(pattern, "**")
 
-	err = filepath.Walk(searchPath, func(path string, info os.FileInfo, walkErr error) error {
-		// Check context cancellation
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
-
-		if walkErr != nil {
-			return nil //nolint:nilerr // Skip inaccessible files, continue walking
-		}
-
-		// Skip directories themselves, but continue walking into them
-		if info.IsDir() {
-			// Skip hidden directories
-			if info.Name() != "." && strings.HasPrefix(info.Name(), ".") {
-				return filepath.SkipDir
-			}
-			// Skip common non-source directories
-			switch info.Name() {
-			case "node_modules", "vendor", "__pycache__", ".git":
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		// Skip hidden files
-		if strings.HasPrefix(info.Name(), ".") {
-			return nil
-		}
-
+	err = walkSearchFiles(ctx, searchPath, func(path string, info os.FileInfo) error {
 		// Get relative path for matching
 		relPath, relErr := filepath.Rel(searchPath, path)
 		if relErr != nil {
@@ -158,8 +135,7 @@ func globFiles(ctx context.Context, pattern, searchPath string, limit int) (file
 
 		return nil
 	})
-
-	if err != nil && !errors.Is(err, filepath.SkipAll) {
+	if err != nil {
 		return nil, false, err
 	}
 
@@ -21,6 +21,7 @@ const (
 	GrepToolName        = "grep"
 	maxGrepContentWidth = 500
 	grepLimit           = 100
+	maxGrepLimit        = 1000
 )
 
 // GrepParams are the parameters for the grep tool.
@@ -29,12 +30,24 @@ type GrepParams struct {
 	Path        string `json:"path,omitempty" description:"The directory to search in. Defaults to the current working directory."`
 	Include     string `json:"include,omitempty" description:"File pattern to include in the search (e.g., '*.go', '*.{ts,tsx}')"`
 	LiteralText bool   `json:"literal_text,omitempty" description:"If true, the pattern will be treated as literal text. Default is false."`
+	IgnoreCase  bool   `json:"ignore_case,omitempty" description:"If true, match case-insensitively. Default is false."`
+	Limit       int    `json:"limit,omitempty" description:"Maximum number of matching lines to return (default 100, max 1000)"`
+}
+
+// GrepMatch is a single matching line.
+type GrepMatch struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Text   string `json:"text"`
 }
 
 // GrepResponseMetadata provides metadata about the grep operation.
 type GrepResponseMetadata struct {
-	NumberOfMatches int  `json:"number_of_matches"`
-	Truncated       bool `json:"truncated"`
+	NumberOfMatches int         `json:"number_of_matches"`
+	NumberOfFiles   int         `json:"number_of_files"`
+	Truncated       bool        `json:"truncated"`
+	Matches         []GrepMatch `json:"matches,omitempty"`
 }
 
 const grepDescription = `A powerful search tool for searching file contents.
@@ -43,8 +56,10 @@ Usage:
 - Supports full regex syntax (e.g., "log.*Error", "function\s+\w+")
 - Filter files with the include parameter (e.g., "*.js", "*.{ts,tsx}")
 - Use literal_text=true to search for exact text without regex interpretation
-- Results are sorted by file modification time (most recent first)
-- Results are limited to 100 matches by default`
+- Use ignore_case=true for case-insensitive search
+- Skips hidden files, dependency directories, and files ignored by .gitignore
+- Returns every matching line as "path:line:column: text", grouped by file, with the most recently modified files first
+- Results are limited to 100 lines by default; raise limit (up to 1000) or narrow the search when truncated`
 
 // grepMatch represents a single grep match.
 type grepMatch struct {
@@ -91,6 +106,7 @@ func NewGrepTool(workingDir string) fantasy.AgentTool {
 		GrepToolName,
 		grepDescription,
 		func(ctx context.Context, params GrepParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			workingDir := sessionDir(ctx, workingDir)
 			if params.Pattern == "" {
 				return fantasy.NewTextErrorResponse("pattern is required"), nil
 			}
@@ -100,6 +116,9 @@ func NewGrepTool(workingDir string) fantasy.AgentTool {
 			if params.LiteralText {
 				searchPattern = regexp.QuoteMeta(params.Pattern)
 			}
+			if params.IgnoreCase {
+				searchPattern = "(?i)" + searchPattern
+			}
 
 			searchPath := params.Path
 			if searchPath == "" {
@@ -116,55 +135,72 @@ func NewGrepTool(workingDir string) fantasy.AgentTool {
 				return fantasy.ToolResponse{}, fmt.Errorf("error accessing directory: %w", err)
 			}
 
-			matches, truncated, err := searchFiles(ctx, searchPattern, searchPath, params.Include, grepLimit)
+			limit := clampLimit(params.Limit, grepLimit, maxGrepLimit)
+			matches, truncated, err := searchFiles(ctx, searchPattern, searchPath, params.Include, limit)
 			if err != nil {
 				return fantasy.NewTextErrorResponse(fmt.Sprintf("Error searching files: %v", err)), nil
 			}
 
-			var output strings.Builder
-			if len(matches) == 0 {
-				output.WriteString("No matches found")
-			} else {
-				fmt.Fprintf(&output, "Found %d matches\n", len(matches))
-
-				currentFile := ""
-				for _, match := range matches {
-					if currentFile != match.path {
-						if currentFile != "" {
-							output.WriteString("\n")
-						}
-						currentFile = match.path
-						fmt.Fprintf(&output, "%s:\n", filepath.ToSlash(match.path))
-					}
-
-					lineText := match.lineText
-					if len(lineText) > maxGrepContentWidth {
-						lineText = lineText[:maxGrepContentWidth] + "..."
-					}
-
-					if match.charNum > 0 {
-						fmt.Fprintf(&output, "  Line %d, Char %d: %s\n", match.lineNum, match.charNum, lineText)
-					} else {
-						fmt.Fprintf(&output, "  Line %d: %s\n", match.lineNum, lineText)
-					}
+			results := make([]GrepMatch, len(matches))
+			files := 0
+			for i := range matches {
+				if i == 0 || matches[i].path != matches[i-1].path {
+					files++
 				}
-
-				if truncated {
-					output.WriteString("\n(Results are truncated. Consider using a more specific path or pattern.)")
+				lineText := matches[i].lineText
+				if len(lineText) > maxGrepContentWidth {
+					lineText = lineText[:maxGrepContentWidth] + "..."
+				}
+				results[i] = GrepMatch{
+					File:   displayPath(workingDir, matches[i].path),
+					Line:   matches[i].lineNum,
+					Column: matches[i].charNum,
+					Text:   lineText,
 				}
 			}
 
 			return fantasy.WithResponseMetadata(
-				fantasy.NewTextResponse(output.String()),
+				fantasy.NewTextResponse(formatGrepOutput(results, files, truncated)),
 				GrepResponseMetadata{
-					NumberOfMatches: len(matches),
+					NumberOfMatches: len(results),
+					NumberOfFiles:   files,
 					Truncated:       truncated,
+					Matches:         results,
 				},
 			), nil
 		})
 }
 
-//nolint:gocyclo // Complex file walking and pattern matching logic
+// formatGrepOutput renders matches one per line, with a blank line between files.
+func formatGrepOutput(matches []GrepMatch, files int, truncated bool) string {
+	if len(matches) == 0 {
+		return "No matches found"
+	}
+
+	var output strings.Builder
+	fmt.Fprintf(&output, "Found %d matches in %d files\n", len(matches), files)
+	for i := range matches {
+		if i > 0 && matches[i].File != matches[i-1].File {
+			output.WriteString("\n")
+		}
+		fmt.Fprintf(&output, "%s:%d:%d: %s\n", matches[i].File, matches[i].Line, matches[i].Column, matches[i].Text)
+	}
+	if truncated {
+		output.WriteString("\n(Results are truncated. Raise limit or use a more specific path, pattern, or include.)")
+	}
+	return output.String()
+}
+
+// clampLimit returns limit, or def when it is unset, capped at maxLimit.
+func clampLimit(limit, def, maxLimit int) int {
+	if limit <= 0 {
+		return def
+	}
+	return min(limit, maxLimit)
+}
+
+// searchFiles returns up to limit matching lines under rootPath, grouped by
+// file with the most recently modified files first.
 func searchFiles(ctx context.Context, pattern, rootPath, include string, limit int) ([]grepMatch, bool, error) {
 	regex, err := getCachedRegex(pattern)
 	if err != nil {
@@ -180,73 +216,50 @@ func searchFiles(ctx context.Context, pattern, rootPath, include string, limit i
 		}
 	}
 
-	var matches []grepMatch
-
-	err = filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
-		// Check context cancellation
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
-
-		if err != nil {
-			return nil //nolint:nilerr // Skip inaccessible files, continue walking
-		}
-
-		if info.IsDir() {
-			// Skip hidden directories
-			if info.Name() != "." && strings.HasPrefix(info.Name(), ".") {
-				return filepath.SkipDir
-			}
-			// Skip common non-source directories
-			switch info.Name() {
-			case "node_modules", "vendor", "__pycache__", ".git":
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		// Skip hidden files
-		if strings.HasPrefix(info.Name(), ".") {
-			return nil
-		}
+	var fileMatches [][]grepMatch
+	total := 0
 
+	err = walkSearchFiles(ctx, rootPath, func(path string, info os.FileInfo) error {
 		// Check include pattern
 		if includePattern != nil && !includePattern.MatchString(path) {
 			return nil
 		}
 
 		// Search file for pattern (combines text detection and pattern search in single read)
-		match, lineNum, charNum, lineText, err := searchTextFile(path, regex)
-		if err != nil || !match {
+		matches, err := searchTextFile(path, regex, limit+1)
+		if err != nil || len(matches) == 0 {
 			return nil //nolint:nilerr // Skip files with read errors or non-text files, continue walking
 		}
+		for i := range matches {
+			matches[i].modTime = info.ModTime().UnixNano()
+		}
+		fileMatches = append(fileMatches, matches)
 
-		matches = append(matches, grepMatch{
-			path:     path,
-			modTime:  info.ModTime().UnixNano(),
-			lineNum:  lineNum,
-			charNum:  charNum,
-			lineText: lineText,
-		})
-
-		if len(matches) >= limit*2 {
+		// Collect extra matches so sorting by recency has some choice.
+		total += len(matches)
+		if total > limit*2 {
 			return filepath.SkipAll
 		}
 
 		return nil
 	})
-
-	if err != nil && !errors.Is(err, filepath.SkipAll) {
+	if err != nil {
 		return nil, false, err
 	}
 
-	// Sort by modification time (most recent first)
-	sort.Slice(matches, func(i, j int) bool {
-		return matches[i].modTime > matches[j].modTime
+	// Sort files by modification time (most recent first)
+	sort.SliceStable(fileMatches, func(i, j int) bool {
+		return fileMatches[i][0].modTime > fileMatches[j][0].modTime
 	})
 
+	matches := make([]grepMatch, 0, min(total, limit+1))
+	for _, fm := range fileMatches {
+		matches = append(matches, fm...)
+		if len(matches) > limit {
+			break
+		}
+	}
+
 	truncated := len(matches) > limit
 	if truncated {
 		matches = matches[:limit]
@@ -255,38 +268,31 @@ func searchFiles(ctx context.Context, pattern, rootPath, include string, limit i
 	return matches, truncated, nil
 }
 
-// searchTextFile opens a file once, checks if it's a text file, and searches for a pattern.
+// searchTextFile opens a file once, checks if it's a text file, and returns up
+// to limit lines matching pattern.
 // This combines MIME detection and pattern search in a single file read for efficiency.
-// Returns (false, 0, 0, "", nil) for non-text files (not an error).
-func searchTextFile(filePath string, pattern *regexp.Regexp) (found bool, lineNum, charNum int, lineText string, err error) {
+// Returns no matches for non-text files (not an error).
+func searchTextFile(filePath string, pattern *regexp.Regexp, limit int) ([]grepMatch, error) {
 	file, err := os.Open(filePath) //nolint:gosec // G304: File path comes from directory walk
 	if err != nil {
-		return false, 0, 0, "", err
+		return nil, err
 	}
 	defer file.Close() //nolint:errcheck // Error on close for read-only file is ignorable
 
-	// Read first 512 bytes for MIME type detection
-	header := make([]byte, 512)
+	// Read the first bytes for MIME type detection
+	header := make([]byte, sniffLen)
 	n, err := file.Read(header)
 	if err != nil && !errors.Is(err, io.EOF) {
-		return false, 0, 0, "", err
+		return nil, err
 	}
 
-	// Check if it's a text file
-	contentType := http.DetectContentType(header[:n])
-	isText := strings.HasPrefix(contentType, "text/") ||
-		contentType == "application/json" ||
-		contentType == "application/xml" ||
-		contentType == "application/javascript" ||
-		contentType == "application/x-sh"
-
-	if !isText {
-		return false, 0, 0, "", nil // Not a text file, not an error
+	if !isTextContent(header[:n], http.DetectContentType(header[:n])) {
+		return nil, nil // Not a text file, not an error
 	}
 
 	// Seek back to beginning to search the entire file
 	if _, err := file.Seek(0, io.SeekStart); err != nil {
-		return false, 0, 0, "", err
+		return nil, err
 	}
 
 	// Now search for pattern
@@ -295,17 +301,25 @@ func searchTextFile(filePath string, pattern *regexp.Regexp) (found bool, lineNu
 	buf := make([]byte, 0, 64*1024)
 	scanner.Buffer(buf, 1024*1024)
 
-	lineNum = 0
+	var matches []grepMatch
+	lineNum := 0
 	for scanner.Scan() {
 		lineNum++
 		line := scanner.Text()
 		if loc := pattern.FindStringIndex(line); loc != nil {
-			charNum = loc[0] + 1 // 1-based
-			return true, lineNum, charNum, line, nil
+			matches = append(matches, grepMatch{
+				path:     filePath,
+				lineText: line,
+				lineNum:  lineNum,
+				charNum:  loc[0] + 1, // 1-based
+			})
+			if len(matches) >= limit {
+				break
+			}
 		}
 	}
 
-	return false, 0, 0, "", scanner.Err()
+	return matches, scanner.Err()
 }
 
 func globToRegex(glob string) string {
@@ -214,3 +214,66 @@ func invokeGrepTool(ctx context.Context, tool fantasy.AgentTool, params GrepPara
 	}
 	return tool.Run(ctx, call)
 }
+
+func TestGrepTool_StructuredMatches(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := "func One() {}\nvar x = 1\nfunc Two() {}\nFUNC three\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "a.go"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tool := NewGrepTool(tmpDir)
+	ctx := context.Background()
+
+	t.Run("returns every matching line", func(t *testing.T) {
+		resp, err := invokeGrepTool(ctx, tool, GrepParams{Pattern: "func"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var meta GrepResponseMetadata
+		if err := json.Unmarshal([]byte(resp.Metadata), &meta); err != nil {
+			t.Fatalf("parsing metadata: %v", err)
+		}
+		want := []GrepMatch{
+			{File: "a.go", Line: 1, Column: 1, Text: "func One() {}"},
+			{File: "a.go", Line: 3, Column: 1, Text: "func Two() {}"},
+		}
+		if len(meta.Matches) != len(want) {
+			t.Fatalf("Matches = %+v, want %+v", meta.Matches, want)
+		}
+		for i := range want {
+			if meta.Matches[i] != want[i] {
+				t.Errorf("Matches[%d] = %+v, want %+v", i, meta.Matches[i], want[i])
+			}
+		}
+		if !strings.Contains(getTextContent(resp), "a.go:3:1: func Two() {}") {
+			t.Errorf("unexpected output: %s", getTextContent(resp))
+		}
+	})
+
+	t.Run("ignore case", func(t *testing.T) {
+		resp, err := invokeGrepTool(ctx, tool, GrepParams{Pattern: "func", IgnoreCase: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(getTextContent(resp), "a.go:4:1: FUNC three") {
+			t.Errorf("expected case-insensitive match, got: %s", getTextContent(resp))
+		}
+	})
+
+	t.Run("limit truncates", func(t *testing.T) {
+		resp, err := invokeGrepTool(ctx, tool, GrepParams{Pattern: "func", IgnoreCase: true, Limit: 2})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var meta GrepResponseMetadata
+		if err := json.Unmarshal([]byte(resp.Metadata), &meta); err != nil {
+			t.Fatalf("parsing metadata: %v", err)
+		}
+		if meta.NumberOfMatches != 2 || !meta.Truncated {
+			t.Errorf("metadata = %+v, want 2 truncated matches", meta)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/memory"
+)
+
+// Tool names for the project memory.
+const (
+	MemoryWriteToolName  = "memory_write"
+	MemorySearchToolName = "memory_search"
+)
+
+// defaultMemoryResults is how many notes memory_search returns when the
+// model does not ask for a number.
+const defaultMemoryResults = 10
+
+// MemoryWriteParams are the parameters for the memory_write tool.
+type MemoryWriteParams struct {
+	Content  string `json:"content" description:"The note to remember, self-contained and specific"`
+	Replaces int64  `json:"replaces,omitempty" description:"ID of an outdated note this one replaces"`
+}
+
+// MemorySearchParams are the parameters for the memory_search tool.
+type MemorySearchParams struct {
+	Query string `json:"query,omitempty" description:"Words to look for. Omit to list the newest notes."`
+	Limit int    `json:"limit,omitempty" description:"Maximum number of notes to return (default 10)"`
+}
+
+const memoryWriteDescription = `Saves a note to this project's memory, which persists across sessions.
+
+Usage:
+- Save durable facts a future session would otherwise have to rediscover: build and test commands, conventions, where things live, decisions the user made and their preferences
+- Write each note so it makes sense on its own, without the current conversation
+- Don't save what is obvious from the code or only matters for the current task
+- When a note is outdated, pass its ID as replaces to swap it for the new one`
+
+const memorySearchDescription = `Searches this project's memory for notes saved in earlier sessions.
+
+Usage:
+- Search at the start of a task for notes about the area you are working in
+- Notes matching more of the query's words come first
+- Omit the query to list the newest notes
+- Each note is shown with its ID, which memory_write accepts as replaces`
+
+// NewMemoryWriteTool creates a tool that saves notes to project's memory.
+func NewMemoryWriteTool(store memory.Store, project string) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		MemoryWriteToolName,
+		memoryWriteDescription,
+		func(ctx context.Context, params MemoryWriteParams, _ fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if strings.TrimSpace(params.Content) == "" {
+				return fantasy.NewTextErrorResponse("content cannot be empty"), nil
+			}
+			if params.Replaces != 0 {
+				err := store.Delete(ctx, project, params.Replaces)
+				if errors.Is(err, memory.ErrNotFound) {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("No note with ID %d to replace", params.Replaces)), nil
+				}
+				if err != nil {
+					return fantasy.ToolResponse{}, fmt.Errorf("replacing memory: %w", err)
+				}
+			}
+
+			m, err := store.Add(ctx, project, SessionIDFromContext(ctx), params.Content)
+			if err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("saving memory: %w", err)
+			}
+			return fantasy.NewTextResponse(fmt.Sprintf("Saved note %d.", m.ID)), nil
+		})
+}
+
+// NewMemorySearchTool creates a tool that searches project's memory.
+func NewMemorySearchTool(store memory.Store, project string) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		MemorySearchToolName,
+		memorySearchDescription,
+		func(ctx context.Context, params MemorySearchParams, _ fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			limit := params.Limit
+			if limit <= 0 {
+				limit = defaultMemoryResults
+			}
+			memories, err := store.Search(ctx, project, params.Query, limit)
+			if err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("searching memory: %w", err)
+			}
+			if len(memories) == 0 {
+				if params.Query == "" {
+					return fantasy.NewTextResponse("No notes saved for this project yet."), nil
+				}
+				return fantasy.NewTextResponse("No notes match the query."), nil
+			}
+
+			var out strings.Builder
+			for _, m := range memories {
+				fmt.Fprintf(&out, "[%d] (%s) %s\n", m.ID, m.CreatedAt.Format("2006-01-02"), m.Content)
+			}
+			return fantasy.NewTextResponse(strings.TrimRight(out.String(), "\n")), nil
+		})
+}
@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/db"
+	"github.com/guilhermegouw/cdd/internal/memory"
+)
+
+func TestMemoryTools(t *testing.T) {
+	database, err := db.Open(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() }) //nolint:errcheck // Test cleanup
+	store := memory.NewSQLiteStore(database.Conn())
+	write := NewMemoryWriteTool(store, "/repo")
+	search := NewMemorySearchTool(store, "/repo")
+	ctx := WithSessionID(context.Background(), "s1")
+
+	run := func(tool fantasy.AgentTool, input string) fantasy.ToolResponse {
+		t.Helper()
+		resp, err := tool.Run(ctx, fantasy.ToolCall{ID: "call", Name: tool.Info().Name, Input: input})
+		if err != nil {
+			t.Fatalf("Run(%s) error = %v", input, err)
+		}
+		return resp
+	}
+
+	if resp := run(write, `{"content": "Tests run with make test"}`); resp.IsError || resp.Content != "Saved note 1." {
+		t.Fatalf("memory_write = %+v", resp)
+	}
+	if resp := run(write, `{"content": "Lint with make lint"}`); resp.IsError {
+		t.Fatalf("memory_write = %+v", resp)
+	}
+
+	resp := run(search, `{"query": "test"}`)
+	if !strings.Contains(resp.Content, "[1]") || strings.Contains(resp.Content, "lint") {
+		t.Errorf("memory_search should find only the matching note, got %q", resp.Content)
+	}
+
+	if resp := run(write, `{"content": "Tests run with go test ./...", "replaces": 1}`); resp.IsError {
+		t.Fatalf("memory_write replacing = %+v", resp)
+	}
+	resp = run(search, `{}`)
+	if strings.Contains(resp.Content, "make test") || !strings.Contains(resp.Content, "go test") {
+		t.Errorf("the replaced note should be gone, got %q", resp.Content)
+	}
+
+	if resp := run(write, `{"content": "x", "replaces": 99}`); !resp.IsError {
+		t.Error("replacing a missing note should fail")
+	}
+	if resp := run(search, `{"query": "deploy"}`); resp.Content != "No notes match the query." {
+		t.Errorf("memory_search without matches = %q", resp.Content)
+	}
+}
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+// progressInterval is how often a running command reports how long it has
+// been running.
+const progressInterval = time.Second
+
+// readProgressStep is how many bytes a file read gets through between
+// progress reports. Smaller files are read without any.
+const readProgressStep = 1 << 20
+
+// publishStep reports what a tool is doing on hub, when there is one.
+func publishStep(hub *pubsub.Hub, sessionID, toolCallID, toolName, step string, progress float64) {
+	if hub == nil {
+		return
+	}
+	hub.Tool.Publish(pubsub.EventProgress,
+		events.NewToolStepEvent(sessionID, toolCallID, toolName, step, progress))
+}
+
+// reportElapsed publishes how long a tool has been running every
+// progressInterval until the returned function is called.
+func reportElapsed(hub *pubsub.Hub, sessionID, toolCallID, toolName string) (stop func()) {
+	start := time.Now()
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				step := now.Sub(start).Round(time.Second).String() + " elapsed"
+				publishStep(hub, sessionID, toolCallID, toolName, step, 0)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// progressReader counts the bytes read through it and reports the count
+// every readProgressStep bytes.
+type progressReader struct {
+	r      io.Reader
+	read   int64
+	next   int64
+	report func(read int64)
+}
+
+func newProgressReader(r io.Reader, report func(read int64)) *progressReader {
+	return &progressReader{r: r, next: readProgressStep, report: report}
+}
+
+// Read reads from the underlying reader.
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if p.read >= p.next {
+		p.report(p.read)
+		p.next = p.read + readProgressStep
+	}
+	return n, err //nolint:wrapcheck // Passed through, io.EOF included
+}
+
+// restart resets the count after the underlying reader seeks to its start.
+func (p *progressReader) restart() {
+	p.read, p.next = 0, readProgressStep
+}
+
+// formatBytes formats a byte count for progress reports, e.g. "1.5 MB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	switch {
+	case n < unit:
+		return fmt.Sprintf("%d B", n)
+	case n < unit*unit:
+		return fmt.Sprintf("%.1f KB", float64(n)/unit)
+	case n < unit*unit*unit:
+		return fmt.Sprintf("%.1f MB", float64(n)/(unit*unit))
+	}
+	return fmt.Sprintf("%.1f GB", float64(n)/(unit*unit*unit))
+}
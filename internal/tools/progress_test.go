@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestProgressReader(t *testing.T) {
+	var reports []int64
+	r := newProgressReader(strings.NewReader(strings.Repeat("x", 3*readProgressStep+10)), func(read int64) {
+		reports = append(reports, read)
+	})
+	n, err := io.Copy(io.Discard, r)
+	if err != nil || n != 3*readProgressStep+10 {
+		t.Fatalf("io.Copy = %d, %v", n, err)
+	}
+	if len(reports) != 3 || reports[0] < readProgressStep {
+		t.Errorf("reports = %v, want one per %d bytes", reports, readProgressStep)
+	}
+
+	reports = nil
+	small := newProgressReader(strings.NewReader("short"), func(read int64) { reports = append(reports, read) })
+	if _, err := io.Copy(io.Discard, small); err != nil || len(reports) != 0 {
+		t.Errorf("a small read reported %v, %v", reports, err)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{
+		512:             "512 B",
+		1536:            "1.5 KB",
+		5 * 1024 * 1024: "5.0 MB",
+		3 << 30:         "3.0 GB",
+	} {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -2,25 +2,35 @@ package tools
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"strings"
 	"unicode/utf8"
 
 	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
 )
 
 // Tool constants for read operations.
 const (
-	ReadToolName     = "read"
-	MaxReadSize      = 5 * 1024 * 1024 // 5MB
-	DefaultReadLimit = 2000
-	MaxLineLength    = 2000
+	ReadToolName      = "read_file"
+	MaxReadSize       = 5 * 1024 * 1024 // 5MB; larger files must be read with offset/limit
+	MaxReadOutputSize = 256 * 1024      // Bytes of file content returned by a single read
+	DefaultReadLimit  = 2000
+	MaxLineLength     = 2000
 )
 
-// ReadParams are the parameters for the read tool.
+// sniffLen is how many leading bytes are inspected to tell text from binary files.
+const sniffLen = 512
+
+// ReadParams are the parameters for the read_file tool.
 type ReadParams struct {
 	FilePath string `json:"file_path" description:"The absolute path to the file to read"`
 	Offset   int    `json:"offset,omitempty" description:"The line number to start reading from (1-based). Defaults to 1."`
@@ -30,6 +40,7 @@ type ReadParams struct {
 // ReadResponseMetadata provides metadata about the read operation.
 type ReadResponseMetadata struct {
 	FilePath   string `json:"file_path"`
+	StartLine  int    `json:"start_line"`
 	LineCount  int    `json:"line_count"`
 	TotalLines int    `json:"total_lines"`
 	Truncated  bool   `json:"truncated"`
@@ -41,16 +52,20 @@ Usage:
 - The file_path parameter must be an absolute path, not a relative path
 - By default, it reads up to 2000 lines starting from the beginning of the file
 - You can optionally specify a line offset and limit for long files
-- Any lines longer than 2000 characters will be truncated
+- Any lines longer than 2000 characters will be truncated, and a single read returns at most 256KB
+- Files larger than 5MB can only be read in ranges, using offset and limit
 - Results are returned with line numbers starting at 1
-- This tool can only read files, not directories`
+- This tool can only read text files, not directories or binary files (images, archives, executables)`
 
-// NewReadTool creates a new read tool.
-func NewReadTool(workingDir string) fantasy.AgentTool {
+// NewReadTool creates a new read_file tool.
+// When hub is non-nil, the path of the file read is published as a tool progress event,
+// as is how far reading a large file has got.
+func NewReadTool(workingDir string, hub *pubsub.Hub) fantasy.AgentTool {
 	return fantasy.NewAgentTool(
 		ReadToolName,
 		readDescription,
 		func(ctx context.Context, params ReadParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			workingDir := sessionDir(ctx, workingDir)
 			if params.FilePath == "" {
 				return fantasy.NewTextErrorResponse("file_path is required"), nil
 			}
@@ -72,10 +87,20 @@ func NewReadTool(workingDir string) fantasy.AgentTool {
 				return fantasy.NewTextErrorResponse(fmt.Sprintf("Path is a directory, not a file: %s", filePath)), nil
 			}
 
-			// Check file size
-			if fileInfo.Size() > MaxReadSize {
-				return fantasy.NewTextErrorResponse(fmt.Sprintf("File is too large (%d bytes). Maximum size is %d bytes",
-					fileInfo.Size(), MaxReadSize)), nil
+			// Whole-file reads are capped; ranges of larger files are streamed
+			ranged := params.Offset > 0 || params.Limit > 0
+			if fileInfo.Size() > MaxReadSize && !ranged {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("File is too large (%d bytes). Maximum size is %d bytes; "+
+					"use offset and limit to read part of it", fileInfo.Size(), MaxReadSize)), nil
+			}
+
+			contentType, binary, err := detectBinaryFile(filePath)
+			if err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("error reading file: %w", err)
+			}
+			if binary {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("Cannot read binary file: %s (%s, %d bytes)",
+					filePath, contentType, fileInfo.Size())), nil
 			}
 
 			// Set defaults
@@ -87,7 +112,11 @@ func NewReadTool(workingDir string) fantasy.AgentTool {
 			}
 
 			// Read the file content
-			content, lineCount, totalLines, err := readTextFile(filePath, params.Offset, params.Limit)
+			progress := func(read int64) {
+				publishStep(hub, SessionIDFromContext(ctx), call.ID, ReadToolName,
+					formatBytes(read)+" of "+formatBytes(fileInfo.Size()), float64(read)/float64(fileInfo.Size()))
+			}
+			content, lineCount, totalLines, err := readTextFile(filePath, params.Offset, params.Limit, progress)
 			if err != nil {
 				return fantasy.ToolResponse{}, fmt.Errorf("error reading file: %w", err)
 			}
@@ -99,6 +128,10 @@ func NewReadTool(workingDir string) fantasy.AgentTool {
 
 			// Record the read
 			RecordFileRead(filePath)
+			if hub != nil {
+				hub.Tool.Publish(pubsub.EventProgress,
+					events.NewToolFileEvent(SessionIDFromContext(ctx), call.ID, ReadToolName, filePath))
+			}
 
 			// Format the output with line numbers
 			startLine := params.Offset + 1
@@ -118,6 +151,7 @@ func NewReadTool(workingDir string) fantasy.AgentTool {
 				fantasy.NewTextResponse(output),
 				ReadResponseMetadata{
 					FilePath:   filePath,
+					StartLine:  startLine,
 					LineCount:  lineCount,
 					TotalLines: totalLines,
 					Truncated:  truncated,
@@ -150,14 +184,17 @@ func addLineNumbers(content string, startLine int) string {
 	return strings.Join(result, "\n")
 }
 
-func readTextFile(filePath string, offset, limit int) (content string, lineCount, totalLines int, err error) {
+// readTextFile reads limit lines of filePath from offset, counting them all,
+// and calls progress as it gets through each readProgressStep of the file.
+func readTextFile(filePath string, offset, limit int, progress func(read int64)) (content string, lineCount, totalLines int, err error) {
 	file, err := os.Open(filePath) //nolint:gosec // G304: File path is validated above
 	if err != nil {
 		return "", 0, 0, err
 	}
 	defer file.Close() //nolint:errcheck // Error on close for read-only file is ignorable
 
-	scanner := newLineScanner(file)
+	reader := newProgressReader(file, progress)
+	scanner := newLineScanner(reader)
 	totalLines = 0
 
 	// Skip to offset
@@ -174,18 +211,21 @@ func readTextFile(filePath string, offset, limit int) (content string, lineCount
 		if err != nil {
 			return "", 0, 0, err
 		}
-		scanner = newLineScanner(file)
+		reader.restart()
+		scanner = newLineScanner(reader)
 	}
 
-	// Read lines up to limit
-	lines := make([]string, 0, limit)
-	for scanner.Scan() && len(lines) < limit {
+	// Read lines up to limit, stopping early once the output cap is reached
+	lines := make([]string, 0, min(limit, DefaultReadLimit))
+	size := 0
+	for len(lines) < limit && size < MaxReadOutputSize && scanner.Scan() {
 		totalLines++
 		lineText := scanner.Text()
 		if len(lineText) > MaxLineLength {
 			lineText = lineText[:MaxLineLength] + "..."
 		}
 		lines = append(lines, lineText)
+		size += len(lineText) + 1
 	}
 
 	// Count remaining lines
@@ -200,6 +240,40 @@ func readTextFile(filePath string, offset, limit int) (content string, lineCount
 	return strings.Join(lines, "\n"), len(lines), totalLines, nil
 }
 
+// detectBinaryFile reports whether the file at filePath looks binary, judging
+// by its first bytes, along with the detected content type.
+func detectBinaryFile(filePath string) (contentType string, binary bool, err error) {
+	file, err := os.Open(filePath) //nolint:gosec // G304: File path is validated by the caller
+	if err != nil {
+		return "", false, err
+	}
+	defer file.Close() //nolint:errcheck // Error on close for read-only file is ignorable
+
+	header := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", false, err
+	}
+	header = header[:n]
+
+	contentType = http.DetectContentType(header)
+	return contentType, !isTextContent(header, contentType), nil
+}
+
+// isTextContent reports whether header, the start of a file whose sniffed
+// content type is contentType, is text. NUL bytes mark a file as binary
+// regardless of its content type.
+func isTextContent(header []byte, contentType string) bool {
+	if bytes.IndexByte(header, 0) >= 0 {
+		return false
+	}
+	return strings.HasPrefix(contentType, "text/") ||
+		contentType == "application/json" ||
+		contentType == "application/xml" ||
+		contentType == "application/javascript" ||
+		contentType == "application/x-sh"
+}
+
 // lineScanner wraps bufio.Scanner with a larger buffer.
 type lineScanner struct {
 	scanner *bufio.Scanner
@@ -7,8 +7,12 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
 )
 
 //nolint:gocyclo // Test functions naturally have high complexity
@@ -27,7 +31,7 @@ func TestReadTool(t *testing.T) {
 		t.Fatalf("Failed to create test file: %v", err)
 	}
 
-	tool := NewReadTool(tmpDir)
+	tool := NewReadTool(tmpDir, nil)
 	ctx := context.Background()
 
 	t.Run("read entire file", func(t *testing.T) {
@@ -122,6 +126,21 @@ func TestReadTool(t *testing.T) {
 			t.Errorf("Expected line numbers in output, got: %s", content)
 		}
 	})
+
+	t.Run("relative path in the session's working directory", func(t *testing.T) {
+		other := t.TempDir()
+		if err := os.WriteFile(filepath.Join(other, "test.txt"), []byte("Other repo"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := invokeReadTool(WithWorkingDir(ctx, other), tool, ReadParams{FilePath: "test.txt"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if content := getTextContent(resp); !strings.Contains(content, "Other repo") {
+			t.Errorf("Expected the file from the session's directory, got: %s", content)
+		}
+	})
 }
 
 func TestReadToolLongLines(t *testing.T) {
@@ -138,7 +157,7 @@ func TestReadToolLongLines(t *testing.T) {
 		t.Fatalf("Failed to create test file: %v", writeErr)
 	}
 
-	tool := NewReadTool(tmpDir)
+	tool := NewReadTool(tmpDir, nil)
 	ctx := context.Background()
 
 	resp, err := invokeReadTool(ctx, tool, ReadParams{FilePath: testFile})
@@ -153,6 +172,121 @@ func TestReadToolLongLines(t *testing.T) {
 	}
 }
 
+func TestReadTool_BinaryFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	tool := NewReadTool(tmpDir, nil)
+
+	files := map[string][]byte{
+		"image.png": {0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0, 0, 0, 0x0d},
+		"data.bin":  []byte("header\x00\x01\x02payload"),
+	}
+	for name, data := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		resp, err := invokeReadTool(context.Background(), tool, ReadParams{FilePath: path})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !resp.IsError || !strings.Contains(getTextContent(resp), "binary file") {
+			t.Errorf("%s: expected binary file error, got: %s", name, getTextContent(resp))
+		}
+	}
+}
+
+func TestReadTool_SizeCaps(t *testing.T) {
+	tmpDir := t.TempDir()
+	tool := NewReadTool(tmpDir, nil)
+
+	line := strings.Repeat("x", 1000) + "\n"
+	bigFile := filepath.Join(tmpDir, "big.txt")
+	if err := os.WriteFile(bigFile, []byte(strings.Repeat(line, MaxReadSize/len(line)+10)), 0o600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	t.Run("whole read of a large file is rejected", func(t *testing.T) {
+		resp, err := invokeReadTool(context.Background(), tool, ReadParams{FilePath: bigFile})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !resp.IsError || !strings.Contains(getTextContent(resp), "offset and limit") {
+			t.Errorf("expected too large error, got: %s", getTextContent(resp))
+		}
+	})
+
+	t.Run("ranged read of a large file", func(t *testing.T) {
+		resp, err := invokeReadTool(context.Background(), tool, ReadParams{FilePath: bigFile, Offset: 5000, Limit: 3})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.IsError {
+			t.Fatalf("Unexpected error response: %s", getTextContent(resp))
+		}
+
+		var meta ReadResponseMetadata
+		if err := json.Unmarshal([]byte(resp.Metadata), &meta); err != nil {
+			t.Fatalf("Failed to decode metadata: %v", err)
+		}
+		if meta.StartLine != 5001 || meta.LineCount != 3 || !meta.Truncated {
+			t.Errorf("unexpected metadata: %+v", meta)
+		}
+		if !strings.Contains(getTextContent(resp), "  5001\t") {
+			t.Errorf("expected output to start at line 5001, got: %.100s", getTextContent(resp))
+		}
+	})
+
+	t.Run("output is capped", func(t *testing.T) {
+		resp, err := invokeReadTool(context.Background(), tool, ReadParams{FilePath: bigFile, Limit: DefaultReadLimit})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var meta ReadResponseMetadata
+		if err := json.Unmarshal([]byte(resp.Metadata), &meta); err != nil {
+			t.Fatalf("Failed to decode metadata: %v", err)
+		}
+		if meta.LineCount >= DefaultReadLimit || !meta.Truncated {
+			t.Errorf("expected output capped below %d lines, got %+v", DefaultReadLimit, meta)
+		}
+		if meta.TotalLines != MaxReadSize/len(line)+10 {
+			t.Errorf("expected %d total lines, got %d", MaxReadSize/len(line)+10, meta.TotalLines)
+		}
+	})
+}
+
+func TestReadTool_PublishesFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	hub := pubsub.NewHub()
+	defer hub.Shutdown()
+
+	subCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := hub.Tool.Subscribe(subCtx)
+
+	tool := NewReadTool(tmpDir, hub)
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "session-1")
+	if resp, err := invokeReadTool(ctx, tool, ReadParams{FilePath: "main.go"}); err != nil || resp.IsError {
+		t.Fatalf("Unexpected failure: %v %s", err, getTextContent(resp))
+	}
+
+	select {
+	case event := <-ch:
+		payload := event.Payload
+		if payload.Type != events.ToolEventProgress || payload.ToolName != ReadToolName || payload.FilePath != path {
+			t.Errorf("unexpected event %+v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected file event")
+	}
+}
+
 // Helper functions
 
 func invokeReadTool(ctx context.Context, tool fantasy.AgentTool, params ReadParams) (fantasy.ToolResponse, error) {
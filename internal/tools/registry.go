@@ -2,16 +2,28 @@
 package tools
 
 import (
+	"time"
+
 	"charm.land/fantasy"
 
+	"github.com/guilhermegouw/cdd/internal/index"
+	"github.com/guilhermegouw/cdd/internal/memory"
 	"github.com/guilhermegouw/cdd/internal/pubsub"
+	"github.com/guilhermegouw/cdd/internal/tickets"
 )
 
 // RegistryConfig holds configuration for creating a tool registry.
 type RegistryConfig struct {
-	WorkingDir string
-	Hub        *pubsub.Hub
-	TodoStore  *TodoStore
+	WorkingDir  string
+	Hub         *pubsub.Hub
+	TodoStore   *TodoStore
+	BashTimeout time.Duration       // Optional default timeout for the bash tool
+	Diagnostics DiagnosticsProvider // Optional source for the diagnostics tool, usually language servers
+	Memory      memory.Store        // Optional store for the memory tools
+	Index       *index.Index        // Optional codebase index for search_codebase
+	Tickets     *tickets.Client     // Optional issue trackers for fetch_ticket
+	Sandbox     *Sandbox            // Optional limits on the paths and commands tools may use
+	Project     string              // Project the memory tools read and write
 }
 
 // ToolMetadata holds metadata about a tool.
@@ -26,6 +38,7 @@ type ToolMetadata struct {
 type Registry struct {
 	tools    map[string]fantasy.AgentTool
 	metadata map[string]ToolMetadata
+	sandbox  *Sandbox // Checks the calls of tools registered while set
 }
 
 // NewRegistry creates a new empty tool registry.
@@ -38,6 +51,9 @@ func NewRegistry() *Registry {
 
 // Register adds a tool to the registry with its metadata.
 func (r *Registry) Register(tool fantasy.AgentTool, meta ToolMetadata) {
+	if r.sandbox != nil {
+		tool = sandboxedTool{AgentTool: tool, sandbox: r.sandbox}
+	}
 	r.tools[meta.Name] = tool
 	r.metadata[meta.Name] = meta
 }
@@ -104,8 +120,9 @@ func DefaultRegistry(workingDir string) *Registry {
 // NewDefaultRegistry creates a registry with the default set of tools.
 func NewDefaultRegistry(cfg RegistryConfig) *Registry {
 	r := NewRegistry()
+	r.sandbox = cfg.Sandbox
 
-	r.Register(NewReadTool(cfg.WorkingDir), ToolMetadata{
+	r.Register(NewReadTool(cfg.WorkingDir, cfg.Hub), ToolMetadata{
 		Name:        ReadToolName,
 		Category:    "file",
 		Description: "Read file contents with line numbers",
@@ -126,7 +143,7 @@ func NewDefaultRegistry(cfg RegistryConfig) *Registry {
 		Safe:        true,
 	})
 
-	r.Register(NewWriteTool(cfg.WorkingDir), ToolMetadata{
+	r.Register(NewWriteTool(cfg.WorkingDir, cfg.Hub), ToolMetadata{
 		Name:        WriteToolName,
 		Category:    "file",
 		Description: "Write or create files",
@@ -140,13 +157,29 @@ func NewDefaultRegistry(cfg RegistryConfig) *Registry {
 		Safe:        false,
 	})
 
-	r.Register(NewBashTool(cfg.WorkingDir), ToolMetadata{
+	r.Register(NewEditFileTool(cfg.WorkingDir, cfg.Hub), ToolMetadata{
+		Name:        EditFileToolName,
+		Category:    "file",
+		Description: "Apply search/replace blocks or a unified diff to a file",
+		Safe:        false,
+	})
+
+	r.Register(NewBashTool(cfg.WorkingDir, WithBashHub(cfg.Hub), WithBashTimeout(cfg.BashTimeout)), ToolMetadata{
 		Name:        BashToolName,
 		Category:    "system",
 		Description: "Execute shell commands",
 		Safe:        false,
 	})
 
+	if cfg.Diagnostics != nil {
+		r.Register(NewDiagnosticsTool(cfg.WorkingDir, cfg.Diagnostics), ToolMetadata{
+			Name:        DiagnosticsToolName,
+			Category:    "file",
+			Description: "Report compile and lint errors from language servers",
+			Safe:        true,
+		})
+	}
+
 	// Register TodoWrite if store is provided
 	if cfg.TodoStore != nil {
 		r.Register(NewTodoWriteTool(cfg.TodoStore, cfg.Hub), ToolMetadata{
@@ -157,5 +190,38 @@ func NewDefaultRegistry(cfg RegistryConfig) *Registry {
 		})
 	}
 
+	if cfg.Index != nil {
+		r.Register(NewSearchCodebaseTool(cfg.Index, cfg.Hub), ToolMetadata{
+			Name:        SearchCodebaseToolName,
+			Category:    "file",
+			Description: "Find code related to a question in the codebase index",
+			Safe:        true,
+		})
+	}
+
+	if cfg.Tickets != nil {
+		r.Register(NewFetchTicketTool(cfg.Tickets), ToolMetadata{
+			Name:        FetchTicketToolName,
+			Category:    "context",
+			Description: "Fetch a Jira or Linear ticket by ID",
+			Safe:        true,
+		})
+	}
+
+	if cfg.Memory != nil {
+		r.Register(NewMemoryWriteTool(cfg.Memory, cfg.Project), ToolMetadata{
+			Name:        MemoryWriteToolName,
+			Category:    "memory",
+			Description: "Save a note for later sessions in this project",
+			Safe:        true,
+		})
+		r.Register(NewMemorySearchTool(cfg.Memory, cfg.Project), ToolMetadata{
+			Name:        MemorySearchToolName,
+			Category:    "memory",
+			Description: "Search notes saved in earlier sessions",
+			Safe:        true,
+		})
+	}
+
 	return r
 }
@@ -0,0 +1,215 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"charm.land/fantasy"
+)
+
+// Sandbox restricts the files tools may access to the working directory and
+// a few allowed ones, and the programs bash may run to an allowlist.
+type Sandbox struct {
+	workingDir string
+	roots      []string // Allowed directories as configured
+	realRoots  []string // The same with symlinks resolved
+	commands   map[string]bool
+}
+
+// NewSandbox creates a sandbox for workingDir. allowedPaths may be absolute,
+// relative to workingDir, or start with ~/. An empty allowedCommands lets
+// bash run any program.
+func NewSandbox(workingDir string, allowedPaths, allowedCommands []string) *Sandbox {
+	s := &Sandbox{workingDir: workingDir, commands: make(map[string]bool, len(allowedCommands))}
+	for _, dir := range append([]string{workingDir}, allowedPaths...) {
+		dir = ResolvePath(workingDir, ExpandHome(dir))
+		s.roots = append(s.roots, dir)
+		s.realRoots = append(s.realRoots, realPath(dir))
+	}
+	for _, name := range allowedCommands {
+		s.commands[name] = true
+	}
+	return s
+}
+
+// at returns the sandbox for a session working in dir, which /cd may have
+// moved away from the directory cdd started in. Relative paths resolve
+// against dir, and dir itself is allowed, as the user chose it.
+func (s *Sandbox) at(dir string) *Sandbox {
+	if dir == "" || dir == s.workingDir {
+		return s
+	}
+	moved := *s
+	moved.workingDir = dir
+	moved.roots = append([]string{dir}, s.roots...)
+	moved.realRoots = append([]string{realPath(dir)}, s.realRoots...)
+	return &moved
+}
+
+// CheckPath returns an error unless path, relative to the working directory,
+// lies in an allowed directory both as written and with symlinks resolved.
+func (s *Sandbox) CheckPath(path string) error {
+	abs := ResolvePath(s.workingDir, path)
+	if !within(abs, s.roots) {
+		return fmt.Errorf("%s is outside the working directory", abs)
+	}
+	if !within(realPath(abs), s.realRoots) {
+		return fmt.Errorf("%s leads outside the working directory through a symlink", abs)
+	}
+	return nil
+}
+
+var (
+	// commandSeparator splits a shell command into the commands it runs.
+	commandSeparator = regexp.MustCompile(`\|\||&&|[|;&\n]`)
+	// envAssignment matches a leading VAR=value of a command.
+	envAssignment = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+	// outputRedirection matches a redirection of output to a file, e.g.
+	// "> out.txt", "2>>log" or "&> all.log", and captures the file.
+	outputRedirection = regexp.MustCompile(`>>?[|&]?[ \t]*([^\s;&|<>()]+)`)
+	// fdNumber matches the target of a redirection to another descriptor,
+	// as in "2>&1", or its closing with "-".
+	fdNumber = regexp.MustCompile(`^([0-9]+|-)$`)
+)
+
+// redirectionDevices are the files output may always be redirected to.
+var redirectionDevices = map[string]bool{"/dev/null": true, "/dev/stdout": true, "/dev/stderr": true}
+
+// CheckCommand returns an error unless every file command redirects output
+// to is in an allowed directory and every program it runs is allowed.
+// Command and process substitution are refused under an allowlist, as they
+// could run anything.
+func (s *Sandbox) CheckCommand(command string) error {
+	if err := s.checkRedirections(command); err != nil {
+		return err
+	}
+	if len(s.commands) == 0 {
+		return nil
+	}
+	if strings.Contains(command, "`") || strings.Contains(command, "$(") {
+		return fmt.Errorf("command substitution is not allowed")
+	}
+	if strings.Contains(command, "<(") || strings.Contains(command, ">(") {
+		return fmt.Errorf("process substitution is not allowed")
+	}
+	for _, segment := range commandSeparator.Split(command, -1) {
+		fields := strings.Fields(strings.Trim(strings.TrimSpace(segment), "(){}"))
+		for len(fields) > 0 && (envAssignment.MatchString(fields[0]) || fields[0] == "!") {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		if program := filepath.Base(fields[0]); !s.commands[program] {
+			return fmt.Errorf("%s is not an allowed command (allowed: %s)", program, strings.Join(s.allowedCommands(), ", "))
+		}
+	}
+	return nil
+}
+
+// checkRedirections returns an error unless every file command redirects
+// output to lies in an allowed directory. A target the shell would expand
+// from a variable cannot be checked and is refused.
+func (s *Sandbox) checkRedirections(command string) error {
+	for _, match := range outputRedirection.FindAllStringSubmatch(command, -1) {
+		target := strings.Trim(match[1], `"'`)
+		if fdNumber.MatchString(target) || redirectionDevices[target] {
+			continue
+		}
+		if strings.Contains(target, "$") {
+			return fmt.Errorf("redirecting output to %s is not allowed", target)
+		}
+		if rest, ok := strings.CutPrefix(target, "~/"); ok {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("redirecting output to %s is not allowed", target)
+			}
+			target = filepath.Join(home, rest)
+		}
+		if err := s.CheckPath(target); err != nil {
+			return fmt.Errorf("redirecting output: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Sandbox) allowedCommands() []string {
+	names := make([]string, 0, len(s.commands))
+	for name := range s.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// within reports whether path is one of dirs or inside one.
+func within(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(dir, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// realPath resolves the symlinks of path. Components that do not exist yet,
+// such as a file about to be written, are kept as they are.
+func realPath(path string) string {
+	var missing []string
+	for current := path; ; {
+		if resolved, err := filepath.EvalSymlinks(current); err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...)
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return path
+		}
+		missing = append([]string{filepath.Base(current)}, missing...)
+		current = parent
+	}
+}
+
+// sandboxedTool checks the paths and commands in a tool call's input
+// against a sandbox before running the tool.
+type sandboxedTool struct {
+	fantasy.AgentTool
+	sandbox *Sandbox
+}
+
+// sandboxedPathParams are the input fields of the tools that name files or
+// directories.
+var sandboxedPathParams = []string{"file_path", "path", "working_dir"}
+
+// Run refuses calls that reach outside the sandbox. Paths resolve against
+// the session's working directory, as the tools resolve them.
+func (t sandboxedTool) Run(ctx context.Context, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+	sandbox := t.sandbox.at(WorkingDirFromContext(ctx))
+	var input map[string]any
+	if err := json.Unmarshal([]byte(call.Input), &input); err == nil {
+		for _, key := range sandboxedPathParams {
+			if path, ok := input[key].(string); ok && path != "" {
+				if err := sandbox.CheckPath(path); err != nil {
+					return fantasy.NewTextErrorResponse("Blocked by the sandbox: " + err.Error()), nil
+				}
+			}
+		}
+		if command, ok := input["command"].(string); ok {
+			// Redirections are relative to the directory the command runs in.
+			runner := *sandbox
+			if dir, ok := input["working_dir"].(string); ok && dir != "" {
+				runner.workingDir = ResolvePath(sandbox.workingDir, dir)
+			}
+			if err := runner.CheckCommand(command); err != nil {
+				return fantasy.NewTextErrorResponse("Blocked by the sandbox: " + err.Error()), nil
+			}
+		}
+	}
+	return t.AgentTool.Run(ctx, call)
+}
@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/index"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+// SearchCodebaseToolName is the name of the codebase search tool.
+const SearchCodebaseToolName = "search_codebase"
+
+// defaultCodebaseResults is how many snippets search_codebase returns when
+// the model does not ask for a number.
+const defaultCodebaseResults = 5
+
+// SearchCodebaseParams are the parameters for the search_codebase tool.
+type SearchCodebaseParams struct {
+	Query string `json:"query" description:"What you are looking for, in words, e.g. 'where OAuth tokens are refreshed'"`
+	Limit int    `json:"limit,omitempty" description:"Maximum number of snippets to return (default 5)"`
+}
+
+const searchCodebaseDescription = `Finds the code most related to a question by meaning, using an index of the project's files.
+
+Usage:
+- Use it to locate where something is implemented or handled when you don't know the names to grep for
+- Describe what the code does rather than guessing identifiers
+- Each result is a snippet of up to 60 lines with its path and line range; read the file for more context
+- Prefer grep for exact names and glob for file names`
+
+// NewSearchCodebaseTool creates a tool that searches idx. The index is
+// brought up to date before each search, so edits are found; when hub is
+// non-nil, how many changed files have been indexed is published as tool
+// progress.
+func NewSearchCodebaseTool(idx *index.Index, hub *pubsub.Hub) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		SearchCodebaseToolName,
+		searchCodebaseDescription,
+		func(ctx context.Context, params SearchCodebaseParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if strings.TrimSpace(params.Query) == "" {
+				return fantasy.NewTextErrorResponse("query cannot be empty"), nil
+			}
+			progress := func(done, total int) {
+				publishStep(hub, SessionIDFromContext(ctx), call.ID, SearchCodebaseToolName,
+					fmt.Sprintf("indexed %d of %d files", done, total), float64(done)/float64(total))
+			}
+			if _, err := idx.Update(ctx, progress); err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("Error updating the codebase index: %v", err)), nil
+			}
+
+			limit := params.Limit
+			if limit <= 0 {
+				limit = defaultCodebaseResults
+			}
+			results, err := idx.Search(ctx, params.Query, limit)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("Error searching the codebase: %v", err)), nil
+			}
+			if len(results) == 0 {
+				return fantasy.NewTextResponse("The index has no files to search."), nil
+			}
+
+			var out strings.Builder
+			for i, r := range results {
+				if i > 0 {
+					out.WriteString("\n\n")
+				}
+				fmt.Fprintf(&out, "%s:%d-%d (score %.2f)\n", filepath.ToSlash(r.Path), r.StartLine, r.EndLine, r.Score)
+				out.WriteString(r.Content)
+			}
+			return fantasy.NewTextResponse(out.String()), nil
+		})
+}
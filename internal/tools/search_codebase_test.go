@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/db"
+	"github.com/guilhermegouw/cdd/internal/index"
+)
+
+func TestSearchCodebaseTool(t *testing.T) {
+	database, err := db.Open(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() }) //nolint:errcheck // Test cleanup
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "auth.go"), []byte("func refreshToken() error {\n\treturn nil\n}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	embedder, err := index.NewEmbedder(&config.IndexOptions{Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := NewSearchCodebaseTool(index.New(database.Conn(), root, embedder), nil)
+
+	resp, err := tool.Run(context.Background(), fantasy.ToolCall{ID: "call", Name: SearchCodebaseToolName, Input: `{"query": "where is the token refreshed"}`})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if resp.IsError || !strings.HasPrefix(resp.Content, "auth.go:1-3") || !strings.Contains(resp.Content, "refreshToken") {
+		t.Errorf("search_codebase = %q, want the snippet of auth.go", resp.Content)
+	}
+
+	resp, _ = tool.Run(context.Background(), fantasy.ToolCall{ID: "call", Name: SearchCodebaseToolName, Input: `{"query": " "}`})
+	if !resp.IsError {
+		t.Error("an empty query should be an error")
+	}
+}
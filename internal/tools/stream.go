@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+// maxPendingOutput is how much of an unterminated line is buffered before it is published anyway.
+const maxPendingOutput = 4096
+
+// outputStreamer publishes command output as tool progress events, one batch of lines at a time.
+// It is safe for concurrent use so stdout and stderr can share it.
+type outputStreamer struct {
+	hub        *pubsub.Hub
+	sessionID  string
+	toolCallID string
+	pending    []byte
+	sent       int
+	mu         sync.Mutex
+}
+
+func newOutputStreamer(hub *pubsub.Hub, sessionID, toolCallID string) *outputStreamer {
+	return &outputStreamer{
+		hub:        hub,
+		sessionID:  sessionID,
+		toolCallID: toolCallID,
+	}
+}
+
+// Write buffers p and publishes every complete line.
+func (s *outputStreamer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending = append(s.pending, p...)
+	if idx := bytes.LastIndexByte(s.pending, '\n'); idx >= 0 {
+		s.publish(s.pending[:idx+1])
+		s.pending = append(s.pending[:0], s.pending[idx+1:]...)
+	} else if len(s.pending) >= maxPendingOutput {
+		s.publish(s.pending)
+		s.pending = s.pending[:0]
+	}
+	return len(p), nil
+}
+
+// Flush publishes any buffered partial line.
+func (s *outputStreamer) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) > 0 {
+		s.publish(s.pending)
+		s.pending = s.pending[:0]
+	}
+}
+
+// publish sends chunk unless the streamed output already exceeds MaxOutputLength.
+func (s *outputStreamer) publish(chunk []byte) {
+	if s.sent >= MaxOutputLength {
+		return
+	}
+	s.sent += len(chunk)
+	s.hub.Tool.Publish(pubsub.EventProgress,
+		events.NewToolOutputEvent(s.sessionID, s.toolCallID, BashToolName, string(chunk)))
+}
@@ -1,13 +1,21 @@
 package tools
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"sync"
+
+	"github.com/guilhermegouw/cdd/internal/db/sqlc"
+	"github.com/guilhermegouw/cdd/internal/debug"
 )
 
 // TodoStore manages todo lists per session with thread-safe access.
 type TodoStore struct {
-	mu    sync.RWMutex
-	todos map[string][]TodoItem // sessionID -> todos
+	mu     sync.RWMutex
+	todos  map[string][]TodoItem // sessionID -> todos
+	conn   *sql.DB               // Nil keeps todos in memory only
+	loaded map[string]bool       // Sessions read from conn
 }
 
 // NewTodoStore creates a new todo store.
@@ -17,9 +25,83 @@ func NewTodoStore() *TodoStore {
 	}
 }
 
+// NewPersistentTodoStore creates a todo store that saves every session's
+// todos to the database, so a reopened session gets its plan back.
+func NewPersistentTodoStore(conn *sql.DB) *TodoStore {
+	return &TodoStore{
+		todos:  make(map[string][]TodoItem),
+		conn:   conn,
+		loaded: make(map[string]bool),
+	}
+}
+
+// load reads a session's todos from the database the first time the session
+// is looked up.
+func (s *TodoStore) load(sessionID string) {
+	if s.conn == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded[sessionID] {
+		return
+	}
+
+	rows, err := sqlc.New(s.conn).ListTodos(context.Background(), sessionID)
+	if err != nil {
+		debug.Error("tools", err, "loading todos of "+sessionID)
+		return
+	}
+	s.loaded[sessionID] = true
+	if len(rows) == 0 {
+		return
+	}
+	todos := make([]TodoItem, len(rows))
+	for i, row := range rows {
+		todos[i] = TodoItem{
+			Content:    row.Content,
+			ActiveForm: row.ActiveForm,
+			Status:     TodoStatus(row.Status),
+		}
+	}
+	s.todos[sessionID] = todos
+}
+
+// save replaces a session's todos in the database.
+func (s *TodoStore) save(sessionID string, todos []TodoItem) error {
+	ctx := context.Background()
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit.
+
+	queries := sqlc.New(tx)
+	if err := queries.DeleteTodos(ctx, sessionID); err != nil {
+		return fmt.Errorf("deleting todos: %w", err)
+	}
+	for i, todo := range todos {
+		err := queries.AddTodo(ctx, sqlc.AddTodoParams{
+			SessionID:  sessionID,
+			Position:   int64(i),
+			Content:    todo.Content,
+			ActiveForm: todo.ActiveForm,
+			Status:     string(todo.Status),
+		})
+		if err != nil {
+			return fmt.Errorf("adding todo: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing todos: %w", err)
+	}
+	return nil
+}
+
 // Get returns a copy of the todos for a session.
 // Returns nil if no todos exist for the session.
 func (s *TodoStore) Get(sessionID string) []TodoItem {
+	s.load(sessionID)
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -36,10 +118,17 @@ func (s *TodoStore) Get(sessionID string) []TodoItem {
 
 // Set updates the todos for a session.
 // Passing nil or empty slice clears the todos for that session.
+// A persistent store keeps the update in memory even when saving it fails.
 func (s *TodoStore) Set(sessionID string, todos []TodoItem) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.conn != nil {
+		s.loaded[sessionID] = true
+		if err := s.save(sessionID, todos); err != nil {
+			debug.Error("tools", err, "saving todos of "+sessionID)
+		}
+	}
 	if len(todos) == 0 {
 		delete(s.todos, sessionID)
 		return
@@ -53,22 +142,24 @@ func (s *TodoStore) Set(sessionID string, todos []TodoItem) {
 
 // Clear removes todos for a session.
 func (s *TodoStore) Clear(sessionID string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	delete(s.todos, sessionID)
+	s.Set(sessionID, nil)
 }
 
-// ClearAll removes all todos from all sessions.
+// ClearAll removes all todos from all sessions. Saved todos are kept and
+// read again when their sessions are looked up.
 func (s *TodoStore) ClearAll() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	s.todos = make(map[string][]TodoItem)
+	if s.conn != nil {
+		s.loaded = make(map[string]bool)
+	}
 }
 
 // HasTodos returns true if the session has any todos.
 func (s *TodoStore) HasTodos(sessionID string) bool {
+	s.load(sessionID)
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -78,6 +169,7 @@ func (s *TodoStore) HasTodos(sessionID string) bool {
 
 // Count returns the number of todos for a session.
 func (s *TodoStore) Count(sessionID string) int {
+	s.load(sessionID)
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -86,6 +178,7 @@ func (s *TodoStore) Count(sessionID string) int {
 
 // CountByStatus returns the count of todos with a specific status.
 func (s *TodoStore) CountByStatus(sessionID string, status TodoStatus) int {
+	s.load(sessionID)
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -100,6 +193,7 @@ func (s *TodoStore) CountByStatus(sessionID string, status TodoStatus) int {
 
 // GetInProgress returns the currently in-progress todo, if any.
 func (s *TodoStore) GetInProgress(sessionID string) *TodoItem {
+	s.load(sessionID)
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
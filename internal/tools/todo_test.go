@@ -9,6 +9,8 @@ import (
 
 	"charm.land/fantasy"
 
+	"github.com/guilhermegouw/cdd/internal/db"
+	"github.com/guilhermegouw/cdd/internal/db/sqlc"
 	"github.com/guilhermegouw/cdd/internal/pubsub"
 )
 
@@ -347,6 +349,38 @@ func TestTodoStore_MultipleSessions(t *testing.T) {
 
 // Tests for ValidateTodos
 
+func TestPersistentTodoStore(t *testing.T) {
+	database, err := db.Open(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	_, err = sqlc.New(database.Conn()).CreateSession(context.Background(), sqlc.CreateSessionParams{ID: "session-1"})
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	todos := []TodoItem{
+		{Content: "Write tests", ActiveForm: "Writing tests", Status: TodoStatusCompleted},
+		{Content: "Fix bug", ActiveForm: "Fixing bug", Status: TodoStatusInProgress},
+	}
+	NewPersistentTodoStore(database.Conn()).Set("session-1", todos)
+
+	reopened := NewPersistentTodoStore(database.Conn())
+	got := reopened.Get("session-1")
+	if len(got) != 2 || got[0] != todos[0] || got[1] != todos[1] {
+		t.Fatalf("Get() after reopening = %+v, want %+v", got, todos)
+	}
+	if inProgress := reopened.GetInProgress("session-1"); inProgress == nil || inProgress.Content != "Fix bug" {
+		t.Errorf("GetInProgress() = %+v, want Fix bug", inProgress)
+	}
+
+	reopened.Clear("session-1")
+	if got := NewPersistentTodoStore(database.Conn()).Get("session-1"); got != nil {
+		t.Errorf("Get() after Clear = %+v, want nil", got)
+	}
+}
+
 func TestValidateTodos_Valid(t *testing.T) {
 	tests := []struct {
 		name  string
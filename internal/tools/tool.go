@@ -3,7 +3,9 @@ package tools
 
 import (
 	"context"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 )
@@ -76,6 +78,15 @@ func WorkingDirFromContext(ctx context.Context) string {
 	return s
 }
 
+// sessionDir returns the working directory of the session running a tool, or
+// dir when the context does not carry one.
+func sessionDir(ctx context.Context, dir string) string {
+	if workingDir := WorkingDirFromContext(ctx); workingDir != "" {
+		return workingDir
+	}
+	return dir
+}
+
 // ResolvePath resolves a potentially relative path against the working directory.
 func ResolvePath(workingDir, path string) string {
 	if filepath.IsAbs(path) {
@@ -84,6 +95,32 @@ func ResolvePath(workingDir, path string) string {
 	return filepath.Clean(filepath.Join(workingDir, path))
 }
 
+// ExpandHome expands a leading "~/" to the home directory. Other paths, and
+// all paths when there is no home directory, are returned as they are.
+func ExpandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// Ancestors returns dir and its parents, from the filesystem root down to dir.
+func Ancestors(dir string) []string {
+	var dirs []string
+	for {
+		dirs = append(dirs, dir)
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	slices.Reverse(dirs)
+	return dirs
+}
+
 // IsPathWithinDir checks if a path is within the given directory.
 func IsPathWithinDir(path, dir string) bool {
 	absPath, err := filepath.Abs(path)
@@ -0,0 +1,33 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestAncestors(t *testing.T) {
+	root := filepath.VolumeName(t.TempDir()) + string(filepath.Separator)
+	dir := filepath.Join(root, "home", "dev", "repo")
+
+	want := []string{root, filepath.Join(root, "home"), filepath.Join(root, "home", "dev"), dir}
+	if got := Ancestors(dir); !slices.Equal(got, want) {
+		t.Errorf("Ancestors(%q) = %v, want %v", dir, got, want)
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	if got, want := ExpandHome("~/notes/CDD.md"), filepath.Join(home, "notes", "CDD.md"); got != want {
+		t.Errorf("ExpandHome() = %q, want %q", got, want)
+	}
+	for _, path := range []string{"notes/CDD.md", "/etc/CDD.md", "~other/CDD.md"} {
+		if got := ExpandHome(path); got != path {
+			t.Errorf("ExpandHome(%q) = %q, want it unchanged", path, got)
+		}
+	}
+}
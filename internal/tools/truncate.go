@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// headShare is the part of a truncated output kept from its start; the rest
+// comes from its end, where errors and summaries usually are.
+const headShare = 0.4
+
+// TruncateMiddle cuts content to about limit characters by dropping its
+// middle, keeping whole lines from the start and the end, and notes how much
+// was left out. It returns content unchanged when it fits or limit is not
+// positive.
+func TruncateMiddle(content string, limit int) (string, bool) {
+	if limit <= 0 || len(content) <= limit {
+		return content, false
+	}
+
+	headEnd := int(float64(limit) * headShare)
+	tailStart := len(content) - (limit - headEnd)
+	for headEnd > 0 && !utf8.RuneStart(content[headEnd]) {
+		headEnd--
+	}
+	for tailStart < len(content) && !utf8.RuneStart(content[tailStart]) {
+		tailStart++
+	}
+	head := content[:headEnd]
+	tail := content[tailStart:]
+
+	// Cut at line breaks, unless that gives up most of what is kept, as for
+	// output on a few long lines.
+	if i := strings.LastIndexByte(head, '\n'); i > len(head)/2 {
+		head = head[:i+1]
+	}
+	if i := strings.IndexByte(tail, '\n'); i >= 0 && i < len(tail)/2 {
+		tail = tail[i+1:]
+	}
+
+	omitted := content[len(head) : len(content)-len(tail)]
+	note := fmt.Sprintf("[... %d characters", len(omitted))
+	if lines := strings.Count(omitted, "\n"); lines > 0 {
+		note += fmt.Sprintf(", %d lines,", lines)
+	}
+	note += " omitted ...]"
+	return strings.TrimRight(head, "\n") + "\n\n" + note + "\n\n" + tail, true
+}
@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateMiddle(t *testing.T) {
+	t.Run("fits", func(t *testing.T) {
+		got, cut := TruncateMiddle("short", 10)
+		if cut || got != "short" {
+			t.Errorf("TruncateMiddle = %q, %v", got, cut)
+		}
+		if _, cut := TruncateMiddle(strings.Repeat("x", 100), 0); cut {
+			t.Error("a zero limit cut the content")
+		}
+	})
+
+	t.Run("lines", func(t *testing.T) {
+		var b strings.Builder
+		for i := range 1000 {
+			b.WriteString(strings.Repeat("a", 9) + string(rune('0'+i%10)) + "\n")
+		}
+		content := "first line\n" + b.String() + "error: last line"
+
+		got, cut := TruncateMiddle(content, 500)
+		if !cut {
+			t.Fatal("content was not cut")
+		}
+		if !strings.HasPrefix(got, "first line\n") || !strings.HasSuffix(got, "\nerror: last line") {
+			t.Errorf("head or tail lost:\n%s", got)
+		}
+		if !strings.Contains(got, "lines, omitted ...]") {
+			t.Errorf("no omission note:\n%s", got)
+		}
+		for _, line := range strings.Split(got, "\n") {
+			if strings.HasPrefix(line, "a") && len(line) != 10 {
+				t.Errorf("line cut partway: %q", line)
+			}
+		}
+		if len(got) > 600 {
+			t.Errorf("len = %d, want about 500", len(got))
+		}
+	})
+
+	t.Run("runes", func(t *testing.T) {
+		got, cut := TruncateMiddle(strings.Repeat("é", 1000), 101)
+		if !cut || !utf8.ValidString(got) {
+			t.Errorf("TruncateMiddle = %q, %v", got, cut)
+		}
+		if !strings.Contains(got, "[... ") || strings.Contains(got, "lines") {
+			t.Errorf("note = %q", got)
+		}
+	})
+}
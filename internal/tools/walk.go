@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// skippedDirs are never searched, whether or not they are ignored by git.
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"__pycache__":  true,
+	".git":         true,
+}
+
+// walkSearchFiles calls fn for every file under root that a code search should
+// consider: hidden files and directories, common dependency directories, and
+// paths ignored by .gitignore are skipped. fn may return filepath.SkipAll to
+// stop early.
+func walkSearchFiles(ctx context.Context, root string, fn func(path string, info os.FileInfo) error) error {
+	ignore := newIgnoreMatcher(root)
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, walkErr error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if walkErr != nil {
+			return nil //nolint:nilerr // Skip inaccessible files, continue walking
+		}
+
+		name := info.Name()
+		if info.IsDir() {
+			if path == root {
+				return nil
+			}
+			if strings.HasPrefix(name, ".") || skippedDirs[name] || ignore.ignored(path, true) {
+				return filepath.SkipDir
+			}
+			ignore.enterDir(path)
+			return nil
+		}
+
+		if strings.HasPrefix(name, ".") || ignore.ignored(path, false) {
+			return nil
+		}
+		return fn(path, info)
+	})
+	if errors.Is(err, filepath.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// ListFiles returns the files under root that a search would consider, as
+// slash-separated paths relative to root, stopping after limit files.
+func ListFiles(ctx context.Context, root string, limit int) ([]string, error) {
+	var files []string
+	err := walkSearchFiles(ctx, root, func(path string, _ os.FileInfo) error {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil //nolint:nilerr // Skip paths outside root
+		}
+		files = append(files, filepath.ToSlash(rel))
+		if len(files) >= limit {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return files, err
+}
+
+// displayPath returns path relative to workingDir when it is inside it, which
+// keeps tool output short, and the absolute path otherwise.
+func displayPath(workingDir, path string) string {
+	if rel, err := filepath.Rel(workingDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
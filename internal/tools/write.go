@@ -9,12 +9,15 @@ import (
 	"time"
 
 	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
 )
 
-// WriteToolName is the name of the write tool.
-const WriteToolName = "write"
+// WriteToolName is the name of the write_file tool.
+const WriteToolName = "write_file"
 
-// WriteParams are the parameters for the write tool.
+// WriteParams are the parameters for the write_file tool.
 type WriteParams struct {
 	FilePath string `json:"file_path" description:"The absolute path to the file to write"`
 	Content  string `json:"content" description:"The content to write to the file"`
@@ -23,7 +26,10 @@ type WriteParams struct {
 // WriteResponseMetadata provides metadata about the write operation.
 type WriteResponseMetadata struct {
 	FilePath     string `json:"file_path"`
+	Diff         string `json:"diff"`
 	BytesWritten int    `json:"bytes_written"`
+	Additions    int    `json:"additions"`
+	Removals     int    `json:"removals"`
 	Created      bool   `json:"created"`
 }
 
@@ -32,15 +38,18 @@ const writeDescription = `Writes content to a file on the local filesystem.
 Usage:
 - The file_path parameter must be an absolute path, not a relative path
 - This tool will overwrite the existing file if there is one at the provided path
-- If this is an existing file, you MUST use the Read tool first to read the file's contents
-- Parent directories will be created automatically if they don't exist`
+- If this is an existing file, you MUST use the read_file tool first to read the file's contents
+- Parent directories will be created automatically if they don't exist
+- Binary files cannot be overwritten`
 
-// NewWriteTool creates a new write tool.
-func NewWriteTool(workingDir string) fantasy.AgentTool {
+// NewWriteTool creates a new write_file tool.
+// When hub is non-nil, the resulting diff is published as a tool progress event.
+func NewWriteTool(workingDir string, hub *pubsub.Hub) fantasy.AgentTool {
 	return fantasy.NewAgentTool(
 		WriteToolName,
 		writeDescription,
 		func(ctx context.Context, params WriteParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			workingDir := sessionDir(ctx, workingDir)
 			if params.FilePath == "" {
 				return fantasy.NewTextErrorResponse("file_path is required"), nil
 			}
@@ -55,6 +64,8 @@ func NewWriteTool(workingDir string) fantasy.AgentTool {
 			// Check if file already exists
 			fileInfo, err := os.Stat(filePath)
 			created := os.IsNotExist(err)
+			var existing []byte
+			var oldContent string
 
 			if err == nil {
 				// File exists
@@ -74,9 +85,22 @@ func NewWriteTool(workingDir string) fantasy.AgentTool {
 						filePath, modTime.Format(time.RFC3339), lastRead.Format(time.RFC3339))), nil
 				}
 
+				contentType, binary, sniffErr := detectBinaryFile(filePath)
+				if sniffErr != nil {
+					return fantasy.ToolResponse{}, fmt.Errorf("error reading file: %w", sniffErr)
+				}
+				if binary {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("Cannot overwrite binary file: %s (%s)", filePath, contentType)), nil
+				}
+
 				// Check for no-op writes
-				oldContent, readErr := os.ReadFile(filePath) //nolint:gosec // G304: File path is validated above
-				if readErr == nil && string(oldContent) == params.Content {
+				var readErr error
+				existing, readErr = os.ReadFile(filePath) //nolint:gosec // G304: File path is validated above
+				if readErr != nil {
+					return fantasy.ToolResponse{}, fmt.Errorf("error reading file: %w", readErr)
+				}
+				oldContent = string(existing)
+				if oldContent == params.Content {
 					return fantasy.NewTextErrorResponse(fmt.Sprintf(
 						"File %s already contains the exact content. No changes made.", filePath)), nil
 				}
@@ -98,6 +122,15 @@ func NewWriteTool(workingDir string) fantasy.AgentTool {
 			// Record the write and read (since we now know the content)
 			RecordFileWrite(filePath)
 			RecordFileRead(filePath)
+			recordChange(ctx, filePath, WriteToolName, existing, !created, []byte(params.Content))
+
+			diff := UnifiedDiff(params.FilePath, oldContent, params.Content)
+			additions, removals := DiffStats(diff)
+
+			if hub != nil {
+				hub.Tool.Publish(pubsub.EventProgress,
+					events.NewToolDiffEvent(SessionIDFromContext(ctx), call.ID, WriteToolName, filePath, diff))
+			}
 
 			action := "written"
 			if created {
@@ -105,10 +138,13 @@ func NewWriteTool(workingDir string) fantasy.AgentTool {
 			}
 
 			return fantasy.WithResponseMetadata(
-				fantasy.NewTextResponse(fmt.Sprintf("File successfully %s: %s", action, filePath)),
+				fantasy.NewTextResponse(fmt.Sprintf("File successfully %s: %s (+%d -%d)", action, filePath, additions, removals)),
 				WriteResponseMetadata{
 					FilePath:     filePath,
+					Diff:         diff,
 					BytesWritten: len(params.Content),
+					Additions:    additions,
+					Removals:     removals,
 					Created:      created,
 				},
 			), nil
@@ -3,12 +3,17 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
 )
 
 //nolint:gocyclo // Test functions naturally have high complexity
@@ -23,7 +28,7 @@ func TestWriteTool(t *testing.T) {
 	// Clear file records before each test
 	ClearFileRecords()
 
-	tool := NewWriteTool(tmpDir)
+	tool := NewWriteTool(tmpDir, nil)
 	ctx := context.Background()
 
 	t.Run("create new file", func(t *testing.T) {
@@ -210,6 +215,100 @@ func TestWriteTool(t *testing.T) {
 	})
 }
 
+func TestWriteTool_BinaryFile(t *testing.T) {
+	ClearFileRecords()
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "data.bin")
+	if err := os.WriteFile(path, []byte("header\x00\x01payload"), 0o600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	RecordFileRead(path)
+
+	resp, err := invokeWriteTool(context.Background(), NewWriteTool(tmpDir, nil), WriteParams{FilePath: path, Content: "text"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !resp.IsError || !strings.Contains(getTextContent(resp), "binary file") {
+		t.Errorf("expected binary file error, got: %s", getTextContent(resp))
+	}
+}
+
+func TestWriteTool_PublishesDiff(t *testing.T) {
+	ClearFileRecords()
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	RecordFileRead(path)
+
+	hub := pubsub.NewHub()
+	defer hub.Shutdown()
+
+	subCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := hub.Tool.Subscribe(subCtx)
+
+	tool := NewWriteTool(tmpDir, hub)
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "session-1")
+	resp, err := invokeWriteTool(ctx, tool, WriteParams{FilePath: "main.go", Content: "package app\n"})
+	if err != nil || resp.IsError {
+		t.Fatalf("Unexpected failure: %v %s", err, getTextContent(resp))
+	}
+	if !strings.Contains(getTextContent(resp), "(+1 -1)") {
+		t.Errorf("expected diff stats in response, got: %s", getTextContent(resp))
+	}
+
+	select {
+	case event := <-ch:
+		payload := event.Payload
+		if payload.Type != events.ToolEventProgress || payload.ToolName != WriteToolName || payload.FilePath != path {
+			t.Errorf("unexpected event %+v", payload)
+		}
+		if !strings.Contains(payload.Diff, "-package main") || !strings.Contains(payload.Diff, "+package app") {
+			t.Errorf("unexpected diff:\n%s", payload.Diff)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected diff event")
+	}
+}
+
+// fakeRecorder collects recorded changes.
+type fakeRecorder struct {
+	changes []string
+}
+
+func (r *fakeRecorder) Record(sessionID, path, tool string, before []byte, existed bool, after []byte) error {
+	r.changes = append(r.changes, fmt.Sprintf("%s %s %s %q->%q existed=%v",
+		sessionID, filepath.Base(path), tool, before, after, existed))
+	return nil
+}
+
+func TestWriteTool_RecordsChanges(t *testing.T) {
+	ClearFileRecords()
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "notes.txt")
+
+	recorder := &fakeRecorder{}
+	ctx := WithChangeRecorder(WithSessionID(context.Background(), "s1"), recorder)
+	tool := NewWriteTool(tmpDir, nil)
+
+	if _, err := invokeWriteTool(ctx, tool, WriteParams{FilePath: path, Content: "one"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := invokeWriteTool(ctx, tool, WriteParams{FilePath: path, Content: "two"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`s1 notes.txt write_file ""->"one" existed=false`,
+		`s1 notes.txt write_file "one"->"two" existed=true`,
+	}
+	if strings.Join(recorder.changes, "\n") != strings.Join(want, "\n") {
+		t.Errorf("recorded changes:\n%s\nwant:\n%s", strings.Join(recorder.changes, "\n"), strings.Join(want, "\n"))
+	}
+}
+
 func invokeWriteTool(ctx context.Context, tool fantasy.AgentTool, params WriteParams) (fantasy.ToolResponse, error) {
 	inputJSON, err := json.Marshal(params)
 	if err != nil {
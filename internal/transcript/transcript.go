@@ -0,0 +1,98 @@
+// Package transcript appends the conversation of each session to a JSON Lines
+// file, one message per line, when options.transcript is set. Transcripts are
+// kept apart from the session database and are never rewritten, so they hold
+// messages that were later regenerated, edited away or deleted.
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Record is a message as written to a transcript.
+type Record struct { //nolint:govet // fieldalignment: preserving logical field order
+	Time        time.Time    `json:"time"`
+	SessionID   string       `json:"session_id"`
+	MessageID   string       `json:"message_id"`
+	Role        string       `json:"role"`
+	Content     string       `json:"content,omitempty"`
+	Reasoning   string       `json:"reasoning,omitempty"`
+	ToolCalls   []ToolCall   `json:"tool_calls,omitempty"`
+	ToolResults []ToolResult `json:"tool_results,omitempty"`
+	Attachments []string     `json:"attachments,omitempty"` // File names; the content is not copied
+	Summary     bool         `json:"summary,omitempty"`     // Compaction summary replacing the earlier messages
+	Cancelled   bool         `json:"cancelled,omitempty"`
+	Interrupted bool         `json:"interrupted,omitempty"` // cdd exited before the reply finished
+}
+
+// ToolCall is a tool call made by the model.
+type ToolCall struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Input string `json:"input"`
+}
+
+// ToolResult is the output of a tool call.
+type ToolResult struct {
+	ToolCallID string `json:"tool_call_id"`
+	Name       string `json:"name"`
+	Content    string `json:"content"`
+	IsError    bool   `json:"is_error,omitempty"`
+}
+
+// Writer appends records to one file per session under a directory. A nil
+// *Writer discards them, so callers need not check whether transcripts are
+// enabled.
+type Writer struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// New creates a writer keeping transcripts under dir.
+func New(dir string) *Writer {
+	return &Writer{dir: dir}
+}
+
+// Path returns the transcript file of a session.
+func (w *Writer) Path(sessionID string) string {
+	return filepath.Join(w.dir, sessionID+".jsonl")
+}
+
+// Write appends the records of a session in one write, so a turn is never
+// interleaved with another.
+func (w *Writer) Write(sessionID string, records ...Record) error {
+	if w == nil || len(records) == 0 {
+		return nil
+	}
+
+	var data []byte
+	for i := range records {
+		line, err := json.Marshal(&records[i])
+		if err != nil {
+			return fmt.Errorf("encoding transcript record: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := os.MkdirAll(w.dir, 0o700); err != nil {
+		return fmt.Errorf("creating transcript directory: %w", err)
+	}
+	f, err := os.OpenFile(w.Path(sessionID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:gosec // Path comes from the data directory
+	if err != nil {
+		return fmt.Errorf("opening transcript: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close() //nolint:errcheck,gosec // The write error is the one to report
+		return fmt.Errorf("writing transcript: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing transcript: %w", err)
+	}
+	return nil
+}
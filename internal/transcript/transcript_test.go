@@ -0,0 +1,75 @@
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readRecords returns the records in a transcript file.
+func readRecords(t *testing.T, path string) []Record {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close() //nolint:errcheck // Test file
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("line %q is not a record: %v", scanner.Text(), err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestWriter(t *testing.T) {
+	w := New(filepath.Join(t.TempDir(), "transcripts"))
+	now := time.Now()
+
+	if err := w.Write("s1", Record{Time: now, SessionID: "s1", MessageID: "m1", Role: "user", Content: "list files"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Write("s1",
+		Record{SessionID: "s1", MessageID: "m2", Role: "assistant", ToolCalls: []ToolCall{{ID: "c1", Name: "glob", Input: `{"pattern":"*"}`}}},
+		Record{SessionID: "s1", MessageID: "m3", Role: "tool", ToolResults: []ToolResult{{ToolCallID: "c1", Name: "glob", Content: "main.go"}}},
+	); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Write("s2", Record{SessionID: "s2", MessageID: "m4", Role: "user", Content: "hi"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	records := readRecords(t, w.Path("s1"))
+	if len(records) != 3 {
+		t.Fatalf("s1 has %d records, want 3 appended in order", len(records))
+	}
+	if records[0].Content != "list files" || records[1].ToolCalls[0].Name != "glob" || records[2].ToolResults[0].Content != "main.go" {
+		t.Errorf("records = %+v", records)
+	}
+	if got := readRecords(t, w.Path("s2")); len(got) != 1 {
+		t.Errorf("s2 has %d records, want its own file", len(got))
+	}
+
+	info, err := os.Stat(w.Path("s1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("transcript mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestWriter_Nil(t *testing.T) {
+	var w *Writer
+	if err := w.Write("s1", Record{Role: "user"}); err != nil {
+		t.Errorf("nil Write() error = %v", err)
+	}
+}
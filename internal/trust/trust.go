@@ -0,0 +1,98 @@
+// Package trust records the directories the user has agreed to run cdd in,
+// so that a freshly cloned repository cannot load its configuration, hooks
+// or commands without being confirmed first.
+package trust
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// File is the name of the list of trusted directories in the data directory.
+const File = "trusted_dirs.json"
+
+// Store is the list of trusted directories saved at a path.
+type Store struct {
+	path string
+	dirs []string
+}
+
+// Load reads the trusted directories saved in dataDir. A missing file is an
+// empty list.
+func Load(dataDir string) (*Store, error) {
+	s := &Store{path: filepath.Join(dataDir, File)}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading trusted directories: %w", err)
+	}
+	var saved struct {
+		Directories []string `json:"directories"`
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", s.path, err)
+	}
+	s.dirs = saved.Directories
+	return s, nil
+}
+
+// Dirs returns the trusted directories in the order they were trusted.
+func (s *Store) Dirs() []string {
+	return slices.Clone(s.dirs)
+}
+
+// IsTrusted reports whether dir or one of its parents is trusted.
+func (s *Store) IsTrusted(dir string) bool {
+	dir = filepath.Clean(dir)
+	for _, trusted := range s.dirs {
+		rel, err := filepath.Rel(trusted, dir)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Trust adds dir to the list and saves it.
+func (s *Store) Trust(dir string) error {
+	dir = filepath.Clean(dir)
+	if slices.Contains(s.dirs, dir) {
+		return nil
+	}
+	s.dirs = append(s.dirs, dir)
+	return s.save()
+}
+
+// Revoke removes dir from the list and saves it. It reports whether dir was
+// trusted.
+func (s *Store) Revoke(dir string) (bool, error) {
+	i := slices.Index(s.dirs, filepath.Clean(dir))
+	if i < 0 {
+		return false, nil
+	}
+	s.dirs = slices.Delete(s.dirs, i, i+1)
+	return true, s.save()
+}
+
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(struct {
+		Directories []string `json:"directories"`
+	}{s.dirs}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding trusted directories: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("creating data directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("saving trusted directories: %w", err)
+	}
+	return nil
+}
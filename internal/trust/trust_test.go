@@ -0,0 +1,40 @@
+package trust
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	dataDir := t.TempDir()
+	project := filepath.Join(t.TempDir(), "project")
+
+	s, err := Load(dataDir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if s.IsTrusted(project) {
+		t.Fatal("nothing should be trusted before Trust")
+	}
+	if err := s.Trust(project); err != nil {
+		t.Fatalf("Trust() error = %v", err)
+	}
+
+	s, err = Load(dataDir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !s.IsTrusted(project) || !s.IsTrusted(filepath.Join(project, "sub", "dir")) {
+		t.Error("a trusted directory and its subdirectories should be trusted after reloading")
+	}
+	if s.IsTrusted(filepath.Dir(project)) || s.IsTrusted(project+"-other") {
+		t.Error("parents and siblings of a trusted directory should not be trusted")
+	}
+
+	if revoked, err := s.Revoke(project); err != nil || !revoked {
+		t.Fatalf("Revoke() = %v, %v", revoked, err)
+	}
+	if s, _ := Load(dataDir); s.IsTrusted(project) {
+		t.Error("a revoked directory should not be trusted")
+	}
+}
@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/colorprofile"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+// colorProfile returns the colors the TUI draws with: the config's setting,
+// or what the terminal and environment report when it is "auto".
+func colorProfile(setting string, output io.Writer, environ []string) colorprofile.Profile {
+	switch setting {
+	case config.ColorsTrueColor:
+		return colorprofile.TrueColor
+	case config.Colors256:
+		return colorprofile.ANSI256
+	case config.Colors16:
+		return colorprofile.ANSI
+	case config.ColorsNone:
+		return colorprofile.ASCII
+	}
+
+	profile := colorprofile.Detect(output, environ)
+	// NO_COLOR turns color off whatever its value (https://no-color.org);
+	// detection only reads it as a boolean.
+	if noColor(environ) {
+		profile = min(profile, colorprofile.ASCII)
+	}
+	return profile
+}
+
+// noColor reports whether NO_COLOR is set to anything in environ.
+func noColor(environ []string) bool {
+	for _, kv := range environ {
+		if value, ok := strings.CutPrefix(kv, "NO_COLOR="); ok && value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// detectColors returns the colors of the terminal on stdout for cfg.
+func detectColors(cfg *config.Config) colorprofile.Profile {
+	setting, _ := cfg.Colors()
+	return colorProfile(setting, os.Stdout, os.Environ())
+}
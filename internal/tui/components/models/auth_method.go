@@ -69,7 +69,7 @@ func (a *AuthMethodChooser) View() string {
 		Height(boxHeight).
 		Align(lipgloss.Center).
 		AlignVertical(lipgloss.Center).
-		Border(lipgloss.RoundedBorder()).
+		Border(styles.Border()).
 		BorderForeground(t.Primary)
 
 	unselectedBox := lipgloss.NewStyle().
@@ -77,7 +77,7 @@ func (a *AuthMethodChooser) View() string {
 		Height(boxHeight).
 		Align(lipgloss.Center).
 		AlignVertical(lipgloss.Center).
-		Border(lipgloss.RoundedBorder()).
+		Border(styles.Border()).
 		BorderForeground(t.FgMuted)
 
 	selectedText := t.S().Text.Bold(true)
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"charm.land/bubbles/v2/textinput"
@@ -20,6 +23,8 @@ const (
 	FieldBaseURL
 	FieldModelID
 	FieldAPIKey
+	FieldHeaders
+	FieldQuery
 )
 
 // ConnectionForm is the form for adding/editing connections.
@@ -28,6 +33,8 @@ type ConnectionForm struct {
 	baseURLInput textinput.Model
 	modelIDInput textinput.Model
 	apiKeyInput  textinput.Model
+	headersInput textinput.Model
+	queryInput   textinput.Model
 	focused      FormField
 	providerID   string
 	providerName string
@@ -37,6 +44,9 @@ type ConnectionForm struct {
 	height       int
 	isEdit       bool
 	editID       string
+	advanced     bool // Whether the headers and query params fields are shown.
+	resolver     *config.Resolver
+	matchingVar  string // Environment variable holding the entered API key, once offered
 }
 
 // NewConnectionForm creates a new ConnectionForm.
@@ -62,12 +72,25 @@ func NewConnectionForm() *ConnectionForm {
 	apiKeyInput.EchoMode = textinput.EchoPassword
 	apiKeyInput.Prompt = ""
 
+	headersInput := textinput.New()
+	headersInput.Placeholder = "X-Header=value; X-Other=value"
+	headersInput.CharLimit = 2000
+	headersInput.Prompt = ""
+
+	queryInput := textinput.New()
+	queryInput.Placeholder = "api-version=2024-02-01"
+	queryInput.CharLimit = 1000
+	queryInput.Prompt = ""
+
 	return &ConnectionForm{
 		nameInput:    nameInput,
 		baseURLInput: baseURLInput,
 		modelIDInput: modelIDInput,
 		apiKeyInput:  apiKeyInput,
+		headersInput: headersInput,
+		queryInput:   queryInput,
 		focused:      FieldName,
+		resolver:     config.NewResolver(),
 	}
 }
 
@@ -81,6 +104,12 @@ func (f *ConnectionForm) Reset() {
 	f.modelIDInput.Blur()
 	f.apiKeyInput.Reset()
 	f.apiKeyInput.Blur()
+	f.headersInput.Reset()
+	f.headersInput.Blur()
+	f.queryInput.Reset()
+	f.queryInput.Blur()
+	f.advanced = false
+	f.matchingVar = ""
 	f.focused = FieldName
 	f.providerID = ""
 	f.providerName = ""
@@ -115,9 +144,23 @@ func (f *ConnectionForm) SetConnection(conn *config.Connection) {
 	f.providerName = conn.ProviderID // We don't have the name, use ID
 	f.nameInput.SetValue(conn.Name)
 	f.apiKeyInput.SetValue(conn.APIKey)
+	f.headersInput.SetValue(formatPairs(conn.ExtraHeaders))
+	f.queryInput.SetValue(formatPairs(conn.ExtraQuery))
+	f.advanced = len(conn.ExtraHeaders) > 0 || len(conn.ExtraQuery) > 0
 	f.focused = FieldName
 }
 
+// SetDuplicate sets up the form for a copy of conn: its name marked as a
+// copy, its headers and query params, and an empty API key for the copy's
+// own.
+func (f *ConnectionForm) SetDuplicate(conn *config.Connection) {
+	f.SetConnection(conn)
+	f.isEdit = false
+	f.editID = ""
+	f.nameInput.SetValue(conn.Name + " (copy)")
+	f.apiKeyInput.SetValue("")
+}
+
 // Focus focuses the first input.
 func (f *ConnectionForm) Focus() tea.Cmd {
 	return f.nameInput.Focus()
@@ -139,9 +182,17 @@ func (f *ConnectionForm) Update(msg tea.Msg) (*ConnectionForm, tea.Cmd) {
 			return f.nextField()
 		case "shift+tab", "up":
 			return f.prevField()
+		case "ctrl+o":
+			return f.toggleAdvanced()
+		case "ctrl+e":
+			if f.matchingVar != "" {
+				f.apiKeyInput.SetValue("$" + f.matchingVar)
+				return f.submit()
+			}
 		case keyEnter:
-			// Submit if on last field (API key).
-			if f.focused == FieldAPIKey {
+			// Submit if on the last field.
+			fields := f.fields()
+			if f.focused == fields[len(fields)-1] {
 				return f.submit()
 			}
 			// Move to next field.
@@ -159,7 +210,15 @@ func (f *ConnectionForm) Update(msg tea.Msg) (*ConnectionForm, tea.Cmd) {
 	case FieldModelID:
 		f.modelIDInput, cmd = f.modelIDInput.Update(msg)
 	case FieldAPIKey:
+		before := f.apiKeyInput.Value()
 		f.apiKeyInput, cmd = f.apiKeyInput.Update(msg)
+		if f.apiKeyInput.Value() != before {
+			f.matchingVar = ""
+		}
+	case FieldHeaders:
+		f.headersInput, cmd = f.headersInput.Update(msg)
+	case FieldQuery:
+		f.queryInput, cmd = f.queryInput.Update(msg)
 	}
 	if cmd != nil {
 		cmds = append(cmds, cmd)
@@ -193,64 +252,128 @@ func (f *ConnectionForm) submit() (*ConnectionForm, tea.Cmd) {
 		}
 	}
 
+	headers, err := parsePairs(f.headersInput.Value())
+	if err != nil {
+		return f, util.ReportWarn("Headers: " + err.Error())
+	}
+	query, err := parsePairs(f.queryInput.Value())
+	if err != nil {
+		return f, util.ReportWarn("Query params: " + err.Error())
+	}
+
+	// Offer to save a key found in the environment as a reference to it.
+	if f.matchingVar == "" {
+		if f.matchingVar = f.resolver.VarWithValue(apiKey); f.matchingVar != "" {
+			return f, nil
+		}
+	}
+
 	return f, util.CmdHandler(FormSubmitMsg{
 		Name:     name,
 		APIKey:   apiKey,
 		BaseURL:  baseURL,
 		ModelID:  modelID,
 		IsCustom: f.isCustom,
+		Headers:  headers,
+		Query:    query,
 	})
 }
 
-//nolint:dupl // nextField and prevField are intentionally similar but serve different purposes.
-func (f *ConnectionForm) nextField() (*ConnectionForm, tea.Cmd) {
-	switch f.focused {
-	case FieldName:
-		f.nameInput.Blur()
-		if f.isCustom {
-			f.focused = FieldBaseURL
-			return f, f.baseURLInput.Focus()
+// parsePairs parses "key=value; key=value" into a map. It returns nil for
+// blank input.
+func parsePairs(s string) (map[string]string, error) {
+	var pairs map[string]string
+	for part := range strings.SplitSeq(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("expected key=value, got %q", part)
 		}
-		f.focused = FieldAPIKey
-		return f, f.apiKeyInput.Focus()
+		if pairs == nil {
+			pairs = make(map[string]string)
+		}
+		pairs[key] = strings.TrimSpace(value)
+	}
+	return pairs, nil
+}
+
+// formatPairs formats a map in the form read by parsePairs, sorted by key.
+func formatPairs(pairs map[string]string) string {
+	parts := make([]string, 0, len(pairs))
+	for _, key := range slices.Sorted(maps.Keys(pairs)) {
+		parts = append(parts, key+"="+pairs[key])
+	}
+	return strings.Join(parts, "; ")
+}
+
+// fields returns the visible fields in tab order.
+func (f *ConnectionForm) fields() []FormField {
+	fields := []FormField{FieldName}
+	if f.isCustom {
+		fields = append(fields, FieldBaseURL, FieldModelID)
+	}
+	fields = append(fields, FieldAPIKey)
+	if f.advanced {
+		fields = append(fields, FieldHeaders, FieldQuery)
+	}
+	return fields
+}
+
+// input returns the text input for a field.
+func (f *ConnectionForm) input(field FormField) *textinput.Model {
+	switch field {
 	case FieldBaseURL:
-		f.baseURLInput.Blur()
-		f.focused = FieldModelID
-		return f, f.modelIDInput.Focus()
+		return &f.baseURLInput
 	case FieldModelID:
-		f.modelIDInput.Blur()
-		f.focused = FieldAPIKey
-		return f, f.apiKeyInput.Focus()
+		return &f.modelIDInput
 	case FieldAPIKey:
-		// Stay on API key - submit handled separately.
-		return f, nil
+		return &f.apiKeyInput
+	case FieldHeaders:
+		return &f.headersInput
+	case FieldQuery:
+		return &f.queryInput
+	default:
+		return &f.nameInput
+	}
+}
+
+// focusField moves focus to the given field.
+func (f *ConnectionForm) focusField(field FormField) (*ConnectionForm, tea.Cmd) {
+	f.input(f.focused).Blur()
+	f.focused = field
+	return f, f.input(field).Focus()
+}
+
+// toggleAdvanced shows or hides the headers and query params fields.
+func (f *ConnectionForm) toggleAdvanced() (*ConnectionForm, tea.Cmd) {
+	f.advanced = !f.advanced
+	if !f.advanced && (f.focused == FieldHeaders || f.focused == FieldQuery) {
+		return f.focusField(FieldAPIKey)
 	}
 	return f, nil
 }
 
-//nolint:dupl // prevField and nextField are intentionally similar but serve different purposes.
+func (f *ConnectionForm) nextField() (*ConnectionForm, tea.Cmd) {
+	fields := f.fields()
+	i := slices.Index(fields, f.focused)
+	if i < 0 || i == len(fields)-1 {
+		// Stay on the last field - submit handled separately.
+		return f, nil
+	}
+	return f.focusField(fields[i+1])
+}
+
 func (f *ConnectionForm) prevField() (*ConnectionForm, tea.Cmd) {
-	switch f.focused {
-	case FieldAPIKey:
-		f.apiKeyInput.Blur()
-		if f.isCustom {
-			f.focused = FieldModelID
-			return f, f.modelIDInput.Focus()
-		}
-		f.focused = FieldName
-		return f, f.nameInput.Focus()
-	case FieldModelID:
-		f.modelIDInput.Blur()
-		f.focused = FieldBaseURL
-		return f, f.baseURLInput.Focus()
-	case FieldBaseURL:
-		f.baseURLInput.Blur()
-		f.focused = FieldName
-		return f, f.nameInput.Focus()
-	case FieldName:
+	fields := f.fields()
+	i := slices.Index(fields, f.focused)
+	if i <= 0 {
 		return f, nil
 	}
-	return f, nil
+	return f.focusField(fields[i-1])
 }
 
 // View renders the form.
@@ -313,12 +436,45 @@ func (f *ConnectionForm) View() string {
 	sb.WriteString(f.apiKeyInput.View())
 	sb.WriteString("\n\n")
 
+	// Advanced fields.
+	if f.advanced {
+		if f.focused == FieldHeaders {
+			sb.WriteString(t.S().Primary.Bold(true).Render("Headers"))
+		} else {
+			sb.WriteString(t.S().Text.Render("Headers"))
+		}
+		sb.WriteString("\n")
+		sb.WriteString("  ")
+		sb.WriteString(f.headersInput.View())
+		sb.WriteString("\n\n")
+
+		if f.focused == FieldQuery {
+			sb.WriteString(t.S().Primary.Bold(true).Render("Query Params"))
+		} else {
+			sb.WriteString(t.S().Text.Render("Query Params"))
+		}
+		sb.WriteString("\n")
+		sb.WriteString("  ")
+		sb.WriteString(f.queryInput.View())
+		sb.WriteString("\n\n")
+	}
+
 	// Hint about env vars.
-	sb.WriteString(t.S().Muted.Render("Tip: Use $ENV_VAR to reference environment variables"))
+	tip := "Tip: Use $ENV_VAR to reference environment variables"
+	if f.advanced {
+		tip += "; separate key=value pairs with ;"
+	}
+	if f.matchingVar != "" {
+		sb.WriteString(t.S().Warning.Render(fmt.Sprintf("The API key is the value of $%s.", f.matchingVar)))
+		sb.WriteString("\n\n")
+		sb.WriteString(t.S().Muted.Render(fmt.Sprintf("[ctrl+e] save $%s instead  [enter] save the key itself  [esc] cancel", f.matchingVar)))
+		return sb.String()
+	}
+	sb.WriteString(t.S().Muted.Render(tip))
 	sb.WriteString("\n\n")
 
 	// Help.
-	sb.WriteString(t.S().Muted.Render("[tab] next field  [enter] submit  [esc] cancel"))
+	sb.WriteString(t.S().Muted.Render("[tab] next field  [ctrl+o] advanced  [enter] submit  [esc] cancel"))
 
 	return sb.String()
 }
@@ -334,6 +490,10 @@ func (f *ConnectionForm) Cursor() *tea.Cursor {
 		return f.modelIDInput.Cursor()
 	case FieldAPIKey:
 		return f.apiKeyInput.Cursor()
+	case FieldHeaders:
+		return f.headersInput.Cursor()
+	case FieldQuery:
+		return f.queryInput.Cursor()
 	}
 	return nil
 }
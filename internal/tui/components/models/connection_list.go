@@ -7,6 +7,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 
 	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
 	"github.com/guilhermegouw/cdd/internal/tui/styles"
 	"github.com/guilhermegouw/cdd/internal/tui/util"
 )
@@ -53,35 +54,51 @@ func (l *ConnectionList) SetSize(width, height int) {
 // Update handles messages.
 func (l *ConnectionList) Update(msg tea.Msg) (*ConnectionList, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
-		switch keyMsg.String() {
-		case "up", "k":
+		km := keymap.Current()
+		switch {
+		case km.Matches(keyMsg, keymap.Up):
 			if l.cursor > 0 {
 				l.cursor--
 			}
 			return l, nil
 
-		case keyDown, "j":
+		case km.Matches(keyMsg, keymap.Down):
 			if l.cursor < len(l.connections)-1 {
 				l.cursor++
 			}
 			return l, nil
 
-		case "a":
+		case keyMsg.String() == "a":
 			return l, util.CmdHandler(StartAddConnectionMsg{})
 
-		case "e":
+		case keyMsg.String() == "e":
 			if len(l.connections) > 0 {
 				return l, util.CmdHandler(EditConnectionMsg{ID: l.connections[l.cursor].ID})
 			}
 			return l, nil
 
-		case "d":
+		case keyMsg.String() == "p":
+			if len(l.connections) > 0 {
+				return l, util.CmdHandler(EditProviderMsg{ProviderID: l.connections[l.cursor].ProviderID})
+			}
+			return l, nil
+
+		case keyMsg.String() == "c":
+			if len(l.connections) > 0 {
+				return l, util.CmdHandler(DuplicateConnectionMsg{ID: l.connections[l.cursor].ID})
+			}
+			return l, nil
+
+		case keyMsg.String() == "D":
+			return l, util.CmdHandler(DeleteUnusedMsg{})
+
+		case keyMsg.String() == "d":
 			if len(l.connections) > 0 {
 				return l, util.CmdHandler(DeleteConnectionMsg{ID: l.connections[l.cursor].ID})
 			}
 			return l, nil
 
-		case keyEnter:
+		case km.Matches(keyMsg, keymap.Select):
 			if len(l.connections) > 0 {
 				return l, util.CmdHandler(ConnectionSelectedMsg{Connection: l.connections[l.cursor]})
 			}
@@ -140,8 +157,14 @@ func (l *ConnectionList) View() string {
 	sb.WriteString("\n")
 	sb.WriteString(t.S().Muted.Render("  [e] edit selected"))
 	sb.WriteString("\n")
+	sb.WriteString(t.S().Muted.Render("  [p] edit custom provider"))
+	sb.WriteString("\n")
+	sb.WriteString(t.S().Muted.Render("  [c] duplicate selected"))
+	sb.WriteString("\n")
 	sb.WriteString(t.S().Muted.Render("  [d] delete selected"))
 	sb.WriteString("\n")
+	sb.WriteString(t.S().Muted.Render("  [D] delete unused"))
+	sb.WriteString("\n")
 	sb.WriteString(t.S().Muted.Render("  [enter] select model"))
 	sb.WriteString("\n")
 	sb.WriteString(t.S().Muted.Render("  [esc] close"))
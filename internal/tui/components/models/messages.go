@@ -25,6 +25,19 @@ type (
 		ID string
 	}
 
+	// EditProviderMsg requests editing the custom provider of a connection.
+	EditProviderMsg struct {
+		ProviderID string
+	}
+
+	// DuplicateConnectionMsg requests copying a connection.
+	DuplicateConnectionMsg struct {
+		ID string
+	}
+
+	// DeleteUnusedMsg requests deleting the connections no model uses.
+	DeleteUnusedMsg struct{}
+
 	// DeleteConnectionMsg requests deleting a connection.
 	DeleteConnectionMsg struct {
 		ID string
@@ -103,6 +116,8 @@ type (
 		BaseURL  string // For custom providers
 		ModelID  string // For custom providers
 		IsCustom bool
+		Headers  map[string]string // Extra request headers, nil when none
+		Query    map[string]string // Extra query params, nil when none
 	}
 
 	// FormCancelMsg is sent when a form is cancelled.
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"strings"
 
 	tea "charm.land/bubbletea/v2"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/guilhermegouw/cdd/internal/config"
 	"github.com/guilhermegouw/cdd/internal/tui/components/wizard"
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
 	"github.com/guilhermegouw/cdd/internal/tui/styles"
 	"github.com/guilhermegouw/cdd/internal/tui/util"
 )
@@ -33,6 +35,12 @@ const (
 	StepDeleteConfirm
 	// StepSelectModel shows model selection for a connection.
 	StepSelectModel
+	// StepEditProvider shows the definition of a connection's custom provider.
+	StepEditProvider
+	// StepDuplicate shows the form for a copy of a connection.
+	StepDuplicate
+	// StepDeleteUnusedConfirm shows confirmation for deleting unused connections.
+	StepDeleteUnusedConfirm
 )
 
 // Modal is the models/connections management modal.
@@ -46,12 +54,15 @@ type Modal struct {
 	modelPicker           *ModelPicker
 	authMethodChooser     *AuthMethodChooser
 	oauthFlow             *wizard.OAuth2Flow
+	providerForm          *wizard.CustomProviderDefine
 	step                  ModalStep
 	visible               bool
 	width                 int
 	height                int
 	deleteTargetID        string
 	editTargetID          string
+	duplicateSourceID     string
+	unusedTargets         []config.Connection // Connections StepDeleteUnusedConfirm deletes
 	selectedConn          *config.Connection
 	pendingProviderID     string // Provider ID for auth method flow
 	pendingProviderName   string // Provider name for auth method flow
@@ -128,13 +139,16 @@ func (m *Modal) SetSize(width, height int) {
 	if m.oauthFlow != nil {
 		m.oauthFlow.SetWidth(innerWidth)
 	}
+	if m.providerForm != nil {
+		m.providerForm.SetWidth(innerWidth)
+	}
 }
 
 // Update handles messages.
 func (m *Modal) Update(msg tea.Msg) (*Modal, tea.Cmd) {
 	// Handle key events first for Escape.
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
-		if keyMsg.String() == "esc" {
+		if keymap.Current().Matches(keyMsg, keymap.Back) {
 			return m.handleEscape()
 		}
 	}
@@ -157,6 +171,12 @@ func (m *Modal) Update(msg tea.Msg) (*Modal, tea.Cmd) {
 		return m.updateDeleteConfirm(msg)
 	case StepSelectModel:
 		return m.updateSelectModel(msg)
+	case StepEditProvider:
+		return m.updateEditProvider(msg)
+	case StepDuplicate:
+		return m.updateDuplicate(msg)
+	case StepDeleteUnusedConfirm:
+		return m.updateDeleteUnusedConfirm(msg)
 	}
 
 	return m, nil
@@ -168,7 +188,8 @@ func (m *Modal) handleEscape() (*Modal, tea.Cmd) {
 		// Close modal.
 		m.Hide()
 		return m, util.CmdHandler(ModalClosedMsg{})
-	case StepAddProvider, StepAddForm, StepEdit, StepDeleteConfirm:
+	case StepAddProvider, StepAddForm, StepEdit, StepDeleteConfirm, StepEditProvider,
+		StepDuplicate, StepDeleteUnusedConfirm:
 		// Go back to list.
 		m.step = StepList
 		m.connectionList.Refresh()
@@ -207,11 +228,40 @@ func (m *Modal) updateList(msg tea.Msg) (*Modal, tea.Cmd) {
 		}
 		return m, nil
 
+	case EditProviderMsg:
+		p, err := m.customProviderManager.Get(msg.ProviderID)
+		if err != nil {
+			return m, util.ReportWarn(fmt.Sprintf("%s is not a custom provider", msg.ProviderID))
+		}
+		m.providerForm = wizard.NewCustomProviderEdit(*p)
+		m.providerForm.SetWidth(min(m.width-10, 60))
+		m.step = StepEditProvider
+		return m, m.providerForm.Init()
+
+	case DuplicateConnectionMsg:
+		conn := m.connManager.Get(msg.ID)
+		if conn == nil {
+			return m, nil
+		}
+		m.duplicateSourceID = msg.ID
+		m.connectionForm.Reset()
+		m.connectionForm.SetDuplicate(conn)
+		m.step = StepDuplicate
+		return m, m.connectionForm.Focus()
+
 	case DeleteConnectionMsg:
 		m.deleteTargetID = msg.ID
 		m.step = StepDeleteConfirm
 		return m, nil
 
+	case DeleteUnusedMsg:
+		m.unusedTargets = m.connManager.Unused()
+		if len(m.unusedTargets) == 0 {
+			return m, util.ReportInfo("No unused connections")
+		}
+		m.step = StepDeleteUnusedConfirm
+		return m, nil
+
 	case ConnectionSelectedMsg:
 		// Go to model selection for this connection.
 		m.selectedConn = &msg.Connection
@@ -337,10 +387,12 @@ func (m *Modal) updateAddForm(msg tea.Msg) (*Modal, tea.Cmd) {
 
 		// Create the connection.
 		conn := config.Connection{
-			Name:       msg.Name,
-			ProviderID: providerID,
-			APIKey:     msg.APIKey,
-			BaseURL:    msg.BaseURL, // Store BaseURL in connection for custom providers
+			Name:         msg.Name,
+			ProviderID:   providerID,
+			APIKey:       msg.APIKey,
+			BaseURL:      msg.BaseURL, // Store BaseURL in connection for custom providers
+			ExtraHeaders: msg.Headers,
+			ExtraQuery:   msg.Query,
 		}
 		if err := m.connManager.Add(conn); err != nil {
 			return m, util.ReportError(err)
@@ -369,6 +421,8 @@ func (m *Modal) updateEdit(msg tea.Msg) (*Modal, tea.Cmd) {
 		}
 		conn.Name = msg.Name
 		conn.APIKey = msg.APIKey
+		conn.ExtraHeaders = msg.Headers
+		conn.ExtraQuery = msg.Query
 		if err := m.connManager.Update(*conn); err != nil {
 			return m, util.ReportError(err)
 		}
@@ -386,6 +440,59 @@ func (m *Modal) updateEdit(msg tea.Msg) (*Modal, tea.Cmd) {
 	return m, cmd
 }
 
+func (m *Modal) updateDuplicate(msg tea.Msg) (*Modal, tea.Cmd) {
+	switch msg := msg.(type) {
+	case FormSubmitMsg:
+		dup, err := m.connManager.Duplicate(m.duplicateSourceID, msg.Name, msg.APIKey)
+		if err != nil {
+			return m, util.ReportError(err)
+		}
+		// The headers and query params may have been changed for the copy.
+		dup.ExtraHeaders = msg.Headers
+		dup.ExtraQuery = msg.Query
+		if err := m.connManager.Update(*dup); err != nil {
+			return m, util.ReportError(err)
+		}
+		m.step = StepList
+		m.connectionList.Refresh()
+		return m, util.ReportSuccess("Connection duplicated")
+
+	case FormCancelMsg:
+		m.step = StepList
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.connectionForm, cmd = m.connectionForm.Update(msg)
+	return m, cmd
+}
+
+func (m *Modal) updateEditProvider(msg tea.Msg) (*Modal, tea.Cmd) {
+	if dm, ok := msg.(wizard.CustomProviderDefinedMsg); ok {
+		if err := m.customProviderManager.Update(dm.Provider.ID, dm.Provider); err != nil {
+			return m, util.ReportError(err)
+		}
+
+		// Keep the known providers in step with the saved definition.
+		updated := dm.Provider.ToCatwalkProvider()
+		knownProviders := m.cfg.KnownProviders()
+		for i := range knownProviders {
+			if knownProviders[i].ID == updated.ID {
+				knownProviders[i] = updated
+			}
+		}
+		m.cfg.SetKnownProviders(knownProviders)
+
+		m.providerForm = nil
+		m.step = StepList
+		m.connectionList.Refresh()
+		return m, util.ReportSuccess("Provider updated")
+	}
+
+	_, cmd := m.providerForm.Update(msg)
+	return m, cmd
+}
+
 func (m *Modal) updateDeleteConfirm(msg tea.Msg) (*Modal, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		switch keyMsg.String() {
@@ -406,6 +513,30 @@ func (m *Modal) updateDeleteConfirm(msg tea.Msg) (*Modal, tea.Cmd) {
 	return m, nil
 }
 
+func (m *Modal) updateDeleteUnusedConfirm(msg tea.Msg) (*Modal, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "y", "Y", keyEnter:
+			ids := make([]string, 0, len(m.unusedTargets))
+			for i := range m.unusedTargets {
+				ids = append(ids, m.unusedTargets[i].ID)
+			}
+			if err := m.connManager.DeleteMany(ids); err != nil {
+				return m, util.ReportError(err)
+			}
+			m.unusedTargets = nil
+			m.step = StepList
+			m.connectionList.Refresh()
+			return m, util.ReportSuccess(fmt.Sprintf("Deleted %d unused connection(s)", len(ids)))
+		case "n", "N":
+			m.unusedTargets = nil
+			m.step = StepList
+			return m, nil
+		}
+	}
+	return m, nil
+}
+
 func (m *Modal) updateSelectModel(msg tea.Msg) (*Modal, tea.Cmd) {
 	if msm, ok := msg.(ModelSelectedMsg); ok {
 		// Set the active model in config (always Large tier - Small is reserved for future use).
@@ -481,6 +612,17 @@ func (m *Modal) View() string {
 	case StepSelectModel:
 		title = "Select Model"
 		content = m.modelPicker.View()
+	case StepDuplicate:
+		title = "Duplicate Connection"
+		content = m.connectionForm.View()
+	case StepDeleteUnusedConfirm:
+		title = "Delete Unused Connections"
+		content = m.renderDeleteUnusedConfirm()
+	case StepEditProvider:
+		title = "Edit Provider"
+		if m.providerForm != nil {
+			content = m.providerForm.View()
+		}
 	}
 
 	// Build modal box.
@@ -502,7 +644,7 @@ func (m *Modal) View() string {
 	)
 
 	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(styles.Border()).
 		BorderForeground(t.BorderFocus).
 		Padding(1, 2).
 		Width(boxWidth)
@@ -529,13 +671,36 @@ func (m *Modal) renderDeleteConfirm(name string) string {
 	return sb.String()
 }
 
+func (m *Modal) renderDeleteUnusedConfirm() string {
+	t := styles.CurrentTheme()
+
+	var sb strings.Builder
+	sb.WriteString(t.S().Text.Render("No model uses these connections:"))
+	sb.WriteString("\n\n")
+	for i := range m.unusedTargets {
+		sb.WriteString("  ")
+		sb.WriteString(t.S().Primary.Bold(true).Render(m.unusedTargets[i].Name))
+		sb.WriteString(t.S().Muted.Render(fmt.Sprintf(" (%s)", m.unusedTargets[i].ProviderID)))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(t.S().Text.Render("Delete them all?"))
+	sb.WriteString("\n\n")
+	sb.WriteString(t.S().Muted.Render("[y] Yes  [n] No  [esc] Cancel"))
+
+	return sb.String()
+}
+
 // Cursor returns the cursor position.
 func (m *Modal) Cursor() *tea.Cursor {
-	if m.step == StepAddForm || m.step == StepEdit {
+	if m.step == StepAddForm || m.step == StepEdit || m.step == StepDuplicate {
 		return m.connectionForm.Cursor()
 	}
 	if m.step == StepOAuth && m.oauthFlow != nil {
 		return m.oauthFlow.Cursor()
 	}
+	if m.step == StepEditProvider && m.providerForm != nil {
+		return m.providerForm.Cursor()
+	}
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
 
 	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
 	"github.com/guilhermegouw/cdd/internal/tui/styles"
 	"github.com/guilhermegouw/cdd/internal/tui/util"
 )
@@ -33,22 +34,7 @@ func NewModelPicker(cfg *config.Config) *ModelPicker {
 func (p *ModelPicker) SetConnection(conn *config.Connection) {
 	p.connection = conn
 	p.cursor = 0
-	p.models = nil
-
-	// First try provider config (may have user-configured models).
-	if provider, ok := p.cfg.Providers[conn.ProviderID]; ok && len(provider.Models) > 0 {
-		p.models = provider.Models
-		return
-	}
-
-	// Fall back to known providers from catwalk.
-	known := p.cfg.KnownProviders()
-	for i := range known {
-		if string(known[i].ID) == conn.ProviderID {
-			p.models = known[i].Models
-			return
-		}
-	}
+	p.models = p.cfg.ConnectionModels(conn)
 }
 
 // SetSize sets the component size.
@@ -60,20 +46,21 @@ func (p *ModelPicker) SetSize(width, height int) {
 // Update handles messages.
 func (p *ModelPicker) Update(msg tea.Msg) (*ModelPicker, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
-		switch keyMsg.String() {
-		case "up", "k":
+		km := keymap.Current()
+		switch {
+		case km.Matches(keyMsg, keymap.Up):
 			if p.cursor > 0 {
 				p.cursor--
 			}
 			return p, nil
 
-		case keyDown, "j":
+		case km.Matches(keyMsg, keymap.Down):
 			if p.cursor < len(p.models)-1 {
 				p.cursor++
 			}
 			return p, nil
 
-		case keyEnter:
+		case km.Matches(keyMsg, keymap.Select):
 			if p.cursor >= 0 && p.cursor < len(p.models) && p.connection != nil {
 				model := p.models[p.cursor]
 				return p, util.CmdHandler(ModelSelectedMsg{
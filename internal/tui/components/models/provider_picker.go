@@ -7,6 +7,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
 
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
 	"github.com/guilhermegouw/cdd/internal/tui/styles"
 	"github.com/guilhermegouw/cdd/internal/tui/util"
 )
@@ -89,20 +90,21 @@ func (p *ProviderPicker) SetSize(width, height int) {
 // Update handles messages.
 func (p *ProviderPicker) Update(msg tea.Msg) (*ProviderPicker, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
-		switch keyMsg.String() {
-		case "up", "k":
+		km := keymap.Current()
+		switch {
+		case km.Matches(keyMsg, keymap.Up):
 			if p.cursor > 0 {
 				p.cursor--
 			}
 			return p, nil
 
-		case keyDown, "j":
+		case km.Matches(keyMsg, keymap.Down):
 			if p.cursor < len(p.options)-1 {
 				p.cursor++
 			}
 			return p, nil
 
-		case keyEnter:
+		case km.Matches(keyMsg, keymap.Select):
 			if p.cursor >= 0 && p.cursor < len(p.options) {
 				opt := p.options[p.cursor]
 				return p, util.CmdHandler(ProviderSelectedMsg{
@@ -0,0 +1,112 @@
+package sessions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/guilhermegouw/cdd/internal/journal"
+	"github.com/guilhermegouw/cdd/internal/tui/styles"
+)
+
+// maxCheckpointRows is how many checkpoints the dialog shows at once.
+const maxCheckpointRows = 8
+
+// CheckpointList shows the prompts in a session that changed files. Each one
+// can be restored to put the files back as they were before it ran.
+type CheckpointList struct {
+	checkpoints []journal.Checkpoint
+	cursor      int
+	offset      int // Scroll offset
+	width       int
+	confirming  bool // Asking before restoring the selected checkpoint
+}
+
+// NewCheckpointList creates an empty checkpoint list.
+func NewCheckpointList() *CheckpointList {
+	return &CheckpointList{}
+}
+
+// SetCheckpoints replaces the list and selects the newest checkpoint.
+func (c *CheckpointList) SetCheckpoints(checkpoints []journal.Checkpoint) {
+	c.checkpoints = checkpoints
+	c.confirming = false
+	c.cursor = max(0, len(checkpoints)-1)
+	c.offset = max(0, len(checkpoints)-maxCheckpointRows)
+}
+
+// SetWidth sets the list width.
+func (c *CheckpointList) SetWidth(width int) {
+	c.width = width
+}
+
+// MoveUp selects the previous (older) checkpoint.
+func (c *CheckpointList) MoveUp() {
+	if c.cursor > 0 {
+		c.cursor--
+		c.offset = min(c.offset, c.cursor)
+	}
+}
+
+// MoveDown selects the next (newer) checkpoint.
+func (c *CheckpointList) MoveDown() {
+	if c.cursor < len(c.checkpoints)-1 {
+		c.cursor++
+		c.offset = max(c.offset, c.cursor-maxCheckpointRows+1)
+	}
+}
+
+// Selected returns the highlighted checkpoint.
+func (c *CheckpointList) Selected() (journal.Checkpoint, bool) {
+	if c.cursor < len(c.checkpoints) {
+		return c.checkpoints[c.cursor], true
+	}
+	return journal.Checkpoint{}, false
+}
+
+// View renders the checkpoints, oldest first, or the restore confirmation.
+func (c *CheckpointList) View() string {
+	t := styles.CurrentTheme()
+
+	if len(c.checkpoints) == 0 {
+		return t.S().Muted.Render("No file changes recorded in this session.")
+	}
+
+	if c.confirming {
+		selected, _ := c.Selected()
+		later := len(c.checkpoints) - c.cursor - 1
+		var sb strings.Builder
+		sb.WriteString(t.S().Text.Render("Restore files to before "))
+		sb.WriteString(t.S().Primary.Bold(true).Render(truncateToWidth(promptLine(selected.Prompt), max(c.width-24, 10)))) //nolint:mnd // Leave room for the sentence
+		sb.WriteString(t.S().Text.Render("?\n\n"))
+		if later > 0 {
+			sb.WriteString(t.S().Warning.Render(fmt.Sprintf("The changes of %d later prompt(s) are reverted too. ", later)))
+		}
+		sb.WriteString(t.S().Muted.Render("Messages are kept."))
+		return sb.String()
+	}
+
+	rows := make([]string, 0, maxCheckpointRows+2) //nolint:mnd // Rows plus scroll indicators
+	if c.offset > 0 {
+		rows = append(rows, t.S().Muted.Render(fmt.Sprintf("  ↑ %d more above", c.offset)))
+	}
+	end := min(c.offset+maxCheckpointRows, len(c.checkpoints))
+	for i := c.offset; i < end; i++ {
+		cp := c.checkpoints[i]
+		when := formatDateTime(cp.CreatedAt)
+		prompt := truncateToWidth(promptLine(cp.Prompt), max(c.width-len(when)-4, 10)) //nolint:mnd // Marker and spacing
+		if i == c.cursor {
+			rows = append(rows, t.S().Primary.Bold(true).Render("> "+prompt)+"  "+t.S().Muted.Render(when))
+		} else {
+			rows = append(rows, t.S().Text.Render("  "+prompt)+"  "+t.S().Muted.Render(when))
+		}
+	}
+	if remaining := len(c.checkpoints) - end; remaining > 0 {
+		rows = append(rows, t.S().Muted.Render(fmt.Sprintf("  ↓ %d more below", remaining)))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// promptLine flattens a prompt onto one line.
+func promptLine(prompt string) string {
+	return strings.Join(strings.Fields(prompt), " ")
+}
@@ -1,8 +1,11 @@
 package sessions
 
 import (
+	"strings"
+
 	"charm.land/lipgloss/v2"
 
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
 	"github.com/guilhermegouw/cdd/internal/tui/styles"
 )
 
@@ -20,6 +23,8 @@ const (
 	HintModeDelete
 	// HintModeExport shows hints for export mode.
 	HintModeExport
+	// HintModeCheckpoints shows hints for the checkpoint list.
+	HintModeCheckpoints
 )
 
 // HintBar displays context-sensitive keyboard hints.
@@ -49,18 +54,32 @@ func (h *HintBar) SetWidth(width int) {
 func (h *HintBar) View() string {
 	t := styles.CurrentTheme()
 
+	km := keymap.Current()
+	back := km.Key(keymap.Back)
+
 	var hints string
 	switch h.mode {
 	case HintModeNormal:
-		hints = "[/] search  [n] new  [enter] open  [r] rename  [d] delete  [esc] close"
+		hints = joinHints(
+			hint(km.Key(keymap.Search), "search"),
+			hint(km.Key(keymap.NewSession), "new"),
+			hint(km.Key(keymap.Select), "open"),
+			hint(km.Key(keymap.RenameSession), "rename"),
+			hint(km.Key(keymap.DeleteSession), "delete"),
+			hint(km.Key(keymap.Checkpoints), "checkpoints"),
+			hint(km.Key(keymap.AllProjects), "all projects"),
+			hint(back, "close"),
+		)
 	case HintModeSearch:
-		hints = "[enter] done  [esc] clear  [↑↓] navigate"
+		hints = joinHints(hint(km.Key(keymap.Select), "done"), hint(back, "clear"), hint("↑↓", "navigate"))
 	case HintModeRename:
-		hints = "[enter] save  [esc] cancel"
+		hints = joinHints(hint("enter", "save"), hint(back, "cancel"))
 	case HintModeDelete:
-		hints = "[y] yes  [n] no  [esc] cancel"
+		hints = joinHints(hint("y", "yes"), hint("n", "no"), hint(back, "cancel"))
 	case HintModeExport:
-		hints = "[m] markdown  [esc] cancel"
+		hints = joinHints(hint("m", "markdown"), hint(back, "cancel"))
+	case HintModeCheckpoints:
+		hints = joinHints(hint("↑↓", "navigate"), hint(km.Key(keymap.Select), "restore"), hint(back, "back"))
 	}
 
 	hintStyle := t.S().Muted.
@@ -69,3 +88,22 @@ func (h *HintBar) View() string {
 
 	return hintStyle.Render(hints)
 }
+
+// hint formats a single hint, or returns "" when the action is unbound.
+func hint(key, label string) string {
+	if key == "" {
+		return ""
+	}
+	return "[" + key + "] " + label
+}
+
+// joinHints joins the non-empty hints.
+func joinHints(hints ...string) string {
+	shown := hints[:0]
+	for _, h := range hints {
+		if h != "" {
+			shown = append(shown, h)
+		}
+	}
+	return strings.Join(shown, "  ")
+}
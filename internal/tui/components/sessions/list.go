@@ -4,6 +4,7 @@ package sessions
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 
 	"github.com/guilhermegouw/cdd/internal/debug"
 	"github.com/guilhermegouw/cdd/internal/session"
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
 	"github.com/guilhermegouw/cdd/internal/tui/styles"
 	"github.com/guilhermegouw/cdd/internal/tui/util"
 )
@@ -36,6 +38,7 @@ type SessionList struct {
 	offset      int // Scroll offset
 	searchMode  bool
 	searchText  string
+	allProjects bool // List sessions of every project, not just the current one
 }
 
 // NewSessionList creates a new session list.
@@ -61,7 +64,7 @@ func (l *SessionList) Refresh() {
 		l.sessions = nil
 		return
 	}
-	sessions, err := l.sessionSvc.ListWithPreview(ctx)
+	sessions, err := l.sessionSvc.ListWithPreview(ctx, l.allProjects)
 	if err != nil {
 		debug.Log("SessionList.Refresh: error loading sessions: %v", err)
 		l.sessions = nil
@@ -86,7 +89,7 @@ func (l *SessionList) Search(keyword string) {
 		return
 	}
 
-	sessions, err := l.sessionSvc.SearchWithPreview(ctx, keyword)
+	sessions, err := l.sessionSvc.SearchWithPreview(ctx, keyword, l.allProjects)
 	if err != nil {
 		l.sessions = nil
 		return
@@ -96,6 +99,24 @@ func (l *SessionList) Search(keyword string) {
 	l.offset = 0
 }
 
+// ToggleAllProjects switches between the current project's sessions and
+// those of all projects, keeping any search.
+func (l *SessionList) ToggleAllProjects() {
+	l.allProjects = !l.allProjects
+	l.cursor = 0
+	l.offset = 0
+	if l.searchText != "" {
+		l.Search(l.searchText)
+		return
+	}
+	l.Refresh()
+}
+
+// AllProjects reports whether sessions of all projects are listed.
+func (l *SessionList) AllProjects() bool {
+	return l.allProjects
+}
+
 // SetSize sets the list dimensions.
 func (l *SessionList) SetSize(width, height int) {
 	l.width = width
@@ -120,45 +141,52 @@ func (l *SessionList) Update(msg tea.Msg) (*SessionList, tea.Cmd) {
 	}
 
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
-		switch keyMsg.String() {
-		case "up", "k":
+		km := keymap.Current()
+		switch {
+		case km.Matches(keyMsg, keymap.Up):
 			if l.cursor > 0 {
 				l.cursor--
 				l.ensureVisible()
 			}
-		case "down", "j": //nolint:goconst // Navigation keys are intentionally readable
+		case km.Matches(keyMsg, keymap.Down):
 			if l.cursor < len(l.sessions)-1 {
 				l.cursor++
 				l.ensureVisible()
 			}
-		case "home", "g":
+		case km.Matches(keyMsg, keymap.Top):
 			l.cursor = 0
 			l.offset = 0
-		case "end", "G":
+		case km.Matches(keyMsg, keymap.Bottom):
 			l.cursor = max(0, len(l.sessions)-1)
 			l.ensureVisible()
-		case keyEnter:
+		case km.Matches(keyMsg, keymap.Select):
 			if selected := l.Selected(); selected != nil {
 				return l, util.CmdHandler(SessionSelectedMsg{SessionID: selected.ID})
 			}
-		case "n":
+		case km.Matches(keyMsg, keymap.NewSession):
 			return l, util.CmdHandler(NewSessionMsg{})
-		case "r":
+		case km.Matches(keyMsg, keymap.RenameSession):
 			if selected := l.Selected(); selected != nil {
 				return l, util.CmdHandler(RenameSessionMsg{
 					SessionID:    selected.ID,
 					CurrentTitle: selected.Title,
 				})
 			}
-		case "d":
+		case km.Matches(keyMsg, keymap.DeleteSession):
 			if selected := l.Selected(); selected != nil {
 				return l, util.CmdHandler(DeleteSessionMsg{SessionID: selected.ID})
 			}
-		case "e":
+		case km.Matches(keyMsg, keymap.ExportSession):
 			if selected := l.Selected(); selected != nil {
 				return l, util.CmdHandler(ExportSessionMsg{SessionID: selected.ID})
 			}
-		case "/":
+		case km.Matches(keyMsg, keymap.Checkpoints):
+			if selected := l.Selected(); selected != nil {
+				return l, util.CmdHandler(CheckpointsMsg{SessionID: selected.ID})
+			}
+		case km.Matches(keyMsg, keymap.AllProjects):
+			return l, util.CmdHandler(ToggleProjectsMsg{})
+		case km.Matches(keyMsg, keymap.Search):
 			l.searchMode = true
 			l.searchInput.SetValue("")
 			return l, l.searchInput.Focus()
@@ -171,8 +199,9 @@ func (l *SessionList) Update(msg tea.Msg) (*SessionList, tea.Cmd) {
 // updateSearchMode handles input when in search mode.
 func (l *SessionList) updateSearchMode(msg tea.Msg) (*SessionList, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
-		switch keyMsg.String() {
-		case "esc":
+		km := keymap.Current()
+		switch {
+		case km.Matches(keyMsg, keymap.Back):
 			// Exit search mode and show all sessions
 			l.searchMode = false
 			l.searchText = ""
@@ -180,12 +209,12 @@ func (l *SessionList) updateSearchMode(msg tea.Msg) (*SessionList, tea.Cmd) {
 			l.searchInput.Blur()
 			l.Refresh()
 			return l, nil
-		case keyEnter:
+		case km.Matches(keyMsg, keymap.Select):
 			// Exit search mode but keep filtered results
 			l.searchMode = false
 			l.searchInput.Blur()
 			return l, nil
-		case "up", "down":
+		case keyMsg.String() == "up" || keyMsg.String() == "down":
 			// Allow navigation while searching
 			l.searchMode = false
 			l.searchInput.Blur()
@@ -292,6 +321,9 @@ func (l *SessionList) renderSession(sess *session.SessionWithPreview, selected b
 	// Message count and time.
 	timeStr := formatRelativeTime(sess.UpdatedAt)
 	meta := fmt.Sprintf("%d msgs · %s", sess.MessageCount, timeStr)
+	if l.allProjects && sess.Project != "" {
+		meta += " · " + filepath.Base(sess.Project)
+	}
 
 	// Preview line.
 	preview := sess.FirstMessage
@@ -364,7 +396,7 @@ func (l *SessionList) TotalCount() int {
 	}
 	// When searching, we need to get total from a fresh query
 	ctx := context.Background()
-	all, err := l.sessionSvc.ListWithPreview(ctx)
+	all, err := l.sessionSvc.ListWithPreview(ctx, l.allProjects)
 	if err != nil {
 		return len(l.sessions)
 	}
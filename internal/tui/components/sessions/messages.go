@@ -34,6 +34,15 @@ type ExportMarkdownMsg struct {
 	SessionID string
 }
 
+// CheckpointsMsg is sent to list a session's checkpoints.
+type CheckpointsMsg struct {
+	SessionID string
+}
+
+// ToggleProjectsMsg switches the list between the current project's sessions
+// and those of all projects.
+type ToggleProjectsMsg struct{}
+
 // NewSessionMsg is sent to create a new session.
 type NewSessionMsg struct{}
 
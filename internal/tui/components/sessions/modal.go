@@ -2,6 +2,7 @@ package sessions
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -9,7 +10,9 @@ import (
 	"charm.land/lipgloss/v2"
 
 	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/journal"
 	"github.com/guilhermegouw/cdd/internal/session"
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
 	"github.com/guilhermegouw/cdd/internal/tui/styles"
 	"github.com/guilhermegouw/cdd/internal/tui/util"
 )
@@ -26,11 +29,15 @@ const (
 	StepDeleteConfirm
 	// StepExport shows export options.
 	StepExport
+	// StepCheckpoints lists the checkpoints of a session.
+	StepCheckpoints
 )
 
 // Modal is the sessions management modal.
 type Modal struct {
 	sessionSvc     *session.Service
+	journal        *journal.Journal // Nil when file changes are not recorded
+	checkpointList *CheckpointList
 	sessionList    *SessionList
 	preview        *Preview
 	renameInput    *RenameInput
@@ -43,7 +50,8 @@ type Modal struct {
 	height         int
 	deleteTargetID string
 	renameTargetID string
-	totalSessions  int // Total count before filtering
+	checkpointsID  string // Session whose checkpoints are listed
+	totalSessions  int    // Total count before filtering
 }
 
 // New creates a new sessions Modal.
@@ -60,10 +68,16 @@ func New(sessionSvc *session.Service) *Modal {
 	m.searchBox = NewSearchBox()
 	m.hintBar = NewHintBar()
 	m.listPanel = NewBorderedPanel()
+	m.checkpointList = NewCheckpointList()
 
 	return m
 }
 
+// SetJournal sets the journal checkpoints are restored from.
+func (m *Modal) SetJournal(j *journal.Journal) {
+	m.journal = j
+}
+
 // Init initializes the modal.
 func (m *Modal) Init() tea.Cmd {
 	debug.Log("Modal.Init: initializing modal")
@@ -131,13 +145,14 @@ func (m *Modal) SetSize(width, height int) {
 	m.searchBox.SetWidth(totalWidth)
 	m.hintBar.SetWidth(totalWidth)
 	m.renameInput.SetWidth(min(totalWidth-4, 56))
+	m.checkpointList.SetWidth(min(totalWidth-4, 56))
 }
 
 // Update handles messages.
 func (m *Modal) Update(msg tea.Msg) (*Modal, tea.Cmd) {
 	// Handle key events first for Escape.
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
-		if keyMsg.String() == "esc" {
+		if keymap.Current().Matches(keyMsg, keymap.Back) {
 			return m.handleEscape()
 		}
 	}
@@ -152,6 +167,8 @@ func (m *Modal) Update(msg tea.Msg) (*Modal, tea.Cmd) {
 		return m.updateDeleteConfirm(msg)
 	case StepExport:
 		return m.updateExport(msg)
+	case StepCheckpoints:
+		return m.updateCheckpoints(msg)
 	}
 
 	return m, nil
@@ -173,6 +190,15 @@ func (m *Modal) handleEscape() (*Modal, tea.Cmd) {
 		// Close modal.
 		m.Hide()
 		return m, util.CmdHandler(ModalClosedMsg{})
+	case StepCheckpoints:
+		if m.checkpointList.confirming {
+			m.checkpointList.confirming = false
+			m.hintBar.SetMode(HintModeCheckpoints)
+			return m, nil
+		}
+		m.step = StepList
+		m.hintBar.SetMode(HintModeNormal)
+		return m, nil
 	case StepRename, StepDeleteConfirm, StepExport:
 		// Go back to list.
 		m.step = StepList
@@ -241,6 +267,16 @@ func (m *Modal) updateList(msg tea.Msg) (*Modal, tea.Cmd) {
 		m.hintBar.SetMode(HintModeExport)
 		return m, nil
 
+	case CheckpointsMsg:
+		return m, m.showCheckpoints(msg.SessionID)
+
+	case ToggleProjectsMsg:
+		m.sessionList.ToggleAllProjects()
+		m.totalSessions = m.sessionList.TotalCount()
+		m.searchBox.SetCounts(m.sessionList.Count(), m.totalSessions)
+		m.preview.SetSession(m.sessionList.Selected())
+		return m, nil
+
 	case NewSessionMsg:
 		// Create new session and switch to it.
 		ctx := context.Background()
@@ -260,7 +296,7 @@ func (m *Modal) updateList(msg tea.Msg) (*Modal, tea.Cmd) {
 	}
 
 	// Handle '/' to enter search mode
-	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "/" && !m.searchBox.IsVisible() {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && keymap.Current().Matches(keyMsg, keymap.Search) && !m.searchBox.IsVisible() {
 		m.searchBox.SetCounts(m.sessionList.Count(), m.totalSessions)
 		m.hintBar.SetMode(HintModeSearch)
 		m.SetSize(m.width, m.height) // Recalculate with search box
@@ -342,6 +378,73 @@ func (m *Modal) updateExport(msg tea.Msg) (*Modal, tea.Cmd) {
 	return m, nil
 }
 
+// showCheckpoints lists the checkpoints of a session.
+func (m *Modal) showCheckpoints(sessionID string) tea.Cmd {
+	if m.journal == nil {
+		return util.ReportInfo("File changes are not being recorded")
+	}
+	checkpoints, err := m.journal.Checkpoints(sessionID)
+	if err != nil {
+		return util.ReportError(err)
+	}
+	m.checkpointsID = sessionID
+	m.checkpointList.SetCheckpoints(checkpoints)
+	m.step = StepCheckpoints
+	m.hintBar.SetMode(HintModeCheckpoints)
+	return nil
+}
+
+func (m *Modal) updateCheckpoints(msg tea.Msg) (*Modal, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if m.checkpointList.confirming {
+		switch keyMsg.String() {
+		case "y", "Y", keyEnter:
+			return m, m.restoreCheckpoint()
+		case "n", "N":
+			m.checkpointList.confirming = false
+			m.hintBar.SetMode(HintModeCheckpoints)
+		}
+		return m, nil
+	}
+
+	km := keymap.Current()
+	switch {
+	case km.Matches(keyMsg, keymap.Up):
+		m.checkpointList.MoveUp()
+	case km.Matches(keyMsg, keymap.Down):
+		m.checkpointList.MoveDown()
+	case km.Matches(keyMsg, keymap.Select):
+		if _, ok := m.checkpointList.Selected(); ok {
+			m.checkpointList.confirming = true
+			m.hintBar.SetMode(HintModeDelete)
+		}
+	}
+	return m, nil
+}
+
+// restoreCheckpoint reverts the files to the selected checkpoint and returns
+// to the session list.
+func (m *Modal) restoreCheckpoint() tea.Cmd {
+	selected, _ := m.checkpointList.Selected()
+	reverted, err := m.journal.RestoreCheckpoint(m.checkpointsID, selected.MessageID)
+
+	m.step = StepList
+	m.checkpointList.confirming = false
+	m.hintBar.SetMode(HintModeNormal)
+
+	switch {
+	case errors.Is(err, journal.ErrModified):
+		return util.ReportWarn(fmt.Sprintf("Restored %d file change(s), then stopped: %v", len(reverted), err))
+	case err != nil:
+		return util.ReportError(err)
+	}
+	return util.ReportSuccess(fmt.Sprintf("Restored files to the checkpoint (%d change(s) reverted)", len(reverted)))
+}
+
 // View renders the modal.
 func (m *Modal) View() string {
 	if !m.visible {
@@ -377,7 +480,11 @@ func (m *Modal) renderTelescopeView() string {
 	m.preview.SetSize(previewWidth, panelHeight)
 
 	// Render list panel with "Sessions" title
-	m.listPanel.SetTitle("Sessions")
+	if m.sessionList.AllProjects() {
+		m.listPanel.SetTitle("Sessions · all projects")
+	} else {
+		m.listPanel.SetTitle("Sessions")
+	}
 	m.listPanel.SetContent(m.sessionList.ViewList())
 	listView := m.listPanel.View()
 
@@ -430,6 +537,9 @@ func (m *Modal) renderDialog() string {
 	case StepExport:
 		title = "Export Session"
 		content = m.renderExportOptions()
+	case StepCheckpoints:
+		title = "Checkpoints"
+		content = m.checkpointList.View()
 	}
 
 	contentWidth := boxWidth - 6
@@ -450,7 +560,7 @@ func (m *Modal) renderDialog() string {
 	)
 
 	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(styles.Border()).
 		BorderForeground(t.BorderFocus).
 		Padding(1, 2).
 		Width(boxWidth)
@@ -25,6 +25,8 @@ type APIKeyInput struct {
 	input        textinput.Model
 	width        int
 	envVarMode   bool
+	resolver     *config.Resolver
+	matchingVar  string // Environment variable holding the entered key, once offered
 }
 
 // NewAPIKeyInput creates a new API key input component.
@@ -42,6 +44,7 @@ func NewAPIKeyInput(providerName string) *APIKeyInput {
 	return &APIKeyInput{
 		input:        ti,
 		providerName: providerName,
+		resolver:     config.NewResolver(),
 	}
 }
 
@@ -56,9 +59,23 @@ func (a *APIKeyInput) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		switch keyMsg.String() {
 		case keyEnter:
 			value := strings.TrimSpace(a.input.Value())
-			if value != "" {
+			if value == "" {
+				break
+			}
+			// A key already in the environment is better saved as a
+			// reference to it; offer that once before saving the key itself.
+			if a.matchingVar == "" {
+				if a.matchingVar = a.resolver.VarWithValue(value); a.matchingVar != "" {
+					return a, nil
+				}
+			}
+			return a, util.CmdHandler(APIKeyEnteredMsg{
+				APIKey: value,
+			})
+		case keyCtrlE:
+			if a.matchingVar != "" {
 				return a, util.CmdHandler(APIKeyEnteredMsg{
-					APIKey: value,
+					APIKey: "$" + a.matchingVar,
 				})
 			}
 		case keyTab:
@@ -73,7 +90,11 @@ func (a *APIKeyInput) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 	}
 
 	var cmd tea.Cmd
+	before := a.input.Value()
 	a.input, cmd = a.input.Update(msg)
+	if a.input.Value() != before {
+		a.matchingVar = ""
+	}
 
 	// Check if input looks like an env var reference.
 	a.envVarMode = strings.HasPrefix(a.input.Value(), "$")
@@ -98,6 +119,10 @@ func (a *APIKeyInput) View() string {
 
 	// Hint about env vars.
 	hint := t.S().Subtle.Render("Tip: Use $ENV_VAR to reference an environment variable")
+	if a.matchingVar != "" {
+		hint = t.S().Warning.Render(fmt.Sprintf("This key is the value of $%s.", a.matchingVar))
+		help = t.S().Muted.Render(fmt.Sprintf("Ctrl+E to save $%s instead | Enter to save the key itself", a.matchingVar))
+	}
 
 	// Config path info.
 	configPath := config.GlobalConfigPath()
@@ -140,5 +165,6 @@ func (a *APIKeyInput) SetProviderName(name string) {
 // Reset clears the input.
 func (a *APIKeyInput) Reset() {
 	a.input.SetValue("")
+	a.matchingVar = ""
 	a.input.Focus()
 }
@@ -0,0 +1,75 @@
+package wizard
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+// enterAPIKey types key into a and presses Enter, returning the key sent, if any.
+func enterAPIKey(a *APIKeyInput, key string) (string, bool) {
+	a.input.SetValue(key)
+	return pressAPIKey(a, tea.KeyPressMsg{Code: tea.KeyEnter})
+}
+
+// pressAPIKey sends msg to a and returns the key sent, if any.
+func pressAPIKey(a *APIKeyInput, msg tea.Msg) (string, bool) {
+	_, cmd := a.Update(msg)
+	if cmd == nil {
+		return "", false
+	}
+	entered, ok := cmd().(APIKeyEnteredMsg)
+	return entered.APIKey, ok
+}
+
+func TestAPIKeyInput_OffersEnvReference(t *testing.T) {
+	env := map[string]string{"OPENAI_API_KEY": "sk-from-the-env"}
+	ctrlE := tea.KeyPressMsg{Code: 'e', Mod: tea.ModCtrl}
+
+	t.Run("saves the reference", func(t *testing.T) {
+		a := NewAPIKeyInput("OpenAI")
+		a.resolver = config.NewResolverWithEnv(env)
+		if _, sent := enterAPIKey(a, "sk-from-the-env"); sent {
+			t.Fatal("a key found in the environment should be offered as a reference first")
+		}
+		if !strings.Contains(a.View(), "$OPENAI_API_KEY") {
+			t.Error("View() should name the variable")
+		}
+		if key, _ := pressAPIKey(a, ctrlE); key != "$OPENAI_API_KEY" {
+			t.Errorf("Ctrl+E sent %q, want the reference", key)
+		}
+	})
+
+	t.Run("keeps the key on a second Enter", func(t *testing.T) {
+		a := NewAPIKeyInput("OpenAI")
+		a.resolver = config.NewResolverWithEnv(env)
+		enterAPIKey(a, "sk-from-the-env")
+		if key, _ := pressAPIKey(a, tea.KeyPressMsg{Code: tea.KeyEnter}); key != "sk-from-the-env" {
+			t.Errorf("Enter sent %q, want the key itself", key)
+		}
+	})
+
+	t.Run("editing withdraws the offer", func(t *testing.T) {
+		a := NewAPIKeyInput("OpenAI")
+		a.resolver = config.NewResolverWithEnv(env)
+		enterAPIKey(a, "sk-from-the-env")
+		pressAPIKey(a, tea.KeyPressMsg{Code: 'x', Text: "x"})
+		if a.matchingVar != "" {
+			t.Error("changing the key should withdraw the offer")
+		}
+		if _, sent := pressAPIKey(a, ctrlE); sent {
+			t.Error("Ctrl+E should do nothing without an offer")
+		}
+	})
+
+	t.Run("other keys are sent at once", func(t *testing.T) {
+		a := NewAPIKeyInput("OpenAI")
+		a.resolver = config.NewResolverWithEnv(env)
+		if key, _ := enterAPIKey(a, "sk-typed-by-hand"); key != "sk-typed-by-hand" {
+			t.Errorf("Enter sent %q, want the key", key)
+		}
+	})
+}
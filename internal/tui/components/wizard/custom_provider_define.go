@@ -5,6 +5,7 @@ package wizard
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"charm.land/bubbles/v2/textinput"
@@ -41,6 +42,9 @@ type CustomProviderDefine struct {
 	headerMode     bool // true when adding a header
 	headerIndex    int  // for editing existing headers
 
+	// The provider being edited, nil when defining a new one.
+	original *config.CustomProvider
+
 	width int
 	step  int // 0: name, 1: id, 2: type, 3: endpoint, 4: headers, 5: confirm
 }
@@ -109,6 +113,29 @@ func NewCustomProviderDefine() *CustomProviderDefine {
 	}
 }
 
+// NewCustomProviderEdit creates a definition component pre-populated from an
+// existing provider. Its ID can't be changed, as connections refer to it; the
+// models and default model IDs are kept as they are.
+func NewCustomProviderEdit(p config.CustomProvider) *CustomProviderDefine {
+	c := NewCustomProviderDefine()
+	c.original = &p
+	c.nameInput.SetValue(p.Name)
+	c.idInput.SetValue(p.ID)
+	c.typeInput.SetValue(string(p.Type))
+	if i := slices.Index(c.providerTypes, p.Type); i >= 0 {
+		c.typeIndex = i
+	}
+	endpoint := p.APIEndpoint
+	if endpoint == "" {
+		endpoint = p.BaseURL
+	}
+	c.apiEndpointInput.SetValue(endpoint)
+	for k, v := range p.DefaultHeaders {
+		c.headers[k] = v
+	}
+	return c
+}
+
 // Init initializes the component.
 func (c *CustomProviderDefine) Init() tea.Cmd {
 	return textinput.Blink
@@ -174,7 +201,12 @@ func (c *CustomProviderDefine) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 func (c *CustomProviderDefine) handleEnter() (util.Model, tea.Cmd) {
 	switch c.step {
 	case 0: // Name
-		if strings.TrimSpace(c.nameInput.Value()) != "" {
+		if strings.TrimSpace(c.nameInput.Value()) != "" && c.original != nil {
+			// Skip the ID, which can't change.
+			c.step = 2
+			c.typeInput.Focus()
+			c.nameInput.Blur()
+		} else if strings.TrimSpace(c.nameInput.Value()) != "" {
 			c.step = 1
 			c.idInput.Focus()
 			c.nameInput.Blur()
@@ -213,10 +245,12 @@ func (c *CustomProviderDefine) handleEnter() (util.Model, tea.Cmd) {
 					c.headerValInput.Focus()
 				}
 			} else {
-				// Save header and go back to key.
+				// Save header and go back to key. An empty value removes it.
 				key := strings.TrimSpace(c.headerKeyInput.Value())
 				val := strings.TrimSpace(c.headerValInput.Value())
-				if key != "" {
+				if val == "" {
+					delete(c.headers, key)
+				} else if key != "" {
 					c.headers[key] = val
 				}
 				c.headerKeyInput.SetValue("")
@@ -254,6 +288,15 @@ func (c *CustomProviderDefine) handleTab() (util.Model, tea.Cmd) {
 func (c *CustomProviderDefine) buildProvider() config.CustomProvider {
 	providerType := catwalk.Type(c.typeInput.Value())
 
+	if c.original != nil {
+		p := *c.original
+		p.Name = strings.TrimSpace(c.nameInput.Value())
+		p.Type = providerType
+		p.APIEndpoint = strings.TrimSpace(c.apiEndpointInput.Value())
+		p.DefaultHeaders = c.headers
+		return p
+	}
+
 	return config.CustomProvider{
 		Name:           strings.TrimSpace(c.nameInput.Value()),
 		ID:             strings.TrimSpace(c.idInput.Value()),
@@ -269,6 +312,9 @@ func (c *CustomProviderDefine) View() string {
 	t := styles.CurrentTheme()
 
 	title := t.S().Title.Render("Define Custom Provider")
+	if c.original != nil {
+		title = t.S().Title.Render("Edit Custom Provider")
+	}
 
 	var fields []string
 
@@ -277,8 +323,12 @@ func (c *CustomProviderDefine) View() string {
 		"A friendly name for this provider"))
 
 	// Step 1: ID
-	fields = append(fields, c.renderField("Provider ID", c.idInput, c.step == 1,
-		"Unique identifier (e.g., my-custom-provider)"))
+	if c.original != nil {
+		fields = append(fields, c.renderReadOnlyField("Provider ID", c.original.ID, false))
+	} else {
+		fields = append(fields, c.renderField("Provider ID", c.idInput, c.step == 1,
+			"Unique identifier (e.g., my-custom-provider)"))
+	}
 
 	// Step 2: Type
 	typeHelp := "Use ↑/↓ to select from: openai-compat, openai, anthropic, google, azure, bedrock, vertexai, openrouter"
@@ -388,7 +438,7 @@ func (c *CustomProviderDefine) renderSummary() string {
 	t := styles.CurrentTheme()
 
 	summaryStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(styles.Border()).
 		BorderForeground(t.Primary).
 		Padding(1)
 
@@ -410,7 +460,11 @@ func (c *CustomProviderDefine) renderSummary() string {
 	}
 
 	lines = append(lines, "")
-	lines = append(lines, t.S().Success.Render("Press Enter to continue to model configuration"))
+	if c.original != nil {
+		lines = append(lines, t.S().Success.Render("Press Enter to save changes"))
+	} else {
+		lines = append(lines, t.S().Success.Render("Press Enter to continue to model configuration"))
+	}
 	lines = append(lines, t.S().Muted.Render("Press Tab to add more headers"))
 
 	return summaryStyle.Render(strings.Join(lines, "\n"))
@@ -424,7 +478,7 @@ func (c *CustomProviderDefine) getHelpText() string {
 		return t.S().Muted.Render("Enter to continue • Tab to skip")
 	case 4:
 		if c.headerMode {
-			return t.S().Muted.Render("Enter to add header • Tab to finish headers")
+			return t.S().Muted.Render("Enter to add header (empty value removes it) • Tab to finish headers")
 		}
 		return t.S().Muted.Render("Tab to finish headers")
 	case 5:
@@ -0,0 +1,70 @@
+package wizard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+func TestCustomProviderEdit(t *testing.T) {
+	original := config.CustomProvider{
+		Name:                "Gateway",
+		ID:                  "gateway",
+		Type:                catwalk.TypeAnthropic,
+		BaseURL:             "https://gw.example.com",
+		DefaultHeaders:      map[string]string{"X-Team": "platform", "X-Old": "1"},
+		DefaultLargeModelID: "big",
+		Models:              []catwalk.Model{{ID: "big", Name: "Big"}},
+	}
+	c := NewCustomProviderEdit(original)
+
+	if c.nameInput.Value() != "Gateway" || c.apiEndpointInput.Value() != "https://gw.example.com" {
+		t.Errorf("inputs = %q, %q, want the existing name and endpoint", c.nameInput.Value(), c.apiEndpointInput.Value())
+	}
+	if c.providerTypes[c.typeIndex] != catwalk.TypeAnthropic {
+		t.Errorf("type = %s, want anthropic selected", c.providerTypes[c.typeIndex])
+	}
+	if !strings.Contains(c.View(), "Edit Custom Provider") {
+		t.Error("view should say the provider is being edited")
+	}
+
+	c.nameInput.SetValue("Team Gateway")
+	c.handleEnter()
+	if c.step != 2 {
+		t.Fatalf("step = %d, want the ID step skipped", c.step)
+	}
+	c.handleEnter() // Type
+	c.apiEndpointInput.SetValue("https://gw2.example.com/v1")
+	c.handleEnter() // Endpoint
+
+	// An empty value removes a header.
+	c.headerKeyInput.SetValue("X-Old")
+	c.handleEnter()
+	c.handleEnter()
+	c.handleTab()
+
+	_, cmd := c.handleEnter()
+	if cmd == nil {
+		t.Fatal("confirming should finish the definition")
+	}
+	msg, ok := cmd().(CustomProviderDefinedMsg)
+	if !ok {
+		t.Fatalf("got %T, want CustomProviderDefinedMsg", cmd())
+	}
+	p := msg.Provider
+	if p.ID != "gateway" || p.Name != "Team Gateway" || p.APIEndpoint != "https://gw2.example.com/v1" {
+		t.Errorf("provider = %s %q at %s, want the same ID with the new name and endpoint", p.ID, p.Name, p.APIEndpoint)
+	}
+	if len(p.DefaultHeaders) != 1 || p.DefaultHeaders["X-Team"] != "platform" {
+		t.Errorf("headers = %v, want only X-Team", p.DefaultHeaders)
+	}
+	if len(p.Models) != 1 || p.DefaultLargeModelID != "big" {
+		t.Errorf("models and defaults should be kept, got %+v", p)
+	}
+	if len(original.DefaultHeaders) != 2 {
+		t.Error("editing should not change the original's headers")
+	}
+}
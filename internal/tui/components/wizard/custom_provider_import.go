@@ -0,0 +1,267 @@
+package wizard
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/tui/styles"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// CustomProvidersImportedMsg is sent with the providers chosen for import.
+type CustomProvidersImportedMsg struct {
+	Providers []config.CustomProvider
+}
+
+// customProvidersFetchedMsg carries a providers file read from a URL or path.
+type customProvidersFetchedMsg struct {
+	file *config.CustomProvidersFile
+	err  error
+}
+
+// importCandidate is a provider found in the file, with what is wrong with it.
+type importCandidate struct {
+	provider config.CustomProvider
+	result   *config.ValidationResult
+	selected bool
+}
+
+// CustomProviderImport reads custom providers from a URL or a file and lets
+// the user pick which to import. Providers that fail validation are shown
+// with the reason and can't be picked.
+type CustomProviderImport struct {
+	method      ProviderImportMethod
+	input       textinput.Model
+	existingIDs []string
+
+	loading    bool
+	err        error
+	candidates []importCandidate // Set once a file has been read
+	cursor     int
+
+	width int
+}
+
+// NewCustomProviderImport creates an importer reading from a URL or a file,
+// as method says. Providers whose IDs are in existingIDs are rejected.
+func NewCustomProviderImport(method ProviderImportMethod, existingIDs []string) *CustomProviderImport {
+	t := styles.CurrentTheme()
+
+	input := textinput.New()
+	input.Prompt = "> "
+	input.SetStyles(t.S().TextInput)
+	if method == ProviderImportMethodURL {
+		input.Placeholder = "https://example.com/providers.json"
+	} else {
+		input.Placeholder = "~/providers.json"
+	}
+	input.Focus()
+
+	return &CustomProviderImport{
+		method:      method,
+		input:       input,
+		existingIDs: existingIDs,
+		width:       60,
+	}
+}
+
+// Init initializes the component.
+func (c *CustomProviderImport) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update handles messages.
+func (c *CustomProviderImport) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case customProvidersFetchedMsg:
+		c.loading = false
+		c.err = msg.err
+		if msg.err == nil {
+			c.setCandidates(msg.file.Providers)
+		}
+		return c, nil
+	case tea.KeyMsg:
+		if c.loading {
+			return c, nil
+		}
+		if c.candidates != nil {
+			return c.updateSelection(msg)
+		}
+		if msg.String() == keyEnter {
+			return c, c.fetch()
+		}
+	}
+
+	var cmd tea.Cmd
+	c.input, cmd = c.input.Update(msg)
+	return c, cmd
+}
+
+// fetch reads the providers file named in the input.
+func (c *CustomProviderImport) fetch() tea.Cmd {
+	source := strings.TrimSpace(c.input.Value())
+	if source == "" {
+		return nil
+	}
+	if c.method == ProviderImportMethodURL && !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		c.err = fmt.Errorf("enter a URL starting with http:// or https://")
+		return nil
+	}
+	if rest, ok := strings.CutPrefix(source, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			source = filepath.Join(home, rest)
+		}
+	}
+
+	c.loading = true
+	c.err = nil
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), discoverTimeout)
+		defer cancel()
+		file, err := config.ReadCustomProviders(ctx, source)
+		return customProvidersFetchedMsg{file: file, err: err}
+	}
+}
+
+// setCandidates validates the providers read and selects the valid ones.
+func (c *CustomProviderImport) setCandidates(providers []config.CustomProvider) {
+	c.candidates = make([]importCandidate, len(providers))
+	c.cursor = 0
+	seen := slices.Clone(c.existingIDs)
+	for i := range providers {
+		result := config.ValidateCustomProvider(&providers[i], seen)
+		c.candidates[i] = importCandidate{provider: providers[i], result: result, selected: result.IsValid}
+		seen = append(seen, providers[i].ID)
+	}
+}
+
+func (c *CustomProviderImport) updateSelection(msg tea.KeyMsg) (util.Model, tea.Cmd) {
+	switch msg.String() {
+	case keyUp, keyK:
+		if c.cursor > 0 {
+			c.cursor--
+		}
+	case keyDown, keyJ:
+		if c.cursor < len(c.candidates)-1 {
+			c.cursor++
+		}
+	case "space", " ":
+		if cand := &c.candidates[c.cursor]; cand.result.IsValid {
+			cand.selected = !cand.selected
+		}
+	case keyEnter:
+		var chosen []config.CustomProvider
+		for i := range c.candidates {
+			if c.candidates[i].selected {
+				chosen = append(chosen, c.candidates[i].provider)
+			}
+		}
+		if len(chosen) == 0 {
+			c.err = fmt.Errorf("select at least one provider to import")
+			return c, nil
+		}
+		return c, util.CmdHandler(CustomProvidersImportedMsg{Providers: chosen})
+	}
+	return c, nil
+}
+
+// Back leaves the provider selection for the source input. It returns false
+// when the input is already shown, so the wizard should go back a step.
+func (c *CustomProviderImport) Back() bool {
+	if c.candidates == nil {
+		return false
+	}
+	c.candidates = nil
+	c.err = nil
+	c.input.Focus()
+	return true
+}
+
+// View renders the importer.
+func (c *CustomProviderImport) View() string {
+	t := styles.CurrentTheme()
+
+	title := "Import Providers from URL"
+	label := "URL of a providers.json file:"
+	if c.method == ProviderImportMethodFile {
+		title = "Import Providers from File"
+		label = "Path to a providers.json file:"
+	}
+
+	lines := []string{t.S().Title.Render(title), ""}
+	if c.candidates == nil {
+		lines = append(lines, t.S().Success.Bold(true).Render(label), c.input.View())
+		switch {
+		case c.loading:
+			lines = append(lines, "", t.S().Muted.Render("Reading providers..."))
+		case c.err != nil:
+			lines = append(lines, "", t.S().Error.Render("✗ "+c.err.Error()))
+		}
+		lines = append(lines, "", t.S().Muted.Render("Enter to read the file"))
+		return lipgloss.JoinVertical(lipgloss.Left, lines...)
+	}
+
+	lines = append(lines, t.S().Text.Render("Select the providers to import:"), "")
+	for i := range c.candidates {
+		lines = append(lines, c.renderCandidate(i))
+	}
+	if c.err != nil {
+		lines = append(lines, "", t.S().Error.Render("✗ "+c.err.Error()))
+	}
+	lines = append(lines, "", t.S().Muted.Render("↑/↓ to move • Space to toggle • Enter to import • Esc to change the source"))
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// renderCandidate shows one provider with its checkbox and any problems.
+func (c *CustomProviderImport) renderCandidate(i int) string {
+	t := styles.CurrentTheme()
+	cand := c.candidates[i]
+
+	cursor := "  "
+	style := t.S().Text
+	if i == c.cursor {
+		cursor = t.S().Success.Render(styles.Selected + " ")
+		style = t.S().Text.Bold(true)
+	}
+	box := "[ ] "
+	if cand.selected {
+		box = "[x] "
+	}
+	if !cand.result.IsValid {
+		box = "[-] "
+		style = t.S().Subtle
+	}
+	line := cursor + box + style.Render(cand.provider.Name) +
+		t.S().Muted.Render(fmt.Sprintf(" (%s, %d models)", cand.provider.ID, len(cand.provider.Models)))
+
+	for _, e := range cand.result.Errors {
+		line += "\n      " + t.S().Error.Render(e.Field+": "+e.Message)
+	}
+	for _, w := range cand.result.Warnings {
+		line += "\n      " + t.S().Warning.Render(w.Field+": "+w.Message)
+	}
+	return line
+}
+
+// SetWidth sets the component width.
+func (c *CustomProviderImport) SetWidth(width int) {
+	c.width = width
+	c.input.SetWidth(width - 4)
+}
+
+// Cursor returns the cursor position while the source is being entered.
+func (c *CustomProviderImport) Cursor() *tea.Cursor {
+	if c.candidates != nil || c.loading {
+		return nil
+	}
+	return c.input.Cursor()
+}
@@ -0,0 +1,152 @@
+package wizard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/adrg/xdg"
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// providersJSON has a valid provider, one with an unknown type and one whose
+// ID is taken by a built-in provider.
+const providersJSON = `{"version":"1.0","providers":[
+	{"id":"gateway","name":"Gateway","type":"openai-compat","base_url":"https://gw.example.com/v1","models":[{"id":"big","name":"Big"}]},
+	{"id":"broken","name":"Broken","type":"carrier-pigeon","models":[{"id":"m","name":"M"}]},
+	{"id":"openai","name":"Shadow","type":"openai","models":[{"id":"m","name":"M"}]}
+]}`
+
+// writeProviders writes content to a providers file and returns its path.
+func writeProviders(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "providers.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// readSource types source into the importer and reads it.
+func readSource(t *testing.T, c *CustomProviderImport, source string) {
+	t.Helper()
+	c.input.SetValue(source)
+	_, cmd := c.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Enter should read the source")
+	}
+	c.Update(cmd())
+}
+
+func TestCustomProviderImport(t *testing.T) {
+	t.Run("validates and selects providers", func(t *testing.T) {
+		c := NewCustomProviderImport(ProviderImportMethodFile, []string{"openai"})
+		readSource(t, c, writeProviders(t, providersJSON))
+
+		if len(c.candidates) != 3 {
+			t.Fatalf("candidates = %d, want 3", len(c.candidates))
+		}
+		if !c.candidates[0].selected || c.candidates[1].selected || c.candidates[2].selected {
+			t.Error("only the valid provider should be selected")
+		}
+		view := c.View()
+		for _, want := range []string{"Gateway", `unsupported provider type "carrier-pigeon"`, "conflicts with existing provider"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("View() should show %q", want)
+			}
+		}
+
+		// Invalid providers can't be picked.
+		c.cursor = 1
+		c.Update(tea.KeyPressMsg{Code: tea.KeySpace, Text: " "})
+		if c.candidates[1].selected {
+			t.Error("an invalid provider was selected")
+		}
+
+		_, cmd := c.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+		msg, ok := cmd().(CustomProvidersImportedMsg)
+		if !ok || len(msg.Providers) != 1 || msg.Providers[0].ID != "gateway" {
+			t.Errorf("import sent %#v, want the gateway", msg)
+		}
+	})
+
+	t.Run("needs a selection", func(t *testing.T) {
+		c := NewCustomProviderImport(ProviderImportMethodFile, []string{"openai"})
+		readSource(t, c, writeProviders(t, providersJSON))
+		c.cursor = 0
+		c.Update(tea.KeyPressMsg{Code: tea.KeySpace, Text: " "})
+		if _, cmd := c.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); cmd != nil {
+			t.Error("importing nothing should not finish")
+		}
+		if !strings.Contains(c.View(), "select at least one provider") {
+			t.Error("View() should ask for a selection")
+		}
+	})
+
+	t.Run("reports read errors inline", func(t *testing.T) {
+		c := NewCustomProviderImport(ProviderImportMethodFile, nil)
+		readSource(t, c, writeProviders(t, "{not json"))
+		if c.candidates != nil || !strings.Contains(c.View(), "parsing JSON") {
+			t.Errorf("View() = %q, want the parse error under the input", c.View())
+		}
+	})
+
+	t.Run("URL method wants a URL", func(t *testing.T) {
+		c := NewCustomProviderImport(ProviderImportMethodURL, nil)
+		c.input.SetValue("providers.json")
+		if _, cmd := c.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); cmd != nil {
+			t.Error("a path should not be fetched as a URL")
+		}
+		if !strings.Contains(c.View(), "http://") {
+			t.Error("View() should explain what a URL looks like")
+		}
+	})
+
+	t.Run("back returns to the source", func(t *testing.T) {
+		c := NewCustomProviderImport(ProviderImportMethodFile, nil)
+		if c.Back() {
+			t.Error("Back() on the source input should let the wizard go back")
+		}
+		readSource(t, c, writeProviders(t, providersJSON))
+		if !c.Back() || c.candidates != nil {
+			t.Error("Back() should return from the selection to the source input")
+		}
+	})
+}
+
+func TestWizard_ImportProviders(t *testing.T) {
+	dataHome := xdg.DataHome
+	xdg.DataHome = t.TempDir()
+	t.Cleanup(func() { xdg.DataHome = dataHome })
+
+	w := NewWizard([]catwalk.Provider{{ID: "openai", Name: "OpenAI"}})
+	w.step = StepCustomProviderMethod
+	w.customProviderMethod = NewCustomProviderMethod()
+	w.Update(CustomProviderMethodSelectedMsg{Method: ProviderImportMethodFile})
+	if w.step != StepCustomProviderImport {
+		t.Fatalf("step = %d, want the import step", w.step)
+	}
+	readSource(t, w.customProviderImport, writeProviders(t, providersJSON))
+
+	_, cmd := w.customProviderImport.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	w.Update(cmd())
+
+	// The one provider imported goes straight on to its API key.
+	if w.step != StepAPIKey || w.selectedProvider == nil || w.selectedProvider.ID != "gateway" {
+		t.Fatalf("step = %d with %v, want the gateway's API key", w.step, w.selectedProvider)
+	}
+	if len(w.providers) != 3 || w.providers[1].ID != "gateway" {
+		t.Errorf("providers = %v, want the gateway before the custom option", w.providers)
+	}
+	data, err := os.ReadFile(filepath.Join(xdg.DataHome, "cdd", "custom-providers.json"))
+	if err != nil || !strings.Contains(string(data), `"gateway"`) {
+		t.Errorf("custom providers file = %s, %v; want the gateway saved", data, err)
+	}
+
+	w.goBack()
+	if w.step != StepProvider || w.providerList.SelectedProvider().ID != "gateway" {
+		t.Errorf("going back should show the provider list at the gateway")
+	}
+}
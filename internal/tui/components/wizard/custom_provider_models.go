@@ -4,9 +4,12 @@
 package wizard
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
@@ -14,6 +17,7 @@ import (
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
 
 	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/provider"
 	"github.com/guilhermegouw/cdd/internal/tui/styles"
 	"github.com/guilhermegouw/cdd/internal/tui/util"
 )
@@ -23,6 +27,15 @@ type CustomProviderModelsCompleteMsg struct {
 	Provider config.CustomProvider
 }
 
+// discoverTimeout bounds the request for the endpoint's model list.
+const discoverTimeout = 10 * time.Second
+
+// modelsDiscoveredMsg carries the result of fetching the endpoint's model list.
+type modelsDiscoveredMsg struct {
+	models []catwalk.Model
+	err    error
+}
+
 // CustomProviderModels handles model definition for custom providers.
 type CustomProviderModels struct {
 	provider config.CustomProvider
@@ -40,6 +53,10 @@ type CustomProviderModels struct {
 	step      int // 0: name, 1: id, 2: context, 3: cost_in, 4: cost_out, 5: max_tokens, 6: confirm
 	editIndex int // -1 when adding new model
 
+	// Model discovery for OpenAI-compatible endpoints.
+	discovering  bool
+	discoverNote string
+
 	width int
 }
 
@@ -97,11 +114,72 @@ func NewCustomProviderModels(provider config.CustomProvider) *CustomProviderMode
 
 // Init initializes the component.
 func (c *CustomProviderModels) Init() tea.Cmd {
-	return textinput.Blink
+	if !provider.SupportsDiscovery(c.provider.Type) || c.endpoint() == "" {
+		return textinput.Blink
+	}
+	c.discovering = true
+	return tea.Batch(textinput.Blink, c.discoverModels())
+}
+
+// endpoint returns the provider's API endpoint.
+func (c *CustomProviderModels) endpoint() string {
+	if c.provider.APIEndpoint != "" {
+		return c.provider.APIEndpoint
+	}
+	return c.provider.BaseURL
+}
+
+// discoverModels fetches the model list from the provider's endpoint.
+func (c *CustomProviderModels) discoverModels() tea.Cmd {
+	endpoint := c.endpoint()
+	headers := c.provider.DefaultHeaders
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), discoverTimeout)
+		defer cancel()
+		models, err := provider.DiscoverModels(ctx, endpoint, "", headers)
+		return modelsDiscoveredMsg{models: models, err: err}
+	}
+}
+
+// handleDiscovered adds discovered models and skips to the finish step if the
+// user has not started entering a model by hand.
+func (c *CustomProviderModels) handleDiscovered(msg modelsDiscoveredMsg) (util.Model, tea.Cmd) {
+	c.discovering = false
+	if msg.err != nil {
+		c.discoverNote = fmt.Sprintf("Could not discover models (%v). Define them manually.", msg.err)
+		return c, nil
+	}
+
+	for _, m := range provider.MergeModels(msg.models, slices.Concat(c.provider.Models, c.models)) {
+		if !containsModel(c.models, m.ID) {
+			c.models = append(c.models, m)
+		}
+	}
+	c.discoverNote = fmt.Sprintf("Discovered %d model(s) from %s", len(msg.models), c.endpoint())
+
+	if c.step == 0 && c.modelNameInput.Value() == "" {
+		c.step = 7
+		c.modelNameInput.Blur()
+	}
+	return c, nil
+}
+
+// containsModel reports whether models contains a model with the given ID.
+func containsModel(models []catwalk.Model, id string) bool {
+	for i := range models {
+		if models[i].ID == id {
+			return true
+		}
+	}
+	return false
 }
 
 // Update handles messages.
 func (c *CustomProviderModels) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	if discovered, ok := msg.(modelsDiscoveredMsg); ok {
+		return c.handleDiscovered(discovered)
+	}
+
 	keyMsg, ok := msg.(tea.KeyMsg)
 	if !ok {
 		return c, nil
@@ -196,7 +274,8 @@ func (c *CustomProviderModels) handleTab() (util.Model, tea.Cmd) {
 			c.step = 7
 		}
 	case 7:
-		c.step = 6
+		c.step = 0
+		c.modelNameInput.Focus()
 	}
 	return c, nil
 }
@@ -262,6 +341,13 @@ func (c *CustomProviderModels) View() string {
 
 	var content []string
 
+	switch {
+	case c.discovering:
+		content = append(content, t.S().Subtle.Render(fmt.Sprintf("Discovering models from %s…", c.endpoint())), "")
+	case c.discoverNote != "":
+		content = append(content, t.S().Subtle.Render(c.discoverNote), "")
+	}
+
 	// Existing models list.
 	if len(c.models) > 0 {
 		content = append(content, c.renderModelList())
@@ -333,7 +419,7 @@ func (c *CustomProviderModels) renderConfirmStep() string {
 	t := styles.CurrentTheme()
 
 	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(styles.Border()).
 		BorderForeground(t.Primary).
 		Padding(1)
 
@@ -366,7 +452,7 @@ func (c *CustomProviderModels) renderFinishStep() string {
 	t := styles.CurrentTheme()
 
 	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(styles.Border()).
 		BorderForeground(t.Success).
 		Padding(1)
 
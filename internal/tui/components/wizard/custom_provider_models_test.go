@@ -0,0 +1,70 @@
+package wizard
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+func TestCustomProviderModels_Discovery(t *testing.T) {
+	compat := config.CustomProvider{
+		Name:        "Local",
+		ID:          "local",
+		Type:        catwalk.TypeOpenAICompat,
+		APIEndpoint: "http://localhost:11434/v1",
+	}
+
+	t.Run("starts discovery for OpenAI-compatible endpoints", func(t *testing.T) {
+		c := NewCustomProviderModels(compat)
+		c.Init()
+		if !c.discovering {
+			t.Error("expected discovery to start")
+		}
+	})
+
+	t.Run("skips discovery for other provider types", func(t *testing.T) {
+		p := compat
+		p.Type = catwalk.TypeAnthropic
+		c := NewCustomProviderModels(p)
+		c.Init()
+		if c.discovering {
+			t.Error("discovery should not start for anthropic providers")
+		}
+	})
+
+	t.Run("fills models and jumps to finish", func(t *testing.T) {
+		c := NewCustomProviderModels(compat)
+		c.Init()
+		c.Update(modelsDiscoveredMsg{models: []catwalk.Model{{ID: "llama3.2", Name: "llama3.2"}}})
+
+		if c.discovering {
+			t.Error("discovery should be finished")
+		}
+		if len(c.models) != 1 || c.models[0].ID != "llama3.2" {
+			t.Errorf("unexpected models %+v", c.models)
+		}
+		if c.step != 7 {
+			t.Errorf("step = %d, want finish step", c.step)
+		}
+		if !strings.Contains(c.View(), "Discovered 1 model") {
+			t.Error("expected discovery note in view")
+		}
+	})
+
+	t.Run("falls back to manual entry on error", func(t *testing.T) {
+		c := NewCustomProviderModels(compat)
+		c.Init()
+		c.Update(modelsDiscoveredMsg{err: errors.New("connection refused")})
+
+		if c.step != 0 || len(c.models) != 0 {
+			t.Errorf("expected manual entry, got step %d with %d models", c.step, len(c.models))
+		}
+		if !strings.Contains(c.View(), "connection refused") {
+			t.Error("expected error note in view")
+		}
+	})
+}
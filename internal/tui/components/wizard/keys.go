@@ -9,4 +9,5 @@ const (
 	keyEsc   = "esc"
 	keyK     = "k"
 	keyJ     = "j"
+	keyCtrlE = "ctrl+e"
 )
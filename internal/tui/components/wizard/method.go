@@ -78,7 +78,7 @@ func (a *AuthMethodChooser) View() string {
 		Height(boxHeight).
 		Align(lipgloss.Center).
 		AlignVertical(lipgloss.Center).
-		Border(lipgloss.RoundedBorder()).
+		Border(styles.Border()).
 		BorderForeground(t.Primary)
 
 	unselectedBox := lipgloss.NewStyle().
@@ -86,7 +86,7 @@ func (a *AuthMethodChooser) View() string {
 		Height(boxHeight).
 		Align(lipgloss.Center).
 		AlignVertical(lipgloss.Center).
-		Border(lipgloss.RoundedBorder()).
+		Border(styles.Border()).
 		BorderForeground(t.FgMuted)
 
 	selectedText := t.S().Text.Bold(true)
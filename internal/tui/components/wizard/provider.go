@@ -114,6 +114,17 @@ func (p *ProviderList) SetSize(width, height int) {
 	p.height = height
 }
 
+// SetCursorToProvider moves the cursor to the provider with the given ID, if
+// it is listed.
+func (p *ProviderList) SetCursorToProvider(id catwalk.InferenceProvider) {
+	for i := range p.providers {
+		if p.providers[i].ID == id {
+			p.cursor = i
+			return
+		}
+	}
+}
+
 // SelectedProvider returns the currently selected provider.
 func (p *ProviderList) SelectedProvider() *catwalk.Provider {
 	if len(p.providers) == 0 {
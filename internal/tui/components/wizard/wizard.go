@@ -4,14 +4,19 @@
 package wizard
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
 
 	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/debug"
 	"github.com/guilhermegouw/cdd/internal/oauth"
+	"github.com/guilhermegouw/cdd/internal/provider"
 	"github.com/guilhermegouw/cdd/internal/tui/styles"
 	"github.com/guilhermegouw/cdd/internal/tui/util"
 )
@@ -23,6 +28,7 @@ type Step int
 const (
 	StepProvider Step = iota
 	StepCustomProviderMethod
+	StepCustomProviderImport
 	StepCustomProviderDefine
 	StepCustomProviderModels
 	StepAuthMethod
@@ -41,6 +47,31 @@ type CompleteMsg struct {
 	SmallModelID string
 }
 
+// configSavedMsg is sent when the wizard's choices have been saved, or
+// could not be.
+type configSavedMsg struct {
+	err error
+}
+
+// connectionCheckedMsg reports the test completion run on the Complete step.
+type connectionCheckedMsg struct {
+	result *provider.CheckResult
+	err    error // The check could not be run
+}
+
+// ConnectionChecker sends a test completion to a model of a saved provider.
+type ConnectionChecker func(ctx context.Context, providerID, model string) (*provider.CheckResult, error)
+
+// checkConnection loads the saved config and sends the model a minimal
+// prompt through it.
+func checkConnection(ctx context.Context, providerID, model string) (*provider.CheckResult, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	return provider.NewBuilder(cfg).CheckCompletion(ctx, providerID, model) //nolint:wrapcheck // Errors name the provider
+}
+
 // Wizard manages the setup wizard flow.
 type Wizard struct {
 	providerList           *ProviderList
@@ -50,6 +81,7 @@ type Wizard struct {
 	largeModel             *ModelList
 	smallModel             *ModelList
 	customProviderMethod   *CustomProviderMethod
+	customProviderImport   *CustomProviderImport
 	customProviderDefine   *CustomProviderDefine
 	customProviderModels   *CustomProviderModels
 	selectedProvider       *catwalk.Provider
@@ -57,12 +89,18 @@ type Wizard struct {
 	selectedLarge          *catwalk.Model
 	selectedSmall          *catwalk.Model
 	oauthToken             *oauth.Token
+	check                  ConnectionChecker
+	checkResult            *provider.CheckResult
+	checkErr               error
 	apiKey                 string
 	providers              []catwalk.Provider
 	height                 int
 	width                  int
 	step                   Step
 	authMethod             AuthMethod
+	saved                  bool // The choices are in the config file
+	checking               bool // The config is being saved or tested
+	finished               bool // The user moved on to the chat
 }
 
 // NewWizard creates a new wizard instance.
@@ -73,9 +111,15 @@ func NewWizard(providers []catwalk.Provider) *Wizard {
 		step:         StepProvider,
 		providers:    providersWithCustom,
 		providerList: NewProviderList(providersWithCustom),
+		check:        checkConnection,
 	}
 }
 
+// SetConnectionChecker replaces how the Complete step tests the connection.
+func (w *Wizard) SetConnectionChecker(check ConnectionChecker) {
+	w.check = check
+}
+
 // Init initializes the wizard.
 func (w *Wizard) Init() tea.Cmd {
 	return w.providerList.Init()
@@ -96,6 +140,8 @@ func (w *Wizard) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		return w.updateProvider(msg)
 	case StepCustomProviderMethod:
 		return w.updateCustomProviderMethod(msg)
+	case StepCustomProviderImport:
+		return w.updateCustomProviderImport(msg)
 	case StepCustomProviderDefine:
 		return w.updateCustomProviderDefine(msg)
 	case StepCustomProviderModels:
@@ -111,7 +157,7 @@ func (w *Wizard) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 	case StepSmallModel:
 		return w.updateSmallModel(msg)
 	case StepComplete:
-		return w, nil
+		return w.updateComplete(msg)
 	}
 
 	return w, nil
@@ -179,12 +225,10 @@ func (w *Wizard) updateCustomProviderMethod(msg tea.Msg) (util.Model, tea.Cmd) {
 			w.step = StepCustomProviderDefine
 			return w, w.customProviderDefine.Init()
 		case ProviderImportMethodURL, ProviderImportMethodFile:
-			// TODO: Implement URL and file import in future PR.
-			// For now, fall back to manual.
-			w.customProviderDefine = NewCustomProviderDefine()
-			w.customProviderDefine.SetWidth(w.width)
-			w.step = StepCustomProviderDefine
-			return w, w.customProviderDefine.Init()
+			w.customProviderImport = NewCustomProviderImport(m.Method, w.providerIDs())
+			w.customProviderImport.SetWidth(w.width)
+			w.step = StepCustomProviderImport
+			return w, w.customProviderImport.Init()
 		}
 	}
 
@@ -192,6 +236,54 @@ func (w *Wizard) updateCustomProviderMethod(msg tea.Msg) (util.Model, tea.Cmd) {
 	return w, cmd
 }
 
+func (w *Wizard) updateCustomProviderImport(msg tea.Msg) (util.Model, tea.Cmd) {
+	m, ok := msg.(CustomProvidersImportedMsg)
+	if !ok {
+		_, cmd := w.customProviderImport.Update(msg)
+		return w, cmd
+	}
+
+	manager := config.NewProviderLoader("").GetCustomProviderManager()
+	imported := make([]catwalk.Provider, 0, len(m.Providers))
+	for i := range m.Providers {
+		if err := manager.Add(m.Providers[i]); err != nil {
+			debug.Error("wizard", err, "importing provider "+m.Providers[i].ID)
+			continue
+		}
+		imported = append(imported, m.Providers[i].ToCatwalkProvider())
+	}
+	w.customProviderImport = nil
+	w.customProviderMethod = nil
+
+	// List the new providers above the custom option, and go straight on to
+	// the credentials when only one was imported.
+	last := len(w.providers) - 1
+	w.providers = slices.Concat(w.providers[:last], imported, w.providers[last:])
+	w.providerList = NewProviderList(w.providers)
+	w.providerList.SetSize(w.width, w.height)
+	w.step = StepProvider
+	if len(imported) == 0 {
+		return w, util.ReportError(fmt.Errorf("no providers were imported"))
+	}
+	w.providerList.SetCursorToProvider(imported[0].ID)
+	if len(imported) == 1 {
+		return w.updateProvider(ProviderSelectedMsg{Provider: imported[0]})
+	}
+	return w, nil
+}
+
+// providerIDs returns the IDs of the providers offered, which imported ones
+// must not reuse.
+func (w *Wizard) providerIDs() []string {
+	ids := make([]string, 0, len(w.providers))
+	for i := range w.providers {
+		if w.providers[i].ID != catwalk.InferenceProvider("custom") {
+			ids = append(ids, string(w.providers[i].ID))
+		}
+	}
+	return ids
+}
+
 func (w *Wizard) updateCustomProviderDefine(msg tea.Msg) (util.Model, tea.Cmd) {
 	if m, ok := msg.(CustomProviderDefinedMsg); ok {
 		w.selectedCustomProvider = &m.Provider
@@ -305,19 +397,88 @@ func (w *Wizard) updateSmallModel(msg tea.Msg) (util.Model, tea.Cmd) {
 	if m, ok := msg.(ModelSelectedMsg); ok {
 		w.selectedSmall = &m.Model
 		w.step = StepComplete
-		cmd := w.saveConfig()
-		return w, cmd
+		return w, w.save()
 	}
 
 	_, cmd := w.smallModel.Update(msg)
 	return w, cmd
 }
 
+// updateComplete tests the saved connection, then waits for the user to go on
+// to the chat, retry the test, or go back and fix the credentials.
+func (w *Wizard) updateComplete(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case configSavedMsg:
+		if msg.err != nil {
+			w.checking = false
+			w.checkErr = fmt.Errorf("saving config: %w", msg.err)
+			return w, nil
+		}
+		w.saved = true
+		return w, w.startCheck()
+	case connectionCheckedMsg:
+		w.checking = false
+		w.checkResult = msg.result
+		w.checkErr = msg.err
+	case tea.KeyMsg:
+		if w.checking || w.finished {
+			return w, nil
+		}
+		switch {
+		case !w.saved && msg.String() == "r":
+			return w, w.save()
+		case !w.saved:
+			return w, nil
+		case msg.String() == "r" && !w.connectionOK():
+			return w, w.startCheck()
+		}
+		w.finished = true
+		return w, w.complete()
+	}
+	return w, nil
+}
+
+// startCheck runs the test completion against the large model.
+func (w *Wizard) startCheck() tea.Cmd {
+	w.checking = true
+	w.checkResult = nil
+	w.checkErr = nil
+	check := w.check
+	providerID := string(w.selectedProvider.ID)
+	model := w.selectedLarge.ID
+	return func() tea.Msg {
+		result, err := check(context.Background(), providerID, model)
+		return connectionCheckedMsg{result: result, err: err}
+	}
+}
+
+// connectionOK reports whether the test completion succeeded.
+func (w *Wizard) connectionOK() bool {
+	return w.checkErr == nil && w.checkResult != nil && w.checkResult.OK()
+}
+
+// complete reports the wizard's result.
+func (w *Wizard) complete() tea.Cmd {
+	msg := CompleteMsg{
+		ProviderID:   string(w.selectedProvider.ID),
+		APIKey:       w.apiKey,
+		LargeModelID: w.selectedLarge.ID,
+		SmallModelID: w.selectedSmall.ID,
+	}
+	return func() tea.Msg { return msg }
+}
+
 func (w *Wizard) goBack() {
 	switch w.step {
 	case StepCustomProviderMethod:
 		w.step = StepProvider
 		w.customProviderMethod = nil
+	case StepCustomProviderImport:
+		if w.customProviderImport.Back() {
+			return
+		}
+		w.step = StepCustomProviderMethod
+		w.customProviderImport = nil
 	case StepCustomProviderDefine:
 		w.step = StepCustomProviderMethod
 		w.customProviderDefine = nil
@@ -356,11 +517,36 @@ func (w *Wizard) goBack() {
 		}
 	case StepSmallModel:
 		w.step = StepLargeModel
-	case StepProvider, StepComplete:
-		// Can't go back from first step or complete.
+	case StepComplete:
+		// Only a failed connection test sends the user back, to fix the
+		// credentials; the models chosen are kept.
+		if !w.saved || w.checking || w.finished || (w.checkResult == nil && w.checkErr == nil) || w.connectionOK() {
+			return
+		}
+		w.checkResult = nil
+		w.checkErr = nil
+		if w.oauthToken != nil {
+			w.step = StepOAuth
+		} else {
+			w.step = StepAPIKey
+			if w.apiKeyInput != nil {
+				w.apiKeyInput.Reset()
+			}
+		}
+	case StepProvider:
+		// Can't go back from the first step.
 	}
 }
 
+// save saves the choices; the connection test follows.
+func (w *Wizard) save() tea.Cmd {
+	w.saved = false
+	w.checking = true
+	w.checkResult = nil
+	w.checkErr = nil
+	return w.saveConfig()
+}
+
 func (w *Wizard) saveConfig() tea.Cmd {
 	return func() tea.Msg {
 		var err error
@@ -383,18 +569,7 @@ func (w *Wizard) saveConfig() tea.Cmd {
 			)
 		}
 
-		if err != nil {
-			return util.InfoMsg{
-				Type: util.InfoTypeError,
-				Msg:  fmt.Sprintf("Failed to save config: %v", err),
-			}
-		}
-		return CompleteMsg{
-			ProviderID:   string(w.selectedProvider.ID),
-			APIKey:       w.apiKey,
-			LargeModelID: w.selectedLarge.ID,
-			SmallModelID: w.selectedSmall.ID,
-		}
+		return configSavedMsg{err: err}
 	}
 }
 
@@ -411,6 +586,8 @@ func (w *Wizard) View() string {
 		content = w.providerList.View()
 	case StepCustomProviderMethod:
 		content = w.customProviderMethod.View()
+	case StepCustomProviderImport:
+		content = w.customProviderImport.View()
 	case StepCustomProviderDefine:
 		content = w.customProviderDefine.View()
 	case StepCustomProviderModels:
@@ -447,7 +624,7 @@ func (w *Wizard) View() string {
 	// Box style with border.
 	boxWidth := min(w.width-4, 70)
 	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(styles.Border()).
 		BorderForeground(t.Border).
 		Padding(1, 3).
 		Width(boxWidth).
@@ -512,8 +689,29 @@ func (w *Wizard) renderComplete() string {
 		t.S().Text.Render(fmt.Sprintf("Small Model: %s", w.selectedSmall.Name)),
 	)
 
-	configPath := config.GlobalConfigPath()
-	saved := t.S().Muted.Render(fmt.Sprintf("Configuration saved to: %s", configPath))
+	var saved string
+	if w.saved {
+		saved = t.S().Muted.Render(fmt.Sprintf("Configuration saved to: %s", config.GlobalConfigPath()))
+	}
+
+	var check, hint string
+	switch {
+	case w.checking && !w.saved:
+		check = t.S().Muted.Render("Saving...")
+	case w.checking:
+		check = t.S().Muted.Render(fmt.Sprintf("Testing %s...", w.selectedLarge.Name))
+	case !w.saved && w.checkErr != nil:
+		check = t.S().Error.Render("✗ " + w.checkErr.Error())
+		hint = t.S().Info.Render("Press r to retry")
+	case w.connectionOK():
+		check = t.S().Success.Render(fmt.Sprintf("✓ %s replied in %s", w.selectedLarge.Name, w.checkResult.Latency.Round(time.Millisecond)))
+		hint = t.S().Info.Render("Press any key to continue...")
+	case w.checkResult == nil && w.checkErr == nil:
+		// Still saving.
+	default:
+		check = t.S().Error.Render("✗ Test completion failed: " + w.checkFailure())
+		hint = t.S().Info.Render("Press r to retry, Esc to change the credentials, or any other key to continue anyway")
+	}
 
 	return lipgloss.JoinVertical(lipgloss.Left,
 		title,
@@ -522,10 +720,25 @@ func (w *Wizard) renderComplete() string {
 		"",
 		saved,
 		"",
-		t.S().Info.Render("Press any key to continue..."),
+		check,
+		"",
+		hint,
 	)
 }
 
+// checkFailure explains why the test completion failed.
+func (w *Wizard) checkFailure() string {
+	switch {
+	case w.checkErr != nil:
+		return w.checkErr.Error()
+	case !w.checkResult.AuthValid && w.checkResult.Err != nil:
+		return "the credentials or endpoint were rejected: " + w.checkResult.Err.Error()
+	case w.checkResult.Err != nil:
+		return w.checkResult.Err.Error()
+	}
+	return "the model did not answer"
+}
+
 // SetSize sets the wizard size.
 func (w *Wizard) SetSize(width, height int) {
 	w.width = width
@@ -537,6 +750,9 @@ func (w *Wizard) SetSize(width, height int) {
 	if w.customProviderMethod != nil {
 		w.customProviderMethod.SetWidth(width)
 	}
+	if w.customProviderImport != nil {
+		w.customProviderImport.SetWidth(width)
+	}
 	if w.customProviderDefine != nil {
 		w.customProviderDefine.SetWidth(width)
 	}
@@ -560,9 +776,10 @@ func (w *Wizard) SetSize(width, height int) {
 	}
 }
 
-// IsComplete returns true if the wizard is complete.
+// IsComplete returns true once the setup is saved and the user has moved on
+// from the connection test.
 func (w *Wizard) IsComplete() bool {
-	return w.step == StepComplete
+	return w.step == StepComplete && w.finished
 }
 
 // SelectedLargeModel returns the selected large model, or nil if not yet selected.
@@ -578,6 +795,9 @@ func (w *Wizard) Cursor() *tea.Cursor {
 	if w.step == StepOAuth && w.oauthFlow != nil {
 		return w.oauthFlow.Cursor()
 	}
+	if w.step == StepCustomProviderImport && w.customProviderImport != nil {
+		return w.customProviderImport.Cursor()
+	}
 	if w.step == StepCustomProviderDefine && w.customProviderDefine != nil {
 		return w.customProviderDefine.Cursor()
 	}
@@ -591,7 +811,7 @@ func (w *Wizard) oauthStepIndex() int {
 	switch w.step {
 	case StepProvider:
 		return 0
-	case StepCustomProviderMethod, StepCustomProviderDefine, StepCustomProviderModels:
+	case StepCustomProviderMethod, StepCustomProviderImport, StepCustomProviderDefine, StepCustomProviderModels:
 		return 0 // Custom provider steps are not shown in OAuth progress.
 	case StepAuthMethod:
 		return 1
@@ -611,7 +831,7 @@ func (w *Wizard) apiKeyStepIndex() int {
 	switch w.step {
 	case StepProvider:
 		return 0
-	case StepCustomProviderMethod, StepCustomProviderDefine, StepCustomProviderModels:
+	case StepCustomProviderMethod, StepCustomProviderImport, StepCustomProviderDefine, StepCustomProviderModels:
 		return 0 // Custom provider steps are not shown in API key progress.
 	case StepAuthMethod, StepAPIKey, StepOAuth:
 		return 1
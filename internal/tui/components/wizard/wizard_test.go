@@ -1,10 +1,16 @@
 package wizard
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
+	tea "charm.land/bubbletea/v2"
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
+
+	"github.com/guilhermegouw/cdd/internal/provider"
 )
 
 func TestNewWizard(t *testing.T) {
@@ -57,11 +63,115 @@ func TestWizard_IsComplete(t *testing.T) {
 	}
 
 	w.step = StepComplete
+	if w.IsComplete() {
+		t.Error("IsComplete() = true, want false until the user moves on from the connection test")
+	}
+
+	w.finished = true
 	if !w.IsComplete() {
-		t.Error("IsComplete() = false, want true when step is StepComplete")
+		t.Error("IsComplete() = false, want true once the user has moved on")
 	}
 }
 
+func TestWizard_ConnectionCheck(t *testing.T) {
+	providers := []catwalk.Provider{
+		{ID: "openai", Name: "OpenAI"},
+	}
+	newWizard := func(results ...*provider.CheckResult) (*Wizard, *[]string) {
+		w := NewWizard(providers)
+		w.step = StepComplete
+		w.selectedProvider = &providers[0]
+		w.selectedLarge = &catwalk.Model{ID: "gpt-large", Name: "Large Model"}
+		w.selectedSmall = &catwalk.Model{ID: "gpt-small", Name: "Small Model"}
+		w.apiKeyInput = NewAPIKeyInput("OpenAI")
+		var checked []string
+		w.SetConnectionChecker(func(_ context.Context, providerID, model string) (*provider.CheckResult, error) {
+			checked = append(checked, providerID+"/"+model)
+			result := results[0]
+			results = results[1:]
+			return result, nil
+		})
+		return w, &checked
+	}
+	// runCheck feeds the wizard a saved config and the check's result.
+	runCheck := func(t *testing.T, w *Wizard, cmd tea.Cmd) {
+		t.Helper()
+		if cmd == nil {
+			t.Fatal("expected a command to run the check")
+		}
+		w.Update(cmd())
+	}
+	key := func(s string) tea.KeyMsg {
+		return tea.KeyPressMsg{Text: s, Code: []rune(s)[0]}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		w, checked := newWizard(&provider.CheckResult{AuthValid: true, ModelAvailable: true, Latency: 1200 * time.Millisecond})
+		_, cmd := w.Update(configSavedMsg{})
+		if !w.checking || w.IsComplete() {
+			t.Fatal("the wizard should be testing the connection")
+		}
+		w.Update(key("x")) // Ignored while the test runs
+		runCheck(t, w, cmd)
+		if len(*checked) != 1 || (*checked)[0] != "openai/gpt-large" {
+			t.Errorf("checked %v, want the large model", *checked)
+		}
+		if view := w.renderComplete(); !strings.Contains(view, "Large Model replied in 1.2s") {
+			t.Errorf("renderComplete() = %q, want the success", view)
+		}
+
+		_, cmd = w.Update(key("x"))
+		if !w.IsComplete() || cmd == nil {
+			t.Fatal("a key after a passing test should finish the wizard")
+		}
+		if msg, ok := cmd().(CompleteMsg); !ok || msg.LargeModelID != "gpt-large" {
+			t.Errorf("finish sent %#v, want CompleteMsg", msg)
+		}
+	})
+
+	t.Run("failure, retry and go back", func(t *testing.T) {
+		failed := &provider.CheckResult{Err: errors.New("401 Unauthorized")}
+		w, checked := newWizard(failed, failed)
+		_, cmd := w.Update(configSavedMsg{})
+		runCheck(t, w, cmd)
+		if view := w.renderComplete(); !strings.Contains(view, "credentials or endpoint were rejected") {
+			t.Errorf("renderComplete() = %q, want the failure explained", view)
+		}
+
+		_, cmd = w.Update(key("r"))
+		runCheck(t, w, cmd)
+		if len(*checked) != 2 || w.IsComplete() {
+			t.Errorf("r should test again, checked %v", *checked)
+		}
+
+		w.goBack()
+		if w.step != StepAPIKey {
+			t.Errorf("step = %d, want the API key step after a failed test", w.step)
+		}
+	})
+
+	t.Run("continue anyway", func(t *testing.T) {
+		w, _ := newWizard(&provider.CheckResult{Err: errors.New("connection refused")})
+		_, cmd := w.Update(configSavedMsg{})
+		runCheck(t, w, cmd)
+		if _, cmd = w.Update(key("c")); !w.IsComplete() || cmd == nil {
+			t.Error("any other key should go on to the chat despite the failure")
+		}
+	})
+
+	t.Run("save failure", func(t *testing.T) {
+		w, checked := newWizard()
+		w.checking = true
+		w.Update(configSavedMsg{err: errors.New("read-only file system")})
+		if w.Update(key("x")); w.IsComplete() || len(*checked) != 0 {
+			t.Error("the wizard should not go on or test an unsaved config")
+		}
+		if view := w.renderComplete(); !strings.Contains(view, "saving config: read-only file system") {
+			t.Errorf("renderComplete() = %q, want the save error", view)
+		}
+	})
+}
+
 func TestWizard_SetSize(t *testing.T) {
 	providers := []catwalk.Provider{
 		{ID: "anthropic", Name: "Anthropic"},
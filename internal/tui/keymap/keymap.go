@@ -0,0 +1,255 @@
+// Package keymap defines the TUI's key bindings. Defaults can be overridden
+// per action with options.keybindings in cdd.json.
+package keymap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+)
+
+// Action names a bindable action. Action names are the keys of options.keybindings.
+type Action string
+
+// Bindable actions.
+const (
+	Quit   Action = "quit"
+	Help   Action = "help"
+	Send   Action = "send"
+	Cancel Action = "cancel"
+
+	Newline       Action = "newline"
+	HistoryPrev   Action = "history_prev"
+	HistoryNext   Action = "history_next"
+	HistorySearch Action = "history_search"
+	PickMessage   Action = "pick_message"
+	ToggleTools   Action = "toggle_tools"
+	SwitchModel   Action = "switch_model"
+	CopyMessage   Action = "copy_message"
+	CopyCode      Action = "copy_code"
+	FilePane      Action = "file_pane"
+	PrevMessage   Action = "prev_message"
+	NextMessage   Action = "next_message"
+	JumpUnread    Action = "jump_unread"
+
+	NewTab   Action = "new_tab"
+	CloseTab Action = "close_tab"
+	NextTab  Action = "next_tab"
+	PrevTab  Action = "prev_tab"
+
+	Up     Action = "up"
+	Down   Action = "down"
+	Top    Action = "top"
+	Bottom Action = "bottom"
+	Select Action = "select"
+	Back   Action = "back"
+	Search Action = "search"
+
+	NewSession    Action = "new_session"
+	RenameSession Action = "rename_session"
+	DeleteSession Action = "delete_session"
+	ExportSession Action = "export_session"
+	Checkpoints   Action = "checkpoints"
+	AllProjects   Action = "all_projects"
+)
+
+// definition is the default binding of an action.
+type definition struct {
+	action Action
+	group  string
+	keys   []string
+	help   string
+}
+
+// defaults lists every action in display order.
+var defaults = []definition{
+	{Quit, "Global", []string{"ctrl+c"}, "quit"},
+	{Help, "Global", []string{"?"}, "show key bindings (when the input is empty)"},
+
+	{Send, "Chat", []string{"enter"}, "send message"},
+	{Newline, "Chat", []string{"ctrl+j"}, "insert a new line"},
+	{Cancel, "Chat", []string{"esc"}, "stop the running response"},
+	{HistoryPrev, "Chat", []string{"up"}, "previous prompt (when the input is empty)"},
+	{HistoryNext, "Chat", []string{"down"}, "next prompt"},
+	{HistorySearch, "Chat", []string{"ctrl+r"}, "search past prompts"},
+	{PickMessage, "Chat", []string{"ctrl+up"}, "pick an earlier prompt to edit or retry, or a tool result to expand"},
+	{ToggleTools, "Chat", []string{"ctrl+o"}, "expand or collapse all tool results"},
+	{SwitchModel, "Chat", []string{"ctrl+m"}, "quick-switch the model"},
+	{CopyMessage, "Chat", []string{"y"}, "copy the last reply (while not typing)"},
+	{CopyCode, "Chat", []string{"Y"}, "copy the last reply's code blocks in turn (while not typing)"},
+	{FilePane, "Chat", []string{"ctrl+g"}, "show or hide the file the agent last read or changed"},
+	{PrevMessage, "Chat", []string{"alt+up"}, "scroll to the previous message"},
+	{NextMessage, "Chat", []string{"alt+down"}, "scroll to the next message"},
+	{JumpUnread, "Chat", []string{"alt+end", "ctrl+end"}, "scroll to the first output that arrived while scrolled up"},
+
+	{NewTab, "Tabs", []string{"alt+t"}, "open a new session in a tab"},
+	{CloseTab, "Tabs", []string{"alt+w"}, "close the tab, stopping its reply"},
+	{NextTab, "Tabs", []string{"ctrl+tab", "ctrl+pgdown"}, "next tab"},
+	{PrevTab, "Tabs", []string{"ctrl+shift+tab", "ctrl+pgup"}, "previous tab"},
+
+	{Up, "Lists", []string{"up", "k"}, "move up"},
+	{Down, "Lists", []string{"down", "j"}, "move down"},
+	{Top, "Lists", []string{"home", "g"}, "go to first item"},
+	{Bottom, "Lists", []string{"end", "G"}, "go to last item"},
+	{Select, "Lists", []string{"enter"}, "open selected item"},
+	{Back, "Lists", []string{"esc"}, "close or go back"},
+	{Search, "Lists", []string{"/"}, "search"},
+
+	{NewSession, "Sessions", []string{"n"}, "new session"},
+	{RenameSession, "Sessions", []string{"r"}, "rename session"},
+	{DeleteSession, "Sessions", []string{"d"}, "delete session"},
+	{ExportSession, "Sessions", []string{"e"}, "export session"},
+	{Checkpoints, "Sessions", []string{"c"}, "restore files to a checkpoint"},
+	{AllProjects, "Sessions", []string{"a"}, "show sessions of all projects"},
+}
+
+// Entry is a single action in a help listing.
+type Entry struct {
+	Action Action
+	Keys   []string
+	Help   string
+}
+
+// Group is a titled set of entries, such as the chat bindings.
+type Group struct {
+	Name    string
+	Entries []Entry
+}
+
+// KeyMap maps actions to key bindings.
+type KeyMap struct {
+	bindings map[Action]key.Binding
+}
+
+// Default returns the built-in key bindings.
+func Default() *KeyMap {
+	km := &KeyMap{bindings: make(map[Action]key.Binding, len(defaults))}
+	for _, d := range defaults {
+		km.bindings[d.action] = newBinding(d.keys, d.help)
+	}
+	return km
+}
+
+// New returns the default bindings with overrides applied. Each override
+// replaces all keys of an action; an empty list unbinds it. Unknown actions
+// are reported as an error listing the valid names.
+func New(overrides map[string][]string) (*KeyMap, error) {
+	km := Default()
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var unknown []string
+	for _, name := range names {
+		d, ok := lookup(Action(name))
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		keys := make([]string, 0, len(overrides[name]))
+		for _, k := range overrides[name] {
+			if k = strings.TrimSpace(k); k != "" {
+				keys = append(keys, k)
+			}
+		}
+		km.bindings[d.action] = newBinding(keys, d.help)
+	}
+
+	if len(unknown) > 0 {
+		return km, fmt.Errorf("unknown keybinding action(s) %s; valid actions: %s",
+			strings.Join(unknown, ", "), strings.Join(actionNames(), ", "))
+	}
+	return km, nil
+}
+
+// newBinding creates a binding whose help shows the first key.
+func newBinding(keys []string, help string) key.Binding {
+	if len(keys) == 0 {
+		return key.NewBinding(key.WithDisabled(), key.WithHelp("", help))
+	}
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(keys[0], help))
+}
+
+// lookup returns the default definition of an action.
+func lookup(a Action) (definition, bool) {
+	for _, d := range defaults {
+		if d.action == a {
+			return d, true
+		}
+	}
+	return definition{}, false
+}
+
+// actionNames returns every action name in display order.
+func actionNames() []string {
+	names := make([]string, len(defaults))
+	for i, d := range defaults {
+		names[i] = string(d.action)
+	}
+	return names
+}
+
+// Binding returns the binding of an action.
+func (k *KeyMap) Binding(a Action) key.Binding {
+	return k.bindings[a]
+}
+
+// Matches reports whether msg triggers any of the actions.
+func (k *KeyMap) Matches(msg tea.KeyMsg, actions ...Action) bool {
+	for _, a := range actions {
+		if key.Matches(msg, k.bindings[a]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Key returns the first key bound to an action, for hints, or "" when it is unbound.
+func (k *KeyMap) Key(a Action) string {
+	b := k.bindings[a]
+	if !b.Enabled() {
+		return ""
+	}
+	return b.Help().Key
+}
+
+// Groups returns the bindings in display order, grouped for help listings.
+func (k *KeyMap) Groups() []Group {
+	var groups []Group
+	for _, d := range defaults {
+		if len(groups) == 0 || groups[len(groups)-1].Name != d.group {
+			groups = append(groups, Group{Name: d.group})
+		}
+		g := &groups[len(groups)-1]
+		g.Entries = append(g.Entries, Entry{
+			Action: d.action,
+			Keys:   k.bindings[d.action].Keys(),
+			Help:   d.help,
+		})
+	}
+	return groups
+}
+
+// current holds the key map used by the TUI components.
+var current atomic.Pointer[KeyMap]
+
+func init() {
+	current.Store(Default())
+}
+
+// Current returns the active key map.
+func Current() *KeyMap {
+	return current.Load()
+}
+
+// SetCurrent replaces the active key map. Call it before the TUI starts.
+func SetCurrent(k *KeyMap) {
+	current.Store(k)
+}
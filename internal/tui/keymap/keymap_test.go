@@ -0,0 +1,137 @@
+package keymap
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func press(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyPressMsg{Code: tea.KeyEnter}
+	case "esc":
+		return tea.KeyPressMsg{Code: tea.KeyEscape}
+	case "ctrl+c":
+		return tea.KeyPressMsg{Code: 'c', Mod: tea.ModCtrl}
+	case "f1":
+		return tea.KeyPressMsg{Code: tea.KeyF1}
+	case "ctrl+tab":
+		return tea.KeyPressMsg{Code: tea.KeyTab, Mod: tea.ModCtrl}
+	}
+	r := []rune(s)[0]
+	return tea.KeyPressMsg{Code: r, Text: s}
+}
+
+func TestDefault(t *testing.T) {
+	km := Default()
+
+	tests := []struct {
+		key    string
+		action Action
+	}{
+		{"ctrl+c", Quit},
+		{"?", Help},
+		{"enter", Send},
+		{"esc", Cancel},
+		{"k", Up},
+		{"j", Down},
+		{"G", Bottom},
+		{"/", Search},
+		{"n", NewSession},
+		{"alt+up", PrevMessage},
+		{"ctrl+end", JumpUnread},
+		{"ctrl+tab", NextTab},
+	}
+	for _, tt := range tests {
+		if !km.Matches(press(tt.key), tt.action) {
+			t.Errorf("%q should trigger %s", tt.key, tt.action)
+		}
+	}
+	if km.Matches(press("x"), Up, Down, Quit) {
+		t.Error("x should not match any action")
+	}
+	if got := km.Key(Newline); got != "ctrl+j" {
+		t.Errorf("Key(Newline) = %q, want %q", got, "ctrl+j")
+	}
+}
+
+func TestNew_Overrides(t *testing.T) {
+	km, err := New(map[string][]string{
+		"help":   {"f1", " "},
+		"search": {},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if !km.Matches(press("f1"), Help) {
+		t.Error("f1 should trigger help")
+	}
+	if km.Matches(press("?"), Help) {
+		t.Error("override should replace the default key")
+	}
+	if got := km.Binding(Help).Keys(); len(got) != 1 {
+		t.Errorf("blank keys should be dropped, got %q", got)
+	}
+	if km.Matches(press("/"), Search) {
+		t.Error("empty list should unbind search")
+	}
+	if got := km.Key(Search); got != "" {
+		t.Errorf("Key of unbound action = %q, want empty", got)
+	}
+	if !km.Matches(press("enter"), Send) {
+		t.Error("actions without overrides keep their defaults")
+	}
+}
+
+func TestNew_UnknownAction(t *testing.T) {
+	km, err := New(map[string][]string{
+		"sned": {"enter"},
+		"quit": {"ctrl+q"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "sned") || !strings.Contains(err.Error(), "send") {
+		t.Fatalf("expected error naming the unknown and valid actions, got %v", err)
+	}
+	if km == nil || km.Key(Quit) != "ctrl+q" {
+		t.Error("valid overrides should still apply")
+	}
+}
+
+func TestGroups(t *testing.T) {
+	km, err := New(map[string][]string{"delete_session": {}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	groups := km.Groups()
+	var names []string
+	total := 0
+	for _, g := range groups {
+		names = append(names, g.Name)
+		total += len(g.Entries)
+		for _, e := range g.Entries {
+			if e.Action == DeleteSession && len(e.Keys) != 0 {
+				t.Errorf("unbound action listed with keys %v", e.Keys)
+			}
+		}
+	}
+	if got := strings.Join(names, ","); got != "Global,Chat,Tabs,Lists,Sessions" {
+		t.Errorf("groups = %s", got)
+	}
+	if total != len(defaults) {
+		t.Errorf("groups list %d actions, want %d", total, len(defaults))
+	}
+}
+
+func TestSetCurrent(t *testing.T) {
+	prev := Current()
+	t.Cleanup(func() { SetCurrent(prev) })
+
+	km, _ := New(map[string][]string{"quit": {"ctrl+q"}}) //nolint:errcheck // Overrides are valid
+	SetCurrent(km)
+	if Current().Key(Quit) != "ctrl+q" {
+		t.Error("Current should return the key map set last")
+	}
+}
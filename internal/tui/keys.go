@@ -1,37 +0,0 @@
-package tui
-
-import (
-	"charm.land/bubbles/v2/key"
-)
-
-// KeyMap defines the key bindings for the TUI.
-type KeyMap struct {
-	Quit key.Binding
-	Help key.Binding
-}
-
-// DefaultKeyMap returns the default key bindings.
-func DefaultKeyMap() KeyMap {
-	return KeyMap{
-		Quit: key.NewBinding(
-			key.WithKeys("q", "ctrl+c"),
-			key.WithHelp("q", "quit"),
-		),
-		Help: key.NewBinding(
-			key.WithKeys("?"),
-			key.WithHelp("?", "help"),
-		),
-	}
-}
-
-// ShortHelp returns the short help text.
-func (k KeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Quit, k.Help}
-}
-
-// FullHelp returns the full help text.
-func (k KeyMap) FullHelp() [][]key.Binding {
-	return [][]key.Binding{
-		{k.Quit, k.Help},
-	}
-}
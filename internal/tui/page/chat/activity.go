@@ -8,6 +8,7 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 
 	"github.com/guilhermegouw/cdd/internal/tui/styles"
 )
@@ -18,6 +19,12 @@ var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "
 // spinnerInterval is the time between spinner frame updates.
 const spinnerInterval = 100 * time.Millisecond
 
+// maxOutputLines is how many lines of live output are shown under a running tool.
+const maxOutputLines = 3
+
+// progressBarWidth is the number of cells in a running tool's progress bar.
+const progressBarWidth = 10
+
 // ToolStatus represents the status of a tool operation.
 type ToolStatus int
 
@@ -33,14 +40,21 @@ const (
 type ToolActivity struct {
 	Name    string
 	Summary string
+	Output  []string // Most recent lines of live output while running
+	Step    string   // What the tool reports it is doing
+	Percent float64  // Fraction done, 0 when the tool cannot tell
 	Status  ToolStatus
 }
 
-// SpinnerTickMsg is sent to advance the spinner animation.
-type SpinnerTickMsg struct{}
+// SpinnerTickMsg is sent to advance the spinner animation of the panel
+// showing a session.
+type SpinnerTickMsg struct {
+	SessionID string
+}
 
 // ActivityPanel shows real-time activity during AI interactions.
 type ActivityPanel struct { //nolint:govet // fieldalignment: preserving logical field order
+	session  string         // Session whose activity is shown
 	spinner  int            // Current spinner frame index
 	thinking bool           // Whether we're in thinking state
 	tools    []ToolActivity // Active/recent tool calls
@@ -55,15 +69,22 @@ func NewActivityPanel() *ActivityPanel {
 	}
 }
 
-// SetThinking sets the thinking state and starts/stops the spinner.
+// SetThinking sets the thinking state and starts/stops the spinner. The
+// spinner stays still in accessible mode.
 func (a *ActivityPanel) SetThinking(thinking bool) tea.Cmd {
 	a.thinking = thinking
-	if thinking {
+	if thinking && !styles.Accessible() {
 		return a.tickSpinner()
 	}
 	return nil
 }
 
+// SetSession sets the session the panel shows. Its spinner ignores ticks
+// scheduled for other sessions, so each tab animates only its own.
+func (a *ActivityPanel) SetSession(sessionID string) {
+	a.session = sessionID
+}
+
 // AddTool adds a tool operation to the activity list.
 func (a *ActivityPanel) AddTool(name, input string) {
 	summary := toolSummary(name, input)
@@ -81,12 +102,41 @@ func (a *ActivityPanel) AddTool(name, input string) {
 	}
 }
 
+// AppendOutput adds streamed output to the most recent running tool with this name.
+func (a *ActivityPanel) AppendOutput(name, chunk string) {
+	for i := len(a.tools) - 1; i >= 0; i-- {
+		if a.tools[i].Name != name || a.tools[i].Status != ToolStatusRunning {
+			continue
+		}
+		lines := strings.Split(strings.TrimRight(ansi.Strip(chunk), "\n"), "\n")
+		output := append(a.tools[i].Output, lines...)
+		if len(output) > maxOutputLines {
+			output = output[len(output)-maxOutputLines:]
+		}
+		a.tools[i].Output = output
+		return
+	}
+}
+
+// SetProgress records the progress reported by the most recent running tool
+// with this name.
+func (a *ActivityPanel) SetProgress(name, step string, percent float64) {
+	for i := len(a.tools) - 1; i >= 0; i-- {
+		if a.tools[i].Name == name && a.tools[i].Status == ToolStatusRunning {
+			a.tools[i].Step = step
+			a.tools[i].Percent = min(percent, 1)
+			return
+		}
+	}
+}
+
 // MarkToolDone marks a tool as completed.
 func (a *ActivityPanel) MarkToolDone(name string) {
 	// Mark the most recent tool with this name as done
 	for i := len(a.tools) - 1; i >= 0; i-- {
 		if a.tools[i].Name == name && a.tools[i].Status == ToolStatusRunning {
 			a.tools[i].Status = ToolStatusDone
+			a.tools[i].Output = nil
 			break
 		}
 	}
@@ -98,6 +148,7 @@ func (a *ActivityPanel) MarkToolError(name string) {
 	for i := len(a.tools) - 1; i >= 0; i-- {
 		if a.tools[i].Name == name && a.tools[i].Status == ToolStatusRunning {
 			a.tools[i].Status = ToolStatusError
+			a.tools[i].Output = nil
 			break
 		}
 	}
@@ -125,7 +176,9 @@ func (a *ActivityPanel) Height() int {
 	if a.thinking {
 		height++ // Thinking line
 	}
-	height += len(a.tools) // Tool lines
+	for _, tool := range a.tools {
+		height += 1 + len(tool.Output) // Tool line plus live output
+	}
 	return height
 }
 
@@ -136,7 +189,7 @@ func (a *ActivityPanel) IsActive() bool {
 
 // Update handles messages for the activity panel.
 func (a *ActivityPanel) Update(msg tea.Msg) (*ActivityPanel, tea.Cmd) {
-	if _, ok := msg.(SpinnerTickMsg); ok && a.thinking {
+	if tick, ok := msg.(SpinnerTickMsg); ok && a.thinking && tick.SessionID == a.session {
 		a.spinner = (a.spinner + 1) % len(spinnerFrames)
 		cmd := a.tickSpinner()
 		return a, cmd
@@ -146,8 +199,9 @@ func (a *ActivityPanel) Update(msg tea.Msg) (*ActivityPanel, tea.Cmd) {
 
 // tickSpinner returns a command that sends a SpinnerTickMsg after the interval.
 func (a *ActivityPanel) tickSpinner() tea.Cmd {
+	session := a.session
 	return tea.Tick(spinnerInterval, func(time.Time) tea.Msg {
-		return SpinnerTickMsg{}
+		return SpinnerTickMsg{SessionID: session}
 	})
 }
 
@@ -157,14 +211,27 @@ func (a *ActivityPanel) View() string {
 		return ""
 	}
 
+	var lines []string
+	if styles.Accessible() {
+		lines = a.plainLines()
+	} else {
+		lines = a.treeLines()
+	}
+
+	content := strings.Join(lines, "\n")
+
+	// Apply padding
+	return lipgloss.NewStyle().
+		Padding(0, 1).
+		Width(a.width).
+		Render(content)
+}
+
+// treeLines renders the thinking line and the tools as a tree.
+func (a *ActivityPanel) treeLines() []string {
 	t := styles.CurrentTheme()
 
-	// Pre-allocate lines slice
-	lineCount := len(a.tools)
-	if a.thinking {
-		lineCount++
-	}
-	lines := make([]string, 0, lineCount)
+	lines := make([]string, 0, a.Height())
 
 	// Thinking line with spinner
 	if a.thinking {
@@ -176,8 +243,9 @@ func (a *ActivityPanel) View() string {
 
 	// Tool lines
 	for i, tool := range a.tools {
+		isLast := i == len(a.tools)-1
 		var prefix string
-		if i == len(a.tools)-1 {
+		if isLast {
 			prefix = "   └─ "
 		} else {
 			prefix = "   ├─ "
@@ -190,17 +258,90 @@ func (a *ActivityPanel) View() string {
 			statusStyle.Render(tool.Name) +
 			t.S().Muted.Render(": ") +
 			t.S().Text.Render(a.truncateSummary(tool.Summary))
+		if tool.Status == ToolStatusRunning {
+			// Cut the progress short rather than wrap the line.
+			toolLine = ansi.Truncate(toolLine+a.renderProgress(t, tool), max(a.width-2, 10), "…") //nolint:mnd // Padding
+		} else if !styles.HasColor() {
+			// The state is otherwise told by color alone.
+			toolLine += t.S().Muted.Render(" (" + toolStatusText(tool.Status) + ")")
+		}
 
 		lines = append(lines, toolLine)
+
+		// Live output lines, indented under the tool
+		outputPrefix := "   │    "
+		if isLast {
+			outputPrefix = "        "
+		}
+		for _, out := range tool.Output {
+			lines = append(lines, t.S().Muted.Render(outputPrefix+a.truncateOutputLine(out)))
+		}
 	}
+	return lines
+}
 
-	content := strings.Join(lines, "\n")
+// plainLines renders the panel for a screen reader: the state of each tool
+// in words, without the spinner, tree lines or progress bar.
+func (a *ActivityPanel) plainLines() []string {
+	t := styles.CurrentTheme()
+	var lines []string
+	if a.thinking {
+		lines = append(lines, t.S().Info.Render("Working"))
+	}
+	for _, tool := range a.tools {
+		line := tool.Name + ": " + a.truncateSummary(tool.Summary) + ", " + toolStatusText(tool.Status)
+		if tool.Status == ToolStatusRunning {
+			if tool.Step != "" {
+				line += ", " + tool.Step
+			}
+			if tool.Percent > 0 {
+				line += fmt.Sprintf(", %.0f%% done", tool.Percent*100) //nolint:mnd // Percent
+			}
+			line = ansi.Truncate(line, max(a.width-2, 10), "…") //nolint:mnd // Padding
+		}
+		lines = append(lines, "  "+a.statusStyle(t, tool.Status).Render(line))
+		for _, out := range tool.Output {
+			lines = append(lines, t.S().Muted.Render("    "+a.truncateOutputLine(out)))
+		}
+	}
+	return lines
+}
 
-	// Apply padding
-	return lipgloss.NewStyle().
-		Padding(0, 1).
-		Width(a.width).
-		Render(content)
+// toolStatusText names a tool status in words.
+func toolStatusText(status ToolStatus) string {
+	switch status {
+	case ToolStatusPending:
+		return "waiting"
+	case ToolStatusRunning:
+		return "running"
+	case ToolStatusDone:
+		return "done"
+	case ToolStatusError:
+		return "failed"
+	}
+	return ""
+}
+
+// renderProgress renders a running tool's progress after its summary: a bar
+// when the tool knows how far along it is, or the spinner, with its step.
+func (a *ActivityPanel) renderProgress(t *styles.Theme, tool ToolActivity) string {
+	if tool.Step == "" && tool.Percent <= 0 {
+		return ""
+	}
+	var indicator string
+	if tool.Percent > 0 {
+		filled := int(tool.Percent * progressBarWidth)
+		indicator = t.S().Info.Render(strings.Repeat("█", filled)) +
+			t.S().Muted.Render(strings.Repeat("░", progressBarWidth-filled)) +
+			t.S().Text.Render(fmt.Sprintf(" %3.0f%%", tool.Percent*100)) //nolint:mnd // Percent
+	} else {
+		indicator = t.S().Info.Render(spinnerFrames[a.spinner])
+	}
+	out := "  " + indicator
+	if tool.Step != "" {
+		out += t.S().Muted.Render(" " + tool.Step)
+	}
+	return out
 }
 
 // statusStyle returns the appropriate style for a tool status.
@@ -233,6 +374,15 @@ func (a *ActivityPanel) truncateSummary(summary string) string {
 	return summary[:maxLen-3] + "..."
 }
 
+// truncateOutputLine truncates a line of live output to fit the panel width.
+func (a *ActivityPanel) truncateOutputLine(line string) string {
+	maxLen := a.width - 12
+	if maxLen < 10 {
+		maxLen = 10
+	}
+	return truncate(strings.TrimRight(line, "\r"), maxLen)
+}
+
 // toolSummary extracts a human-readable summary from tool input JSON.
 func toolSummary(name, input string) string {
 	// Parse the input JSON
@@ -243,7 +393,9 @@ func toolSummary(name, input string) string {
 	}
 
 	switch name {
-	case "read", "write", "edit":
+	case "read_file":
+		return summarizeReadTool(params)
+	case "read", "write", "write_file", "edit", "edit_file", "diagnostics":
 		return summarizeFileTool(params)
 	case "grep":
 		return summarizeGrepTool(params)
@@ -251,6 +403,8 @@ func toolSummary(name, input string) string {
 		return summarizeGlobTool(params)
 	case "bash":
 		return summarizeBashTool(params)
+	case "task":
+		return summarizeTaskTool(params)
 	default:
 		return summarizeFallback(params)
 	}
@@ -263,6 +417,22 @@ func summarizeFileTool(params map[string]any) string {
 	return summarizeFallback(params)
 }
 
+// summarizeReadTool adds the requested line range to the file name, e.g. "main.go:101-150".
+func summarizeReadTool(params map[string]any) string {
+	summary := summarizeFileTool(params)
+	offset, _ := params["offset"].(float64) //nolint:errcheck // Missing offset reads from the start
+	limit, _ := params["limit"].(float64)   //nolint:errcheck // Missing limit uses the default
+	switch {
+	case offset > 0 && limit > 0:
+		return fmt.Sprintf("%s:%d-%d", summary, int(offset)+1, int(offset+limit))
+	case offset > 0:
+		return fmt.Sprintf("%s:%d-", summary, int(offset)+1)
+	case limit > 0:
+		return fmt.Sprintf("%s:1-%d", summary, int(limit))
+	}
+	return summary
+}
+
 func summarizeGrepTool(params map[string]any) string {
 	var parts []string
 	if pattern, ok := params["pattern"].(string); ok {
@@ -300,6 +470,13 @@ func summarizeBashTool(params map[string]any) string {
 	return summarizeFallback(params)
 }
 
+func summarizeTaskTool(params map[string]any) string {
+	if desc, ok := params["description"].(string); ok && desc != "" {
+		return truncate(desc, 50)
+	}
+	return summarizeFallback(params)
+}
+
 func summarizeFallback(params map[string]any) string {
 	// Return first parameter value found
 	for _, v := range params {
@@ -3,6 +3,11 @@ package chat
 import (
 	"strings"
 	"testing"
+
+	"github.com/charmbracelet/colorprofile"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/guilhermegouw/cdd/internal/tui/styles"
 )
 
 func TestActivityPanel_NewActivityPanel(t *testing.T) {
@@ -106,6 +111,24 @@ func TestActivityPanel_SpinnerAnimation(t *testing.T) {
 	}
 }
 
+func TestActivityPanel_SpinnerSession(t *testing.T) {
+	p := NewActivityPanel()
+	p.SetSession("s1")
+	cmd := p.SetThinking(true)
+	if tick, ok := cmd().(SpinnerTickMsg); !ok || tick.SessionID != "s1" {
+		t.Fatalf("tick = %#v, want one for s1", tick)
+	}
+
+	p.Update(SpinnerTickMsg{SessionID: "s2"})
+	if p.spinner != 0 {
+		t.Error("a tick for another session should not advance the spinner")
+	}
+	p.Update(SpinnerTickMsg{SessionID: "s1"})
+	if p.spinner != 1 {
+		t.Error("a tick for the panel's session should advance the spinner")
+	}
+}
+
 func TestActivityPanel_ToolManagement(t *testing.T) {
 	p := NewActivityPanel()
 	p.SetWidth(80)
@@ -180,6 +203,50 @@ func TestActivityPanel_View(t *testing.T) {
 	}
 }
 
+func TestActivityPanel_Accessible(t *testing.T) {
+	styles.SetAccessible(true)
+	t.Cleanup(func() { styles.SetAccessible(false) })
+
+	p := NewActivityPanel()
+	p.SetWidth(80)
+	if p.SetThinking(true) != nil {
+		t.Error("the spinner should not tick in accessible mode")
+	}
+	p.AddTool("bash", `{"command": "go test ./..."}`)
+	p.SetProgress("bash", "compiling", 0.4)
+	p.AddTool("read", `{"file_path": "/path/to/file.go"}`)
+	p.MarkToolDone("read")
+
+	view := ansi.Strip(p.View())
+	for _, want := range []string{"Working", "bash: go test ./..., running, compiling, 40% done", "read: file.go, done"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view should contain %q:\n%s", want, view)
+		}
+	}
+	if containsSpinnerFrame(view) || strings.ContainsAny(view, "├└│█░") {
+		t.Errorf("view should have no spinner, tree lines or progress bar:\n%s", view)
+	}
+}
+
+func TestActivityPanel_NoColor(t *testing.T) {
+	styles.SetColorProfile(colorprofile.ASCII)
+	t.Cleanup(func() { styles.SetColorProfile(colorprofile.TrueColor) })
+
+	p := NewActivityPanel()
+	p.SetWidth(80)
+	p.AddTool("read", `{"file_path": "/path/to/file.go"}`)
+	p.MarkToolDone("read")
+	p.AddTool("bash", `{"command": "go test ./..."}`)
+	p.MarkToolError("bash")
+
+	view := ansi.Strip(p.View())
+	for _, want := range []string{"read: file.go (done)", "bash: go test ./... (failed)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view should contain %q:\n%s", want, view)
+		}
+	}
+}
+
 func TestActivityPanel_Clear(t *testing.T) {
 	p := NewActivityPanel()
 
@@ -219,6 +286,24 @@ func TestToolSummary(t *testing.T) {
 			input:    `{"file_path": "/home/user/new_file.go", "content": "package main"}`,
 			expected: "new_file.go",
 		},
+		{
+			testName: "read_file with range",
+			toolName: "read_file",
+			input:    `{"file_path": "/home/user/code/main.go", "offset": 100, "limit": 50}`,
+			expected: "main.go:101-150",
+		},
+		{
+			testName: "read_file without range",
+			toolName: "read_file",
+			input:    `{"file_path": "/home/user/code/main.go"}`,
+			expected: "main.go",
+		},
+		{
+			testName: "write_file",
+			toolName: "write_file",
+			input:    `{"file_path": "/home/user/new_file.go", "content": "package main"}`,
+			expected: "new_file.go",
+		},
 		{
 			testName: "edit file",
 			toolName: "edit",
@@ -261,6 +346,12 @@ func TestToolSummary(t *testing.T) {
 			input:    `{"command": "this is a very long command that should be truncated because it exceeds the maximum length"}`,
 			expected: "this is a very long command that should be trun...",
 		},
+		{
+			testName: "task description",
+			toolName: "task",
+			input:    `{"description": "find config loaders", "prompt": "Where is the config loaded?"}`,
+			expected: "find config loaders",
+		},
 	}
 
 	for _, tt := range tests {
@@ -336,3 +427,75 @@ func containsSpinnerFrame(s string) bool {
 	}
 	return false
 }
+
+func TestActivityPanel_AppendOutput(t *testing.T) {
+	p := NewActivityPanel()
+	p.SetWidth(80)
+	p.AddTool("bash", `{"command": "go test"}`)
+
+	p.AppendOutput("bash", "line 1\nline 2\n")
+	p.AppendOutput("bash", "line 3\nline 4\n")
+
+	want := []string{"line 2", "line 3", "line 4"}
+	got := p.tools[0].Output
+	if len(got) != len(want) {
+		t.Fatalf("expected %d output lines, got %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("output[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if h := p.Height(); h != 1+maxOutputLines {
+		t.Errorf("expected height %d with live output, got %d", 1+maxOutputLines, h)
+	}
+	if !strings.Contains(p.View(), "line 4") {
+		t.Error("expected view to contain live output")
+	}
+
+	p.MarkToolDone("bash")
+	if len(p.tools[0].Output) != 0 {
+		t.Error("expected output to be cleared when tool finishes")
+	}
+	if h := p.Height(); h != 1 {
+		t.Errorf("expected height 1 after tool finished, got %d", h)
+	}
+}
+
+func TestActivityPanel_AppendOutputIgnoresFinishedTools(t *testing.T) {
+	p := NewActivityPanel()
+	p.AddTool("bash", `{"command": "ls"}`)
+	p.MarkToolDone("bash")
+
+	p.AppendOutput("bash", "late output\n")
+	if len(p.tools[0].Output) != 0 {
+		t.Error("expected output for finished tool to be ignored")
+	}
+}
+
+func TestActivityPanel_SetProgress(t *testing.T) {
+	p := NewActivityPanel()
+	p.SetWidth(100)
+	p.AddTool("bash", `{"command": "go test ./..."}`)
+	p.AddTool("read_file", `{"file_path": "/var/log/big.log"}`)
+
+	p.SetProgress("read_file", "2.0 MB of 4.0 MB", 0.5)
+	p.SetProgress("bash", "12s elapsed", 0)
+	view := ansi.Strip(p.View())
+	if !strings.Contains(view, "█████░░░░░  50% 2.0 MB of 4.0 MB") {
+		t.Errorf("expected a half full bar for read_file:\n%s", view)
+	}
+	if !strings.Contains(view, spinnerFrames[0]+" 12s elapsed") {
+		t.Errorf("expected a spinner and the elapsed time for bash:\n%s", view)
+	}
+	if h := p.Height(); h != 2 {
+		t.Errorf("progress should not add lines, got height %d", h)
+	}
+
+	p.MarkToolDone("read_file")
+	p.SetProgress("read_file", "4.0 MB of 4.0 MB", 1)
+	if view := ansi.Strip(p.View()); strings.Contains(view, "%") {
+		t.Errorf("finished tools should not show progress:\n%s", view)
+	}
+}
@@ -0,0 +1,120 @@
+package chat
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// sgrReset ends the colors of a line of terminal output.
+const sgrReset = "\x1b[m"
+
+// hasANSI reports whether content holds terminal escape sequences.
+func hasANSI(content string) bool {
+	return strings.ContainsRune(content, '\x1b')
+}
+
+// ansiLines splits colored terminal output, as written by test runners and
+// build tools, into lines that render on their own. Colors (SGR sequences)
+// are kept and carried over to the next line when they span several; other
+// sequences, such as cursor moves and window titles, are dropped, and a
+// carriage return starts its line over, as progress lines do in a terminal.
+func ansiLines(content string) []string {
+	var (
+		lines []string
+		line  strings.Builder
+		state []string // SGR sequences in effect
+	)
+	startLine := func() {
+		line.Reset()
+		for _, seq := range state {
+			line.WriteString(seq)
+		}
+	}
+	endLine := func() {
+		if len(state) > 0 {
+			line.WriteString(sgrReset)
+		}
+		lines = append(lines, line.String())
+		startLine()
+	}
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '\x1b':
+			seq, n := escapeSequence(content[i:])
+			if seq != "" {
+				line.WriteString(seq)
+				state = applySGR(state, seq)
+			}
+			i += n
+			continue
+		case c == '\n':
+			endLine()
+		case c == '\r':
+			if i+1 < len(content) && content[i+1] != '\n' {
+				startLine()
+			}
+		case c == '\t':
+			line.WriteByte(c)
+		case c < ' ' || c == 0x7f:
+			// Other control characters do not print.
+		default:
+			_, size := utf8.DecodeRuneInString(content[i:])
+			line.WriteString(content[i : i+size])
+			i += size
+			continue
+		}
+		i++
+	}
+	if line.Len() > 0 || len(lines) == 0 {
+		endLine()
+	}
+	return lines
+}
+
+// escapeSequence returns the length of the escape sequence s starts with,
+// and the sequence itself when it sets colors.
+func escapeSequence(s string) (sgr string, n int) {
+	if len(s) < 2 { //nolint:mnd // ESC and one more byte
+		return "", len(s)
+	}
+	switch s[1] {
+	case '[':
+		// CSI: parameter and intermediate bytes, then a final byte.
+		for j := 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				if s[j] == 'm' {
+					return s[:j+1], j + 1
+				}
+				return "", j + 1
+			}
+		}
+		return "", len(s)
+	case ']', 'P', '_', '^':
+		// OSC and other strings end with BEL or ST (ESC \).
+		for j := 2; j < len(s); j++ {
+			if s[j] == '\a' {
+				return "", j + 1
+			}
+			if s[j] == '\x1b' && j+1 < len(s) && s[j+1] == '\\' {
+				return "", j + 2 //nolint:mnd // ESC \
+			}
+		}
+		return "", len(s)
+	}
+	return "", 2 //nolint:mnd // ESC and the byte naming the sequence
+}
+
+// applySGR returns the colors in effect after seq. A reset clears them; any
+// other sequence adds to them.
+func applySGR(state []string, seq string) []string {
+	params := seq[2 : len(seq)-1]
+	if params == "" || params == "0" || strings.HasPrefix(params, "0;") {
+		state = state[:0]
+		if params == "" || params == "0" {
+			return state
+		}
+	}
+	return append(state, seq)
+}
@@ -0,0 +1,75 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+)
+
+func TestANSILines(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "plain",
+			content: "ok\tpkg",
+			want:    []string{"ok\tpkg"},
+		},
+		{
+			name:    "colors end on the line",
+			content: "\x1b[32mPASS\x1b[0m TestA\nok",
+			want:    []string{"\x1b[32mPASS\x1b[0m TestA", "ok"},
+		},
+		{
+			name:    "colors carried to the next line",
+			content: "\x1b[1m\x1b[31mFAIL\n  want 2\x1b[0m\ndone",
+			want:    []string{"\x1b[1m\x1b[31mFAIL" + sgrReset, "\x1b[1m\x1b[31m  want 2\x1b[0m", "done"},
+		},
+		{
+			name:    "other sequences dropped",
+			content: "\x1b]0;title\a\x1b[2K\x1b[1Gbuilding\x1b[?25h",
+			want:    []string{"building"},
+		},
+		{
+			name:    "carriage return starts the line over",
+			content: "10%\r50%\r100% done\r\nnext",
+			want:    []string{"100% done", "next"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ansiLines(tt.content)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("ansiLines(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageList_ColoredToolResult(t *testing.T) {
+	m := NewMessageList()
+	m.SetSize(80, 30)
+	m.SetMessages([]agent.Message{
+		{ID: "a1", Role: agent.RoleAssistant, ToolCalls: []agent.ToolCall{{ID: "call1", Name: "bash", Input: `{"command":"go test ./..."}`}}},
+		{ID: "t1", Role: agent.RoleTool, ToolResults: []agent.ToolResult{{
+			ToolCallID: "call1", Name: "bash", IsError: true,
+			Content: "\x1b[31m--- FAIL: TestA\x1b[0m\n\x1b[2Kexit status 1",
+		}}},
+	})
+
+	content := m.renderedContent
+	if !strings.Contains(content, "\x1b[31m--- FAIL: TestA") {
+		t.Errorf("the output's own colors should be kept:\n%q", content)
+	}
+	if strings.Contains(content, "\x1b[2K") {
+		t.Errorf("cursor sequences should be dropped:\n%q", content)
+	}
+	if !strings.Contains(ansi.Strip(content), "— 2 lines (error)") {
+		t.Errorf("header should count the lines:\n%s", ansi.Strip(content))
+	}
+}
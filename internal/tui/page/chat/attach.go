@@ -0,0 +1,123 @@
+package chat
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// imageExtensions are the file extensions recognized when a path is pasted
+// or dropped into the input.
+var imageExtensions = map[string]bool{
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".gif":  true,
+	".webp": true,
+}
+
+// cleanPath turns a typed, pasted or dropped path into a file path. Terminals
+// drop files as quoted paths, backslash-escaped paths, or file:// URLs.
+func cleanPath(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		s = s[1 : len(s)-1]
+	} else {
+		s = strings.NewReplacer(`\ `, " ", `\(`, "(", `\)`, ")", `\'`, "'").Replace(s)
+	}
+	if strings.HasPrefix(s, "file://") {
+		if u, err := url.Parse(s); err == nil {
+			s = u.Path
+		}
+	}
+	if s == "~" || strings.HasPrefix(s, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			s = filepath.Join(home, s[1:])
+		}
+	}
+	return s
+}
+
+// pastedImagePath reports whether pasted text is the path of an existing
+// image file, as happens when an image is dragged onto the terminal.
+func pastedImagePath(text string) (string, bool) {
+	if strings.ContainsAny(strings.TrimSpace(text), "\n\r") {
+		return "", false
+	}
+	path := cleanPath(text)
+	if !imageExtensions[strings.ToLower(filepath.Ext(path))] {
+		return "", false
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+	return path, true
+}
+
+// modelSupportsImages reports whether the selected model accepts images.
+// Models missing from the catalog, such as custom ones, are assumed to.
+func (m *Model) modelSupportsImages() bool {
+	if m.cfg == nil {
+		return true
+	}
+	selected, ok := m.cfg.Models[config.SelectedModelTypeLarge]
+	if !ok {
+		return true
+	}
+	model := m.cfg.GetModel(selected.Provider, selected.Model)
+	return model == nil || model.SupportsImages
+}
+
+// attach loads an image to send with the next message.
+func (m *Model) attach(path string) tea.Cmd {
+	if !m.modelSupportsImages() {
+		return util.ReportWarn("The selected model does not accept images. Switch models with /models.")
+	}
+	att, err := agent.LoadAttachment(cleanPath(path))
+	if err != nil {
+		return util.ReportError(err)
+	}
+	m.attachments = append(m.attachments, att)
+	m.status.SetAttachments(attachmentNames(m.attachments))
+	return util.ReportInfo(fmt.Sprintf("Attached %s; it will be sent with your next message", att.Filename))
+}
+
+// handleAttach runs the /attach command.
+func (m *Model) handleAttach(args []string) tea.Cmd {
+	switch {
+	case len(args) == 0 && len(m.attachments) == 0:
+		return util.ReportInfo("Usage: /attach <image path>, or drop an image file onto the input")
+	case len(args) == 0:
+		return util.ReportInfo("Attached: " + strings.Join(attachmentNames(m.attachments), ", "))
+	case len(args) == 1 && args[0] == "clear":
+		m.attachments = nil
+		m.status.SetAttachments(nil)
+		return util.ReportInfo("Attachments cleared")
+	}
+	return m.attach(strings.Join(args, " "))
+}
+
+// attachmentKind labels an attachment in the transcript.
+func attachmentKind(att agent.Attachment) string {
+	if att.IsImage() {
+		return "image"
+	}
+	return "file"
+}
+
+func attachmentNames(attachments []agent.Attachment) []string {
+	names := make([]string, len(attachments))
+	for i := range attachments {
+		names[i] = attachments[i].Filename
+	}
+	return names
+}
@@ -0,0 +1,55 @@
+package chat
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	tests := map[string]string{
+		"/tmp/shot.png":             "/tmp/shot.png",
+		"  '/tmp/my shot.png'  ":    "/tmp/my shot.png",
+		`"/tmp/my shot.png"`:        "/tmp/my shot.png",
+		`/tmp/my\ shot\ \(1\).png`:  "/tmp/my shot (1).png",
+		"file:///tmp/my%20shot.png": "/tmp/my shot.png",
+		"~/Pictures/cat.png":        filepath.Join(home, "Pictures/cat.png"),
+		"relative/cat.png":          "relative/cat.png",
+	}
+	for in, want := range tests {
+		if got := cleanPath(in); got != want {
+			t.Errorf("cleanPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPastedImagePath(t *testing.T) {
+	dir := t.TempDir()
+	image := filepath.Join(dir, "drop me.PNG")
+	if err := os.WriteFile(image, []byte("\x89PNG\r\n\x1a\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	text := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(text, []byte("hi"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, ok := pastedImagePath("'" + image + "' "); !ok || got != image {
+		t.Errorf("dropped image not recognized: %q, %v", got, ok)
+	}
+	for _, pasted := range []string{
+		text,
+		filepath.Join(dir, "missing.png"),
+		image + "\nsome other text",
+		"look at this screenshot",
+	} {
+		if _, ok := pastedImagePath(pasted); ok {
+			t.Errorf("%q should be pasted as text", pasted)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package chat
+
+import (
+	"errors"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+)
+
+// resumePrompt is sent to pick a run back up after it paused for the budget.
+const resumePrompt = "You were paused because the budget ran out; the user agreed to go on. " +
+	"Continue the task where you left off."
+
+// BudgetExceededMsg is sent when the agent would not start a prompt, or
+// paused a run, because a budget is used up.
+type BudgetExceededMsg struct {
+	SessionID   string
+	Error       error
+	Prompt      string
+	Attachments []agent.Attachment
+	ReplaceFrom string
+	Model       string // Model the prompt was addressed to, if not the session's
+}
+
+// paused reports whether the prompt ran and was stopped between steps,
+// rather than refused before it was sent.
+func (msg BudgetExceededMsg) paused() bool {
+	return errors.Is(msg.Error, agent.ErrPaused)
+}
+
+// confirmBudget asks whether to go on past the budget.
+func (m *Model) confirmBudget(msg BudgetExceededMsg) {
+	m.overBudget = &msg
+	m.status.SetNotice(msg.Error.Error() + ". Continue anyway for this session? (y/n)")
+}
+
+// handleBudgetKey goes on past the budget for the rest of the session, or
+// keeps the agent paused and puts a refused prompt back in the input.
+func (m *Model) handleBudgetKey(msg tea.KeyMsg) tea.Cmd {
+	pending := m.overBudget
+	switch msg.String() {
+	case "y", "Y", "enter":
+		m.overBudget = nil
+		m.budgetApproved = m.sessionID
+		m.status.SetNotice("")
+		if pending.paused() {
+			return m.startStream(resumePrompt, nil, "")
+		}
+		m.promptModel = pending.Model
+		return m.startStream(pending.Prompt, pending.Attachments, pending.ReplaceFrom)
+	case "n", "N", "esc":
+		m.overBudget = nil
+		m.status.SetNotice("")
+		if !pending.paused() {
+			prompt := pending.Prompt
+			if pending.Model != "" {
+				prompt = "@" + pending.Model + ": " + prompt
+			}
+			m.input.SetValue(prompt)
+			m.attachments = pending.Attachments
+		}
+	}
+	return nil
+}
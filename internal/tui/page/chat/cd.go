@@ -0,0 +1,94 @@
+package chat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/commands"
+	"github.com/guilhermegouw/cdd/internal/session"
+	"github.com/guilhermegouw/cdd/internal/tools"
+	"github.com/guilhermegouw/cdd/internal/trust"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// SetTrust sets the list of trusted directories /cd checks before moving
+// the session out of the directory cdd started in.
+func (m *Model) SetTrust(store *trust.Store) {
+	m.trust = store
+}
+
+// handleCd runs the /cd command. Without arguments it shows the session's
+// working directory; /cd PATH changes it, relative to the current one, and
+// /cd --trust PATH trusts an untrusted project on the way.
+func (m *Model) handleCd(args []string) tea.Cmd {
+	if m.agent == nil {
+		return util.ReportWarn("No agent configured")
+	}
+	trustDir := len(args) > 0 && args[0] == "--trust"
+	if trustDir {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return util.ReportInfo("Working directory: " + m.workingDir())
+	}
+	if m.isStreaming {
+		return util.ReportWarn("Wait for the reply to finish before changing the working directory")
+	}
+
+	dir := cleanPath(strings.Join(args, " "))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(m.workingDir(), dir)
+	}
+	dir = filepath.Clean(dir)
+	info, err := os.Stat(dir)
+	if err != nil {
+		return util.ReportError(fmt.Errorf("changing directory: %w", err))
+	}
+	if !info.IsDir() {
+		return util.ReportWarn(dir + " is not a directory")
+	}
+	if err := m.checkTrust(dir, trustDir); err != nil {
+		return util.ReportWarn(err.Error())
+	}
+
+	// The directory cdd started in is stored as the default, so the session
+	// keeps following it.
+	stored := dir
+	if dir == m.agent.WorkingDir() {
+		stored = ""
+	}
+	if !m.agent.SetSessionWorkingDir(m.sessionID, stored) {
+		return util.ReportError(fmt.Errorf("saving the working directory for this session"))
+	}
+	m.commandRegistry.RegisterCustom(commands.Load(dir))
+	m.refreshStatus()
+	return util.ReportSuccess("Working directory: " + dir)
+}
+
+// checkTrust returns an error unless the session may work in dir: inside
+// the directory cdd started in, which was trusted then, or in a trusted
+// project, since its commands load and the agent runs there. With trustDir
+// an untrusted project is trusted first.
+func (m *Model) checkTrust(dir string, trustDir bool) error {
+	if tools.IsPathWithinDir(dir, m.agent.WorkingDir()) {
+		return nil
+	}
+	if m.trust == nil {
+		return fmt.Errorf("%s is outside the start directory and trust cannot be checked", dir)
+	}
+	project := session.ProjectRoot(dir)
+	if m.trust.IsTrusted(project) {
+		return nil
+	}
+	if !trustDir {
+		return fmt.Errorf("%s is not trusted; /cd --trust %s trusts it", project, dir)
+	}
+	if err := m.trust.Trust(project); err != nil {
+		return fmt.Errorf("trusting %s: %w", project, err)
+	}
+	return nil
+}
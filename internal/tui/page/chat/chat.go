@@ -5,9 +5,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/fantasy"
@@ -16,44 +18,83 @@ import (
 
 	"github.com/guilhermegouw/cdd/internal/agent"
 	"github.com/guilhermegouw/cdd/internal/bridge"
+	"github.com/guilhermegouw/cdd/internal/commands"
 	"github.com/guilhermegouw/cdd/internal/config"
 	"github.com/guilhermegouw/cdd/internal/debug"
 	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/history"
+	"github.com/guilhermegouw/cdd/internal/jobs"
 	"github.com/guilhermegouw/cdd/internal/pubsub"
 	"github.com/guilhermegouw/cdd/internal/session"
 	"github.com/guilhermegouw/cdd/internal/tools"
+	"github.com/guilhermegouw/cdd/internal/trust"
 	"github.com/guilhermegouw/cdd/internal/tui/components/models"
 	"github.com/guilhermegouw/cdd/internal/tui/components/sessions"
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
 	"github.com/guilhermegouw/cdd/internal/tui/styles"
 	"github.com/guilhermegouw/cdd/internal/tui/util"
+	"github.com/guilhermegouw/cdd/internal/usage"
 )
 
-// Stream message types for TUI updates.
+// Stream message types for TUI updates. Each carries the session it streams
+// for, so the TUI can deliver it to the tab showing that session.
 type (
 	// StreamTextMsg is sent when text is streamed.
 	StreamTextMsg struct {
-		Text string
+		SessionID string
+		Text      string
 	}
 
 	// StreamToolCallMsg is sent when a tool is called.
 	StreamToolCallMsg struct {
-		ToolCall agent.ToolCall
+		SessionID string
+		ToolCall  agent.ToolCall
 	}
 
 	// StreamToolResultMsg is sent when a tool completes.
 	StreamToolResultMsg struct {
+		SessionID  string
 		ToolResult agent.ToolResult
 	}
 
 	// StreamCompleteMsg is sent when streaming completes.
-	StreamCompleteMsg struct{}
+	StreamCompleteMsg struct {
+		SessionID string
+	}
 
 	// StreamErrorMsg is sent when an error occurs.
 	StreamErrorMsg struct {
-		Error error
+		SessionID string
+		Error     error
 	}
 )
 
+// SessionOf returns the session a stream, spinner or bridge event message
+// belongs to. It reports false for messages that concern no one session.
+func SessionOf(msg tea.Msg) (string, bool) {
+	switch msg := msg.(type) {
+	case StreamTextMsg:
+		return msg.SessionID, true
+	case StreamToolCallMsg:
+		return msg.SessionID, true
+	case StreamToolResultMsg:
+		return msg.SessionID, true
+	case StreamCompleteMsg:
+		return msg.SessionID, true
+	case StreamErrorMsg:
+		return msg.SessionID, true
+	case BudgetExceededMsg:
+		return msg.SessionID, true
+	case SpinnerTickMsg:
+		return msg.SessionID, true
+	case handoffDoneMsg:
+		return msg.SessionID, true
+	case regenerateMsg:
+		return msg.SessionID, true
+	}
+	return bridge.SessionOf(msg)
+}
+
 // AgentFactory creates a new agent (used for rebuilding after token refresh).
 type AgentFactory func() (*agent.DefaultAgent, error)
 
@@ -69,31 +110,102 @@ type Model struct {
 	commandRegistry *CommandRegistry
 	modelsModal     *models.Modal
 	sessionsModal   *sessions.Modal
+	helpOverlay     *HelpOverlay
+	filePicker      *FilePicker
+	history         *PromptHistory
+	historySearch   *HistorySearch
+	modelSwitcher   *ModelSwitcher
+	filePane        *FilePane
+	fileCalls       map[string]string // File paths of running file tool calls
 	sessionSvc      *session.Service
 	messages        *MessageList
 	activity        *ActivityPanel
 	todoPanel       *TodoPanel
+	jobs            *jobs.Manager
+	hub             *pubsub.Hub // Recent events, replayed when a running session is opened
+	jobsPanel       *JobsPanel
 	input           *Input
 	status          *StatusBar
 	program         *tea.Program
 	cfg             *config.Config
 	providers       []catwalk.Provider
+	attachments     []agent.Attachment    // Images to send with the next message
+	editing         *agent.Message        // Earlier prompt being edited in the input
+	resend          *pendingResend        // Edit or retry waiting for confirmation
+	overBudget      *BudgetExceededMsg    // Prompt waiting for the user to go past the budget
+	budgetApproved  string                // Session the user let go past the budget
+	interrupted     *agent.InterruptedRun // Reply cut short when cdd last exited, offered to resume
+	locked          string                // Session this page holds the lock of
+	takeover        *session.LockedError  // Session in use by another cdd, offered to take over
+	trust           *trust.Store          // Trusted directories, checked by /cd
+	planFirst       bool                  // Plan prompts and wait for approval before running them
+	planning        bool                  // The prompt being sent is a planning pass
+	planPending     bool                  // A plan is waiting for approval
+	revisingPlan    bool                  // The next prompt asks for changes to the plan
+	promptModel     string                // Model the next prompt is sent to instead of the session's
 	sessionID       string
 	isStreaming     bool
+	picking         bool          // Choosing an earlier prompt to edit or retry
+	regen           *regeneration // The last reply being written again, or nil
+	focused         bool          // Terminal has focus, as far as it reports
+	runStarted      time.Time     // When the reply in progress was requested
+	copiedCode      int           // Code block last copied with keymap.CopyCode
+	copiedCodeFrom  string        // ID of the reply that block is in
 	width           int
 	height          int
 }
 
 // New creates a new chat page model.
 func New(ag *agent.DefaultAgent) *Model {
+	promptHistory := NewPromptHistory()
+	status := NewStatusBar()
+	if ag != nil {
+		status.SetAgentMode(ag.Mode())
+	}
 	return &Model{
 		agent:           ag,
 		commandRegistry: NewCommandRegistry(),
+		helpOverlay:     NewHelpOverlay(),
+		filePicker:      NewFilePicker(),
+		history:         promptHistory,
+		historySearch:   NewHistorySearch(promptHistory),
+		modelSwitcher:   NewModelSwitcher(),
+		filePane:        NewFilePane(),
+		fileCalls:       make(map[string]string),
 		messages:        NewMessageList(),
 		activity:        NewActivityPanel(),
 		todoPanel:       NewTodoPanel(),
+		jobsPanel:       NewJobsPanel(nil),
 		input:           NewInput(),
-		status:          NewStatusBar(),
+		status:          status,
+		focused:         true,
+	}
+}
+
+// SessionID returns the ID of the session shown.
+func (m *Model) SessionID() string {
+	return m.sessionID
+}
+
+// Title returns the title of the session shown.
+func (m *Model) Title() string {
+	if m.agent != nil {
+		if sess, ok := m.agent.Sessions().Get(m.sessionID); ok && sess.Title != "" {
+			return sess.Title
+		}
+	}
+	return "New Session"
+}
+
+// IsStreaming reports whether a reply is being streamed.
+func (m *Model) IsStreaming() bool {
+	return m.isStreaming
+}
+
+// Cancel stops the reply being streamed, if any.
+func (m *Model) Cancel() {
+	if m.isStreaming {
+		m.agent.Cancel(m.sessionID)
 	}
 }
 
@@ -122,6 +234,37 @@ func (m *Model) SetConfig(cfg *config.Config, providers []catwalk.Provider) {
 	m.cfg = cfg
 	m.providers = providers
 	m.modelsModal = models.New(cfg, providers)
+	m.input.SetVimMode(cfg.VimMode())
+	m.messages.SetShowThinking(cfg.ShowThinking())
+	m.messages.SetResultLines(cfg.ToolOutputDisplayLines())
+	markdown, _ := cfg.Markdown()
+	if styles.Accessible() {
+		markdown.Style = config.MarkdownStyleNoTTY // No box drawing in tables and rules
+	} else if !styles.HasColor() {
+		markdown.Style = config.MarkdownStyleNoTTY // Headings and code marked by text, not color
+	}
+	m.messages.SetRenderOptions(markdown)
+	m.planFirst = cfg.PlanFirst()
+	segments, _ := cfg.StatusBar()
+	m.status.SetSegments(segments)
+}
+
+// ReloadConfig applies configuration that changed on disk. A models modal
+// that is open keeps the providers it was opened with until it closes.
+func (m *Model) ReloadConfig(cfg *config.Config, providers []catwalk.Provider) {
+	open := m.modelsModal
+	m.SetConfig(cfg, providers)
+	if open != nil && open.IsVisible() {
+		m.modelsModal = open
+	}
+}
+
+// SetPromptHistory loads the working directory's prompt history from store
+// and saves new prompts to it.
+func (m *Model) SetPromptHistory(store history.Store) {
+	if store != nil {
+		m.history.Load(store, m.workingDir())
+	}
 }
 
 // SetSessionService sets the session service for the sessions modal.
@@ -154,9 +297,16 @@ func (m *Model) Init() tea.Cmd {
 	// Get or create a session
 	sess := m.agent.Sessions().Current()
 	m.sessionID = sess.ID
+	m.activity.SetSession(sess.ID)
 	m.messages.SetMessages(sess.Messages)
-
-	return m.input.Init()
+	m.restoreTodos()
+	m.commandRegistry.RegisterCustom(commands.Load(m.workingDir()))
+	m.refreshStatus()
+
+	m.holdSession()
+	replay := m.replaySession()
+	m.offerRecovery()
+	return tea.Batch(m.input.Init(), replay)
 }
 
 // Update handles messages.
@@ -165,6 +315,19 @@ func (m *Model) Init() tea.Cmd {
 func (m *Model) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
+	// Track focus even while a modal is open, for completion notifications.
+	switch msg.(type) {
+	case tea.FocusMsg:
+		m.focused = true
+	case tea.BlurMsg:
+		m.focused = false
+	}
+
+	// Messages for a session shown in another tab are not ours.
+	if id, ok := SessionOf(msg); ok && id != "" && id != m.sessionID {
+		return m, nil
+	}
+
 	// Route to modal if visible.
 	if m.modelsModal != nil && m.modelsModal.IsVisible() {
 		var cmd tea.Cmd
@@ -187,10 +350,25 @@ func (m *Model) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		debug.Event("chat", "KeyMsg", fmt.Sprintf("key=%q", msg.String()))
+		if m.helpOverlay.IsVisible() {
+			if keymap.Current().Matches(msg, keymap.Back, keymap.Help) {
+				m.helpOverlay.Hide()
+			}
+			return m, nil
+		}
 		return m.handleKey(msg)
 
 	case tea.MouseWheelMsg:
 		debug.Event("chat", "MouseWheel", fmt.Sprintf("button=%v x=%d y=%d", msg.Button, msg.X, msg.Y))
+		if m.showFilePane() && msg.X >= m.chatWidth() {
+			switch msg.Button { //nolint:exhaustive // Only vertical scrolling
+			case tea.MouseWheelUp:
+				m.filePane.ScrollUp(3) //nolint:mnd // Lines per wheel notch
+			case tea.MouseWheelDown:
+				m.filePane.ScrollDown(3) //nolint:mnd // Lines per wheel notch
+			}
+			return m, nil
+		}
 		// Route mouse wheel events to viewport
 		var cmd tea.Cmd
 		m.messages, cmd = m.messages.Update(msg)
@@ -200,7 +378,7 @@ func (m *Model) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 	case tea.MouseClickMsg:
 		// Only handle clicks in the messages area
 		messagesHeight := m.messagesAreaHeight()
-		if msg.Y < messagesHeight && msg.Button == tea.MouseLeft {
+		if msg.Y < messagesHeight && msg.X < m.chatWidth() && msg.Button == tea.MouseLeft {
 			debug.Event("chat", "MouseClick", fmt.Sprintf("x=%d y=%d in messages area", msg.X, msg.Y))
 			m.messages.StartSelection(msg.X, msg.Y)
 		}
@@ -224,8 +402,8 @@ func (m *Model) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 			// Clamp x to valid range
 			if x < 0 {
 				x = 0
-			} else if x >= m.width {
-				x = m.width - 1
+			} else if x >= m.chatWidth() {
+				x = m.chatWidth() - 1
 			}
 
 			m.messages.EndSelection(x, y)
@@ -238,6 +416,14 @@ func (m *Model) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 			debug.Event("chat", "MouseRelease", fmt.Sprintf("x=%d y=%d", msg.X, msg.Y))
 			m.messages.SelectionStop()
 
+			// A click without a drag on a tool header opens or closes it
+			if !m.messages.HasSelection() {
+				if id := m.messages.ToolResultAt(msg.Y); id != "" {
+					m.messages.ToggleExpanded(id)
+				}
+				return m, nil
+			}
+
 			// Copy selection to clipboard if there's a selection
 			if m.messages.HasSelection() {
 				cmd := m.messages.CopySelection()
@@ -275,14 +461,47 @@ func (m *Model) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		m.input.Enable()
 		// Refresh messages from session
 		m.messages.SetMessages(m.agent.Sessions().GetMessages(m.sessionID))
-		return m, m.input.Focus()
+		m.refreshStatus()
+		m.finishRegenerate()
+		if m.planning {
+			m.confirmPlan()
+			return m, tea.Batch(m.input.Focus(), m.notifyFinished("Plan ready for approval"))
+		}
+		return m, tea.Batch(m.input.Focus(), m.notifyFinished("Reply ready"))
+
+	case BudgetExceededMsg:
+		m.isStreaming = false
+		m.planning = false
+		m.activity.Clear()
+		m.status.SetStatus(StatusReady)
+		m.input.Enable()
+		m.regen = nil // The reply being regenerated is still in place
+		// Drops the placeholders of a prompt that was not sent
+		m.messages.SetMessages(m.agent.Sessions().GetMessages(m.sessionID))
+		m.confirmBudget(msg)
+		return m, tea.Batch(m.input.Focus(), m.notifyFinished("Paused: budget used up"))
 
 	case StreamErrorMsg:
 		m.isStreaming = false
+		m.planning = false
 		m.activity.Clear()
+		if errors.Is(msg.Error, context.Canceled) {
+			// The partial reply was saved, marked as cancelled
+			m.status.SetStatus(StatusReady)
+			m.input.Enable()
+			m.messages.SetMessages(m.agent.Sessions().GetMessages(m.sessionID))
+			m.finishRegenerate()
+			m.announce("Reply cancelled")
+			return m, m.input.Focus()
+		}
+		if errors.Is(msg.Error, agent.ErrUnknownModel) {
+			// The prompt was not sent, so it is not kept either
+			m.messages.SetMessages(m.agent.Sessions().GetMessages(m.sessionID))
+		}
 		m.status.SetError(msg.Error.Error())
 		m.input.Enable()
-		return m, m.input.Focus()
+		m.finishRegenerate()
+		return m, tea.Batch(m.input.Focus(), m.notifyFinished("Request failed: "+msg.Error.Error()))
 
 	case SpinnerTickMsg:
 		var cmd tea.Cmd
@@ -304,6 +523,27 @@ func (m *Model) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 	case bridge.TodoEventMsg:
 		return m.handleTodoEvent(msg.Event)
 
+	case bridge.JobEventMsg:
+		return m, m.handleJobEvent(msg.Event.Payload)
+
+	case BackgroundMsg:
+		return m, m.handleBackground(msg.Args)
+
+	case JobsMsg:
+		return m, m.handleJobs(msg.Args)
+
+	case regenerateMsg:
+		return m, m.handleRegenerate()
+
+	case ModelMsg:
+		return m, m.handleModel(msg.Args)
+
+	case HandoffMsg:
+		return m, m.handleHandoff(msg.Args)
+
+	case handoffDoneMsg:
+		return m, m.handleHandoffDone(msg)
+
 	case OpenModelsModalMsg:
 		if m.modelsModal == nil {
 			return m, util.ReportWarn("Models modal not configured. Please set config first.")
@@ -329,8 +569,86 @@ func (m *Model) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		}
 		m.agent.SetModel(newModel)
 		m.status.SetModelName(msg.ModelName)
+		m.announceModelSwitch(msg)
 		return m, util.ReportSuccess(fmt.Sprintf("Switched to %s", msg.ModelName))
 
+	case AttachMsg:
+		return m, m.handleAttach(msg.Args)
+
+	case SystemMsg:
+		return m, m.handleSystem(msg.Args)
+
+	case ModeMsg:
+		return m, m.handleMode(msg.Args)
+
+	case PlanMsg:
+		return m, m.handlePlan(msg.Args)
+
+	case CdMsg:
+		return m, m.handleCd(msg.Args)
+
+	case ShareMsg:
+		return m, m.handleShare()
+
+	case sharedMsg:
+		if msg.err != nil {
+			return m, util.ReportError(fmt.Errorf("sharing the session: %w", msg.err))
+		}
+		return m, copyToClipboard(msg.url, "Shared at "+msg.url+" (link copied)")
+
+	case ThinkingMsg:
+		return m, m.handleThinking(msg.Args)
+
+	case RenderMsg:
+		return m, m.handleRender(msg.Args)
+
+	case TranscriptMsg:
+		return m, m.viewTranscript()
+
+	case ContinueMsg:
+		if m.isStreaming {
+			return m, nil
+		}
+		return m, m.startStream(continuePrompt, nil, "")
+
+	case UndoMsg:
+		return m, m.handleUndo(msg.Args)
+
+	case tea.PasteMsg:
+		// Dropping an image onto the terminal pastes its path.
+		if path, ok := pastedImagePath(msg.Content); ok && m.input.IsEnabled() {
+			return m, m.attach(path)
+		}
+
+	case ShowContextMsg:
+		m.messages.AppendMessage(agent.Message{
+			Role:    agent.RoleSystem,
+			Content: contextReport(m.agent.ContextFiles()),
+		})
+		return m, nil
+
+	case StatsMsg:
+		stats, err := m.agent.SessionStats(context.Background(), m.sessionID)
+		if err != nil {
+			return m, util.ReportError(err)
+		}
+		m.messages.AppendMessage(agent.Message{
+			Role:    agent.RoleSystem,
+			Content: "Session stats\n\n" + stats.Report(),
+		})
+		return m, nil
+
+	case CustomCommandMsg:
+		if m.isStreaming {
+			return m, nil
+		}
+		mentions, warnings := m.mentionedFiles(msg.Prompt)
+		attachments := m.attachments
+		attachments = append(attachments, mentions...)
+		m.attachments = nil
+		m.status.SetAttachments(nil)
+		return m, tea.Batch(append(warnings, m.startPrompt(msg.Prompt, attachments))...)
+
 	case UnknownCommandMsg:
 		return m, util.ReportWarn(fmt.Sprintf("Unknown command: /%s", msg.Command))
 
@@ -338,6 +656,9 @@ func (m *Model) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		if m.sessionsModal == nil {
 			return m, util.ReportWarn("Sessions modal not configured. Please set session service first.")
 		}
+		if m.agent != nil {
+			m.sessionsModal.SetJournal(m.agent.Journal())
+		}
 		m.sessionsModal.Show()
 		m.sessionsModal.SetSize(m.width, m.height)
 		m.input.Disable()
@@ -379,8 +700,49 @@ func (m *Model) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 }
 
 func (m *Model) handleKey(msg tea.KeyMsg) (util.Model, tea.Cmd) {
-	switch msg.String() {
-	case "enter":
+	km := keymap.Current()
+	if m.resend != nil {
+		return m, m.handleResendKey(msg)
+	}
+	if m.regen.choosing() {
+		return m, m.handleVariantKey(msg)
+	}
+	if m.takeover != nil {
+		return m, m.handleTakeoverKey(msg)
+	}
+	if m.overBudget != nil {
+		return m, m.handleBudgetKey(msg)
+	}
+	if m.interrupted != nil {
+		return m, m.handleRecoveryKey(msg)
+	}
+	if m.planPending {
+		return m, m.handlePlanKey(msg)
+	}
+	if m.picking {
+		return m, m.handlePickKey(msg)
+	}
+	if m.historySearch.IsVisible() {
+		m.handleHistorySearchKey(msg)
+		return m, nil
+	}
+	if m.modelSwitcher.IsVisible() {
+		return m, m.handleModelSwitcherKey(msg)
+	}
+	if m.filePicker.IsVisible() && m.handleFilePickerKey(msg) {
+		return m, nil
+	}
+	if msg.String() == "tab" && m.input.IsEnabled() && m.completePath() {
+		return m, nil
+	}
+
+	switch {
+	case km.Matches(msg, keymap.Help) && (!m.input.IsEnabled() || m.input.Value() == ""):
+		m.helpOverlay.SetSize(m.width, m.height)
+		m.helpOverlay.Show()
+		return m, nil
+
+	case km.Matches(msg, keymap.Send):
 		if m.isStreaming {
 			return m, nil
 		}
@@ -389,6 +751,7 @@ func (m *Model) handleKey(msg tea.KeyMsg) (util.Model, tea.Cmd) {
 		if value == "" {
 			return m, nil
 		}
+		m.history.Add(m.sessionID, value)
 
 		// Check for slash commands before sending to agent.
 		if cmd := m.parseCommand(value); cmd != nil {
@@ -396,30 +759,90 @@ func (m *Model) handleKey(msg tea.KeyMsg) (util.Model, tea.Cmd) {
 			return m, cmd
 		}
 
-		// Clear input and start streaming
-		m.input.Clear()
-		m.input.Disable()
-		m.isStreaming = true
-		m.status.SetStatus(StatusThinking)
+		// A leading @model: sends this prompt alone to another model.
+		if m.editing == nil {
+			m.promptModel, value = m.promptModelPrefix(value)
+		}
 
-		// Start activity panel with spinner
-		spinnerCmd := m.activity.SetThinking(true)
+		// Files mentioned as @path are sent along with the prompt.
+		mentions, warnings := m.mentionedFiles(value)
 
-		// Add placeholder for assistant response
-		m.messages.AppendMessage(agent.Message{
-			Role:    agent.RoleUser,
-			Content: value,
-		})
-		m.messages.AppendMessage(agent.Message{
-			Role:    agent.RoleAssistant,
-			Content: "",
-		})
+		attachments := m.attachments
+		attachments = append(attachments, mentions...)
+		m.attachments = nil
+		m.status.SetAttachments(nil)
+
+		if m.editing != nil {
+			attachments = append(editedAttachments(*m.editing), attachments...)
+			return m, tea.Batch(append(warnings, m.confirmResend(*m.editing, value, attachments))...)
+		}
+		return m, tea.Batch(append(warnings, m.startPrompt(value, attachments))...)
+
+	case km.Matches(msg, keymap.HistoryPrev) && m.input.IsEnabled() &&
+		(m.input.Value() == "" || m.history.Recalled(m.input.Value())):
+		if prompt, ok := m.history.Prev(); ok {
+			m.input.SetValue(prompt)
+		}
+		return m, nil
+
+	case km.Matches(msg, keymap.HistoryNext) && m.input.IsEnabled() && m.history.Recalled(m.input.Value()):
+		if prompt, ok := m.history.Next(); ok {
+			m.input.SetValue(prompt)
+		}
+		return m, nil
+
+	case km.Matches(msg, keymap.PickMessage) && !m.isStreaming:
+		return m, m.startPicking()
+
+	case km.Matches(msg, keymap.CopyMessage) && !m.typing():
+		return m, m.copyLastReply()
+
+	case km.Matches(msg, keymap.CopyCode) && !m.typing():
+		return m, m.copyNextCodeBlock()
+
+	case km.Matches(msg, keymap.ToggleTools):
+		m.messages.ToggleAllTools()
+		return m, nil
+
+	case km.Matches(msg, keymap.PrevMessage):
+		m.messages.JumpPrev()
+		return m, nil
+
+	case km.Matches(msg, keymap.NextMessage):
+		m.messages.JumpNext()
+		return m, nil
+
+	case km.Matches(msg, keymap.JumpUnread):
+		if !m.messages.JumpUnread() {
+			return m, util.ReportInfo("No new output to jump to")
+		}
+		return m, nil
+
+	case km.Matches(msg, keymap.FilePane):
+		m.filePane.Toggle()
+		if m.filePane.IsVisible() && m.width < minFilePaneWidth {
+			return m, util.ReportWarn(fmt.Sprintf("The file pane needs a terminal at least %d columns wide", minFilePaneWidth))
+		}
+		return m, nil
 
-		// Send to agent
-		sendCmd := m.sendMessage(value)
-		return m, tea.Batch(spinnerCmd, sendCmd)
+	case km.Matches(msg, keymap.Cancel) && m.editing != nil && !m.isStreaming:
+		m.cancelEdit()
+		return m, util.ReportInfo("Edit cancelled")
+
+	case km.Matches(msg, keymap.HistorySearch) && m.input.IsEnabled():
+		m.filePicker.Close()
+		m.historySearch.Open()
+		return m, nil
+
+	case km.Matches(msg, keymap.SwitchModel) && !m.isStreaming:
+		if m.cfg == nil {
+			return m, util.ReportWarn("Models not configured. Please set config first.")
+		}
+		m.filePicker.Close()
+		m.modelSwitcher.Open(m.cfg)
+		return m, nil
 
-	case "ctrl+c":
+	case km.Matches(msg, keymap.Quit):
 		if m.isStreaming {
 			m.agent.Cancel(m.sessionID)
 			m.activity.Clear()
@@ -427,7 +850,7 @@ func (m *Model) handleKey(msg tea.KeyMsg) (util.Model, tea.Cmd) {
 		}
 		return m, tea.Quit
 
-	case "esc":
+	case km.Matches(msg, keymap.Cancel):
 		if m.isStreaming {
 			m.agent.Cancel(m.sessionID)
 			m.activity.Clear()
@@ -453,11 +876,53 @@ func (m *Model) handleKey(msg tea.KeyMsg) (util.Model, tea.Cmd) {
 	if inputCmd != nil {
 		cmds = append(cmds, inputCmd)
 	}
+	m.updateFilePicker()
+	if !m.history.Recalled(m.input.Value()) {
+		m.history.Reset() // Editing a recalled prompt ends browsing
+	}
 
 	return m, tea.Batch(cmds...)
 }
 
-func (m *Model) sendMessage(prompt string) tea.Cmd {
+// startStream shows the prompt and a placeholder for the reply, then sends
+// the prompt to the agent. A non-empty replaceFrom is the ID of the earlier
+// prompt it replaces.
+func (m *Model) startStream(prompt string, attachments []agent.Attachment, replaceFrom string) tea.Cmd {
+	// Clear input and start streaming
+	m.input.Clear()
+	m.input.Disable()
+	m.filePicker.Close()
+	m.isStreaming = true
+	m.runStarted = time.Now()
+	m.status.SetStatus(StatusThinking)
+
+	// Start activity panel with spinner
+	spinnerCmd := m.activity.SetThinking(true)
+
+	// Add placeholder for assistant response
+	m.messages.AppendMessage(agent.Message{
+		Role:        agent.RoleUser,
+		Content:     prompt,
+		Attachments: attachments,
+	})
+	m.messages.AppendMessage(agent.Message{
+		Role:    agent.RoleAssistant,
+		Content: "",
+	})
+
+	// Send to agent
+	return tea.Batch(spinnerCmd, m.sendMessage(prompt, attachments, replaceFrom))
+}
+
+func (m *Model) sendMessage(prompt string, attachments []agent.Attachment, replaceFrom string) tea.Cmd {
+	sessionID := m.sessionID
+	ignoreBudget := m.budgetApproved != "" && m.budgetApproved == m.sessionID
+	var mode string
+	if m.planning {
+		mode = config.ModePlan
+	}
+	model := m.promptModel
+	m.promptModel = ""
 	return func() tea.Msg {
 		ctx := context.Background()
 
@@ -467,37 +932,45 @@ func (m *Model) sendMessage(prompt string) tea.Cmd {
 			OnTextDelta: func(text string) error {
 				streamedContent += text
 				if m.program != nil {
-					m.program.Send(StreamTextMsg{Text: text})
+					m.program.Send(StreamTextMsg{SessionID: sessionID, Text: text})
 				}
 				return nil
 			},
 			OnToolCall: func(tc agent.ToolCall) error {
 				if m.program != nil {
-					m.program.Send(StreamToolCallMsg{ToolCall: tc})
+					m.program.Send(StreamToolCallMsg{SessionID: sessionID, ToolCall: tc})
 				}
 				return nil
 			},
 			OnToolResult: func(tr agent.ToolResult) error {
 				if m.program != nil {
-					m.program.Send(StreamToolResultMsg{ToolResult: tr})
+					m.program.Send(StreamToolResultMsg{SessionID: sessionID, ToolResult: tr})
 				}
 				return nil
 			},
 			OnComplete: func() error {
 				if m.program != nil {
-					m.program.Send(StreamCompleteMsg{})
+					m.program.Send(StreamCompleteMsg{SessionID: sessionID})
 				}
 				return nil
 			},
 			OnError: func(err error) {
 				if m.program != nil {
-					m.program.Send(StreamErrorMsg{Error: err})
+					m.program.Send(StreamErrorMsg{SessionID: sessionID, Error: err})
 				}
 			},
 		}
 
 		opts := agent.SendOptions{
-			SessionID: m.sessionID,
+			SessionID:    sessionID,
+			Attachments:  attachments,
+			ReplaceFrom:  replaceFrom,
+			IgnoreBudget: ignoreBudget,
+			Mode:         mode,
+			Model:        model,
+		}
+		if m.cfg != nil {
+			opts.ThinkingBudget = m.cfg.ThinkingBudget(config.SelectedModelTypeLarge)
 		}
 
 		debug.Auth("send_start", fmt.Sprintf("sending prompt length=%d", len(prompt)))
@@ -511,7 +984,7 @@ func (m *Model) sendMessage(prompt string) tea.Cmd {
 			newModel, factoryErr := m.modelFactory()
 			if factoryErr != nil {
 				debug.Auth("retry_failed", fmt.Sprintf("failed to rebuild model: %v", factoryErr))
-				return StreamErrorMsg{Error: fmt.Errorf("session expired, please restart: %w", err)}
+				return StreamErrorMsg{SessionID: sessionID, Error: fmt.Errorf("session expired, please restart: %w", err)}
 			}
 
 			// Swap the model - agent keeps its session history intact.
@@ -519,6 +992,7 @@ func (m *Model) sendMessage(prompt string) tea.Cmd {
 			streamedContent = "" // Reset streamed content for retry
 
 			debug.Auth("retry_attempt", "model rebuilt, retrying request")
+			opts.ReplaceFrom = "" // The first attempt already dropped the replaced messages
 			err = m.agent.Send(ctx, prompt, opts, callbacks)
 			if err != nil {
 				debug.Auth("retry_result", fmt.Sprintf("retry failed: %v", err))
@@ -527,11 +1001,14 @@ func (m *Model) sendMessage(prompt string) tea.Cmd {
 			}
 		}
 
+		if errors.Is(err, usage.ErrBudgetExceeded) {
+			return BudgetExceededMsg{SessionID: sessionID, Error: err, Prompt: prompt, Attachments: attachments, ReplaceFrom: replaceFrom, Model: model}
+		}
 		if err != nil {
-			return StreamErrorMsg{Error: err}
+			return StreamErrorMsg{SessionID: sessionID, Error: err}
 		}
 
-		return StreamCompleteMsg{}
+		return StreamCompleteMsg{SessionID: sessionID}
 	}
 }
 
@@ -550,14 +1027,30 @@ func (m *Model) View() string {
 		return m.sessionsModal.View()
 	}
 
+	if m.helpOverlay.IsVisible() {
+		return m.helpOverlay.View()
+	}
+
 	debug.Event("chat", "View", fmt.Sprintf("rendering chat width=%d height=%d inputHeight=%d statusHeight=1 msgAreaHeight=%d", m.width, m.height, m.input.Height(), m.messagesAreaHeight()))
 
 	// Set component sizes (messages height adjusts dynamically based on input, activity, and todos)
-	m.messages.SetSize(m.width, m.messagesAreaHeight())
-	m.todoPanel.SetWidth(m.width)
-	m.activity.SetWidth(m.width)
-	m.input.SetWidth(m.width)
-	m.status.SetWidth(m.width)
+	width := m.chatWidth()
+	m.messages.SetSize(width, m.messagesAreaHeight())
+	m.todoPanel.SetWidth(width)
+	m.jobsPanel.SetWidth(width)
+	m.activity.SetWidth(width)
+	m.filePicker.SetWidth(width)
+	m.historySearch.SetWidth(width)
+	m.modelSwitcher.SetWidth(width)
+	m.input.SetWidth(width)
+	m.status.SetWidth(width)
+	m.status.SetInputMode(m.input.Mode())
+	m.status.SetUnread(m.messages.HasUnread())
+	if m.isStreaming && !m.runStarted.IsZero() {
+		m.status.SetElapsed(time.Since(m.runStarted))
+	} else {
+		m.status.SetElapsed(0)
+	}
 
 	// Render components
 	messagesView := m.messages.View()
@@ -569,13 +1062,16 @@ func (m *Model) View() string {
 	// Separator - use a simple line instead of BorderBottom to avoid extra blank line
 	separator := lipgloss.NewStyle().
 		Foreground(t.Border).
-		Render(strings.Repeat("─", m.width))
+		Render(strings.Repeat("─", width))
 
 	// Build layout - include panels only if they have content
 	var parts []string
 	parts = append(parts, messagesView)
 
-	// Todo panel appears above activity panel
+	// Background jobs, then todos, then the activity of this session
+	if m.jobsPanel.IsActive() {
+		parts = append(parts, separator, m.jobsPanel.View())
+	}
 	if m.todoPanel.IsActive() {
 		parts = append(parts, separator, todoView)
 	}
@@ -584,6 +1080,17 @@ func (m *Model) View() string {
 		parts = append(parts, separator, activityView)
 	}
 
+	// File suggestions sit right above the input they complete.
+	if m.filePicker.IsVisible() {
+		parts = append(parts, m.filePicker.View())
+	}
+	if m.historySearch.IsVisible() {
+		parts = append(parts, m.historySearch.View())
+	}
+	if m.modelSwitcher.IsVisible() {
+		parts = append(parts, m.modelSwitcher.View())
+	}
+
 	// No separator before input - the input's border serves as the visual separator
 	parts = append(parts, inputView, statusView)
 
@@ -591,6 +1098,10 @@ func (m *Model) View() string {
 	debug.Event("chat", "View", fmt.Sprintf("msgViewLines=%d inputViewLines=%d statusViewLines=%d", strings.Count(messagesView, "\n")+1, strings.Count(inputView, "\n")+1, strings.Count(statusView, "\n")+1))
 
 	chatView := lipgloss.JoinVertical(lipgloss.Left, parts...)
+	if m.showFilePane() {
+		m.filePane.SetSize(m.width-width, m.height)
+		chatView = lipgloss.JoinHorizontal(lipgloss.Top, chatView, m.filePane.View())
+	}
 
 	chatLines := strings.Count(chatView, "\n") + 1
 	debug.Event("chat", "View", fmt.Sprintf("chatView lines=%d (expected=%d)", chatLines, m.height))
@@ -615,11 +1126,26 @@ func (m *Model) SetSize(width, height int) {
 	if m.modelsModal != nil {
 		m.modelsModal.SetSize(width, height)
 	}
+	m.helpOverlay.SetSize(width, height)
 	if m.sessionsModal != nil {
 		m.sessionsModal.SetSize(width, height)
 	}
 }
 
+// showFilePane reports whether the file pane is open and fits beside the chat.
+func (m *Model) showFilePane() bool {
+	return m.filePane.IsVisible() && m.width >= minFilePaneWidth
+}
+
+// chatWidth is the width of the chat column, which leaves two fifths of the
+// screen to the file pane when it is shown.
+func (m *Model) chatWidth() int {
+	if m.showFilePane() {
+		return m.width * 3 / 5 //nolint:mnd // Three fifths
+	}
+	return m.width
+}
+
 // messagesAreaHeight calculates the current height of the messages area.
 func (m *Model) messagesAreaHeight() int {
 	statusHeight := 1
@@ -631,13 +1157,20 @@ func (m *Model) messagesAreaHeight() int {
 		todoHeight++ // Add separator height
 	}
 
+	// Account for jobs panel if active (height + separator)
+	jobsHeight := m.jobsPanel.Height()
+	if jobsHeight > 0 {
+		jobsHeight++ // Add separator height
+	}
+
 	// Account for activity panel if active (height + separator)
 	activityHeight := m.activity.Height()
 	if activityHeight > 0 {
 		activityHeight++ // Add separator height
 	}
 
-	h := m.height - statusHeight - inputHeight - todoHeight - activityHeight
+	h := m.height - statusHeight - inputHeight - todoHeight - jobsHeight - activityHeight -
+		m.filePicker.Height() - m.historySearch.Height() - m.modelSwitcher.Height()
 	if h < 1 {
 		h = 1
 	}
@@ -669,12 +1202,16 @@ func (m *Model) handleAgentEvent(event pubsub.Event[events.AgentEvent]) (util.Mo
 	//nolint:exhaustive // AgentEventCancelled handled same as Complete
 	switch event.Payload.Type {
 	case events.AgentEventTextDelta:
+		m.status.SetNotice("")
 		// Update the last message (assistant response) with new text
 		if len(m.messages.messages) > 0 {
 			lastMsg := m.messages.messages[len(m.messages.messages)-1]
 			m.messages.UpdateLast(lastMsg.Content + event.Payload.TextDelta)
 		}
 
+	case events.AgentEventReasoningDelta:
+		m.messages.AppendReasoning(event.Payload.ReasoningDelta)
+
 	case events.AgentEventToolCall:
 		if event.Payload.ToolCall != nil {
 			m.activity.AddTool(event.Payload.ToolCall.Name, event.Payload.ToolCall.Input)
@@ -689,6 +1226,15 @@ func (m *Model) handleAgentEvent(event pubsub.Event[events.AgentEvent]) (util.Mo
 			}
 		}
 
+	case events.AgentEventRetrying:
+		if retry := event.Payload.Retry; retry != nil {
+			m.status.SetNotice(retryNotice(retry))
+		}
+
+	case events.AgentEventCompacted:
+		// The summary shows up when messages are refreshed on completion
+		return m, util.ReportInfo("Older messages were summarized to fit the context window")
+
 	case events.AgentEventComplete, events.AgentEventCancelled:
 		m.isStreaming = false
 		m.activity.Clear()
@@ -696,18 +1242,30 @@ func (m *Model) handleAgentEvent(event pubsub.Event[events.AgentEvent]) (util.Mo
 		m.input.Enable()
 		// Refresh messages from session to get final state
 		m.messages.SetMessages(m.agent.Sessions().GetMessages(m.sessionID))
-		return m, m.input.Focus()
+		m.refreshStatus()
+		m.finishRegenerate()
+		cmds := []tea.Cmd{m.input.Focus()}
+		if event.Payload.Type == events.AgentEventComplete {
+			cmds = append(cmds, m.notifyFinished("Reply ready"))
+		} else {
+			m.announce("Reply cancelled")
+		}
+		if info := event.Payload.Completion; info != nil {
+			cmds = append(cmds, completionNotice(*info))
+		}
+		return m, tea.Batch(cmds...)
 
 	case events.AgentEventError:
 		m.isStreaming = false
 		m.activity.Clear()
+		errText := "unknown error"
 		if event.Payload.Error != nil {
-			m.status.SetError(event.Payload.Error.Error())
-		} else {
-			m.status.SetError("unknown error")
+			errText = event.Payload.Error.Error()
 		}
+		m.status.SetError(errText)
 		m.input.Enable()
-		return m, m.input.Focus()
+		m.finishRegenerate()
+		return m, tea.Batch(m.input.Focus(), m.notifyFinished("Request failed: "+errText))
 	}
 
 	return m, nil
@@ -720,22 +1278,41 @@ func (m *Model) handleToolEvent(event pubsub.Event[events.ToolEvent]) (util.Mode
 		return m, nil
 	}
 
-	//nolint:exhaustive // ToolEventProgress only used for logging
 	switch event.Payload.Type {
 	case events.ToolEventStarted:
 		debug.Event("chat", "ToolStarted", fmt.Sprintf("tool=%s", event.Payload.ToolName))
 		m.activity.AddTool(event.Payload.ToolName, event.Payload.Input)
+		if isFileTool(event.Payload.ToolName) {
+			if path := toolFilePath(event.Payload.Input); path != "" {
+				m.fileCalls[event.Payload.ToolCallID] = path
+			}
+		}
 
 	case events.ToolEventCompleted:
 		debug.Event("chat", "ToolCompleted", fmt.Sprintf("tool=%s duration=%v", event.Payload.ToolName, event.Payload.Duration))
 		m.activity.MarkToolDone(event.Payload.ToolName)
+		if path, ok := m.fileCalls[event.Payload.ToolCallID]; ok {
+			delete(m.fileCalls, event.Payload.ToolCallID)
+			m.filePane.ShowFile(m.workingDir(), path, event.Payload.ToolCallID)
+		}
 
 	case events.ToolEventFailed:
 		debug.Event("chat", "ToolFailed", fmt.Sprintf("tool=%s error=%v", event.Payload.ToolName, event.Payload.Error))
 		m.activity.MarkToolError(event.Payload.ToolName)
+		delete(m.fileCalls, event.Payload.ToolCallID)
 
 	case events.ToolEventProgress:
 		debug.Event("chat", "ToolProgress", fmt.Sprintf("tool=%s", event.Payload.ToolName))
+		if event.Payload.Chunk != "" {
+			m.activity.AppendOutput(event.Payload.ToolName, event.Payload.Chunk)
+		}
+		if event.Payload.Step != "" || event.Payload.Progress > 0 {
+			m.activity.SetProgress(event.Payload.ToolName, event.Payload.Step, event.Payload.Progress)
+		}
+		if event.Payload.Diff != "" {
+			m.messages.SetDiff(event.Payload.ToolCallID, event.Payload.FilePath, event.Payload.Diff)
+			m.filePane.ShowDiff(event.Payload.FilePath, event.Payload.ToolCallID, event.Payload.Diff)
+		}
 	}
 
 	return m, nil
@@ -750,6 +1327,17 @@ func (m *Model) handleAuthEvent(event pubsub.Event[events.AuthEvent]) (util.Mode
 
 	case events.AuthEventTokenRefreshed:
 		debug.Auth("token_refreshed", fmt.Sprintf("provider=%s new_expires_at=%v", event.Payload.ProviderID, event.Payload.ExpiresAt))
+		// Swap in a model built with the new token. A response already
+		// streaming keeps the old one, which is still valid for a few minutes.
+		if m.modelFactory != nil && m.agent != nil {
+			newModel, err := m.modelFactory()
+			if err != nil {
+				debug.Auth("model_rebuild_failed", err.Error())
+				m.status.SetError(fmt.Sprintf("Reloading model after token refresh: %v", err))
+				return m, nil
+			}
+			m.agent.SetModel(newModel)
+		}
 
 	case events.AuthEventRefreshFailed:
 		debug.Auth("refresh_failed", fmt.Sprintf("provider=%s error=%v", event.Payload.ProviderID, event.Payload.Error))
@@ -789,6 +1377,18 @@ func (m *Model) handleTodoEvent(event pubsub.Event[events.TodoEvent]) (util.Mode
 	return m, nil
 }
 
+// restoreTodos shows the todos saved for the current session. Later changes
+// arrive as todo events.
+func (m *Model) restoreTodos() {
+	m.todoPanel.Clear()
+	if m.agent == nil || m.agent.Todos() == nil {
+		return
+	}
+	if todos := m.agent.Todos().Get(m.sessionID); len(todos) > 0 {
+		m.todoPanel.SetTodos(todos)
+	}
+}
+
 // switchSession switches to a different session.
 func (m *Model) switchSession(sessionID string) (util.Model, tea.Cmd) {
 	if m.agent == nil {
@@ -809,18 +1409,27 @@ func (m *Model) switchSession(sessionID string) (util.Model, tea.Cmd) {
 
 	// Update the chat state
 	m.sessionID = sessionID
+	m.activity.SetSession(sessionID)
 	m.messages.SetMessages(sess.Messages)
 
-	// Clear activity and todo panels
+	// Clear activity and show the session's own todos, and its reply in
+	// progress if it is running elsewhere
 	m.activity.Clear()
-	m.todoPanel.Clear()
+	m.restoreTodos()
+	m.commandRegistry.RegisterCustom(commands.Load(m.workingDir()))
+	m.refreshStatus()
+	m.withdrawOffers()
+	m.holdSession()
+	replay := m.replaySession()
 
 	title := sess.Title
 	if title == "" || title == "New Session" {
 		title = fmt.Sprintf("Session %s...", sessionID[:8])
 	}
 
-	return m, util.ReportSuccess(fmt.Sprintf("Switched to: %s", title))
+	m.offerRecovery()
+
+	return m, tea.Batch(replay, util.ReportSuccess(fmt.Sprintf("Switched to: %s", title)))
 }
 
 // generateSessionTitle requests the LLM to generate a title for the session.
@@ -845,11 +1454,21 @@ func (m *Model) exportSessionToMarkdown(sessionID string) (util.Model, tea.Cmd)
 		return m, util.ReportError(fmt.Errorf("session not found: %s", sessionID))
 	}
 
-	// Build markdown content
+	// Write to file
+	filename := fmt.Sprintf("session-%s.md", sessionID[:8])
+	if err := writeFile(filename, sessionMarkdown(sess)); err != nil {
+		return m, util.ReportError(fmt.Errorf("failed to export: %w", err))
+	}
+
+	return m, util.ReportSuccess(fmt.Sprintf("Exported to %s", filename))
+}
+
+// sessionMarkdown renders a session as a markdown transcript.
+func sessionMarkdown(sess *agent.Session) string {
 	var sb strings.Builder
 	title := sess.Title
 	if title == "" || title == "New Session" {
-		title = fmt.Sprintf("Session %s", sessionID[:8])
+		title = fmt.Sprintf("Session %s", sess.ID[:8])
 	}
 
 	sb.WriteString(fmt.Sprintf("# %s\n\n", title))
@@ -862,6 +1481,9 @@ func (m *Model) exportSessionToMarkdown(sessionID string) (util.Model, tea.Cmd)
 			sb.WriteString("## You\n\n")
 			sb.WriteString(msg.Content)
 			sb.WriteString("\n\n")
+			for _, att := range msg.Attachments {
+				sb.WriteString(fmt.Sprintf("*[%s: %s]*\n\n", attachmentKind(att), att.Filename))
+			}
 		case agent.RoleAssistant:
 			sb.WriteString("## Assistant\n\n")
 			sb.WriteString(msg.Content)
@@ -876,16 +1498,50 @@ func (m *Model) exportSessionToMarkdown(sessionID string) (util.Model, tea.Cmd)
 		}
 	}
 
-	// Write to file
-	filename := fmt.Sprintf("session-%s.md", sessionID[:8])
-	if err := writeFile(filename, sb.String()); err != nil {
-		return m, util.ReportError(fmt.Errorf("failed to export: %w", err))
-	}
-
-	return m, util.ReportSuccess(fmt.Sprintf("Exported to %s", filename))
+	return sb.String()
 }
 
 // writeFile writes content to a file.
 func writeFile(filename, content string) error {
 	return os.WriteFile(filename, []byte(content), 0o644) //nolint:gosec // User-initiated export
 }
+
+// retryNotice formats a pending retry for the status bar, e.g. "Retrying in 3s… (2/4)".
+func retryNotice(retry *events.RetryInfo) string {
+	secs := int(math.Ceil(retry.Delay.Seconds()))
+	return fmt.Sprintf("Retrying in %ds… (%d/%d)", secs, retry.Attempt, retry.MaxAttempts)
+}
+
+// continuePrompt is sent by /continue to resume a reply that was cut off.
+const continuePrompt = "Your last reply was cut off by the output token limit. " +
+	"Continue exactly where you left off, without repeating anything."
+
+// refreshStatus updates the session details in the status bar. It runs
+// when they may have changed: at start, after each run, and when the session
+// or its working directory changes.
+func (m *Model) refreshStatus() {
+	if m.agent == nil || m.sessionID == "" {
+		return
+	}
+	if sess, ok := m.agent.Sessions().Get(m.sessionID); ok {
+		m.status.SetSessionTitle(sess.Title)
+	}
+	m.status.SetContext(m.agent.ContextUsage(m.sessionID))
+	totals, err := m.agent.Usage().Session(context.Background(), m.sessionID)
+	if err != nil {
+		debug.Error("chat", err, "reading session usage")
+	}
+	m.status.SetUsage(totals.Input+totals.Output, totals.Cost)
+	m.status.SetBranch(session.Branch(m.workingDir()))
+}
+
+// completionNotice warns when a reply did not end normally, or returns nil.
+func completionNotice(info events.CompletionInfo) tea.Cmd {
+	switch {
+	case info.Truncated():
+		return util.ReportWarn("The reply was cut off by the max tokens limit; type /continue to resume it")
+	case info.FinishReason == events.FinishReasonContentFilter:
+		return util.ReportWarn("The reply was stopped by the provider's content filter")
+	}
+	return nil
+}
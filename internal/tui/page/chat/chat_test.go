@@ -0,0 +1,40 @@
+package chat
+
+import (
+	"testing"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+)
+
+func TestChat_OtherSessionMessages(t *testing.T) {
+	ag := agent.New(agent.Config{})
+	m := New(ag)
+	m.Init()
+	m.startStream("hello", nil, "")
+
+	// A reply finishing in another tab's session leaves this one streaming.
+	m.Update(StreamCompleteMsg{SessionID: "another"})
+	if !m.IsStreaming() {
+		t.Fatal("a message for another session should be ignored")
+	}
+	m.Update(StreamTextMsg{SessionID: m.SessionID(), Text: "hi"})
+	if got := m.messages.messages[len(m.messages.messages)-1].Content; got != "hi" {
+		t.Errorf("last message = %q, want the streamed text", got)
+	}
+	m.Update(StreamCompleteMsg{SessionID: m.SessionID()})
+	if m.IsStreaming() {
+		t.Error("the session's own completion should end streaming")
+	}
+}
+
+func TestSessionOf(t *testing.T) {
+	if id, ok := SessionOf(StreamErrorMsg{SessionID: "s1"}); !ok || id != "s1" {
+		t.Errorf("SessionOf(StreamErrorMsg) = %q, %v", id, ok)
+	}
+	if id, ok := SessionOf(SpinnerTickMsg{SessionID: "s2"}); !ok || id != "s2" {
+		t.Errorf("SessionOf(SpinnerTickMsg) = %q, %v", id, ok)
+	}
+	if _, ok := SessionOf(ContinueMsg{}); ok {
+		t.Error("SessionOf(ContinueMsg) should report no session")
+	}
+}
@@ -1,10 +1,15 @@
 package chat
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 
 	tea "charm.land/bubbletea/v2"
 
+	"github.com/guilhermegouw/cdd/internal/commands"
+	"github.com/guilhermegouw/cdd/internal/contextfiles"
+	"github.com/guilhermegouw/cdd/internal/debug"
 	"github.com/guilhermegouw/cdd/internal/tui/util"
 )
 
@@ -22,6 +27,92 @@ type (
 	// CloseSessionsModalMsg requests closing the sessions modal.
 	CloseSessionsModalMsg struct{}
 
+	// TabMsg requests opening, closing or moving between session tabs.
+	TabMsg struct {
+		Args []string
+	}
+
+	// BackgroundMsg requests running a prompt as a background job.
+	BackgroundMsg struct {
+		Args []string
+	}
+
+	// JobsMsg requests listing the background jobs, or opening, cancelling
+	// or clearing them.
+	JobsMsg struct {
+		Args []string
+	}
+
+	// ModelMsg requests switching the large model, or picking one.
+	ModelMsg struct {
+		Args []string
+	}
+
+	// HandoffMsg requests handing the session over to another model.
+	HandoffMsg struct {
+		Args []string
+	}
+
+	// ShowContextMsg requests listing the project context files in the system prompt.
+	ShowContextMsg struct{}
+
+	// StatsMsg requests a summary of the session's messages, tools and cost.
+	StatsMsg struct{}
+
+	// AttachMsg requests attaching an image to the next message.
+	AttachMsg struct {
+		Args []string
+	}
+
+	// ThinkingMsg requests expanding or collapsing the model's reasoning.
+	ThinkingMsg struct {
+		Args []string
+	}
+
+	// TranscriptMsg requests reading the session as plain text in the pager.
+	TranscriptMsg struct{}
+
+	// RenderMsg requests showing or changing how replies are rendered.
+	RenderMsg struct {
+		Args []string
+	}
+
+	// ContinueMsg asks the model to carry on from a reply that was cut off.
+	ContinueMsg struct{}
+
+	// SystemMsg requests showing or replacing the session's system prompt.
+	SystemMsg struct {
+		Args []string
+	}
+
+	// ModeMsg requests listing the agent modes or switching to one.
+	ModeMsg struct {
+		Args []string
+	}
+
+	// PlanMsg requests turning planning first on or off, or planning a prompt.
+	PlanMsg struct {
+		Args []string
+	}
+
+	// CdMsg requests showing or changing the session's working directory.
+	CdMsg struct {
+		Args []string
+	}
+
+	// ShareMsg requests publishing the session and copying its link.
+	ShareMsg struct{}
+
+	// UndoMsg requests reverting the agent's file changes in this session.
+	UndoMsg struct {
+		Args []string
+	}
+
+	// CustomCommandMsg sends the prompt of a custom command from .cdd/commands.
+	CustomCommandMsg struct {
+		Prompt string
+	}
+
 	// UnknownCommandMsg indicates an unknown slash command was entered.
 	UnknownCommandMsg struct {
 		Command string
@@ -33,6 +124,7 @@ type Command struct {
 	Name        string
 	Description string
 	Handler     func(args []string) tea.Msg
+	custom      bool // Loaded from .cdd/commands
 }
 
 // CommandRegistry holds registered slash commands.
@@ -53,12 +145,120 @@ func NewCommandRegistry() *CommandRegistry {
 		Handler:     func(args []string) tea.Msg { return OpenModelsModalMsg{} },
 	})
 
+	r.Register(Command{
+		Name:        "model",
+		Description: "Switch to a model by connection/model, provider/model or alias, or pick one",
+		Handler:     func(args []string) tea.Msg { return ModelMsg{Args: args} },
+	})
+
 	r.Register(Command{
 		Name:        "sessions",
 		Description: "Manage conversation sessions",
 		Handler:     func(args []string) tea.Msg { return OpenSessionsModalMsg{} },
 	})
 
+	r.Register(Command{
+		Name:        "tab",
+		Description: "Open a session in a new tab, or close or switch tabs (new, close, next, prev)",
+		Handler:     func(args []string) tea.Msg { return TabMsg{Args: args} },
+	})
+
+	r.Register(Command{
+		Name:        "bg",
+		Description: "Run a prompt as a background job in a session of its own",
+		Handler:     func(args []string) tea.Msg { return BackgroundMsg{Args: args} },
+	})
+
+	r.Register(Command{
+		Name:        "jobs",
+		Description: "List background jobs, or open, cancel or clear them (open N, cancel N, clear)",
+		Handler:     func(args []string) tea.Msg { return JobsMsg{Args: args} },
+	})
+
+	r.Register(Command{
+		Name:        "handoff",
+		Description: "Have the current model summarize the session, then continue it on another (connection/model)",
+		Handler:     func(args []string) tea.Msg { return HandoffMsg{Args: args} },
+	})
+
+	r.Register(Command{
+		Name:        "context",
+		Description: "Show the project context files (CDD.md, AGENTS.md) loaded into the system prompt",
+		Handler:     func(args []string) tea.Msg { return ShowContextMsg{} },
+	})
+
+	r.Register(Command{
+		Name:        "stats",
+		Description: "Show the session's message counts, tool calls, tokens and cost",
+		Handler:     func(args []string) tea.Msg { return StatsMsg{} },
+	})
+
+	r.Register(Command{
+		Name:        "attach",
+		Description: "Attach an image to the next message (/attach clear removes attachments)",
+		Handler:     func(args []string) tea.Msg { return AttachMsg{Args: args} },
+	})
+
+	r.Register(Command{
+		Name:        "thinking",
+		Description: "Show or hide the model's reasoning (/thinking on|off)",
+		Handler:     func(args []string) tea.Msg { return ThinkingMsg{Args: args} },
+	})
+
+	r.Register(Command{
+		Name:        "render",
+		Description: "Show or change how replies are rendered (/render style light, /render wrap 100, /render code github)",
+		Handler:     func(args []string) tea.Msg { return RenderMsg{Args: args} },
+	})
+
+	r.Register(Command{
+		Name:        "transcript",
+		Description: "Read the session from start to end as plain text in the pager",
+		Handler:     func(args []string) tea.Msg { return TranscriptMsg{} },
+	})
+
+	r.Register(Command{
+		Name:        "continue",
+		Description: "Ask the model to continue a reply that was cut off",
+		Handler:     func(args []string) tea.Msg { return ContinueMsg{} },
+	})
+
+	r.Register(Command{
+		Name:        "undo",
+		Description: "Revert the agent's last file change (/undo N or /undo all for more)",
+		Handler:     func(args []string) tea.Msg { return UndoMsg{Args: args} },
+	})
+
+	r.Register(Command{
+		Name:        "system",
+		Description: "Show the system prompt, or set it for this session (/system TEXT, /system file PATH, /system reset)",
+		Handler:     func(args []string) tea.Msg { return SystemMsg{Args: args} },
+	})
+
+	r.Register(Command{
+		Name:        "mode",
+		Description: "List the agent modes, or switch to one (/mode plan, /mode code, /mode review)",
+		Handler:     func(args []string) tea.Msg { return ModeMsg{Args: args} },
+	})
+
+	r.Register(Command{
+		Name:        "plan",
+		Description: "Plan a prompt and approve it before it runs (/plan TEXT), or plan every prompt (/plan on|off)",
+		Handler:     func(args []string) tea.Msg { return PlanMsg{Args: args} },
+	})
+
+	r.Register(Command{
+		Name:        "cd",
+		Description: "Show the session's working directory, or change it (/cd [--trust] PATH)",
+		Handler:     func(args []string) tea.Msg { return CdMsg{Args: args} },
+	})
+
+	r.Register(Command{
+		Name:        "share",
+		Description: "Publish this session, secrets redacted, as a gist or paste and copy the link",
+		Handler:     func(args []string) tea.Msg { return ShareMsg{} },
+	})
+
 	return r
 }
 
@@ -67,6 +267,28 @@ func (r *CommandRegistry) Register(cmd Command) {
 	r.commands[cmd.Name] = cmd
 }
 
+// RegisterCustom adds commands loaded from .cdd/commands, replacing custom
+// commands registered before. Built-in commands keep their names.
+func (r *CommandRegistry) RegisterCustom(custom []commands.Command) {
+	for name, cmd := range r.commands {
+		if cmd.custom {
+			delete(r.commands, name)
+		}
+	}
+	for _, c := range custom {
+		if _, ok := r.commands[c.Name]; ok {
+			debug.Log("[COMMANDS] %s: /%s is a built-in command", c.Path, c.Name)
+			continue
+		}
+		r.Register(Command{
+			Name:        c.Name,
+			Description: c.Description,
+			Handler:     func(args []string) tea.Msg { return CustomCommandMsg{Prompt: c.Expand(args)} },
+			custom:      true,
+		})
+	}
+}
+
 // Parse attempts to parse input as a slash command.
 // Returns the command message and true if it's a command, nil and false otherwise.
 func (r *CommandRegistry) Parse(input string) (tea.Msg, bool) {
@@ -93,12 +315,13 @@ func (r *CommandRegistry) Parse(input string) (tea.Msg, bool) {
 	return cmd.Handler(args), true
 }
 
-// GetCommands returns all registered commands.
+// GetCommands returns all registered commands, sorted by name.
 func (r *CommandRegistry) GetCommands() []Command {
 	cmds := make([]Command, 0, len(r.commands))
 	for _, cmd := range r.commands {
 		cmds = append(cmds, cmd)
 	}
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
 	return cmds
 }
 
@@ -116,3 +339,44 @@ func (m *Model) parseCommand(input string) tea.Cmd {
 
 	return util.CmdHandler(msg)
 }
+
+// contextReport describes the project context files for the /context command.
+func contextReport(files []contextfiles.File) string {
+	if len(files) == 0 {
+		return "No project context files loaded. Add a CDD.md or AGENTS.md to the project, " +
+			"or list files under options.context_paths in cdd.json."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Project context (%d file%s, in order of precedence):", len(files), pluralize(len(files)))
+	for i := range files {
+		lines := strings.Count(strings.TrimRight(files[i].Content, "\n"), "\n") + 1
+		fmt.Fprintf(&b, "\n  %s (%d line%s", files[i].Path, lines, pluralize(lines))
+		if files[i].Truncated {
+			b.WriteString(", truncated")
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+// handleThinking runs the /thinking command. Without arguments it toggles.
+func (m *Model) handleThinking(args []string) tea.Cmd {
+	show := !m.messages.ShowThinking()
+	if len(args) > 0 {
+		switch strings.ToLower(args[0]) {
+		case "on":
+			show = true
+		case "off":
+			show = false
+		default:
+			return util.ReportWarn("Usage: /thinking [on|off]")
+		}
+	}
+
+	m.messages.SetShowThinking(show)
+	if show {
+		return util.ReportInfo("Showing the model's reasoning")
+	}
+	return util.ReportInfo("Hiding the model's reasoning")
+}
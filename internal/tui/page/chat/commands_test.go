@@ -0,0 +1,316 @@
+package chat
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/commands"
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/contextfiles"
+	"github.com/guilhermegouw/cdd/internal/trust"
+)
+
+func TestCommandRegistry_Context(t *testing.T) {
+	msg, ok := NewCommandRegistry().Parse("/context")
+	if !ok {
+		t.Fatal("expected /context to be a command")
+	}
+	if _, isShow := msg.(ShowContextMsg); !isShow {
+		t.Errorf("Parse(/context) = %T, want ShowContextMsg", msg)
+	}
+}
+
+func TestCommandRegistry_Custom(t *testing.T) {
+	r := NewCommandRegistry()
+	r.RegisterCustom([]commands.Command{
+		{Name: "review", Description: "Review a file", Template: "Review $1 carefully."},
+		{Name: "context", Template: "Shadowed by the built-in."},
+	})
+
+	msg, ok := r.Parse("/review main.go")
+	if custom, isCustom := msg.(CustomCommandMsg); !ok || !isCustom || custom.Prompt != "Review main.go carefully." {
+		t.Errorf("Parse(/review main.go) = %#v", msg)
+	}
+	if msg, _ := r.Parse("/context"); msg != (ShowContextMsg{}) {
+		t.Errorf("built-in /context should win over a custom command, got %#v", msg)
+	}
+
+	r.RegisterCustom(nil)
+	if msg, _ := r.Parse("/review"); msg != (UnknownCommandMsg{Command: "review"}) {
+		t.Errorf("reloading should drop removed custom commands, got %#v", msg)
+	}
+}
+
+func TestChat_CommandPicker(t *testing.T) {
+	m := New(nil)
+	m.SetSize(80, 24)
+	m.commandRegistry.RegisterCustom([]commands.Command{
+		{Name: "review", Description: "Review a file", Template: "Review $ARGUMENTS"},
+	})
+
+	for _, r := range "/rev" {
+		m.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if !m.filePicker.IsCommands() || m.filePicker.Selected() != "review" {
+		t.Fatalf("expected the picker to suggest /review, commands=%v selected=%q",
+			m.filePicker.IsCommands(), m.filePicker.Selected())
+	}
+	m.filePicker.SetWidth(80)
+	if view := m.filePicker.View(); !strings.Contains(view, "Review a file") {
+		t.Errorf("picker should show the command description:\n%s", view)
+	}
+
+	m.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	if got := m.input.Value(); got != "/review " {
+		t.Errorf("input = %q, want the completed command", got)
+	}
+	if m.filePicker.IsVisible() {
+		t.Error("picker should close once the command is complete")
+	}
+}
+
+func TestContextReport(t *testing.T) {
+	if got := contextReport(nil); !strings.Contains(got, "No project context files") {
+		t.Errorf("contextReport(nil) = %q", got)
+	}
+
+	got := contextReport([]contextfiles.File{
+		{Path: "/p/CDD.md", Content: "one\ntwo\n"},
+		{Path: "/p/AGENTS.md", Content: "x", Truncated: true},
+	})
+	for _, want := range []string{"2 files", "/p/CDD.md (2 lines)", "/p/AGENTS.md (1 line, truncated)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("contextReport() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestHandleThinking(t *testing.T) {
+	m := New(nil)
+
+	if m.handleThinking(nil); !m.messages.ShowThinking() {
+		t.Error("/thinking should toggle reasoning on")
+	}
+	if m.handleThinking([]string{"off"}); m.messages.ShowThinking() {
+		t.Error("/thinking off should hide reasoning")
+	}
+	if m.handleThinking([]string{"ON"}); !m.messages.ShowThinking() {
+		t.Error("/thinking ON should show reasoning")
+	}
+	if m.handleThinking([]string{"maybe"}); !m.messages.ShowThinking() {
+		t.Error("an invalid argument should leave the setting alone")
+	}
+}
+
+func TestHandleRender(t *testing.T) {
+	m := New(nil)
+	cfg := &config.Config{Options: &config.Options{Markdown: &config.MarkdownOptions{Style: "light", WordWrap: 90}}}
+	m.SetConfig(cfg, nil)
+
+	m.handleRender([]string{"style", "NoTTY"})
+	m.handleRender([]string{"wrap", "72"})
+	m.handleRender([]string{"code", "monokai"})
+	want := config.MarkdownOptions{Style: config.MarkdownStyleNoTTY, WordWrap: 72, CodeTheme: "monokai"}
+	if got := m.messages.RenderOptions(); got != want {
+		t.Errorf("RenderOptions() = %+v, want %+v", got, want)
+	}
+
+	for _, args := range [][]string{{"style", "solarized"}, {"wrap", "0"}, {"code", "no-such-theme"}, {"size", "big"}} {
+		m.handleRender(args)
+	}
+	if got := m.messages.RenderOptions(); got != want {
+		t.Errorf("invalid settings changed the options to %+v", got)
+	}
+
+	m.handleRender([]string{"wrap", "off"})
+	m.handleRender([]string{"code", "default"})
+	if got := m.messages.RenderOptions(); got.WordWrap != 0 || got.CodeTheme != "" {
+		t.Errorf("RenderOptions() = %+v, want no wrap column or code theme", got)
+	}
+
+	m.handleRender([]string{"reset"})
+	if got := m.messages.RenderOptions(); got != (config.MarkdownOptions{Style: "light", WordWrap: 90}) {
+		t.Errorf("/render reset gave %+v, want the configured options", got)
+	}
+
+	m.handleRender(nil)
+	if msgs := m.messages.messages; len(msgs) != 1 || !strings.Contains(msgs[0].Content, "Style: light") {
+		t.Errorf("/render should report the settings, got %+v", msgs)
+	}
+}
+
+func TestHandleSystem(t *testing.T) {
+	ag := agent.New(agent.Config{SystemPrompt: "Default prompt", WorkingDir: t.TempDir()})
+	m := New(ag)
+	m.sessionID = ag.Sessions().Create("test").ID
+
+	m.handleSystem([]string{"Answer", "in", "French."})
+	if got := ag.SystemPrompt(m.sessionID); got != "Answer in French." {
+		t.Errorf("/system TEXT set %q", got)
+	}
+
+	path := filepath.Join(ag.WorkingDir(), "prompt.md")
+	if err := os.WriteFile(path, []byte("Line one.\nLine two.\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m.handleSystem([]string{"file", "prompt.md"})
+	if got := ag.SystemPrompt(m.sessionID); got != "Line one.\nLine two." {
+		t.Errorf("/system file set %q", got)
+	}
+
+	m.handleSystem(nil)
+	msgs := m.messages.messages
+	if len(msgs) == 0 || !strings.Contains(msgs[len(msgs)-1].Content, "set for this session") {
+		t.Errorf("/system should show the session's prompt, got %v", msgs)
+	}
+
+	m.handleSystem([]string{"reset"})
+	if got := ag.SystemPrompt(m.sessionID); got != "Default prompt" {
+		t.Errorf("/system reset left %q", got)
+	}
+}
+
+func TestHandleMode(t *testing.T) {
+	ag := agent.New(agent.Config{
+		Modes: []agent.Mode{{Name: "code", Description: "Write code"}, {Name: "plan", Description: "Plan only"}},
+		Mode:  "code",
+	})
+	m := New(ag)
+
+	m.handleMode(nil)
+	msgs := m.messages.messages
+	if len(msgs) == 0 || !strings.Contains(msgs[len(msgs)-1].Content, "* code - Write code") {
+		t.Errorf("/mode should list the modes with the current one marked, got %v", msgs)
+	}
+
+	m.handleMode([]string{"Plan"})
+	if ag.Mode() != "plan" || m.status.agentMode != "plan" {
+		t.Errorf("mode = %q, status = %q, want plan in both", ag.Mode(), m.status.agentMode)
+	}
+	m.handleMode([]string{"nope"})
+	if ag.Mode() != "plan" {
+		t.Errorf("an unknown mode should leave %q in place, got %q", "plan", ag.Mode())
+	}
+}
+
+func TestHandleCd(t *testing.T) {
+	ag := agent.New(agent.Config{WorkingDir: t.TempDir()})
+	m := New(ag)
+	m.sessionID = ag.Sessions().Create("test").ID
+
+	sub := filepath.Join(ag.WorkingDir(), "sub")
+	if err := os.Mkdir(sub, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "notes.txt"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m.handleCd([]string{"sub"})
+	if got := ag.SessionWorkingDir(m.sessionID); got != sub {
+		t.Errorf("/cd sub moved to %q, want %q", got, sub)
+	}
+	m.handleCd([]string{"notes.txt"})
+	if got := ag.SessionWorkingDir(m.sessionID); got != sub {
+		t.Errorf("/cd to a file should be refused, moved to %q", got)
+	}
+
+	m.handleCd([]string{".."})
+	if sess, _ := ag.Sessions().Get(m.sessionID); sess.WorkingDir != "" {
+		t.Errorf("going back to the start directory should store the default, got %q", sess.WorkingDir)
+	}
+}
+
+func TestHandleCd_Trust(t *testing.T) {
+	ag := agent.New(agent.Config{WorkingDir: t.TempDir()})
+	m := New(ag)
+	m.sessionID = ag.Sessions().Create("test").ID
+	other := t.TempDir()
+
+	m.handleCd([]string{other})
+	if got := ag.SessionWorkingDir(m.sessionID); got != ag.WorkingDir() {
+		t.Errorf("/cd without a trust list should stay in the start directory, moved to %q", got)
+	}
+
+	store, err := trust.Load(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetTrust(store)
+	m.handleCd([]string{other})
+	if got := ag.SessionWorkingDir(m.sessionID); got != ag.WorkingDir() {
+		t.Errorf("/cd to an untrusted directory should be refused, moved to %q", got)
+	}
+
+	m.handleCd([]string{"--trust", other})
+	if got := ag.SessionWorkingDir(m.sessionID); got != other || !store.IsTrusted(other) {
+		t.Errorf("/cd --trust moved to %q, trusted %v; want %q trusted", got, store.IsTrusted(other), other)
+	}
+}
+
+func TestHandleShare(t *testing.T) {
+	var uploaded string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body) //nolint:errcheck // Checked below
+		uploaded = string(data)
+		_, _ = io.WriteString(w, "https://paste.example/1") //nolint:errcheck // Test server
+	}))
+	defer server.Close()
+
+	ag := agent.New(agent.Config{WorkingDir: t.TempDir()})
+	m := New(ag)
+	m.sessionID = ag.Sessions().Create("test").ID
+	m.cfg = &config.Config{
+		Connections:  []config.Connection{{ID: "work", ProviderID: "openai", APIKey: "configured-api-key"}},
+		Integrations: &config.IntegrationsConfig{Paste: &config.PasteIntegration{URL: server.URL}},
+	}
+	ag.Sessions().AddMessage(m.sessionID, agent.Message{Role: agent.RoleUser, Content: "my key is configured-api-key"})
+
+	batch, ok := m.handleShare()().(tea.BatchMsg)
+	if !ok {
+		t.Fatal("/share should report progress and upload")
+	}
+	var shared sharedMsg
+	for _, cmd := range batch {
+		if msg, ok := cmd().(sharedMsg); ok {
+			shared = msg
+		}
+	}
+	if shared.err != nil || shared.url != "https://paste.example/1" {
+		t.Fatalf("/share = %q, %v; want the paste URL", shared.url, shared.err)
+	}
+	if strings.Contains(uploaded, "configured-api-key") || !strings.Contains(uploaded, "my key is [REDACTED]") {
+		t.Errorf("the configured key should be redacted from the upload:\n%s", uploaded)
+	}
+}
+
+func TestChat_Stats(t *testing.T) {
+	ag := agent.New(agent.Config{})
+	m := New(ag)
+	m.Init()
+	m.messages.SetSize(80, 30)
+	ag.Sessions().AddMessages(m.sessionID, []agent.Message{
+		{ID: "u1", Role: agent.RoleUser, Content: "run the tests"},
+		{ID: "a1", Role: agent.RoleAssistant, ToolCalls: []agent.ToolCall{{ID: "c1", Name: "bash", Input: `{"command":"go test"}`}}},
+	})
+
+	if msg, _ := NewCommandRegistry().Parse("/stats"); msg != (StatsMsg{}) {
+		t.Fatalf("Parse(/stats) = %#v, want StatsMsg", msg)
+	}
+	m.Update(StatsMsg{})
+	content := ansi.Strip(m.messages.renderedContent)
+	for _, want := range []string{"Session stats", "Messages: 2", "Tool calls: 1"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("/stats should show %q:\n%s", want, content)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/atotto/clipboard"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// typing reports whether keys go to the input as text, in which case the
+// single-letter copy bindings are left alone. They work while a reply
+// streams, while picking a message, and in vim normal mode with an empty
+// input.
+func (m *Model) typing() bool {
+	if !m.input.IsEnabled() || m.picking {
+		return false
+	}
+	return m.input.Mode() != vimNormal.String() || m.input.Value() != ""
+}
+
+// lastReply returns the latest assistant message with text.
+func (m *Model) lastReply() (agent.Message, bool) {
+	for i := len(m.messages.messages) - 1; i >= 0; i-- {
+		if msg := m.messages.messages[i]; msg.Role == agent.RoleAssistant && strings.TrimSpace(msg.Content) != "" {
+			return msg, true
+		}
+	}
+	return agent.Message{}, false
+}
+
+// copyLastReply copies the text of the latest reply.
+func (m *Model) copyLastReply() tea.Cmd {
+	reply, ok := m.lastReply()
+	if !ok {
+		return util.ReportInfo("No reply to copy")
+	}
+	return copyToClipboard(reply.Content, "Copied the last reply")
+}
+
+// copyNextCodeBlock copies a code block of the latest reply, starting with
+// the first and moving to the next one on each call.
+func (m *Model) copyNextCodeBlock() tea.Cmd {
+	reply, ok := m.lastReply()
+	if !ok {
+		return util.ReportInfo("No reply to copy")
+	}
+	blocks := codeBlocks(reply.Content)
+	if len(blocks) == 0 {
+		return util.ReportInfo("No code blocks in the last reply")
+	}
+
+	next := 0
+	if reply.ID == m.copiedCodeFrom {
+		next = (m.copiedCode + 1) % len(blocks)
+	}
+	m.copiedCode, m.copiedCodeFrom = next, reply.ID
+	return copyToClipboard(blocks[next], fmt.Sprintf("Copied code block %d of %d", next+1, len(blocks)))
+}
+
+// copyToClipboard copies text through OSC 52 and the native clipboard, like
+// a mouse selection, and reports notice.
+func copyToClipboard(text, notice string) tea.Cmd {
+	return tea.Batch(
+		tea.SetClipboard(text),
+		func() tea.Msg {
+			//nolint:errcheck // Best effort clipboard write; OSC 52 is primary
+			clipboard.WriteAll(text)
+			return nil
+		},
+		util.ReportInfo(notice),
+	)
+}
+
+// codeBlocks returns the contents of the ``` fenced blocks in content. A
+// block left open at the end, as in a cut-off reply, runs to the end.
+func codeBlocks(content string) []string {
+	var blocks []string
+	var block []string
+	inBlock := false
+	for line := range strings.SplitSeq(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if inBlock {
+				blocks = append(blocks, strings.Join(block, "\n"))
+			}
+			inBlock, block = !inBlock, nil
+			continue
+		}
+		if inBlock {
+			block = append(block, line)
+		}
+	}
+	if inBlock && len(block) > 0 {
+		blocks = append(blocks, strings.Join(block, "\n"))
+	}
+	return blocks
+}
@@ -0,0 +1,58 @@
+package chat
+
+import (
+	"slices"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+)
+
+func TestCodeBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"none", "Just text.", nil},
+		{"two blocks", "Run:\n```sh\ngo test ./...\n```\nthen\n```go\nx := 1\ny := 2\n```", []string{"go test ./...", "x := 1\ny := 2"}},
+		{"indented fence", "  ```\n  a\n  ```", []string{"  a"}},
+		{"cut off", "```go\nfunc main() {", []string{"func main() {"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := codeBlocks(tt.content); !slices.Equal(got, tt.want) {
+				t.Errorf("codeBlocks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChat_CopyKeys(t *testing.T) {
+	m := New(nil)
+	m.SetSize(80, 24)
+	m.messages.SetMessages([]agent.Message{
+		{ID: "u1", Role: agent.RoleUser, Content: "show me"},
+		{ID: "a1", Role: agent.RoleAssistant, Content: "```\none\n```\n```\ntwo\n```"},
+	})
+
+	m.Update(tea.KeyPressMsg{Code: 'y', Text: "y"})
+	if m.input.Value() != "y" {
+		t.Fatalf("y should be typed into the input, got %q", m.input.Value())
+	}
+	m.input.Clear()
+
+	m.input.Disable()
+	if _, cmd := m.Update(tea.KeyPressMsg{Code: 'y', Text: "y"}); cmd == nil {
+		t.Error("y should copy the last reply while the input is disabled")
+	}
+	var copied []int
+	for range 3 {
+		m.Update(tea.KeyPressMsg{Code: 'y', Text: "Y", Mod: tea.ModShift})
+		copied = append(copied, m.copiedCode)
+	}
+	if !slices.Equal(copied, []int{0, 1, 0}) || m.copiedCodeFrom != "a1" {
+		t.Errorf("Y copied blocks %v of %q, want 0, 1 then back to 0", copied, m.copiedCodeFrom)
+	}
+}
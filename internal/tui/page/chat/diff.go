@@ -0,0 +1,62 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/guilhermegouw/cdd/internal/tui/styles"
+)
+
+// maxDiffPreviewLines caps how many diff lines are shown inline in the chat.
+const maxDiffPreviewLines = 40
+
+// renderDiff renders a unified diff with added, removed, and hunk lines colored.
+func renderDiff(title, diff string, width int) string {
+	t := styles.CurrentTheme()
+
+	lines := strings.Split(strings.TrimRight(diff, "\n"), "\n")
+	hidden := 0
+	if len(lines) > maxDiffPreviewLines {
+		hidden = len(lines) - maxDiffPreviewLines
+		lines = lines[:maxDiffPreviewLines]
+	}
+
+	parts := make([]string, 0, len(lines)+2)
+	parts = append(parts, t.S().Muted.Bold(true).Render(title))
+	for _, line := range lines {
+		parts = append(parts, diffLine(ansi.Truncate(strings.ReplaceAll(line, "\t", "    "), width, "…")))
+	}
+	if hidden > 0 {
+		parts = append(parts, t.S().Subtle.Render(fmt.Sprintf("… %d more line%s", hidden, pluralize(hidden))))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
+
+// diffTitle heads the diff of a file edited by a tool.
+func diffTitle(path string) string {
+	if styles.Accessible() {
+		return "Changed " + path
+	}
+	return "✎ " + path
+}
+
+// diffLine colors one line of a unified diff by its prefix.
+func diffLine(line string) string {
+	t := styles.CurrentTheme()
+	switch {
+	case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		return t.S().Subtle.Render(line)
+	case strings.HasPrefix(line, "@@"):
+		return t.S().Info.Render(line)
+	case strings.HasPrefix(line, "+"):
+		return t.S().Success.Render(line)
+	case strings.HasPrefix(line, "-"):
+		return t.S().Error.Render(line)
+	default:
+		return t.S().Muted.Render(line)
+	}
+}
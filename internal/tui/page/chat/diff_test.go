@@ -0,0 +1,59 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+)
+
+func TestRenderDiff(t *testing.T) {
+	diff := "--- a/f\n+++ b/f\n@@ -1 +1 @@\n-old\n+new\n"
+	out := ansi.Strip(renderDiff("✎ f", diff, 80))
+
+	for _, want := range []string{"✎ f", "@@ -1 +1 @@", "-old", "+new"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("rendered diff missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderDiff_Truncates(t *testing.T) {
+	var sb strings.Builder
+	for i := range maxDiffPreviewLines + 5 {
+		fmt.Fprintf(&sb, "+line %d\n", i)
+	}
+	out := ansi.Strip(renderDiff("f", sb.String(), 80))
+
+	if !strings.Contains(out, "… 5 more lines") {
+		t.Errorf("expected truncation notice:\n%s", out)
+	}
+	if strings.Contains(out, fmt.Sprintf("line %d", maxDiffPreviewLines)) {
+		t.Error("lines beyond the cap should not be rendered")
+	}
+}
+
+func TestMessageList_RendersToolDiff(t *testing.T) {
+	m := NewMessageList()
+	m.SetSize(80, 20)
+	msg := agent.Message{
+		ID:   "tool-msg",
+		Role: agent.RoleTool,
+		ToolResults: []agent.ToolResult{
+			{ToolCallID: "call-1", Name: "edit_file", Content: "File edited"},
+		},
+	}
+
+	if got := m.renderToolMessage(msg, 76); got != "" {
+		t.Errorf("expected no output without a diff, got %q", got)
+	}
+
+	m.SetDiff("call-1", "/tmp/f.go", "@@ -1 +1 @@\n-a\n+b\n")
+	out := ansi.Strip(m.renderToolMessage(msg, 76))
+	if !strings.Contains(out, "/tmp/f.go") || !strings.Contains(out, "+b") {
+		t.Errorf("expected diff preview, got:\n%s", out)
+	}
+}
@@ -0,0 +1,176 @@
+package chat
+
+import (
+	"fmt"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// pickNotice is shown in the status bar while picking a prompt.
+const pickNotice = "Pick a prompt, tool result or the last reply: ↑/↓ move · e edit · r retry/regenerate · enter expand · v view output · y/Y copy reply/code · esc cancel"
+
+// pendingResend is an edited or retried prompt waiting for the user to confirm
+// that the messages after it may be dropped.
+type pendingResend struct {
+	from        agent.Message // The prompt being replaced
+	prompt      string
+	attachments []agent.Attachment
+	dropped     int // Messages removed from the session, including from
+}
+
+// startPicking highlights the latest saved prompt so it can be edited or
+// retried. Tool results after it can be reached to expand them, and the last
+// reply to regenerate it.
+func (m *Model) startPicking() tea.Cmd {
+	targets := m.messages.Pickable()
+	if len(targets) == 0 {
+		return util.ReportInfo("No earlier prompts to pick")
+	}
+	start := targets[len(targets)-1]
+	if prompts := m.messages.UserMessages(); len(prompts) > 0 {
+		start = prompts[len(prompts)-1].ID
+	}
+	m.filePicker.Close()
+	m.picking = true
+	m.messages.Focus(start)
+	m.status.SetNotice(pickNotice)
+	return nil
+}
+
+// stopPicking clears the highlight.
+func (m *Model) stopPicking() {
+	m.picking = false
+	m.messages.Focus("")
+	m.status.SetNotice("")
+}
+
+// handlePickKey moves between prompts and tool results or acts on the
+// highlighted one.
+func (m *Model) handlePickKey(msg tea.KeyMsg) tea.Cmd {
+	targets := m.messages.Pickable()
+	current := -1
+	for i := range targets {
+		if targets[i] == m.messages.Focused() {
+			current = i
+		}
+	}
+	if current < 0 {
+		m.stopPicking()
+		return nil
+	}
+
+	var prompt *agent.Message
+	for _, p := range m.messages.UserMessages() {
+		if p.ID == targets[current] {
+			prompt = &p
+		}
+	}
+
+	switch km := keymap.Current(); {
+	case km.Matches(msg, keymap.CopyMessage):
+		return m.copyLastReply()
+	case km.Matches(msg, keymap.CopyCode):
+		return m.copyNextCodeBlock()
+	}
+
+	switch msg.String() {
+	case "up", "k", "ctrl+up":
+		if current > 0 {
+			m.messages.Focus(targets[current-1])
+		}
+	case "down", "j", "ctrl+down":
+		if current < len(targets)-1 {
+			m.messages.Focus(targets[current+1])
+		} else {
+			m.stopPicking()
+		}
+	case "enter":
+		if prompt == nil {
+			m.messages.ToggleExpanded(targets[current])
+			return nil
+		}
+		m.stopPicking()
+		return m.editPrompt(*prompt)
+	case "e":
+		if prompt != nil {
+			m.stopPicking()
+			return m.editPrompt(*prompt)
+		}
+	case "r":
+		if prompt != nil {
+			m.stopPicking()
+			return m.confirmResend(*prompt, prompt.Content, prompt.Attachments)
+		}
+		if reply, ok := m.messages.LastReply(); ok && reply == targets[current] {
+			m.stopPicking()
+			return m.startRegenerate()
+		}
+	case "v":
+		if prompt == nil {
+			return m.viewFullOutput(targets[current])
+		}
+	case "esc", "q":
+		m.stopPicking()
+	}
+	return nil
+}
+
+// editPrompt loads a saved prompt into the input. Sending it replaces the
+// original and everything after it.
+func (m *Model) editPrompt(msg agent.Message) tea.Cmd {
+	m.editing = &msg
+	m.input.SetValue(msg.Content)
+	return util.ReportInfo("Editing an earlier prompt: enter resends it, esc cancels")
+}
+
+// cancelEdit abandons an edit started with editPrompt.
+func (m *Model) cancelEdit() {
+	m.editing = nil
+	m.input.Clear()
+}
+
+// confirmResend asks before dropping the messages from msg onwards.
+func (m *Model) confirmResend(from agent.Message, prompt string, attachments []agent.Attachment) tea.Cmd {
+	m.resend = &pendingResend{
+		from:        from,
+		prompt:      prompt,
+		attachments: attachments,
+		dropped:     m.messages.CountFrom(from.ID),
+	}
+	later := m.resend.dropped - 1
+	m.status.SetNotice(fmt.Sprintf("Resend and drop the %d later message%s? (y/n)", later, pluralize(later)))
+	return nil
+}
+
+// handleResendKey confirms or cancels a pending resend.
+func (m *Model) handleResendKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		resend := m.resend
+		m.resend = nil
+		m.editing = nil
+		m.status.SetNotice("")
+		m.messages.TruncateAt(resend.from.ID)
+		return m.startStream(resend.prompt, resend.attachments, resend.from.ID)
+	case "n", "N", "esc":
+		m.resend = nil
+		m.status.SetNotice("")
+	}
+	return nil
+}
+
+// editedAttachments keeps the images of the prompt being edited; mentioned
+// files are loaded again from the edited text.
+func editedAttachments(from agent.Message) []agent.Attachment {
+	var images []agent.Attachment
+	for _, att := range from.Attachments {
+		if att.IsImage() {
+			images = append(images, att)
+		}
+	}
+	return images
+}
@@ -0,0 +1,72 @@
+package chat
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+)
+
+func TestChat_PickAndEditPrompt(t *testing.T) {
+	m := New(nil)
+	m.SetSize(80, 24)
+	m.messages.SetMessages([]agent.Message{
+		{ID: "u1", Role: agent.RoleUser, Content: "first"},
+		{ID: "a1", Role: agent.RoleAssistant, Content: "one"},
+		{ID: "u2", Role: agent.RoleUser, Content: "second"},
+		{ID: "a2", Role: agent.RoleAssistant, Content: "two"},
+	})
+
+	m.Update(tea.KeyPressMsg{Code: tea.KeyUp, Mod: tea.ModCtrl})
+	if !m.picking || m.messages.Focused() != "u2" {
+		t.Fatalf("ctrl+up should pick the latest prompt, focused %q", m.messages.Focused())
+	}
+	m.Update(tea.KeyPressMsg{Code: tea.KeyUp})
+	if m.messages.Focused() != "u1" {
+		t.Fatalf("up should move to the earlier prompt, focused %q", m.messages.Focused())
+	}
+
+	m.Update(tea.KeyPressMsg{Code: 'e', Text: "e"})
+	if m.picking || m.editing == nil || m.input.Value() != "first" {
+		t.Fatalf("e should load the prompt into the input, got %q", m.input.Value())
+	}
+
+	m.input.SetValue("first, edited")
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.resend == nil || m.resend.dropped != 4 {
+		t.Fatalf("sending an edit should ask before dropping 4 messages, got %+v", m.resend)
+	}
+
+	m.Update(tea.KeyPressMsg{Code: 'n', Text: "n"})
+	if m.resend != nil || m.input.Value() != "first, edited" {
+		t.Fatalf("n should cancel and keep the edit, input %q", m.input.Value())
+	}
+
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m.Update(tea.KeyPressMsg{Code: 'y', Text: "y"})
+	if !m.isStreaming || m.editing != nil {
+		t.Fatal("y should resend the edited prompt")
+	}
+	msgs := m.messages.messages
+	if len(msgs) != 2 || msgs[0].Content != "first, edited" {
+		t.Errorf("the list should restart from the edited prompt, got %+v", msgs)
+	}
+}
+
+func TestChat_PickEscape(t *testing.T) {
+	m := New(nil)
+	m.SetSize(80, 24)
+
+	m.Update(tea.KeyPressMsg{Code: tea.KeyUp, Mod: tea.ModCtrl})
+	if m.picking {
+		t.Fatal("there is nothing to pick without saved prompts")
+	}
+
+	m.messages.SetMessages([]agent.Message{{ID: "u1", Role: agent.RoleUser, Content: "only"}})
+	m.Update(tea.KeyPressMsg{Code: tea.KeyUp, Mod: tea.ModCtrl})
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.picking || m.messages.Focused() != "" {
+		t.Error("esc should stop picking")
+	}
+}
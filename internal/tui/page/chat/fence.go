@@ -0,0 +1,133 @@
+package chat
+
+import (
+	"slices"
+	"strings"
+)
+
+// documentLanguages are the fence languages whose blocks may hold fenced
+// blocks of their own, as when a model shows a README.
+var documentLanguages = map[string]bool{"markdown": true, "md": true, "mdx": true}
+
+// fence is a line opening or closing a fenced code block.
+type fence struct {
+	indent string
+	char   byte // '`' or '~'
+	length int
+	info   string // The rest of the line, trimmed; empty for a closing fence
+}
+
+// parseFence reads line as a code fence: up to three spaces, then three or
+// more backticks or tildes, then an optional info string.
+func parseFence(line string) (fence, bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	indent := len(line) - len(trimmed)
+	if indent > 3 || len(trimmed) < 3 || (trimmed[0] != '`' && trimmed[0] != '~') { //nolint:mnd // CommonMark limits
+		return fence{}, false
+	}
+	c := trimmed[0]
+	n := 0
+	for n < len(trimmed) && trimmed[n] == c {
+		n++
+	}
+	info := strings.TrimSpace(trimmed[n:])
+	if n < 3 || (c == '`' && strings.Contains(info, "`")) { //nolint:mnd // CommonMark limits
+		return fence{}, false // Too short, or inline code such as ```x```
+	}
+	return fence{indent: line[:indent], char: c, length: n, info: info}, true
+}
+
+// language returns the first word of the info string, lowercased.
+func (f fence) language() string {
+	if fields := strings.Fields(f.info); len(fields) > 0 {
+		return strings.ToLower(fields[0])
+	}
+	return ""
+}
+
+// closes reports whether f closes the block open opened.
+func (f fence) closes(open fence) bool {
+	return f.info == "" && f.char == open.char && f.length >= open.length
+}
+
+// sameKind reports whether f could close the block open opened, were it not
+// for its info string.
+func (f fence) sameKind(open fence) bool {
+	return f.char == open.char && f.length >= open.length
+}
+
+// withLength returns the fence line made length markers long.
+func (f fence) withLength(length int) string {
+	return f.indent + strings.Repeat(string(f.char), length) + f.info
+}
+
+// openBlock is a fenced block being read.
+type openBlock struct {
+	fence
+	at      int // Index of the opening line in the output
+	nested  int // Nested blocks open inside a document block
+	longest int // Longest fence nested inside it
+}
+
+// repairFences balances the code fences of a message, so that one broken
+// block does not turn the rest of the message into code:
+//   - a block still open at the end is closed, as it is while streaming;
+//   - a fence with a language inside a code block of the same kind starts a
+//     new block, so the open one is closed first;
+//   - blocks nested inside a markdown block get a longer outer fence, so the
+//     inner closing fences do not end it early.
+func repairFences(content string) string {
+	lines := strings.Split(content, "\n")
+	out := make([]string, 0, len(lines)+1)
+	var open *openBlock
+
+	for _, line := range lines {
+		f, ok := parseFence(line)
+		switch {
+		case !ok:
+		case open == nil:
+			open = &openBlock{fence: f, at: len(out)}
+		case f.closes(open.fence) && open.nested > 0:
+			open.nested--
+		case f.closes(open.fence):
+			if open.longest >= open.length {
+				length := open.longest + 1
+				out[open.at] = open.withLength(length)
+				line = f.withLength(length)
+			}
+			open = nil
+		case f.info == "" || !f.sameKind(open.fence):
+		case documentLanguages[open.language()]:
+			open.nested++
+			open.longest = max(open.longest, f.length)
+		default:
+			out = append(out, open.closeLine())
+			open = &openBlock{fence: f, at: len(out)}
+		}
+		out = append(out, line)
+	}
+
+	if open == nil {
+		return strings.Join(out, "\n")
+	}
+	// Close what is left before any trailing newline
+	end := len(out)
+	if end > open.at+1 && out[end-1] == "" {
+		end--
+	}
+	closing := make([]string, 0, open.nested+1)
+	for ; open.nested > 0; open.nested-- {
+		closing = append(closing, fence{indent: open.indent, char: open.char}.withLength(open.longest))
+	}
+	if open.longest >= open.length {
+		open.length = open.longest + 1
+		out[open.at] = open.withLength(open.length)
+	}
+	closing = append(closing, open.closeLine())
+	return strings.Join(slices.Insert(out, end, closing...), "\n")
+}
+
+// closeLine returns the fence that closes the block.
+func (b *openBlock) closeLine() string {
+	return fence{indent: b.indent, char: b.char}.withLength(b.length)
+}
@@ -0,0 +1,97 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRepairFences(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "balanced",
+			content: "Run:\n```bash\ngo test ./...\n```\nDone.",
+			want:    "Run:\n```bash\ngo test ./...\n```\nDone.",
+		},
+		{
+			name:    "unclosed at the end",
+			content: "```go\nfunc main() {",
+			want:    "```go\nfunc main() {\n```",
+		},
+		{
+			name:    "unclosed before a trailing newline",
+			content: "```go\nfunc main() {\n",
+			want:    "```go\nfunc main() {\n```\n",
+		},
+		{
+			name:    "new block without closing the last",
+			content: "```python\nprint(1)\n```bash\necho 2\n```\nok",
+			want:    "```python\nprint(1)\n```\n```bash\necho 2\n```\nok",
+		},
+		{
+			name:    "block nested in markdown",
+			content: "```markdown\n# Usage\n```bash\nmake\n```\n```\nAfter.",
+			want:    "````markdown\n# Usage\n```bash\nmake\n```\n````\nAfter.",
+		},
+		{
+			name:    "unclosed nested block",
+			content: "```md\n```go\nx := 1",
+			want:    "````md\n```go\nx := 1\n```\n````",
+		},
+		{
+			name:    "longer outer fence already nests",
+			content: "````markdown\n```go\nx\n```\n````",
+			want:    "````markdown\n```go\nx\n```\n````",
+		},
+		{
+			name:    "tildes inside backticks",
+			content: "```text\n~~~\nnot a fence here\n```",
+			want:    "```text\n~~~\nnot a fence here\n```",
+		},
+		{
+			name:    "inline triple backticks",
+			content: "Use ```code``` sparingly.",
+			want:    "Use ```code``` sparingly.",
+		},
+		{
+			name:    "indented too far",
+			content: "    ```\n    code",
+			want:    "    ```\n    code",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := repairFences(tt.content); got != tt.want {
+				t.Errorf("repairFences() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownRenderer_BrokenFences(t *testing.T) {
+	r := NewMarkdownRenderer()
+
+	got, err := r.Render("```markdown\n# Setup\n```bash\nmake install\n```\n```\n\nThen **run** it.", 80)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	plain := stripANSI(got)
+	if !strings.Contains(plain, "make install") || strings.Contains(plain, "**run**") {
+		t.Errorf("text after the nested block should render as markdown:\n%s", plain)
+	}
+
+	// The next message is unaffected by an unclosed block in the last one.
+	if _, err := r.Render("```go\nfunc main() {", 80); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	got, err = r.Render("Plain **bold** text", 80)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if plain := stripANSI(got); strings.Contains(plain, "**") || !strings.Contains(plain, "bold") {
+		t.Errorf("a following message should render normally:\n%s", plain)
+	}
+}
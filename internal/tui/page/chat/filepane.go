@@ -0,0 +1,188 @@
+package chat
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/guilhermegouw/cdd/internal/tools"
+	"github.com/guilhermegouw/cdd/internal/tui/styles"
+)
+
+const (
+	// minFilePaneWidth is the narrowest terminal the file pane is shown in,
+	// so the chat beside it stays readable.
+	minFilePaneWidth = 100
+	// maxFilePaneBytes caps how much of a file the pane reads.
+	maxFilePaneBytes = 512 * 1024
+)
+
+// FilePane shows the file the agent last read or changed, or the diff of its
+// last change, beside the chat.
+type FilePane struct {
+	path    string
+	callID  string // Tool call whose diff is shown, empty for a file
+	lines   []string
+	offset  int
+	width   int
+	height  int
+	visible bool
+}
+
+// NewFilePane creates a hidden, empty pane.
+func NewFilePane() *FilePane {
+	return &FilePane{}
+}
+
+// Toggle shows or hides the pane.
+func (p *FilePane) Toggle() {
+	p.visible = !p.visible
+}
+
+// IsVisible reports whether the pane is shown.
+func (p *FilePane) IsVisible() bool {
+	return p.visible
+}
+
+// Path returns the file shown, or "" before the agent touched any.
+func (p *FilePane) Path() string {
+	return p.path
+}
+
+// SetSize sets the pane's outer size, border included.
+func (p *FilePane) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+	p.clampOffset()
+}
+
+// ShowFile shows the file at path, read from disk relative to dir. The
+// file a tool call changed is not shown when its diff already is.
+func (p *FilePane) ShowFile(dir, path, callID string) {
+	if callID != "" && callID == p.callID {
+		return
+	}
+	display := path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	} else if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		display = rel
+	}
+
+	p.path = display
+	p.callID = ""
+	p.offset = 0
+	content, err := readPaneFile(path)
+	if err != nil {
+		p.lines = []string{styles.CurrentTheme().S().Error.Render(err.Error())}
+		return
+	}
+
+	code := strings.ReplaceAll(strings.TrimRight(content, "\n"), "\t", "    ")
+	lines := highlightCode(code, path)
+	if lines == nil {
+		lines = strings.Split(code, "\n")
+	}
+	t := styles.CurrentTheme()
+	digits := len(fmt.Sprint(len(lines)))
+	p.lines = make([]string, len(lines))
+	for i, line := range lines {
+		p.lines[i] = t.S().Subtle.Render(fmt.Sprintf("%*d", digits, i+1)) + " " + line
+	}
+}
+
+// ShowDiff shows the diff a tool call made to the file at path.
+func (p *FilePane) ShowDiff(path, callID, diff string) {
+	p.path = path
+	p.callID = callID
+	p.offset = 0
+	lines := strings.Split(strings.TrimRight(diff, "\n"), "\n")
+	p.lines = make([]string, len(lines))
+	for i, line := range lines {
+		p.lines[i] = diffLine(strings.ReplaceAll(line, "\t", "    "))
+	}
+}
+
+// ScrollUp scrolls the content up by n lines.
+func (p *FilePane) ScrollUp(n int) {
+	p.offset -= n
+	p.clampOffset()
+}
+
+// ScrollDown scrolls the content down by n lines.
+func (p *FilePane) ScrollDown(n int) {
+	p.offset += n
+	p.clampOffset()
+}
+
+// bodyHeight is how many content lines fit under the header.
+func (p *FilePane) bodyHeight() int {
+	return max(p.height-1, 0)
+}
+
+func (p *FilePane) clampOffset() {
+	p.offset = max(min(p.offset, len(p.lines)-p.bodyHeight()), 0)
+}
+
+// View renders the header and the visible lines, with a border on the left
+// separating the pane from the chat.
+func (p *FilePane) View() string {
+	t := styles.CurrentTheme()
+	inner := max(p.width-2, 1) //nolint:mnd // Border and padding
+
+	title := "─ No file yet"
+	if p.path != "" {
+		title = "─ " + p.path
+		if p.callID != "" {
+			title += " (diff)"
+		}
+	}
+	lines := make([]string, 0, p.height)
+	lines = append(lines, t.S().Muted.Bold(true).Render(ansi.Truncate(title, inner, "…")))
+	if p.path == "" {
+		lines = append(lines, t.S().Muted.Render(ansi.Truncate("Files the agent reads or changes show up here.", inner, "…")))
+	}
+	end := min(p.offset+p.bodyHeight(), len(p.lines))
+	for _, line := range p.lines[p.offset:end] {
+		lines = append(lines, ansi.Truncate(line, inner, "…"))
+	}
+
+	return lipgloss.NewStyle().
+		Border(styles.Border(), false, false, false, true).
+		BorderForeground(t.Border).
+		PaddingLeft(1).
+		Width(p.width).
+		Height(p.height).
+		MaxHeight(p.height).
+		Render(strings.Join(lines, "\n"))
+}
+
+// readPaneFile reads up to maxFilePaneBytes of the file at path.
+func readPaneFile(path string) (string, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: Shows files the agent already opened.
+	if err != nil {
+		return "", fmt.Errorf("opening %s: %w", filepath.Base(path), err)
+	}
+	defer f.Close() //nolint:errcheck // Read-only
+
+	content, err := io.ReadAll(io.LimitReader(f, maxFilePaneBytes))
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", filepath.Base(path), err)
+	}
+	return string(content), nil
+}
+
+// isFileTool reports whether a tool reads or changes the file named by its
+// file_path input.
+func isFileTool(name string) bool {
+	switch name {
+	case tools.ReadToolName, tools.EditToolName, tools.EditFileToolName, tools.WriteToolName:
+		return true
+	}
+	return false
+}
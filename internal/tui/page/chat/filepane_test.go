@@ -0,0 +1,87 @@
+package chat
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+func TestFilePane(t *testing.T) {
+	dir := t.TempDir()
+	var content strings.Builder
+	for i := 1; i <= 30; i++ {
+		content.WriteString("line\n")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(content.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewFilePane()
+	p.SetSize(40, 10)
+	p.ShowFile(dir, filepath.Join(dir, "notes.txt"), "call-1")
+
+	view := ansi.Strip(p.View())
+	if lines := strings.Split(view, "\n"); len(lines) != 10 {
+		t.Fatalf("view has %d lines, want 10", len(lines))
+	}
+	if !strings.Contains(view, "─ notes.txt") || !strings.Contains(view, " 1 line") {
+		t.Errorf("view should show the relative path and numbered lines:\n%s", view)
+	}
+
+	p.ScrollDown(100)
+	if view := ansi.Strip(p.View()); !strings.Contains(view, "30 line") || strings.Contains(view, " 1 line") {
+		t.Errorf("scrolling should stop at the last line:\n%s", view)
+	}
+
+	p.ShowDiff("notes.txt", "call-2", "@@ -1 +1 @@\n-line\n+changed\n")
+	p.ShowFile(dir, "notes.txt", "call-2")
+	if view := ansi.Strip(p.View()); !strings.Contains(view, "(diff)") || !strings.Contains(view, "+changed") {
+		t.Errorf("the diff of a call should not be replaced by its file:\n%s", view)
+	}
+
+	p.ShowFile(dir, "missing.go", "call-3")
+	if view := ansi.Strip(p.View()); !strings.Contains(view, "opening missing.go") {
+		t.Errorf("view should explain an unreadable file:\n%s", view)
+	}
+}
+
+func TestChat_FilePane(t *testing.T) {
+	m := New(nil)
+	m.SetSize(120, 30)
+
+	m.Update(tea.KeyPressMsg{Code: 'g', Mod: tea.ModCtrl})
+	if !m.filePane.IsVisible() {
+		t.Fatal("ctrl+g should show the file pane")
+	}
+	if m.chatWidth() != 72 {
+		t.Errorf("chat width = %d, want 72 beside the pane", m.chatWidth())
+	}
+	for _, line := range strings.Split(m.View(), "\n") {
+		if w := ansi.StringWidth(line); w > 120 {
+			t.Fatalf("line is %d cells wide, want at most 120", w)
+		}
+	}
+
+	m.handleToolEvent(pubsub.Event[events.ToolEvent]{Payload: events.ToolEvent{
+		Type:       events.ToolEventProgress,
+		ToolCallID: "call-1",
+		ToolName:   "edit_file",
+		FilePath:   "main.go",
+		Diff:       "+package main\n",
+	}})
+	if m.filePane.Path() != "main.go" {
+		t.Errorf("pane shows %q, want the edited main.go", m.filePane.Path())
+	}
+
+	m.SetSize(80, 30)
+	if m.chatWidth() != 80 {
+		t.Error("the pane should not be shown in a narrow terminal")
+	}
+}
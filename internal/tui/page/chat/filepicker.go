@@ -0,0 +1,238 @@
+package chat
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/guilhermegouw/cdd/internal/tui/styles"
+)
+
+// File picker limits.
+const (
+	maxPickerFiles = 20000 // Files loaded from the working directory
+	maxFileMatches = 8     // Suggestions shown at once
+)
+
+// Fuzzy match scores.
+const (
+	fuzzyMatchBonus = 1
+	fuzzyRunBonus   = 5 // Match right after the previous one
+	fuzzyStartBonus = 8 // Match at the start of a path segment or word
+	fuzzyNameBonus  = 2 // Match inside the file name
+)
+
+// FilePicker suggests working directory files while the user types an
+// @mention or completes a path fragment. It also suggests slash commands while
+// the user types the first word of a command.
+type FilePicker struct {
+	files    []string          // Candidate paths, relative to the working directory
+	notes    map[string]string // Text shown after a candidate, such as a command's description
+	matches  []string
+	query    string
+	cursor   int
+	width    int
+	visible  bool
+	commands bool // Candidates are command names
+}
+
+// NewFilePicker creates a new, hidden file picker.
+func NewFilePicker() *FilePicker {
+	return &FilePicker{}
+}
+
+// Open shows the picker over files, filtered by query.
+func (p *FilePicker) Open(files []string, query string) {
+	p.files = files
+	p.notes = nil
+	p.commands = false
+	p.visible = true
+	p.filter(query)
+}
+
+// OpenCommands shows the picker over command names, filtered by query, with
+// each command's description beside it.
+func (p *FilePicker) OpenCommands(names []string, descriptions map[string]string, query string) {
+	p.files = names
+	p.notes = descriptions
+	p.commands = true
+	p.visible = true
+	p.filter(query)
+}
+
+// Close hides the picker.
+func (p *FilePicker) Close() {
+	p.visible = false
+	p.files = nil
+	p.notes = nil
+	p.commands = false
+	p.matches = nil
+	p.query = ""
+	p.cursor = 0
+}
+
+// IsVisible reports whether the picker is shown.
+func (p *FilePicker) IsVisible() bool {
+	return p.visible
+}
+
+// IsCommands reports whether the picker is suggesting slash commands.
+func (p *FilePicker) IsCommands() bool {
+	return p.visible && p.commands
+}
+
+// SetQuery refilters the files by query, keeping the best matches.
+func (p *FilePicker) SetQuery(query string) {
+	if query != p.query {
+		p.filter(query)
+	}
+}
+
+func (p *FilePicker) filter(query string) {
+	p.query = query
+	p.cursor = 0
+	p.matches = rankFiles(p.files, query, maxFileMatches)
+}
+
+// SetWidth sets the picker width.
+func (p *FilePicker) SetWidth(width int) {
+	p.width = width
+}
+
+// MoveUp selects the previous suggestion, wrapping around.
+func (p *FilePicker) MoveUp() {
+	if len(p.matches) > 0 {
+		p.cursor = (p.cursor - 1 + len(p.matches)) % len(p.matches)
+	}
+}
+
+// MoveDown selects the next suggestion, wrapping around.
+func (p *FilePicker) MoveDown() {
+	if len(p.matches) > 0 {
+		p.cursor = (p.cursor + 1) % len(p.matches)
+	}
+}
+
+// Selected returns the highlighted path, or "" when nothing matches.
+func (p *FilePicker) Selected() string {
+	if p.cursor < len(p.matches) {
+		return p.matches[p.cursor]
+	}
+	return ""
+}
+
+// Height returns the rendered height (0 when hidden).
+func (p *FilePicker) Height() int {
+	if !p.visible {
+		return 0
+	}
+	return 1 + max(len(p.matches), 1) // Header + suggestions
+}
+
+// View renders the suggestions.
+func (p *FilePicker) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	t := styles.CurrentTheme()
+	lines := make([]string, 0, p.Height())
+	title, empty, prefix := "─ Files ", "  No matching files", ""
+	if p.commands {
+		title, empty, prefix = "─ Commands ", "  No matching commands", "/"
+	}
+	lines = append(lines, t.S().Muted.Bold(true).Render(title)+
+		t.S().Muted.Render("(tab to insert, esc to dismiss)"))
+
+	if len(p.matches) == 0 {
+		lines = append(lines, t.S().Muted.Render(empty))
+	}
+	for i, path := range p.matches {
+		label := truncate(prefix+path, max(p.width-6, 10)) //nolint:mnd // Marker and padding
+		if i == p.cursor {
+			label = t.S().Primary.Bold(true).Render("> " + label)
+		} else {
+			label = t.S().Text.Render("  " + label)
+		}
+		if note := p.notes[path]; note != "" {
+			room := p.width - lipgloss.Width(label) - 6 //nolint:mnd // Gap and padding
+			if room > 10 {                              //nolint:mnd // Too narrow to be useful
+				label += t.S().Muted.Render("  " + ansi.Truncate(note, room, "…"))
+			}
+		}
+		lines = append(lines, label)
+	}
+
+	return lipgloss.NewStyle().
+		Padding(0, 1).
+		Width(p.width).
+		Render(strings.Join(lines, "\n"))
+}
+
+// rankFiles returns up to limit files that fuzzily match query, best first.
+func rankFiles(files []string, query string, limit int) []string {
+	type scored struct {
+		path  string
+		score int
+	}
+	var found []scored
+	for _, path := range files {
+		if score, ok := fuzzyScore(query, path); ok {
+			found = append(found, scored{path, score})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].score != found[j].score {
+			return found[i].score > found[j].score
+		}
+		return len(found[i].path) < len(found[j].path)
+	})
+
+	matches := make([]string, 0, min(len(found), limit))
+	for i := 0; i < len(found) && i < limit; i++ {
+		matches = append(matches, found[i].path)
+	}
+	return matches
+}
+
+// fuzzyScore reports whether the characters of query appear in order in path,
+// ignoring case, and scores the match. Runs of consecutive characters,
+// matches at the start of a path segment or word, and matches in the file
+// name score higher.
+func fuzzyScore(query, path string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	p := []rune(strings.ToLower(path))
+	nameStart := 0
+	for i, r := range p {
+		if r == '/' {
+			nameStart = i + 1
+		}
+	}
+
+	score, qi, last := 0, 0, -2
+	for pi := 0; pi < len(p) && qi < len(q); pi++ {
+		if p[pi] != q[qi] {
+			continue
+		}
+		score += fuzzyMatchBonus
+		if pi == last+1 {
+			score += fuzzyRunBonus
+		}
+		if pi == 0 || isPathBoundary(p[pi-1]) {
+			score += fuzzyStartBonus
+		}
+		if pi >= nameStart {
+			score += fuzzyNameBonus
+		}
+		last = pi
+		qi++
+	}
+	return score, qi == len(q)
+}
+
+func isPathBoundary(r rune) bool {
+	return r == '/' || r == '.' || r == '_' || r == '-' || unicode.IsSpace(r)
+}
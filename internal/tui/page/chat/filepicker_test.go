@@ -0,0 +1,153 @@
+package chat
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	if _, ok := fuzzyScore("cht", "internal/tui/page/chat/chat.go"); !ok {
+		t.Error("expected subsequence to match")
+	}
+	if _, ok := fuzzyScore("xyz", "internal/chat.go"); ok {
+		t.Error("expected no match")
+	}
+	if _, ok := fuzzyScore("CHAT", "chat.go"); !ok {
+		t.Error("matching should ignore case")
+	}
+
+	prefix, _ := fuzzyScore("read", "internal/tools/read.go")
+	scattered, _ := fuzzyScore("read", "internal/tui/page/chat/markdown.go")
+	if prefix <= scattered {
+		t.Errorf("file name prefix should beat a scattered match: %d <= %d", prefix, scattered)
+	}
+}
+
+func TestRankFiles(t *testing.T) {
+	files := []string{
+		"docs/features/provider-system.md",
+		"internal/tools/read_test.go",
+		"internal/tools/read.go",
+		"README.md",
+	}
+
+	got := rankFiles(files, "read", 10)
+	want := []string{"README.md", "internal/tools/read.go", "internal/tools/read_test.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("rankFiles(read) = %v, want %v", got, want)
+	}
+
+	if got := rankFiles(files, "", 2); len(got) != 2 || got[0] != "README.md" {
+		t.Errorf("an empty query should list the shortest paths first, got %v", got)
+	}
+}
+
+func TestFilePicker_Navigation(t *testing.T) {
+	p := NewFilePicker()
+	if p.Height() != 0 || p.View() != "" {
+		t.Fatal("hidden picker should take no space")
+	}
+
+	p.Open([]string{"a.go", "b.go", "c.txt"}, ".go")
+	if !p.IsVisible() || p.Height() != 3 {
+		t.Fatalf("expected header and two matches, height %d", p.Height())
+	}
+	if p.Selected() != "a.go" {
+		t.Errorf("Selected() = %q, want a.go", p.Selected())
+	}
+	p.MoveDown()
+	if p.Selected() != "b.go" {
+		t.Errorf("after MoveDown Selected() = %q, want b.go", p.Selected())
+	}
+	p.MoveDown()
+	if p.Selected() != "a.go" {
+		t.Errorf("MoveDown should wrap, got %q", p.Selected())
+	}
+	p.MoveUp()
+	if p.Selected() != "b.go" {
+		t.Errorf("MoveUp should wrap, got %q", p.Selected())
+	}
+
+	p.SetQuery("zzz")
+	if p.Selected() != "" || !strings.Contains(p.View(), "No matching files") {
+		t.Errorf("expected no matches, got %q", p.Selected())
+	}
+
+	p.Close()
+	if p.IsVisible() {
+		t.Error("Close should hide the picker")
+	}
+}
+
+func TestInput_ReplaceCurrentWord(t *testing.T) {
+	input := NewInput()
+	input.SetWidth(80)
+	input.SetValue("look at @cha")
+
+	if got := input.CurrentWord(); got != "@cha" {
+		t.Fatalf("CurrentWord() = %q, want @cha", got)
+	}
+	input.ReplaceCurrentWord("@internal/chat.go ")
+	if got := input.Value(); got != "look at @internal/chat.go " {
+		t.Errorf("Value() = %q", got)
+	}
+	if got := input.CurrentWord(); got != "" {
+		t.Errorf("cursor should follow the inserted text, word %q", got)
+	}
+}
+
+func TestMentionedFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.MkdirAll(filepath.Join(dir, "pkg"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pkg", "main.go"), []byte("package main\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "blob.bin"), []byte{0, 1, 2}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New(nil)
+	files, warnings := m.mentionedFiles("explain @pkg/main.go. Also @pkg/main.go, ask @bob or mail me@pkg/main.go @blob.bin")
+	if len(files) != 1 || files[0].Filename != "pkg/main.go" || string(files[0].Data) != "package main\n" {
+		t.Errorf("mentionedFiles() = %+v, want pkg/main.go once", files)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("expected a warning for the binary file, got %d", len(warnings))
+	}
+}
+
+func TestChat_FilePickerCompletesMention(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	for _, name := range []string{"main.go", "README.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := New(nil)
+	m.SetSize(80, 24)
+	for _, r := range "see @mai" {
+		m.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if !m.filePicker.IsVisible() || m.filePicker.Selected() != "main.go" {
+		t.Fatalf("expected the picker to suggest main.go, visible=%v selected=%q",
+			m.filePicker.IsVisible(), m.filePicker.Selected())
+	}
+
+	m.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	if got := m.input.Value(); got != "see @main.go " {
+		t.Errorf("input = %q, want the selected path", got)
+	}
+	if m.filePicker.IsVisible() {
+		t.Error("picker should close after inserting a file")
+	}
+}
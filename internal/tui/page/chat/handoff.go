@@ -0,0 +1,111 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// handoffDoneMsg carries the result of a handoff back to the chat page.
+type handoffDoneMsg struct {
+	SessionID string
+	target    modelOption
+	err       error
+}
+
+// findModel returns the option spec names: connection/model, by ID or name,
+// provider/model, or a model alone, ignoring case. Without an exact match it falls back to
+// the best fuzzy match, as the quick switcher does.
+func findModel(options []modelOption, spec string) (modelOption, bool) {
+	for _, option := range options {
+		for _, name := range []string{
+			option.connectionID + "/" + option.modelID,
+			option.label(),
+			option.providerID + "/" + option.modelID,
+			option.modelID,
+			option.modelName,
+		} {
+			if strings.EqualFold(name, spec) {
+				return option, true
+			}
+		}
+	}
+
+	best, bestScore, found := modelOption{}, 0, false
+	for _, option := range options {
+		if score, ok := fuzzyScore(spec, option.label()); ok && (!found || score > bestScore) {
+			best, bestScore, found = option, score, true
+		}
+	}
+	return best, found
+}
+
+// handleHandoff runs /handoff: the current model briefs the model named in
+// args, which then takes over the session.
+func (m *Model) handleHandoff(args []string) tea.Cmd {
+	if m.isStreaming {
+		return util.ReportWarn("Wait for the reply to finish before handing off")
+	}
+	if m.cfg == nil || m.agent == nil {
+		return util.ReportWarn("Handoff is not available")
+	}
+	spec := strings.Join(args, " ")
+	if spec == "" {
+		return util.ReportWarn("Usage: /handoff CONNECTION/MODEL or /handoff ALIAS")
+	}
+
+	options, active := modelOptions(m.cfg)
+	target, ok := findModel(options, m.cfg.ResolveModelAlias(spec))
+	if !ok {
+		return util.ReportWarn(fmt.Sprintf("No model matches %q; /models lists them", spec))
+	}
+	if target == active {
+		return util.ReportWarn(target.label() + " is already the model in use")
+	}
+	if len(m.agent.Sessions().GetMessages(m.sessionID)) == 0 {
+		// Nothing to brief the next model on, so just switch.
+		return m.switchModel(target)
+	}
+
+	// The briefing runs like a reply: the input waits and esc cancels it.
+	m.input.Disable()
+	m.filePicker.Close()
+	m.isStreaming = true
+	m.status.SetStatus(StatusThinking)
+	m.status.SetNotice("Briefing " + target.label())
+	spinnerCmd := m.activity.SetThinking(true)
+
+	ag, sessionID := m.agent, m.sessionID
+	handoff := func() tea.Msg {
+		_, err := ag.Handoff(context.Background(), sessionID)
+		return handoffDoneMsg{SessionID: sessionID, target: target, err: err}
+	}
+	return tea.Batch(spinnerCmd, handoff)
+}
+
+// handleHandoffDone shows the briefing, which the next model continues from,
+// and switches to that model, or reports why the handoff failed and leaves
+// the model as it was.
+func (m *Model) handleHandoffDone(msg handoffDoneMsg) tea.Cmd {
+	m.isStreaming = false
+	m.activity.Clear()
+	m.status.SetStatus(StatusReady)
+	m.input.Enable()
+	focus := m.input.Focus()
+
+	switch {
+	case errors.Is(msg.err, context.Canceled):
+		return tea.Batch(focus, util.ReportInfo("Handoff cancelled"))
+	case msg.err != nil:
+		return tea.Batch(focus, util.ReportError(fmt.Errorf("handing off: %w", msg.err)))
+	}
+
+	m.messages.SetMessages(m.agent.Sessions().GetMessages(m.sessionID))
+	m.refreshStatus()
+	return tea.Batch(focus, m.switchModel(msg.target))
+}
@@ -0,0 +1,58 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+func TestFindModel(t *testing.T) {
+	options, _ := modelOptions(switcherConfig())
+
+	tests := []struct {
+		spec      string
+		wantModel string
+		wantOK    bool
+	}{
+		{"c1/claude-sonnet", "claude-sonnet", true},
+		{"work/claude haiku", "claude-haiku", true},
+		{"GPT-4O", "gpt-4o", true},
+		{"openai/gpt-4o", "gpt-4o", true},
+		{"work/son", "claude-sonnet", true},
+		{"zzz", "", false},
+	}
+	for _, tt := range tests {
+		option, ok := findModel(options, tt.spec)
+		if ok != tt.wantOK || option.modelID != tt.wantModel {
+			t.Errorf("findModel(%q) = %q, %v; want %q, %v", tt.spec, option.modelID, ok, tt.wantModel, tt.wantOK)
+		}
+	}
+}
+
+func TestHandleHandoff(t *testing.T) {
+	m := New(agent.New(agent.Config{}))
+	m.Init()
+	m.SetConfig(switcherConfig(), nil)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "Usage"},
+		{[]string{"zzz"}, "No model matches"},
+		{[]string{"Work/Claude", "Haiku"}, "already the model in use"},
+	}
+	for _, tt := range tests {
+		msg := m.handleHandoff(tt.args)()
+		if info, ok := msg.(util.InfoMsg); !ok || !strings.Contains(info.Msg, tt.want) {
+			t.Errorf("/handoff %v = %#v, want %q", tt.args, msg, tt.want)
+		}
+	}
+
+	m.isStreaming = true
+	if msg := m.handleHandoff([]string{"gpt-4o"})(); !strings.Contains(msg.(util.InfoMsg).Msg, "Wait") {
+		t.Errorf("/handoff while streaming = %#v, want a warning", msg)
+	}
+}
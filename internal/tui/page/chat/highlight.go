@@ -0,0 +1,164 @@
+package chat
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/tools"
+	"github.com/guilhermegouw/cdd/internal/tui/styles"
+)
+
+// numberedLine matches a line of read_file output: a right-aligned line
+// number, a tab, then the file's text.
+var numberedLine = regexp.MustCompile(`^(\s*\d+)\t(.*)$`)
+
+// toolResultLines returns the lines of a tool result, highlighting code found
+// in it: the file read by read_file, or fenced code blocks in other output.
+// Output that brings its own colors, as from a test runner, keeps them.
+func toolResultLines(call agent.ToolCall, content string) []string {
+	content = strings.TrimRight(content, "\n")
+	if hasANSI(content) {
+		return ansiLines(content)
+	}
+	if call.Name == tools.ReadToolName {
+		if path := toolFilePath(call.Input); path != "" {
+			return highlightNumbered(content, path)
+		}
+	}
+	return highlightFences(content)
+}
+
+// toolFilePath returns the file_path argument of a tool call.
+func toolFilePath(input string) string {
+	var params struct {
+		FilePath string `json:"file_path"`
+	}
+	if err := json.Unmarshal([]byte(input), &params); err != nil {
+		return ""
+	}
+	return params.FilePath
+}
+
+// highlightNumbered highlights read_file output, keeping the line numbers
+// out of the code so the lexer sees the file as written.
+func highlightNumbered(content, filename string) []string {
+	t := styles.CurrentTheme()
+	lines := strings.Split(content, "\n")
+
+	var code []string
+	for _, line := range lines {
+		if m := numberedLine.FindStringSubmatch(line); m != nil {
+			code = append(code, m[2])
+		}
+	}
+	highlighted := highlightCode(strings.Join(code, "\n"), filename)
+	if highlighted == nil {
+		return lines
+	}
+
+	out := make([]string, 0, len(lines))
+	next := 0
+	for _, line := range lines {
+		m := numberedLine.FindStringSubmatch(line)
+		if m == nil || next >= len(highlighted) {
+			out = append(out, t.S().Muted.Render(line))
+			continue
+		}
+		out = append(out, t.S().Subtle.Render(m[1])+"  "+highlighted[next])
+		next++
+	}
+	return out
+}
+
+// highlightFences highlights the contents of ``` blocks that name their
+// language and leaves the rest of the text alone.
+func highlightFences(content string) []string {
+	lines := strings.Split(content, "\n")
+	out := make([]string, 0, len(lines))
+
+	var block []string
+	lang, inBlock := "", false
+	for _, line := range lines {
+		fence := strings.HasPrefix(strings.TrimSpace(line), "```")
+		switch {
+		case fence && !inBlock:
+			inBlock, lang, block = true, strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "```")), nil
+			out = append(out, line)
+		case fence:
+			inBlock = false
+			out = append(out, fencedBlock(block, lang)...)
+			out = append(out, line)
+		case inBlock:
+			block = append(block, line)
+		default:
+			out = append(out, line)
+		}
+	}
+	if inBlock {
+		out = append(out, fencedBlock(block, lang)...)
+	}
+	return out
+}
+
+func fencedBlock(block []string, lang string) []string {
+	if lang != "" {
+		if highlighted := highlightCode(strings.Join(block, "\n"), lang); highlighted != nil {
+			return highlighted
+		}
+	}
+	return block
+}
+
+// highlightCode returns code highlighted line by line with the lexer for
+// lang, which may be a language name or a file name. It returns nil when no
+// lexer is known for lang.
+func highlightCode(code, lang string) []string {
+	lexer := lexers.Get(lang)
+	if lexer == nil {
+		lexer = lexers.Match(lang)
+	}
+	if lexer == nil {
+		return nil
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return nil
+	}
+
+	formatter := formatters.TTY16m
+	style := codeStyle()
+	var out []string
+	for _, tokens := range chroma.SplitTokensIntoLines(iterator.Tokens()) {
+		var b strings.Builder
+		if err := formatter.Format(&b, style, chroma.Literator(tokens...)); err != nil {
+			return nil
+		}
+		// The newline ending each line may be wrapped in color codes.
+		out = append(out, strings.ReplaceAll(b.String(), "\n", ""))
+	}
+	return out
+}
+
+// codeStyle builds a chroma style from the app theme, using the same colors
+// as code blocks in markdown.
+func codeStyle() *chroma.Style {
+	t := styles.CurrentTheme()
+	return chroma.MustNewStyle("cdd", chroma.StyleEntries{
+		chroma.Text:           colorToHex(t.FgBase),
+		chroma.Keyword:        colorToHex(t.Primary),
+		chroma.Operator:       colorToHex(t.Primary),
+		chroma.Comment:        "italic " + colorToHex(t.FgMuted),
+		chroma.CommentPreproc: colorToHex(t.FgMuted),
+		chroma.Name:           colorToHex(t.FgBase),
+		chroma.NameFunction:   colorToHex(t.Accent),
+		chroma.NameClass:      colorToHex(t.Accent),
+		chroma.LiteralString:  colorToHex(t.Secondary),
+		chroma.LiteralNumber:  colorToHex(t.Secondary),
+	})
+}
@@ -0,0 +1,64 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/tools"
+)
+
+func TestToolResultLines(t *testing.T) {
+	tests := []struct {
+		name          string
+		call          agent.ToolCall
+		content       string
+		want          []string
+		wantHighlight bool
+	}{
+		{
+			name:          "read_file output by extension",
+			call:          agent.ToolCall{Name: tools.ReadToolName, Input: `{"file_path": "/src/main.go"}`},
+			content:       "     1\tpackage main\n     2\t\n     3\tfunc main() {}\n\n(File has 10 total lines)",
+			want:          []string{"     1  package main", "     2  ", "     3  func main() {}", "", "(File has 10 total lines)"},
+			wantHighlight: true,
+		},
+		{
+			name:    "read_file of unknown type",
+			call:    agent.ToolCall{Name: tools.ReadToolName, Input: `{"file_path": "notes.unknownext"}`},
+			content: "     1\thello",
+			want:    []string{"     1\thello"},
+		},
+		{
+			name:          "fenced block",
+			call:          agent.ToolCall{Name: "task"},
+			content:       "Found it:\n```go\nx := 1\n```\ndone",
+			want:          []string{"Found it:", "```go", "x := 1", "```", "done"},
+			wantHighlight: true,
+		},
+		{
+			name:    "plain output",
+			call:    agent.ToolCall{Name: tools.BashToolName},
+			content: "ok\n",
+			want:    []string{"ok"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := toolResultLines(tt.call, tt.content)
+			plain := make([]string, len(lines))
+			for i, line := range lines {
+				plain[i] = ansi.Strip(line)
+			}
+			if strings.Join(plain, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("toolResultLines() = %q, want %q", plain, tt.want)
+			}
+			if highlighted := strings.Join(lines, "\n") != strings.Join(plain, "\n"); highlighted != tt.wantHighlight {
+				t.Errorf("highlighted = %v, want %v", highlighted, tt.wantHighlight)
+			}
+		})
+	}
+}
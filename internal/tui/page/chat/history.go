@@ -0,0 +1,270 @@
+package chat
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/history"
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
+	"github.com/guilhermegouw/cdd/internal/tui/styles"
+)
+
+// maxHistoryMatches is how many prompts the history search shows at once.
+const maxHistoryMatches = 8
+
+// PromptHistory recalls the prompts sent in a project, like a shell history.
+type PromptHistory struct {
+	store   history.Store // Nil keeps the history in memory only
+	project string
+	entries []string // Oldest first
+	index   int      // Entry being shown while browsing; len(entries) otherwise
+}
+
+// NewPromptHistory creates an empty, in-memory prompt history.
+func NewPromptHistory() *PromptHistory {
+	return &PromptHistory{}
+}
+
+// Load replaces the history with the project's saved prompts.
+func (h *PromptHistory) Load(store history.Store, project string) {
+	h.store = store
+	h.project = project
+	h.entries = nil
+
+	saved, err := store.List(context.Background(), project, history.MaxEntries)
+	if err != nil {
+		debug.Error("history", err, "loading prompt history")
+	}
+	for i := len(saved) - 1; i >= 0; i-- {
+		h.entries = append(h.entries, saved[i].Prompt)
+	}
+	h.Reset()
+}
+
+// Add records a sent prompt. Repeating the previous prompt is not recorded
+// twice in a row.
+func (h *PromptHistory) Add(sessionID, prompt string) {
+	h.Reset()
+	if strings.TrimSpace(prompt) == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == prompt) {
+		return
+	}
+	h.entries = append(h.entries, prompt)
+	h.index = len(h.entries)
+
+	if h.store != nil {
+		if err := h.store.Add(context.Background(), h.project, sessionID, prompt); err != nil {
+			debug.Error("history", err, "saving prompt")
+		}
+	}
+}
+
+// Reset stops browsing.
+func (h *PromptHistory) Reset() {
+	h.index = len(h.entries)
+}
+
+// Recalled reports whether value is the entry being browsed, unedited.
+func (h *PromptHistory) Recalled(value string) bool {
+	return h.index < len(h.entries) && h.entries[h.index] == value
+}
+
+// Prev returns the prompt before the one being browsed.
+func (h *PromptHistory) Prev() (string, bool) {
+	if h.index == 0 {
+		return "", false
+	}
+	h.index--
+	return h.entries[h.index], true
+}
+
+// Next returns the prompt after the one being browsed, or "" after the
+// newest, which ends browsing.
+func (h *PromptHistory) Next() (string, bool) {
+	if h.index >= len(h.entries) {
+		return "", false
+	}
+	h.index++
+	if h.index == len(h.entries) {
+		return "", true
+	}
+	return h.entries[h.index], true
+}
+
+// Search returns up to limit distinct prompts that fuzzily match query, best
+// first. Equally good matches are ordered newest first.
+func (h *PromptHistory) Search(query string, limit int) []string {
+	type scored struct {
+		prompt string
+		score  int
+	}
+	seen := make(map[string]bool)
+	var found []scored
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		prompt := h.entries[i]
+		if seen[prompt] {
+			continue
+		}
+		seen[prompt] = true
+		if score, ok := fuzzyScore(query, prompt); ok {
+			found = append(found, scored{prompt, score})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].score > found[j].score
+	})
+
+	matches := make([]string, 0, min(len(found), limit))
+	for i := 0; i < len(found) && i < limit; i++ {
+		matches = append(matches, found[i].prompt)
+	}
+	return matches
+}
+
+// HistorySearch is the ctrl+r search over past prompts. It keeps its own
+// query so the input is left alone until a prompt is picked.
+type HistorySearch struct {
+	history *PromptHistory
+	matches []string
+	query   string
+	cursor  int
+	width   int
+	visible bool
+}
+
+// NewHistorySearch creates a hidden search over h.
+func NewHistorySearch(h *PromptHistory) *HistorySearch {
+	return &HistorySearch{history: h}
+}
+
+// Open shows the search with an empty query.
+func (s *HistorySearch) Open() {
+	s.visible = true
+	s.SetQuery("")
+}
+
+// Close hides the search.
+func (s *HistorySearch) Close() {
+	s.visible = false
+	s.matches = nil
+	s.query = ""
+	s.cursor = 0
+}
+
+// IsVisible reports whether the search is shown.
+func (s *HistorySearch) IsVisible() bool {
+	return s.visible
+}
+
+// Query returns the search text.
+func (s *HistorySearch) Query() string {
+	return s.query
+}
+
+// SetQuery refilters the history by query.
+func (s *HistorySearch) SetQuery(query string) {
+	s.query = query
+	s.cursor = 0
+	s.matches = s.history.Search(query, maxHistoryMatches)
+}
+
+// SetWidth sets the panel width.
+func (s *HistorySearch) SetWidth(width int) {
+	s.width = width
+}
+
+// MoveUp selects the previous match, wrapping around.
+func (s *HistorySearch) MoveUp() {
+	if len(s.matches) > 0 {
+		s.cursor = (s.cursor - 1 + len(s.matches)) % len(s.matches)
+	}
+}
+
+// MoveDown selects the next match, wrapping around.
+func (s *HistorySearch) MoveDown() {
+	if len(s.matches) > 0 {
+		s.cursor = (s.cursor + 1) % len(s.matches)
+	}
+}
+
+// Selected returns the highlighted prompt, or "" when nothing matches.
+func (s *HistorySearch) Selected() string {
+	if s.cursor < len(s.matches) {
+		return s.matches[s.cursor]
+	}
+	return ""
+}
+
+// Height returns the rendered height (0 when hidden).
+func (s *HistorySearch) Height() int {
+	if !s.visible {
+		return 0
+	}
+	return 1 + max(len(s.matches), 1) // Query line + matches
+}
+
+// View renders the query and the matching prompts.
+func (s *HistorySearch) View() string {
+	if !s.visible {
+		return ""
+	}
+
+	t := styles.CurrentTheme()
+	lines := make([]string, 0, s.Height())
+	lines = append(lines, t.S().Muted.Bold(true).Render("─ History search: ")+
+		t.S().Text.Render(s.query+"▏")+
+		t.S().Muted.Render(" (enter to use, esc to cancel)"))
+
+	if len(s.matches) == 0 {
+		lines = append(lines, t.S().Muted.Render("  No matching prompts"))
+	}
+	for i, prompt := range s.matches {
+		// Multi-line prompts are shown on one line.
+		prompt = truncate(strings.ReplaceAll(prompt, "\n", " ⏎ "), max(s.width-6, 10)) //nolint:mnd // Marker and padding
+		if i == s.cursor {
+			lines = append(lines, t.S().Primary.Bold(true).Render("> "+prompt))
+		} else {
+			lines = append(lines, t.S().Text.Render("  "+prompt))
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Padding(0, 1).
+		Width(s.width).
+		Render(strings.Join(lines, "\n"))
+}
+
+// handleHistorySearchKey edits the search query or picks a prompt while the
+// history search is open. Pressing the search key again selects the next match.
+func (m *Model) handleHistorySearchKey(msg tea.KeyMsg) {
+	if keymap.Current().Matches(msg, keymap.HistorySearch) {
+		m.historySearch.MoveDown()
+		return
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.historySearch.Close()
+	case "enter", "tab":
+		if prompt := m.historySearch.Selected(); prompt != "" {
+			m.input.SetValue(prompt)
+		}
+		m.historySearch.Close()
+	case "up", "ctrl+p":
+		m.historySearch.MoveUp()
+	case "down", "ctrl+n":
+		m.historySearch.MoveDown()
+	case "backspace":
+		if query := []rune(m.historySearch.Query()); len(query) > 0 {
+			m.historySearch.SetQuery(string(query[:len(query)-1]))
+		}
+	default:
+		if key, ok := msg.(tea.KeyPressMsg); ok && key.Text != "" {
+			m.historySearch.SetQuery(m.historySearch.Query() + key.Text)
+		}
+	}
+}
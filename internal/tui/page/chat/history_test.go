@@ -0,0 +1,135 @@
+package chat
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/history"
+)
+
+// memoryHistory is a history.Store for tests.
+type memoryHistory struct {
+	added []history.Entry
+}
+
+func (s *memoryHistory) Add(_ context.Context, _, sessionID, prompt string) error {
+	s.added = append(s.added, history.Entry{Prompt: prompt, SessionID: sessionID})
+	return nil
+}
+
+func (s *memoryHistory) List(_ context.Context, _ string, limit int) ([]history.Entry, error) {
+	var entries []history.Entry
+	for i := len(s.added) - 1; i >= 0 && len(entries) < limit; i-- {
+		entries = append(entries, s.added[i])
+	}
+	return entries, nil
+}
+
+func TestPromptHistory_Browse(t *testing.T) {
+	h := NewPromptHistory()
+	for _, p := range []string{"one", "two", "two", "three"} {
+		h.Add("s1", p)
+	}
+
+	var got []string
+	for {
+		p, ok := h.Prev()
+		if !ok {
+			break
+		}
+		got = append(got, p)
+	}
+	if want := []string{"three", "two", "one"}; !slices.Equal(got, want) {
+		t.Fatalf("Prev() walked %v, want %v", got, want)
+	}
+	if !h.Recalled("one") || h.Recalled("edited") {
+		t.Error("Recalled should match only the unedited entry")
+	}
+
+	if p, _ := h.Next(); p != "two" {
+		t.Errorf("Next() = %q, want two", p)
+	}
+	h.Next()
+	if p, ok := h.Next(); !ok || p != "" {
+		t.Errorf("Next() past the newest = %q, %v; want an empty input", p, ok)
+	}
+	if _, ok := h.Next(); ok {
+		t.Error("Next() should stop once browsing has ended")
+	}
+}
+
+func TestPromptHistory_LoadAndSave(t *testing.T) {
+	store := &memoryHistory{}
+	store.Add(context.Background(), "/repo", "old", "saved prompt") //nolint:errcheck // Cannot fail
+
+	h := NewPromptHistory()
+	h.Load(store, "/repo")
+	h.Add("s2", "new prompt")
+
+	if p, _ := h.Prev(); p != "new prompt" {
+		t.Errorf("Prev() = %q, want the newest prompt", p)
+	}
+	if p, _ := h.Prev(); p != "saved prompt" {
+		t.Errorf("Prev() = %q, want the saved prompt", p)
+	}
+	if len(store.added) != 2 || store.added[1].SessionID != "s2" {
+		t.Errorf("new prompts should be saved with their session, got %+v", store.added)
+	}
+}
+
+func TestPromptHistory_Search(t *testing.T) {
+	h := NewPromptHistory()
+	for _, p := range []string{"fix the tests", "explain main.go", "fix lint", "fix the tests"} {
+		h.Add("s1", p)
+	}
+
+	if got, want := h.Search("fix", 10), []string{"fix the tests", "fix lint"}; !slices.Equal(got, want) {
+		t.Errorf("Search(fix) = %v, want %v", got, want)
+	}
+	if got := h.Search("", 2); len(got) != 2 || got[0] != "fix the tests" {
+		t.Errorf("an empty query should list the newest prompts, got %v", got)
+	}
+}
+
+func TestChat_HistoryKeys(t *testing.T) {
+	m := New(nil)
+	m.SetSize(80, 24)
+	m.history.Add("s1", "first")
+	m.history.Add("s1", "second")
+
+	m.Update(tea.KeyPressMsg{Code: tea.KeyUp})
+	m.Update(tea.KeyPressMsg{Code: tea.KeyUp})
+	if got := m.input.Value(); got != "first" {
+		t.Fatalf("two Up presses recalled %q, want first", got)
+	}
+	m.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	if got := m.input.Value(); got != "second" {
+		t.Errorf("Down recalled %q, want second", got)
+	}
+
+	// Once the recalled prompt is edited, Up is left to the input.
+	m.Update(tea.KeyPressMsg{Code: '!', Text: "!"})
+	m.Update(tea.KeyPressMsg{Code: tea.KeyUp})
+	if got := m.input.Value(); got != "second!" {
+		t.Errorf("Up on an edited prompt changed the input to %q", got)
+	}
+
+	m.input.Clear()
+	m.Update(tea.KeyPressMsg{Code: 'r', Mod: tea.ModCtrl})
+	if !m.historySearch.IsVisible() {
+		t.Fatal("ctrl+r should open the history search")
+	}
+	for _, r := range "fst" {
+		m.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if m.input.Value() != "" {
+		t.Errorf("typing should edit the search query, not the input %q", m.input.Value())
+	}
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if got := m.input.Value(); got != "first" || m.historySearch.IsVisible() {
+		t.Errorf("enter should put the match in the input, got %q", got)
+	}
+}
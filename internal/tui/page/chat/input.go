@@ -3,6 +3,7 @@ package chat
 import (
 	"fmt"
 	"strings"
+	"unicode"
 
 	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/textarea"
@@ -10,6 +11,7 @@ import (
 	"charm.land/lipgloss/v2"
 
 	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
 	"github.com/guilhermegouw/cdd/internal/tui/styles"
 )
 
@@ -18,13 +20,18 @@ type Input struct {
 	textArea textarea.Model
 	width    int
 	enabled  bool
-	viewID   int // Debug: track view renders
+	viewID   int       // Debug: track view renders
+	vim      *vimState // Nil unless vim mode is enabled
 }
 
 // NewInput creates a new input component.
 func NewInput() *Input {
 	ta := textarea.New()
-	ta.Placeholder = "Type a message... (ctrl+j for newline)"
+	km := keymap.Current()
+	ta.Placeholder = "Type a message..."
+	if newline := km.Key(keymap.Newline); newline != "" {
+		ta.Placeholder = fmt.Sprintf("Type a message... (%s for newline)", newline)
+	}
 	ta.CharLimit = 4096
 	ta.MaxHeight = 5 // Allow up to 5 lines
 	ta.SetHeight(1)  // Start with single line
@@ -37,12 +44,9 @@ func NewInput() *Input {
 	taStyles.Blurred.CursorLine = lipgloss.NewStyle()
 	ta.SetStyles(taStyles)
 
-	// Customize key bindings: Enter should NOT insert newline (we handle submit externally)
-	// ctrl+j will insert newline
-	ta.KeyMap.InsertNewline = key.NewBinding(
-		key.WithKeys("ctrl+j"),
-		key.WithHelp("ctrl+j", "new line"),
-	)
+	// Enter should NOT insert a newline (we handle submit externally);
+	// the newline action does.
+	ta.KeyMap.InsertNewline = km.Binding(keymap.Newline)
 
 	return &Input{
 		textArea: ta,
@@ -64,6 +68,23 @@ func (i *Input) Update(msg tea.Msg) (*Input, tea.Cmd) {
 	// Track lines before update to detect large content changes (like paste)
 	linesBefore := i.textArea.LineCount()
 
+	if keyMsg, ok := msg.(tea.KeyPressMsg); ok && i.vim != nil {
+		switch {
+		case i.vim.mode == vimNormal:
+			buf := i.vimBuffer()
+			before := string(buf.text)
+			i.vim.handleKey(keyMsg.String(), &buf)
+			i.setVimBuffer(buf, string(buf.text) != before)
+			i.fitHeight(linesBefore)
+			return i, nil
+		case keyMsg.String() == "esc":
+			buf := i.vimBuffer()
+			i.vim.enterNormal(&buf)
+			i.setVimBuffer(buf, false)
+			return i, nil
+		}
+	}
+
 	// Pre-expand height before processing newline to prevent viewport scrolling.
 	// This ensures the textarea has room for the new line before it's added,
 	// so the viewport doesn't scroll and hide the first line.
@@ -80,7 +101,13 @@ func (i *Input) Update(msg tea.Msg) (*Input, tea.Cmd) {
 
 	var cmd tea.Cmd
 	i.textArea, cmd = i.textArea.Update(msg)
+	i.fitHeight(linesBefore)
 
+	return i, cmd
+}
+
+// fitHeight resizes the textarea to its content, up to five lines.
+func (i *Input) fitHeight(linesBefore int) {
 	// Adjust height based on actual content (handles deletions and other changes)
 	actualLines := i.textArea.LineCount()
 	displayLines := actualLines
@@ -96,8 +123,94 @@ func (i *Input) Update(msg tea.Msg) (*Input, tea.Cmd) {
 	if actualLines > 5 && actualLines-linesBefore > 1 {
 		i.textArea.MoveToEnd()
 	}
+}
 
-	return i, cmd
+// vimBuffer returns the input text and the cursor offset within it.
+func (i *Input) vimBuffer() vimBuffer {
+	text := []rune(i.textArea.Value())
+	row := i.textArea.Line()
+	li := i.textArea.LineInfo()
+
+	pos := 0
+	for line := 0; line < row && pos < len(text); pos++ {
+		if text[pos] == '\n' {
+			line++
+		}
+	}
+	return vimBuffer{text: text, pos: min(pos+li.StartColumn+li.ColumnOffset, len(text))}
+}
+
+// setVimBuffer writes back the text, if it changed, and moves the cursor.
+func (i *Input) setVimBuffer(buf vimBuffer, changed bool) {
+	if changed {
+		i.textArea.SetValue(string(buf.text))
+	}
+
+	row, col := 0, 0
+	for _, r := range buf.text[:buf.pos] {
+		col++
+		if r == '\n' {
+			row++
+			col = 0
+		}
+	}
+
+	// CursorDown steps through soft-wrapped lines, so it may take more than
+	// one call per row.
+	i.textArea.MoveToBegin()
+	for n := 0; i.textArea.Line() < row && n <= len(buf.text); n++ {
+		i.textArea.CursorDown()
+	}
+	i.textArea.SetCursorColumn(col)
+}
+
+// wordStart returns the offset of the whitespace-delimited word that ends at
+// the cursor.
+func wordStart(buf vimBuffer) int {
+	start := buf.pos
+	for start > 0 && !unicode.IsSpace(buf.text[start-1]) {
+		start--
+	}
+	return start
+}
+
+// CurrentWord returns the whitespace-delimited word that ends at the cursor.
+func (i *Input) CurrentWord() string {
+	buf := i.vimBuffer()
+	return string(buf.text[wordStart(buf):buf.pos])
+}
+
+// ReplaceCurrentWord replaces the word that ends at the cursor with s and
+// moves the cursor after it.
+func (i *Input) ReplaceCurrentWord(s string) {
+	linesBefore := i.textArea.LineCount()
+	buf := i.vimBuffer()
+	start := wordStart(buf)
+
+	text := make([]rune, 0, len(buf.text)+len(s))
+	text = append(text, buf.text[:start]...)
+	text = append(text, []rune(s)...)
+	text = append(text, buf.text[buf.pos:]...)
+	i.setVimBuffer(vimBuffer{text: text, pos: start + len([]rune(s))}, true)
+	i.fitHeight(linesBefore)
+}
+
+// SetVimMode enables or disables vim-style modal editing.
+func (i *Input) SetVimMode(enabled bool) {
+	switch {
+	case enabled && i.vim == nil:
+		i.vim = newVimState()
+	case !enabled:
+		i.vim = nil
+	}
+}
+
+// Mode returns the vim mode name, or "" when vim mode is disabled.
+func (i *Input) Mode() string {
+	if i.vim == nil {
+		return ""
+	}
+	return i.vim.mode.String()
 }
 
 // View renders the input.
@@ -124,7 +237,7 @@ func (i *Input) View() string {
 	}
 
 	inputStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(styles.Border()).
 		BorderForeground(t.BorderFocus).
 		Padding(0, 1).
 		Width(width)
@@ -164,15 +277,24 @@ func (i *Input) Value() string {
 	return i.textArea.Value()
 }
 
-// SetValue sets the input value.
+// SetValue sets the input value, moves the cursor to the end and resizes
+// the input to fit.
 func (i *Input) SetValue(value string) {
+	linesBefore := i.textArea.LineCount()
 	i.textArea.SetValue(value)
+	i.fitHeight(linesBefore)
 }
 
 // Clear clears the input.
 func (i *Input) Clear() {
 	i.textArea.SetValue("")
 	i.textArea.SetHeight(1)
+	if i.vim != nil {
+		// Each message starts in insert mode with a fresh undo history.
+		i.vim.mode = vimInsert
+		i.vim.pending = nil
+		i.vim.undo = nil
+	}
 }
 
 // Enable enables the input.
@@ -0,0 +1,229 @@
+package chat
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/jobs"
+	"github.com/guilhermegouw/cdd/internal/tui/components/sessions"
+	"github.com/guilhermegouw/cdd/internal/tui/styles"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// maxJobLines is the most jobs the panel lists; /jobs shows them all.
+const maxJobLines = 5
+
+// JobsPanel lists the background jobs with what each is doing. It is shared
+// by every tab, since jobs don't belong to the session they were started
+// from.
+type JobsPanel struct {
+	jobs  *jobs.Manager
+	width int
+}
+
+// NewJobsPanel creates a panel listing the jobs of a manager, which may be
+// nil.
+func NewJobsPanel(manager *jobs.Manager) *JobsPanel {
+	return &JobsPanel{jobs: manager}
+}
+
+// SetWidth sets the panel width.
+func (p *JobsPanel) SetWidth(width int) {
+	p.width = width
+}
+
+// list returns the jobs shown, newest last.
+func (p *JobsPanel) list() []jobs.Job {
+	if p.jobs == nil {
+		return nil
+	}
+	list := p.jobs.List()
+	if len(list) > maxJobLines {
+		list = list[len(list)-maxJobLines:]
+	}
+	return list
+}
+
+// Height returns the current height of the panel (0 when there are no jobs).
+func (p *JobsPanel) Height() int {
+	n := len(p.list())
+	if n == 0 {
+		return 0
+	}
+	return n + 1 // Header
+}
+
+// IsActive returns true if the panel has jobs to show.
+func (p *JobsPanel) IsActive() bool {
+	return p.Height() > 0
+}
+
+// View renders the jobs panel.
+func (p *JobsPanel) View() string {
+	list := p.list()
+	if len(list) == 0 {
+		return ""
+	}
+	t := styles.CurrentTheme()
+	lines := []string{t.S().Muted.Bold(true).Render("─ Background jobs ")}
+	for i := range list {
+		lines = append(lines, ansi.Truncate(jobLine(&list[i]), p.width-2, "…")) //nolint:mnd // Padding
+	}
+	return lipgloss.NewStyle().Padding(0, 1).Width(p.width).Render(strings.Join(lines, "\n"))
+}
+
+// jobLine renders a job with its state, e.g. "● #1 fix the tests  → bash · 3 tools · 1m20s".
+func jobLine(job *jobs.Job) string {
+	t := styles.CurrentTheme()
+	prompt := strings.Join(strings.Fields(job.Prompt), " ")
+	if len([]rune(prompt)) > 40 { //nolint:mnd // Enough to tell jobs apart
+		prompt = string([]rune(prompt)[:39]) + "…"
+	}
+	head := fmt.Sprintf("#%d %s", job.ID, prompt)
+	elapsed := job.Elapsed().Truncate(time.Second).String()
+
+	switch job.Status {
+	case jobs.StatusRunning:
+		detail := []string{}
+		if job.Activity != "" {
+			detail = append(detail, job.Activity)
+		}
+		if job.ToolCalls > 0 {
+			detail = append(detail, fmt.Sprintf("%d tools", job.ToolCalls))
+		}
+		detail = append(detail, elapsed)
+		return "  " + t.S().Warning.Render("●") + " " + t.S().Text.Render(head) + "  " + t.S().Muted.Render(strings.Join(detail, " · "))
+	case jobs.StatusCompleted:
+		return "  " + t.S().Success.Render("✓") + " " + t.S().Text.Render(head) + "  " + t.S().Muted.Render("done in "+elapsed)
+	case jobs.StatusFailed:
+		return "  " + t.S().Error.Render("✗") + " " + t.S().Text.Render(head) + "  " + t.S().Error.Render("failed: "+job.Err.Error())
+	case jobs.StatusCancelled:
+		return "  " + t.S().Muted.Render("■") + " " + t.S().Muted.Render(head+"  cancelled")
+	}
+	return "  " + head
+}
+
+// SetJobs sets the manager that runs background jobs for /bg and /jobs.
+func (m *Model) SetJobs(manager *jobs.Manager) {
+	m.jobs = manager
+	m.jobsPanel = NewJobsPanel(manager)
+}
+
+// handleBackground runs /bg: the prompt runs in a session of its own while
+// this one stays free.
+func (m *Model) handleBackground(args []string) tea.Cmd {
+	if m.jobs == nil {
+		return util.ReportWarn("Background jobs are not available")
+	}
+	prompt := strings.Join(args, " ")
+	if prompt == "" {
+		return util.ReportWarn("Usage: /bg PROMPT")
+	}
+	opts := jobs.Options{WorkingDir: m.workingDir()}
+	if m.cfg != nil {
+		opts.ThinkingBudget = m.cfg.ThinkingBudget(config.SelectedModelTypeLarge)
+	}
+	job := m.jobs.Start(prompt, opts)
+	return util.ReportInfo(fmt.Sprintf("Started job #%d in the background; /jobs lists jobs", job.ID))
+}
+
+// handleJobs runs /jobs, which lists the background jobs, or opens, cancels
+// or clears them.
+func (m *Model) handleJobs(args []string) tea.Cmd {
+	if m.jobs == nil {
+		return util.ReportWarn("Background jobs are not available")
+	}
+	if len(args) == 0 {
+		m.messages.AppendMessage(agent.Message{
+			Role:    agent.RoleSystem,
+			Content: jobsReport(m.jobs.List()),
+		})
+		return nil
+	}
+
+	action := strings.ToLower(args[0])
+	if action == "clear" {
+		m.jobs.Clear()
+		return util.ReportInfo("Cleared finished jobs")
+	}
+	if len(args) != 2 || (action != "open" && action != "cancel") { //nolint:mnd // Action and job
+		return util.ReportWarn("Usage: /jobs [open N | cancel N | clear]")
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(args[1], "#"))
+	if err != nil {
+		return util.ReportWarn(fmt.Sprintf("%q is not a job number", args[1]))
+	}
+	job, ok := m.jobs.Get(id)
+	if !ok {
+		return util.ReportWarn(fmt.Sprintf("No job #%d", id))
+	}
+	if action == "cancel" {
+		if err := m.jobs.Cancel(id); err != nil {
+			return util.ReportWarn(err.Error())
+		}
+		return nil
+	}
+	if m.isStreaming {
+		return util.ReportWarn("Wait for the reply to finish, or open the job in a new tab with /tab")
+	}
+	return util.CmdHandler(sessions.SwitchSessionMsg{SessionID: job.SessionID})
+}
+
+// handleJobEvent announces jobs that finish. If the terminal is in the
+// background it also rings the bell, and shows a desktop notification when
+// enabled, unless notifications are turned off.
+func (m *Model) handleJobEvent(e events.JobEvent) tea.Cmd {
+	if !e.Finished() {
+		return nil
+	}
+	var report tea.Cmd
+	var summary string
+	switch e.Type { //nolint:exhaustive // Only finished jobs get here
+	case events.JobEventCompleted:
+		summary = fmt.Sprintf("Job #%d finished", e.JobID)
+		report = util.ReportSuccess(summary + fmt.Sprintf("; /jobs open %d shows it", e.JobID))
+	case events.JobEventFailed:
+		summary = fmt.Sprintf("Job #%d failed: %v", e.JobID, e.Error)
+		report = util.ReportError(fmt.Errorf("job #%d failed: %w", e.JobID, e.Error))
+	default:
+		return util.ReportInfo(fmt.Sprintf("Job #%d cancelled", e.JobID))
+	}
+	cfg := m.cfg
+	if cfg == nil {
+		cfg = config.NewConfig()
+	}
+	if m.focused || cfg.NotifyAfter() == 0 { // Notifications are off
+		return report
+	}
+
+	bell := tea.Raw("\a")
+	if !cfg.DesktopNotifications() {
+		return tea.Batch(report, bell)
+	}
+	return tea.Batch(report, bell, desktopNotification(notificationTitle, summary))
+}
+
+// jobsReport lists the background jobs for /jobs.
+func jobsReport(list []jobs.Job) string {
+	if len(list) == 0 {
+		return "No background jobs. Start one with /bg PROMPT."
+	}
+	var b strings.Builder
+	b.WriteString("Background jobs:\n")
+	for i := range list {
+		job := &list[i]
+		fmt.Fprintf(&b, "\n#%d  %s  %s  (session %s)\n    %s\n",
+			job.ID, job.Status, job.Elapsed().Truncate(time.Second), job.SessionID, job.Prompt)
+	}
+	b.WriteString("\n/jobs open N shows a job's session, /jobs cancel N stops it and /jobs clear removes finished jobs.")
+	return b.String()
+}
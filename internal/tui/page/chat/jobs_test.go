@@ -0,0 +1,84 @@
+package chat
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/jobs"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// jobAgent finishes every prompt at once.
+type jobAgent struct {
+	sessions *agent.SessionStore
+}
+
+func (a *jobAgent) Send(context.Context, string, agent.SendOptions, agent.StreamCallbacks) error {
+	return nil
+}
+
+func (a *jobAgent) Sessions() agent.Sessions {
+	return a.sessions
+}
+
+func (a *jobAgent) SetSessionWorkingDir(string, string) bool {
+	return true
+}
+
+func TestJobsPanel(t *testing.T) {
+	p := NewJobsPanel(nil)
+	if p.Height() != 0 || p.IsActive() {
+		t.Error("a panel without a manager should be hidden")
+	}
+
+	manager := jobs.NewManager(&jobAgent{sessions: agent.NewSessionStore()}, nil)
+	p = NewJobsPanel(manager)
+	p.SetWidth(80)
+	for range maxJobLines + 2 {
+		manager.Start("refactor the parser", jobs.Options{})
+	}
+	manager.Wait()
+
+	if h := p.Height(); h != maxJobLines+1 {
+		t.Errorf("Height() = %d, want %d jobs and the header", h, maxJobLines)
+	}
+	view := ansi.Strip(p.View())
+	if strings.Contains(view, "#1 ") || !strings.Contains(view, "✓ #7 refactor the parser") {
+		t.Errorf("View() should list the newest jobs as done, got:\n%s", view)
+	}
+}
+
+func TestHandleJobs(t *testing.T) {
+	m := New(nil)
+	msg := m.handleBackground([]string{"fix", "it"})()
+	if info, ok := msg.(util.InfoMsg); !ok || info.Type != util.InfoTypeWarn {
+		t.Errorf("/bg without jobs = %#v, want a warning", msg)
+	}
+
+	manager := jobs.NewManager(&jobAgent{sessions: agent.NewSessionStore()}, nil)
+	m.SetJobs(manager)
+	if msg := m.handleBackground(nil)(); !strings.Contains(msg.(util.InfoMsg).Msg, "Usage") {
+		t.Errorf("/bg without a prompt = %#v, want the usage", msg)
+	}
+	if msg := m.handleBackground([]string{"fix", "it"})(); !strings.Contains(msg.(util.InfoMsg).Msg, "#1") {
+		t.Errorf("/bg = %#v, want job #1 started", msg)
+	}
+	manager.Wait()
+
+	if job, _ := manager.Get(1); job.Prompt != "fix it" {
+		t.Errorf("prompt = %q, want the arguments joined", job.Prompt)
+	}
+	if msg := m.handleJobs([]string{"open", "9"})(); !strings.Contains(msg.(util.InfoMsg).Msg, "No job #9") {
+		t.Errorf("/jobs open 9 = %#v, want no such job", msg)
+	}
+	if got := jobsReport(manager.List()); !strings.Contains(got, "#1  completed") {
+		t.Errorf("jobsReport() = %q, want job 1 completed", got)
+	}
+	if got := jobsReport(nil); !strings.Contains(got, "/bg PROMPT") {
+		t.Errorf("jobsReport(nil) = %q, want a hint", got)
+	}
+}
@@ -0,0 +1,95 @@
+package chat
+
+import (
+	"strings"
+
+	"charm.land/lipgloss/v2"
+
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
+	"github.com/guilhermegouw/cdd/internal/tui/styles"
+)
+
+// HelpOverlay lists the active key bindings.
+type HelpOverlay struct {
+	visible bool
+	width   int
+	height  int
+}
+
+// NewHelpOverlay creates a hidden help overlay.
+func NewHelpOverlay() *HelpOverlay {
+	return &HelpOverlay{}
+}
+
+// Show makes the overlay visible.
+func (h *HelpOverlay) Show() {
+	h.visible = true
+}
+
+// Hide hides the overlay.
+func (h *HelpOverlay) Hide() {
+	h.visible = false
+}
+
+// IsVisible reports whether the overlay is shown.
+func (h *HelpOverlay) IsVisible() bool {
+	return h.visible
+}
+
+// SetSize sets the area the overlay is centered in.
+func (h *HelpOverlay) SetSize(width, height int) {
+	h.width = width
+	h.height = height
+}
+
+// View renders the key bindings grouped by context.
+func (h *HelpOverlay) View() string {
+	t := styles.CurrentTheme()
+	km := keymap.Current()
+
+	keyStyle := t.S().Primary
+	helpStyle := t.S().Muted
+
+	var sections []string
+	for _, group := range km.Groups() {
+		keyWidth := 0
+		keys := make([]string, len(group.Entries))
+		for i, e := range group.Entries {
+			keys[i] = strings.Join(e.Keys, "/")
+			if keys[i] == "" {
+				keys[i] = "unbound"
+			}
+			keyWidth = max(keyWidth, lipgloss.Width(keys[i]))
+		}
+
+		lines := []string{t.S().Subtle.Render(group.Name)}
+		for i, e := range group.Entries {
+			lines = append(lines, keyStyle.Width(keyWidth+2).Render(keys[i])+helpStyle.Render(e.Help))
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	hint := "[" + km.Key(keymap.Back) + "] close"
+	if km.Key(keymap.Back) == "" {
+		hint = "[" + km.Key(keymap.Help) + "] close"
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		t.S().Title.MarginBottom(1).Render("Key Bindings"),
+		strings.Join(sections, "\n\n"),
+		"",
+		t.S().Muted.Render(hint),
+	)
+
+	box := lipgloss.NewStyle().
+		Border(styles.Border()).
+		BorderForeground(t.BorderFocus).
+		Padding(1, 2).
+		Render(content)
+
+	return lipgloss.Place(
+		h.width, h.height,
+		lipgloss.Center, lipgloss.Center,
+		box,
+	)
+}
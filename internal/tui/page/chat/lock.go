@@ -0,0 +1,70 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/session"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// holdSession locks the session shown against other cdd processes, letting
+// go of the one shown before. When another cdd is using it the user is asked
+// whether to take it over; until then it can be read but prompts are
+// refused.
+func (m *Model) holdSession() {
+	locks := m.agent.Locks()
+	if locks == nil || m.locked == m.sessionID {
+		return
+	}
+	m.releaseSession()
+
+	err := locks.Lock(m.sessionID, false)
+	var locked *session.LockedError
+	switch {
+	case errors.As(err, &locked):
+		m.takeover = locked
+		m.status.SetNotice(locked.Error() + ". Take it over? (y/n)")
+	case err != nil:
+		debug.Log("Locking session %s: %v", m.sessionID, err)
+	default:
+		m.locked = m.sessionID
+	}
+}
+
+// releaseSession lets go of the lock this page holds, if any.
+func (m *Model) releaseSession() {
+	if m.locked != "" {
+		m.agent.Locks().Unlock(m.locked)
+		m.locked = ""
+	}
+}
+
+// handleTakeoverKey takes the session over from the other cdd, which stops
+// writing to it, or leaves it to that cdd.
+func (m *Model) handleTakeoverKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "y", "Y":
+		m.takeover = nil
+		m.status.SetNotice("")
+		if err := m.agent.Locks().Lock(m.sessionID, true); err != nil {
+			return util.ReportError(fmt.Errorf("taking over session: %w", err))
+		}
+		m.locked = m.sessionID
+		return util.ReportSuccess("Took over the session")
+	case "n", "N", "esc":
+		holder := m.takeover.Holder
+		m.takeover = nil
+		m.status.SetNotice("")
+		return util.ReportWarn(fmt.Sprintf("Read-only while PID %d uses this session", holder.PID))
+	}
+	return nil
+}
+
+// Close lets go of the session shown, when the page's tab is closed.
+func (m *Model) Close() {
+	m.releaseSession()
+}
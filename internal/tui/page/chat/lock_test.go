@@ -0,0 +1,47 @@
+package chat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/session"
+)
+
+func TestChat_TakesOverSessionInUse(t *testing.T) {
+	dir := t.TempDir()
+	locks := session.NewLocker(dir, "cdd")
+	ag := agent.New(agent.Config{Locks: locks})
+	sessionID := ag.Sessions().Current().ID
+
+	// Another cdd, the test's parent process, has the session open.
+	lock := fmt.Sprintf(`{"pid":%d,"instance":"other"}`, os.Getppid())
+	if err := os.WriteFile(filepath.Join(dir, sessionID+".lock"), []byte(lock), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New(ag)
+	m.Init()
+	if m.takeover == nil || !strings.Contains(m.status.notice, fmt.Sprintf("PID %d", os.Getppid())) {
+		t.Fatalf("notice = %q, want the session reported in use", m.status.notice)
+	}
+
+	m.Update(tea.KeyPressMsg{Code: 'y', Text: "y"})
+	if m.takeover != nil || m.locked != sessionID {
+		t.Fatal("y should take the session over")
+	}
+	if err := locks.Check(sessionID); err != nil {
+		t.Errorf("Check() error = %v after the takeover", err)
+	}
+
+	// Closing the tab lets go of it.
+	m.Close()
+	if _, ok := locks.Holder(sessionID); ok {
+		t.Error("the lock should be released when the tab closes")
+	}
+}
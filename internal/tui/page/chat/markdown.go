@@ -10,6 +10,7 @@ import (
 	glamourstyles "github.com/charmbracelet/glamour/styles"
 	"github.com/muesli/termenv"
 
+	"github.com/guilhermegouw/cdd/internal/config"
 	"github.com/guilhermegouw/cdd/internal/tui/styles"
 )
 
@@ -17,17 +18,41 @@ import (
 type MarkdownRenderer struct {
 	renderer    *glamour.TermRenderer
 	cachedWidth int
+	options     config.MarkdownOptions
 	mu          sync.RWMutex
 }
 
 // NewMarkdownRenderer creates a new markdown renderer.
 func NewMarkdownRenderer() *MarkdownRenderer {
-	return &MarkdownRenderer{}
+	return &MarkdownRenderer{options: config.MarkdownOptions{Style: config.MarkdownStyleDark}}
+}
+
+// Options returns the style, wrap column and code theme in use.
+func (m *MarkdownRenderer) Options() config.MarkdownOptions {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.options
+}
+
+// SetOptions changes the style, wrap column and code theme, and reports
+// whether they changed.
+func (m *MarkdownRenderer) SetOptions(opts config.MarkdownOptions) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.options == opts {
+		return false
+	}
+	m.options = opts
+	m.renderer = nil
+	return true
 }
 
 // Render renders markdown content to styled terminal output.
 // It caches the renderer and recreates it only when width changes.
-func (m *MarkdownRenderer) Render(content string, width int) (string, error) {
+// Unbalanced code fences are repaired first. Content that still fails to
+// render is returned as it is, with the error, so the caller can show that
+// one message as plain text.
+func (m *MarkdownRenderer) Render(content string, width int) (rendered string, err error) {
 	if content == "" {
 		return "", nil
 	}
@@ -37,12 +62,26 @@ func (m *MarkdownRenderer) Render(content string, width int) (string, error) {
 		return content, err // Fallback to plain text
 	}
 
-	rendered, err := renderer.Render(content)
-	if err != nil {
-		return content, err // Fallback to plain text
-	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("rendering markdown: %v", r)
+		}
+		if err != nil {
+			// A failed render may leave the renderer mid-block; start afresh.
+			m.reset(renderer)
+			rendered = content
+		}
+	}()
+	return renderer.Render(repairFences(content)) //nolint:wrapcheck // Shown as plain text instead
+}
 
-	return rendered, nil
+// reset drops renderer from the cache, unless it was already replaced.
+func (m *MarkdownRenderer) reset(renderer *glamour.TermRenderer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.renderer == renderer {
+		m.renderer = nil
+	}
 }
 
 func (m *MarkdownRenderer) getRenderer(width int) (*glamour.TermRenderer, error) {
@@ -61,12 +100,21 @@ func (m *MarkdownRenderer) getRenderer(width int) (*glamour.TermRenderer, error)
 		return m.renderer, nil
 	}
 
+	wrap := width
+	if m.options.WordWrap > 0 {
+		wrap = min(wrap, m.options.WordWrap)
+	}
+	profile := termenv.TrueColor
+	if m.options.Style == config.MarkdownStyleNoTTY {
+		profile = termenv.Ascii
+	}
+
 	style := m.buildStyle()
 	renderer, err := glamour.NewTermRenderer(
 		glamour.WithStyles(style),
-		glamour.WithWordWrap(width),
+		glamour.WithWordWrap(wrap),
 		glamour.WithEmoji(),
-		glamour.WithColorProfile(termenv.TrueColor),
+		glamour.WithColorProfile(profile),
 	)
 	if err != nil {
 		return nil, err
@@ -77,8 +125,27 @@ func (m *MarkdownRenderer) getRenderer(width int) (*glamour.TermRenderer, error)
 	return renderer, nil
 }
 
-// buildStyle creates a Glamour style config that matches the app theme.
+// buildStyle returns the Glamour style config of the configured style, with
+// code blocks in the configured theme.
 func (m *MarkdownRenderer) buildStyle() ansi.StyleConfig {
+	var style ansi.StyleConfig
+	switch m.options.Style {
+	case config.MarkdownStyleLight:
+		style = glamourstyles.LightStyleConfig
+	case config.MarkdownStyleNoTTY:
+		return glamourstyles.NoTTYStyleConfig
+	default:
+		style = themedStyle()
+	}
+	if m.options.CodeTheme != "" {
+		style.CodeBlock.Chroma = nil // Takes precedence over the theme
+		style.CodeBlock.Theme = m.options.CodeTheme
+	}
+	return style
+}
+
+// themedStyle creates a Glamour style config that matches the app theme.
+func themedStyle() ansi.StyleConfig {
 	t := styles.CurrentTheme()
 
 	// Start with the dark style as a base
@@ -4,6 +4,8 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+
+	"github.com/guilhermegouw/cdd/internal/config"
 )
 
 // stripANSI removes ANSI escape codes from a string for testing purposes.
@@ -205,3 +207,54 @@ func TestMarkdownRenderer_NarrowWidth(t *testing.T) {
 		t.Error("Render() expected non-empty output")
 	}
 }
+
+func TestMarkdownRenderer_Options(t *testing.T) {
+	r := NewMarkdownRenderer()
+	const content = "# Title\n\nSome **bold** words in a sentence long enough to wrap.\n\n```go\nx := 1\n```"
+
+	dark, err := r.Render(content, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.SetOptions(r.Options()) {
+		t.Error("SetOptions() with the same options should report no change")
+	}
+
+	r.SetOptions(config.MarkdownOptions{Style: config.MarkdownStyleLight})
+	light, err := r.Render(content, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if light == dark {
+		t.Error("the light style should render differently")
+	}
+
+	r.SetOptions(config.MarkdownOptions{Style: config.MarkdownStyleNoTTY})
+	plain, err := r.Render(content, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(plain, "\x1b[") {
+		t.Errorf("the notty style should have no colors:\n%q", plain)
+	}
+
+	r.SetOptions(config.MarkdownOptions{Style: config.MarkdownStyleNoTTY, WordWrap: 30})
+	wrapped, err := r.Render(content, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for line := range strings.SplitSeq(wrapped, "\n") {
+		if width := len(strings.TrimRight(line, " ")); width > 30 {
+			t.Errorf("line %q is %d columns, want at most 30", line, width)
+		}
+	}
+
+	r.SetOptions(config.MarkdownOptions{Style: config.MarkdownStyleDark, CodeTheme: "github"})
+	themed, err := r.Render(content, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if themed == dark {
+		t.Error("the code theme should change how code blocks render")
+	}
+}
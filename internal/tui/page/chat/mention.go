@@ -0,0 +1,199 @@
+package chat
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/tools"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// mentionPattern matches @path mentions at the start of the prompt or after
+// whitespace, so email addresses are left alone.
+var mentionPattern = regexp.MustCompile(`(?:^|\s)@(\S+)`)
+
+// workingDir returns the session's working directory, which mentioned paths
+// are relative to.
+func (m *Model) workingDir() string {
+	if m.agent != nil {
+		if dir := m.agent.SessionWorkingDir(m.sessionID); dir != "" {
+			return dir
+		}
+	}
+	dir, _ := os.Getwd() //nolint:errcheck // Relative paths still resolve against "."
+	return dir
+}
+
+// updateFilePicker opens, refilters or closes the file picker to follow the
+// word being typed. Typing @ opens it; it stays open until the word ends.
+// Typing / at the start of the prompt opens it over the slash commands
+// instead, until the command name is complete.
+func (m *Model) updateFilePicker() {
+	word := m.input.CurrentWord()
+	if !m.input.IsEnabled() || m.input.Mode() == vimNormal.String() {
+		m.filePicker.Close()
+		return
+	}
+
+	value := m.input.Value()
+	if strings.HasPrefix(value, "/") && word == value {
+		query := strings.TrimPrefix(value, "/")
+		if m.filePicker.IsCommands() {
+			m.filePicker.SetQuery(query)
+		} else {
+			m.openCommandPicker(query)
+		}
+		return
+	}
+	if m.filePicker.IsCommands() {
+		m.filePicker.Close()
+	}
+
+	if word == "" || (!strings.HasPrefix(word, "@") && !m.filePicker.IsVisible()) {
+		m.filePicker.Close()
+		return
+	}
+
+	query := strings.TrimPrefix(word, "@")
+	if m.filePicker.IsVisible() {
+		m.filePicker.SetQuery(query)
+		return
+	}
+	m.filePicker.Open(m.projectFiles(), query)
+}
+
+// completePath opens the file picker for the path fragment before the cursor,
+// or inserts the only match straight away. It reports whether the word looked
+// like a relative path; slash commands and mentions are left alone.
+func (m *Model) completePath() bool {
+	word := m.input.CurrentWord()
+	if !strings.ContainsAny(word, "/.") || strings.HasPrefix(word, "/") || strings.HasPrefix(word, "@") {
+		return false
+	}
+	m.filePicker.Open(m.projectFiles(), word)
+	if matches := m.filePicker.matches; len(matches) == 1 {
+		m.acceptFile()
+	}
+	return true
+}
+
+// openCommandPicker shows the slash commands matching query.
+func (m *Model) openCommandPicker(query string) {
+	cmds := m.commandRegistry.GetCommands()
+	names := make([]string, len(cmds))
+	descriptions := make(map[string]string, len(cmds))
+	for i, cmd := range cmds {
+		names[i] = cmd.Name
+		descriptions[cmd.Name] = cmd.Description
+	}
+	m.filePicker.OpenCommands(names, descriptions, query)
+}
+
+// acceptFile replaces the word being typed with a mention of the selected
+// file, or with the selected command.
+func (m *Model) acceptFile() {
+	if path := m.filePicker.Selected(); path != "" {
+		if m.filePicker.IsCommands() {
+			m.input.ReplaceCurrentWord("/" + path + " ")
+		} else {
+			m.input.ReplaceCurrentWord("@" + path + " ")
+		}
+	}
+	m.filePicker.Close()
+}
+
+// handleFilePickerKey handles navigation keys while the picker is open. It
+// reports whether the key was consumed.
+func (m *Model) handleFilePickerKey(msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "up", "ctrl+p":
+		m.filePicker.MoveUp()
+	case "down", "ctrl+n":
+		m.filePicker.MoveDown()
+	case "tab", "enter":
+		if msg.String() == "enter" && m.filePicker.IsCommands() &&
+			m.filePicker.Selected() == strings.ToLower(m.filePicker.query) {
+			// The command is typed out in full, so enter runs it.
+			m.filePicker.Close()
+			return false
+		}
+		if m.filePicker.Selected() == "" {
+			m.filePicker.Close()
+			return msg.String() == "tab"
+		}
+		m.acceptFile()
+	case "esc":
+		m.filePicker.Close()
+	default:
+		return false
+	}
+	return true
+}
+
+// projectFiles lists the working directory for the file picker, skipping
+// hidden and gitignored files.
+func (m *Model) projectFiles() []string {
+	files, err := tools.ListFiles(context.Background(), m.workingDir(), maxPickerFiles)
+	if err != nil {
+		return nil
+	}
+	return files
+}
+
+// mentionedFiles loads the files mentioned in prompt as @path. Mentions that
+// are not files, such as @someone, are ignored; files that cannot be included
+// are reported as warnings.
+func (m *Model) mentionedFiles(prompt string) ([]agent.Attachment, []tea.Cmd) {
+	dir := m.workingDir()
+	seen := make(map[string]bool)
+
+	var files []agent.Attachment
+	var warnings []tea.Cmd
+	for _, match := range mentionPattern.FindAllStringSubmatch(prompt, -1) {
+		path, ok := mentionPath(dir, match[1])
+		if !ok || seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		att, err := agent.LoadFileMention(path, mentionName(dir, path))
+		if err != nil {
+			warnings = append(warnings, util.ReportWarn("Skipped mention: "+err.Error()))
+			continue
+		}
+		files = append(files, att)
+	}
+	return files, warnings
+}
+
+// mentionPath resolves a mention to an existing file. Trailing punctuation is
+// dropped when the mention ends a sentence.
+func mentionPath(dir, mention string) (string, bool) {
+	for _, candidate := range []string{mention, strings.TrimRight(mention, ".,;:!?)\"'")} {
+		path := cleanPath(candidate)
+		if path == "" {
+			continue
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// mentionName labels a file for the model: relative to dir when inside it.
+func mentionName(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
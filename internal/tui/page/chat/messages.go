@@ -2,6 +2,7 @@ package chat
 
 import (
 	"fmt"
+	"math"
 	"strings"
 
 	"charm.land/bubbles/v2/viewport"
@@ -9,9 +10,12 @@ import (
 	"charm.land/lipgloss/v2"
 	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/x/ansi"
+	"github.com/rivo/uniseg"
 
 	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/config"
 	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/tools"
 	"github.com/guilhermegouw/cdd/internal/tui/styles"
 )
 
@@ -29,6 +33,26 @@ type MessageList struct { //nolint:govet // fieldalignment: preserving logical f
 	cachedWidth      int               // width used for cached renders (invalidate on resize)
 	lastMessageCount int               // track message count for cache invalidation
 
+	// File diffs reported by editing tools, keyed by tool call ID
+	diffs map[string]fileDiff
+
+	showThinking bool // Expand reasoning blocks instead of collapsing them
+
+	// Tool results opened or closed by the user, keyed by tool call ID, and
+	// where each result's header sits within its message
+	expanded      map[string]bool
+	headers       map[string]int
+	toolsExpanded bool // Show tool output unless collapsed one by one
+	resultLines   int  // Lines of an expanded result shown, 0 for all
+
+	// Message picked for editing or retrying, and where each message starts
+	focused string
+	offsets map[string]int
+	starts  []int // First line of each message, in order
+
+	// First line of output that arrived while scrolled up, or -1
+	unreadFrom int
+
 	// Selection state
 	selectionStartCol  int
 	selectionStartLine int
@@ -44,6 +68,11 @@ func NewMessageList() *MessageList {
 		messages:           []agent.Message{},
 		mdRenderer:         NewMarkdownRenderer(),
 		renderCache:        make(map[string]string),
+		diffs:              make(map[string]fileDiff),
+		expanded:           make(map[string]bool),
+		headers:            make(map[string]int),
+		offsets:            make(map[string]int),
+		unreadFrom:         -1,
 		selectionStartCol:  -1,
 		selectionStartLine: -1,
 		selectionEndCol:    -1,
@@ -51,6 +80,34 @@ func NewMessageList() *MessageList {
 	}
 }
 
+// fileDiff is a diff reported by a tool for a single file.
+type fileDiff struct {
+	path string
+	diff string
+}
+
+// SetDiff records the diff produced by a tool call so its result renders a preview.
+func (m *MessageList) SetDiff(toolCallID, path, diff string) {
+	m.diffs[toolCallID] = fileDiff{path: path, diff: diff}
+	m.invalidate(toolCallID)
+	m.updateContent()
+}
+
+// invalidate drops the cached render of the message with the given ID, or of
+// the message holding the result of the tool call with that ID.
+func (m *MessageList) invalidate(id string) {
+	for i := range m.messages {
+		if m.messages[i].ID == id {
+			delete(m.renderCache, id)
+		}
+		for _, tr := range m.messages[i].ToolResults {
+			if tr.ToolCallID == id {
+				delete(m.renderCache, m.messages[i].ID)
+			}
+		}
+	}
+}
+
 // SetMessages sets the messages to display.
 func (m *MessageList) SetMessages(messages []agent.Message) {
 	m.messages = messages
@@ -72,6 +129,202 @@ func (m *MessageList) UpdateLast(content string) {
 	m.updateContent()
 }
 
+// AppendReasoning adds streamed reasoning to the last message.
+func (m *MessageList) AppendReasoning(text string) {
+	if len(m.messages) == 0 {
+		return
+	}
+	m.messages[len(m.messages)-1].Reasoning += text
+	m.updateContent()
+}
+
+// SetShowThinking expands or collapses the model's reasoning.
+func (m *MessageList) SetShowThinking(show bool) {
+	if m.showThinking == show {
+		return
+	}
+	m.showThinking = show
+	m.renderCache = make(map[string]string)
+	m.updateContent()
+}
+
+// SetRenderOptions sets how replies are rendered: the markdown style, the
+// column they wrap at and the theme of their code blocks.
+func (m *MessageList) SetRenderOptions(opts config.MarkdownOptions) {
+	if !m.mdRenderer.SetOptions(opts) {
+		return
+	}
+	m.renderCache = make(map[string]string)
+	m.updateContent()
+}
+
+// RenderOptions returns how replies are rendered.
+func (m *MessageList) RenderOptions() config.MarkdownOptions {
+	return m.mdRenderer.Options()
+}
+
+// SetResultLines sets how many lines of an expanded tool result are shown;
+// longer results keep their first and last lines. 0 shows every line.
+func (m *MessageList) SetResultLines(n int) {
+	if m.resultLines == n {
+		return
+	}
+	m.resultLines = n
+	m.renderCache = make(map[string]string)
+	m.updateContent()
+}
+
+// ToolResult returns the result of the tool call with the given ID.
+func (m *MessageList) ToolResult(toolCallID string) (agent.ToolResult, bool) {
+	for i := range m.messages {
+		for _, tr := range m.messages[i].ToolResults {
+			if tr.ToolCallID == toolCallID {
+				return tr, true
+			}
+		}
+	}
+	return agent.ToolResult{}, false
+}
+
+// ShowThinking reports whether reasoning is expanded.
+func (m *MessageList) ShowThinking() bool {
+	return m.showThinking
+}
+
+// UserMessages returns the saved user prompts, oldest first. Prompts still
+// being sent have no ID yet and are skipped.
+func (m *MessageList) UserMessages() []agent.Message {
+	var prompts []agent.Message
+	for i := range m.messages {
+		if msg := m.messages[i]; msg.Role == agent.RoleUser && !msg.IsSummary && msg.ID != "" {
+			prompts = append(prompts, msg)
+		}
+	}
+	return prompts
+}
+
+// LastReply returns the ID of the last saved assistant message, if no prompt
+// follows it.
+func (m *MessageList) LastReply() (string, bool) {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		msg := m.messages[i]
+		switch {
+		case msg.Role == agent.RoleAssistant && !msg.IsSummary && msg.ID != "":
+			return msg.ID, true
+		case msg.Role == agent.RoleUser:
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// Pickable returns the IDs of the entries that can be focused, in order:
+// saved user prompts, tool results, which are identified by their tool call
+// ID, and the last reply.
+func (m *MessageList) Pickable() []string {
+	var ids []string
+	for i := range m.messages {
+		msg := m.messages[i]
+		switch {
+		case msg.Role == agent.RoleUser && !msg.IsSummary && msg.ID != "":
+			ids = append(ids, msg.ID)
+		case msg.Role == agent.RoleTool:
+			for _, tr := range msg.ToolResults {
+				if showsResult(tr) {
+					ids = append(ids, tr.ToolCallID)
+				}
+			}
+		}
+	}
+	if id, ok := m.LastReply(); ok {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// ToggleExpanded shows or hides the output of a tool call.
+func (m *MessageList) ToggleExpanded(toolCallID string) {
+	for i := range m.messages {
+		for _, tr := range m.messages[i].ToolResults {
+			if tr.ToolCallID == toolCallID {
+				m.expanded[toolCallID] = !m.isExpanded(tr)
+			}
+		}
+	}
+	m.invalidate(toolCallID)
+	m.updateContent()
+}
+
+// ToggleAllTools expands every tool result, or collapses them all when they
+// are already expanded.
+func (m *MessageList) ToggleAllTools() {
+	m.toolsExpanded = !m.toolsExpanded
+	m.expanded = make(map[string]bool)
+	m.renderCache = make(map[string]string)
+	m.updateContent()
+}
+
+// ToolsExpanded reports whether tool results are expanded by default.
+func (m *MessageList) ToolsExpanded() bool {
+	return m.toolsExpanded
+}
+
+// ToolResultAt returns the tool call ID of the result whose header is on row y
+// of the view, or "" if there is none.
+func (m *MessageList) ToolResultAt(y int) string {
+	line := y + m.viewport.YOffset()
+	for i := range m.messages {
+		for _, tr := range m.messages[i].ToolResults {
+			if offset, ok := m.offsets[tr.ToolCallID]; ok && showsResult(tr) && offset == line {
+				return tr.ToolCallID
+			}
+		}
+	}
+	return ""
+}
+
+// Focus highlights the message or tool result with the given ID and scrolls
+// to it. An empty ID clears the highlight.
+func (m *MessageList) Focus(id string) {
+	m.invalidate(m.focused)
+	m.invalidate(id)
+	m.focused = id
+	m.updateContent()
+
+	if offset, ok := m.offsets[id]; ok && id != "" {
+		m.viewport.SetYOffset(offset)
+	}
+}
+
+// Focused returns the ID of the highlighted message.
+func (m *MessageList) Focused() string {
+	return m.focused
+}
+
+// CountFrom returns how many messages start at the one with the given ID.
+func (m *MessageList) CountFrom(id string) int {
+	for i := range m.messages {
+		if m.messages[i].ID == id {
+			return len(m.messages) - i
+		}
+	}
+	return 0
+}
+
+// TruncateAt removes the message with the given ID and everything after it.
+func (m *MessageList) TruncateAt(id string) {
+	for i := range m.messages {
+		if m.messages[i].ID == id {
+			m.messages = m.messages[:i]
+			break
+		}
+	}
+	if m.focused == id {
+		m.focused = ""
+	}
+	m.updateContent()
+}
+
 // SetSize sets the component size.
 func (m *MessageList) SetSize(width, height int) {
 	// Skip if size hasn't changed
@@ -94,9 +347,21 @@ func (m *MessageList) SetSize(width, height int) {
 		m.updateContent()
 	} else {
 		debug.Event("messages", "SetSize", fmt.Sprintf("resizing viewport width=%d height=%d", width, height))
+		reflow := m.viewport.Width() != width
+		scrolled := m.viewport.ScrollPercent()
+		atBottom := m.viewport.AtBottom()
 		m.viewport.SetWidth(width)
 		m.viewport.SetHeight(height)
-		// Don't call updateContent on resize - preserves scroll position
+		if reflow {
+			// Wrap the messages to the new width, keeping the reader's place.
+			m.updateContent()
+			if atBottom {
+				m.viewport.GotoBottom()
+			} else {
+				maxOffset := max(m.viewport.TotalLineCount()-height, 0)
+				m.viewport.SetYOffset(int(math.Round(scrolled * float64(maxOffset))))
+			}
+		}
 	}
 }
 
@@ -203,6 +468,7 @@ func (m *MessageList) updateContent() {
 
 	// Check if we were at the bottom before updating
 	wasAtBottom := m.viewport.AtBottom()
+	previousLines := m.viewport.TotalLineCount()
 
 	// Render messages with caching
 	rendered := make([]string, 0, len(m.messages))
@@ -233,13 +499,27 @@ func (m *MessageList) updateContent() {
 	}
 	m.lastMessageCount = len(m.messages)
 
+	// Record where each message starts, counting the blank line between messages
+	line := 0
+	m.starts = m.starts[:0]
+	for i := range rendered {
+		m.starts = append(m.starts, line)
+		if id := m.messages[i].ID; id != "" {
+			m.offsets[id] = line
+		}
+		for _, tr := range m.messages[i].ToolResults {
+			m.offsets[tr.ToolCallID] = line + m.headers[tr.ToolCallID]
+		}
+		line += strings.Count(rendered[i], "\n") + 2 //nolint:mnd // Last line and the separator
+	}
+
 	// Join with spacing
 	content := strings.Join(rendered, "\n\n")
 
 	// Add padding
 	paddedContent := lipgloss.NewStyle().
 		Width(m.width-2).
-		Padding(0, 1).
+		Padding(0, messagePadding).
 		Render(content)
 
 	// Cache for selection text extraction
@@ -251,7 +531,62 @@ func (m *MessageList) updateContent() {
 	// This preserves scroll position when user is reading earlier content
 	if wasAtBottom {
 		m.viewport.GotoBottom()
+		m.unreadFrom = -1
+	} else if m.unreadFrom < 0 && m.viewport.TotalLineCount() > previousLines {
+		m.unreadFrom = previousLines
+	}
+}
+
+// JumpPrev scrolls to the start of the message above the top of the view.
+// It reports whether the view moved.
+func (m *MessageList) JumpPrev() bool {
+	top := m.viewport.YOffset()
+	for i := len(m.starts) - 1; i >= 0; i-- {
+		if m.starts[i] < top {
+			m.viewport.SetYOffset(m.starts[i])
+			return true
+		}
 	}
+	return false
+}
+
+// JumpNext scrolls to the start of the next message below the top of the
+// view, or to the bottom after the last one. It reports whether the view
+// moved.
+func (m *MessageList) JumpNext() bool {
+	top := m.viewport.YOffset()
+	for _, start := range m.starts {
+		if start > top {
+			m.viewport.SetYOffset(start)
+			return m.viewport.YOffset() != top
+		}
+	}
+	if m.viewport.AtBottom() {
+		return false
+	}
+	m.viewport.GotoBottom()
+	return true
+}
+
+// HasUnread reports whether output arrived while the view was scrolled up
+// and has not been scrolled to since.
+func (m *MessageList) HasUnread() bool {
+	if m.unreadFrom >= 0 && (m.viewport.AtBottom() || m.unreadFrom >= m.viewport.TotalLineCount() ||
+		m.viewport.YOffset()+m.viewport.Height() > m.unreadFrom) {
+		m.unreadFrom = -1
+	}
+	return m.unreadFrom >= 0
+}
+
+// JumpUnread scrolls to the first output that arrived while the view was
+// scrolled up. It reports whether there was any.
+func (m *MessageList) JumpUnread() bool {
+	if !m.HasUnread() {
+		return false
+	}
+	m.viewport.SetYOffset(m.unreadFrom)
+	m.unreadFrom = -1
+	return true
 }
 
 // cleanupCache removes cached renders for messages that no longer exist.
@@ -262,6 +597,9 @@ func (m *MessageList) cleanupCache() {
 		if m.messages[i].ID != "" {
 			currentIDs[m.messages[i].ID] = struct{}{}
 		}
+		for _, tr := range m.messages[i].ToolResults {
+			currentIDs[tr.ToolCallID] = struct{}{}
+		}
 	}
 
 	// Remove cached renders for messages that no longer exist
@@ -270,6 +608,12 @@ func (m *MessageList) cleanupCache() {
 			delete(m.renderCache, id)
 		}
 	}
+	for id := range m.offsets {
+		if _, exists := currentIDs[id]; !exists {
+			delete(m.offsets, id)
+			delete(m.headers, id)
+		}
+	}
 }
 
 func (m *MessageList) renderMessage(msg agent.Message) string {
@@ -278,6 +622,10 @@ func (m *MessageList) renderMessage(msg agent.Message) string {
 		contentWidth = 1
 	}
 
+	if msg.IsSummary {
+		return m.renderSummaryMessage(msg, contentWidth)
+	}
+
 	switch msg.Role {
 	case agent.RoleUser:
 		return m.renderUserMessage(msg, contentWidth)
@@ -286,8 +634,9 @@ func (m *MessageList) renderMessage(msg agent.Message) string {
 	case agent.RoleTool:
 		return m.renderToolMessage(msg, contentWidth)
 	case agent.RoleSystem:
-		// System messages are typically not displayed in chat
-		return ""
+		// System messages are not stored in sessions; the ones shown are
+		// local notices such as the output of /context.
+		return m.renderSystemMessage(msg, contentWidth)
 	}
 	return "" // Unreachable, all cases handled
 }
@@ -296,19 +645,35 @@ func (m *MessageList) renderUserMessage(msg agent.Message, width int) string {
 	t := styles.CurrentTheme()
 
 	header := t.S().Text.Bold(true).Render("You")
+	if msg.ID != "" && msg.ID == m.focused {
+		header = t.S().Primary.Bold(true).Render(styles.Icon("▶ ")+"You") +
+			t.S().Muted.Render("  e edit · r retry · esc cancel")
+	}
 	content := t.S().Text.Width(width).Render(msg.Content)
 
-	return lipgloss.JoinVertical(lipgloss.Left, header, content)
+	lines := []string{header, content}
+	for _, att := range msg.Attachments {
+		lines = append(lines, t.S().Muted.Render("["+attachmentKind(att)+": "+att.Filename+"]"))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
 
 func (m *MessageList) renderAssistantMessage(msg agent.Message, width int) string {
 	t := styles.CurrentTheme()
 
 	header := t.S().Primary.Bold(true).Render("Assistant")
+	if msg.ID != "" && msg.ID == m.focused {
+		header = t.S().Primary.Bold(true).Render(styles.Icon("▶ ")+"Assistant") +
+			t.S().Muted.Render("  r regenerate · esc cancel")
+	}
 
-	parts := make([]string, 0, 2)
+	parts := make([]string, 0, 4)
 	parts = append(parts, header)
 
+	if reasoning := strings.TrimSpace(msg.Reasoning); reasoning != "" {
+		parts = append(parts, m.renderReasoning(reasoning, width))
+	}
+
 	if msg.Content != "" {
 		// Try to render markdown
 		rendered, err := m.mdRenderer.Render(msg.Content, width)
@@ -324,33 +689,194 @@ func (m *MessageList) renderAssistantMessage(msg agent.Message, width int) strin
 	// Show subtle indicator for tool usage (tools are shown in activity panel during streaming)
 	if len(msg.ToolCalls) > 0 {
 		toolCount := len(msg.ToolCalls)
-		indicator := t.S().Muted.Render(fmt.Sprintf("⚡ %d tool%s used", toolCount, pluralize(toolCount)))
+		indicator := t.S().Muted.Render(styles.Icon("⚡ ") + fmt.Sprintf("%d tool%s used", toolCount, pluralize(toolCount)))
 		parts = append(parts, indicator)
 	}
 
+	switch {
+	case msg.Interrupted:
+		parts = append(parts, t.S().Muted.Italic(true).Render(styles.Icon("■ ")+"Interrupted when cdd exited"))
+	case msg.Cancelled:
+		parts = append(parts, t.S().Muted.Italic(true).Render(styles.Icon("■ ")+"Cancelled"))
+	case msg.Timing != nil:
+		parts = append(parts, t.S().Muted.Faint(true).Render(msg.Timing.Summary()))
+	}
+
 	return lipgloss.JoinVertical(lipgloss.Left, parts...)
 }
 
-func (m *MessageList) renderToolMessage(msg agent.Message, width int) string {
-	// Tool results are no longer displayed inline - they're shown in the activity panel
-	// during streaming. For completed messages, we show a summary in the assistant message.
-	// Only show errors if present.
+// renderReasoning renders the model's thinking as a dimmed block, or as a
+// single line when collapsed.
+func (m *MessageList) renderReasoning(reasoning string, width int) string {
+	t := styles.CurrentTheme()
+	if !m.showThinking {
+		lines := strings.Count(reasoning, "\n") + 1
+		return t.S().Muted.Faint(true).Render(
+			fmt.Sprintf("%sThinking (%d line%s, /thinking on to expand)", styles.Icon("▸ "), lines, pluralize(lines)))
+	}
+
+	header := t.S().Muted.Render(styles.Icon("▾ ") + "Thinking")
+	body := t.S().Muted.Faint(true).Italic(true).
+		Width(width).
+		PaddingLeft(2).
+		Render(reasoning)
+	return lipgloss.JoinVertical(lipgloss.Left, header, body)
+}
+
+func (m *MessageList) renderSummaryMessage(msg agent.Message, width int) string {
+	t := styles.CurrentTheme()
+
+	header := t.S().Muted.Bold(true).Render("Conversation summary")
+	content := t.S().Muted.Width(width).Render(msg.Content)
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, content)
+}
+
+func (m *MessageList) renderSystemMessage(msg agent.Message, width int) string {
+	if msg.Content == "" {
+		return ""
+	}
 	t := styles.CurrentTheme()
+	return t.S().Muted.Width(width).Render(msg.Content)
+}
 
-	var errorParts []string
+func (m *MessageList) renderToolMessage(msg agent.Message, width int) string {
+	// Tool activity is shown in the activity panel while streaming. Once done,
+	// each result is a block that expands from a one-line header, except that
+	// editing tools show the diff of their change instead.
+	var parts []string
+	line := 0
 	for _, tr := range msg.ToolResults {
-		if tr.IsError {
-			header := t.S().Error.Bold(true).Render(fmt.Sprintf("⚠ %s error:", tr.Name))
-			content := t.S().Error.Width(width - 4).Render(truncateToolResult(tr.Content))
-			errorParts = append(errorParts, header, content)
+		var part string
+		switch d, ok := m.diffs[tr.ToolCallID]; {
+		case ok && d.diff != "" && !tr.IsError:
+			part = renderDiff(diffTitle(d.path), d.diff, width)
+		case showsResult(tr):
+			m.headers[tr.ToolCallID] = line
+			part = m.renderToolResult(tr, width)
+		default:
+			continue
 		}
+		parts = append(parts, part)
+		line += strings.Count(part, "\n") + 1
 	}
 
-	if len(errorParts) == 0 {
+	if len(parts) == 0 {
 		return ""
 	}
 
-	return lipgloss.JoinVertical(lipgloss.Left, errorParts...)
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
+
+// renderToolResult renders a tool call and its result as a block: a header
+// naming the call, followed, when expanded, by the output with code
+// highlighted.
+func (m *MessageList) renderToolResult(tr agent.ToolResult, width int) string {
+	t := styles.CurrentTheme()
+	call := m.toolCall(tr.ToolCallID)
+
+	var lines []string
+	if tr.IsError && !hasANSI(tr.Content) {
+		for _, line := range strings.Split(strings.TrimRight(tr.Content, "\n"), "\n") {
+			lines = append(lines, t.S().Error.Render(line))
+		}
+	} else {
+		lines = toolResultLines(call, tr.Content)
+	}
+
+	expanded := m.isExpanded(tr)
+	marker := "▶"
+	if expanded {
+		marker = "▼"
+	}
+	title := styles.Icon(marker+" ") + "Tool: " + tr.Name
+	if summary := toolSummary(tr.Name, call.Input); summary != "" {
+		title += " " + summary
+	}
+	title = ansi.Truncate(title, max(width-20, 10), "…") + //nolint:mnd // Room for the line count
+		fmt.Sprintf(" — %d line%s", len(lines), pluralize(len(lines)))
+	if tr.IsError {
+		title += " (error)"
+	}
+
+	var header string
+	switch {
+	case tr.ToolCallID == m.focused:
+		header = t.S().Primary.Bold(true).Render(title) + t.S().Muted.Render("  enter expand/collapse · v view all · esc cancel")
+	case tr.IsError:
+		header = t.S().Error.Bold(true).Render(title)
+	default:
+		header = t.S().Muted.Render(title)
+	}
+	if !expanded {
+		return header
+	}
+
+	shown, hidden := middleLines(lines, m.resultLines)
+	body := make([]string, 0, len(shown)+2) //nolint:mnd // Header and the hidden lines note
+	body = append(body, header)
+	for i, line := range shown {
+		if hidden > 0 && i == len(shown)-m.resultLines/2 {
+			body = append(body, t.S().Muted.Render(fmt.Sprintf("  … %d line%s hidden; pick the result and press v to view all …", hidden, pluralize(hidden))))
+		}
+		body = append(body, "  "+ansi.Truncate(expandTabs(line), max(width-2, 1), "…"))
+	}
+	if tr.FullOutput != "" {
+		body = append(body, t.S().Muted.Render("  The model was sent part of this output; v views all of it"))
+	}
+	return strings.Join(body, "\n")
+}
+
+// middleLines keeps the first and last of lines, limit in all, and returns
+// them with how many were left out between. A limit of 0 keeps every line.
+func middleLines(lines []string, limit int) (kept []string, hidden int) {
+	if limit <= 0 || len(lines) <= limit {
+		return lines, 0
+	}
+	tail := limit / 2 //nolint:mnd // Half from each end
+	kept = append(kept, lines[:limit-tail]...)
+	kept = append(kept, lines[len(lines)-tail:]...)
+	return kept, len(lines) - limit
+}
+
+// isExpanded reports whether a tool result shows its output. Results start
+// collapsed, errors excepted, unless all were expanded with ToggleAllTools
+// or the chat reads as a plain transcript in accessible mode.
+func (m *MessageList) isExpanded(tr agent.ToolResult) bool {
+	if open, ok := m.expanded[tr.ToolCallID]; ok {
+		return open
+	}
+	return m.toolsExpanded || tr.IsError || styles.Accessible()
+}
+
+// showsResult reports whether the text of a tool result is worth showing.
+// Editing tools only confirm the change, which their diff already shows.
+func showsResult(tr agent.ToolResult) bool {
+	if strings.TrimSpace(tr.Content) == "" {
+		return false
+	}
+	switch tr.Name {
+	case tools.EditToolName, tools.EditFileToolName, tools.WriteToolName:
+		return tr.IsError
+	}
+	return true
+}
+
+// toolCall returns the call that produced the result with the given ID.
+func (m *MessageList) toolCall(id string) agent.ToolCall {
+	for i := range m.messages {
+		for _, tc := range m.messages[i].ToolCalls {
+			if tc.ID == id {
+				return tc
+			}
+		}
+	}
+	return agent.ToolCall{ID: id}
+}
+
+// expandTabs replaces tabs with spaces so truncation measures lines correctly.
+func expandTabs(line string) string {
+	return strings.ReplaceAll(line, "\t", "    ")
 }
 
 // pluralize returns "s" if count != 1, empty string otherwise.
@@ -361,15 +887,6 @@ func pluralize(count int) string {
 	return "s"
 }
 
-// truncateToolResult truncates a tool result for display.
-func truncateToolResult(content string) string {
-	const maxLen = 200
-	if len(content) <= maxLen {
-		return content
-	}
-	return content[:maxLen] + "..."
-}
-
 // Selection methods
 
 // StartSelection begins a text selection at the given coordinates.
@@ -456,69 +973,21 @@ type SelectionCopiedMsg struct {
 	Text string
 }
 
-// getSelectedText extracts the text within the current selection.
-//
-//nolint:gocyclo // Complex due to multi-line selection handling
+// getSelectedText extracts the text within the current selection, as it is
+// highlighted. Padding after the end of each line is left out.
 func (m *MessageList) getSelectedText() string {
 	if !m.HasSelection() || m.renderedContent == "" {
 		return ""
 	}
 
-	// Normalize selection coordinates (start should be before end)
-	startLine, endLine := m.selectionStartLine, m.selectionEndLine
-	startCol, endCol := m.selectionStartCol, m.selectionEndCol
-
-	if startLine > endLine || (startLine == endLine && startCol > endCol) {
-		startLine, endLine = endLine, startLine
-		startCol, endCol = endCol, startCol
-	}
-
+	sel := m.selection()
 	lines := strings.Split(m.renderedContent, "\n")
 	var result strings.Builder
-
-	for lineIdx := startLine; lineIdx <= endLine && lineIdx < len(lines); lineIdx++ {
-		if lineIdx < 0 {
-			continue
-		}
-
-		line := ansi.Strip(lines[lineIdx])
-
-		var lineStart, lineEnd int
-		switch {
-		case startLine == endLine:
-			// Single line selection
-			lineStart = startCol
-			lineEnd = endCol
-		case lineIdx == startLine:
-			// First line of multi-line selection
-			lineStart = startCol
-			lineEnd = len(line)
-		case lineIdx == endLine:
-			// Last line of multi-line selection
-			lineStart = 0
-			lineEnd = endCol
-		default:
-			// Middle lines
-			lineStart = 0
-			lineEnd = len(line)
-		}
-
-		// Clamp to line bounds
-		if lineStart < 0 {
-			lineStart = 0
-		}
-		if lineEnd > len(line) {
-			lineEnd = len(line)
-		}
-		if lineStart > lineEnd {
-			lineStart = lineEnd
-		}
-
-		if lineStart < len(line) {
-			result.WriteString(line[lineStart:lineEnd])
-		}
-
-		if lineIdx < endLine {
+	for lineIdx := max(sel.startLine, 0); lineIdx <= sel.endLine && lineIdx < len(lines); lineIdx++ {
+		plain := strings.TrimRight(ansi.Strip(lines[lineIdx]), " ")
+		from, to := sel.cells(lineIdx, plain)
+		result.WriteString(strings.TrimRight(ansi.Cut(plain, from, to), " "))
+		if lineIdx < sel.endLine {
 			result.WriteByte('\n')
 		}
 	}
@@ -526,9 +995,8 @@ func (m *MessageList) getSelectedText() string {
 	return strings.TrimSpace(result.String())
 }
 
-// applySelectionHighlight renders the view with selection highlighting.
-//
-//nolint:gocyclo // Complex due to multi-line selection highlighting
+// applySelectionHighlight renders the view with selection highlighting,
+// keeping the styling of the text around it.
 func (m *MessageList) applySelectionHighlight(content string) string {
 	if !m.HasSelection() {
 		return content
@@ -537,81 +1005,79 @@ func (m *MessageList) applySelectionHighlight(content string) string {
 	t := styles.CurrentTheme()
 	selStyle := t.S().TextSelection
 
-	// Normalize selection coordinates
-	startLine, endLine := m.selectionStartLine, m.selectionEndLine
-	startCol, endCol := m.selectionStartCol, m.selectionEndCol
+	// Selection lines are absolute; the view starts at the viewport offset.
+	sel := m.selection()
+	offset := m.viewport.YOffset()
 
-	if startLine > endLine || (startLine == endLine && startCol > endCol) {
-		startLine, endLine = endLine, startLine
-		startCol, endCol = endCol, startCol
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lineIdx := i + offset
+		if lineIdx < sel.startLine || lineIdx > sel.endLine {
+			continue
+		}
+		from, to := sel.cells(lineIdx, strings.TrimRight(ansi.Strip(line), " "))
+		if from >= to {
+			continue
+		}
+		lines[i] = ansi.Cut(line, 0, from) + ansi.ResetStyle +
+			selStyle.Render(ansi.Strip(ansi.Cut(line, from, to))) +
+			ansi.Cut(line, to, ansi.StringWidth(line))
 	}
+	return strings.Join(lines, "\n")
+}
 
-	// Adjust for viewport offset (convert absolute line to viewport-relative)
-	viewportOffset := m.viewport.YOffset()
-	startLine -= viewportOffset
-	endLine -= viewportOffset
+// messagePadding is the blank columns on either side of the messages, which
+// selections skip.
+const messagePadding = 1
 
-	lines := strings.Split(content, "\n")
-	var result strings.Builder
+// selectionSpan is a selection in content lines and terminal cell columns,
+// with the start before the end.
+type selectionSpan struct {
+	startLine, startCol int
+	endLine, endCol     int
+}
 
-	for lineIdx, line := range lines {
-		if lineIdx >= startLine && lineIdx <= endLine {
-			// This line has selection
-			plainLine := ansi.Strip(line)
-
-			var lineStart, lineEnd int
-			switch {
-			case startLine == endLine:
-				lineStart = startCol
-				lineEnd = endCol
-			case lineIdx == startLine:
-				lineStart = startCol
-				lineEnd = len(plainLine)
-			case lineIdx == endLine:
-				lineStart = 0
-				lineEnd = endCol
-			default:
-				lineStart = 0
-				lineEnd = len(plainLine)
-			}
+// selection returns the current selection with its ends in order.
+func (m *MessageList) selection() selectionSpan {
+	sel := selectionSpan{
+		startLine: m.selectionStartLine, startCol: m.selectionStartCol,
+		endLine: m.selectionEndLine, endCol: m.selectionEndCol,
+	}
+	if sel.startLine > sel.endLine || (sel.startLine == sel.endLine && sel.startCol > sel.endCol) {
+		sel.startLine, sel.endLine = sel.endLine, sel.startLine
+		sel.startCol, sel.endCol = sel.endCol, sel.startCol
+	}
+	return sel
+}
 
-			// Clamp to line bounds
-			if lineStart < 0 {
-				lineStart = 0
-			}
-			if lineEnd > len(plainLine) {
-				lineEnd = len(plainLine)
-			}
-			if lineStart > len(plainLine) {
-				lineStart = len(plainLine)
-			}
+// cells returns the cell range of the line at lineIdx, whose visible text is
+// plain, that the selection covers. The range is widened to whole grapheme
+// clusters, so a wide character or emoji is either fully selected or not.
+func (s selectionSpan) cells(lineIdx int, plain string) (from, to int) {
+	width := ansi.StringWidth(plain)
+	from, to = 0, width
+	if lineIdx == s.startLine {
+		from = s.startCol
+	}
+	if lineIdx == s.endLine {
+		to = s.endCol
+	}
+	from, to = max(from, messagePadding), min(to, width)
+	if from >= to {
+		return 0, 0
+	}
 
-			// Build the highlighted line
-			if lineStart >= lineEnd {
-				result.WriteString(line)
-			} else {
-				// Before selection
-				if lineStart > 0 && lineStart <= len(plainLine) {
-					result.WriteString(plainLine[:lineStart])
-				}
-				// Selected text
-				if lineEnd > lineStart {
-					selectedPart := plainLine[lineStart:lineEnd]
-					result.WriteString(selStyle.Render(selectedPart))
-				}
-				// After selection
-				if lineEnd < len(plainLine) {
-					result.WriteString(plainLine[lineEnd:])
-				}
-			}
-		} else {
-			result.WriteString(line)
+	col := 0
+	gr := uniseg.NewGraphemes(plain)
+	for gr.Next() {
+		w := ansi.StringWidth(gr.Str())
+		if col < from && col+w > from {
+			from = col
 		}
-
-		if lineIdx < len(lines)-1 {
-			result.WriteByte('\n')
+		if col < to && col+w > to {
+			to = col + w
 		}
+		col += w
 	}
-
-	return result.String()
+	return from, to
 }
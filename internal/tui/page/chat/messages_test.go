@@ -0,0 +1,282 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/tui/styles"
+)
+
+func toolBlockMessages() []agent.Message {
+	var output strings.Builder
+	for i := range 20 {
+		fmt.Fprintf(&output, "line %d\n", i)
+	}
+	return []agent.Message{
+		{ID: "u1", Role: agent.RoleUser, Content: "run it"},
+		{ID: "a1", Role: agent.RoleAssistant, ToolCalls: []agent.ToolCall{
+			{ID: "call1", Name: "bash", Input: `{"command": "seq"}`},
+			{ID: "call2", Name: "bash", Input: `{"command": "false"}`},
+		}},
+		{ID: "t1", Role: agent.RoleTool, ToolResults: []agent.ToolResult{
+			{ToolCallID: "call1", Name: "bash", Content: output.String()},
+			{ToolCallID: "call2", Name: "bash", Content: "exit status 1", IsError: true},
+		}},
+	}
+}
+
+func TestMessageList_ToolBlocks(t *testing.T) {
+	m := NewMessageList()
+	m.SetSize(80, 60)
+	m.SetMessages(toolBlockMessages())
+
+	content := ansi.Strip(m.renderedContent)
+	if !strings.Contains(content, "▶ Tool: bash seq — 20 lines") || strings.Contains(content, "line 0") {
+		t.Fatalf("results should start collapsed to a header:\n%s", content)
+	}
+	if !strings.Contains(content, "▼ Tool: bash false — 1 line (error)") || !strings.Contains(content, "exit status 1") {
+		t.Fatalf("errors should start expanded:\n%s", content)
+	}
+
+	m.ToggleAllTools()
+	content = ansi.Strip(m.renderedContent)
+	if !strings.Contains(content, "line 19") {
+		t.Errorf("ToggleAllTools() should expand every result:\n%s", content)
+	}
+	m.ToggleAllTools()
+	if strings.Contains(ansi.Strip(m.renderedContent), "line 0") {
+		t.Error("ToggleAllTools() again should collapse them")
+	}
+}
+
+func TestMessageList_Accessible(t *testing.T) {
+	styles.SetAccessible(true)
+	t.Cleanup(func() { styles.SetAccessible(false) })
+
+	m := NewMessageList()
+	m.SetSize(80, 60)
+	messages := toolBlockMessages()
+	messages[1].Cancelled = true
+	m.SetMessages(messages)
+
+	content := ansi.Strip(m.renderedContent)
+	for _, want := range []string{"2 tools used", "Cancelled", "Tool: bash seq — 20 lines", "line 19"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("the transcript should contain %q:\n%s", want, content)
+		}
+	}
+	if strings.ContainsAny(content, "▶▼⚡■") {
+		t.Errorf("the transcript should have no symbols:\n%s", content)
+	}
+}
+
+func TestMessageList_ToolResultAt(t *testing.T) {
+	m := NewMessageList()
+	m.SetSize(80, 60)
+	m.SetMessages(toolBlockMessages())
+
+	lines := strings.Split(ansi.Strip(m.renderedContent), "\n")
+	for y, line := range lines {
+		want := ""
+		switch {
+		case strings.Contains(line, "Tool: bash seq"):
+			want = "call1"
+		case strings.Contains(line, "Tool: bash false"):
+			want = "call2"
+		}
+		if got := m.ToolResultAt(y); got != want {
+			t.Errorf("ToolResultAt(%d) = %q, want %q (line %q)", y, got, want, line)
+		}
+	}
+
+	m.ToggleExpanded("call1")
+	if !strings.Contains(ansi.Strip(m.renderedContent), "line 19") {
+		t.Error("ToggleExpanded() should open the result")
+	}
+}
+
+func TestMessageList_ResultLines(t *testing.T) {
+	m := NewMessageList()
+	m.SetSize(80, 60)
+	m.SetMessages(toolBlockMessages())
+	m.SetResultLines(6)
+	m.ToggleExpanded("call1")
+
+	content := ansi.Strip(m.renderedContent)
+	for _, want := range []string{"line 0", "line 2", "… 14 lines hidden", "line 17", "line 19"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expanded result should show %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "line 3\n") || strings.Contains(content, "line 16") {
+		t.Errorf("the middle lines should be hidden:\n%s", content)
+	}
+
+	m.SetResultLines(0)
+	if !strings.Contains(ansi.Strip(m.renderedContent), "line 10") {
+		t.Error("SetResultLines(0) should show every line")
+	}
+}
+
+func TestChat_PickToolResult(t *testing.T) {
+	m := New(nil)
+	m.SetSize(80, 40)
+	m.messages.SetSize(80, 30)
+	m.messages.SetMessages(toolBlockMessages())
+
+	if got := m.messages.Pickable(); len(got) != 4 || got[1] != "call1" || got[3] != "a1" {
+		t.Fatalf("Pickable() = %v, want the prompt, both results and the last reply", got)
+	}
+
+	m.Update(tea.KeyPressMsg{Code: tea.KeyUp, Mod: tea.ModCtrl})
+	m.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	if m.messages.Focused() != "call1" {
+		t.Fatalf("down should move to the tool result, focused %q", m.messages.Focused())
+	}
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if !m.picking || m.editing != nil {
+		t.Fatal("enter on a tool result should expand it, not edit")
+	}
+	if content := ansi.Strip(m.messages.renderedContent); !strings.Contains(content, "line 19") {
+		t.Errorf("expanded result is missing its last line:\n%s", content)
+	}
+}
+
+func TestMessageList_SelectionCells(t *testing.T) {
+	lines := []string{
+		" \x1b[1mab日本c\x1b[m    ",
+		" 👍🏽 wrapped text  ",
+		" end",
+	}
+	tests := []struct {
+		name                string
+		startCol, startLine int
+		endCol, endLine     int
+		want                string
+	}{
+		{"half of a wide rune selects all of it", 4, 0, 6, 0, "日本"},
+		{"backwards selection", 2, 0, 1, 0, "a"},
+		{"across lines with emoji", 7, 0, 3, 1, "c\n👍🏽"},
+		{"padding is not copied", 0, 0, 2, 2, "ab日本c\n👍🏽 wrapped text\ne"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMessageList()
+			m.renderedContent = strings.Join(lines, "\n")
+			m.selectionStartCol, m.selectionStartLine = tt.startCol, tt.startLine
+			m.selectionEndCol, m.selectionEndLine = tt.endCol, tt.endLine
+
+			if got := m.getSelectedText(); got != tt.want {
+				t.Errorf("getSelectedText() = %q, want %q", got, tt.want)
+			}
+
+			highlighted := strings.Split(m.applySelectionHighlight(m.renderedContent), "\n")
+			for i := range lines {
+				if ansi.Strip(highlighted[i]) != ansi.Strip(lines[i]) {
+					t.Errorf("highlighting changed line %d text: %q", i, ansi.Strip(highlighted[i]))
+				}
+			}
+			if !strings.Contains(highlighted[0], "\x1b[1m") {
+				t.Errorf("styling outside the selection should be kept: %q", highlighted[0])
+			}
+		})
+	}
+}
+
+func TestMessageList_ReflowOnResize(t *testing.T) {
+	m := NewMessageList()
+	m.SetSize(80, 10)
+	m.SetMessages([]agent.Message{
+		{ID: "a1", Role: agent.RoleAssistant, Content: strings.Repeat("word ", 60)},
+	})
+	wide := strings.Count(m.renderedContent, "\n")
+
+	m.SetSize(40, 10)
+	if narrow := strings.Count(m.renderedContent, "\n"); narrow <= wide {
+		t.Errorf("narrowing should re-wrap the messages onto more lines, got %d then %d", wide, narrow)
+	}
+	for line := range strings.SplitSeq(m.renderedContent, "\n") {
+		if w := ansi.StringWidth(line); w > 40 {
+			t.Fatalf("line is %d cells wide after resizing to 40: %q", w, ansi.Strip(line))
+		}
+	}
+	if !m.viewport.AtBottom() {
+		t.Error("a list scrolled to the bottom should stay there after resizing")
+	}
+}
+
+func TestMessageList_Cancelled(t *testing.T) {
+	m := NewMessageList()
+	m.SetSize(80, 20)
+	m.SetMessages([]agent.Message{
+		{ID: "u1", Role: agent.RoleUser, Content: "explain"},
+		{ID: "a1", Role: agent.RoleAssistant, Content: "It starts by", Cancelled: true},
+	})
+
+	content := ansi.Strip(m.renderedContent)
+	if !strings.Contains(content, "It starts by") || !strings.Contains(content, "■ Cancelled") {
+		t.Errorf("a cancelled reply should show its partial text and a marker:\n%s", content)
+	}
+}
+
+func TestMessageList_Timing(t *testing.T) {
+	m := NewMessageList()
+	m.SetSize(80, 20)
+	m.SetMessages([]agent.Message{
+		{ID: "u1", Role: agent.RoleUser, Content: "explain"},
+		{ID: "a1", Role: agent.RoleAssistant, Content: "It starts by reading the config.",
+			Timing: &agent.Timing{FirstToken: 250 * time.Millisecond, OutputTokens: 80, Generation: 2 * time.Second}},
+	})
+
+	if content := ansi.Strip(m.renderedContent); !strings.Contains(content, "0.25s to first token · 40.0 tokens/s") {
+		t.Errorf("a timed reply should end with its speed:\n%s", content)
+	}
+}
+
+func TestMessageList_Jump(t *testing.T) {
+	long := strings.Repeat("line\n", 12)
+	m := NewMessageList()
+	m.SetSize(60, 5)
+	m.SetMessages([]agent.Message{
+		{ID: "u1", Role: agent.RoleUser, Content: "first"},
+		{ID: "a1", Role: agent.RoleAssistant, Content: long},
+		{ID: "u2", Role: agent.RoleUser, Content: "second"},
+		{ID: "a2", Role: agent.RoleAssistant, Content: long},
+	})
+
+	// From the bottom, each jump back lands on an earlier message start.
+	var visited []int
+	for m.JumpPrev() {
+		visited = append(visited, m.viewport.YOffset())
+	}
+	if len(visited) == 0 || visited[len(visited)-1] != 0 || m.viewport.YOffset() != m.offsets["u1"] {
+		t.Fatalf("JumpPrev stopped at %v, want to end on the first message", visited)
+	}
+	if !m.JumpNext() || m.viewport.YOffset() != m.offsets["a1"] {
+		t.Errorf("JumpNext from the top = %d, want the start of a1 (%d)", m.viewport.YOffset(), m.offsets["a1"])
+	}
+
+	// Output that arrives while scrolled up is remembered until seen.
+	m.viewport.SetYOffset(0)
+	before := m.viewport.TotalLineCount()
+	m.AppendMessage(agent.Message{ID: "u3", Role: agent.RoleUser, Content: "third"})
+	if !m.HasUnread() {
+		t.Fatal("a message added while scrolled up should be unread")
+	}
+	if m.viewport.YOffset() != 0 {
+		t.Error("new output should not move a scrolled up view")
+	}
+	if !m.JumpUnread() || m.viewport.YOffset() > before || m.viewport.YOffset()+m.viewport.Height() <= before {
+		t.Errorf("JumpUnread moved to %d, want the new output at line %d in view", m.viewport.YOffset(), before)
+	}
+	if m.HasUnread() || m.JumpUnread() {
+		t.Error("output should be read once jumped to")
+	}
+}
@@ -0,0 +1,57 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// handleMode runs the /mode command. Without arguments it lists the modes;
+// /mode NAME switches to one.
+func (m *Model) handleMode(args []string) tea.Cmd {
+	if m.agent == nil {
+		return util.ReportWarn("No agent configured")
+	}
+	if len(m.agent.Modes()) == 0 {
+		return util.ReportWarn("No modes configured")
+	}
+	if len(args) == 0 {
+		m.messages.AppendMessage(agent.Message{
+			Role:    agent.RoleSystem,
+			Content: modeReport(m.agent.Modes(), m.agent.Mode()),
+		})
+		return nil
+	}
+	if m.isStreaming {
+		return util.ReportWarn("Wait for the reply to finish before changing the mode")
+	}
+
+	name := strings.ToLower(args[0])
+	if !m.agent.SetMode(name) {
+		return util.ReportWarn(fmt.Sprintf("Unknown mode %q; /mode lists them", name))
+	}
+	m.status.SetAgentMode(name)
+	return util.ReportSuccess("Switched to " + name + " mode")
+}
+
+// modeReport lists the agent's modes for the /mode command, marking the
+// current one.
+func modeReport(modes []agent.Mode, current string) string {
+	var b strings.Builder
+	b.WriteString("Modes (/mode NAME switches):")
+	for _, mode := range modes {
+		marker := "  "
+		if mode.Name == current {
+			marker = "* "
+		}
+		fmt.Fprintf(&b, "\n%s%s", marker, mode.Name)
+		if mode.Description != "" {
+			fmt.Fprintf(&b, " - %s", mode.Description)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,320 @@
+package chat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/tui/components/models"
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
+	"github.com/guilhermegouw/cdd/internal/tui/styles"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// maxModelMatches is how many models the quick switcher shows at once.
+const maxModelMatches = 8
+
+// modelOption is one model of a configured connection.
+type modelOption struct {
+	connectionID string
+	connection   string
+	providerID   string
+	modelID      string
+	modelName    string
+}
+
+// label is the text the switcher shows and matches the query against.
+func (o modelOption) label() string {
+	return o.connection + "/" + o.modelName
+}
+
+// ModelSwitcher is the quick switcher over every connection and model, for
+// changing the large model without opening the models modal.
+type ModelSwitcher struct {
+	options []modelOption
+	matches []modelOption
+	active  modelOption // Zero when no connection is selected
+	query   string
+	cursor  int
+	width   int
+	visible bool
+	action  string // What enter does with the model, e.g. "switch"
+}
+
+// NewModelSwitcher creates a hidden switcher.
+func NewModelSwitcher() *ModelSwitcher {
+	return &ModelSwitcher{}
+}
+
+// modelOptions lists the models of cfg's connections, and the one in use as
+// the large model, zero when no connection is selected.
+func modelOptions(cfg *config.Config) (options []modelOption, active modelOption) {
+	large := cfg.Models[config.SelectedModelTypeLarge]
+	for _, conn := range config.NewConnectionManager(cfg).List() {
+		for _, model := range cfg.ConnectionModels(&conn) {
+			name := model.Name
+			if name == "" {
+				name = model.ID
+			}
+			option := modelOption{
+				connectionID: conn.ID,
+				connection:   conn.Name,
+				providerID:   conn.ProviderID,
+				modelID:      model.ID,
+				modelName:    name,
+			}
+			if conn.ID == large.ConnectionID && model.ID == large.Model {
+				active = option
+			}
+			options = append(options, option)
+		}
+	}
+	return options, active
+}
+
+// Open lists the models of cfg's connections and shows the switcher.
+func (s *ModelSwitcher) Open(cfg *config.Config) {
+	s.OpenFor(cfg, "switch")
+}
+
+// OpenFor shows the switcher to pick a model for action, such as
+// "regenerate", which the prompt line names. Other than for switching, the
+// current model is listed first, so enter keeps it.
+func (s *ModelSwitcher) OpenFor(cfg *config.Config, action string) {
+	s.options, s.active = modelOptions(cfg)
+	if action != "switch" {
+		for i, option := range s.options {
+			if option == s.active {
+				copy(s.options[1:i+1], s.options[:i])
+				s.options[0] = option
+				break
+			}
+		}
+	}
+	s.action = action
+	s.visible = true
+	s.SetQuery("")
+}
+
+// Action returns what the switcher was opened for.
+func (s *ModelSwitcher) Action() string {
+	return s.action
+}
+
+// Close hides the switcher.
+func (s *ModelSwitcher) Close() {
+	s.visible = false
+	s.options = nil
+	s.matches = nil
+	s.query = ""
+	s.cursor = 0
+	s.action = ""
+}
+
+// IsVisible reports whether the switcher is shown.
+func (s *ModelSwitcher) IsVisible() bool {
+	return s.visible
+}
+
+// Query returns the search text.
+func (s *ModelSwitcher) Query() string {
+	return s.query
+}
+
+// SetQuery refilters the models by query, best match first.
+func (s *ModelSwitcher) SetQuery(query string) {
+	type scored struct {
+		option modelOption
+		score  int
+	}
+	var found []scored
+	for _, option := range s.options {
+		if score, ok := fuzzyScore(query, option.label()); ok {
+			found = append(found, scored{option, score})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].score > found[j].score
+	})
+
+	s.query = query
+	s.cursor = 0
+	s.matches = make([]modelOption, 0, min(len(found), maxModelMatches))
+	for i := 0; i < len(found) && i < maxModelMatches; i++ {
+		s.matches = append(s.matches, found[i].option)
+	}
+}
+
+// SetWidth sets the panel width.
+func (s *ModelSwitcher) SetWidth(width int) {
+	s.width = width
+}
+
+// MoveUp selects the previous match, wrapping around.
+func (s *ModelSwitcher) MoveUp() {
+	if len(s.matches) > 0 {
+		s.cursor = (s.cursor - 1 + len(s.matches)) % len(s.matches)
+	}
+}
+
+// MoveDown selects the next match, wrapping around.
+func (s *ModelSwitcher) MoveDown() {
+	if len(s.matches) > 0 {
+		s.cursor = (s.cursor + 1) % len(s.matches)
+	}
+}
+
+// Selected returns the highlighted model, or false when nothing matches.
+func (s *ModelSwitcher) Selected() (modelOption, bool) {
+	if s.cursor < len(s.matches) {
+		return s.matches[s.cursor], true
+	}
+	return modelOption{}, false
+}
+
+// Height returns the rendered height (0 when hidden).
+func (s *ModelSwitcher) Height() int {
+	if !s.visible {
+		return 0
+	}
+	return 1 + max(len(s.matches), 1) // Query line + matches
+}
+
+// View renders the query and the matching models.
+func (s *ModelSwitcher) View() string {
+	if !s.visible {
+		return ""
+	}
+
+	t := styles.CurrentTheme()
+	lines := make([]string, 0, s.Height())
+	title := "Switch model"
+	if s.action != "switch" {
+		title = "Model to " + s.action + " with"
+	}
+	lines = append(lines, t.S().Muted.Bold(true).Render("─ "+title+": ")+
+		t.S().Text.Render(s.query+"▏")+
+		t.S().Muted.Render(" (enter to "+s.action+", esc to cancel)"))
+
+	if len(s.matches) == 0 {
+		if len(s.options) == 0 {
+			lines = append(lines, t.S().Muted.Render("  No connections configured; add one with /models"))
+		} else {
+			lines = append(lines, t.S().Muted.Render("  No matching models"))
+		}
+	}
+	for i, option := range s.matches {
+		label := option.label()
+		if option == s.active {
+			label += " (current)"
+		}
+		label = truncate(label, max(s.width-6, 10)) //nolint:mnd // Marker and padding
+		if i == s.cursor {
+			lines = append(lines, t.S().Primary.Bold(true).Render("> "+label))
+		} else {
+			lines = append(lines, t.S().Text.Render("  "+label))
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Padding(0, 1).
+		Width(s.width).
+		Render(strings.Join(lines, "\n"))
+}
+
+// handleModelSwitcherKey edits the query or switches to the picked model
+// while the quick switcher is open.
+func (m *Model) handleModelSwitcherKey(msg tea.KeyMsg) tea.Cmd {
+	if keymap.Current().Matches(msg, keymap.SwitchModel) {
+		m.modelSwitcher.MoveDown()
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.modelSwitcher.Close()
+	case "enter", "tab":
+		option, ok := m.modelSwitcher.Selected()
+		action := m.modelSwitcher.Action()
+		m.modelSwitcher.Close()
+		switch {
+		case ok && action == "regenerate":
+			return m.regenerate(option)
+		case ok:
+			return m.switchModel(option)
+		}
+	case "up", "ctrl+p":
+		m.modelSwitcher.MoveUp()
+	case "down", "ctrl+n":
+		m.modelSwitcher.MoveDown()
+	case "backspace":
+		if query := []rune(m.modelSwitcher.Query()); len(query) > 0 {
+			m.modelSwitcher.SetQuery(string(query[:len(query)-1]))
+		}
+	default:
+		if key, ok := msg.(tea.KeyPressMsg); ok && key.Text != "" {
+			m.modelSwitcher.SetQuery(m.modelSwitcher.Query() + key.Text)
+		}
+	}
+	return nil
+}
+
+// handleModel runs /model: without arguments it opens the quick switcher,
+// otherwise it switches to the model named, which may be an alias from
+// options.model_aliases.
+func (m *Model) handleModel(args []string) tea.Cmd {
+	if m.isStreaming {
+		return util.ReportWarn("Wait for the reply to finish before switching models")
+	}
+	if m.cfg == nil {
+		return util.ReportWarn("Models not configured. Please set config first.")
+	}
+	spec := strings.Join(args, " ")
+	if spec == "" {
+		m.filePicker.Close()
+		m.modelSwitcher.Open(m.cfg)
+		return nil
+	}
+
+	options, active := modelOptions(m.cfg)
+	target, ok := findModel(options, m.cfg.ResolveModelAlias(spec))
+	if !ok {
+		return util.ReportWarn(fmt.Sprintf("No model matches %q; /models lists them", spec))
+	}
+	if target == active {
+		return util.ReportInfo(target.label() + " is already the model in use")
+	}
+	return m.switchModel(target)
+}
+
+// switchModel saves option as the large model and asks for it to be loaded,
+// as picking it in the models modal does.
+func (m *Model) switchModel(option modelOption) tea.Cmd {
+	connManager := config.NewConnectionManager(m.cfg)
+	if err := connManager.SetActiveModel(config.SelectedModelTypeLarge, option.connectionID, option.modelID); err != nil {
+		return util.ReportError(fmt.Errorf("switching model: %w", err))
+	}
+	return util.CmdHandler(models.ModelSwitchedMsg{
+		Tier:         config.SelectedModelTypeLarge,
+		ConnectionID: option.connectionID,
+		ModelID:      option.modelID,
+		ModelName:    option.modelName,
+	})
+}
+
+// announceModelSwitch notes in the conversation which model answers from
+// here on.
+func (m *Model) announceModelSwitch(msg models.ModelSwitchedMsg) {
+	text := "Switched to " + msg.ModelName
+	if m.cfg != nil {
+		if conn := config.NewConnectionManager(m.cfg).Get(msg.ConnectionID); conn != nil {
+			text += " (" + conn.Name + ")"
+		}
+	}
+	m.messages.AppendMessage(agent.Message{Role: agent.RoleSystem, Content: text})
+}
@@ -0,0 +1,123 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+func switcherConfig() *config.Config {
+	cfg := config.NewConfig()
+	cfg.Providers["anthropic"] = &config.ProviderConfig{
+		ID: "anthropic",
+		Models: []catwalk.Model{
+			{ID: "claude-sonnet", Name: "Claude Sonnet"},
+			{ID: "claude-haiku", Name: "Claude Haiku"},
+		},
+	}
+	cfg.Providers["openai"] = &config.ProviderConfig{
+		ID:     "openai",
+		Models: []catwalk.Model{{ID: "gpt-4o"}},
+	}
+	cfg.Connections = []config.Connection{
+		{ID: "c1", Name: "Work", ProviderID: "anthropic"},
+		{ID: "c2", Name: "Personal", ProviderID: "openai"},
+	}
+	cfg.Models[config.SelectedModelTypeLarge] = config.SelectedModel{ConnectionID: "c1", Model: "claude-haiku"}
+	return cfg
+}
+
+func TestHandleModel(t *testing.T) {
+	cfg := switcherConfig()
+	cfg.Options.ModelAliases = map[string]string{"cheap": "anthropic/claude-haiku"}
+	m := New(agent.New(agent.Config{}))
+	m.Init()
+	m.SetConfig(cfg, nil)
+
+	for args, want := range map[string]string{
+		"zzz":                    "No model matches",
+		"anthropic/claude-haiku": "already the model in use",
+		"cheap":                  "already the model in use",
+	} {
+		msg := m.handleModel([]string{args})()
+		if info, ok := msg.(util.InfoMsg); !ok || !strings.Contains(info.Msg, want) {
+			t.Errorf("/model %s = %#v, want %q", args, msg, want)
+		}
+	}
+
+	if cmd := m.handleModel(nil); cmd != nil || !m.modelSwitcher.IsVisible() {
+		t.Error("/model without arguments should open the switcher")
+	}
+	m.modelSwitcher.Close()
+}
+
+func TestModelSwitcher(t *testing.T) {
+	s := NewModelSwitcher()
+	s.Open(switcherConfig())
+
+	if len(s.matches) != 3 {
+		t.Fatalf("empty query should list every model, got %d", len(s.matches))
+	}
+	if s.active.modelID != "claude-haiku" {
+		t.Errorf("active model = %q, want claude-haiku", s.active.modelID)
+	}
+
+	s.SetQuery("perso")
+	option, ok := s.Selected()
+	if !ok || option.connectionID != "c2" || option.modelName != "gpt-4o" {
+		t.Errorf("Selected() = %+v, want the gpt-4o model of Personal", option)
+	}
+
+	s.SetQuery("work/son")
+	if option, _ := s.Selected(); len(s.matches) != 1 || option.modelID != "claude-sonnet" {
+		t.Errorf("matches = %+v, want only claude-sonnet", s.matches)
+	}
+
+	s.SetQuery("zzz")
+	if _, ok := s.Selected(); ok {
+		t.Error("nothing should be selected without matches")
+	}
+}
+
+func TestChat_ModelSwitcherKeys(t *testing.T) {
+	m := New(nil)
+	m.SetSize(80, 24)
+	m.cfg = switcherConfig()
+
+	m.Update(tea.KeyPressMsg{Code: 'm', Mod: tea.ModCtrl})
+	if !m.modelSwitcher.IsVisible() {
+		t.Fatal("ctrl+m should open the model switcher")
+	}
+	for _, r := range "gpt" {
+		m.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if m.input.Value() != "" || m.modelSwitcher.Query() != "gpt" {
+		t.Errorf("typing should edit the switcher query, got query %q input %q", m.modelSwitcher.Query(), m.input.Value())
+	}
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.modelSwitcher.IsVisible() {
+		t.Error("esc should close the model switcher")
+	}
+}
+
+func TestModelSwitcher_OpenFor(t *testing.T) {
+	s := NewModelSwitcher()
+	s.OpenFor(switcherConfig(), "regenerate")
+
+	if option, _ := s.Selected(); option.modelID != "claude-haiku" {
+		t.Errorf("Selected() = %q, want the current model first", option.modelID)
+	}
+	if s.Action() != "regenerate" {
+		t.Errorf("Action() = %q, want regenerate", s.Action())
+	}
+	s.Close()
+	if s.Action() != "" {
+		t.Error("Close() should forget the action")
+	}
+}
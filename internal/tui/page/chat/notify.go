@@ -0,0 +1,85 @@
+package chat
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/tui/styles"
+)
+
+// notificationTitle heads desktop notifications.
+const notificationTitle = "cdd"
+
+// notifyFinished announces the end of a run with the terminal bell, plus a
+// desktop notification when enabled, if the run took long enough and the
+// terminal is in the background. It returns nil otherwise, and at most once
+// per run.
+func (m *Model) notifyFinished(summary string) tea.Cmd {
+	m.announce(summary)
+	started := m.runStarted
+	m.runStarted = time.Time{}
+
+	cfg := m.cfg
+	if cfg == nil {
+		cfg = config.NewConfig()
+	}
+	after := cfg.NotifyAfter()
+	if m.focused || started.IsZero() || after == 0 || time.Since(started) < after {
+		return nil
+	}
+
+	bell := tea.Raw("\a")
+	if !cfg.DesktopNotifications() {
+		return bell
+	}
+	return tea.Batch(bell, desktopNotification(notificationTitle, summary))
+}
+
+// announce adds a line of plain text to the chat in accessible mode, so a
+// screen reader reads out a change of state that is otherwise only shown by
+// the status bar.
+func (m *Model) announce(text string) {
+	if !styles.Accessible() {
+		return
+	}
+	m.messages.AppendMessage(agent.Message{Role: agent.RoleSystem, Content: text})
+}
+
+// desktopNotification shows a notification through terminal-notifier on macOS
+// when it is installed, or asks the terminal for one with OSC 777, which
+// terminals without support ignore.
+func desktopNotification(title, body string) tea.Cmd {
+	if runtime.GOOS == "darwin" {
+		if path, err := exec.LookPath("terminal-notifier"); err == nil {
+			return func() tea.Msg {
+				//nolint:gosec // G204: Fixed binary, arguments are passed without a shell.
+				if err := exec.Command(path, "-title", title, "-message", body).Run(); err != nil {
+					debug.Log("[NOTIFY] terminal-notifier: %v", err)
+				}
+				return nil
+			}
+		}
+	}
+	return tea.Raw(osc777(title, body))
+}
+
+// osc777 builds the OSC 777 notify sequence, dropping control characters
+// that would end it early.
+func osc777(title, body string) string {
+	clean := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r < ' ' || r == 0x7f {
+				return ' '
+			}
+			return r
+		}, s)
+	}
+	return "\x1b]777;notify;" + clean(strings.ReplaceAll(title, ";", ",")) + ";" + clean(body) + "\x1b\\"
+}
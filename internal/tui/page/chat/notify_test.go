@@ -0,0 +1,68 @@
+package chat
+
+import (
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/tui/styles"
+)
+
+func TestNotifyFinished(t *testing.T) {
+	m := New(nil)
+	m.cfg = config.NewConfig()
+
+	m.runStarted = time.Now().Add(-time.Minute)
+	if m.notifyFinished("done") != nil {
+		t.Error("no notification while the terminal has focus")
+	}
+
+	m.Update(tea.BlurMsg{})
+	m.runStarted = time.Now()
+	if m.notifyFinished("done") != nil {
+		t.Error("no notification for a short run")
+	}
+
+	m.runStarted = time.Now().Add(-time.Minute)
+	cmd := m.notifyFinished("done")
+	if cmd == nil {
+		t.Fatal("expected a notification for a long run in the background")
+	}
+	if msg, ok := cmd().(tea.RawMsg); !ok || msg.Msg != "\a" {
+		t.Errorf("notification = %#v, want the terminal bell", msg)
+	}
+	if m.notifyFinished("done") != nil {
+		t.Error("a run should be announced once")
+	}
+
+	m.cfg.Options.Notifications = &config.NotificationOptions{Disabled: true}
+	m.runStarted = time.Now().Add(-time.Minute)
+	if m.notifyFinished("done") != nil {
+		t.Error("no notification when disabled")
+	}
+}
+
+func TestAnnounce(t *testing.T) {
+	m := New(nil)
+	m.announce("Reply ready")
+	if len(m.messages.messages) != 0 {
+		t.Error("state changes are only written to the chat in accessible mode")
+	}
+
+	styles.SetAccessible(true)
+	t.Cleanup(func() { styles.SetAccessible(false) })
+	m.notifyFinished("Request failed: timeout")
+	if msgs := m.messages.messages; len(msgs) != 1 || msgs[0].Content != "Request failed: timeout" {
+		t.Errorf("messages = %+v, want the end of the run announced", msgs)
+	}
+}
+
+func TestOSC777(t *testing.T) {
+	got := osc777("cd;d", "Request failed:\nbad\x1b]")
+	want := "\x1b]777;notify;cd,d;Request failed: bad ]\x1b\\"
+	if got != want {
+		t.Errorf("osc777() = %q, want %q", got, want)
+	}
+}
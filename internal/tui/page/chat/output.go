@@ -0,0 +1,69 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// viewFullOutput shows a tool result in $PAGER, or less: the whole output
+// saved when it was cut for the model, or else the result as stored.
+func (m *Model) viewFullOutput(toolCallID string) tea.Cmd {
+	tr, ok := m.messages.ToolResult(toolCallID)
+	if !ok {
+		return nil
+	}
+	if _, err := os.Stat(tr.FullOutput); tr.FullOutput != "" && err == nil {
+		return openPager(tr.FullOutput, "")
+	}
+	return openPager("", tr.Content)
+}
+
+// viewTranscript shows the session in $PAGER, or less, as one plain run of
+// text from the first prompt to the last reply, for reading top to bottom
+// with a screen reader or search.
+func (m *Model) viewTranscript() tea.Cmd {
+	if m.agent == nil {
+		return util.ReportWarn("No agent configured")
+	}
+	sess, ok := m.agent.Sessions().Get(m.sessionID)
+	if !ok || len(sess.Messages) == 0 {
+		return util.ReportInfo("Nothing in this session yet")
+	}
+	return openPager("", sessionMarkdown(sess))
+}
+
+// openPager runs $PAGER, or less, on the file at path, or on content when
+// path is empty.
+func openPager(path, content string) tea.Cmd {
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		pager = []string{"less"}
+	}
+
+	args := pager[1:]
+	pagerCmd := exec.Command(pager[0]) //nolint:gosec // G204: The pager is the user's choice.
+	if path != "" {
+		args = append(args, path)
+	} else {
+		pagerCmd.Stdin = strings.NewReader(content)
+	}
+	pagerCmd.Args = append(pagerCmd.Args, args...)
+	if os.Getenv("LESS") == "" {
+		pagerCmd.Env = append(os.Environ(), "LESS=R")
+	}
+
+	return tea.ExecProcess(pagerCmd, func(err error) tea.Msg {
+		var exitErr *exec.ExitError
+		if err == nil || errors.As(err, &exitErr) {
+			return nil
+		}
+		return util.InfoMsg{Type: util.InfoTypeError, Msg: fmt.Sprintf("Showing the output: %v", err)}
+	})
+}
@@ -0,0 +1,97 @@
+package chat
+
+import (
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// approvePrompt is sent to carry out a plan the user approved.
+const approvePrompt = "The plan is approved. Carry it out step by step, " +
+	"marking each todo in progress and then completed as you go."
+
+// handlePlan runs the /plan command: /plan on|off turns planning first on or
+// off for the session, and /plan TEXT plans a single prompt.
+func (m *Model) handlePlan(args []string) tea.Cmd {
+	if m.agent == nil {
+		return util.ReportWarn("No agent configured")
+	}
+	if !m.hasPlanMode() {
+		return util.ReportWarn("No plan mode configured")
+	}
+	if len(args) == 0 {
+		return util.ReportWarn("Usage: /plan on|off, or /plan TEXT to plan one prompt")
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "on":
+		if len(args) == 1 {
+			m.planFirst = true
+			return util.ReportInfo("Prompts are planned first and wait for approval")
+		}
+	case "off":
+		if len(args) == 1 {
+			m.planFirst = false
+			return util.ReportInfo("Prompts run without a planning pass")
+		}
+	}
+	if m.isStreaming {
+		return nil
+	}
+	m.planning = true
+	return m.startStream(strings.Join(args, " "), nil, "")
+}
+
+// hasPlanMode reports whether the agent has the mode planning passes run in.
+func (m *Model) hasPlanMode() bool {
+	for _, mode := range m.agent.Modes() {
+		if mode.Name == config.ModePlan {
+			return true
+		}
+	}
+	return false
+}
+
+// startPrompt sends a prompt the user wrote, first as a planning pass when
+// planning first is on or the user is revising a plan.
+func (m *Model) startPrompt(prompt string, attachments []agent.Attachment) tea.Cmd {
+	m.planning = (m.planFirst || m.revisingPlan) && m.hasPlanMode()
+	m.revisingPlan = false
+	return m.startStream(prompt, attachments, "")
+}
+
+// confirmPlan asks whether to carry out the plan the agent just made.
+func (m *Model) confirmPlan() {
+	m.planning = false
+	m.planPending = true
+	m.status.SetNotice("Carry out this plan? (y)es / (e)dit / (n)o")
+}
+
+// handlePlanKey carries out the plan, lets the user ask for changes to it, or
+// drops it along with its todos.
+func (m *Model) handlePlanKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		m.planPending = false
+		m.status.SetNotice("")
+		return m.startStream(approvePrompt, nil, "")
+	case "e", "E":
+		m.planPending = false
+		m.revisingPlan = true
+		m.status.SetNotice("Describe the changes to the plan; Enter sends them")
+		return m.input.Focus()
+	case "n", "N", "esc":
+		m.planPending = false
+		m.status.SetNotice("")
+		if todos := m.agent.Todos(); todos != nil {
+			todos.Clear(m.sessionID)
+			m.todoPanel.Clear()
+		}
+		return util.ReportInfo("Plan rejected")
+	}
+	return nil
+}
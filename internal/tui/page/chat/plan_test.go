@@ -0,0 +1,75 @@
+package chat
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/tools"
+)
+
+func TestPlanFirst(t *testing.T) {
+	todos := tools.NewTodoStore()
+	ag := agent.New(agent.Config{
+		Modes: []agent.Mode{{Name: config.ModeCode}, {Name: config.ModePlan}},
+		Mode:  config.ModeCode,
+		Todos: todos,
+	})
+	m := New(ag)
+	m.sessionID = ag.Sessions().Create("test").ID
+
+	m.handlePlan([]string{"on"})
+	if !m.planFirst {
+		t.Fatal("/plan on should plan every prompt")
+	}
+
+	m.startPrompt("add a flag", nil)
+	if !m.planning {
+		t.Fatal("a prompt should be planned first")
+	}
+	m.Update(StreamCompleteMsg{})
+	if !m.planPending || m.planning {
+		t.Fatalf("pending = %v, planning = %v, want the plan waiting for approval", m.planPending, m.planning)
+	}
+
+	// Asking for changes plans the next prompt again.
+	m.Update(tea.KeyPressMsg{Code: 'e', Text: "e"})
+	m.planFirst = false
+	m.startPrompt("use a config option instead", nil)
+	if m.planPending || !m.planning {
+		t.Errorf("pending = %v, planning = %v, want the revision planned", m.planPending, m.planning)
+	}
+	m.Update(StreamCompleteMsg{})
+
+	todos.Set(m.sessionID, []tools.TodoItem{{Content: "Add option", Status: tools.TodoStatusPending}})
+	m.Update(tea.KeyPressMsg{Code: 'n', Text: "n"})
+	if m.planPending || todos.HasTodos(m.sessionID) {
+		t.Error("rejecting the plan should drop it and its todos")
+	}
+
+	m.startPrompt("just do it", nil)
+	if m.planning {
+		t.Error("with planning first off, prompts should run directly")
+	}
+	m.isStreaming = false
+
+	m.handlePlan([]string{"add", "a", "flag"})
+	if !m.planning || !m.isStreaming {
+		t.Error("/plan TEXT should send the prompt as a planning pass")
+	}
+	m.Update(StreamCompleteMsg{})
+	m.Update(tea.KeyPressMsg{Code: 'y', Text: "y"})
+	if m.planPending || m.planning || !m.isStreaming {
+		t.Error("approving should carry out the plan outside plan mode")
+	}
+}
+
+func TestHandlePlan_NoPlanMode(t *testing.T) {
+	m := New(agent.New(agent.Config{}))
+	m.handlePlan([]string{"on"})
+	if m.planFirst {
+		t.Error("planning first needs a plan mode")
+	}
+}
@@ -0,0 +1,32 @@
+package chat
+
+import "regexp"
+
+// modelPrefixPattern matches a prompt addressed to another model, such as
+// "@small: summarize this file" or "@ollama/qwen2.5:7b: explain". The model
+// ends at the first colon followed by whitespace, so model IDs may contain
+// colons themselves.
+var modelPrefixPattern = regexp.MustCompile(`(?s)^@(\S+?):\s+(\S.*)$`)
+
+// splitModelPrefix returns the model a prompt is addressed to and the prompt
+// without the prefix. Without a prefix, model is empty and prompt is value.
+func splitModelPrefix(value string) (model, prompt string) {
+	match := modelPrefixPattern.FindStringSubmatch(value)
+	if match == nil {
+		return "", value
+	}
+	return match[1], match[2]
+}
+
+// promptModelPrefix is splitModelPrefix for a prompt typed in the chat. A
+// prefix naming a file in the working directory is a file mention instead.
+func (m *Model) promptModelPrefix(value string) (model, prompt string) {
+	model, prompt = splitModelPrefix(value)
+	if model == "" {
+		return "", value
+	}
+	if _, isFile := mentionPath(m.workingDir(), model); isFile {
+		return "", value
+	}
+	return model, prompt
+}
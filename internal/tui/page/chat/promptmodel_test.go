@@ -0,0 +1,79 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/fantasy"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+)
+
+func TestSplitModelPrefix(t *testing.T) {
+	tests := []struct {
+		value, wantModel, wantPrompt string
+	}{
+		{"@small: summarize this file", "small", "summarize this file"},
+		{"@ollama/qwen2.5:7b: explain\nthis", "ollama/qwen2.5:7b", "explain\nthis"},
+		{"@fast:\n  go", "fast", "go"},
+		{"@main.go: what does it do?", "main.go", "what does it do?"},
+		{"@main.go what does it do?", "", "@main.go what does it do?"},
+		{"@small:", "", "@small:"},
+		{"@small:no space", "", "@small:no space"},
+		{"ask @small: later", "", "ask @small: later"},
+	}
+	for _, tt := range tests {
+		model, prompt := splitModelPrefix(tt.value)
+		if model != tt.wantModel || prompt != tt.wantPrompt {
+			t.Errorf("splitModelPrefix(%q) = %q, %q; want %q, %q", tt.value, model, prompt, tt.wantModel, tt.wantPrompt)
+		}
+	}
+}
+
+func TestPromptModel(t *testing.T) {
+	var specs []string
+	ag := agent.New(agent.Config{
+		ModelResolver: func(_ context.Context, spec string) (fantasy.LanguageModel, error) {
+			specs = append(specs, spec)
+			return nil, errors.New("not configured")
+		},
+	})
+	m := New(ag)
+	m.Init()
+	m.sessionID = ag.Sessions().Create("test").ID
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	if model, _ := m.promptModelPrefix("@main.go: what does it do?"); model != "" {
+		t.Errorf("a mentioned file was taken for the model %q", model)
+	}
+
+	m.input.SetValue("@small: summarize the session")
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if got := m.messages.messages[0].Content; got != "summarize the session" {
+		t.Errorf("prompt shown = %q, want it without the prefix", got)
+	}
+	if m.promptModel != "" {
+		t.Error("the model should apply to one prompt only")
+	}
+
+	m.isStreaming = false
+	m.promptModel = "small"
+	msg := m.sendMessage("hi", nil, "")()
+	if failed, ok := msg.(StreamErrorMsg); !ok || !errors.Is(failed.Error, agent.ErrUnknownModel) {
+		t.Errorf("sendMessage() = %#v, want ErrUnknownModel", msg)
+	}
+	if len(specs) != 1 || specs[0] != "small" {
+		t.Errorf("resolved %v, want [small]", specs)
+	}
+	if m.promptModel != "" {
+		t.Error("sending should use up the prompt's model")
+	}
+}
@@ -0,0 +1,93 @@
+package chat
+
+import (
+	"fmt"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// Prompts sent for a run that was cut short when cdd last exited.
+const (
+	interruptedResumePrompt = "Your last reply was cut short because cdd exited; anything not in " +
+		"this conversation was lost. Check what was already done, then continue the task where you left off."
+	interruptedSummaryPrompt = "Your last reply was cut short because cdd exited. Without changing " +
+		"anything, summarize what was done towards the last request and what is left to do."
+)
+
+// offerRecovery asks what to do about the session's reply that was cut short
+// when cdd last exited: resume the task, summarize how far it got, or leave
+// it.
+func (m *Model) offerRecovery() {
+	runs := m.agent.Runs()
+	if runs == nil || m.isStreaming || m.takeover != nil {
+		return
+	}
+	run, ok := runs.Get(m.sessionID)
+	if !ok {
+		return
+	}
+	m.interrupted = &run
+	how := "when cdd exited"
+	if run.Crashed() {
+		how = "when cdd crashed, and the reply was lost"
+	}
+	m.status.SetNotice(fmt.Sprintf("%q was cut short %s. Resume (r), summarize progress (s) or dismiss (esc)?",
+		truncate(run.Prompt, 40), how))
+}
+
+// withdrawOffers drops the questions asked about the session shown before.
+func (m *Model) withdrawOffers() {
+	if m.interrupted != nil || m.takeover != nil {
+		m.interrupted, m.takeover = nil, nil
+		m.status.SetNotice("")
+	}
+}
+
+// ReportInterrupted mentions the other sessions whose replies were cut short
+// when cdd last exited; opening one offers to resume it.
+func (m *Model) ReportInterrupted() tea.Cmd {
+	runs := m.agent.Runs()
+	if runs == nil {
+		return nil
+	}
+	others := 0
+	for _, run := range runs.List() {
+		if _, ok := m.agent.Sessions().Get(run.SessionID); ok && run.SessionID != m.sessionID {
+			others++
+		}
+	}
+	if others == 0 {
+		return nil
+	}
+	return util.ReportInfo(fmt.Sprintf("Replies in %d other session%s were cut short when cdd last exited; open with /sessions to resume",
+		others, pluralize(others)))
+}
+
+// handleRecoveryKey resumes or summarizes the interrupted run, or dismisses
+// it so it is not offered again.
+func (m *Model) handleRecoveryKey(msg tea.KeyMsg) tea.Cmd {
+	var prompt string
+	switch msg.String() {
+	case "r", "R", "enter":
+		prompt = interruptedResumePrompt
+	case "s", "S":
+		prompt = interruptedSummaryPrompt
+	case "esc":
+	default:
+		return nil
+	}
+
+	run := m.interrupted
+	m.interrupted = nil
+	m.status.SetNotice("")
+	if err := m.agent.Runs().Dismiss(run.SessionID); err != nil {
+		debug.Log("Dismissing interrupted run: %v", err)
+	}
+	if prompt == "" {
+		return nil
+	}
+	return m.startStream(prompt, nil, "")
+}
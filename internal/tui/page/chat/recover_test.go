@@ -0,0 +1,90 @@
+package chat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+)
+
+// crashedRun leaves a run record for sessionID as a cdd that died mid-reply
+// would.
+func crashedRun(t *testing.T, dir, sessionID, prompt string) {
+	t.Helper()
+	record := fmt.Sprintf(`{"session_id":%q,"prompt":%q}`, sessionID, prompt)
+	if err := os.WriteFile(filepath.Join(dir, sessionID+".json"), []byte(record), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChat_OffersToResumeInterruptedRun(t *testing.T) {
+	dir := t.TempDir()
+	runs := agent.NewRunLog(dir)
+	ag := agent.New(agent.Config{Runs: runs})
+	sessionID := ag.Sessions().Current().ID
+	crashedRun(t, dir, sessionID, "refactor the parser")
+
+	m := New(ag)
+	m.Init()
+	if m.interrupted == nil || !strings.Contains(m.status.notice, "refactor the parser") {
+		t.Fatalf("notice = %q, want an offer to resume the interrupted run", m.status.notice)
+	}
+
+	// Other keys leave the offer open.
+	m.Update(tea.KeyPressMsg{Code: 'x', Text: "x"})
+	if m.interrupted == nil {
+		t.Fatal("an unrelated key should not dismiss the offer")
+	}
+
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.interrupted != nil || m.status.notice != "" {
+		t.Error("esc should dismiss the offer")
+	}
+	if _, ok := runs.Get(sessionID); ok {
+		t.Error("a dismissed run should not be offered again")
+	}
+}
+
+func TestChat_ResumesInterruptedRun(t *testing.T) {
+	dir := t.TempDir()
+	ag := agent.New(agent.Config{Runs: agent.NewRunLog(dir)})
+	sessionID := ag.Sessions().Current().ID
+	crashedRun(t, dir, sessionID, "add tests")
+
+	m := New(ag)
+	m.Init()
+	m.Update(tea.KeyPressMsg{Code: 'r', Text: "r"})
+	if !m.IsStreaming() {
+		t.Fatal("r should resume the task")
+	}
+	if got := m.messages.messages[len(m.messages.messages)-2].Content; got != interruptedResumePrompt {
+		t.Errorf("sent %q, want the resume prompt", got)
+	}
+}
+
+func TestChat_ReportsOtherInterruptedSessions(t *testing.T) {
+	dir := t.TempDir()
+	ag := agent.New(agent.Config{Runs: agent.NewRunLog(dir)})
+	other := ag.Sessions().Create("Other")
+	ag.Sessions().Create("Current")
+	crashedRun(t, dir, other.ID, "deploy")
+	crashedRun(t, dir, "deleted-session", "gone")
+
+	m := New(ag)
+	m.Init()
+	if m.interrupted != nil {
+		t.Error("the current session was not interrupted")
+	}
+	cmd := m.ReportInterrupted()
+	if cmd == nil {
+		t.Fatal("expected the other interrupted session to be reported")
+	}
+	if msg := fmt.Sprint(cmd()); !strings.Contains(msg, "1 other session ") {
+		t.Errorf("report = %s, want one other session", msg)
+	}
+}
@@ -0,0 +1,176 @@
+package chat
+
+import (
+	"fmt"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// replyVariant is one reply to a prompt: the assistant and tool messages
+// after it.
+type replyVariant struct {
+	messages []agent.Message
+	model    string // Model that wrote it
+}
+
+// regeneration is a reply being written again. Once the new reply is in,
+// the user picks which variant stays in the session.
+type regeneration struct {
+	prompt   agent.Message
+	variants []replyVariant
+	shown    int  // Variant on screen while choosing
+	running  bool // The new reply is streaming
+}
+
+// regenerateMsg starts writing the reply again, once the model picked for it
+// is loaded.
+type regenerateMsg struct {
+	SessionID string
+}
+
+// startRegenerate asks which model should write the last reply again. The
+// current model is offered first; without models to pick from, it is used
+// straight away.
+func (m *Model) startRegenerate() tea.Cmd {
+	if m.cfg == nil {
+		return m.regenerate(modelOption{})
+	}
+	if options, _ := modelOptions(m.cfg); len(options) < 2 { //nolint:mnd // Nothing else to pick
+		return m.regenerate(modelOption{})
+	}
+	m.filePicker.Close()
+	m.modelSwitcher.OpenFor(m.cfg, "regenerate")
+	return nil
+}
+
+// regenerate keeps the last reply as a variant and sends its prompt again,
+// switching to option first unless it is the model in use or zero.
+func (m *Model) regenerate(option modelOption) tea.Cmd {
+	msgs := m.agent.Sessions().GetMessages(m.sessionID)
+	last := -1
+	for i := range msgs {
+		if msgs[i].Role == agent.RoleUser && !msgs[i].IsSummary {
+			last = i
+		}
+	}
+	if last < 0 || last == len(msgs)-1 {
+		return util.ReportInfo("No reply to regenerate")
+	}
+
+	m.regen = &regeneration{
+		prompt: msgs[last],
+		variants: []replyVariant{{
+			messages: append([]agent.Message(nil), msgs[last+1:]...),
+			model:    m.status.modelName,
+		}},
+	}
+	start := util.CmdHandler(regenerateMsg{SessionID: m.sessionID})
+	if option == (modelOption{}) {
+		return start
+	}
+	if _, active := modelOptions(m.cfg); option == active {
+		return start
+	}
+	return tea.Sequence(m.switchModel(option), start)
+}
+
+// handleRegenerate sends the prompt of the reply being regenerated again.
+func (m *Model) handleRegenerate() tea.Cmd {
+	if m.regen == nil || m.isStreaming {
+		return nil
+	}
+	m.regen.running = true
+	prompt := m.regen.prompt
+	m.messages.TruncateAt(prompt.ID)
+	return m.startStream(prompt.Content, prompt.Attachments, prompt.ID)
+}
+
+// choosing reports whether the user is picking which variant to keep.
+func (r *regeneration) choosing() bool {
+	return r != nil && !r.running && len(r.variants) > 1
+}
+
+// finishRegenerate adds the new reply as a variant, once it has been written
+// or stopped, and lets the user choose between the variants. If no new reply
+// was saved, the earlier one is put back. The end of a reply is reported
+// twice, and the second report redraws the chosen variant over the refreshed
+// messages.
+func (m *Model) finishRegenerate() {
+	if m.regen.choosing() {
+		m.showVariant()
+		return
+	}
+	if m.regen == nil || !m.regen.running {
+		return
+	}
+	m.regen.running = false
+
+	msgs := m.agent.Sessions().GetMessages(m.sessionID)
+	var reply []agent.Message
+	for i := len(msgs) - 1; i >= 0 && msgs[i].Role != agent.RoleUser; i-- {
+		reply = append([]agent.Message{msgs[i]}, reply...)
+	}
+	if len(reply) == 0 {
+		m.agent.Sessions().AddMessages(m.sessionID, m.regen.variants[0].messages)
+		m.regen = nil
+		m.messages.SetMessages(m.agent.Sessions().GetMessages(m.sessionID))
+		return
+	}
+	m.regen.variants = append(m.regen.variants, replyVariant{messages: reply, model: m.status.modelName})
+	m.regen.shown = len(m.regen.variants) - 1
+	m.showVariant()
+}
+
+// showVariant puts the variant being considered on screen, in place of the
+// session's reply.
+func (m *Model) showVariant() {
+	msgs := m.agent.Sessions().GetMessages(m.sessionID)
+	newest := m.regen.variants[len(m.regen.variants)-1].messages
+	shown := append(msgs[:len(msgs)-len(newest):len(msgs)-len(newest)], m.regen.variants[m.regen.shown].messages...)
+	m.messages.SetMessages(shown)
+	m.messages.ScrollToBottom()
+
+	variant := m.regen.variants[m.regen.shown]
+	m.status.SetNotice(fmt.Sprintf("Reply %d of %d (%s): ←/→ compare · enter keep this one · esc keep the new one",
+		m.regen.shown+1, len(m.regen.variants), variant.model))
+}
+
+// keepVariant makes a variant the session's reply to the prompt, in place of
+// the newest, and drops the others.
+func (m *Model) keepVariant(i int) {
+	sessions := m.agent.Sessions()
+	if newest := len(m.regen.variants) - 1; i != newest {
+		sessions.TruncateMessages(m.sessionID, m.regen.variants[newest].messages[0].ID)
+		sessions.AddMessages(m.sessionID, m.regen.variants[i].messages)
+	}
+	m.regen = nil
+	m.status.SetNotice("")
+	m.messages.SetMessages(sessions.GetMessages(m.sessionID))
+	m.refreshStatus()
+}
+
+// handleVariantKey moves between the reply variants or keeps one.
+func (m *Model) handleVariantKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "left", "h":
+		if m.regen.shown > 0 {
+			m.regen.shown--
+			m.showVariant()
+		}
+	case "right", "l":
+		if m.regen.shown < len(m.regen.variants)-1 {
+			m.regen.shown++
+			m.showVariant()
+		}
+	case "enter":
+		kept := m.regen.shown + 1
+		m.keepVariant(m.regen.shown)
+		return util.ReportInfo(fmt.Sprintf("Kept reply %d", kept))
+	case "esc":
+		m.keepVariant(len(m.regen.variants) - 1)
+	}
+	return nil
+}
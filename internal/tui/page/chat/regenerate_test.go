@@ -0,0 +1,68 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+func TestChat_Regenerate(t *testing.T) {
+	ag := agent.New(agent.Config{})
+	m := New(ag)
+	m.Init()
+	m.SetSize(80, 40)
+	m.messages.SetSize(80, 30)
+	sessions := ag.Sessions()
+	sessions.AddMessages(m.SessionID(), []agent.Message{
+		{ID: "u1", Role: agent.RoleUser, Content: "name a color"},
+		{ID: "a1", Role: agent.RoleAssistant, Content: "blue"},
+	})
+	m.messages.SetMessages(sessions.GetMessages(m.SessionID()))
+
+	if id, ok := m.messages.LastReply(); !ok || id != "a1" {
+		t.Fatalf("LastReply() = %q, %v; want a1", id, ok)
+	}
+	if msg := m.regenerate(modelOption{})(); msg != (regenerateMsg{SessionID: m.SessionID()}) {
+		t.Fatalf("regenerate() = %#v, want the reply sent again", msg)
+	}
+
+	// The agent replaces the prompt and writes a new reply.
+	m.regen.running = true
+	sessions.TruncateMessages(m.SessionID(), "u1")
+	sessions.AddMessages(m.SessionID(), []agent.Message{
+		{ID: "u2", Role: agent.RoleUser, Content: "name a color"},
+		{ID: "a2", Role: agent.RoleAssistant, Content: "green"},
+	})
+	m.Update(StreamCompleteMsg{SessionID: m.SessionID()})
+	if !m.regen.choosing() || m.regen.shown != 1 {
+		t.Fatalf("after the new reply, regen = %+v, want the new one shown", m.regen)
+	}
+
+	m.Update(tea.KeyPressMsg{Code: tea.KeyLeft})
+	if view := ansi.Strip(m.messages.renderedContent); !strings.Contains(view, "blue") || strings.Contains(view, "green") {
+		t.Errorf("left should show the earlier reply, got:\n%s", view)
+	}
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.regen != nil {
+		t.Error("enter should end choosing")
+	}
+	msgs := sessions.GetMessages(m.SessionID())
+	if len(msgs) != 2 || msgs[0].ID != "u2" || msgs[1].Content != "blue" {
+		t.Errorf("session = %+v, want the prompt with the kept reply", msgs)
+	}
+}
+
+func TestChat_RegenerateWithoutReply(t *testing.T) {
+	ag := agent.New(agent.Config{})
+	m := New(ag)
+	m.Init()
+	ag.Sessions().AddMessage(m.SessionID(), agent.Message{ID: "u1", Role: agent.RoleUser, Content: "hi"})
+	if msg := m.regenerate(modelOption{})(); !strings.Contains(msg.(util.InfoMsg).Msg, "No reply") {
+		t.Errorf("regenerate() = %#v, want nothing to regenerate", msg)
+	}
+}
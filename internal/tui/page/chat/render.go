@@ -0,0 +1,105 @@
+package chat
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	chromastyles "github.com/alecthomas/chroma/v2/styles"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// renderUsage lists the forms of the /render command.
+const renderUsage = "Usage: /render [style dark|light|notty | wrap N|off | code THEME|default | reset]"
+
+// handleRender runs the /render command. Without arguments it shows how
+// replies are rendered; the rest change it for this run, leaving
+// options.markdown in the config as it is.
+func (m *Model) handleRender(args []string) tea.Cmd {
+	opts := m.messages.RenderOptions()
+	if len(args) == 0 {
+		m.messages.AppendMessage(agent.Message{Role: agent.RoleSystem, Content: renderReport(opts)})
+		return nil
+	}
+
+	var info string
+	switch setting := strings.ToLower(args[0]); {
+	case setting == "reset":
+		opts = config.MarkdownOptions{Style: config.MarkdownStyleDark}
+		if m.cfg != nil {
+			opts, _ = m.cfg.Markdown()
+		}
+		info = "Replies rendered as configured"
+	case len(args) != 2: //nolint:mnd // A setting and its value
+		if setting == "code" && len(args) == 1 {
+			m.messages.AppendMessage(agent.Message{
+				Role:    agent.RoleSystem,
+				Content: "Code themes\n\n" + strings.Join(chromastyles.Names(), ", "),
+			})
+			return nil
+		}
+		return util.ReportWarn(renderUsage)
+	case setting == "style":
+		style := strings.ToLower(args[1])
+		if !slices.Contains(config.MarkdownStyles, style) {
+			return util.ReportWarn(fmt.Sprintf("Unknown style %q; valid styles: %s",
+				args[1], strings.Join(config.MarkdownStyles, ", ")))
+		}
+		opts.Style = style
+		info = "Replies rendered in the " + style + " style"
+	case setting == "wrap":
+		if strings.EqualFold(args[1], "off") {
+			opts.WordWrap = 0
+			info = "Replies wrap at the chat width"
+			break
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			return util.ReportWarn("Usage: /render wrap N|off, N a column above 0")
+		}
+		opts.WordWrap = n
+		info = fmt.Sprintf("Replies wrap at column %d", n)
+	case setting == "code":
+		theme := args[1]
+		if strings.EqualFold(theme, "default") {
+			opts.CodeTheme = ""
+			info = "Code blocks use the style's colors"
+			break
+		}
+		if _, ok := chromastyles.Registry[theme]; !ok {
+			return util.ReportWarn(fmt.Sprintf("Unknown code theme %q; /render code lists them", theme))
+		}
+		opts.CodeTheme = theme
+		info = "Code blocks use the " + theme + " theme"
+	default:
+		return util.ReportWarn(renderUsage)
+	}
+
+	m.messages.SetRenderOptions(opts)
+	return util.ReportInfo(info)
+}
+
+// renderReport describes how replies are rendered, for /render.
+func renderReport(opts config.MarkdownOptions) string {
+	wrap := "at the chat width"
+	if opts.WordWrap > 0 {
+		wrap = fmt.Sprintf("at column %d, or the chat width if narrower", opts.WordWrap)
+	}
+	code := "the style's colors"
+	if opts.CodeTheme != "" {
+		code = opts.CodeTheme
+	}
+
+	var b strings.Builder
+	b.WriteString("Markdown rendering\n\n")
+	fmt.Fprintf(&b, "Style: %s\n", opts.Style)
+	fmt.Fprintf(&b, "Wrap: %s\n", wrap)
+	fmt.Fprintf(&b, "Code theme: %s\n\n", code)
+	b.WriteString("Change them for this run with /render style, wrap or code; keep them with options.markdown in the config.")
+	return b.String()
+}
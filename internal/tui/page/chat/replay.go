@@ -0,0 +1,87 @@
+package chat
+
+import (
+	"sort"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+// SetHub sets the hub whose recent events catch the page up on a session
+// that is already running when it is opened.
+func (m *Model) SetHub(hub *pubsub.Hub) {
+	m.hub = hub
+}
+
+// replaySession catches the page up on a session running elsewhere, such as
+// in a background job: the reply streamed so far and the tools it runs are
+// replayed from the events the hub kept, so the activity panel is not blank
+// until the next event. Events up to the session's last finished reply are
+// skipped, since that reply is among its messages. When the start of the
+// running reply no longer fits in what the hub kept, a note says so rather
+// than pass the rest off as the whole reply.
+func (m *Model) replaySession() tea.Cmd {
+	if m.hub == nil || m.isStreaming {
+		return nil
+	}
+
+	agentEvents, evicted := m.hub.Agent.Replay(m.sessionID)
+	var ended time.Time
+	for i := len(agentEvents) - 1; i >= 0; i-- {
+		if replyEnded(agentEvents[i].Payload.Type) {
+			ended = agentEvents[i].Timestamp
+			agentEvents = agentEvents[i+1:]
+			evicted = false // The reply started after this
+			break
+		}
+	}
+	toolReplay, _ := m.hub.Tool.Replay(m.sessionID)
+	var toolEvents []pubsub.Event[events.ToolEvent]
+	for _, e := range toolReplay {
+		if e.Timestamp.After(ended) {
+			toolEvents = append(toolEvents, e)
+		}
+	}
+	if len(agentEvents) == 0 && len(toolEvents) == 0 {
+		return nil
+	}
+
+	// Apply the events in the order they were published, as if streamed here.
+	type replayed struct {
+		at    time.Time
+		apply func()
+	}
+	steps := make([]replayed, 0, len(agentEvents)+len(toolEvents))
+	for _, e := range agentEvents {
+		steps = append(steps, replayed{e.Timestamp, func() { m.handleAgentEvent(e) }})
+	}
+	for _, e := range toolEvents {
+		steps = append(steps, replayed{e.Timestamp, func() { m.handleToolEvent(e) }})
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].at.Before(steps[j].at) })
+
+	m.isStreaming = true
+	m.runStarted = steps[0].at
+	m.input.Disable()
+	m.status.SetStatus(StatusThinking)
+	if evicted {
+		m.messages.AppendMessage(agent.Message{
+			Role:    agent.RoleSystem,
+			Content: "Earlier output of this reply is not available.",
+		})
+	}
+	m.messages.AppendMessage(agent.Message{Role: agent.RoleAssistant})
+	for _, step := range steps {
+		step.apply()
+	}
+	return m.activity.SetThinking(true)
+}
+
+// replyEnded reports whether an agent event ends a reply.
+func replyEnded(t events.AgentEventType) bool {
+	return t == events.AgentEventComplete || t == events.AgentEventCancelled || t == events.AgentEventError
+}
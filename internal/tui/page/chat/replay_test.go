@@ -0,0 +1,88 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/pubsub"
+)
+
+func TestChat_ReplaysRunningSession(t *testing.T) {
+	hub := pubsub.NewHub()
+	defer hub.Shutdown()
+	m := New(agent.New(agent.Config{}))
+	m.SetHub(hub)
+	m.Init()
+	home := m.sessionID
+
+	job := m.agent.Sessions().Create("Job")
+	m.agent.Sessions().AddMessage(job.ID, agent.Message{Role: agent.RoleUser, Content: "fix the build"})
+
+	// An earlier reply that finished, then the one still running.
+	hub.Agent.Publish(pubsub.EventUpdated, events.NewTextDeltaEvent(job.ID, "m1", "old reply"))
+	hub.Agent.Publish(pubsub.EventCompleted, events.NewCompleteEvent(job.ID, "m1", events.CompletionInfo{}))
+	hub.Tool.Publish(pubsub.EventCompleted, events.NewToolCompletedEvent(job.ID, "c0", "read", "", 0))
+	hub.Agent.Publish(pubsub.EventUpdated, events.NewTextDeltaEvent(job.ID, "m2", "Running "))
+	hub.Tool.Publish(pubsub.EventStarted, events.NewToolStartedEvent(job.ID, "c1", "bash", `{"command":"go build"}`))
+	hub.Agent.Publish(pubsub.EventUpdated, events.NewTextDeltaEvent(job.ID, "m2", "the build"))
+
+	m.switchSession(job.ID)
+
+	if !m.isStreaming || m.input.IsEnabled() {
+		t.Error("a running session should open as streaming, with the input disabled")
+	}
+	msgs := m.messages.messages
+	if last := msgs[len(msgs)-1]; last.Role != agent.RoleAssistant || last.Content != "Running the build" {
+		t.Errorf("last message = %s %q, want the reply streamed so far", last.Role, last.Content)
+	}
+	if len(m.activity.tools) != 1 || m.activity.tools[0].Name != "bash" || m.activity.tools[0].Status != ToolStatusRunning {
+		t.Errorf("activity = %+v, want bash running", m.activity.tools)
+	}
+
+	// The reply finishing is handled as if it had streamed here.
+	hub.Agent.Publish(pubsub.EventCompleted, events.NewCompleteEvent(job.ID, "m2", events.CompletionInfo{}))
+	kept, _ := hub.Agent.Replay(job.ID)
+	m.handleAgentEvent(kept[len(kept)-1])
+	if m.isStreaming {
+		t.Error("the reply completing should end streaming")
+	}
+
+	// A session whose last reply finished has nothing to replay.
+	m.switchSession(home)
+	m.switchSession(job.ID)
+	if m.isStreaming || len(m.activity.tools) != 0 {
+		t.Errorf("a finished session should open idle, got streaming=%v activity=%+v", m.isStreaming, m.activity.tools)
+	}
+}
+
+func TestChat_ReplaysTailOfLongReply(t *testing.T) {
+	hub := pubsub.NewHub()
+	defer hub.Shutdown()
+	m := New(agent.New(agent.Config{}))
+	m.SetHub(hub)
+	m.Init()
+
+	job := m.agent.Sessions().Create("Job")
+	m.agent.Sessions().AddMessage(job.ID, agent.Message{Role: agent.RoleUser, Content: "write a novel"})
+
+	// The reply has streamed more deltas than the hub keeps.
+	hub.Agent.Publish(pubsub.EventUpdated, events.NewTextDeltaEvent(job.ID, "m1", "Chapter one"))
+	for range pubsub.AgentReplaySize {
+		hub.Agent.Publish(pubsub.EventUpdated, events.NewTextDeltaEvent(job.ID, "m1", "."))
+	}
+
+	m.switchSession(job.ID)
+
+	msgs := m.messages.messages
+	if len(msgs) < 2 {
+		t.Fatalf("expected a note and the reply, got %d messages", len(msgs))
+	}
+	if note := msgs[len(msgs)-2]; note.Role != agent.RoleSystem || !strings.Contains(note.Content, "not available") {
+		t.Errorf("message before the reply = %s %q, want a note that earlier output is missing", note.Role, note.Content)
+	}
+	if last := msgs[len(msgs)-1]; strings.Contains(last.Content, "Chapter one") || len(last.Content) != pubsub.AgentReplaySize {
+		t.Errorf("reply has %d bytes, want only the %d kept deltas", len(last.Content), pubsub.AgentReplaySize)
+	}
+}
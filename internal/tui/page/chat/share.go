@@ -0,0 +1,57 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/share"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// sharedMsg reports the outcome of /share.
+type sharedMsg struct {
+	url string
+	err error
+}
+
+// handleShare runs the /share command: it publishes the current session,
+// with secrets redacted, to the service set under integrations in cdd.json.
+func (m *Model) handleShare() tea.Cmd {
+	if m.agent == nil {
+		return util.ReportWarn("No agent configured")
+	}
+	if m.isStreaming {
+		return util.ReportWarn("Wait for the reply to finish before sharing")
+	}
+	sess, ok := m.agent.Sessions().Get(m.sessionID)
+	if !ok || len(sess.Messages) == 0 {
+		return util.ReportInfo("Nothing to share yet")
+	}
+
+	var integrations *config.IntegrationsConfig
+	var secrets []string
+	if m.cfg != nil {
+		integrations = m.cfg.Integrations
+		secrets = m.cfg.Secrets()
+	}
+	publisher, err := share.New(integrations)
+	if err != nil {
+		return util.ReportError(err)
+	}
+
+	doc := share.Document{
+		Filename:    fmt.Sprintf("session-%s.md", sess.ID[:8]),
+		Description: "cdd session: " + sess.Title,
+		Content:     share.Redact(sessionMarkdown(sess), secrets),
+	}
+	return tea.Batch(
+		util.ReportInfo("Sharing to "+publisher.Name()+"…"),
+		func() tea.Msg {
+			url, err := publisher.Publish(context.Background(), doc)
+			return sharedMsg{url: url, err: err}
+		},
+	)
+}
@@ -3,10 +3,13 @@ package chat
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"charm.land/lipgloss/v2"
 
+	"github.com/guilhermegouw/cdd/internal/config"
 	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
 	"github.com/guilhermegouw/cdd/internal/tui/styles"
 )
 
@@ -20,38 +23,118 @@ const (
 	StatusError
 )
 
+// maxTitleWidth is the most of a session title shown in the status bar.
+const maxTitleWidth = 30
+
 // StatusBar displays the current chat status.
 type StatusBar struct {
-	modelName string
-	errorMsg  string
-	width     int
-	status    Status
+	modelName     string
+	errorMsg      string
+	notice        string
+	inputMode     string
+	agentMode     string
+	attached      []string
+	width         int
+	status        Status
+	unread        bool     // Output arrived below the scrolled-up view
+	segments      []string // Segments shown on the left, from config.StatusSegments
+	sessionTitle  string
+	contextUsed   int64
+	contextWindow int64 // 0 when unknown
+	tokens        int64 // Input and output tokens of the session
+	cost          float64
+	branch        string
+	elapsed       time.Duration // Time the reply in progress has taken, 0 when idle
 }
 
 // NewStatusBar creates a new status bar.
 func NewStatusBar() *StatusBar {
 	return &StatusBar{
-		status: StatusReady,
+		status:   StatusReady,
+		segments: config.DefaultStatusBar,
 	}
 }
 
+// SetSegments sets which segments are shown, in order.
+func (s *StatusBar) SetSegments(segments []string) {
+	s.segments = segments
+}
+
+// SetSessionTitle sets the title of the current session.
+func (s *StatusBar) SetSessionTitle(title string) {
+	s.sessionTitle = title
+}
+
+// SetContext sets the estimated tokens the next request sends and the
+// model's context window, or 0 when it is not known.
+func (s *StatusBar) SetContext(used, window int64) {
+	s.contextUsed = used
+	s.contextWindow = window
+}
+
+// SetUsage sets the tokens and cost of the session so far.
+func (s *StatusBar) SetUsage(tokens int64, cost float64) {
+	s.tokens = tokens
+	s.cost = cost
+}
+
+// SetBranch sets the git branch of the working directory, or "" outside a
+// repository.
+func (s *StatusBar) SetBranch(branch string) {
+	s.branch = branch
+}
+
+// SetElapsed sets how long the reply in progress has taken, or 0 when there
+// is none.
+func (s *StatusBar) SetElapsed(d time.Duration) {
+	s.elapsed = d
+}
+
 // SetStatus sets the current status.
 func (s *StatusBar) SetStatus(status Status) {
 	s.status = status
+	s.notice = ""
 	if status == StatusReady {
 		s.errorMsg = ""
 	}
 }
 
+// SetNotice sets a transient message, such as a pending retry, shown in place
+// of the model name. It is cleared by the next status change.
+func (s *StatusBar) SetNotice(msg string) {
+	s.notice = msg
+}
+
 // SetModelName sets the model name to display.
 func (s *StatusBar) SetModelName(name string) {
 	s.modelName = name
 }
 
+// SetInputMode sets the vim mode of the input, or "" to hide it.
+func (s *StatusBar) SetInputMode(mode string) {
+	s.inputMode = mode
+}
+
+// SetAgentMode sets the agent's mode, or "" to hide it.
+func (s *StatusBar) SetAgentMode(mode string) {
+	s.agentMode = mode
+}
+
+// SetAttachments sets the names of the images waiting to be sent.
+func (s *StatusBar) SetAttachments(names []string) {
+	s.attached = names
+}
+
+// SetUnread sets whether output arrived while the chat was scrolled up.
+func (s *StatusBar) SetUnread(unread bool) {
+	s.unread = unread
+}
+
 // SetError sets an error message.
 func (s *StatusBar) SetError(msg string) {
 	s.status = StatusError
 	s.errorMsg = msg
+	s.notice = ""
 }
 
 // SetWidth sets the status bar width.
@@ -80,22 +163,57 @@ func (s *StatusBar) View() string {
 			errMsg = errMsg[:maxLen-3] + "..."
 		}
 		left = t.S().Error.Render("Error: " + errMsg)
-	} else if s.modelName != "" {
-		left = t.S().Muted.Render(s.modelName)
-	} else {
+	} else if s.notice != "" {
+		left = t.S().Warning.Render(s.notice)
+	} else if segments := s.segmentsView(); segments != "" {
+		left = segments
+	} else if len(s.segments) > 0 {
 		// DEBUG: Always show something in status bar
 		left = t.S().Muted.Render("─── STATUS BAR ───")
 	}
 
+	if s.agentMode != "" {
+		left = t.S().Info.Render(s.agentMode) + "  " + left
+	}
+	if len(s.attached) > 0 {
+		left = t.S().Primary.Render("+ "+strings.Join(s.attached, ", ")) + "  " + left
+	}
+	if s.inputMode != "" {
+		left = t.S().Primary.Render(s.inputMode) + "  " + left
+	}
+	if s.status == StatusThinking && styles.Accessible() {
+		// Without the spinner, the state is said in words.
+		left = t.S().Info.Render("Working") + "  " + left
+	}
+	if s.unread {
+		hint := styles.Icon("↓ ") + "new output"
+		if k := keymap.Current().Key(keymap.JumpUnread); k != "" {
+			hint += " (" + shortcutKey(k) + ")"
+		}
+		left = t.S().Info.Render(hint) + "  " + left
+	}
+
 	// Right side: context-aware shortcuts
-	var shortcuts string
-	//nolint:exhaustive // StatusReady and StatusError use default case
-	switch s.status {
-	case StatusThinking:
-		shortcuts = "Esc cancel · Ctrl+C quit"
-	default:
-		shortcuts = "Enter send · Esc cancel · Ctrl+C quit"
+	km := keymap.Current()
+	hints := []struct {
+		action keymap.Action
+		label  string
+	}{
+		{keymap.Send, "send"},
+		{keymap.Cancel, "cancel"},
+		{keymap.Quit, "quit"},
+		{keymap.Help, "keys"},
 	}
+	if s.status == StatusThinking {
+		hints = hints[1:3]
+	}
+	var parts []string
+	for _, h := range hints {
+		if k := km.Key(h.action); k != "" {
+			parts = append(parts, shortcutKey(k)+" "+h.label)
+		}
+	}
+	shortcuts := strings.Join(parts, " · ")
 	right := t.S().Muted.Render(shortcuts)
 	debug.Event("status", "View", fmt.Sprintf("left=%q right=%q width=%d", left, shortcuts, s.width))
 
@@ -111,3 +229,88 @@ func (s *StatusBar) View() string {
 	debug.Event("status", "View", fmt.Sprintf("lines=%d width=%d", strings.Count(result, "\n")+1, s.width))
 	return result
 }
+
+// segmentsView renders the segments that have something to show, in order.
+func (s *StatusBar) segmentsView() string {
+	t := styles.CurrentTheme()
+	var parts []string
+	for _, name := range s.segments {
+		if text := s.segmentText(name); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return t.S().Muted.Render(strings.Join(parts, " · "))
+}
+
+// segmentText returns the text of one segment, or "" when it has nothing to
+// show.
+func (s *StatusBar) segmentText(name string) string {
+	switch name {
+	case config.SegmentModel:
+		return s.modelName
+	case config.SegmentSession:
+		title := []rune(s.sessionTitle)
+		if len(title) > maxTitleWidth {
+			return string(title[:maxTitleWidth-1]) + "…"
+		}
+		return s.sessionTitle
+	case config.SegmentContext:
+		switch {
+		case s.contextUsed <= 0:
+			return ""
+		case s.contextWindow <= 0:
+			return "~" + formatTokens(s.contextUsed) + " context"
+		}
+		return fmt.Sprintf("%s/%s context (%d%%)", formatTokens(s.contextUsed), formatTokens(s.contextWindow),
+			s.contextUsed*100/s.contextWindow) //nolint:mnd // Percent
+	case config.SegmentCost:
+		switch {
+		case s.tokens <= 0:
+			return ""
+		case s.cost <= 0:
+			return formatTokens(s.tokens) + " tokens"
+		}
+		return fmt.Sprintf("%s tokens $%.2f", formatTokens(s.tokens), s.cost)
+	case config.SegmentBranch:
+		if s.branch == "" {
+			return ""
+		}
+		if styles.Accessible() {
+			return "branch " + s.branch
+		}
+		return "⎇ " + s.branch
+	case config.SegmentElapsed:
+		if s.elapsed <= 0 {
+			return ""
+		}
+		return s.elapsed.Truncate(time.Second).String()
+	}
+	return ""
+}
+
+// formatTokens shortens a token count, e.g. 12345 as "12.3K".
+func formatTokens(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fK", float64(n)/1000) //nolint:mnd // Thousands
+	}
+	return fmt.Sprint(n)
+}
+
+// shortcutKey formats a key for the status bar, e.g. "ctrl+c" as "Ctrl+C".
+func shortcutKey(k string) string {
+	if len(k) == 1 {
+		return k
+	}
+	parts := strings.Split(k, "+")
+	for i, p := range parts {
+		if len(p) == 1 {
+			parts[i] = strings.ToUpper(p)
+		} else if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "+")
+}
@@ -0,0 +1,55 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+)
+
+func TestStatusBar_Segments(t *testing.T) {
+	s := NewStatusBar()
+	s.SetWidth(200)
+	s.SetModelName("claude-sonnet")
+	s.SetSessionTitle("Refactor the status bar to show segments")
+	s.SetContext(12_345, 200_000)
+	s.SetUsage(45_600, 0.126)
+	s.SetBranch("main")
+	s.SetElapsed(75 * time.Second)
+
+	view := ansi.Strip(s.View())
+	for _, want := range []string{"claude-sonnet", "12.3K/200.0K context (6%)", "45.6K tokens $0.13", "⎇ main", "1m15s"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() = %q, want it to contain %q", view, want)
+		}
+	}
+	if strings.Contains(view, "Refactor") {
+		t.Errorf("View() = %q, the session title is not a default segment", view)
+	}
+
+	s.SetSegments([]string{config.SegmentSession, config.SegmentModel})
+	view = ansi.Strip(s.View())
+	if !strings.Contains(view, "Refactor the status bar to sh… · claude-sonnet") {
+		t.Errorf("View() = %q, want the shortened title then the model", view)
+	}
+	if strings.Contains(view, "main") || strings.Contains(view, "context") {
+		t.Errorf("View() = %q, want only the chosen segments", view)
+	}
+
+	// Segments with nothing to show are left out, and errors take their place.
+	s.SetSegments(config.DefaultStatusBar)
+	s.SetContext(0, 0)
+	s.SetUsage(0, 0)
+	s.SetBranch("")
+	s.SetElapsed(0)
+	if fields := strings.Fields(ansi.Strip(s.View())); fields[0] != "claude-sonnet" || fields[1] != "Enter" {
+		t.Errorf("View() = %q, want only the model name on the left", strings.Join(fields, " "))
+	}
+	s.SetError("boom")
+	if view = ansi.Strip(s.View()); !strings.Contains(view, "Error: boom") || strings.Contains(view, "claude-sonnet") {
+		t.Errorf("View() = %q, want the error in place of the segments", view)
+	}
+}
@@ -0,0 +1,76 @@
+package chat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/agent"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// handleSystem runs the /system command. Without arguments it shows the
+// session's system prompt; /system reset restores the default, /system file
+// PATH uses a file's contents, and any other text becomes the prompt.
+func (m *Model) handleSystem(args []string) tea.Cmd {
+	if m.agent == nil {
+		return util.ReportWarn("No agent configured")
+	}
+	if len(args) == 0 {
+		m.messages.AppendMessage(agent.Message{
+			Role:    agent.RoleSystem,
+			Content: m.systemPromptReport(),
+		})
+		return nil
+	}
+	if m.isStreaming {
+		return util.ReportWarn("Wait for the reply to finish before changing the system prompt")
+	}
+
+	var prompt string
+	switch strings.ToLower(args[0]) {
+	case "reset":
+		if len(args) > 1 {
+			prompt = strings.Join(args, " ")
+		}
+	case "file":
+		if len(args) < 2 { //nolint:mnd // "file" and a path
+			return util.ReportWarn("Usage: /system file PATH")
+		}
+		path := cleanPath(strings.Join(args[1:], " "))
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(m.workingDir(), path)
+		}
+		data, err := os.ReadFile(path) //nolint:gosec // G304: The user names the file.
+		if err != nil {
+			return util.ReportError(fmt.Errorf("reading system prompt: %w", err))
+		}
+		if prompt = strings.TrimSpace(string(data)); prompt == "" {
+			return util.ReportWarn(path + " is empty")
+		}
+	default:
+		prompt = strings.Join(args, " ")
+	}
+
+	if !m.agent.SetSessionSystemPrompt(m.sessionID, prompt) {
+		return util.ReportError(fmt.Errorf("saving the system prompt for this session"))
+	}
+	m.refreshStatus()
+	if prompt == "" {
+		return util.ReportSuccess("System prompt restored to the default")
+	}
+	return util.ReportSuccess("System prompt set for this session")
+}
+
+// systemPromptReport shows the system prompt sent in this session for the
+// /system command.
+func (m *Model) systemPromptReport() string {
+	heading := "System prompt (default; /system TEXT sets one for this session):"
+	if sess, ok := m.agent.Sessions().Get(m.sessionID); ok && sess.SystemPrompt != "" {
+		heading = "System prompt (set for this session; /system reset restores the default):"
+	}
+	return heading + "\n\n" + m.agent.SystemPrompt(m.sessionID)
+}
@@ -84,7 +84,7 @@ func (p *TodoPanel) View() string {
 
 	// Header
 	headerStyle := t.S().Muted.Bold(true)
-	lines = append(lines, headerStyle.Render("─ Tasks "))
+	lines = append(lines, headerStyle.Render(styles.Icon("─ ")+"Tasks "))
 
 	// Todo items
 	for _, todo := range p.todos {
@@ -93,7 +93,7 @@ func (p *TodoPanel) View() string {
 	}
 
 	// Bottom border
-	lines = append(lines, t.S().Muted.Render(strings.Repeat("─", 10)))
+	lines = append(lines, t.S().Muted.Render(styles.Icon(strings.Repeat("─", 10))))
 
 	content := strings.Join(lines, "\n")
 
@@ -126,10 +126,27 @@ func (p *TodoPanel) renderTodoItem(t *styles.Theme, todo tools.TodoItem) string
 		text = todo.Content // Use imperative form for pending
 	}
 
+	if styles.Accessible() {
+		icon = todoStatusText(todo.Status)
+	}
+
 	// Format: "  icon text"
 	return "  " + iconStyle.Render(icon) + " " + p.styledText(t, todo.Status, text)
 }
 
+// todoStatusText names a todo status in words, in place of its icon.
+func todoStatusText(status tools.TodoStatus) string {
+	switch status {
+	case tools.TodoStatusCompleted:
+		return "[done]"
+	case tools.TodoStatusInProgress:
+		return "[now]"
+	case tools.TodoStatusPending:
+		return "[todo]"
+	}
+	return ""
+}
+
 // styledText applies appropriate styling to todo text based on status.
 func (p *TodoPanel) styledText(t *styles.Theme, status tools.TodoStatus, text string) string {
 	text = p.truncateText(text)
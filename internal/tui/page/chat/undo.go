@@ -0,0 +1,61 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/journal"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// handleUndo runs the /undo command: it reverts the agent's last file change
+// in this session, the last N with /undo N, or every change with /undo all.
+func (m *Model) handleUndo(args []string) tea.Cmd {
+	count := 1
+	if len(args) > 0 {
+		if strings.EqualFold(args[0], "all") {
+			count = 0
+		} else if n, err := strconv.Atoi(args[0]); err == nil && n > 0 {
+			count = n
+		} else {
+			return util.ReportWarn("Usage: /undo [N|all]")
+		}
+	}
+	if m.isStreaming {
+		return util.ReportWarn("Wait for the reply to finish before undoing changes")
+	}
+	if m.agent == nil || m.agent.Journal() == nil {
+		return util.ReportInfo("File changes are not being recorded")
+	}
+
+	reverted, err := m.agent.Journal().Undo(m.sessionID, count)
+	return undoReport(m.workingDir(), reverted, err)
+}
+
+// undoReport describes the outcome of an undo for the status bar.
+func undoReport(dir string, reverted []journal.Entry, err error) tea.Cmd {
+	if errors.Is(err, journal.ErrNothingToUndo) {
+		return util.ReportInfo("No file changes to undo")
+	}
+
+	paths := make([]string, 0, len(reverted))
+	for _, entry := range reverted {
+		paths = append(paths, mentionName(dir, entry.Path))
+	}
+	summary := fmt.Sprintf("Reverted %d change%s: %s", len(paths), pluralize(len(paths)), strings.Join(paths, ", "))
+
+	switch {
+	case errors.Is(err, journal.ErrModified):
+		if len(paths) == 0 {
+			return util.ReportWarn("Not undone: " + err.Error())
+		}
+		return util.ReportWarn(summary + "; stopped, " + err.Error())
+	case err != nil:
+		return util.ReportError(fmt.Errorf("undo failed: %w", err))
+	}
+	return util.ReportSuccess(summary)
+}
@@ -0,0 +1,717 @@
+package chat
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// vimMode is the editing mode of the vim input.
+type vimMode int
+
+const (
+	vimInsert vimMode = iota
+	vimNormal
+)
+
+// String returns the mode name shown in the status bar.
+func (m vimMode) String() string {
+	if m == vimNormal {
+		return "NORMAL"
+	}
+	return "INSERT"
+}
+
+// maxVimUndo caps the undo history of the vim input.
+const maxVimUndo = 100
+
+// vimRegister holds yanked or deleted text.
+type vimRegister struct {
+	text     string
+	linewise bool
+}
+
+// vimBuffer is the text being edited and the cursor as a rune offset.
+type vimBuffer struct {
+	text []rune
+	pos  int
+}
+
+// vimState implements a subset of vim's normal mode on top of the chat input:
+// counts, h/j/k/l, word motions (w b e W B E), 0 ^ $ gg G, x X r, the d c y
+// operators with motions and iw/aw text objects, i a I A o O, p P, u, and
+// registers selected with "a (uppercase appends, "_ discards).
+type vimState struct {
+	mode      vimMode
+	pending   []string
+	registers map[rune]vimRegister
+	undo      []vimBuffer
+}
+
+func newVimState() *vimState {
+	return &vimState{registers: make(map[rune]vimRegister)}
+}
+
+// command is a parsed normal mode command.
+type command struct {
+	register rune
+	count    int
+	op       string // "d", "c", "y" or "" for a plain command
+	key      string // Motion, text object, or command key
+	arg      string // Character argument of r
+}
+
+// parseStatus reports whether pending keys form a complete command.
+type parseStatus int
+
+const (
+	parseDone parseStatus = iota
+	parseMore
+	parseInvalid
+)
+
+// motionKeys are the keys usable both alone and after an operator.
+var motionKeys = map[string]bool{
+	"h": true, "l": true, "j": true, "k": true,
+	"left": true, "right": true, "up": true, "down": true,
+	"0": true, "^": true, "$": true, "home": true, "end": true,
+	"w": true, "b": true, "e": true, "W": true, "B": true, "E": true,
+	"gg": true, "G": true,
+}
+
+// commandKeys are the keys that act on their own.
+var commandKeys = map[string]bool{
+	"i": true, "a": true, "I": true, "A": true, "o": true, "O": true,
+	"x": true, "delete": true, "X": true, "D": true, "C": true, "Y": true,
+	"p": true, "P": true, "u": true,
+}
+
+// parseCommand parses the pending keys of a normal mode command.
+func parseCommand(keys []string) (command, parseStatus) {
+	cmd := command{register: '"'}
+	i := 0
+	next := func() (string, bool) {
+		if i >= len(keys) {
+			return "", false
+		}
+		i++
+		return keys[i-1], true
+	}
+	count := func() (int, bool) {
+		digits := ""
+		for i < len(keys) && len(keys[i]) == 1 && keys[i][0] >= '0' && keys[i][0] <= '9' {
+			if digits == "" && keys[i] == "0" {
+				break
+			}
+			digits += keys[i]
+			i++
+		}
+		if digits == "" {
+			return 1, i < len(keys)
+		}
+		n, err := strconv.Atoi(digits)
+		return max(1, min(n, 9999)), err == nil && i < len(keys)
+	}
+
+	if i < len(keys) && keys[i] == `"` {
+		i++
+		name, ok := next()
+		if !ok {
+			return cmd, parseMore
+		}
+		r := []rune(name)
+		if len(r) != 1 || !isRegisterName(r[0]) {
+			return cmd, parseInvalid
+		}
+		cmd.register = r[0]
+	}
+
+	n, ok := count()
+	if !ok {
+		return cmd, parseMore
+	}
+	cmd.count = n
+
+	k, _ := next()
+	switch {
+	case k == "d" || k == "c" || k == "y":
+		cmd.op = k
+		n2, ok := count()
+		if !ok {
+			return cmd, parseMore
+		}
+		cmd.count *= n2
+		m, _ := next()
+		switch {
+		case m == k:
+			cmd.key = m
+		case m == "i" || m == "a":
+			obj, ok := next()
+			if !ok {
+				return cmd, parseMore
+			}
+			if obj != "w" && obj != "W" {
+				return cmd, parseInvalid
+			}
+			cmd.key = m + obj
+		case m == "g":
+			g, ok := next()
+			if !ok {
+				return cmd, parseMore
+			}
+			if g != "g" {
+				return cmd, parseInvalid
+			}
+			cmd.key = "gg"
+		case motionKeys[m]:
+			cmd.key = m
+		default:
+			return cmd, parseInvalid
+		}
+	case k == "g":
+		g, ok := next()
+		if !ok {
+			return cmd, parseMore
+		}
+		if g != "g" {
+			return cmd, parseInvalid
+		}
+		cmd.key = "gg"
+	case k == "r":
+		c, ok := next()
+		if !ok {
+			return cmd, parseMore
+		}
+		if len([]rune(c)) != 1 {
+			return cmd, parseInvalid
+		}
+		cmd.key, cmd.arg = k, c
+	case motionKeys[k] || commandKeys[k]:
+		cmd.key = k
+	default:
+		return cmd, parseInvalid
+	}
+	return cmd, parseDone
+}
+
+func isRegisterName(r rune) bool {
+	return r == '"' || r == '_' || r == '0' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// handleKey feeds a normal mode key to the state machine and applies any
+// completed command to buf.
+func (v *vimState) handleKey(k string, buf *vimBuffer) {
+	if k == "esc" {
+		v.pending = nil
+		return
+	}
+	v.pending = append(v.pending, k)
+	cmd, status := parseCommand(v.pending)
+	if status == parseMore {
+		return
+	}
+	v.pending = nil
+	if status == parseDone {
+		v.execute(cmd, buf)
+	}
+	if v.mode == vimNormal {
+		buf.clampNormal()
+	}
+}
+
+// enterNormal switches from insert to normal mode, moving the cursor back
+// onto the last inserted character like vim does.
+func (v *vimState) enterNormal(buf *vimBuffer) {
+	v.mode = vimNormal
+	v.pending = nil
+	if buf.pos > buf.lineStart(buf.pos) {
+		buf.pos--
+	}
+	buf.clampNormal()
+}
+
+// enterInsert switches to insert mode, recording the text for undo.
+func (v *vimState) enterInsert(buf *vimBuffer) {
+	v.saveUndo(buf)
+	v.mode = vimInsert
+}
+
+func (v *vimState) saveUndo(buf *vimBuffer) {
+	snap := vimBuffer{text: append([]rune(nil), buf.text...), pos: buf.pos}
+	if n := len(v.undo); n > 0 && string(v.undo[n-1].text) == string(snap.text) {
+		return
+	}
+	v.undo = append(v.undo, snap)
+	if len(v.undo) > maxVimUndo {
+		v.undo = v.undo[1:]
+	}
+}
+
+//nolint:gocyclo // One case per vim command
+func (v *vimState) execute(cmd command, buf *vimBuffer) {
+	if cmd.op != "" {
+		v.operate(cmd, buf)
+		return
+	}
+	if motionKeys[cmd.key] {
+		buf.pos, _, _ = buf.motion(cmd.key, cmd.count, false)
+		return
+	}
+
+	switch cmd.key {
+	case "i":
+		v.enterInsert(buf)
+	case "a":
+		v.enterInsert(buf)
+		if buf.pos < buf.lineEnd(buf.pos) {
+			buf.pos++
+		}
+	case "I":
+		v.enterInsert(buf)
+		buf.pos = buf.firstNonBlank(buf.pos)
+	case "A":
+		v.enterInsert(buf)
+		buf.pos = buf.lineEnd(buf.pos)
+	case "o":
+		v.enterInsert(buf)
+		buf.pos = buf.lineEnd(buf.pos)
+		buf.insert("\n")
+	case "O":
+		v.enterInsert(buf)
+		buf.pos = buf.lineStart(buf.pos)
+		buf.insert("\n")
+		buf.pos--
+	case "x", "delete":
+		end := min(buf.pos+cmd.count, buf.lineEnd(buf.pos))
+		if end > buf.pos {
+			v.saveUndo(buf)
+			v.store(cmd.register, string(buf.text[buf.pos:end]), false, false)
+			buf.delete(buf.pos, end)
+		}
+	case "X":
+		start := max(buf.pos-cmd.count, buf.lineStart(buf.pos))
+		if start < buf.pos {
+			v.saveUndo(buf)
+			v.store(cmd.register, string(buf.text[start:buf.pos]), false, false)
+			buf.delete(start, buf.pos)
+		}
+	case "D", "C":
+		op := map[string]string{"D": "d", "C": "c"}[cmd.key]
+		v.operate(command{register: cmd.register, count: 1, op: op, key: "$"}, buf)
+	case "Y":
+		v.operate(command{register: cmd.register, count: cmd.count, op: "y", key: "y"}, buf)
+	case "r":
+		end := buf.pos + cmd.count
+		if end <= buf.lineEnd(buf.pos) {
+			v.saveUndo(buf)
+			replacement := []rune(strings.Repeat(cmd.arg, cmd.count))
+			copy(buf.text[buf.pos:end], replacement)
+			buf.pos = end - 1
+		}
+	case "p", "P":
+		v.put(cmd, buf)
+	case "u":
+		if n := len(v.undo); n > 0 {
+			*buf = v.undo[n-1]
+			v.undo = v.undo[:n-1]
+		}
+	}
+}
+
+// operate applies d, c or y to the range covered by the command's motion.
+func (v *vimState) operate(cmd command, buf *vimBuffer) {
+	var start, end int
+	linewise := false
+
+	switch {
+	case cmd.key == cmd.op:
+		linewise = true
+		start = buf.lineStart(buf.pos)
+		end = buf.lineEnd(buf.pos)
+		for range cmd.count - 1 {
+			if end >= len(buf.text) {
+				break
+			}
+			end = buf.lineEnd(end + 1)
+		}
+	case cmd.key == "iw" || cmd.key == "aw" || cmd.key == "iW" || cmd.key == "aW":
+		start, end = buf.wordObject(cmd.key[0] == 'a', cmd.key[1] == 'W', cmd.count)
+	case cmd.op == "c" && (cmd.key == "w" || cmd.key == "W") && !isSpace(buf.at(buf.pos)):
+		// Like vim, cw on a word changes to the end of the word.
+		big := cmd.key == "W"
+		start, end = buf.pos, buf.pos
+		class := charClass(buf.text[end], big)
+		for end+1 < len(buf.text) && charClass(buf.text[end+1], big) == class {
+			end++
+		}
+		for range cmd.count - 1 {
+			end = buf.wordEnd(end, big)
+		}
+		end++
+	default:
+		target, inclusive, lines := buf.motion(cmd.key, cmd.count, true)
+		start, end = min(buf.pos, target), max(buf.pos, target)
+		if lines {
+			linewise = true
+			start = buf.lineStart(start)
+			end = buf.lineEnd(end)
+		} else if inclusive && end < len(buf.text) {
+			end++
+		}
+	}
+
+	if linewise {
+		text := string(buf.text[start:end])
+		switch cmd.op {
+		case "y":
+			v.store(cmd.register, text, true, true)
+			buf.pos = start
+		case "d":
+			v.saveUndo(buf)
+			v.store(cmd.register, text, true, false)
+			// Remove the line break before or after the lines too.
+			if end < len(buf.text) {
+				end++
+			} else if start > 0 {
+				start--
+			}
+			buf.delete(start, end)
+			buf.pos = buf.firstNonBlank(min(start, len(buf.text)))
+		case "c":
+			v.store(cmd.register, text, true, false)
+			v.enterInsert(buf)
+			buf.delete(start, end)
+		}
+		return
+	}
+
+	if end <= start && cmd.op != "c" {
+		return
+	}
+	text := string(buf.text[start:end])
+	switch cmd.op {
+	case "y":
+		v.store(cmd.register, text, false, true)
+		buf.pos = start
+	case "d":
+		v.saveUndo(buf)
+		v.store(cmd.register, text, false, false)
+		buf.delete(start, end)
+	case "c":
+		v.store(cmd.register, text, false, false)
+		v.enterInsert(buf)
+		buf.delete(start, end)
+	}
+}
+
+// put pastes a register after (p) or before (P) the cursor.
+func (v *vimState) put(cmd command, buf *vimBuffer) {
+	reg, ok := v.registers[unicode.ToLower(cmd.register)]
+	if !ok || reg.text == "" {
+		return
+	}
+	v.saveUndo(buf)
+	text := strings.Repeat(reg.text, cmd.count)
+
+	if reg.linewise {
+		text = strings.TrimSuffix(strings.Repeat(reg.text+"\n", cmd.count), "\n")
+		// The cursor lands on the first pasted line.
+		if cmd.key == "p" {
+			end := buf.lineEnd(buf.pos)
+			buf.pos = end
+			buf.insert("\n" + text)
+			buf.pos = buf.firstNonBlank(end + 1)
+		} else {
+			start := buf.lineStart(buf.pos)
+			buf.pos = start
+			buf.insert(text + "\n")
+			buf.pos = buf.firstNonBlank(start)
+		}
+		return
+	}
+
+	if cmd.key == "p" && buf.pos < buf.lineEnd(buf.pos) {
+		buf.pos++
+	}
+	buf.insert(text)
+	buf.pos--
+}
+
+// store saves text into a register. Yanks also fill "0, and every write
+// updates the unnamed register unless the black hole register "_ is used.
+func (v *vimState) store(name rune, text string, linewise, yank bool) {
+	if name == '_' {
+		return
+	}
+	reg := vimRegister{text: text, linewise: linewise}
+	if unicode.IsUpper(name) {
+		lower := unicode.ToLower(name)
+		if prev, ok := v.registers[lower]; ok {
+			if prev.linewise || linewise {
+				reg = vimRegister{text: prev.text + "\n" + text, linewise: true}
+			} else {
+				reg.text = prev.text + text
+			}
+		}
+		name = lower
+	}
+	v.registers[name] = reg
+	v.registers['"'] = reg
+	if yank {
+		v.registers['0'] = reg
+	}
+}
+
+// at returns the rune at i, or '\n' past the end.
+func (b *vimBuffer) at(i int) rune {
+	if i < 0 || i >= len(b.text) {
+		return '\n'
+	}
+	return b.text[i]
+}
+
+func (b *vimBuffer) lineStart(pos int) int {
+	for pos > 0 && b.text[pos-1] != '\n' {
+		pos--
+	}
+	return pos
+}
+
+func (b *vimBuffer) lineEnd(pos int) int {
+	for pos < len(b.text) && b.text[pos] != '\n' {
+		pos++
+	}
+	return pos
+}
+
+func (b *vimBuffer) firstNonBlank(pos int) int {
+	start, end := b.lineStart(pos), b.lineEnd(pos)
+	for start < end && (b.text[start] == ' ' || b.text[start] == '\t') {
+		start++
+	}
+	return start
+}
+
+func (b *vimBuffer) insert(s string) {
+	r := []rune(s)
+	text := make([]rune, 0, len(b.text)+len(r))
+	text = append(text, b.text[:b.pos]...)
+	text = append(text, r...)
+	b.text = append(text, b.text[b.pos:]...)
+	b.pos += len(r)
+}
+
+func (b *vimBuffer) delete(start, end int) {
+	b.text = append(b.text[:start], b.text[end:]...)
+	b.pos = start
+}
+
+// clampNormal keeps the cursor on a character, as normal mode does not allow
+// the cursor after the end of a non-empty line.
+func (b *vimBuffer) clampNormal() {
+	b.pos = max(0, min(b.pos, len(b.text)))
+	if b.pos == b.lineEnd(b.pos) && b.pos > b.lineStart(b.pos) {
+		b.pos--
+	}
+}
+
+// motion returns where a motion moves the cursor, whether the character at
+// the target is included when an operator is applied, and whether the motion
+// is linewise.
+//
+//nolint:gocyclo // One case per motion
+func (b *vimBuffer) motion(key string, count int, operator bool) (target int, inclusive, linewise bool) {
+	pos := b.pos
+	switch key {
+	case "h", "left":
+		return max(pos-count, b.lineStart(pos)), false, false
+	case "l", "right":
+		end := b.lineEnd(pos)
+		if !operator {
+			end = max(b.lineStart(pos), end-1)
+		}
+		return min(pos+count, end), false, false
+	case "j", "down", "k", "up":
+		col := pos - b.lineStart(pos)
+		for range count {
+			if key == "j" || key == "down" {
+				if next := b.lineEnd(pos); next < len(b.text) {
+					pos = next + 1
+				}
+			} else if start := b.lineStart(pos); start > 0 {
+				pos = b.lineStart(start - 1)
+			}
+		}
+		start := b.lineStart(pos)
+		return min(start+col, b.lineEnd(start)), false, true
+	case "0", "home":
+		return b.lineStart(pos), false, false
+	case "^":
+		return b.firstNonBlank(pos), false, false
+	case "$", "end":
+		end := b.lineEnd(pos)
+		for range count - 1 {
+			if end < len(b.text) {
+				end = b.lineEnd(end + 1)
+			}
+		}
+		if !operator && end > b.lineStart(end) {
+			end--
+		}
+		return end, operator && end < b.lineEnd(end), false
+	case "gg", "G":
+		line := 0
+		if key == "G" {
+			line = -1
+		}
+		if count > 1 || (key == "gg" && count == 1) {
+			line = count - 1
+		}
+		return b.firstNonBlank(b.lineOffset(line)), false, true
+	case "w", "W":
+		for range count {
+			next := b.wordForward(pos, key == "W")
+			// An operator stops at the end of the line rather than
+			// joining it with the next one.
+			if operator && next > b.lineEnd(pos) && b.lineEnd(pos) > pos {
+				next = b.lineEnd(pos)
+			}
+			pos = next
+		}
+		return pos, false, false
+	case "b", "B":
+		for range count {
+			pos = b.wordBackward(pos, key == "B")
+		}
+		return pos, false, false
+	case "e", "E":
+		for range count {
+			pos = b.wordEnd(pos, key == "E")
+		}
+		return pos, true, false
+	}
+	return pos, false, false
+}
+
+// lineOffset returns the offset of line n, or of the last line when n is
+// negative or past the end.
+func (b *vimBuffer) lineOffset(n int) int {
+	pos := 0
+	for line := 0; n < 0 || line < n; line++ {
+		end := b.lineEnd(pos)
+		if end >= len(b.text) {
+			break
+		}
+		pos = end + 1
+	}
+	return pos
+}
+
+// charClass groups runes for word motions: 0 for whitespace, 1 for word
+// characters and 2 for punctuation. With big, all non-blanks are class 1.
+func charClass(r rune, big bool) int {
+	switch {
+	case isSpace(r):
+		return 0
+	case big || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func isSpace(r rune) bool {
+	return unicode.IsSpace(r)
+}
+
+func (b *vimBuffer) wordForward(pos int, big bool) int {
+	n := len(b.text)
+	if pos >= n {
+		return n
+	}
+	class := charClass(b.text[pos], big)
+	for pos < n && class != 0 && charClass(b.text[pos], big) == class {
+		pos++
+	}
+	for pos < n && isSpace(b.text[pos]) {
+		// An empty line is a word of its own.
+		if b.text[pos] == '\n' && pos+1 < n && b.text[pos+1] == '\n' {
+			return pos + 1
+		}
+		pos++
+	}
+	return pos
+}
+
+func (b *vimBuffer) wordEnd(pos int, big bool) int {
+	n := len(b.text)
+	pos++
+	for pos < n && isSpace(b.text[pos]) {
+		pos++
+	}
+	if pos >= n {
+		return max(0, n-1)
+	}
+	class := charClass(b.text[pos], big)
+	for pos+1 < n && charClass(b.text[pos+1], big) == class {
+		pos++
+	}
+	return pos
+}
+
+func (b *vimBuffer) wordBackward(pos int, big bool) int {
+	if pos == 0 {
+		return 0
+	}
+	pos--
+	for pos > 0 && isSpace(b.text[pos]) {
+		pos--
+	}
+	class := charClass(b.text[pos], big)
+	for pos > 0 && charClass(b.text[pos-1], big) == class {
+		pos--
+	}
+	return pos
+}
+
+// wordObject returns the range of the iw/aw text object at the cursor. Runs
+// of whitespace count as words for iw. aw adds the whitespace after the word,
+// or before it when there is none after.
+func (b *vimBuffer) wordObject(around, big bool, count int) (start, end int) {
+	lineStart, lineEnd := b.lineStart(b.pos), b.lineEnd(b.pos)
+	if lineStart == lineEnd {
+		return b.pos, b.pos
+	}
+	runEnd := func(i int) int {
+		class := charClass(b.text[i], big)
+		for i < lineEnd && charClass(b.text[i], big) == class {
+			i++
+		}
+		return i
+	}
+
+	start = b.pos
+	class := charClass(b.text[start], big)
+	for start > lineStart && charClass(b.text[start-1], big) == class {
+		start--
+	}
+	end = runEnd(b.pos)
+	for n := 1; n < count && end < lineEnd; n++ {
+		end = runEnd(end)
+	}
+	if !around {
+		return start, end
+	}
+
+	switch {
+	case class == 0 && end < lineEnd:
+		end = runEnd(end)
+	case class != 0 && end < lineEnd && isSpace(b.text[end]):
+		end = runEnd(end)
+	case class != 0:
+		for start > lineStart && isSpace(b.text[start-1]) {
+			start--
+		}
+	}
+	return start, end
+}
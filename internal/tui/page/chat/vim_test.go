@@ -0,0 +1,229 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// vimRun applies normal mode keys to text with the cursor at the "|" marker
+// and returns the result with the marker at the new cursor position.
+func vimRun(t *testing.T, v *vimState, input string, keys ...string) string {
+	t.Helper()
+	pos := strings.Index(input, "|")
+	if pos < 0 {
+		t.Fatalf("input %q has no cursor marker", input)
+	}
+	text := []rune(strings.Replace(input, "|", "", 1))
+	buf := vimBuffer{text: text, pos: len([]rune(input[:pos]))}
+
+	v.mode = vimNormal
+	for _, k := range keys {
+		if v.mode == vimInsert {
+			if k == "esc" {
+				v.enterNormal(&buf)
+				continue
+			}
+			buf.insert(k)
+			continue
+		}
+		v.handleKey(k, &buf)
+	}
+	return string(buf.text[:buf.pos]) + "|" + string(buf.text[buf.pos:])
+}
+
+func split(keys string) []string {
+	return strings.Split(keys, "")
+}
+
+func TestVim_Motions(t *testing.T) {
+	tests := []struct {
+		input string
+		keys  string
+		want  string
+	}{
+		{"|hello world", "w", "hello |world"},
+		{"|foo.bar baz", "w", "foo|.bar baz"},
+		{"|foo.bar baz", "W", "foo.bar |baz"},
+		{"|one two three", "2w", "one two |three"},
+		{"hello worl|d", "b", "hello |world"},
+		{"|hello world", "e", "hell|o world"},
+		{"  in|dented", "0", "|  indented"},
+		{"  in|dented", "^", "  |indented"},
+		{"|abc", "$", "ab|c"},
+		{"|abc", "5l", "ab|c"},
+		{"ab|c", "h", "a|bc"},
+		{"one\ntw|o\nthree", "k", "on|e\ntwo\nthree"},
+		{"one\ntw|o\nthree", "j", "one\ntwo\nth|ree"},
+		{"one\ntwo\nthr|ee", "gg", "|one\ntwo\nthree"},
+		{"|one\ntwo\nthree", "G", "one\ntwo\n|three"},
+		{"|one\ntwo\nthree", "2G", "one\n|two\nthree"},
+		{"|one\n\ntwo", "w", "one\n|\ntwo"},
+	}
+	for _, tt := range tests {
+		if got := vimRun(t, newVimState(), tt.input, split(tt.keys)...); got != tt.want {
+			t.Errorf("%q + %s = %q, want %q", tt.input, tt.keys, got, tt.want)
+		}
+	}
+}
+
+func TestVim_Edits(t *testing.T) {
+	tests := []struct {
+		input string
+		keys  []string
+		want  string
+	}{
+		{"|hello", split("x"), "|ello"},
+		{"hell|o", split("x"), "hel|l"},
+		{"hel|lo", split("X"), "he|lo"},
+		{"|hello world", split("dw"), "|world"},
+		{"hello |world", split("dw"), "hello| "},
+		{"|one two three", split("d2w"), "|three"},
+		{"|one two three", split("2dw"), "|three"},
+		{"one\n|two\nthree", split("dd"), "one\n|three"},
+		{"one\ntwo\nthre|e", split("dd"), "one\n|two"},
+		{"|one\ntwo\nthree", split("2dd"), "|three"},
+		{"|one\ntwo\nthree", split("dj"), "|three"},
+		{"hello wo|rld", split("D"), "hello w|o"},
+		{"say he|llo there", split("diw"), "say | there"},
+		{"say he|llo there", split("daw"), "say |there"},
+		{"say he|llo", split("daw"), "sa|y"},
+		{"say he|llo there", []string{"c", "i", "w", "b", "y", "e", "esc"}, "say by|e there"},
+		{"|hello world", []string{"c", "w", "h", "i", "esc"}, "h|i world"},
+		{"he|llo", []string{"C", "y", "esc"}, "he|y"},
+		{"|abc", []string{"r", "x"}, "|xbc"},
+		{"|abc", []string{"A", "d", "esc"}, "abc|d"},
+		{"a|bc", []string{"I", ">", "esc"}, "|>abc"},
+		{"|one", []string{"o", "two", "esc"}, "one\ntw|o"},
+		{"|one", []string{"O", "zero", "esc"}, "zer|o\none"},
+		{"|one two", split("yiwwP"), "one on|etwo"},
+		{"|one two", split("dwp"), "tone| wo"},
+		{"|one\ntwo", split("yyjp"), "one\ntwo\n|one"},
+		{"|one\ntwo", split("ddp"), "two\n|one"},
+		{"|one\ntwo", split("ddP"), "|one\ntwo"},
+		{"|hello", split("xu"), "|hello"},
+		{"|hello", split("xxuu"), "|hello"},
+		{"|hello", split("zx"), "|ello"},
+	}
+	for _, tt := range tests {
+		if got := vimRun(t, newVimState(), tt.input, tt.keys...); got != tt.want {
+			t.Errorf("%q + %v = %q, want %q", tt.input, tt.keys, got, tt.want)
+		}
+	}
+}
+
+func TestVim_Registers(t *testing.T) {
+	v := newVimState()
+
+	got := vimRun(t, v, "|one two three", split(`"ayiww"Ayiww"_dw`)...)
+	if got != "one two| " {
+		t.Errorf("got %q", got)
+	}
+	if reg := v.registers['a']; reg.text != "onetwo" {
+		t.Errorf(`register a = %q, want "onetwo"`, reg.text)
+	}
+	if reg := v.registers['"']; reg.text != "onetwo" {
+		t.Errorf("unnamed register = %q, want the last write before the black hole delete", reg.text)
+	}
+
+	got = vimRun(t, v, "|x", split(`"ap`)...)
+	if got != "xonetw|o" {
+		t.Errorf(`"ap = %q`, got)
+	}
+
+	// Deletes leave the yank register alone.
+	got = vimRun(t, v, "|keep drop", split(`yiwwdiw0"0P`)...)
+	if got != "kee|pkeep " {
+		t.Errorf(`"0P = %q`, got)
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		keys   string
+		status parseStatus
+		want   command
+	}{
+		{"d", parseMore, command{}},
+		{"d2", parseMore, command{}},
+		{`"`, parseMore, command{}},
+		{"di", parseMore, command{}},
+		{"g", parseMore, command{}},
+		{"dz", parseInvalid, command{}},
+		{"q", parseInvalid, command{}},
+		{"0", parseDone, command{register: '"', count: 1, key: "0"}},
+		{"10j", parseDone, command{register: '"', count: 10, key: "j"}},
+		{`"b3d2w`, parseDone, command{register: 'b', count: 6, op: "d", key: "w"}},
+		{"ciw", parseDone, command{register: '"', count: 1, op: "c", key: "iw"}},
+		{"dgg", parseDone, command{register: '"', count: 1, op: "d", key: "gg"}},
+	}
+	for _, tt := range tests {
+		got, status := parseCommand(split(tt.keys))
+		if status != tt.status {
+			t.Errorf("%s: status %v, want %v", tt.keys, status, tt.status)
+			continue
+		}
+		if status == parseDone && got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.keys, got, tt.want)
+		}
+	}
+}
+
+func TestInput_VimMode(t *testing.T) {
+	input := NewInput()
+	input.SetWidth(80)
+	input.SetVimMode(true)
+
+	press := func(keys ...string) {
+		for _, k := range keys {
+			var msg tea.KeyPressMsg
+			switch k {
+			case "esc":
+				msg = tea.KeyPressMsg{Code: tea.KeyEscape}
+			case "ctrl+j":
+				msg = tea.KeyPressMsg{Code: 'j', Mod: tea.ModCtrl}
+			default:
+				r := []rune(k)[0]
+				msg = tea.KeyPressMsg{Code: r, Text: k}
+			}
+			input, _ = input.Update(msg)
+		}
+	}
+
+	press(split("hello world")...)
+	if input.Mode() != "INSERT" || input.Value() != "hello world" {
+		t.Fatalf("mode %q value %q", input.Mode(), input.Value())
+	}
+
+	press("esc")
+	if input.Mode() != "NORMAL" {
+		t.Fatalf("esc should enter normal mode, got %q", input.Mode())
+	}
+	press(split("bdw")...)
+	if input.Value() != "hello " {
+		t.Errorf("after bdw got %q", input.Value())
+	}
+
+	press("ctrl+j")
+	press(split("0iline one")...)
+	press("ctrl+j")
+	press("esc")
+	press(split("jdd")...)
+	if input.Value() != "line one" {
+		t.Errorf("dd on the second line left %q", input.Value())
+	}
+	if input.Height() != 3 {
+		t.Errorf("input should shrink back to one line, height %d", input.Height())
+	}
+
+	input.Clear()
+	if input.Mode() != "INSERT" {
+		t.Errorf("Clear should return to insert mode, got %q", input.Mode())
+	}
+
+	input.SetVimMode(false)
+	if input.Mode() != "" {
+		t.Errorf("disabled vim mode should have no mode, got %q", input.Mode())
+	}
+}
@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/events"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// handleConfigEvent applies configuration reloaded after cdd.json or
+// custom-providers.json changed on disk: every tab gets the new providers and
+// settings, and the agent a model built from the new config, so a changed
+// key, endpoint or model takes effect with the next prompt. While a reply is
+// streaming the model is kept until every reply has finished.
+func (m *Model) handleConfigEvent(event events.ConfigEvent) tea.Cmd {
+	name := filepath.Base(event.Path)
+	if event.Type == events.ConfigEventReloadFailed {
+		return util.ReportWarn(fmt.Sprintf("%s changed but was not loaded: %v", name, event.Error))
+	}
+
+	m.cfg = event.Config
+	m.providers = event.Config.KnownProviders()
+	for _, t := range m.tabs {
+		t.page.ReloadConfig(m.cfg, m.providers)
+	}
+
+	if m.agent == nil || m.modelFactory == nil {
+		return util.ReportInfo("Reloaded " + name)
+	}
+	if m.streaming() {
+		m.modelReloadPending = true
+		return util.ReportInfo(fmt.Sprintf("Reloaded %s; the model changes once the reply finishes", name))
+	}
+	if err := m.reloadModel(); err != nil {
+		return util.ReportWarn(fmt.Sprintf("Reloaded %s, but kept the current model: %v", name, err))
+	}
+	return util.ReportInfo("Reloaded " + name)
+}
+
+// applyPendingModel swaps in the model of a config reloaded during a reply,
+// once no tab is streaming any more.
+func (m *Model) applyPendingModel() tea.Cmd {
+	if !m.modelReloadPending || m.streaming() {
+		return nil
+	}
+	m.modelReloadPending = false
+	if err := m.reloadModel(); err != nil {
+		return util.ReportWarn(fmt.Sprintf("Kept the current model after the config reload: %v", err))
+	}
+	return nil
+}
+
+// reloadModel gives the agent a model built from the current config.
+func (m *Model) reloadModel() error {
+	newModel, err := m.modelFactory()
+	if err != nil {
+		debug.Error("tui", err, "rebuilding model after config reload")
+		return err
+	}
+	m.agent.SetModel(newModel)
+	if modelName := largeModelName(m.cfg); modelName != "" && modelName != m.modelName {
+		m.modelName = modelName
+		for _, t := range m.tabs {
+			t.page.SetModelName(modelName)
+		}
+	}
+	return nil
+}
+
+// streaming reports whether any tab is waiting on a reply.
+func (m *Model) streaming() bool {
+	for _, t := range m.tabs {
+		if t.page.IsStreaming() {
+			return true
+		}
+	}
+	return false
+}
+
+// largeModelName returns the display name of the configured large model.
+func largeModelName(cfg *config.Config) string {
+	selected := cfg.Models[config.SelectedModelTypeLarge]
+	if model := cfg.GetModel(selected.Provider, selected.Model); model != nil && model.Name != "" {
+		return model.Name
+	}
+	return selected.Model
+}
@@ -0,0 +1,35 @@
+package styles
+
+import "charm.land/lipgloss/v2"
+
+// accessible is set for screen readers: no animation, box drawing or
+// decorative symbols, and nothing told by color alone.
+var accessible bool
+
+// SetAccessible turns accessible output on or off.
+func SetAccessible(on bool) {
+	accessible = on
+}
+
+// Accessible reports whether output is meant for a screen reader.
+func Accessible() bool {
+	return accessible
+}
+
+// Icon returns a decorative symbol, or "" in accessible mode, where it
+// would be read out as noise.
+func Icon(symbol string) string {
+	if accessible {
+		return ""
+	}
+	return symbol
+}
+
+// Border returns the border drawn around boxes. In accessible mode it is
+// blank, keeping the layout without box drawing characters.
+func Border() lipgloss.Border {
+	if accessible {
+		return lipgloss.HiddenBorder()
+	}
+	return lipgloss.RoundedBorder()
+}
@@ -0,0 +1,31 @@
+package styles
+
+import "github.com/charmbracelet/colorprofile"
+
+// colorProfile is the color support of the terminal the TUI draws on.
+var colorProfile = colorprofile.TrueColor
+
+// SetColorProfile sets the color support of the terminal and picks the theme
+// to match: the default theme down to 256 colors, which the renderer
+// approximates closely, and the 16-color theme below that.
+func SetColorProfile(p colorprofile.Profile) {
+	colorProfile = p
+	theme := NewDefaultTheme()
+	if p <= colorprofile.ANSI {
+		theme = NewANSITheme()
+	}
+	m := DefaultManager()
+	m.Register(theme)
+	m.current = theme
+}
+
+// ColorProfile returns the color support of the terminal.
+func ColorProfile() colorprofile.Profile {
+	return colorProfile
+}
+
+// HasColor reports whether the terminal shows color. Without it, states
+// told by color are told in words or by reverse video instead.
+func HasColor() bool {
+	return colorProfile > colorprofile.ASCII
+}
@@ -1,5 +1,7 @@
 package styles
 
+import "charm.land/lipgloss/v2"
+
 // NewDefaultTheme creates an ocean-inspired dark theme for CDD.
 func NewDefaultTheme() *Theme {
 	return &Theme{
@@ -33,3 +35,34 @@ func NewDefaultTheme() *Theme {
 		Info:    ParseHex("#5eb5f7"), // Ocean blue
 	}
 }
+
+// NewANSITheme creates the default theme in the 16 colors of the terminal's
+// own palette, for terminals without 256 colors. The palette is the user's,
+// so it keeps to pairs any scheme makes readable.
+func NewANSITheme() *Theme {
+	return &Theme{
+		Name:   "ansi",
+		IsDark: true,
+
+		Primary:   lipgloss.BrightBlue,
+		Secondary: lipgloss.Cyan,
+		Tertiary:  lipgloss.Blue,
+		Accent:    lipgloss.BrightCyan,
+
+		BgBase:    lipgloss.Black,
+		BgSubtle:  lipgloss.BrightBlack,
+		BgOverlay: lipgloss.BrightBlack,
+
+		FgBase:   lipgloss.White,
+		FgMuted:  lipgloss.BrightBlack,
+		FgSubtle: lipgloss.BrightBlack,
+
+		Border:      lipgloss.BrightBlack,
+		BorderFocus: lipgloss.BrightBlue,
+
+		Success: lipgloss.Green,
+		Error:   lipgloss.BrightRed,
+		Warning: lipgloss.Yellow,
+		Info:    lipgloss.BrightBlue,
+	}
+}
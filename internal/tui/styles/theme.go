@@ -88,9 +88,7 @@ func (t *Theme) buildStyles() *Styles {
 		Warning: base.Foreground(t.Warning),
 		Info:    base.Foreground(t.Info),
 
-		TextSelection: lipgloss.NewStyle().
-			Background(t.Tertiary).
-			Foreground(t.Accent),
+		TextSelection: t.selectionStyle(),
 
 		TextInput: textinput.Styles{
 			Focused: textinput.StyleState{
@@ -114,6 +112,17 @@ func (t *Theme) buildStyles() *Styles {
 	}
 }
 
+// selectionStyle marks selected text: in the theme's colors, or in reverse
+// video when the terminal shows no color.
+func (t *Theme) selectionStyle() lipgloss.Style {
+	if !HasColor() {
+		return lipgloss.NewStyle().Reverse(true)
+	}
+	return lipgloss.NewStyle().
+		Background(t.Tertiary).
+		Foreground(t.Accent)
+}
+
 // Manager manages theme instances.
 type Manager struct {
 	themes  map[string]*Theme
@@ -0,0 +1,269 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/guilhermegouw/cdd/internal/config"
+	"github.com/guilhermegouw/cdd/internal/trust"
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
+	"github.com/guilhermegouw/cdd/internal/tui/page/chat"
+	"github.com/guilhermegouw/cdd/internal/tui/styles"
+	"github.com/guilhermegouw/cdd/internal/tui/util"
+)
+
+// maxTabTitle is the most characters of a session title shown on its tab.
+const maxTabTitle = 24
+
+// tab is a chat session open in the TUI. Each tab has its own chat page, so
+// a reply can stream in one while the user types in another.
+type tab struct {
+	page     *chat.Model
+	finished bool // A reply finished while the tab was in the background
+}
+
+// newChatPage creates a chat page for the agent, wired to the TUI's
+// services.
+func (m *Model) newChatPage() *chat.Model {
+	p := chat.New(m.agent)
+	p.SetAgentFactory(m.wrapAgentFactory())
+	p.SetModelFactory(chat.ModelFactory(m.modelFactory))
+	p.SetConfig(m.cfg, m.providers)
+	if m.sessionSvc != nil {
+		p.SetSessionService(m.sessionSvc)
+	}
+	p.SetPromptHistory(m.history)
+	if store, err := trust.Load(config.DefaultDataDir()); err == nil {
+		p.SetTrust(store)
+	}
+	if m.jobs != nil {
+		p.SetJobs(m.jobs)
+	}
+	if m.hub != nil {
+		p.SetHub(m.hub)
+	}
+	if m.modelName != "" {
+		p.SetModelName(m.modelName)
+	}
+	if m.program != nil {
+		p.SetProgram(m.program)
+	}
+	return p
+}
+
+// addTab adds a chat page as a tab and switches to it.
+func (m *Model) addTab(p *chat.Model) {
+	m.tabs = append(m.tabs, &tab{page: p})
+	m.activate(len(m.tabs) - 1)
+	m.updateComponentSizes() // The tab bar may have just appeared
+}
+
+// openTab starts a new session in a new tab.
+func (m *Model) openTab() tea.Cmd {
+	if m.agent == nil {
+		return nil
+	}
+	// The page shows the current session when it starts.
+	m.agent.Sessions().Create("New Session")
+	p := m.newChatPage()
+	cmd := p.Init()
+	m.addTab(p)
+	return cmd
+}
+
+// closeTab closes the active tab, stopping the reply streaming in it.
+func (m *Model) closeTab() tea.Cmd {
+	if len(m.tabs) < 2 { //nolint:mnd // The last tab stays open
+		return util.ReportWarn("This is the only tab; quit with " + keymap.Current().Key(keymap.Quit))
+	}
+	m.tabs[m.active].page.Cancel()
+	m.tabs[m.active].page.Close()
+	m.tabs = append(m.tabs[:m.active], m.tabs[m.active+1:]...)
+	m.activate(min(m.active, len(m.tabs)-1))
+	m.updateComponentSizes()
+	return nil
+}
+
+// cycleTab moves delta tabs along, wrapping around at either end.
+func (m *Model) cycleTab(delta int) {
+	if len(m.tabs) < 2 { //nolint:mnd // Nothing to move to
+		return
+	}
+	m.activate((m.active + delta + len(m.tabs)) % len(m.tabs))
+}
+
+// activate shows the tab at i and makes its session the current one.
+func (m *Model) activate(i int) {
+	m.active = i
+	m.tabs[i].finished = false
+	m.chatPage = m.tabs[i].page
+	if m.agent != nil {
+		m.agent.Sessions().SetCurrent(m.chatPage.SessionID())
+	}
+}
+
+// tabFor returns the index of the tab showing a session, or -1.
+func (m *Model) tabFor(sessionID string) int {
+	for i, t := range m.tabs {
+		if t.page.SessionID() == sessionID {
+			return i
+		}
+	}
+	return -1
+}
+
+// handleTabKeys opens, closes and switches tabs. It returns false for other
+// keys.
+func (m *Model) handleTabKeys(msg tea.KeyMsg) (tea.Cmd, bool) {
+	km := keymap.Current()
+	switch {
+	case km.Matches(msg, keymap.NewTab):
+		return m.openTab(), true
+	case km.Matches(msg, keymap.CloseTab):
+		return m.closeTab(), true
+	case km.Matches(msg, keymap.NextTab):
+		m.cycleTab(1)
+		return nil, true
+	case km.Matches(msg, keymap.PrevTab):
+		m.cycleTab(-1)
+		return nil, true
+	}
+	return nil, false
+}
+
+// handleTab runs /tab.
+func (m *Model) handleTab(args []string) tea.Cmd {
+	action := "new"
+	if len(args) > 0 {
+		action = args[0]
+	}
+	switch action {
+	case "new":
+		return m.openTab()
+	case "close":
+		return m.closeTab()
+	case "next":
+		m.cycleTab(1)
+	case "prev":
+		m.cycleTab(-1)
+	default:
+		return util.ReportWarn(fmt.Sprintf("Unknown /tab action %q; use new, close, next or prev", action))
+	}
+	return nil
+}
+
+// routeToTab delivers a message to the chat pages. Messages about a session
+// go to the tab showing it, focus changes go to every tab, and everything
+// else goes to the active tab.
+func (m *Model) routeToTab(msg tea.Msg) tea.Cmd {
+	switch msg.(type) {
+	case tea.FocusMsg, tea.BlurMsg:
+		cmds := make([]tea.Cmd, len(m.tabs))
+		for i, t := range m.tabs {
+			_, cmds[i] = t.page.Update(msg)
+		}
+		return tea.Batch(cmds...)
+	}
+
+	i := m.active
+	if id, ok := chat.SessionOf(msg); ok {
+		if j := m.tabFor(id); j >= 0 {
+			i = j
+		}
+	}
+	t := m.tabs[i]
+	wasStreaming := t.page.IsStreaming()
+	_, cmd := t.page.Update(msg)
+	if wasStreaming && !t.page.IsStreaming() {
+		if i != m.active {
+			t.finished = true
+		}
+		return tea.Batch(cmd, m.applyPendingModel())
+	}
+	return cmd
+}
+
+// tabBarHeight is the number of rows the tab bar takes: none for one tab.
+func (m *Model) tabBarHeight() int {
+	if len(m.tabs) < 2 { //nolint:mnd // A single tab needs no bar
+		return 0
+	}
+	return 1
+}
+
+// tabLabels renders the label of each tab. Busy tabs are marked ●, and tabs
+// whose reply finished out of sight ✓.
+func (m *Model) tabLabels() []string {
+	t := styles.CurrentTheme()
+	active := lipgloss.NewStyle().Foreground(t.FgBase).Background(t.BgSubtle).Bold(true)
+
+	labels := make([]string, len(m.tabs))
+	for i, tb := range m.tabs {
+		marker := ""
+		switch {
+		case tb.page.IsStreaming():
+			marker = "● "
+		case tb.finished:
+			marker = "✓ "
+		}
+		label := fmt.Sprintf(" %d %s%s ", i+1, marker, ansi.Truncate(tb.page.Title(), maxTabTitle, "…"))
+		if i == m.active {
+			labels[i] = active.Render(label)
+		} else {
+			labels[i] = t.S().Muted.Render(label)
+		}
+	}
+	return labels
+}
+
+// renderTabBar renders the tabs on one line, cut to the terminal width.
+func (m *Model) renderTabBar() string {
+	return ansi.Truncate(strings.Join(m.tabLabels(), ""), m.width, "…")
+}
+
+// tabAt returns the index of the tab label at column x, or -1.
+func (m *Model) tabAt(x int) int {
+	left := 0
+	for i, label := range m.tabLabels() {
+		left += lipgloss.Width(label)
+		if x < left {
+			return i
+		}
+	}
+	return -1
+}
+
+// handleTabBarMouse switches to a clicked tab and shifts other mouse events
+// up past the tab bar, so the chat page sees positions relative to its own
+// top. It returns nil when the event was for the tab bar.
+func (m *Model) handleTabBarMouse(msg tea.Msg) tea.Msg {
+	dy := m.tabBarHeight()
+	if dy == 0 {
+		return msg
+	}
+	switch msg := msg.(type) {
+	case tea.MouseClickMsg:
+		if msg.Y < dy {
+			if i := m.tabAt(msg.X); i >= 0 && msg.Button == tea.MouseLeft {
+				m.activate(i)
+			}
+			return nil
+		}
+		msg.Y -= dy
+		return msg
+	case tea.MouseReleaseMsg:
+		msg.Y -= dy
+		return msg
+	case tea.MouseMotionMsg:
+		msg.Y -= dy
+		return msg
+	case tea.MouseWheelMsg:
+		msg.Y -= dy
+		return msg
+	}
+	return msg
+}
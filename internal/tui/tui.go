@@ -5,7 +5,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/fantasy"
@@ -17,16 +19,27 @@ import (
 	"github.com/guilhermegouw/cdd/internal/bridge"
 	"github.com/guilhermegouw/cdd/internal/config"
 	"github.com/guilhermegouw/cdd/internal/debug"
+	"github.com/guilhermegouw/cdd/internal/history"
+	"github.com/guilhermegouw/cdd/internal/jobs"
 	"github.com/guilhermegouw/cdd/internal/pubsub"
 	"github.com/guilhermegouw/cdd/internal/session"
+	"github.com/guilhermegouw/cdd/internal/tui/components/models"
+	"github.com/guilhermegouw/cdd/internal/tui/components/sessions"
 	"github.com/guilhermegouw/cdd/internal/tui/components/welcome"
 	"github.com/guilhermegouw/cdd/internal/tui/components/wizard"
+	"github.com/guilhermegouw/cdd/internal/tui/keymap"
 	"github.com/guilhermegouw/cdd/internal/tui/page"
 	"github.com/guilhermegouw/cdd/internal/tui/page/chat"
 	"github.com/guilhermegouw/cdd/internal/tui/styles"
 	"github.com/guilhermegouw/cdd/internal/tui/util"
 )
 
+// Smallest terminal the layout fits in; anything smaller shows a notice.
+const (
+	minWidth  = 40
+	minHeight = 12
+)
+
 // AgentFactory is a function that creates an agent from the current config.
 // It's called after the wizard completes to create the agent without restarting.
 // Also returns the session service if database is available.
@@ -40,8 +53,11 @@ type ModelFactory func() (fantasy.LanguageModel, error)
 type Model struct {
 	welcome      *welcome.Welcome
 	wizard       *wizard.Wizard
-	chatPage     *chat.Model
+	chatPage     *chat.Model // Page of the active tab
+	tabs         []*tab
+	active       int
 	agent        *agent.DefaultAgent
+	jobs         *jobs.Manager
 	agentFactory AgentFactory
 	modelFactory ModelFactory
 	program      *tea.Program
@@ -49,21 +65,24 @@ type Model struct {
 	bridge       *bridge.TUIBridge
 	cfg          *config.Config
 	sessionSvc   *session.Service
+	history      history.Store
 	currentPage  page.ID
 	statusMsg    string
 	modelName    string
-	keyMap       KeyMap
 	providers    []catwalk.Provider
 	width        int
 	height       int
 	isFirstRun   bool
 	ready        bool
+
+	// modelReloadPending is set when a config reload came in mid-reply; the
+	// new model is swapped in once every reply has finished.
+	modelReloadPending bool
 }
 
 // New creates a new TUI model.
-func New(cfg *config.Config, providers []catwalk.Provider, isFirstRun bool, ag *agent.DefaultAgent, agentFactory AgentFactory, modelFactory ModelFactory, hub *pubsub.Hub, modelName string, sessionSvc *session.Service) *Model {
+func New(cfg *config.Config, providers []catwalk.Provider, isFirstRun bool, ag *agent.DefaultAgent, agentFactory AgentFactory, modelFactory ModelFactory, hub *pubsub.Hub, modelName string, sessionSvc *session.Service, promptHistory history.Store) *Model {
 	m := &Model{
-		keyMap:       DefaultKeyMap(),
 		cfg:          cfg,
 		providers:    providers,
 		isFirstRun:   isFirstRun,
@@ -75,20 +94,14 @@ func New(cfg *config.Config, providers []catwalk.Provider, isFirstRun bool, ag *
 		hub:          hub,
 		modelName:    modelName,
 		sessionSvc:   sessionSvc,
+		history:      promptHistory,
 	}
 
 	// If we have an agent and it's not first run, go directly to chat.
 	if ag != nil && !isFirstRun {
-		m.chatPage = chat.New(ag)
-		m.chatPage.SetAgentFactory(m.wrapAgentFactory())
-		m.chatPage.SetModelFactory(chat.ModelFactory(modelFactory))
-		m.chatPage.SetConfig(cfg, providers)
-		if sessionSvc != nil {
-			m.chatPage.SetSessionService(sessionSvc)
-		}
-		if modelName != "" {
-			m.chatPage.SetModelName(modelName)
-		}
+		m.jobs = jobs.NewManager(ag, hub)
+		m.chatPage = m.newChatPage()
+		m.tabs = []*tab{{page: m.chatPage}}
 		m.currentPage = page.Chat
 	}
 
@@ -102,10 +115,12 @@ func (m *Model) wrapAgentFactory() chat.AgentFactory {
 		if err != nil {
 			return nil, err
 		}
-		// Update model and chat page with new session service.
+		// Update model and chat pages with new session service.
 		m.sessionSvc = sessionSvc
-		if m.chatPage != nil && sessionSvc != nil {
-			m.chatPage.SetSessionService(sessionSvc)
+		if sessionSvc != nil {
+			for _, t := range m.tabs {
+				t.page.SetSessionService(sessionSvc)
+			}
 		}
 		return ag, nil
 	}
@@ -114,12 +129,19 @@ func (m *Model) wrapAgentFactory() chat.AgentFactory {
 // Init initializes the TUI.
 func (m *Model) Init() tea.Cmd {
 	// If we have an agent and chat page is active, initialize it.
+	var cmd tea.Cmd
 	if m.currentPage == page.Chat && m.chatPage != nil {
-		return m.chatPage.Init()
+		cmd = tea.Batch(m.chatPage.Init(), m.chatPage.ReportInterrupted())
+	} else {
+		// For first run or if no agent, show welcome.
+		cmd = m.welcome.Init()
 	}
 
-	// For first run or if no agent, show welcome.
-	return m.welcome.Init()
+	// Say when the provider list may be out of date.
+	if m.cfg != nil && m.cfg.ProvidersWarning() != "" {
+		return tea.Batch(cmd, util.ReportWarn(m.cfg.ProvidersWarning()))
+	}
+	return cmd
 }
 
 // Update handles messages.
@@ -137,6 +159,11 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if cmd := m.handleGlobalKeys(msg); cmd != nil {
 			return m, cmd
 		}
+		if m.currentPage == page.Chat {
+			if cmd, ok := m.handleTabKeys(msg); ok {
+				return m, cmd
+			}
+		}
 	case tea.MouseWheelMsg:
 		debug.Event("tui", "MouseWheel", fmt.Sprintf("button=%v x=%d y=%d", msg.Button, msg.X, msg.Y))
 	case tea.MouseClickMsg:
@@ -176,18 +203,14 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		if m.agent != nil {
-			m.chatPage = chat.New(m.agent)
-			m.chatPage.SetAgentFactory(m.wrapAgentFactory())
-			m.chatPage.SetModelFactory(chat.ModelFactory(m.modelFactory))
-			m.chatPage.SetConfig(m.cfg, m.providers)
-			if m.sessionSvc != nil {
-				m.chatPage.SetSessionService(m.sessionSvc)
-			}
+			m.jobs = jobs.NewManager(m.agent, m.hub)
 			if modelName != "" {
-				m.chatPage.SetModelName(modelName)
+				m.modelName = modelName
 			}
+			m.chatPage = m.newChatPage()
+			m.tabs = []*tab{{page: m.chatPage}}
+			m.active = 0
 			m.chatPage.SetSize(m.width, m.height)
-			m.chatPage.SetProgram(m.program)
 			m.currentPage = page.Chat
 			return m, m.chatPage.Init()
 		}
@@ -199,6 +222,23 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.statusMsg = msg.Msg
 		}
 		return m, nil
+	case models.ModelSwitchedMsg:
+		// The agent, and so its model, is shared by every tab.
+		m.modelName = msg.ModelName
+		for _, t := range m.tabs {
+			t.page.SetModelName(msg.ModelName)
+		}
+	case bridge.ConfigEventMsg:
+		return m, m.handleConfigEvent(msg.Event.Payload)
+	case chat.TabMsg:
+		return m, m.handleTab(msg.Args)
+	case sessions.SwitchSessionMsg:
+		// A session open in another tab is shown there rather than twice.
+		if i := m.tabFor(msg.SessionID); i >= 0 && m.currentPage == page.Chat {
+			m.chatPage.Update(sessions.ModalClosedMsg{})
+			m.activate(i)
+			return m, nil
+		}
 	case page.ChangeMsg:
 		debug.Event("tui", "PageChange", fmt.Sprintf("page=%s", msg.Page))
 		m.currentPage = msg.Page
@@ -219,7 +259,7 @@ func (m *Model) handleWindowSize(msg tea.WindowSizeMsg) {
 }
 
 func (m *Model) handleGlobalKeys(msg tea.KeyMsg) tea.Cmd {
-	if msg.String() == "ctrl+c" {
+	if keymap.Current().Matches(msg, keymap.Quit) {
 		return tea.Quit
 	}
 	if msg.String() == "q" && m.canQuit() {
@@ -258,11 +298,13 @@ func (m *Model) routeToPage(msg tea.Msg) tea.Cmd {
 }
 
 func (m *Model) updateChat(msg tea.Msg) tea.Cmd {
-	if m.chatPage == nil {
+	if len(m.tabs) == 0 {
 		return nil
 	}
-	_, cmd := m.chatPage.Update(msg)
-	return cmd
+	if msg = m.handleTabBarMouse(msg); msg == nil {
+		return nil
+	}
+	return m.routeToTab(msg)
 }
 
 func (m *Model) updateWizard(msg tea.Msg) tea.Cmd {
@@ -285,6 +327,7 @@ func (m *Model) View() tea.View {
 	var view tea.View
 	view.AltScreen = true
 	view.MouseMode = tea.MouseModeCellMotion
+	view.ReportFocus = true // Completion notifications are only sent in the background
 	// Don't force background color - let terminal use its native background
 	// to avoid polluting the terminal state on exit
 
@@ -293,6 +336,11 @@ func (m *Model) View() tea.View {
 		return view
 	}
 
+	if m.width < minWidth || m.height < minHeight {
+		view.Content = m.renderTooSmall()
+		return view
+	}
+
 	var content string
 	switch m.currentPage {
 	case page.Welcome:
@@ -304,6 +352,9 @@ func (m *Model) View() tea.View {
 	case page.Chat:
 		if m.chatPage != nil {
 			content = m.chatPage.View()
+			if m.tabBarHeight() > 0 {
+				content = lipgloss.JoinVertical(lipgloss.Left, m.renderTabBar(), content)
+			}
 			debug.Event("tui", "View", fmt.Sprintf("chat content lines=%d", strings.Count(content, "\n")+1))
 		}
 	case page.Main:
@@ -329,6 +380,9 @@ func (m *Model) View() tea.View {
 	case page.Chat:
 		if m.chatPage != nil {
 			view.Cursor = m.chatPage.Cursor()
+			if view.Cursor != nil {
+				view.Cursor.Y += m.tabBarHeight()
+			}
 		}
 	case page.Welcome, page.Main:
 		// No cursor for these pages
@@ -337,6 +391,17 @@ func (m *Model) View() tea.View {
 	return view
 }
 
+// renderTooSmall asks for a bigger terminal in place of a layout that would
+// not fit.
+func (m *Model) renderTooSmall() string {
+	t := styles.CurrentTheme()
+	text := lipgloss.JoinVertical(lipgloss.Center,
+		t.S().Warning.Render("Please enlarge your terminal"),
+		t.S().Muted.Render(fmt.Sprintf("%d×%d, need at least %d×%d", m.width, m.height, minWidth, minHeight)),
+	)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, text)
+}
+
 func (m *Model) renderMain() string {
 	t := styles.CurrentTheme()
 	return lipgloss.Place(
@@ -353,29 +418,30 @@ func (m *Model) updateComponentSizes() {
 	if m.wizard != nil {
 		m.wizard.SetSize(m.width, m.height)
 	}
-	if m.chatPage != nil {
-		m.chatPage.SetSize(m.width, m.height)
+	for _, t := range m.tabs {
+		t.page.SetSize(m.width, m.height-m.tabBarHeight())
 	}
 }
 
 // Run starts the TUI program.
-func Run(cfg *config.Config, providers []catwalk.Provider, isFirstRun bool, ag *agent.DefaultAgent, agentFactory AgentFactory, modelFactory ModelFactory, hub *pubsub.Hub, modelName string, sessionSvc *session.Service) error {
+func Run(cfg *config.Config, providers []catwalk.Provider, isFirstRun bool, ag *agent.DefaultAgent, agentFactory AgentFactory, modelFactory ModelFactory, hub *pubsub.Hub, modelName string, sessionSvc *session.Service, promptHistory history.Store) error {
 	// Check if running in a terminal.
 	if !term.IsTerminal(int(os.Stdin.Fd())) {
 		return fmt.Errorf("cdd requires an interactive terminal: stdin/stdout must be connected to a TTY")
 	}
 
-	// Initialize theme.
-	styles.NewManager()
+	// Initialize the theme for the colors the terminal shows.
+	profile := detectColors(cfg)
+	styles.SetColorProfile(profile)
 
-	model := New(cfg, providers, isFirstRun, ag, agentFactory, modelFactory, hub, modelName, sessionSvc)
+	model := New(cfg, providers, isFirstRun, ag, agentFactory, modelFactory, hub, modelName, sessionSvc, promptHistory)
 	// In Bubble Tea v2, AltScreen and MouseMode are set in View()
-	p := tea.NewProgram(model)
+	p := tea.NewProgram(model, tea.WithColorProfile(profile))
 
 	// Set the program reference so chat can send stream messages.
 	model.program = p
-	if model.chatPage != nil {
-		model.chatPage.SetProgram(p)
+	for _, t := range model.tabs {
+		t.page.SetProgram(p)
 	}
 
 	// Start TUI bridge to forward pub/sub events to Bubble Tea messages.
@@ -389,6 +455,21 @@ func Run(cfg *config.Config, providers []catwalk.Provider, isFirstRun bool, ag *
 		defer tuiBridge.Stop()
 	}
 
+	// Bubble Tea quits on SIGINT and SIGTERM; closing the terminal should
+	// too, rather than kill cdd before the replies in progress are saved.
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+	exited := make(chan struct{})
+	defer close(exited)
+	go func() {
+		select {
+		case <-hangup:
+			p.Quit()
+		case <-exited:
+		}
+	}()
+
 	_, err := p.Run()
 	if err != nil {
 		return fmt.Errorf("running TUI: %w", err)
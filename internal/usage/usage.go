@@ -0,0 +1,233 @@
+// Package usage records the tokens and cost of provider requests and checks
+// them against the configured budgets.
+package usage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+
+	"github.com/guilhermegouw/cdd/internal/db/sqlc"
+)
+
+// ErrBudgetExceeded is returned when a session or the day has used up a
+// budget.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// Tokens counts the tokens of one or more requests.
+type Tokens struct {
+	Input         int64
+	Output        int64
+	CacheCreation int64
+	CacheRead     int64
+}
+
+// Price is what a model costs, in dollars per million tokens.
+type Price struct {
+	In        float64
+	Out       float64
+	InCached  float64 // Writing to the prompt cache
+	OutCached float64 // Reading from the prompt cache
+}
+
+// PriceOf returns the price listed for a model.
+func PriceOf(model catwalk.Model) Price {
+	return Price{
+		In:        model.CostPer1MIn,
+		Out:       model.CostPer1MOut,
+		InCached:  model.CostPer1MInCached,
+		OutCached: model.CostPer1MOutCached,
+	}
+}
+
+// Cost returns what tokens cost at price.
+func (p Price) Cost(tokens Tokens) float64 {
+	const perMillion = 1_000_000
+	return (p.In*float64(tokens.Input) +
+		p.Out*float64(tokens.Output) +
+		p.InCached*float64(tokens.CacheCreation) +
+		p.OutCached*float64(tokens.CacheRead)) / perMillion
+}
+
+// Totals sums the requests of a session or a period.
+type Totals struct {
+	Tokens
+	Requests int64
+	Cost     float64
+}
+
+// Total returns the input and output tokens, which budgets count.
+func (t Totals) Total() int64 {
+	return t.Input + t.Output
+}
+
+// Budget caps the tokens and dollars spent per session and per day. Zero
+// leaves a limit off.
+type Budget struct {
+	SessionCost   float64
+	SessionTokens int64
+	DailyCost     float64
+	DailyTokens   int64
+}
+
+// IsZero reports whether no limit is set.
+func (b Budget) IsZero() bool {
+	return b == Budget{}
+}
+
+// Tracker records requests in the session database. A nil Tracker records
+// nothing and never exceeds a budget.
+type Tracker struct {
+	queries *sqlc.Queries
+	prices  map[string]Price // By model ID
+}
+
+// NewTracker creates a tracker that prices requests by model ID.
+func NewTracker(db *sql.DB, prices map[string]Price) *Tracker {
+	return &Tracker{queries: sqlc.New(db), prices: prices}
+}
+
+// Record saves a request made for a session and returns its cost.
+func (t *Tracker) Record(ctx context.Context, sessionID, provider, model string, tokens Tokens) (float64, error) {
+	if t == nil {
+		return 0, nil
+	}
+	cost := t.prices[model].Cost(tokens)
+	err := t.queries.AddUsage(ctx, sqlc.AddUsageParams{
+		SessionID:           sessionID,
+		Provider:            provider,
+		Model:               model,
+		InputTokens:         tokens.Input,
+		OutputTokens:        tokens.Output,
+		CacheCreationTokens: tokens.CacheCreation,
+		CacheReadTokens:     tokens.CacheRead,
+		Cost:                cost,
+		CreatedAt:           time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return cost, fmt.Errorf("recording usage: %w", err)
+	}
+	return cost, nil
+}
+
+// Session returns what a session has used.
+func (t *Tracker) Session(ctx context.Context, sessionID string) (Totals, error) {
+	if t == nil {
+		return Totals{}, nil
+	}
+	row, err := t.queries.SumSessionUsage(ctx, sessionID)
+	if err != nil {
+		return Totals{}, fmt.Errorf("summing session usage: %w", err)
+	}
+	return toTotals(sqlc.SumUsageSinceRow(row)), nil
+}
+
+// Since returns what all sessions have used since a time.
+func (t *Tracker) Since(ctx context.Context, since time.Time) (Totals, error) {
+	if t == nil {
+		return Totals{}, nil
+	}
+	row, err := t.queries.SumUsageSince(ctx, since.UnixMilli())
+	if err != nil {
+		return Totals{}, fmt.Errorf("summing usage: %w", err)
+	}
+	return toTotals(row), nil
+}
+
+// DayUsage is what one model was used for on one day.
+type DayUsage struct {
+	Day      string // YYYY-MM-DD
+	Provider string
+	Model    string
+	Totals
+}
+
+// ByDay returns what each provider and model was used for, day by day, since
+// a time. Days follow the UTC offset of since.
+func (t *Tracker) ByDay(ctx context.Context, since time.Time) ([]DayUsage, error) {
+	if t == nil {
+		return nil, nil
+	}
+	_, offset := since.Zone()
+	rows, err := t.queries.UsageByDay(ctx, sqlc.UsageByDayParams{
+		UtcOffset: int64(offset),
+		CreatedAt: since.UnixMilli(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("summing usage by day: %w", err)
+	}
+	days := make([]DayUsage, 0, len(rows))
+	for _, row := range rows {
+		days = append(days, DayUsage{
+			Day:      row.Day,
+			Provider: row.Provider,
+			Model:    row.Model,
+			Totals: toTotals(sqlc.SumUsageSinceRow{
+				Requests:            row.Requests,
+				InputTokens:         row.InputTokens,
+				OutputTokens:        row.OutputTokens,
+				CacheCreationTokens: row.CacheCreationTokens,
+				CacheReadTokens:     row.CacheReadTokens,
+				Cost:                row.Cost,
+			}),
+		})
+	}
+	return days, nil
+}
+
+// Check returns an error wrapping ErrBudgetExceeded when a session or today
+// has reached a limit of budget.
+func (t *Tracker) Check(ctx context.Context, sessionID string, budget Budget) error {
+	if t == nil || budget.IsZero() {
+		return nil
+	}
+	if budget.SessionCost > 0 || budget.SessionTokens > 0 {
+		session, err := t.Session(ctx, sessionID)
+		if err != nil {
+			return err
+		}
+		if err := exceeded("session", session, budget.SessionCost, budget.SessionTokens); err != nil {
+			return err
+		}
+	}
+	if budget.DailyCost > 0 || budget.DailyTokens > 0 {
+		now := time.Now()
+		today, err := t.Since(ctx, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()))
+		if err != nil {
+			return err
+		}
+		if err := exceeded("daily", today, budget.DailyCost, budget.DailyTokens); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exceeded describes the first limit totals has reached, if any.
+func exceeded(scope string, totals Totals, cost float64, tokens int64) error {
+	if cost > 0 && totals.Cost >= cost {
+		return fmt.Errorf("%w: the %s budget of $%.2f is used up ($%.2f spent)", ErrBudgetExceeded, scope, cost, totals.Cost)
+	}
+	if tokens > 0 && totals.Total() >= tokens {
+		return fmt.Errorf("%w: the %s budget of %d tokens is used up (%d used)", ErrBudgetExceeded, scope, tokens, totals.Total())
+	}
+	return nil
+}
+
+// toTotals converts a sum of usage rows. Both sums have the same columns.
+func toTotals(row sqlc.SumUsageSinceRow) Totals {
+	return Totals{
+		Tokens: Tokens{
+			Input:         row.InputTokens,
+			Output:        row.OutputTokens,
+			CacheCreation: row.CacheCreationTokens,
+			CacheRead:     row.CacheReadTokens,
+		},
+		Requests: row.Requests,
+		Cost:     row.Cost,
+	}
+}
@@ -0,0 +1,138 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/guilhermegouw/cdd/internal/db"
+	"github.com/guilhermegouw/cdd/internal/db/sqlc"
+)
+
+func TestPrice_Cost(t *testing.T) {
+	price := Price{In: 3, Out: 15, InCached: 3.75, OutCached: 0.3}
+	got := price.Cost(Tokens{Input: 1_000_000, Output: 100_000, CacheCreation: 200_000, CacheRead: 1_000_000})
+	if want := 3 + 1.5 + 0.75 + 0.3; got != want {
+		t.Errorf("Cost() = %v, want %v", got, want)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	database, err := db.Open(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() }) //nolint:errcheck // Test cleanup
+	ctx := context.Background()
+
+	tracker := NewTracker(database.Conn(), map[string]Price{"sonnet": {In: 3, Out: 15}})
+	cost, err := tracker.Record(ctx, "s1", "anthropic", "sonnet", Tokens{Input: 100_000, Output: 10_000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost != 0.45 {
+		t.Errorf("Record() cost = %v, want 0.45", cost)
+	}
+	if _, err := tracker.Record(ctx, "s2", "local", "unknown", Tokens{Input: 50_000}); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := tracker.Session(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Requests != 1 || session.Total() != 110_000 || session.Cost != 0.45 {
+		t.Errorf("Session() = %+v", session)
+	}
+	today, err := tracker.Since(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if today.Requests != 2 || today.Total() != 160_000 {
+		t.Errorf("Since() = %+v, want both sessions", today)
+	}
+
+	tests := []struct {
+		name     string
+		budget   Budget
+		exceeded bool
+	}{
+		{"no limits", Budget{}, false},
+		{"session cost left", Budget{SessionCost: 1}, false},
+		{"session cost used up", Budget{SessionCost: 0.45}, true},
+		{"session tokens used up", Budget{SessionTokens: 100_000}, true},
+		{"daily tokens left", Budget{DailyTokens: 200_000}, false},
+		{"daily tokens used up", Budget{DailyTokens: 150_000}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tracker.Check(ctx, "s1", tt.budget)
+			if got := errors.Is(err, ErrBudgetExceeded); got != tt.exceeded {
+				t.Errorf("Check() = %v, want exceeded %v", err, tt.exceeded)
+			}
+		})
+	}
+
+	var none *Tracker
+	if err := none.Check(ctx, "s1", Budget{SessionTokens: 1}); err != nil {
+		t.Errorf("a nil tracker should never exceed a budget, got %v", err)
+	}
+}
+
+func TestTracker_ByDay(t *testing.T) {
+	database, err := db.Open(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() }) //nolint:errcheck // Test cleanup
+	ctx := context.Background()
+
+	zone := time.FixedZone("BRT", -3*60*60)
+	queries := sqlc.New(database.Conn())
+	for _, r := range []struct {
+		model string
+		at    time.Time
+		cost  float64
+	}{
+		{"sonnet", time.Date(2026, 9, 30, 12, 0, 0, 0, zone), 1},  // Before since
+		{"sonnet", time.Date(2026, 10, 1, 9, 0, 0, 0, zone), 0.5}, // Same day and model
+		{"sonnet", time.Date(2026, 10, 1, 23, 30, 0, 0, zone), 0.25},
+		{"haiku", time.Date(2026, 10, 1, 10, 0, 0, 0, zone), 0.1},
+		{"sonnet", time.Date(2026, 10, 2, 8, 0, 0, 0, zone), 2},
+	} {
+		err := queries.AddUsage(ctx, sqlc.AddUsageParams{
+			SessionID: "s1", Provider: "anthropic", Model: r.model,
+			InputTokens: 1000, OutputTokens: 100, Cost: r.cost, CreatedAt: r.at.UnixMilli(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	days, err := NewTracker(database.Conn(), nil).ByDay(ctx, time.Date(2026, 10, 1, 0, 0, 0, 0, zone))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		day, model string
+		requests   int64
+		cost       float64
+	}{
+		{"2026-10-01", "haiku", 1, 0.1},
+		{"2026-10-01", "sonnet", 2, 0.75},
+		{"2026-10-02", "sonnet", 1, 2},
+	}
+	if len(days) != len(want) {
+		t.Fatalf("ByDay() = %+v, want %d rows", days, len(want))
+	}
+	for i, w := range want {
+		d := days[i]
+		if d.Day != w.day || d.Model != w.model || d.Requests != w.requests || d.Cost != w.cost || d.Provider != "anthropic" {
+			t.Errorf("ByDay()[%d] = %+v, want %+v", i, d, w)
+		}
+	}
+	if days[1].Input != 2000 || days[1].Output != 200 {
+		t.Errorf("ByDay()[1] tokens = %+v, want both requests summed", days[1].Tokens)
+	}
+}